@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// allFormat is the output format of the "all" command.
+var allFormat string
+
+// newAllCmd creates "all", which lists every slip matching the ancestry.
+func newAllCmd(deps *Dependencies) *cobra.Command {
+	allCmd := &cobra.Command{
+		Use:   "all [path]",
+		Short: "List every slip matching the commit ancestry",
+		Long: `List every routing slip that matches a commit in the HEAD ancestry,
+nearest first, instead of choosing one.
+
+Each candidate includes the matched commit, its distance from HEAD, and when the
+slip was created, so callers can apply their own selection logic. Selection
+policies, progressive deepening, and fallbacks do not apply.
+
+Examples:
+  slippy-find all
+  slippy-find all --depth 50 --format json
+  slippy-find all --component payments-api --max-age 72h`,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAll(cmd, args, deps)
+		},
+	}
+
+	addSearchFlags(allCmd)
+	allCmd.Flags().StringVar(&allFormat, "format", "text",
+		"Output format: text or json")
+
+	return allCmd
+}
+
+// runAll resolves every candidate slip and writes them to stdout.
+// On failure with --format json, the error and its code are written instead.
+// A panic fails the run like any error, so the session is still closed.
+func runAll(cmd *cobra.Command, args []string, deps *Dependencies) (err error) {
+	var s *session
+	bundle := newDebugBundle()
+	defer func() {
+		if r := recover(); r != nil {
+			err = recoveredError(cmd.Context(), s, r)
+		}
+		if err != nil {
+			reportFailure(cmd.Context(), cmd, deps, s, err)
+			env := s.environment()
+			if allFormat == "json" {
+				writeErrorJSON(resultStdout(deps), err, env)
+			}
+		}
+		writeDebugBundle(cmd, deps, bundle, err)
+		closeSession(deps, s)
+	}()
+
+	if allFormat != "text" && allFormat != "json" {
+		return usageErrorf("unsupported format %q: expected text or json", allFormat)
+	}
+
+	s, err = openSession(cmd, args, deps, bundle)
+	if err != nil {
+		return err
+	}
+	ctx, log := s.ctx, s.log
+
+	result, err := s.resolver.ResolveAll(ctx, s.input)
+	if err != nil {
+		log.Error(ctx, "failed to resolve slips", err, nil)
+		return resolveError(err)
+	}
+
+	env := s.environment()
+	stdout := resultStdout(deps)
+	if allFormat == "json" {
+		err = writeCandidatesJSON(stdout, result, env)
+	} else {
+		err = writeCandidatesText(stdout, result.Candidates)
+	}
+	if err != nil {
+		log.Error(ctx, "failed to write output", err, nil)
+		return err
+	}
+
+	log.Info(ctx, "candidate listing complete", map[string]interface{}{
+		"repository": result.Repository,
+		"candidates": len(result.Candidates),
+	})
+	return nil
+}
+
+// writeCandidatesText writes candidates as aligned columns with a header row.
+func writeCandidatesText(w io.Writer, candidates []domain.Candidate) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "CORRELATION_ID\tMATCHED_COMMIT\tDISTANCE\tCREATED_AT\tBRANCH"); err != nil {
+		return outputError(err)
+	}
+	for _, c := range candidates {
+		_, err := fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\n",
+			c.CorrelationID, c.MatchedCommit, c.Distance, c.CreatedAt.UTC().Format(time.RFC3339), c.Branch)
+		if err != nil {
+			return outputError(err)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return outputError(err)
+	}
+	return nil
+}
+
+// candidateJSON is the JSON representation of a domain.Candidate.
+type candidateJSON struct {
+	CorrelationID string    `json:"correlation_id"`
+	MatchedCommit string    `json:"matched_commit"`
+	Distance      int       `json:"distance"`
+	CreatedAt     time.Time `json:"created_at"`
+	Branch        string    `json:"branch,omitempty"`
+}
+
+// candidatesJSON is the JSON document written by "all --format json".
+type candidatesJSON struct {
+	Repository  string           `json:"repository"`
+	Branch      string           `json:"branch,omitempty"`
+	HeadSHA     string           `json:"head_sha"`
+	Candidates  []candidateJSON  `json:"candidates"`
+	Environment *environmentJSON `json:"environment,omitempty"`
+}
+
+// writeCandidatesJSON writes the full result, with env if it is not nil, as
+// an indented JSON document.
+func writeCandidatesJSON(w io.Writer, result *domain.ResolveAllOutput, env *domain.Environment) error {
+	doc := candidatesJSON{
+		Repository:  result.Repository,
+		Branch:      result.Branch,
+		HeadSHA:     result.HeadSHA,
+		Candidates:  make([]candidateJSON, 0, len(result.Candidates)),
+		Environment: newEnvironmentJSON(env),
+	}
+	for _, c := range result.Candidates {
+		doc.Candidates = append(doc.Candidates, candidateJSON{
+			CorrelationID: c.CorrelationID,
+			MatchedCommit: c.MatchedCommit,
+			Distance:      c.Distance,
+			CreatedAt:     c.CreatedAt.UTC(),
+			Branch:        c.Branch,
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return outputError(err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+func allTestDeps(resolver *mockResolver) *Dependencies {
+	return &Dependencies{
+		LoggerFactory: func() Logger { return &mockLogger{} },
+		ConfigLoader: func() (*AppConfig, error) {
+			return &AppConfig{Database: "ci"}, nil
+		},
+		GitRepoFactory: func(_ string, _ Logger) (domain.LocalGitRepository, error) {
+			return &mockGitRepo{}, nil
+		},
+		SlipFinderFactory: func(_ *AppConfig, _ Logger) (domain.SlipFinder, error) {
+			return &mockSlipFinder{}, nil
+		},
+		ResolverFactory: func(_ domain.LocalGitRepository, _ domain.SlipFinder, _ Logger) domain.Resolver {
+			return resolver
+		},
+		Stderr: io.Discard,
+	}
+}
+
+func runAllCmd(t *testing.T, deps *Dependencies, args ...string) (string, error) {
+	t.Helper()
+	var stdout bytes.Buffer
+	deps.Stdout = &stdout
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs(append([]string{"all"}, args...))
+	err := cmd.Execute()
+	return stdout.String(), err
+}
+
+func testAllOutput() *domain.ResolveAllOutput {
+	created := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	return &domain.ResolveAllOutput{
+		Repository: "org/repo",
+		Branch:     "feature/x",
+		HeadSHA:    "c0",
+		Candidates: []domain.Candidate{
+			{CorrelationID: "slip-a", MatchedCommit: "c1", Distance: 1, CreatedAt: created, Branch: "feature/x"},
+			{CorrelationID: "slip-b", MatchedCommit: "c4", Distance: 4, CreatedAt: created.Add(-time.Hour)},
+		},
+	}
+}
+
+func TestAllCmd_Text(t *testing.T) {
+	resolver := &mockResolver{allOutput: testAllOutput()}
+
+	out, err := runAllCmd(t, allTestDeps(resolver), "--depth", "50")
+
+	require.NoError(t, err)
+	assert.Equal(t, 50, resolver.input.Depth)
+	lines := bytes.Split(bytes.TrimSpace([]byte(out)), []byte("\n"))
+	require.Len(t, lines, 3)
+	assert.Contains(t, string(lines[0]), "CORRELATION_ID")
+	assert.Contains(t, string(lines[1]), "slip-a")
+	assert.Contains(t, string(lines[1]), "2026-06-01T12:00:00Z")
+	assert.Contains(t, string(lines[2]), "slip-b")
+}
+
+func TestAllCmd_JSON(t *testing.T) {
+	resolver := &mockResolver{allOutput: testAllOutput()}
+
+	out, err := runAllCmd(t, allTestDeps(resolver), "--format", "json")
+
+	require.NoError(t, err)
+	var doc candidatesJSON
+	require.NoError(t, json.Unmarshal([]byte(out), &doc))
+	assert.Equal(t, "org/repo", doc.Repository)
+	assert.Equal(t, "c0", doc.HeadSHA)
+	require.Len(t, doc.Candidates, 2)
+	assert.Equal(t, "slip-a", doc.Candidates[0].CorrelationID)
+	assert.Equal(t, 1, doc.Candidates[0].Distance)
+	assert.Equal(t, "c4", doc.Candidates[1].MatchedCommit)
+	assert.Contains(t, out, `"created_at": "2026-06-01T12:00:00Z"`)
+}
+
+func TestAllCmd_NoSlips(t *testing.T) {
+	resolver := &mockResolver{err: domain.ErrNoAncestorSlip}
+
+	_, err := runAllCmd(t, allTestDeps(resolver))
+
+	require.Error(t, err)
+	assert.Equal(t, "no slip found in commit ancestry", err.Error())
+}
+
+func TestAllCmd_InvalidFormat(t *testing.T) {
+	_, err := runAllCmd(t, allTestDeps(&mockResolver{}), "--format", "yaml")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported format")
+}
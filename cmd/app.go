@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// runMu serializes runs: flag values are parsed into package variables, so
+// two command lines must not be executed at once.
+var runMu sync.Mutex
+
+// App runs slippy-find command lines with the dependencies it was created
+// with. Embedders and tests may create as many Apps as they like and run them
+// from any goroutine; their runs take turns.
+type App struct {
+	deps *Dependencies
+}
+
+// NewApp returns an App running command lines with deps.
+func NewApp(deps *Dependencies) *App {
+	return &App{deps: deps}
+}
+
+// Run runs the command line args, without the program name, with ctx and
+// returns its error, leaving the exit to the caller (e.g. to flush telemetry
+// first; see ExitCode). A panic is returned as a *PanicError, after its crash
+// report is written to the App's Stderr.
+func (a *App) Run(ctx context.Context, args []string) error {
+	var stderr io.Writer = os.Stderr
+	if a.deps != nil && a.deps.Stderr != nil {
+		stderr = a.deps.Stderr
+	}
+
+	runMu.Lock()
+	defer runMu.Unlock()
+	return executeRecovered(ctx, NewRootCmdWithDeps(a.deps), args, stderr)
+}
+
+// Run runs the command line args with deps; see App.Run.
+func Run(ctx context.Context, args []string, deps *Dependencies) error {
+	return NewApp(deps).Run(ctx, args)
+}
+
+// defaultDeps holds the dependencies of the package-level entry points
+// NewRootCmd, Execute, and ExecuteContext.
+var defaultDeps atomic.Pointer[Dependencies]
+
+// SetDefaultDependencies sets the dependencies NewRootCmd, Execute, and
+// ExecuteContext run with.
+//
+// Deprecated: Pass the dependencies to NewApp or Run instead.
+func SetDefaultDependencies(deps *Dependencies) {
+	defaultDeps.Store(deps)
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+func TestApp_Run_Concurrent(t *testing.T) {
+	const runs = 8
+	stdouts := make([]bytes.Buffer, runs)
+	errs := make([]error, runs)
+
+	var wg sync.WaitGroup
+	for i := range runs {
+		deps := allTestDeps(&mockResolver{output: &domain.ResolveOutput{CorrelationID: fmt.Sprintf("slip-%d", i)}})
+		deps.Stdout = &stdouts[i]
+		app := NewApp(deps)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = app.Run(context.Background(), []string{".", "--format", "json", "--depth", fmt.Sprint(10 + i)})
+		}()
+	}
+	wg.Wait()
+
+	for i := range runs {
+		require.NoError(t, errs[i])
+		var result resultJSON
+		require.NoError(t, json.Unmarshal(stdouts[i].Bytes(), &result))
+		assert.Equal(t, fmt.Sprintf("slip-%d", i), result.CorrelationID, "each run uses its own dependencies")
+	}
+}
+
+func TestApp_Run_Panic(t *testing.T) {
+	deps := allTestDeps(nil)
+	deps.ResolverFactory = func(_ domain.LocalGitRepository, _ domain.SlipFinder, _ Logger) domain.Resolver {
+		return &panickingResolver{}
+	}
+	var stderr bytes.Buffer
+	deps.Stderr = &stderr
+
+	err := NewApp(deps).Run(context.Background(), []string{"."})
+
+	assert.Equal(t, ExitCodePanic, ExitCode(err))
+	assert.Contains(t, stderr.String(), "slippy-find crashed", "the crash report goes to the App's stderr")
+}
+
+func TestRun_NilDependencies(t *testing.T) {
+	err := Run(context.Background(), []string{"."}, nil)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "dependencies not configured")
+}
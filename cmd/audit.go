@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// recordAudit writes an audit event for each result when --audit or the
+// configuration enables auditing. The resolution has already been written to
+// stdout, so failures are logged as warnings rather than failing the run.
+func recordAudit(s *session, deps *Dependencies, elapsed time.Duration, results ...*domain.ResolveOutput) {
+	if !audit && (s.cfg == nil || !s.cfg.Audit) {
+		return
+	}
+	ctx, log := s.ctx, s.log
+
+	if deps.AuditWriterFactory == nil {
+		log.Warn(ctx, "audit writer not configured", nil)
+		return
+	}
+	writer, err := deps.AuditWriterFactory(s.cfg, log)
+	if err != nil {
+		log.Warn(ctx, "failed to open audit writer", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+	defer func() {
+		if closeErr := writer.Close(); closeErr != nil {
+			log.Warn(ctx, "failed to close audit writer", map[string]interface{}{
+				"error": closeErr.Error(),
+			})
+		}
+	}()
+
+	for _, event := range auditEvents(s, deps, elapsed, results) {
+		if err := writer.WriteAudit(ctx, event); err != nil {
+			log.Warn(ctx, "failed to write audit event", map[string]interface{}{
+				"correlation_id": event.CorrelationID,
+				"error":          err.Error(),
+			})
+		}
+	}
+}
+
+// auditEvents describes each result as an event of this run, which took elapsed.
+func auditEvents(
+	s *session,
+	deps *Dependencies,
+	elapsed time.Duration,
+	results []*domain.ResolveOutput,
+) []domain.AuditEvent {
+	var headSHA string
+	if gitCtx, err := s.gitRepo.GetGitContext(s.ctx); err == nil && gitCtx != nil {
+		headSHA = gitCtx.HeadSHA
+	}
+	var caller domain.Annotation
+	if deps.AnnotationLoader != nil {
+		caller = deps.AnnotationLoader()
+	}
+
+	now := time.Now()
+	events := make([]domain.AuditEvent, 0, len(results))
+	for _, result := range results {
+		events = append(events, domain.AuditEvent{
+			Timestamp:     now,
+			Repository:    result.Repository,
+			Branch:        result.Branch,
+			HeadSHA:       headSHA,
+			MatchedCommit: result.MatchedCommit,
+			CorrelationID: result.CorrelationID,
+			ResolvedBy:    result.ResolvedBy,
+			Component:     result.Component,
+			Caller:        caller,
+			Duration:      elapsed,
+		})
+	}
+	return events
+}
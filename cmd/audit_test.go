@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// mockAuditWriter implements domain.AuditWriter, recording events.
+type mockAuditWriter struct {
+	events []domain.AuditEvent
+	err    error
+	closed bool
+}
+
+func (m *mockAuditWriter) WriteAudit(_ context.Context, event domain.AuditEvent) error {
+	m.events = append(m.events, event)
+	return m.err
+}
+
+func (m *mockAuditWriter) Close() error {
+	m.closed = true
+	return nil
+}
+
+func auditTestDeps(writer *mockAuditWriter, output *domain.ResolveOutput) *Dependencies {
+	deps := allTestDeps(&mockResolver{output: output})
+	deps.OutputWriterFactory = func() domain.OutputWriter { return &mockOutputWriter{} }
+	deps.GitRepoFactory = func(string, Logger) (domain.LocalGitRepository, error) {
+		return &mockGitRepo{gitContext: &domain.GitContext{HeadSHA: "c0", Repository: "org/repo"}}, nil
+	}
+	deps.AnnotationLoader = func() domain.Annotation {
+		return domain.Annotation{Host: "runner-1", Pipeline: "build/42"}
+	}
+	deps.AuditWriterFactory = func(*AppConfig, Logger) (domain.AuditWriter, error) {
+		return writer, nil
+	}
+	return deps
+}
+
+func TestRootCmd_Audit(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		cfgAudit   bool
+		wantEvents int
+	}{
+		{name: "disabled by default", args: []string{"."}},
+		{name: "enabled by flag", args: []string{".", "--audit"}, wantEvents: 1},
+		{name: "enabled by configuration", args: []string{"."}, cfgAudit: true, wantEvents: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer := &mockAuditWriter{}
+			deps := auditTestDeps(writer, &domain.ResolveOutput{
+				CorrelationID: "slip-1",
+				MatchedCommit: "c1",
+				Repository:    "org/repo",
+				Branch:        "main",
+				ResolvedBy:    domain.ResolvedByAncestry,
+			})
+			deps.ConfigLoader = func() (*AppConfig, error) {
+				return &AppConfig{Database: "ci", Audit: tt.cfgAudit}, nil
+			}
+
+			cmd := NewRootCmdWithDeps(deps)
+			cmd.SetArgs(tt.args)
+			require.NoError(t, cmd.Execute())
+
+			require.Len(t, writer.events, tt.wantEvents)
+			if tt.wantEvents == 0 {
+				return
+			}
+			event := writer.events[0]
+			assert.Equal(t, "slip-1", event.CorrelationID)
+			assert.Equal(t, "c1", event.MatchedCommit)
+			assert.Equal(t, "c0", event.HeadSHA)
+			assert.Equal(t, "org/repo", event.Repository)
+			assert.Equal(t, "main", event.Branch)
+			assert.Equal(t, domain.ResolvedByAncestry, event.ResolvedBy)
+			assert.Equal(t, domain.Annotation{Host: "runner-1", Pipeline: "build/42"}, event.Caller)
+			assert.Positive(t, event.Duration)
+			assert.WithinDuration(t, time.Now(), event.Timestamp, time.Minute)
+			assert.True(t, writer.closed)
+		})
+	}
+}
+
+func TestRootCmd_AuditFailureDoesNotFailResolution(t *testing.T) {
+	tests := []struct {
+		name    string
+		factory func(*AppConfig, Logger) (domain.AuditWriter, error)
+	}{
+		{name: "not configured"},
+		{
+			name: "writer cannot open",
+			factory: func(*AppConfig, Logger) (domain.AuditWriter, error) {
+				return nil, errors.New("connection refused")
+			},
+		},
+		{
+			name: "write fails",
+			factory: func(*AppConfig, Logger) (domain.AuditWriter, error) {
+				return &mockAuditWriter{err: errors.New("table does not exist")}, nil
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := auditTestDeps(&mockAuditWriter{}, &domain.ResolveOutput{CorrelationID: "slip-1"})
+			deps.AuditWriterFactory = tt.factory
+
+			cmd := NewRootCmdWithDeps(deps)
+			cmd.SetArgs([]string{".", "--audit"})
+
+			assert.NoError(t, cmd.Execute())
+		})
+	}
+}
+
+func TestRootCmd_AuditComponents(t *testing.T) {
+	writer := &mockAuditWriter{}
+	deps := auditTestDeps(writer, nil)
+	deps.ResolverFactory = func(domain.LocalGitRepository, domain.SlipFinder, Logger) domain.Resolver {
+		return &mockResolver{componentOutputs: map[string]*domain.ResolveOutput{
+			"web": {CorrelationID: "slip-web", Component: "web"},
+			"api": {CorrelationID: "slip-api", Component: "api"},
+		}}
+	}
+	deps.ComponentLoader = func(string) (map[string][]string, error) {
+		return map[string][]string{"api": {"api/"}, "web": {"web/"}}, nil
+	}
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{".", "--components", "api,web", "--audit"})
+	require.NoError(t, cmd.Execute())
+
+	require.Len(t, writer.events, 2)
+	assert.Equal(t, "slip-api", writer.events[0].CorrelationID)
+	assert.Equal(t, "slip-web", writer.events[1].CorrelationID)
+}
@@ -0,0 +1,333 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/slippyfind"
+)
+
+// Batch command flags.
+var (
+	batchConcurrency    int
+	batchRequestTimeout time.Duration
+	batchUnbuffered     bool
+	batchVerbose        bool
+)
+
+// batchRequestJSON is one request read by "batch".
+type batchRequestJSON struct {
+	// ID is echoed in the response so callers can match them up.
+	ID              string   `json:"id,omitempty"`
+	Path            string   `json:"path,omitempty"`
+	Repository      string   `json:"repository,omitempty"`
+	Branch          string   `json:"branch,omitempty"`
+	Commits         []string `json:"commits,omitempty"`
+	Depth           int      `json:"depth,omitempty"`
+	SelectionPolicy string   `json:"selection_policy,omitempty"`
+}
+
+// batchResponseJSON is written for every request: Result on success,
+// otherwise Error and Code.
+type batchResponseJSON struct {
+	ID         string          `json:"id,omitempty"`
+	Result     *resultJSON     `json:"result,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	Code       string          `json:"code,omitempty"`
+	Validation *validationJSON `json:"validation,omitempty"`
+}
+
+// validationJSON points at the rejected commit of a request's commit list,
+// as in serve mode's error responses.
+type validationJSON struct {
+	Field  string `json:"field"`
+	Index  *int   `json:"index,omitempty"`
+	Value  string `json:"value,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// newValidationJSON describes err if it is a *domain.CommitListError, and
+// returns nil otherwise.
+func newValidationJSON(err error) *validationJSON {
+	var invalid *domain.CommitListError
+	if !errors.As(err, &invalid) {
+		return nil
+	}
+	v := &validationJSON{Field: "commits", Value: invalid.Commit, Reason: invalid.Reason}
+	if invalid.Index >= 0 {
+		v.Index = &invalid.Index
+	}
+	return v
+}
+
+// newBatchCmd creates "batch", which resolves many requests against one
+// store connection.
+func newBatchCmd(deps *Dependencies) *cobra.Command {
+	batchCmd := &cobra.Command{
+		Use:   "batch [file]",
+		Short: "Resolve slips for many checkouts or commit lists at once",
+		Long: `Resolve a stream of JSON requests, one per line, read from file or stdin.
+
+Each request names a checkout by path, or a repository and its commit
+ancestry, newest first:
+
+  {"id": "api", "path": "/builds/payments-api"}
+  {"id": "web", "repository": "org/web", "branch": "main",
+   "commits": ["9f2c1e7a4b3d5f6081a2b3c4d5e6f708192a3b4c", "4e8d2a6c0b1f3e5d7c9a8b6f4d2e0c1a3b5d7f9e"]}
+
+Commits must be full 40- or 64-character SHAs; abbreviated ones are rejected.
+"depth" and "selection_policy" are accepted as well. Requests share one store
+connection and are resolved --concurrency at a time; --request-timeout fails
+a request that takes too long without stopping the others. One response per
+request is written to stdout, in request order, echoing its id:
+
+  {"id": "api", "result": {...same fields as --format json...}}
+  {"id": "web", "error": "no slip found in commit ancestry", "code": "SLIPPY_E003"}
+
+Responses are buffered and written in request order. With --unbuffered, each
+response is written as soon as its request is resolved, in completion order,
+so a consumer sees results while slower requests are still running; match
+responses to requests by id. Either way each response is one whole line, and
+lines from concurrent requests never interleave. If the reader of stdout
+goes away, such as "| head -1", requests not yet started are skipped.
+
+The command exits non-zero if any request failed, counting the failures by
+error code.
+
+Examples:
+  slippy-find batch requests.jsonl
+  generate-requests | slippy-find batch --concurrency 16 --request-timeout 10s
+  slippy-find batch --unbuffered requests.jsonl | jq -c 'select(.error)'`,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runBatch(cmd, args, deps)
+		},
+	}
+
+	batchCmd.Flags().IntVar(&batchConcurrency, "concurrency", slippyfind.DefaultConcurrency,
+		"Number of requests resolved at once")
+	batchCmd.Flags().DurationVar(&batchRequestTimeout, "request-timeout", 0,
+		"Fail a request not resolved within this long (0 = no limit)")
+	batchCmd.Flags().BoolVar(&batchUnbuffered, "unbuffered", false,
+		"Write each response as soon as it is resolved, in completion order")
+	batchCmd.Flags().BoolVarP(&batchVerbose, "verbose", "v", false,
+		"Enable verbose/debug logging")
+
+	return batchCmd
+}
+
+// runBatch reads the requests, resolves them with slippyfind.ResolveMany,
+// and writes one response per request.
+func runBatch(cmd *cobra.Command, args []string, deps *Dependencies) error {
+	if deps == nil {
+		return configError(errors.New("dependencies not configured"))
+	}
+	if batchConcurrency < 1 {
+		return usageErrorf("--concurrency must be at least 1")
+	}
+	if batchRequestTimeout < 0 {
+		return usageErrorf("--request-timeout must not be negative")
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if batchVerbose {
+		enableDebugLogging(deps)
+	}
+	log := deps.LoggerFactory()
+
+	ids, reqs, err := readBatchRequests(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := deps.ConfigLoader()
+	if err != nil {
+		err = configError(fmt.Errorf("configuration error: %w", err))
+		log.Error(ctx, "failed to load configuration", err, nil)
+		return err
+	}
+	storeStart := time.Now()
+	finder, closeFinder, err := openSlipFinder(ctx, deps, log, cfg, nil)
+	if err != nil {
+		return err
+	}
+	defer closeFinder()
+	storeOpen := time.Since(storeStart)
+
+	resolver := slippyfind.NewResolver(slippyfind.NewFinderStore(finder), log).
+		WithConcurrency(batchConcurrency).
+		WithRequestTimeout(batchRequestTimeout)
+	failed, err := writeBatch(ctx, resolver, ids, reqs, newNDJSONWriter(commandStdout(deps), !batchUnbuffered))
+	if errors.Is(err, errOutputClosed) {
+		log.Warn(ctx, "batch output closed by its reader, remaining requests skipped", nil)
+		return outputError(err)
+	}
+	if err != nil {
+		err = outputError(err)
+		log.Error(ctx, "failed to write batch responses", err, nil)
+		return err
+	}
+	log.Info(ctx, "batch resolved", map[string]interface{}{
+		"requests":            len(reqs),
+		"failed":              failures(failed),
+		"failed_by_code":      failed,
+		"store_open_ms":       millis(storeOpen),
+		"store_open_saved_ms": millis(reuseSavings(storeOpen, len(reqs))),
+	})
+	return batchError(failed, len(reqs))
+}
+
+// writeBatch resolves reqs with resolver and writes one response per request
+// to out: as each is resolved if out is unbuffered, and otherwise in request
+// order. Returns the failed requests counted by error code. Once a write
+// fails, requests not yet started are skipped and its error is returned.
+func writeBatch(
+	ctx context.Context,
+	resolver *slippyfind.Resolver,
+	ids []string,
+	reqs []slippyfind.Request,
+	out *ndjsonWriter,
+) (map[domain.ErrorCode]int, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	failed := map[domain.ErrorCode]int{}
+	var writeErr error
+	write := func(i int, response slippyfind.Response) {
+		if writeErr != nil {
+			return
+		}
+		if writeErr = out.Write(batchResponse(ids[i], response, failed)); writeErr != nil {
+			cancel()
+		}
+	}
+	if out.buffered == nil {
+		resolver.ResolveEach(ctx, reqs, write)
+	} else {
+		for i, response := range resolver.ResolveMany(ctx, reqs) {
+			write(i, response)
+		}
+	}
+	if writeErr != nil {
+		return nil, writeErr
+	}
+	return failed, out.Flush()
+}
+
+// batchResponse converts the response to request id, counting a failure in
+// failed by error code.
+func batchResponse(id string, response slippyfind.Response, failed map[domain.ErrorCode]int) batchResponseJSON {
+	out := batchResponseJSON{ID: id}
+	if response.Err != nil {
+		err := resolveError(response.Err)
+		failed[domain.CodeOf(err)]++
+		out.Error, out.Code = err.Error(), string(domain.CodeOf(err))
+		out.Validation = newValidationJSON(err)
+		return out
+	}
+	result := newResultJSON(batchOutput(response.Result))
+	out.Result = &result
+	return out
+}
+
+// failures returns the number of failed requests counted in byCode.
+func failures(byCode map[domain.ErrorCode]int) int {
+	n := 0
+	for _, count := range byCode {
+		n += count
+	}
+	return n
+}
+
+// batchError summarizes the failed requests, counted by error code, out of
+// total; nil when none failed. When every failure has the same code, the
+// error carries it.
+func batchError(byCode map[domain.ErrorCode]int, total int) error {
+	failed := failures(byCode)
+	if failed == 0 {
+		return nil
+	}
+	codes := slices.Sorted(maps.Keys(byCode))
+	counts := make([]string, len(codes))
+	for i, code := range codes {
+		counts[i] = fmt.Sprintf("%s: %d", code, byCode[code])
+	}
+	err := fmt.Errorf("%d of %d requests failed (%s)", failed, total, strings.Join(counts, ", "))
+	if len(codes) == 1 {
+		return domain.WithCode(codes[0], err)
+	}
+	return err
+}
+
+// readBatchRequests decodes the requests in the file named by args, or
+// stdin, returning their ids and the requests.
+func readBatchRequests(cmd *cobra.Command, args []string) ([]string, []slippyfind.Request, error) {
+	in := cmd.InOrStdin()
+	if len(args) == 1 && args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return nil, nil, usageErrorf("cannot read requests: %v", err)
+		}
+		defer func() { _ = f.Close() }()
+		in = f
+	}
+
+	var (
+		ids  []string
+		reqs []slippyfind.Request
+	)
+	decoder := json.NewDecoder(in)
+	for {
+		var req batchRequestJSON
+		err := decoder.Decode(&req)
+		if errors.Is(err, io.EOF) {
+			return ids, reqs, nil
+		}
+		if err != nil {
+			return nil, nil, usageErrorf("invalid request %d: %v", len(reqs)+1, err)
+		}
+		ids = append(ids, req.ID)
+		reqs = append(reqs, slippyfind.Request{
+			Path:            req.Path,
+			Repository:      req.Repository,
+			Branch:          req.Branch,
+			Commits:         req.Commits,
+			Depth:           req.Depth,
+			SelectionPolicy: req.SelectionPolicy,
+		})
+	}
+}
+
+// batchOutput converts a library result to the resolver output it came from.
+func batchOutput(result *slippyfind.Result) *domain.ResolveOutput {
+	return &domain.ResolveOutput{
+		CorrelationID:   result.CorrelationID,
+		MatchedCommit:   result.MatchedCommit,
+		Repository:      result.Repository,
+		Branch:          result.Branch,
+		Depth:           result.Depth,
+		Distance:        result.Distance,
+		Confidence:      domain.Confidence(result.Confidence),
+		ResolvedBy:      result.ResolvedBy,
+		SelectionPolicy: domain.SelectionPolicy(result.SelectionPolicy),
+		Candidates:      result.Candidates,
+		Component:       result.Component,
+		Mock:            result.Mock,
+	}
+}
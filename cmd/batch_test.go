@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+func runBatchCmd(t *testing.T, deps *Dependencies, stdin string, args ...string) ([]batchResponseJSON, error) {
+	t.Helper()
+	var stdout bytes.Buffer
+	deps.Stdout = &stdout
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetIn(strings.NewReader(stdin))
+	cmd.SetArgs(append([]string{"batch"}, args...))
+	err := cmd.Execute()
+
+	var responses []batchResponseJSON
+	decoder := json.NewDecoder(&stdout)
+	for decoder.More() {
+		var response batchResponseJSON
+		require.NoError(t, decoder.Decode(&response))
+		responses = append(responses, response)
+	}
+	return responses, err
+}
+
+func TestBatchCmd(t *testing.T) {
+	finder := &mockSlipFinder{
+		slip:        &domain.Slip{CorrelationID: "slip-1", Repository: "org/repo", CommitSHA: "c222222222222222222222222222222222222222"},
+		matchCommit: "c222222222222222222222222222222222222222",
+	}
+	deps := allTestDeps(&mockResolver{})
+	deps.SlipFinderFactory = func(_ *AppConfig, _ Logger) (domain.SlipFinder, error) { return finder, nil }
+
+	responses, err := runBatchCmd(t, deps, `
+{"id": "a", "repository": "org/repo", "branch": "main", "commits": ["c111111111111111111111111111111111111111", "c222222222222222222222222222222222222222"]}
+{"id": "b", "repository": "org/repo"}
+{"id": "c", "repository": "org/repo", "commits": ["c111111111111111111111111111111111111111"], "selection_policy": "oldest"}
+`)
+
+	assert.EqualError(t, err, "2 of 3 requests failed (SLIPPY_E000: 1, SLIPPY_E011: 1)")
+	require.Len(t, responses, 3)
+	assert.Equal(t, "a", responses[0].ID)
+	require.NotNil(t, responses[0].Result)
+	assert.Equal(t, "slip-1", responses[0].Result.CorrelationID)
+	assert.Equal(t, "c222222222222222222222222222222222222222", responses[0].Result.MatchedCommit)
+	assert.Equal(t, SchemaVersion, responses[0].Result.SchemaVersion)
+	assert.Equal(t, "b", responses[1].ID)
+	assert.Contains(t, responses[1].Error, "no commits")
+	assert.Equal(t, "c", responses[2].ID)
+	assert.Equal(t, string(domain.CodeOf(domain.ErrInvalidSelectionPolicy)), responses[2].Code)
+	assert.True(t, finder.closeCalled)
+}
+
+func TestBatchCmd_NoSlip(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "requests.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(`{"id": "a", "repository": "org/repo", "commits": ["c111111111111111111111111111111111111111"]}`), 0o600))
+
+	responses, err := runBatchCmd(t, allTestDeps(&mockResolver{}), "", path, "--concurrency", "1")
+
+	assert.EqualError(t, err, "1 of 1 requests failed (SLIPPY_E003: 1)")
+	assert.Equal(t, domain.CodeNoSlip, domain.CodeOf(err), "a failure code shared by every request is kept")
+	require.Len(t, responses, 1)
+	assert.Equal(t, string(domain.CodeNoSlip), responses[0].Code)
+	assert.Equal(t, "no slip found in commit ancestry", responses[0].Error)
+}
+
+func TestBatchCmd_InvalidCommit(t *testing.T) {
+	responses, err := runBatchCmd(t, allTestDeps(&mockResolver{}),
+		`{"id": "a", "repository": "org/repo", "commits": ["c111111111111111111111111111111111111111", "HEAD~1"]}`)
+
+	require.Error(t, err)
+	require.Len(t, responses, 1)
+	assert.Equal(t, string(domain.CodeInvalidInput), responses[0].Code)
+	require.NotNil(t, responses[0].Validation)
+	assert.Equal(t, "commits", responses[0].Validation.Field)
+	require.NotNil(t, responses[0].Validation.Index)
+	assert.Equal(t, 1, *responses[0].Validation.Index)
+	assert.Equal(t, "HEAD~1", responses[0].Validation.Value)
+	assert.Equal(t, "contains 'h', expected hexadecimal digits", responses[0].Validation.Reason)
+}
+
+func TestBatchCmd_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		stdin   string
+		args    []string
+		wantErr string
+	}{
+		{name: "malformed request", stdin: `{"id": `, wantErr: "invalid request 1"},
+		{name: "missing file", args: []string{"missing.jsonl"}, wantErr: "cannot read requests"},
+		{name: "zero concurrency", args: []string{"--concurrency", "0"}, wantErr: "--concurrency must be at least 1"},
+		{
+			name:    "negative request timeout",
+			args:    []string{"--request-timeout", "-1s"},
+			wantErr: "--request-timeout must not be negative",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runBatchCmd(t, allTestDeps(&mockResolver{}), tt.stdin, tt.args...)
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+			assert.Equal(t, domain.CodeInvalidInput, domain.CodeOf(err))
+		})
+	}
+}
+
+func TestBatchCmd_Unbuffered(t *testing.T) {
+	finder := &mockSlipFinder{slip: &domain.Slip{CorrelationID: "slip-1"}, matchCommit: "c111111111111111111111111111111111111111"}
+	deps := allTestDeps(&mockResolver{})
+	deps.SlipFinderFactory = func(_ *AppConfig, _ Logger) (domain.SlipFinder, error) { return finder, nil }
+
+	responses, err := runBatchCmd(t, deps, `
+{"id": "a", "repository": "org/repo", "commits": ["c111111111111111111111111111111111111111"]}
+{"id": "b", "repository": "org/repo", "commits": ["c111111111111111111111111111111111111111"]}
+`, "--unbuffered")
+
+	require.NoError(t, err)
+	ids := []string{}
+	for _, response := range responses {
+		ids = append(ids, response.ID)
+	}
+	assert.ElementsMatch(t, []string{"a", "b"}, ids)
+}
+
+func TestBatchCmd_OutputClosed(t *testing.T) {
+	for _, mode := range [][]string{nil, {"--unbuffered"}} {
+		t.Run(strings.Join(append([]string{"buffered"}, mode...), " "), func(t *testing.T) {
+			finder := &mockSlipFinder{slip: &domain.Slip{CorrelationID: "slip-1"}, matchCommit: "c111111111111111111111111111111111111111"}
+			deps := allTestDeps(&mockResolver{})
+			deps.SlipFinderFactory = func(_ *AppConfig, _ Logger) (domain.SlipFinder, error) { return finder, nil }
+			out := &pipeWriter{err: syscall.EPIPE}
+			deps.Stdout = out
+
+			cmd := NewRootCmdWithDeps(deps)
+			cmd.SetIn(strings.NewReader(`{"id": "a", "repository": "org/repo", "commits": ["c111111111111111111111111111111111111111"]}`))
+			cmd.SetArgs(append([]string{"batch"}, mode...))
+			err := cmd.Execute()
+
+			require.ErrorIs(t, err, errOutputClosed)
+			assert.Equal(t, domain.CodeOutput, domain.CodeOf(err))
+			assert.Equal(t, 1, out.writes)
+			assert.True(t, finder.closeCalled)
+		})
+	}
+}
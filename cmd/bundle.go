@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// debugBundle collects what a --debug-bundle archive describes while a
+// command runs. Fields are filled in as openSession gets to them, so a run
+// that fails early produces a partial bundle.
+type debugBundle struct {
+	// settings is the effective configuration; nil if it failed to load.
+	settings []ConfigSetting
+
+	gitContext  *domain.GitContext
+	gitErr      error
+	ancestry    []string
+	ancestryErr error
+
+	// mu guards logs, which concurrent resolution steps may append to.
+	mu   sync.Mutex
+	logs []logEntryJSON
+}
+
+// newDebugBundle returns a bundle to collect into when --debug-bundle is set, and nil otherwise.
+func newDebugBundle() *debugBundle {
+	if debugBundlePath == "" {
+		return nil
+	}
+	return &debugBundle{}
+}
+
+// logger returns log wrapped so that every entry is also kept for the bundle.
+func (b *debugBundle) logger(log Logger) Logger {
+	return &bundleLogger{Logger: log, bundle: b}
+}
+
+// recordGit captures the git context and ancestry up front, since the
+// repository may be closed by the time the bundle is written.
+func (b *debugBundle) recordGit(ctx context.Context, gitRepo domain.LocalGitRepository) {
+	b.gitContext, b.gitErr = gitRepo.GetGitContext(ctx)
+	b.ancestry, b.ancestryErr = gitRepo.GetCommitAncestry(ctx, depth)
+}
+
+// record appends a log entry.
+func (b *debugBundle) record(level, msg string, err error, fields map[string]interface{}) {
+	entry := logEntryJSON{Time: time.Now().UTC(), Level: level, Message: msg, Fields: fields}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.logs = append(b.logs, entry)
+}
+
+// files renders the bundle's contents as archive file name -> contents.
+func (b *debugBundle) files(cmd *cobra.Command, runErr error) map[string][]byte {
+	files := map[string][]byte{
+		"run.json":     marshalBundleJSON(newRunJSON(cmd, runErr)),
+		"logs.jsonl":   b.logLines(),
+		"ancestry.txt": ancestryText(b.ancestry, b.ancestryErr),
+	}
+	if b.settings != nil {
+		var buf bytes.Buffer
+		if err := writeSettingsJSON(&buf, b.settings, true); err == nil {
+			files["config.json"] = buf.Bytes()
+		}
+	}
+	if b.gitContext != nil || b.gitErr != nil {
+		files["git.json"] = marshalBundleJSON(newGitJSON(b.gitContext, b.gitErr))
+	}
+	return files
+}
+
+// logLines renders the recorded log entries as JSON lines.
+func (b *debugBundle) logLines() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, entry := range b.logs {
+		if err := enc.Encode(entry); err != nil {
+			// Keep the entry, with its fields flattened to text
+			entry.Fields = map[string]interface{}{"fields": fmt.Sprint(entry.Fields)}
+			_ = enc.Encode(entry)
+		}
+	}
+	return buf.Bytes()
+}
+
+// bundleLogger keeps each entry for the debug bundle before passing it on.
+type bundleLogger struct {
+	Logger
+
+	bundle *debugBundle
+}
+
+// Info records and logs an info message.
+func (l *bundleLogger) Info(ctx context.Context, msg string, fields map[string]interface{}) {
+	l.bundle.record("info", msg, nil, fields)
+	l.Logger.Info(ctx, msg, fields)
+}
+
+// Debug records and logs a debug message. Debug entries are kept even when
+// the log level hides them, which is much of the point of a bundle.
+func (l *bundleLogger) Debug(ctx context.Context, msg string, fields map[string]interface{}) {
+	l.bundle.record("debug", msg, nil, fields)
+	l.Logger.Debug(ctx, msg, fields)
+}
+
+// Warn records and logs a warning.
+func (l *bundleLogger) Warn(ctx context.Context, msg string, fields map[string]interface{}) {
+	l.bundle.record("warn", msg, nil, fields)
+	l.Logger.Warn(ctx, msg, fields)
+}
+
+// Error records and logs an error.
+func (l *bundleLogger) Error(ctx context.Context, msg string, err error, fields map[string]interface{}) {
+	l.bundle.record("error", msg, err, fields)
+	l.Logger.Error(ctx, msg, err, fields)
+}
+
+// logEntryJSON is one line of the bundle's logs.jsonl.
+type logEntryJSON struct {
+	Time    time.Time              `json:"time"`
+	Level   string                 `json:"level"`
+	Message string                 `json:"msg"`
+	Error   string                 `json:"error,omitempty"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+}
+
+// runJSON is the bundle's run.json: what was run and how it ended.
+type runJSON struct {
+	Version   string            `json:"version"`
+	Command   string            `json:"command"`
+	Args      []string          `json:"args,omitempty"`
+	Flags     map[string]string `json:"flags,omitempty"`
+	Error     string            `json:"error,omitempty"`
+	ErrorCode string            `json:"error_code,omitempty"`
+	WrittenAt time.Time         `json:"written_at"`
+}
+
+// newRunJSON describes the run of cmd, including the flags that were set.
+func newRunJSON(cmd *cobra.Command, runErr error) runJSON {
+	run := runJSON{
+		Version:   Version,
+		Command:   cmd.CommandPath(),
+		Args:      cmd.Flags().Args(),
+		Flags:     map[string]string{},
+		WrittenAt: time.Now().UTC(),
+	}
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		run.Flags[f.Name] = f.Value.String()
+	})
+	if runErr != nil {
+		run.Error = runErr.Error()
+		run.ErrorCode = string(domain.CodeOf(runErr))
+	}
+	return run
+}
+
+// gitJSON is the bundle's git.json.
+type gitJSON struct {
+	Repository string `json:"repository,omitempty"`
+	Branch     string `json:"branch,omitempty"`
+	HeadSHA    string `json:"head_sha,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// newGitJSON describes the git context, or the error reading it.
+func newGitJSON(gitCtx *domain.GitContext, err error) gitJSON {
+	var out gitJSON
+	if gitCtx != nil {
+		out.Repository, out.Branch, out.HeadSHA = gitCtx.Repository, gitCtx.Branch, gitCtx.HeadSHA
+	}
+	if err != nil {
+		out.Error = err.Error()
+	}
+	return out
+}
+
+// ancestryText lists the ancestry one SHA per line, newest first, or the error walking it.
+func ancestryText(commits []string, err error) []byte {
+	if err != nil {
+		return []byte("error: " + err.Error() + "\n")
+	}
+	if len(commits) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(commits, "\n") + "\n")
+}
+
+// marshalBundleJSON renders v as indented JSON.
+func marshalBundleJSON(v any) []byte {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return []byte(err.Error())
+	}
+	return append(data, '\n')
+}
+
+// writeDebugBundle writes the bundle to --debug-bundle if the run failed, or
+// always with --debug-bundle-always. It reports where the bundle went on
+// stderr; a bundle that cannot be written is a warning, not a failure.
+func writeDebugBundle(cmd *cobra.Command, deps *Dependencies, bundle *debugBundle, runErr error) {
+	if bundle == nil || deps == nil || deps.DebugBundleWriter == nil || (runErr == nil && !debugBundleAlways) {
+		return
+	}
+	stderr := deps.Stderr
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+	if err := deps.DebugBundleWriter.WriteBundle(debugBundlePath, bundle.files(cmd, runErr)); err != nil {
+		writeWarningf(stderr, "warning: could not write debug bundle: %v\n", err)
+		return
+	}
+	writeWarningf(stderr, "debug bundle written to %s\n", debugBundlePath)
+}
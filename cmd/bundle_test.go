@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// mockBundleWriter implements DebugBundleWriter, keeping the last bundle.
+type mockBundleWriter struct {
+	path  string
+	files map[string][]byte
+	err   error
+}
+
+func (m *mockBundleWriter) WriteBundle(path string, files map[string][]byte) error {
+	m.path = path
+	m.files = files
+	return m.err
+}
+
+func bundleTestDeps(writer *mockBundleWriter, resolver *mockResolver) *Dependencies {
+	deps := allTestDeps(resolver)
+	deps.OutputWriterFactory = func() domain.OutputWriter { return &mockOutputWriter{} }
+	deps.GitRepoFactory = func(string, Logger) (domain.LocalGitRepository, error) {
+		return &mockGitRepo{
+			gitContext: &domain.GitContext{Repository: "org/repo", Branch: "main", HeadSHA: "c0"},
+			commits:    []string{"c0", "c1"},
+		}, nil
+	}
+	deps.ConfigLoader = func() (*AppConfig, error) {
+		return &AppConfig{Database: "ci", Settings: []ConfigSetting{
+			{Key: "clickhouse.password", Value: "hunter22", Source: "env", Secret: true},
+			{Key: "database", Value: "ci", Source: "default"},
+		}}, nil
+	}
+	deps.DebugBundleWriter = writer
+	return deps
+}
+
+func TestRootCmd_DebugBundleOnFailure(t *testing.T) {
+	writer := &mockBundleWriter{}
+	var stderr bytes.Buffer
+	deps := bundleTestDeps(writer, &mockResolver{err: domain.WithCode(domain.CodeStore, errors.New("query timeout"))})
+	deps.Stderr = &stderr
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{".", "--debug-bundle", "/tmp/bundle.tar.gz", "--depth", "2"})
+	require.Error(t, cmd.Execute())
+
+	assert.Equal(t, "/tmp/bundle.tar.gz", writer.path)
+	assert.Contains(t, stderr.String(), "debug bundle written to /tmp/bundle.tar.gz")
+
+	var run runJSON
+	require.NoError(t, json.Unmarshal(writer.files["run.json"], &run))
+	assert.Equal(t, "query timeout", run.Error)
+	assert.Equal(t, "SLIPPY_E014", run.ErrorCode)
+	assert.Equal(t, "2", run.Flags["depth"])
+
+	var git gitJSON
+	require.NoError(t, json.Unmarshal(writer.files["git.json"], &git))
+	assert.Equal(t, gitJSON{Repository: "org/repo", Branch: "main", HeadSHA: "c0"}, git)
+
+	assert.Equal(t, "c0\nc1\n", string(writer.files["ancestry.txt"]))
+	assert.NotContains(t, string(writer.files["config.json"]), "hunter22")
+	assert.Contains(t, string(writer.files["config.json"]), `"source": "env"`)
+	assert.Contains(t, string(writer.files["logs.jsonl"]), `"msg":"starting slippy-find"`)
+	assert.Contains(t, string(writer.files["logs.jsonl"]), `"msg":"failed to resolve slip","error":"query timeout"`)
+}
+
+func TestRootCmd_DebugBundleOnSuccess(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantBundle bool
+	}{
+		{name: "skipped on success", args: []string{".", "--debug-bundle", "b.tar.gz"}},
+		{name: "always", args: []string{".", "--debug-bundle", "b.tar.gz", "--debug-bundle-always"}, wantBundle: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer := &mockBundleWriter{}
+			deps := bundleTestDeps(writer, &mockResolver{output: &domain.ResolveOutput{CorrelationID: "slip-1"}})
+
+			cmd := NewRootCmdWithDeps(deps)
+			cmd.SetArgs(tt.args)
+			require.NoError(t, cmd.Execute())
+
+			if !tt.wantBundle {
+				assert.Nil(t, writer.files)
+				return
+			}
+			var run runJSON
+			require.NoError(t, json.Unmarshal(writer.files["run.json"], &run))
+			assert.Empty(t, run.Error)
+		})
+	}
+}
+
+func TestRootCmd_DebugBundleEarlyFailure(t *testing.T) {
+	writer := &mockBundleWriter{}
+	deps := bundleTestDeps(writer, &mockResolver{})
+	deps.ConfigLoader = func() (*AppConfig, error) { return nil, errors.New("vault sealed") }
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{".", "--debug-bundle", "b.tar.gz"})
+	require.Error(t, cmd.Execute())
+
+	require.NotNil(t, writer.files)
+	assert.NotContains(t, writer.files, "config.json")
+	assert.NotContains(t, writer.files, "git.json")
+	assert.Contains(t, string(writer.files["run.json"]), "vault sealed")
+}
+
+func TestRootCmd_DebugBundleWriteFails(t *testing.T) {
+	var stderr bytes.Buffer
+	deps := bundleTestDeps(&mockBundleWriter{err: errors.New("read-only file system")}, &mockResolver{
+		err: domain.ErrNoAncestorSlip,
+	})
+	deps.Stderr = &stderr
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{".", "--debug-bundle", "b.tar.gz"})
+	err := cmd.Execute()
+
+	require.Error(t, err)
+	assert.Equal(t, "no slip found in commit ancestry", err.Error())
+	assert.Contains(t, stderr.String(), "could not write debug bundle: read-only file system")
+}
+
+func TestRootCmd_DebugBundleNotConfigured(t *testing.T) {
+	deps := bundleTestDeps(nil, &mockResolver{})
+	deps.DebugBundleWriter = nil
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{".", "--debug-bundle", "b.tar.gz"})
+	err := cmd.Execute()
+
+	require.Error(t, err)
+	assert.Equal(t, "debug bundle not configured", err.Error())
+}
+
+func TestAllCmd_DebugBundle(t *testing.T) {
+	writer := &mockBundleWriter{}
+	deps := bundleTestDeps(writer, &mockResolver{err: domain.ErrNoAncestorSlip})
+
+	_, err := runAllCmd(t, deps, "--debug-bundle", "b.tar.gz")
+
+	require.Error(t, err)
+	assert.Contains(t, string(writer.files["run.json"]), `"command": "slippy-find all"`)
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// maxCacheEntries bounds the number of results a resultCache holds.
+const maxCacheEntries = 10000
+
+// cacheKey identifies a resolution: the commit resolved and the settings that
+// can change its answer.
+type cacheKey struct {
+	repository string
+	head       string
+	depth      int
+	policy     domain.SelectionPolicy
+
+	// ancestry hashes the branch and commits a client sent, so a made-up
+	// ancestry only ever answers requests that send the same one. It is empty
+	// for history the server trusts: checkouts and signed push webhooks.
+	ancestry string
+}
+
+// String returns the key as a string, for coalescing requests.
+func (k cacheKey) String() string {
+	return fmt.Sprintf("%s\x00%s\x00%d\x00%s\x00%s", k.repository, k.head, k.depth, k.policy, k.ancestry)
+}
+
+// trusted returns the key of the same resolution from trusted history.
+func (k cacheKey) trusted() cacheKey {
+	k.ancestry = ""
+	return k
+}
+
+// ancestryHash hashes a client-supplied branch and commit list for cacheKey.
+func ancestryHash(branch string, commits []string) string {
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%q", branch)
+	for _, c := range commits {
+		_, _ = fmt.Fprintf(h, "\x00%s", c)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// cacheEntry is a cached result and when it stops being served.
+type cacheEntry struct {
+	result  domain.ResolveOutput
+	expires time.Time
+}
+
+// resultCache keeps successful resolutions for a while, so that requests for
+// a commit that was just resolved (or pre-resolved from a push webhook) skip
+// the store. Failures are not cached: a commit without a slip yet may get one
+// moments later.
+type resultCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[cacheKey]cacheEntry
+
+	// hits and misses count lookups, for the admin endpoints.
+	hits   uint64
+	misses uint64
+}
+
+// newResultCache creates a cache whose entries last ttl.
+func newResultCache(ttl time.Duration) *resultCache {
+	return &resultCache{ttl: ttl, now: time.Now, entries: make(map[cacheKey]cacheEntry)}
+}
+
+// get returns a copy of the result cached for the first of keys that has
+// one that has not expired. A lookup counts as one hit or miss.
+func (c *resultCache) get(keys ...cacheKey) (*domain.ResolveOutput, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		entry, ok := c.entries[key]
+		if !ok {
+			continue
+		}
+		if !c.now().Before(entry.expires) {
+			delete(c.entries, key)
+			continue
+		}
+		c.hits++
+		result := entry.result
+		return &result, true
+	}
+	c.misses++
+	return nil, false
+}
+
+// put caches a copy of result for key. When the cache is full, expired
+// entries are dropped first, then arbitrary ones.
+func (c *resultCache) put(key cacheKey, result *domain.ResolveOutput) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= maxCacheEntries {
+		for k, entry := range c.entries {
+			if !now.Before(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+		for k := range c.entries {
+			if len(c.entries) < maxCacheEntries {
+				break
+			}
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = cacheEntry{result: *result, expires: now.Add(c.ttl)}
+}
+
+// stats reports the cache's size and lookups since it was created.
+func (c *resultCache) stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CacheStats{Enabled: true, Entries: len(c.entries), Hits: c.hits, Misses: c.misses, TTL: c.ttl}
+}
+
+// flush drops every entry and returns how many there were.
+func (c *resultCache) flush() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	flushed := len(c.entries)
+	clear(c.entries)
+	return flushed
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+func TestResultCache(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	cache := newResultCache(time.Minute)
+	cache.now = func() time.Time { return now }
+	key := cacheKey{repository: "org/repo", head: "c1", depth: 25, policy: domain.DefaultSelectionPolicy}
+
+	_, ok := cache.get(key)
+	assert.False(t, ok)
+
+	cache.put(key, &domain.ResolveOutput{CorrelationID: "slip-1"})
+	result, ok := cache.get(key)
+	require.True(t, ok)
+	assert.Equal(t, "slip-1", result.CorrelationID)
+
+	// Callers get their own copy
+	result.CorrelationID = "changed"
+	result, _ = cache.get(key)
+	assert.Equal(t, "slip-1", result.CorrelationID)
+
+	// Other settings are other entries
+	_, ok = cache.get(cacheKey{repository: "org/repo", head: "c1", depth: 50, policy: domain.DefaultSelectionPolicy})
+	assert.False(t, ok)
+
+	now = now.Add(time.Minute)
+	_, ok = cache.get(key)
+	assert.False(t, ok, "entries expire after the TTL")
+	assert.Empty(t, cache.entries)
+}
+
+func TestResultCache_Full(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	cache := newResultCache(time.Minute)
+	cache.now = func() time.Time { return now }
+	for i := range maxCacheEntries {
+		cache.put(cacheKey{head: fmt.Sprint(i)}, &domain.ResolveOutput{})
+	}
+
+	// Full of live entries: one is dropped to make room
+	cache.put(cacheKey{head: "new"}, &domain.ResolveOutput{})
+	assert.Len(t, cache.entries, maxCacheEntries)
+	_, ok := cache.get(cacheKey{head: "new"})
+	assert.True(t, ok)
+
+	// Expired entries are all dropped
+	now = now.Add(time.Minute)
+	cache.put(cacheKey{head: "newer"}, &domain.ResolveOutput{})
+	assert.Len(t, cache.entries, 1)
+}
+
+func TestResultCache_StatsAndFlush(t *testing.T) {
+	cache := newResultCache(time.Minute)
+	key := cacheKey{repository: "org/repo", head: "c1"}
+
+	cache.get(key)
+	cache.put(key, &domain.ResolveOutput{CorrelationID: "slip-1"})
+	cache.get(key)
+	cache.get(key)
+
+	assert.Equal(t, CacheStats{Enabled: true, Entries: 1, Hits: 2, Misses: 1, TTL: time.Minute}, cache.stats())
+
+	assert.Equal(t, 1, cache.flush())
+	_, ok := cache.get(key)
+	assert.False(t, ok, "flushed entries are gone")
+	assert.Equal(t, CacheStats{Enabled: true, Hits: 2, Misses: 2, TTL: time.Minute}, cache.stats())
+}
@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// ConfigSetting is one effective configuration value together with its provenance.
+type ConfigSetting struct {
+	// Key is the dotted setting name (e.g. "clickhouse.hostname").
+	Key string
+
+	// Value is the effective value.
+	Value string
+
+	// Source is where the value came from: default, env, file, vault, or flag.
+	Source string
+
+	// Detail identifies the concrete origin (env var name, file path, Vault path).
+	Detail string
+
+	// Secret marks values that are redacted when displayed.
+	Secret bool
+}
+
+// redactedValue replaces secret values in human- and machine-readable output.
+const redactedValue = "********"
+
+// Config command flags.
+var (
+	showOrigins      bool
+	configShowFormat string
+)
+
+// newConfigCmd creates the "config" command group.
+func newConfigCmd(deps *Dependencies) *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the effective configuration",
+	}
+	configCmd.AddCommand(newConfigShowCmd(deps))
+	return configCmd
+}
+
+// newConfigShowCmd creates "config show", which prints the effective configuration.
+func newConfigShowCmd(deps *Dependencies) *cobra.Command {
+	showCmd := &cobra.Command{
+		Use:   "show",
+		Short: "Print the effective configuration (secrets redacted)",
+		Long: `Print the effective configuration after .env, environment, file, and Vault resolution.
+
+Secret values are always redacted. Use --origins to include the source
+that supplied each value, which makes precedence questions easy to answer.
+
+Examples:
+  slippy-find config show
+  slippy-find config show --origins
+  slippy-find config show --origins --format json`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runConfigShow(deps)
+		},
+	}
+
+	showCmd.Flags().BoolVar(&showOrigins, "origins", false,
+		"Include the source of each configuration value")
+	showCmd.Flags().StringVar(&configShowFormat, "format", "text",
+		"Output format: text or json")
+
+	return showCmd
+}
+
+// runConfigShow loads the configuration and writes it to stdout.
+func runConfigShow(deps *Dependencies) error {
+	if deps == nil {
+		return configError(errors.New("dependencies not configured"))
+	}
+
+	cfg, err := deps.ConfigLoader()
+	if err != nil {
+		return configError(fmt.Errorf("configuration error: %w", err))
+	}
+
+	stdout := deps.Stdout
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+
+	switch configShowFormat {
+	case "text":
+		return writeSettingsText(stdout, cfg.Settings, showOrigins)
+	case "json":
+		return writeSettingsJSON(stdout, cfg.Settings, showOrigins)
+	default:
+		return usageErrorf("unsupported format %q: expected text or json", configShowFormat)
+	}
+}
+
+// displayValue returns the value suitable for display, redacting secrets.
+func displayValue(s ConfigSetting) string {
+	if s.Secret && s.Value != "" {
+		return redactedValue
+	}
+	return s.Value
+}
+
+// describeOrigin formats a setting's provenance as "source (detail)".
+func describeOrigin(s ConfigSetting) string {
+	if s.Detail == "" {
+		return s.Source
+	}
+	return s.Source + " (" + s.Detail + ")"
+}
+
+// writeSettingsText writes settings as aligned columns.
+func writeSettingsText(w io.Writer, settings []ConfigSetting, origins bool) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, s := range settings {
+		var err error
+		if origins {
+			_, err = fmt.Fprintf(tw, "%s\t%s\t%s\n", s.Key, displayValue(s), describeOrigin(s))
+		} else {
+			_, err = fmt.Fprintf(tw, "%s\t%s\n", s.Key, displayValue(s))
+		}
+		if err != nil {
+			return outputError(err)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return outputError(err)
+	}
+	return nil
+}
+
+// settingJSON is the JSON representation of a ConfigSetting.
+type settingJSON struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// writeSettingsJSON writes settings as a JSON array.
+func writeSettingsJSON(w io.Writer, settings []ConfigSetting, origins bool) error {
+	out := make([]settingJSON, 0, len(settings))
+	for _, s := range settings {
+		entry := settingJSON{Key: s.Key, Value: displayValue(s)}
+		if origins {
+			entry.Source = s.Source
+			entry.Detail = s.Detail
+		}
+		out = append(out, entry)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		return outputError(err)
+	}
+	return nil
+}
+
+// applyFlagOverrides marks settings overridden by command-line flags.
+func applyFlagOverrides(settings []ConfigSetting) []ConfigSetting {
+	for i := range settings {
+		switch {
+		case settings[i].Key == "log_level" && verbose:
+			settings[i].Value = "debug"
+			settings[i].Source = "flag"
+			settings[i].Detail = "--verbose"
+		case settings[i].Key == "log_format" && logFormat != "":
+			settings[i].Value = logFormat
+			settings[i].Source = "flag"
+			settings[i].Detail = "--log-format"
+		case settings[i].Key == "log_file" && logFile != "":
+			settings[i].Value = logFile
+			settings[i].Source = "flag"
+			settings[i].Detail = "--log-file"
+		case settings[i].Key == "audit" && audit:
+			settings[i].Value = "true"
+			settings[i].Source = "flag"
+			settings[i].Detail = "--audit"
+		case settings[i].Key == "git_timeout" && gitTimeout > 0:
+			settings[i].Value = gitTimeout.String()
+			settings[i].Source = "flag"
+			settings[i].Detail = "--git-timeout"
+		case settings[i].Key == "store_timeout" && storeTimeout > 0:
+			settings[i].Value = storeTimeout.String()
+			settings[i].Source = "flag"
+			settings[i].Detail = "--store-timeout"
+		}
+	}
+	return settings
+}
+
+// settingOrigins summarizes settings as key -> "source (detail)" for debug logs.
+func settingOrigins(settings []ConfigSetting) map[string]interface{} {
+	origins := make(map[string]interface{}, len(settings))
+	for _, s := range settings {
+		origins[s.Key] = describeOrigin(s)
+	}
+	return origins
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSettings() []ConfigSetting {
+	return []ConfigSetting{
+		{Key: "clickhouse.hostname", Value: "ch.local", Source: "env", Detail: "CLICKHOUSE_HOSTNAME"},
+		{Key: "clickhouse.password", Value: "hunter2", Source: "file", Detail: ".env (CLICKHOUSE_PASSWORD)", Secret: true},
+		{Key: "database", Value: "ci", Source: "default"},
+		{Key: "log_level", Value: "info", Source: "default"},
+		{Key: "log_format", Value: "json", Source: "default"},
+	}
+}
+
+func runConfigShowCmd(t *testing.T, deps *Dependencies, args ...string) (string, error) {
+	t.Helper()
+	var stdout bytes.Buffer
+	deps.Stdout = &stdout
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs(append([]string{"config", "show"}, args...))
+	err := cmd.Execute()
+	return stdout.String(), err
+}
+
+func TestConfigShow_Text(t *testing.T) {
+	deps := &Dependencies{
+		ConfigLoader: func() (*AppConfig, error) { return &AppConfig{Settings: testSettings()}, nil },
+	}
+
+	out, err := runConfigShowCmd(t, deps)
+
+	require.NoError(t, err)
+	assert.Contains(t, out, "clickhouse.hostname")
+	assert.Contains(t, out, "ch.local")
+	assert.Contains(t, out, redactedValue)
+	assert.NotContains(t, out, "hunter2")
+	assert.NotContains(t, out, "CLICKHOUSE_HOSTNAME", "origins hidden without --origins")
+}
+
+func TestConfigShow_TextOrigins(t *testing.T) {
+	deps := &Dependencies{
+		ConfigLoader: func() (*AppConfig, error) { return &AppConfig{Settings: testSettings()}, nil },
+	}
+
+	out, err := runConfigShowCmd(t, deps, "--origins")
+
+	require.NoError(t, err)
+	assert.Contains(t, out, "env (CLICKHOUSE_HOSTNAME)")
+	assert.Contains(t, out, "file (.env (CLICKHOUSE_PASSWORD))")
+	assert.Contains(t, out, "default")
+	assert.NotContains(t, out, "hunter2")
+}
+
+func TestConfigShow_JSONOrigins(t *testing.T) {
+	deps := &Dependencies{
+		ConfigLoader: func() (*AppConfig, error) { return &AppConfig{Settings: testSettings()}, nil },
+	}
+
+	out, err := runConfigShowCmd(t, deps, "--origins", "--format", "json")
+	require.NoError(t, err)
+
+	var settings []map[string]string
+	require.NoError(t, json.Unmarshal([]byte(out), &settings))
+	require.Len(t, settings, 5)
+	assert.Equal(t, "clickhouse.hostname", settings[0]["key"])
+	assert.Equal(t, "env", settings[0]["source"])
+	assert.Equal(t, redactedValue, settings[1]["value"])
+}
+
+func TestConfigShow_InvalidFormat(t *testing.T) {
+	deps := &Dependencies{
+		ConfigLoader: func() (*AppConfig, error) { return &AppConfig{}, nil },
+	}
+
+	_, err := runConfigShowCmd(t, deps, "--format", "yaml")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported format")
+}
+
+func TestConfigShow_ConfigError(t *testing.T) {
+	deps := &Dependencies{
+		ConfigLoader: func() (*AppConfig, error) { return nil, errors.New("vault down") },
+	}
+
+	_, err := runConfigShowCmd(t, deps)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "configuration error")
+}
+
+func TestConfigShow_NilDependencies(t *testing.T) {
+	err := runConfigShow(nil)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "dependencies not configured")
+}
+
+func TestApplyFlagOverrides(t *testing.T) {
+	verbose = true
+	defer func() { verbose = false }()
+
+	settings := applyFlagOverrides(testSettings())
+
+	assert.Equal(t, ConfigSetting{Key: "log_level", Value: "debug", Source: "flag", Detail: "--verbose"}, settings[3])
+	assert.Equal(t, ConfigSetting{Key: "log_format", Value: "json", Source: "default"}, settings[4])
+}
+
+func TestApplyFlagOverrides_LogFormat(t *testing.T) {
+	logFormat = "console"
+	defer func() { logFormat = "" }()
+
+	settings := applyFlagOverrides(testSettings())
+
+	assert.Equal(t, ConfigSetting{Key: "log_level", Value: "info", Source: "default"}, settings[3])
+	assert.Equal(t,
+		ConfigSetting{Key: "log_format", Value: "console", Source: "flag", Detail: "--log-format"}, settings[4])
+}
+
+func TestApplyFlagOverrides_LogFile(t *testing.T) {
+	logFile = "/var/log/slippy-find.log"
+	defer func() { logFile = "" }()
+
+	settings := applyFlagOverrides([]ConfigSetting{{Key: "log_file", Source: "default"}})
+
+	assert.Equal(t,
+		ConfigSetting{Key: "log_file", Value: "/var/log/slippy-find.log", Source: "flag", Detail: "--log-file"},
+		settings[0])
+}
+
+func TestApplyFlagOverrides_Audit(t *testing.T) {
+	audit = true
+	defer func() { audit = false }()
+
+	settings := applyFlagOverrides([]ConfigSetting{{Key: "audit", Value: "false", Source: "default"}})
+
+	assert.Equal(t, ConfigSetting{Key: "audit", Value: "true", Source: "flag", Detail: "--audit"}, settings[0])
+}
+
+func TestSettingOrigins(t *testing.T) {
+	origins := settingOrigins(testSettings())
+
+	assert.Equal(t, "env (CLICKHOUSE_HOSTNAME)", origins["clickhouse.hostname"])
+	assert.Equal(t, "default", origins["database"])
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// EnvCrashReport names a file the crash report of a panicking run is also
+// written to, for runners that keep files but truncate logs.
+const EnvCrashReport = "SLIPPY_CRASH_REPORT"
+
+// PanicError is a panic recovered from a command. Execute writes it as a
+// crash report and exits with ExitCodePanic.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value any
+
+	// Stack is the panicking goroutine's stack, as from debug.Stack.
+	Stack []byte
+
+	// Settings are the command's configuration settings, if it had loaded
+	// them; secret values are redacted in the report.
+	Settings []ConfigSetting
+}
+
+// newPanicError records the panic r, with the stack of the panicking
+// goroutine. It must be called by the function that recovered r.
+func newPanicError(r any, settings []ConfigSetting) *PanicError {
+	return &PanicError{Value: r, Stack: debug.Stack(), Settings: settings}
+}
+
+// Error implements error.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("unexpected panic: %v", e.Value)
+}
+
+// crashReportJSON is the crash report written for a PanicError.
+type crashReportJSON struct {
+	Panic     string        `json:"panic"`
+	Version   string        `json:"version"`
+	GoVersion string        `json:"go_version"`
+	Platform  string        `json:"platform"`
+	Command   string        `json:"command,omitempty"`
+	Flags     []string      `json:"flags,omitempty"`
+	Config    []settingJSON `json:"config,omitempty"`
+	Stack     string        `json:"stack"`
+	WrittenAt time.Time     `json:"written_at"`
+}
+
+// newCrashReportJSON describes e, which panicked in the command at
+// commandPath run with args. Only the names of the flags in args are kept,
+// since their values and the positional arguments may hold credentials.
+func newCrashReportJSON(e *PanicError, commandPath string, args []string) crashReportJSON {
+	report := crashReportJSON{
+		Panic:     fmt.Sprint(e.Value),
+		Version:   Version,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		Command:   commandPath,
+		Stack:     string(e.Stack),
+		WrittenAt: time.Now().UTC(),
+	}
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if len(arg) > 1 && arg[0] == '-' {
+			name, _, _ := strings.Cut(arg, "=")
+			report.Flags = append(report.Flags, name)
+		}
+	}
+	for _, s := range e.Settings {
+		report.Config = append(report.Config, settingJSON{Key: s.Key, Value: displayValue(s), Source: s.Source})
+	}
+	return report
+}
+
+// writeCrashReport writes the crash report of e to stderr and, when
+// EnvCrashReport is set, to that file. Best-effort: the run fails either way.
+func writeCrashReport(stderr io.Writer, e *PanicError, commandPath string, args []string) {
+	data, err := json.MarshalIndent(newCrashReportJSON(e, commandPath, args), "", "  ")
+	if err != nil {
+		writeWarningf(stderr, "slippy-find crashed: %v\n%s\n", e.Value, e.Stack)
+		return
+	}
+	writeWarningf(stderr, "slippy-find crashed; include this report when filing an issue:\n%s\n", data)
+	if path := os.Getenv(EnvCrashReport); path != "" {
+		if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+			writeWarningf(stderr, "warning: could not write crash report: %v\n", err)
+			return
+		}
+		writeWarningf(stderr, "crash report written to %s\n", path)
+	}
+}
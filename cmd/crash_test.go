@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteRecovered_Panic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crash.json")
+	t.Setenv(EnvCrashReport, path)
+	root := &cobra.Command{Use: "slippy-find"}
+	root.AddCommand(&cobra.Command{
+		Use: "serve",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			panic("nil map")
+		},
+	})
+	root.PersistentFlags().String("token", "", "")
+	var stderr bytes.Buffer
+
+	err := executeRecovered(context.Background(), root, []string{"serve", "--token=s3cret"}, &stderr)
+
+	var panicErr *PanicError
+	require.ErrorAs(t, err, &panicErr)
+	assert.Equal(t, "unexpected panic: nil map", err.Error())
+	assert.Equal(t, ExitCodePanic, ExitCode(err))
+	assert.NotContains(t, stderr.String(), "s3cret")
+	assert.Contains(t, stderr.String(), "crash report written to "+path)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var report crashReportJSON
+	require.NoError(t, json.Unmarshal(data, &report))
+	assert.Equal(t, "nil map", report.Panic)
+	assert.Equal(t, Version, report.Version)
+	assert.Equal(t, "slippy-find serve", report.Command)
+	assert.Equal(t, []string{"--token"}, report.Flags)
+	assert.Contains(t, report.Stack, "TestExecuteRecovered_Panic")
+}
+
+func TestExecuteRecovered_NoPanic(t *testing.T) {
+	root := &cobra.Command{Use: "slippy-find", RunE: func(_ *cobra.Command, _ []string) error { return nil }}
+	var stderr bytes.Buffer
+
+	require.NoError(t, executeRecovered(context.Background(), root, nil, &stderr))
+	assert.Empty(t, stderr.String())
+}
+
+func TestNewCrashReportJSON_RedactsSecrets(t *testing.T) {
+	err := newPanicError("boom", []ConfigSetting{
+		{Key: "clickhouse_password", Value: "hunter22", Source: "env", Secret: true},
+		{Key: "database", Value: "ci", Source: "default"},
+	})
+
+	report := newCrashReportJSON(err, "slippy-find", []string{".", "-d", "50", "--", "--not-a-flag"})
+
+	assert.Equal(t, []settingJSON{
+		{Key: "clickhouse_password", Value: redactedValue, Source: "env"},
+		{Key: "database", Value: "ci", Source: "default"},
+	}, report.Config)
+	assert.Equal(t, []string{"-d"}, report.Flags)
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Daemonize command flags.
+var (
+	daemonizeSocket    string
+	daemonizeRepoRoots []string
+	daemonizeCacheTTL  time.Duration
+	daemonizeWait      time.Duration
+	daemonizeVerbose   bool
+)
+
+// daemonPollInterval is how often daemonize checks whether the daemon it
+// started has begun accepting connections.
+const daemonPollInterval = 50 * time.Millisecond
+
+// newDaemonizeCmd creates "daemonize", which starts a resident serve-mode
+// server on a unix socket for later runs on the same host to delegate to.
+func newDaemonizeCmd(deps *Dependencies) *cobra.Command {
+	daemonizeCmd := &cobra.Command{
+		Use:   "daemonize",
+		Short: "Start a resident server that later runs on this host resolve through",
+		Long: `Start slippy-find serve in the background on a unix socket, and print the
+SLIPPY_DAEMON setting that makes later runs resolve through it.
+
+The resident server keeps the slip store connection open and caches results
+for --cache-ttl, so runs after the first skip loading configuration and
+connecting to the store, and repeated resolutions of the same commit are
+answered from memory. Runs that cannot use the daemon resolve directly (see
+SLIPPY_DAEMON in the README).
+
+The command prints a shell assignment, so a runner's setup script can start
+the daemon and export its address in one step:
+
+  eval "$(slippy-find daemonize --repo-root /builds)"
+
+If a daemon already answers on --socket, it is reused rather than replaced,
+so the command can run at the start of every job. The daemon logs to the
+socket path with ".log" appended, and runs until it is sent SIGTERM.
+
+Examples:
+  eval "$(slippy-find daemonize --repo-root /builds)"
+  slippy-find daemonize --repo-root /builds --socket /var/run/slippy-find.sock --cache-ttl 30m`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runDaemonize(cmd, deps)
+		},
+	}
+
+	daemonizeCmd.Flags().StringVar(&daemonizeSocket, "socket", defaultDaemonSocket(),
+		"Unix socket the daemon listens on")
+	daemonizeCmd.Flags().StringArrayVar(&daemonizeRepoRoots, "repo-root", nil,
+		"Directory whose repositories the daemon may resolve (repeatable; required)")
+	daemonizeCmd.Flags().DurationVar(&daemonizeCacheTTL, "cache-ttl", 10*time.Minute,
+		"How long the daemon caches successful results (0 disables caching)")
+	daemonizeCmd.Flags().DurationVar(&daemonizeWait, "wait", 15*time.Second,
+		"How long to wait for the daemon to accept connections")
+	daemonizeCmd.Flags().BoolVarP(&daemonizeVerbose, "verbose", "v", false,
+		"Enable verbose/debug logging in the daemon")
+
+	return daemonizeCmd
+}
+
+// defaultDaemonSocket returns the per-user socket daemonize listens on by
+// default: in XDG_RUNTIME_DIR when set, or else the temporary directory.
+func defaultDaemonSocket() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "slippy-find.sock")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("slippy-find-%d.sock", os.Getuid()))
+}
+
+// runDaemonize starts the daemon, unless one already answers on the socket,
+// and prints the SLIPPY_DAEMON assignment once it accepts connections.
+func runDaemonize(cmd *cobra.Command, deps *Dependencies) error {
+	if deps == nil || deps.DaemonStarter == nil {
+		return configError(errors.New("daemon mode not configured"))
+	}
+	if len(daemonizeRepoRoots) == 0 {
+		return usageErrorf("--repo-root is required: the daemon resolves only repositories under it")
+	}
+	if daemonizeCacheTTL < 0 {
+		return usageErrorf("--cache-ttl cannot be negative")
+	}
+	if daemonizeWait <= 0 {
+		return usageErrorf("--wait must be positive")
+	}
+	socket, err := filepath.Abs(daemonizeSocket)
+	if err != nil {
+		return usageErrorf("invalid --socket %q: %v", daemonizeSocket, err)
+	}
+	roots, err := serveRoots(daemonizeRepoRoots)
+	if err != nil {
+		return err
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	log := deps.LoggerFactory()
+
+	if daemonListening(socket) {
+		log.Info(ctx, "daemon already running", map[string]interface{}{
+			"socket": socket,
+		})
+		return printDaemonAddress(deps, socket)
+	}
+
+	args := []string{"serve", "--unix", socket, "--cache-ttl", daemonizeCacheTTL.String()}
+	for _, root := range roots {
+		args = append(args, "--repo-root", root)
+	}
+	if daemonizeVerbose {
+		args = append(args, "--verbose")
+	}
+	logPath := socket + ".log"
+	pid, err := deps.DaemonStarter(args, logPath)
+	if err != nil {
+		return configError(fmt.Errorf("failed to start daemon: %w", err))
+	}
+
+	if err := waitForDaemon(ctx, socket, daemonizeWait); err != nil {
+		return fmt.Errorf("daemon (pid %d) did not start, see %s: %w", pid, logPath, err)
+	}
+	log.Info(ctx, "daemon started", map[string]interface{}{
+		"socket": socket,
+		"pid":    pid,
+		"log":    logPath,
+	})
+	return printDaemonAddress(deps, socket)
+}
+
+// daemonListening reports whether a server accepts connections on socket.
+func daemonListening(socket string) bool {
+	conn, err := net.DialTimeout("unix", socket, time.Second)
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}
+
+// waitForDaemon polls socket until a server accepts connections on it, for
+// at most wait.
+func waitForDaemon(ctx context.Context, socket string, wait time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+
+	ticker := time.NewTicker(daemonPollInterval)
+	defer ticker.Stop()
+	for !daemonListening(socket) {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("no connection accepted on %s within %s", socket, wait)
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// printDaemonAddress prints the shell assignment exporting socket as SLIPPY_DAEMON.
+func printDaemonAddress(deps *Dependencies, socket string) error {
+	_, err := fmt.Fprintf(commandStdout(deps), "export SLIPPY_DAEMON=%s\n", shellQuote(socket))
+	return err
+}
+
+// shellQuote quotes s for a POSIX shell, unless it holds only characters
+// that are safe unquoted.
+func shellQuote(s string) string {
+	unsafe := func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && !strings.ContainsRune("/._-+:,@%", r)
+	}
+	if s != "" && strings.IndexFunc(s, unsafe) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// daemonSocket returns a socket path short enough for unix socket limits,
+// which t.TempDir can exceed.
+func daemonSocket(t *testing.T) string {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "sfd")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	return filepath.Join(dir, "d.sock")
+}
+
+// listenDaemon serves socket until the test ends, standing in for a daemon.
+func listenDaemon(t *testing.T, socket string) {
+	t.Helper()
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+}
+
+func runDaemonizeCmd(t *testing.T, deps *Dependencies, args ...string) (string, error) {
+	t.Helper()
+	var stdout bytes.Buffer
+	deps.Stdout = &stdout
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs(append([]string{"daemonize"}, args...))
+	err := cmd.Execute()
+	return stdout.String(), err
+}
+
+func TestDaemonizeCmd_StartsDaemon(t *testing.T) {
+	socket := daemonSocket(t)
+	root := t.TempDir()
+	wantRoot, err := filepath.EvalSymlinks(root)
+	require.NoError(t, err)
+
+	var started []string
+	var logPath string
+	deps := allTestDeps(&mockResolver{})
+	deps.DaemonStarter = func(args []string, log string) (int, error) {
+		started, logPath = args, log
+		listenDaemon(t, socket)
+		return 42, nil
+	}
+
+	out, err := runDaemonizeCmd(t, deps, "--socket", socket, "--repo-root", root, "--verbose")
+
+	require.NoError(t, err)
+	assert.Equal(t, "export SLIPPY_DAEMON="+socket+"\n", out)
+	assert.Equal(t, []string{
+		"serve", "--unix", socket, "--cache-ttl", "10m0s", "--repo-root", wantRoot, "--verbose",
+	}, started)
+	assert.Equal(t, socket+".log", logPath)
+}
+
+func TestDaemonizeCmd_ReusesRunningDaemon(t *testing.T) {
+	socket := daemonSocket(t)
+	listenDaemon(t, socket)
+
+	deps := allTestDeps(&mockResolver{})
+	deps.DaemonStarter = func([]string, string) (int, error) {
+		t.Fatal("a daemon was started while one was running")
+		return 0, nil
+	}
+
+	out, err := runDaemonizeCmd(t, deps, "--socket", socket, "--repo-root", t.TempDir())
+
+	require.NoError(t, err)
+	assert.Equal(t, "export SLIPPY_DAEMON="+socket+"\n", out)
+}
+
+func TestDaemonizeCmd_Failures(t *testing.T) {
+	notListening := func([]string, string) (int, error) { return 7, nil }
+
+	tests := []struct {
+		name    string
+		starter func([]string, string) (int, error)
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "no starter",
+			args:    []string{"--repo-root", "."},
+			wantErr: "daemon mode not configured",
+		},
+		{
+			name:    "no repo root",
+			starter: notListening,
+			wantErr: "--repo-root is required",
+		},
+		{
+			name:    "negative cache ttl",
+			starter: notListening,
+			args:    []string{"--repo-root", ".", "--cache-ttl", "-1s"},
+			wantErr: "--cache-ttl cannot be negative",
+		},
+		{
+			name:    "start fails",
+			starter: func([]string, string) (int, error) { return 0, errors.New("exec format error") },
+			args:    []string{"--repo-root", "."},
+			wantErr: "failed to start daemon: exec format error",
+		},
+		{
+			name:    "never listens",
+			starter: notListening,
+			args:    []string{"--repo-root", ".", "--wait", "100ms"},
+			wantErr: "daemon (pid 7) did not start, see ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := allTestDeps(&mockResolver{})
+			deps.DaemonStarter = tt.starter
+
+			out, err := runDaemonizeCmd(t, deps, append([]string{"--socket", daemonSocket(t)}, tt.args...)...)
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+			assert.Empty(t, out)
+		})
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, "/run/user/1000/slippy-find.sock", shellQuote("/run/user/1000/slippy-find.sock"))
+	assert.Equal(t, "'/tmp/my sockets/d.sock'", shellQuote("/tmp/my sockets/d.sock"))
+	assert.Equal(t, `'/tmp/it'\''s.sock'`, shellQuote("/tmp/it's.sock"))
+	assert.Equal(t, "''", shellQuote(""))
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// environmentJSON is the JSON representation of a domain.Environment.
+type environmentJSON struct {
+	GitBackend    string `json:"git_backend,omitempty"`
+	Shallow       bool   `json:"shallow"`
+	PartialClone  bool   `json:"partial_clone"`
+	AncestryCache string `json:"ancestry_cache,omitempty"`
+	Store         string `json:"store,omitempty"`
+}
+
+// newEnvironmentJSON converts env to its JSON representation; nil stays nil.
+func newEnvironmentJSON(env *domain.Environment) *environmentJSON {
+	if env == nil {
+		return nil
+	}
+	return &environmentJSON{
+		GitBackend:    env.GitBackend,
+		Shallow:       env.Shallow,
+		PartialClone:  env.PartialClone,
+		AncestryCache: env.AncestryCache,
+		Store:         env.Store,
+	}
+}
+
+// fingerprinting reports whether the run reports its environment
+// fingerprint: with --verbose, in the log, and with --format json, in the
+// output.
+func fingerprinting() bool {
+	return verbose || resolveFormat == "json" || allFormat == "json"
+}
+
+// describeEnvironment starts the environment fingerprint of s with its
+// repository, before the resolution walks it, so the ancestry cache is
+// reported as the resolution found it.
+func (s *session) describeEnvironment() {
+	if !fingerprinting() {
+		return
+	}
+	s.env = &domain.Environment{}
+	if describer, ok := s.gitRepo.(domain.EnvironmentDescriber); ok {
+		describer.DescribeEnvironment(s.env)
+	}
+}
+
+// environment completes the environment fingerprint of s with its store,
+// waiting for the store to open, and logs it at debug level the first time.
+// Returns nil for a nil session, if the run reports no fingerprint, or if
+// neither the repository nor the store described itself.
+func (s *session) environment() *domain.Environment {
+	if s == nil || s.env == nil {
+		return nil
+	}
+	if !s.envLogged {
+		s.completeEnvironment()
+	}
+	if *s.env == (domain.Environment{}) {
+		return nil
+	}
+	return s.env
+}
+
+// storeBackend returns the type of the store s opened, one of the
+// domain.Store constants, waiting for the open. Returns "" for a nil session
+// or a store that failed to open or does not describe itself.
+func (s *session) storeBackend() string {
+	if s == nil || s.store == nil {
+		return ""
+	}
+	var env domain.Environment
+	s.store.DescribeEnvironment(&env)
+	return env.Store
+}
+
+// completeEnvironment adds the store to the environment fingerprint of s and
+// logs it.
+func (s *session) completeEnvironment() {
+	if s.store != nil {
+		s.store.DescribeEnvironment(s.env)
+	}
+	s.envLogged = true
+	s.log.Debug(s.ctx, "environment fingerprint", map[string]interface{}{
+		"git_backend":    s.env.GitBackend,
+		"shallow":        s.env.Shallow,
+		"partial_clone":  s.env.PartialClone,
+		"ancestry_cache": s.env.AncestryCache,
+		"store":          s.env.Store,
+	})
+}
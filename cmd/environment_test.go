@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// describingGitRepo is a mockGitRepo that describes itself as a shallow
+// go-git checkout.
+type describingGitRepo struct {
+	mockGitRepo
+	calls int
+}
+
+func (g *describingGitRepo) DescribeEnvironment(env *domain.Environment) {
+	g.calls++
+	env.GitBackend = domain.GitBackendGoGit
+	env.Shallow = true
+	env.AncestryCache = domain.AncestryCacheStale
+}
+
+// describingFinder is a mockSlipFinder that describes itself as the mock store.
+type describingFinder struct {
+	mockSlipFinder
+}
+
+func (f *describingFinder) DescribeEnvironment(env *domain.Environment) {
+	env.Store = domain.StoreMock
+}
+
+// environmentTestDeps returns allTestDeps with a describing repository and
+// finder.
+func environmentTestDeps(resolver *mockResolver) (*Dependencies, *describingGitRepo) {
+	deps := allTestDeps(resolver)
+	repo := &describingGitRepo{}
+	deps.GitRepoFactory = func(_ string, _ Logger) (domain.LocalGitRepository, error) {
+		return repo, nil
+	}
+	deps.SlipFinderFactory = func(_ *AppConfig, _ Logger) (domain.SlipFinder, error) {
+		return &describingFinder{}, nil
+	}
+	return deps, repo
+}
+
+func TestRootCmd_FormatJSON_Environment(t *testing.T) {
+	wantEnv := map[string]any{
+		"git_backend":    "go-git",
+		"shallow":        true,
+		"partial_clone":  false,
+		"ancestry_cache": "stale",
+		"store":          "mock",
+	}
+	tests := []struct {
+		name     string
+		resolver *mockResolver
+	}{
+		{name: "result", resolver: &mockResolver{output: &domain.ResolveOutput{CorrelationID: "slip-1"}}},
+		{name: "error", resolver: &mockResolver{err: domain.ErrNoAncestorSlip}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			deps, _ := environmentTestDeps(tt.resolver)
+			deps.Stdout = &stdout
+
+			cmd := NewRootCmdWithDeps(deps)
+			cmd.SetArgs([]string{".", "--format", "json"})
+			_ = cmd.Execute()
+
+			var doc map[string]any
+			require.NoError(t, json.Unmarshal(stdout.Bytes(), &doc))
+			assert.Equal(t, wantEnv, doc["environment"])
+		})
+	}
+}
+
+func TestRootCmd_Environment_NotCollected(t *testing.T) {
+	deps, repo := environmentTestDeps(&mockResolver{output: &domain.ResolveOutput{CorrelationID: "slip-1"}})
+	deps.OutputWriterFactory = func() domain.OutputWriter { return &mockOutputWriter{} }
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{"."})
+	require.NoError(t, cmd.Execute())
+
+	assert.Zero(t, repo.calls, "text output without --verbose reports no fingerprint")
+}
+
+func TestAllCmd_FormatJSON_Environment(t *testing.T) {
+	deps, _ := environmentTestDeps(&mockResolver{allOutput: testAllOutput()})
+
+	out, err := runAllCmd(t, deps, "--format", "json")
+
+	require.NoError(t, err)
+	var doc candidatesJSON
+	require.NoError(t, json.Unmarshal([]byte(out), &doc))
+	assert.Equal(t, &environmentJSON{
+		GitBackend:    domain.GitBackendGoGit,
+		Shallow:       true,
+		AncestryCache: domain.AncestryCacheStale,
+		Store:         domain.StoreMock,
+	}, doc.Environment)
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// usageErrorf reports a malformed flag or argument.
+func usageErrorf(format string, args ...any) error {
+	return domain.WithCode(domain.CodeInvalidInput, fmt.Errorf(format, args...))
+}
+
+// configError reports configuration that could not be loaded or is incomplete.
+func configError(err error) error {
+	return domain.WithCode(domain.CodeConfiguration, err)
+}
+
+// outputError reports a failure to write the command's result.
+func outputError(err error) error {
+	return domain.WithCode(domain.CodeOutput, fmt.Errorf("output error: %w", err))
+}
+
+// reportFailure sends err to deps.ErrorReporter if its code is unexpected,
+// tagged with the command, error code, search depth, (once the repository is
+// open) repository name, and (once the store is open) store backend. s may be
+// nil if the session never opened.
+func reportFailure(ctx context.Context, cmd *cobra.Command, deps *Dependencies, s *session, err error) {
+	code := domain.CodeOf(err)
+	if deps == nil || deps.ErrorReporter == nil || !code.Unexpected() {
+		return
+	}
+
+	tags := map[string]string{
+		"command":    cmd.Name(),
+		"error_code": string(code),
+		"depth":      strconv.Itoa(depth),
+	}
+	if s != nil && s.gitRepo != nil {
+		if gitCtx, ctxErr := s.gitRepo.GetGitContext(ctx); ctxErr == nil && gitCtx != nil {
+			tags["repository"] = gitCtx.Repository
+		}
+	}
+	if backend := s.storeBackend(); backend != "" {
+		tags["backend"] = backend
+	}
+
+	if reportErr := deps.ErrorReporter.Report(ctx, err, tags); reportErr != nil && s != nil {
+		s.log.Warn(ctx, "failed to report error", map[string]interface{}{
+			"error": reportErr.Error(),
+		})
+	}
+}
+
+// notifyFailure sends err to deps.FailureNotifier if it points to a systemic
+// problem: no slip in the ancestry, or a store failure. The repository,
+// branch, and HEAD are included once the repository is open. s may be nil if
+// the session never opened.
+func notifyFailure(ctx context.Context, deps *Dependencies, s *session, err error) {
+	code := domain.CodeOf(err)
+	if deps == nil || deps.FailureNotifier == nil || (code != domain.CodeNoSlip && code != domain.CodeStore) {
+		return
+	}
+
+	failure := domain.ResolutionFailure{Component: component, Code: code, Error: err.Error()}
+	if s != nil && s.gitRepo != nil {
+		if gitCtx, ctxErr := s.gitRepo.GetGitContext(ctx); ctxErr == nil && gitCtx != nil {
+			failure.Repository, failure.Branch, failure.HeadSHA = gitCtx.Repository, gitCtx.Branch, gitCtx.HeadSHA
+		}
+	}
+
+	if notifyErr := deps.FailureNotifier.Notify(ctx, failure); notifyErr != nil && s != nil {
+		s.log.Warn(ctx, "failed to send failure notification", map[string]interface{}{
+			"error": notifyErr.Error(),
+		})
+	}
+}
+
+// errorJSON is the JSON document written in place of a result when a command
+// run with --format json fails.
+type errorJSON struct {
+	SchemaVersion string           `json:"schema_version"`
+	Error         string           `json:"error"`
+	Code          string           `json:"code"`
+	Environment   *environmentJSON `json:"environment,omitempty"`
+}
+
+// commandStdout returns the writer for command results: deps.Stdout, or
+// os.Stdout when unset.
+func commandStdout(deps *Dependencies) io.Writer {
+	if deps == nil || deps.Stdout == nil {
+		return os.Stdout
+	}
+	return deps.Stdout
+}
+
+// resultStdout returns the writer for resolution results: commandStdout,
+// ending lines with CRLF for --crlf.
+func resultStdout(deps *Dependencies) io.Writer {
+	if crlfOutput {
+		return &crlfWriter{w: commandStdout(deps)}
+	}
+	return commandStdout(deps)
+}
+
+// crlfWriter writes to w with every LF line ending turned into CRLF. Line
+// endings that are already CRLF are kept.
+type crlfWriter struct {
+	w io.Writer
+
+	// cr is whether the last byte written was a carriage return, so a CRLF
+	// split across writes is not doubled.
+	cr bool
+}
+
+// Write implements io.Writer, reporting the bytes of p written.
+func (c *crlfWriter) Write(p []byte) (int, error) {
+	out := make([]byte, 0, len(p)+bytes.Count(p, []byte{'\n'}))
+	for _, b := range p {
+		if b == '\n' && !c.cr {
+			out = append(out, '\r')
+		}
+		out = append(out, b)
+		c.cr = b == '\r'
+	}
+	if _, err := c.w.Write(out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// writeErrorJSON writes err and its domain.ErrorCode, with env if it is not
+// nil, as a single-line JSON object. Best-effort: the command's error is
+// reported either way.
+func writeErrorJSON(w io.Writer, err error, env *domain.Environment) {
+	_ = json.NewEncoder(w).Encode(errorJSON{
+		SchemaVersion: SchemaVersion,
+		Error:         err.Error(),
+		Code:          string(domain.CodeOf(err)),
+		Environment:   newEnvironmentJSON(env),
+	})
+}
@@ -0,0 +1,403 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+func TestRootCmd_ErrorCodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		setup    func(deps *Dependencies)
+		wantCode domain.ErrorCode
+	}{
+		{
+			name: "not a repository",
+			setup: func(deps *Dependencies) {
+				deps.GitRepoFactory = func(path string, _ Logger) (domain.LocalGitRepository, error) {
+					return nil, fmt.Errorf("%w: %s", domain.ErrRepositoryNotFound, path)
+				}
+			},
+			wantCode: domain.CodeNotARepository,
+		},
+		{
+			name: "no origin",
+			setup: func(deps *Dependencies) {
+				deps.ResolverFactory = func(domain.LocalGitRepository, domain.SlipFinder, Logger) domain.Resolver {
+					return &mockResolver{err: fmt.Errorf("failed to get git context: %w", domain.ErrNoRemoteOrigin)}
+				}
+			},
+			wantCode: domain.CodeNoRemoteOrigin,
+		},
+		{
+			name: "no slip",
+			setup: func(deps *Dependencies) {
+				deps.ResolverFactory = func(domain.LocalGitRepository, domain.SlipFinder, Logger) domain.Resolver {
+					return &mockResolver{err: domain.ErrNoAncestorSlip}
+				}
+			},
+			wantCode: domain.CodeNoSlip,
+		},
+		{
+			name:     "invalid flag",
+			args:     []string{"--since", "yesterday"},
+			wantCode: domain.CodeInvalidInput,
+		},
+		{
+			name: "configuration",
+			setup: func(deps *Dependencies) {
+				deps.ConfigLoader = func() (*AppConfig, error) { return nil, errors.New("vault down") }
+			},
+			wantCode: domain.CodeConfiguration,
+		},
+		{
+			name: "store connection",
+			setup: func(deps *Dependencies) {
+				deps.SlipFinderFactory = func(*AppConfig, Logger) (domain.SlipFinder, error) {
+					return nil, errors.New("connection refused")
+				}
+			},
+			wantCode: domain.CodeStore,
+		},
+		{
+			name: "unclassified",
+			setup: func(deps *Dependencies) {
+				deps.ResolverFactory = func(domain.LocalGitRepository, domain.SlipFinder, Logger) domain.Resolver {
+					return &mockResolver{err: errors.New("boom")}
+				}
+			},
+			wantCode: domain.CodeUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			deps := allTestDeps(&mockResolver{})
+			deps.Stdout = &stdout
+			if tt.setup != nil {
+				tt.setup(deps)
+			}
+
+			cmd := NewRootCmdWithDeps(deps)
+			cmd.SetArgs(append([]string{".", "--format", "json"}, tt.args...))
+			err := cmd.Execute()
+
+			require.Error(t, err)
+			assert.Equal(t, tt.wantCode, domain.CodeOf(err))
+
+			var doc errorJSON
+			require.NoError(t, json.Unmarshal(stdout.Bytes(), &doc))
+			assert.Equal(t, err.Error(), doc.Error)
+			assert.Equal(t, string(tt.wantCode), doc.Code)
+		})
+	}
+}
+
+func TestRootCmd_ErrorTextFormat(t *testing.T) {
+	var stdout bytes.Buffer
+	deps := allTestDeps(&mockResolver{err: domain.ErrNoAncestorSlip})
+	deps.Stdout = &stdout
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{"."})
+	err := cmd.Execute()
+
+	require.Error(t, err)
+	assert.Equal(t, domain.CodeNoSlip, domain.CodeOf(err))
+	assert.Empty(t, stdout.String(), "text output must stay empty on failure")
+}
+
+func TestAllCmd_ErrorJSON(t *testing.T) {
+	out, err := runAllCmd(t, allTestDeps(&mockResolver{err: domain.ErrNoAncestorSlip}), "--format", "json")
+
+	require.Error(t, err)
+	assert.JSONEq(t, `{"schema_version": "1.4", "error": "no slip found in commit ancestry", "code": "SLIPPY_E003"}`, out)
+}
+
+func TestCodeOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want domain.ErrorCode
+	}{
+		{name: "nil", err: nil, want: ""},
+		{name: "unclassified", err: errors.New("boom"), want: domain.CodeUnknown},
+		{name: "wrapped sentinel", err: fmt.Errorf("walk: %w", domain.ErrCommitNotFound), want: domain.CodeCommitNotFound},
+		{
+			name: "sentinel beats attached code",
+			err:  domain.WithCode(domain.CodeStore, fmt.Errorf("x: %w", domain.ErrInvalidHint)),
+			want: domain.CodeInvalidHint,
+		},
+		{
+			name: "innermost attached code wins",
+			err:  domain.WithCode(domain.CodeStore, fmt.Errorf("search: %w", domain.WithCode(domain.CodeGit, errors.New("io")))),
+			want: domain.CodeGit,
+		},
+		{name: "output", err: outputError(errors.New("broken pipe")), want: domain.CodeOutput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, domain.CodeOf(tt.err))
+		})
+	}
+}
+
+// mockErrorReporter records reported failures.
+type mockErrorReporter struct {
+	errs []error
+	tags []map[string]string
+	err  error
+}
+
+func (m *mockErrorReporter) Report(_ context.Context, err error, tags map[string]string) error {
+	m.errs = append(m.errs, err)
+	m.tags = append(m.tags, tags)
+	return m.err
+}
+
+func TestRootCmd_ReportsUnexpectedFailures(t *testing.T) {
+	tests := []struct {
+		name       string
+		setup      func(deps *Dependencies)
+		wantReport map[string]string
+	}{
+		{
+			name: "store failure before the repository is described",
+			setup: func(deps *Dependencies) {
+				deps.SlipFinderFactory = func(*AppConfig, Logger) (domain.SlipFinder, error) {
+					return nil, errors.New("connection refused")
+				}
+			},
+			wantReport: map[string]string{"command": "slippy-find", "error_code": "SLIPPY_E014", "depth": "25"},
+		},
+		{
+			name: "unclassified resolver failure",
+			setup: func(deps *Dependencies) {
+				deps.GitRepoFactory = func(string, Logger) (domain.LocalGitRepository, error) {
+					return &mockGitRepo{gitContext: &domain.GitContext{Repository: "org/repo"}}, nil
+				}
+				deps.ResolverFactory = func(domain.LocalGitRepository, domain.SlipFinder, Logger) domain.Resolver {
+					return &mockResolver{err: errors.New("boom")}
+				}
+			},
+			wantReport: map[string]string{
+				"command":    "slippy-find",
+				"error_code": "SLIPPY_E000",
+				"depth":      "25",
+				"repository": "org/repo",
+			},
+		},
+		{
+			name: "backend of the opened store",
+			setup: func(deps *Dependencies) {
+				deps.SlipFinderFactory = func(*AppConfig, Logger) (domain.SlipFinder, error) {
+					return &describingFinder{}, nil
+				}
+				deps.ResolverFactory = func(domain.LocalGitRepository, domain.SlipFinder, Logger) domain.Resolver {
+					return &mockResolver{err: domain.WithCode(domain.CodeStore, errors.New("query timeout"))}
+				}
+			},
+			wantReport: map[string]string{
+				"command":    "slippy-find",
+				"error_code": "SLIPPY_E014",
+				"depth":      "25",
+				"backend":    "mock",
+			},
+		},
+		{
+			name: "no slip is expected",
+			setup: func(deps *Dependencies) {
+				deps.ResolverFactory = func(domain.LocalGitRepository, domain.SlipFinder, Logger) domain.Resolver {
+					return &mockResolver{err: domain.ErrNoAncestorSlip}
+				}
+			},
+		},
+		{
+			name: "invalid input is expected",
+			setup: func(deps *Dependencies) {
+				deps.ConfigLoader = func() (*AppConfig, error) { return nil, domain.ErrInvalidSelectionPolicy }
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reporter := &mockErrorReporter{}
+			deps := allTestDeps(&mockResolver{})
+			deps.ErrorReporter = reporter
+			tt.setup(deps)
+
+			cmd := NewRootCmdWithDeps(deps)
+			cmd.SetArgs([]string{"."})
+			err := cmd.Execute()
+
+			require.Error(t, err)
+			if tt.wantReport == nil {
+				assert.Empty(t, reporter.errs)
+				return
+			}
+			require.Len(t, reporter.errs, 1)
+			assert.Equal(t, err, reporter.errs[0])
+			assert.Equal(t, tt.wantReport, reporter.tags[0])
+		})
+	}
+}
+
+func TestAllCmd_ReportsUnexpectedFailures(t *testing.T) {
+	reporter := &mockErrorReporter{err: errors.New("sentry down")}
+	deps := allTestDeps(&mockResolver{err: domain.WithCode(domain.CodeStore, errors.New("query timeout"))})
+	deps.ErrorReporter = reporter
+
+	_, err := runAllCmd(t, deps)
+
+	require.Error(t, err)
+	require.Len(t, reporter.tags, 1)
+	assert.Equal(t, "all", reporter.tags[0]["command"])
+	assert.Equal(t, "SLIPPY_E014", reporter.tags[0]["error_code"])
+}
+
+// mockFailureNotifier records notified failures.
+type mockFailureNotifier struct {
+	failures []domain.ResolutionFailure
+	err      error
+}
+
+func (m *mockFailureNotifier) Notify(_ context.Context, failure domain.ResolutionFailure) error {
+	m.failures = append(m.failures, failure)
+	return m.err
+}
+
+func TestRootCmd_NotifiesFailures(t *testing.T) {
+	openRepo := func(deps *Dependencies) {
+		deps.GitRepoFactory = func(string, Logger) (domain.LocalGitRepository, error) {
+			return &mockGitRepo{gitContext: &domain.GitContext{
+				Repository: "org/repo",
+				Branch:     "main",
+				HeadSHA:    "abc123",
+			}}, nil
+		}
+	}
+	tests := []struct {
+		name        string
+		resolverErr error
+		setup       func(deps *Dependencies)
+		want        *domain.ResolutionFailure
+	}{
+		{
+			name:        "no slip in the ancestry",
+			resolverErr: domain.ErrNoAncestorSlip,
+			setup:       openRepo,
+			want: &domain.ResolutionFailure{
+				Repository: "org/repo",
+				Branch:     "main",
+				HeadSHA:    "abc123",
+				Code:       domain.CodeNoSlip,
+				Error:      "no slip found in commit ancestry",
+			},
+		},
+		{
+			name:        "store query fails",
+			resolverErr: domain.WithCode(domain.CodeStore, errors.New("query timeout")),
+			setup:       openRepo,
+			want: &domain.ResolutionFailure{
+				Repository: "org/repo",
+				Branch:     "main",
+				HeadSHA:    "abc123",
+				Code:       domain.CodeStore,
+				Error:      "query timeout",
+			},
+		},
+		{
+			name: "store cannot be opened",
+			setup: func(deps *Dependencies) {
+				deps.SlipFinderFactory = func(*AppConfig, Logger) (domain.SlipFinder, error) {
+					return nil, errors.New("connection refused")
+				}
+			},
+			want: &domain.ResolutionFailure{Code: domain.CodeStore, Error: "database error: connection refused"},
+		},
+		{
+			name:        "other failures are not notified",
+			resolverErr: errors.New("boom"),
+			setup:       openRepo,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &mockFailureNotifier{}
+			deps := allTestDeps(&mockResolver{err: tt.resolverErr})
+			deps.FailureNotifier = notifier
+			tt.setup(deps)
+
+			cmd := NewRootCmdWithDeps(deps)
+			cmd.SetArgs([]string{"."})
+			require.Error(t, cmd.Execute())
+
+			if tt.want == nil {
+				assert.Empty(t, notifier.failures)
+				return
+			}
+			require.Len(t, notifier.failures, 1)
+			assert.Equal(t, *tt.want, notifier.failures[0])
+		})
+	}
+}
+
+func TestRootCmd_NotifyFailureIsOnlyAWarning(t *testing.T) {
+	notifier := &mockFailureNotifier{err: errors.New("slack down")}
+	deps := allTestDeps(&mockResolver{err: domain.ErrNoAncestorSlip})
+	deps.FailureNotifier = notifier
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{"."})
+	err := cmd.Execute()
+
+	require.Error(t, err)
+	assert.Equal(t, domain.CodeNoSlip, domain.CodeOf(err))
+	assert.Len(t, notifier.failures, 1)
+}
+
+func TestCRLFWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := &crlfWriter{w: &out}
+
+	for _, chunk := range []string{"slip-1\n", "kept\r", "\nlast\r\n"} {
+		n, err := w.Write([]byte(chunk))
+		require.NoError(t, err)
+		assert.Equal(t, len(chunk), n)
+	}
+
+	assert.Equal(t, "slip-1\r\nkept\r\nlast\r\n", out.String())
+}
+
+func TestRootCmd_CRLF(t *testing.T) {
+	for _, format := range []string{"text", "json"} {
+		t.Run(format, func(t *testing.T) {
+			var stdout bytes.Buffer
+			deps := allTestDeps(&mockResolver{output: &domain.ResolveOutput{CorrelationID: "slip-1"}})
+			deps.OutputWriterFactory = func() domain.OutputWriter { return &mockOutputWriter{} }
+			deps.Stdout = &stdout
+
+			cmd := NewRootCmdWithDeps(deps)
+			cmd.SetArgs([]string{".", "--crlf", "--format", format})
+
+			require.NoError(t, cmd.Execute())
+			assert.True(t, bytes.HasSuffix(stdout.Bytes(), []byte("\r\n")))
+			assert.Equal(t, 1, bytes.Count(stdout.Bytes(), []byte("\n")))
+			assert.Contains(t, stdout.String(), "slip-1")
+		})
+	}
+}
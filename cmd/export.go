@@ -0,0 +1,278 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// gitlabDotenvSnippet is printed in verbose mode after writing --gitlab-dotenv,
+// for pasting into the job in .gitlab-ci.yml.
+const gitlabDotenvSnippet = `Add to the job in .gitlab-ci.yml so later jobs receive SLIPPY_CORRELATION_ID:
+  artifacts:
+    reports:
+      dotenv: %s
+`
+
+// exportResult hands result to the CI integrations enabled by flags, before
+// it is written to stdout, so a failed export leaves no result there.
+func exportResult(ctx context.Context, log Logger, deps *Dependencies, result *domain.ResolveOutput) error {
+	if err := exportGitLabDotenv(ctx, log, deps, result); err != nil {
+		return err
+	}
+	if err := exportBuildkiteMetaData(ctx, log, deps, result); err != nil {
+		return err
+	}
+	if err := exportArgoCDAnnotations(ctx, log, deps, result); err != nil {
+		return err
+	}
+	if err := exportOctopusVariables(ctx, log, deps, result); err != nil {
+		return err
+	}
+	if err := exportBackstageFragment(ctx, log, deps, result); err != nil {
+		return err
+	}
+	if err := exportSpinnakerArtifact(ctx, log, deps, result); err != nil {
+		return err
+	}
+	if err := exportAzureDevOpsTag(ctx, log, deps, result); err != nil {
+		return err
+	}
+	if err := exportNotifyURL(ctx, log, deps, result); err != nil {
+		return err
+	}
+	return exportGitHubDeployment(ctx, log, deps, result)
+}
+
+// exportGitLabDotenv writes result to the --gitlab-dotenv report, if any.
+func exportGitLabDotenv(ctx context.Context, log Logger, deps *Dependencies, result *domain.ResolveOutput) error {
+	if gitlabDotenv == "" {
+		return nil
+	}
+	if deps.DotenvWriter == nil {
+		return configError(errors.New("--gitlab-dotenv not supported"))
+	}
+	if err := deps.DotenvWriter(gitlabDotenv, dotenvVars(result)); err != nil {
+		err = outputError(fmt.Errorf("failed to write GitLab dotenv report: %w", err))
+		log.Error(ctx, "failed to write output", err, nil)
+		return err
+	}
+	log.Debug(ctx, "wrote GitLab dotenv report", map[string]interface{}{"path": gitlabDotenv})
+	if verbose {
+		stderr := deps.Stderr
+		if stderr == nil {
+			stderr = os.Stderr
+		}
+		writeWarningf(stderr, gitlabDotenvSnippet, gitlabDotenv)
+	}
+	return nil
+}
+
+// exportBuildkiteMetaData sets result as build meta-data with --buildkite.
+func exportBuildkiteMetaData(ctx context.Context, log Logger, deps *Dependencies, result *domain.ResolveOutput) error {
+	if !buildkiteMetaData {
+		return nil
+	}
+	if deps.BuildkiteMetaData == nil {
+		return configError(errors.New("--buildkite not supported"))
+	}
+	data := buildkiteVars(result)
+	if err := deps.BuildkiteMetaData(ctx, data); err != nil {
+		err = outputError(fmt.Errorf("failed to set Buildkite meta-data: %w", err))
+		log.Error(ctx, "failed to write output", err, nil)
+		return err
+	}
+	log.Debug(ctx, "set Buildkite meta-data", map[string]interface{}{"keys": len(data)})
+	return nil
+}
+
+// exportArgoCDAnnotations annotates the --argocd-app Application with result.
+func exportArgoCDAnnotations(ctx context.Context, log Logger, deps *Dependencies, result *domain.ResolveOutput) error {
+	if argoCDApp == "" {
+		return nil
+	}
+	if deps.ArgoCDAnnotator == nil {
+		return configError(errors.New("--argocd-app not supported"))
+	}
+	if err := deps.ArgoCDAnnotator(ctx, argoCDApp, slipAnnotations(result)); err != nil {
+		err = outputError(fmt.Errorf("failed to annotate Argo CD application: %w", err))
+		log.Error(ctx, "failed to write output", err, nil)
+		return err
+	}
+	log.Debug(ctx, "annotated Argo CD application", map[string]interface{}{"application": argoCDApp})
+	return nil
+}
+
+// exportOctopusVariables prints result as Octopus Deploy output variables with
+// --octopus, on stdout ahead of the correlation ID.
+func exportOctopusVariables(ctx context.Context, log Logger, deps *Dependencies, result *domain.ResolveOutput) error {
+	if !octopusVariables {
+		return nil
+	}
+	if deps.OctopusVariableWriter == nil {
+		return configError(errors.New("--octopus not supported"))
+	}
+	if err := deps.OctopusVariableWriter(commandStdout(deps), octopusVars(result)); err != nil {
+		err = outputError(fmt.Errorf("failed to write Octopus output variables: %w", err))
+		log.Error(ctx, "failed to write output", err, nil)
+		return err
+	}
+	return nil
+}
+
+// exportBackstageFragment writes result to the --backstage entity fragment, if any.
+func exportBackstageFragment(ctx context.Context, log Logger, deps *Dependencies, result *domain.ResolveOutput) error {
+	if backstageFragment == "" {
+		return nil
+	}
+	if deps.BackstageWriter == nil {
+		return configError(errors.New("--backstage not supported"))
+	}
+	if err := deps.BackstageWriter(backstageFragment, slipAnnotations(result)); err != nil {
+		err = outputError(fmt.Errorf("failed to write Backstage fragment: %w", err))
+		log.Error(ctx, "failed to write output", err, nil)
+		return err
+	}
+	log.Debug(ctx, "wrote Backstage fragment", map[string]interface{}{"path": backstageFragment})
+	return nil
+}
+
+// exportSpinnakerArtifact writes result to the --spinnaker-artifact file, if any.
+func exportSpinnakerArtifact(ctx context.Context, log Logger, deps *Dependencies, result *domain.ResolveOutput) error {
+	if spinnakerArtifact == "" {
+		return nil
+	}
+	if deps.SpinnakerArtifactWriter == nil {
+		return configError(errors.New("--spinnaker-artifact not supported"))
+	}
+	if err := deps.SpinnakerArtifactWriter(spinnakerArtifact, result); err != nil {
+		err = outputError(fmt.Errorf("failed to write Spinnaker artifact: %w", err))
+		log.Error(ctx, "failed to write output", err, nil)
+		return err
+	}
+	log.Debug(ctx, "wrote Spinnaker artifact", map[string]interface{}{"path": spinnakerArtifact})
+	return nil
+}
+
+// exportAzureDevOpsTag tags the running Azure Pipelines build with the
+// correlation ID with --azure-devops-tag.
+func exportAzureDevOpsTag(ctx context.Context, log Logger, deps *Dependencies, result *domain.ResolveOutput) error {
+	if !azureDevOpsTag {
+		return nil
+	}
+	if deps.AzureDevOpsTagger == nil {
+		return configError(errors.New("--azure-devops-tag not supported"))
+	}
+	if err := deps.AzureDevOpsTagger(ctx, result.CorrelationID); err != nil {
+		err = outputError(fmt.Errorf("failed to tag Azure DevOps build: %w", err))
+		log.Error(ctx, "failed to write output", err, nil)
+		return err
+	}
+	log.Debug(ctx, "tagged Azure DevOps build", map[string]interface{}{"tag": result.CorrelationID})
+	return nil
+}
+
+// exportNotifyURL POSTs result, as --format json writes it, to --notify-url.
+func exportNotifyURL(ctx context.Context, log Logger, deps *Dependencies, result *domain.ResolveOutput) error {
+	if notifyURL == "" {
+		return nil
+	}
+	if deps.ResultPoster == nil {
+		return configError(errors.New("--notify-url not supported"))
+	}
+	body, err := json.Marshal(newResultJSON(result))
+	if err == nil {
+		err = deps.ResultPoster(ctx, notifyURL, body)
+	}
+	if err != nil {
+		err = outputError(fmt.Errorf("failed to send result to --notify-url: %w", err))
+		log.Error(ctx, "failed to write output", err, nil)
+		return err
+	}
+	log.Debug(ctx, "sent result to --notify-url", nil)
+	return nil
+}
+
+// exportGitHubDeployment records result as a GitHub Deployment of the matched
+// commit to the --github-deployment environment.
+func exportGitHubDeployment(ctx context.Context, log Logger, deps *Dependencies, result *domain.ResolveOutput) error {
+	if githubDeployment == "" {
+		return nil
+	}
+	if deps.GitHubDeployer == nil {
+		return configError(errors.New("--github-deployment not supported"))
+	}
+	payload := map[string]string{
+		"correlation_id": result.CorrelationID,
+		"matched_commit": result.MatchedCommit,
+	}
+	if result.Component != "" {
+		payload["component"] = result.Component
+	}
+	err := deps.GitHubDeployer(ctx, result.Repository, result.MatchedCommit, githubDeployment, payload)
+	if err != nil {
+		err = outputError(fmt.Errorf("failed to create GitHub deployment: %w", err))
+		log.Error(ctx, "failed to write output", err, nil)
+		return err
+	}
+	log.Debug(ctx, "created GitHub deployment", map[string]interface{}{"environment": githubDeployment})
+	return nil
+}
+
+// dotenvVars returns the variables a dotenv report of result sets.
+func dotenvVars(result *domain.ResolveOutput) map[string]string {
+	vars := map[string]string{
+		"SLIPPY_CORRELATION_ID": result.CorrelationID,
+		"SLIPPY_MATCHED_COMMIT": result.MatchedCommit,
+		"SLIPPY_REPOSITORY":     result.Repository,
+		"SLIPPY_RESOLVED_BY":    result.ResolvedBy,
+		"SLIPPY_CONFIDENCE":     string(result.Confidence),
+	}
+	if result.Component != "" {
+		vars["SLIPPY_COMPONENT"] = result.Component
+	}
+	return vars
+}
+
+// buildkiteVars returns the meta-data --buildkite sets for result, read in
+// later steps with `buildkite-agent meta-data get slippy-correlation-id`.
+func buildkiteVars(result *domain.ResolveOutput) map[string]string {
+	return map[string]string{
+		"slippy-correlation-id": result.CorrelationID,
+		"slippy-matched-commit": result.MatchedCommit,
+		"slippy-repository":     result.Repository,
+		"slippy-resolved-by":    result.ResolvedBy,
+		"slippy-confidence":     string(result.Confidence),
+		"slippy-component":      result.Component,
+	}
+}
+
+// slipAnnotations returns the annotations --argocd-app and --backstage set for
+// result. Every key is set, so none is left over from an earlier deployment.
+func slipAnnotations(result *domain.ResolveOutput) map[string]string {
+	return map[string]string{
+		"slippy-find/correlation-id": result.CorrelationID,
+		"slippy-find/matched-commit": result.MatchedCommit,
+		"slippy-find/repository":     result.Repository,
+	}
+}
+
+// octopusVars returns the output variables --octopus sets for result, read in
+// later steps as #{Octopus.Action[<step>].Output.SlippyCorrelationId}.
+func octopusVars(result *domain.ResolveOutput) map[string]string {
+	vars := map[string]string{
+		"SlippyCorrelationId": result.CorrelationID,
+		"SlippyMatchedCommit": result.MatchedCommit,
+		"SlippyRepository":    result.Repository,
+		"SlippyResolvedBy":    result.ResolvedBy,
+		"SlippyConfidence":    string(result.Confidence),
+	}
+	if result.Component != "" {
+		vars["SlippyComponent"] = result.Component
+	}
+	return vars
+}
@@ -0,0 +1,853 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// dotenvRecorder records the files written by a Dependencies.DotenvWriter or
+// BackstageWriter.
+type dotenvRecorder struct {
+	path string
+	vars map[string]string
+	err  error
+}
+
+func (r *dotenvRecorder) write(path string, vars map[string]string) error {
+	r.path, r.vars = path, vars
+	return r.err
+}
+
+// metaDataRecorder records the meta-data set by a Dependencies.BuildkiteMetaData.
+type metaDataRecorder struct {
+	data map[string]string
+	err  error
+}
+
+func (r *metaDataRecorder) set(_ context.Context, data map[string]string) error {
+	r.data = data
+	return r.err
+}
+
+func TestRootCmd_GitLabDotenv(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		wantSnippet bool
+	}{
+		{name: "quiet", args: []string{"--gitlab-dotenv", "slippy.env"}},
+		{
+			name:        "verbose prints the artifacts snippet",
+			args:        []string{"--gitlab-dotenv", "slippy.env", "-v"},
+			wantSnippet: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &dotenvRecorder{}
+			var stderr bytes.Buffer
+			deps := allTestDeps(&mockResolver{output: &domain.ResolveOutput{
+				CorrelationID: "slip-1",
+				MatchedCommit: "c1",
+				Repository:    "org/repo",
+				ResolvedBy:    domain.ResolvedByAncestry,
+				Confidence:    domain.ConfidenceExactHead,
+			}})
+			deps.OutputWriterFactory = func() domain.OutputWriter { return &mockOutputWriter{} }
+			deps.DotenvWriter = recorder.write
+			deps.Stderr = &stderr
+
+			cmd := NewRootCmdWithDeps(deps)
+			cmd.SetArgs(append([]string{"."}, tt.args...))
+			require.NoError(t, cmd.Execute())
+
+			assert.Equal(t, "slippy.env", recorder.path)
+			assert.Equal(t, map[string]string{
+				"SLIPPY_CORRELATION_ID": "slip-1",
+				"SLIPPY_MATCHED_COMMIT": "c1",
+				"SLIPPY_REPOSITORY":     "org/repo",
+				"SLIPPY_RESOLVED_BY":    domain.ResolvedByAncestry,
+				"SLIPPY_CONFIDENCE":     string(domain.ConfidenceExactHead),
+			}, recorder.vars)
+			if tt.wantSnippet {
+				assert.Contains(t, stderr.String(), "reports:\n      dotenv: slippy.env")
+			} else {
+				assert.Empty(t, stderr.String())
+			}
+		})
+	}
+}
+
+func TestRootCmd_GitLabDotenv_ViaSocket(t *testing.T) {
+	remote := &mockRemoteResolver{output: &domain.ResolveOutput{CorrelationID: "slip-1", Component: "payments-api"}}
+	deps, _, _ := socketTestDeps(t, remote)
+	recorder := &dotenvRecorder{}
+	deps.DotenvWriter = recorder.write
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{"--via-socket", "/run/slippy.sock", "--gitlab-dotenv", "slippy.env"})
+	require.NoError(t, cmd.Execute())
+
+	assert.Equal(t, "slip-1", recorder.vars["SLIPPY_CORRELATION_ID"])
+	assert.Equal(t, "payments-api", recorder.vars["SLIPPY_COMPONENT"])
+}
+
+func TestRootCmd_GitLabDotenv_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		writer   func(path string, vars map[string]string) error
+		wantMsg  string
+		wantCode domain.ErrorCode
+	}{
+		{
+			name:     "not supported",
+			args:     []string{"--gitlab-dotenv", "slippy.env"},
+			wantMsg:  "--gitlab-dotenv not supported",
+			wantCode: domain.CodeConfiguration,
+		},
+		{
+			name:     "write fails",
+			args:     []string{"--gitlab-dotenv", "slippy.env"},
+			writer:   (&dotenvRecorder{err: errors.New("read-only file system")}).write,
+			wantMsg:  "failed to write GitLab dotenv report: read-only file system",
+			wantCode: domain.CodeOutput,
+		},
+		{
+			name:     "with --components",
+			args:     []string{"--gitlab-dotenv", "slippy.env", "--components", "web"},
+			wantMsg:  "cannot be combined with --components",
+			wantCode: domain.CodeInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := allTestDeps(&mockResolver{output: &domain.ResolveOutput{CorrelationID: "slip-1"}})
+			deps.OutputWriterFactory = func() domain.OutputWriter { return &mockOutputWriter{} }
+			deps.DotenvWriter = tt.writer
+
+			cmd := NewRootCmdWithDeps(deps)
+			cmd.SetArgs(append([]string{"."}, tt.args...))
+			err := cmd.Execute()
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantMsg)
+			assert.Equal(t, tt.wantCode, domain.CodeOf(err))
+		})
+	}
+}
+
+func TestRootCmd_Buildkite(t *testing.T) {
+	recorder := &metaDataRecorder{}
+	deps := allTestDeps(&mockResolver{output: &domain.ResolveOutput{
+		CorrelationID: "slip-1",
+		MatchedCommit: "c1",
+		Repository:    "org/repo",
+		ResolvedBy:    domain.ResolvedByAncestry,
+		Confidence:    domain.ConfidenceNearAncestor,
+	}})
+	writer := &mockOutputWriter{}
+	deps.OutputWriterFactory = func() domain.OutputWriter { return writer }
+	deps.BuildkiteMetaData = recorder.set
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{".", "--buildkite"})
+	require.NoError(t, cmd.Execute())
+
+	assert.Equal(t, "slip-1", writer.writtenID, "stdout is unchanged")
+	assert.Equal(t, map[string]string{
+		"slippy-correlation-id": "slip-1",
+		"slippy-matched-commit": "c1",
+		"slippy-repository":     "org/repo",
+		"slippy-resolved-by":    domain.ResolvedByAncestry,
+		"slippy-confidence":     string(domain.ConfidenceNearAncestor),
+		"slippy-component":      "",
+	}, recorder.data)
+}
+
+func TestRootCmd_Buildkite_ViaSocket(t *testing.T) {
+	remote := &mockRemoteResolver{output: &domain.ResolveOutput{CorrelationID: "slip-1"}}
+	deps, _, _ := socketTestDeps(t, remote)
+	recorder := &metaDataRecorder{}
+	deps.BuildkiteMetaData = recorder.set
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{"--via-socket", "/run/slippy.sock", "--buildkite"})
+	require.NoError(t, cmd.Execute())
+
+	assert.Equal(t, "slip-1", recorder.data["slippy-correlation-id"])
+}
+
+func TestRootCmd_Buildkite_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		setter   func(ctx context.Context, data map[string]string) error
+		wantMsg  string
+		wantCode domain.ErrorCode
+	}{
+		{
+			name:     "not supported",
+			args:     []string{"--buildkite"},
+			wantMsg:  "--buildkite not supported",
+			wantCode: domain.CodeConfiguration,
+		},
+		{
+			name:     "agent fails",
+			args:     []string{"--buildkite"},
+			setter:   (&metaDataRecorder{err: errors.New("exit status 1")}).set,
+			wantMsg:  "failed to set Buildkite meta-data: exit status 1",
+			wantCode: domain.CodeOutput,
+		},
+		{
+			name:     "with --components",
+			args:     []string{"--buildkite", "--components", "web"},
+			wantMsg:  "--buildkite cannot be combined with --components",
+			wantCode: domain.CodeInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := allTestDeps(&mockResolver{output: &domain.ResolveOutput{CorrelationID: "slip-1"}})
+			deps.OutputWriterFactory = func() domain.OutputWriter { return &mockOutputWriter{} }
+			deps.BuildkiteMetaData = tt.setter
+
+			cmd := NewRootCmdWithDeps(deps)
+			cmd.SetArgs(append([]string{"."}, tt.args...))
+			err := cmd.Execute()
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantMsg)
+			assert.Equal(t, tt.wantCode, domain.CodeOf(err))
+		})
+	}
+}
+
+// annotationRecorder records the annotations set by a Dependencies.ArgoCDAnnotator.
+type annotationRecorder struct {
+	app         string
+	annotations map[string]string
+	err         error
+}
+
+func (r *annotationRecorder) annotate(_ context.Context, app string, annotations map[string]string) error {
+	r.app, r.annotations = app, annotations
+	return r.err
+}
+
+func TestRootCmd_ArgoCDApp(t *testing.T) {
+	recorder := &annotationRecorder{}
+	deps := allTestDeps(&mockResolver{output: &domain.ResolveOutput{
+		CorrelationID: "slip-1",
+		MatchedCommit: "c1",
+		Repository:    "org/repo",
+	}})
+	deps.OutputWriterFactory = func() domain.OutputWriter { return &mockOutputWriter{} }
+	deps.ArgoCDAnnotator = recorder.annotate
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{".", "--argocd-app", "team-a/payments-api"})
+	require.NoError(t, cmd.Execute())
+
+	assert.Equal(t, "team-a/payments-api", recorder.app)
+	assert.Equal(t, map[string]string{
+		"slippy-find/correlation-id": "slip-1",
+		"slippy-find/matched-commit": "c1",
+		"slippy-find/repository":     "org/repo",
+	}, recorder.annotations)
+}
+
+func TestRootCmd_ArgoCDApp_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		annotator func(ctx context.Context, app string, annotations map[string]string) error
+		wantMsg   string
+		wantCode  domain.ErrorCode
+	}{
+		{
+			name:     "not supported",
+			args:     []string{"--argocd-app", "payments-api"},
+			wantMsg:  "--argocd-app not supported",
+			wantCode: domain.CodeConfiguration,
+		},
+		{
+			name:      "patch fails",
+			args:      []string{"--argocd-app", "payments-api"},
+			annotator: (&annotationRecorder{err: errors.New("403 Forbidden")}).annotate,
+			wantMsg:   "failed to annotate Argo CD application: 403 Forbidden",
+			wantCode:  domain.CodeOutput,
+		},
+		{
+			name:     "with --components",
+			args:     []string{"--argocd-app", "payments-api", "--components", "web"},
+			wantMsg:  "--argocd-app cannot be combined with --components",
+			wantCode: domain.CodeInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := allTestDeps(&mockResolver{output: &domain.ResolveOutput{CorrelationID: "slip-1"}})
+			deps.OutputWriterFactory = func() domain.OutputWriter { return &mockOutputWriter{} }
+			deps.ArgoCDAnnotator = tt.annotator
+
+			cmd := NewRootCmdWithDeps(deps)
+			cmd.SetArgs(append([]string{"."}, tt.args...))
+			err := cmd.Execute()
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantMsg)
+			assert.Equal(t, tt.wantCode, domain.CodeOf(err))
+		})
+	}
+}
+
+func TestRootCmd_ExportFailure_JSON(t *testing.T) {
+	deps := allTestDeps(&mockResolver{output: &domain.ResolveOutput{CorrelationID: "slip-1"}})
+	deps.ArgoCDAnnotator = (&annotationRecorder{err: errors.New("403 Forbidden")}).annotate
+	stdout := &bytes.Buffer{}
+	deps.Stdout = stdout
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{".", "--argocd-app", "payments-api", "--format", "json"})
+	require.Error(t, cmd.Execute())
+
+	// Only the error is written, as one JSON document
+	var out map[string]any
+	decoder := json.NewDecoder(stdout)
+	require.NoError(t, decoder.Decode(&out))
+	assert.Equal(t, string(domain.CodeOutput), out["code"])
+	assert.NotContains(t, out, "correlation_id")
+	assert.False(t, decoder.More(), "no second document")
+}
+
+// octopusRecorder records the variables written by a Dependencies.OctopusVariableWriter.
+type octopusRecorder struct {
+	w    io.Writer
+	vars map[string]string
+	err  error
+}
+
+func (r *octopusRecorder) write(w io.Writer, vars map[string]string) error {
+	r.w, r.vars = w, vars
+	return r.err
+}
+
+func TestRootCmd_Octopus(t *testing.T) {
+	recorder := &octopusRecorder{}
+	deps := allTestDeps(&mockResolver{output: &domain.ResolveOutput{
+		CorrelationID: "slip-1",
+		MatchedCommit: "c1",
+		Repository:    "org/repo",
+		ResolvedBy:    domain.ResolvedByAncestry,
+		Confidence:    domain.ConfidenceExactHead,
+		Component:     "web",
+	}})
+	writer := &mockOutputWriter{}
+	deps.OutputWriterFactory = func() domain.OutputWriter { return writer }
+	stdout := &bytes.Buffer{}
+	deps.Stdout = stdout
+	deps.OctopusVariableWriter = recorder.write
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{".", "--octopus"})
+	require.NoError(t, cmd.Execute())
+
+	assert.Equal(t, "slip-1", writer.writtenID)
+	assert.Same(t, stdout, recorder.w, "service messages go to stdout, where Octopus reads them")
+	assert.Equal(t, map[string]string{
+		"SlippyCorrelationId": "slip-1",
+		"SlippyMatchedCommit": "c1",
+		"SlippyRepository":    "org/repo",
+		"SlippyResolvedBy":    domain.ResolvedByAncestry,
+		"SlippyConfidence":    string(domain.ConfidenceExactHead),
+		"SlippyComponent":     "web",
+	}, recorder.vars)
+}
+
+func TestRootCmd_Octopus_ViaSocket(t *testing.T) {
+	remote := &mockRemoteResolver{output: &domain.ResolveOutput{CorrelationID: "slip-1"}}
+	deps, _, _ := socketTestDeps(t, remote)
+	recorder := &octopusRecorder{}
+	deps.OctopusVariableWriter = recorder.write
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{"--via-socket", "/run/slippy.sock", "--octopus"})
+	require.NoError(t, cmd.Execute())
+
+	assert.Equal(t, "slip-1", recorder.vars["SlippyCorrelationId"])
+	assert.NotContains(t, recorder.vars, "SlippyComponent")
+}
+
+func TestRootCmd_Octopus_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		writer   func(w io.Writer, vars map[string]string) error
+		wantMsg  string
+		wantCode domain.ErrorCode
+	}{
+		{
+			name:     "not supported",
+			args:     []string{"--octopus"},
+			wantMsg:  "--octopus not supported",
+			wantCode: domain.CodeConfiguration,
+		},
+		{
+			name:     "write fails",
+			args:     []string{"--octopus"},
+			writer:   (&octopusRecorder{err: errors.New("broken pipe")}).write,
+			wantMsg:  "failed to write Octopus output variables: broken pipe",
+			wantCode: domain.CodeOutput,
+		},
+		{
+			name:     "with --components",
+			args:     []string{"--octopus", "--components", "web"},
+			wantMsg:  "--octopus cannot be combined with --components",
+			wantCode: domain.CodeInvalidInput,
+		},
+		{
+			name:     "with --format json",
+			args:     []string{"--octopus", "--format", "json"},
+			writer:   (&octopusRecorder{}).write,
+			wantMsg:  "--octopus cannot be combined with --format json",
+			wantCode: domain.CodeInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := allTestDeps(&mockResolver{output: &domain.ResolveOutput{CorrelationID: "slip-1"}})
+			deps.OutputWriterFactory = func() domain.OutputWriter { return &mockOutputWriter{} }
+			deps.OctopusVariableWriter = tt.writer
+
+			cmd := NewRootCmdWithDeps(deps)
+			cmd.SetArgs(append([]string{"."}, tt.args...))
+			err := cmd.Execute()
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantMsg)
+			assert.Equal(t, tt.wantCode, domain.CodeOf(err))
+		})
+	}
+}
+
+func TestRootCmd_Backstage(t *testing.T) {
+	recorder := &dotenvRecorder{}
+	deps := allTestDeps(&mockResolver{output: &domain.ResolveOutput{
+		CorrelationID: "slip-1",
+		MatchedCommit: "c1",
+		Repository:    "org/repo",
+	}})
+	writer := &mockOutputWriter{}
+	deps.OutputWriterFactory = func() domain.OutputWriter { return writer }
+	deps.BackstageWriter = recorder.write
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{".", "--backstage", "backstage-slip.json"})
+	require.NoError(t, cmd.Execute())
+
+	assert.Equal(t, "slip-1", writer.writtenID, "stdout is unchanged")
+	assert.Equal(t, "backstage-slip.json", recorder.path)
+	assert.Equal(t, map[string]string{
+		"slippy-find/correlation-id": "slip-1",
+		"slippy-find/matched-commit": "c1",
+		"slippy-find/repository":     "org/repo",
+	}, recorder.vars)
+}
+
+func TestRootCmd_Backstage_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		writer   func(path string, annotations map[string]string) error
+		wantMsg  string
+		wantCode domain.ErrorCode
+	}{
+		{
+			name:     "not supported",
+			args:     []string{"--backstage", "backstage-slip.json"},
+			wantMsg:  "--backstage not supported",
+			wantCode: domain.CodeConfiguration,
+		},
+		{
+			name:     "write fails",
+			args:     []string{"--backstage", "backstage-slip.json"},
+			writer:   (&dotenvRecorder{err: errors.New("permission denied")}).write,
+			wantMsg:  "failed to write Backstage fragment: permission denied",
+			wantCode: domain.CodeOutput,
+		},
+		{
+			name:     "with --components",
+			args:     []string{"--backstage", "backstage-slip.json", "--components", "web"},
+			wantMsg:  "--backstage cannot be combined with --components",
+			wantCode: domain.CodeInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := allTestDeps(&mockResolver{output: &domain.ResolveOutput{CorrelationID: "slip-1"}})
+			deps.OutputWriterFactory = func() domain.OutputWriter { return &mockOutputWriter{} }
+			deps.BackstageWriter = tt.writer
+
+			cmd := NewRootCmdWithDeps(deps)
+			cmd.SetArgs(append([]string{"."}, tt.args...))
+			err := cmd.Execute()
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantMsg)
+			assert.Equal(t, tt.wantCode, domain.CodeOf(err))
+		})
+	}
+}
+
+// artifactRecorder records the results written by a Dependencies.SpinnakerArtifactWriter.
+type artifactRecorder struct {
+	path   string
+	result *domain.ResolveOutput
+	err    error
+}
+
+func (r *artifactRecorder) write(path string, result *domain.ResolveOutput) error {
+	r.path, r.result = path, result
+	return r.err
+}
+
+func TestRootCmd_SpinnakerArtifact(t *testing.T) {
+	recorder := &artifactRecorder{}
+	output := &domain.ResolveOutput{CorrelationID: "slip-1", MatchedCommit: "c1", Repository: "org/repo"}
+	deps := allTestDeps(&mockResolver{output: output})
+	writer := &mockOutputWriter{}
+	deps.OutputWriterFactory = func() domain.OutputWriter { return writer }
+	deps.SpinnakerArtifactWriter = recorder.write
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{".", "--spinnaker-artifact", "spinnaker.json"})
+	require.NoError(t, cmd.Execute())
+
+	assert.Equal(t, "slip-1", writer.writtenID, "stdout is unchanged")
+	assert.Equal(t, "spinnaker.json", recorder.path)
+	assert.Equal(t, output, recorder.result)
+}
+
+func TestRootCmd_SpinnakerArtifact_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		writer   func(path string, result *domain.ResolveOutput) error
+		wantMsg  string
+		wantCode domain.ErrorCode
+	}{
+		{
+			name:     "not supported",
+			args:     []string{"--spinnaker-artifact", "spinnaker.json"},
+			wantMsg:  "--spinnaker-artifact not supported",
+			wantCode: domain.CodeConfiguration,
+		},
+		{
+			name:     "write fails",
+			args:     []string{"--spinnaker-artifact", "spinnaker.json"},
+			writer:   (&artifactRecorder{err: errors.New("read-only file system")}).write,
+			wantMsg:  "failed to write Spinnaker artifact: read-only file system",
+			wantCode: domain.CodeOutput,
+		},
+		{
+			name:     "with --components",
+			args:     []string{"--spinnaker-artifact", "spinnaker.json", "--components", "web"},
+			wantMsg:  "--spinnaker-artifact cannot be combined with --components",
+			wantCode: domain.CodeInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := allTestDeps(&mockResolver{output: &domain.ResolveOutput{CorrelationID: "slip-1"}})
+			deps.OutputWriterFactory = func() domain.OutputWriter { return &mockOutputWriter{} }
+			deps.SpinnakerArtifactWriter = tt.writer
+
+			cmd := NewRootCmdWithDeps(deps)
+			cmd.SetArgs(append([]string{"."}, tt.args...))
+			err := cmd.Execute()
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantMsg)
+			assert.Equal(t, tt.wantCode, domain.CodeOf(err))
+		})
+	}
+}
+
+// tagRecorder records the tags added by a Dependencies.AzureDevOpsTagger.
+type tagRecorder struct {
+	tags []string
+	err  error
+}
+
+func (r *tagRecorder) tag(_ context.Context, tag string) error {
+	r.tags = append(r.tags, tag)
+	return r.err
+}
+
+func TestRootCmd_AzureDevOpsTag(t *testing.T) {
+	recorder := &tagRecorder{}
+	deps := allTestDeps(&mockResolver{output: &domain.ResolveOutput{CorrelationID: "slip-1"}})
+	deps.OutputWriterFactory = func() domain.OutputWriter { return &mockOutputWriter{} }
+	deps.AzureDevOpsTagger = recorder.tag
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{".", "--azure-devops-tag"})
+	require.NoError(t, cmd.Execute())
+
+	assert.Equal(t, []string{"slip-1"}, recorder.tags)
+}
+
+func TestRootCmd_AzureDevOpsTag_ViaSocket(t *testing.T) {
+	remote := &mockRemoteResolver{output: &domain.ResolveOutput{CorrelationID: "slip-1"}}
+	deps, _, _ := socketTestDeps(t, remote)
+	recorder := &tagRecorder{}
+	deps.AzureDevOpsTagger = recorder.tag
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{"--via-socket", "/run/slippy.sock", "--azure-devops-tag"})
+	require.NoError(t, cmd.Execute())
+
+	assert.Equal(t, []string{"slip-1"}, recorder.tags)
+}
+
+func TestRootCmd_AzureDevOpsTag_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		tagger   func(ctx context.Context, tag string) error
+		wantMsg  string
+		wantCode domain.ErrorCode
+	}{
+		{
+			name:     "not supported",
+			args:     []string{"--azure-devops-tag"},
+			wantMsg:  "--azure-devops-tag not supported",
+			wantCode: domain.CodeConfiguration,
+		},
+		{
+			name:     "tag fails",
+			args:     []string{"--azure-devops-tag"},
+			tagger:   (&tagRecorder{err: errors.New("token not accepted")}).tag,
+			wantMsg:  "failed to tag Azure DevOps build: token not accepted",
+			wantCode: domain.CodeOutput,
+		},
+		{
+			name:     "with --components",
+			args:     []string{"--azure-devops-tag", "--components", "web"},
+			wantMsg:  "--azure-devops-tag cannot be combined with --components",
+			wantCode: domain.CodeInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := allTestDeps(&mockResolver{output: &domain.ResolveOutput{CorrelationID: "slip-1"}})
+			deps.OutputWriterFactory = func() domain.OutputWriter { return &mockOutputWriter{} }
+			deps.AzureDevOpsTagger = tt.tagger
+
+			cmd := NewRootCmdWithDeps(deps)
+			cmd.SetArgs(append([]string{"."}, tt.args...))
+			err := cmd.Execute()
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantMsg)
+			assert.Equal(t, tt.wantCode, domain.CodeOf(err))
+		})
+	}
+}
+
+// postRecorder records the bodies sent by a Dependencies.ResultPoster.
+type postRecorder struct {
+	url  string
+	body []byte
+	err  error
+}
+
+func (r *postRecorder) post(_ context.Context, url string, body []byte) error {
+	r.url, r.body = url, body
+	return r.err
+}
+
+func TestRootCmd_NotifyURL(t *testing.T) {
+	recorder := &postRecorder{}
+	deps := allTestDeps(&mockResolver{output: &domain.ResolveOutput{
+		CorrelationID:   "slip-1",
+		MatchedCommit:   "c1",
+		Repository:      "org/repo",
+		ResolvedBy:      domain.ResolvedByAncestry,
+		SelectionPolicy: domain.SelectionNearestCommit,
+		Candidates:      1,
+		Depth:           25,
+		Distance:        2,
+		Confidence:      domain.ConfidenceNearAncestor,
+	}})
+	writer := &mockOutputWriter{}
+	deps.OutputWriterFactory = func() domain.OutputWriter { return writer }
+	deps.ResultPoster = recorder.post
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{".", "--notify-url", "https://tracker.example.com/hooks/slippy"})
+	require.NoError(t, cmd.Execute())
+
+	assert.Equal(t, "slip-1", writer.writtenID, "stdout is unchanged")
+	assert.Equal(t, "https://tracker.example.com/hooks/slippy", recorder.url)
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(recorder.body, &body))
+	assert.Contains(t, body, "timings_ms", "the body is the --format json document")
+	delete(body, "timings_ms")
+	assert.Equal(t, map[string]interface{}{
+		"schema_version": "1.4", "correlation_id": "slip-1", "matched_commit": "c1", "repository": "org/repo",
+		"resolved_by": "ancestry", "selection_policy": "nearest-commit",
+		"candidates": 1.0, "depth": 25.0, "distance": 2.0, "confidence": "near-ancestor",
+	}, body)
+}
+
+func TestRootCmd_NotifyURL_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		poster   func(ctx context.Context, url string, body []byte) error
+		wantMsg  string
+		wantCode domain.ErrorCode
+	}{
+		{
+			name:     "not supported",
+			args:     []string{"--notify-url", "https://tracker.example.com"},
+			wantMsg:  "--notify-url not supported",
+			wantCode: domain.CodeConfiguration,
+		},
+		{
+			name:     "post fails",
+			args:     []string{"--notify-url", "https://tracker.example.com"},
+			poster:   (&postRecorder{err: errors.New("webhook rejected: 502 Bad Gateway")}).post,
+			wantMsg:  "failed to send result to --notify-url: webhook rejected: 502 Bad Gateway",
+			wantCode: domain.CodeOutput,
+		},
+		{
+			name:     "with --components",
+			args:     []string{"--notify-url", "https://tracker.example.com", "--components", "web"},
+			wantMsg:  "--notify-url cannot be combined with --components",
+			wantCode: domain.CodeInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := allTestDeps(&mockResolver{output: &domain.ResolveOutput{CorrelationID: "slip-1"}})
+			deps.OutputWriterFactory = func() domain.OutputWriter { return &mockOutputWriter{} }
+			deps.ResultPoster = tt.poster
+
+			cmd := NewRootCmdWithDeps(deps)
+			cmd.SetArgs(append([]string{"."}, tt.args...))
+			err := cmd.Execute()
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantMsg)
+			assert.Equal(t, tt.wantCode, domain.CodeOf(err))
+		})
+	}
+}
+
+// deploymentRecorder records the deployments created by a Dependencies.GitHubDeployer.
+type deploymentRecorder struct {
+	repository, ref, environment string
+	payload                      map[string]string
+	err                          error
+}
+
+func (r *deploymentRecorder) deploy(
+	_ context.Context,
+	repository, ref, environment string,
+	payload map[string]string,
+) error {
+	r.repository, r.ref, r.environment, r.payload = repository, ref, environment, payload
+	return r.err
+}
+
+func TestRootCmd_GitHubDeployment(t *testing.T) {
+	recorder := &deploymentRecorder{}
+	deps := allTestDeps(&mockResolver{output: &domain.ResolveOutput{
+		CorrelationID: "slip-1",
+		MatchedCommit: "c1",
+		Repository:    "org/repo",
+		Component:     "web",
+	}})
+	deps.OutputWriterFactory = func() domain.OutputWriter { return &mockOutputWriter{} }
+	deps.GitHubDeployer = recorder.deploy
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{".", "--github-deployment", "production"})
+	require.NoError(t, cmd.Execute())
+
+	assert.Equal(t, "org/repo", recorder.repository)
+	assert.Equal(t, "c1", recorder.ref)
+	assert.Equal(t, "production", recorder.environment)
+	assert.Equal(t, map[string]string{
+		"correlation_id": "slip-1",
+		"matched_commit": "c1",
+		"component":      "web",
+	}, recorder.payload)
+}
+
+func TestRootCmd_GitHubDeployment_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		deployer func(ctx context.Context, repository, ref, environment string, payload map[string]string) error
+		wantMsg  string
+		wantCode domain.ErrorCode
+	}{
+		{
+			name:     "not supported",
+			args:     []string{"--github-deployment", "production"},
+			wantMsg:  "--github-deployment not supported",
+			wantCode: domain.CodeConfiguration,
+		},
+		{
+			name:     "deployment fails",
+			args:     []string{"--github-deployment", "production"},
+			deployer: (&deploymentRecorder{err: errors.New("403 Forbidden")}).deploy,
+			wantMsg:  "failed to create GitHub deployment: 403 Forbidden",
+			wantCode: domain.CodeOutput,
+		},
+		{
+			name:     "with --components",
+			args:     []string{"--github-deployment", "production", "--components", "web"},
+			wantMsg:  "--github-deployment cannot be combined with --components",
+			wantCode: domain.CodeInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := allTestDeps(&mockResolver{output: &domain.ResolveOutput{CorrelationID: "slip-1"}})
+			deps.OutputWriterFactory = func() domain.OutputWriter { return &mockOutputWriter{} }
+			deps.GitHubDeployer = tt.deployer
+
+			cmd := NewRootCmdWithDeps(deps)
+			cmd.SetArgs(append([]string{"."}, tt.args...))
+			err := cmd.Execute()
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantMsg)
+			assert.Equal(t, tt.wantCode, domain.CodeOf(err))
+		})
+	}
+}
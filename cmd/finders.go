@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// finderCache shares slip finders between configurations with the same
+// AppConfig.StoreFingerprint, so a process hosting several of them opens each
+// store connection once. It is not safe for concurrent use.
+type finderCache struct {
+	deps    *Dependencies
+	log     Logger
+	entries map[string]*cachedFinder
+
+	// reused counts the opens served by an already-open finder, and saved
+	// estimates the connection setup time they avoided.
+	reused int
+	saved  time.Duration
+}
+
+// cachedFinder is a finder shared by refs users.
+type cachedFinder struct {
+	finder      domain.SlipFinder
+	closeFinder func()
+	refs        int
+
+	// opened is how long the finder took to open.
+	opened time.Duration
+}
+
+// newFinderCache returns an empty finder cache opening finders through deps.
+func newFinderCache(deps *Dependencies, log Logger) *finderCache {
+	return &finderCache{deps: deps, log: log, entries: make(map[string]*cachedFinder)}
+}
+
+// open returns the finder for cfg, logging with fields, and opens it unless a
+// configuration with the same fingerprint already did. Configurations without
+// a fingerprint are never shared. The caller must call the returned release
+// function; a shared finder is closed when its last user releases it.
+func (c *finderCache) open(
+	ctx context.Context,
+	cfg *AppConfig,
+	fields map[string]interface{},
+) (domain.SlipFinder, func(), error) {
+	key := cfg.StoreFingerprint
+	if key == "" {
+		return openSlipFinder(ctx, c.deps, c.log, cfg, fields)
+	}
+	if entry, ok := c.entries[key]; ok {
+		entry.refs++
+		c.reused++
+		c.saved += entry.opened
+		debugFields := map[string]interface{}{"saved_ms": millis(entry.opened)}
+		maps.Copy(debugFields, fields)
+		c.log.Debug(ctx, "reusing slip store connection", debugFields)
+		return entry.finder, c.release(key, entry), nil
+	}
+
+	start := time.Now()
+	finder, closeFinder, err := openSlipFinder(ctx, c.deps, c.log, cfg, fields)
+	if err != nil {
+		return nil, nil, err
+	}
+	entry := &cachedFinder{finder: finder, closeFinder: closeFinder, refs: 1, opened: time.Since(start)}
+	c.entries[key] = entry
+	return finder, c.release(key, entry), nil
+}
+
+// release returns a function dropping one reference to entry, closing its
+// finder when none remain. Calling it more than once has no further effect.
+func (c *finderCache) release(key string, entry *cachedFinder) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			entry.refs--
+			if entry.refs > 0 {
+				return
+			}
+			delete(c.entries, key)
+			entry.closeFinder()
+		})
+	}
+}
+
+// reuseSavings estimates the connection setup time saved by opening a store
+// once, which took open, for uses resolutions instead of once for each.
+func reuseSavings(open time.Duration, uses int) time.Duration {
+	if uses < 2 {
+		return 0
+	}
+	return open * time.Duration(uses-1)
+}
+
+// millis returns d in milliseconds.
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+func TestFinderCache_SharesByFingerprint(t *testing.T) {
+	var opened []*mockSlipFinder
+	deps := &Dependencies{SlipFinderFactory: func(*AppConfig, Logger) (domain.SlipFinder, error) {
+		finder := &mockSlipFinder{}
+		opened = append(opened, finder)
+		return finder, nil
+	}}
+	cache := newFinderCache(deps, &mockLogger{})
+	ctx := context.Background()
+
+	first, releaseFirst, err := cache.open(ctx, &AppConfig{StoreFingerprint: "a"}, nil)
+	require.NoError(t, err)
+	second, releaseSecond, err := cache.open(ctx, &AppConfig{StoreFingerprint: "a"}, nil)
+	require.NoError(t, err)
+	other, releaseOther, err := cache.open(ctx, &AppConfig{StoreFingerprint: "b"}, nil)
+	require.NoError(t, err)
+
+	require.Len(t, opened, 2)
+	assert.Same(t, first, second)
+	assert.NotSame(t, first, other)
+	assert.Equal(t, 1, cache.reused)
+
+	releaseFirst()
+	releaseFirst()
+	assert.False(t, opened[0].closeCalled, "a shared finder stays open while in use")
+	releaseSecond()
+	assert.True(t, opened[0].closeCalled)
+	releaseOther()
+	assert.True(t, opened[1].closeCalled)
+}
+
+func TestFinderCache_NoFingerprint(t *testing.T) {
+	opens := 0
+	deps := &Dependencies{SlipFinderFactory: func(*AppConfig, Logger) (domain.SlipFinder, error) {
+		opens++
+		return &mockSlipFinder{}, nil
+	}}
+	cache := newFinderCache(deps, &mockLogger{})
+
+	for range 2 {
+		_, release, err := cache.open(context.Background(), &AppConfig{}, nil)
+		require.NoError(t, err)
+		defer release()
+	}
+	assert.Equal(t, 2, opens, "configurations without a fingerprint are never shared")
+}
+
+func TestReuseSavings(t *testing.T) {
+	assert.Zero(t, reuseSavings(time.Second, 0))
+	assert.Zero(t, reuseSavings(time.Second, 1))
+	assert.Equal(t, 3*time.Second, reuseSavings(time.Second, 4))
+}
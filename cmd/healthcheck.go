@@ -0,0 +1,285 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// Healthcheck command flags.
+var (
+	healthcheckFormat  string
+	healthcheckTimeout time.Duration
+)
+
+// Health check statuses.
+const (
+	healthOK      = "ok"
+	healthFail    = "fail"
+	healthSkipped = "skipped"
+)
+
+// newHealthcheckCmd creates "healthcheck", which checks the dependencies a
+// resolution needs without resolving anything.
+func newHealthcheckCmd(deps *Dependencies) *cobra.Command {
+	healthCmd := &cobra.Command{
+		Use:   "healthcheck [path]",
+		Short: "Check git, Vault, configuration, and the slip store",
+		Long: `Check everything a resolution depends on and print one report.
+
+The checks are: the git repository can be opened and HEAD read, Vault accepts
+the AppRole login and serves the pipeline configuration (when configured),
+configuration loads, and the slip store answers a query. Each check reports
+ok, fail, or skipped. Checks that depend on a failed one are skipped.
+
+The command exits non-zero if any check fails, so it can gate runner
+bootstrap or serve as a Kubernetes exec probe.
+
+Examples:
+  slippy-find healthcheck
+  slippy-find healthcheck /path/to/repo --format json
+  slippy-find healthcheck --timeout 3s`,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runHealthcheck(cmd, args, deps)
+		},
+	}
+
+	healthCmd.Flags().StringVar(&healthcheckFormat, "format", "text",
+		"Output format: text or json")
+	healthCmd.Flags().DurationVar(&healthcheckTimeout, "timeout", 10*time.Second,
+		"Maximum time for all checks together")
+
+	return healthCmd
+}
+
+// healthCheckJSON is the result of one check.
+type healthCheckJSON struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Detail     string `json:"detail,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// healthReportJSON is the document written by "healthcheck --format json".
+// Status is fail if any check failed, and ok otherwise.
+type healthReportJSON struct {
+	Status  string            `json:"status"`
+	Version string            `json:"version"`
+	Checks  []healthCheckJSON `json:"checks"`
+}
+
+// runHealthcheck runs every check in order and writes the report to stdout.
+// Returns an error naming the failed checks, after the report is written.
+func runHealthcheck(cmd *cobra.Command, args []string, deps *Dependencies) error {
+	if deps == nil {
+		return configError(errors.New("dependencies not configured"))
+	}
+	if healthcheckFormat != "text" && healthcheckFormat != "json" {
+		return usageErrorf("unsupported format %q: expected text or json", healthcheckFormat)
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, healthcheckTimeout)
+	defer cancel()
+
+	repoPath := repoRoot(deps, args)
+
+	checker := &healthChecker{deps: deps, log: deps.LoggerFactory()}
+	checker.run("git", func() (string, error) { return checker.checkGit(ctx, repoPath) })
+	checker.run("vault", func() (string, error) { return checker.checkVault(ctx) })
+	checker.run("config", checker.checkConfig)
+	checker.run("store", func() (string, error) { return checker.checkStore(ctx) })
+	checker.run("cache", func() (string, error) { return "", errCheckSkipped{reason: "no cache configured"} })
+
+	report := checker.report()
+	stdout := commandStdout(deps)
+	var err error
+	if healthcheckFormat == "json" {
+		err = writeHealthJSON(stdout, report)
+	} else {
+		err = writeHealthText(stdout, report)
+	}
+	if err != nil {
+		return err
+	}
+
+	if failed := report.failed(); len(failed) > 0 {
+		return fmt.Errorf("healthcheck failed: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// errCheckSkipped marks a check that did not run, and why.
+type errCheckSkipped struct {
+	reason string
+}
+
+func (e errCheckSkipped) Error() string {
+	return e.reason
+}
+
+// healthChecker runs checks in order, keeping what later checks depend on.
+type healthChecker struct {
+	deps   *Dependencies
+	log    Logger
+	cfg    *AppConfig
+	checks []healthCheckJSON
+}
+
+// run times check and records its result under name. check returns a detail
+// on success, or an errCheckSkipped if it did not run.
+func (h *healthChecker) run(name string, check func() (string, error)) {
+	start := time.Now()
+	detail, err := check()
+	result := healthCheckJSON{Name: name, Status: healthOK, Detail: detail, DurationMS: time.Since(start).Milliseconds()}
+
+	var skipped errCheckSkipped
+	switch {
+	case errors.As(err, &skipped):
+		result.Status, result.Detail = healthSkipped, skipped.reason
+	case err != nil:
+		result.Status, result.Detail = healthFail, err.Error()
+	}
+	h.checks = append(h.checks, result)
+}
+
+// checkGit opens the repository and reads HEAD.
+func (h *healthChecker) checkGit(ctx context.Context, repoPath string) (string, error) {
+	if h.deps.GitRepoFactory == nil {
+		return "", errCheckSkipped{reason: "git not configured"}
+	}
+	gitRepo, err := h.deps.GitRepoFactory(repoPath, h.log)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		// Read-only use; a close failure does not affect the result
+		_ = gitRepo.Close()
+	}()
+
+	gitCtx, err := gitRepo.GetGitContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s at %s", gitCtx.Repository, gitCtx.HeadSHA), nil
+}
+
+// checkVault logs in to Vault and reads the pipeline configuration secret.
+func (h *healthChecker) checkVault(ctx context.Context) (string, error) {
+	if h.deps.VaultChecker == nil {
+		return "", errCheckSkipped{reason: "vault not configured"}
+	}
+	configured, err := h.deps.VaultChecker(ctx)
+	if !configured {
+		return "", errCheckSkipped{reason: "pipeline configuration is not read from vault"}
+	}
+	if err != nil {
+		return "", err
+	}
+	return "reachable", nil
+}
+
+// checkConfig loads the configuration, keeping it for checkStore.
+func (h *healthChecker) checkConfig() (string, error) {
+	cfg, err := h.deps.ConfigLoader()
+	if err != nil {
+		return "", err
+	}
+	h.cfg = cfg
+	return "database " + cfg.Database, nil
+}
+
+// checkStore connects to the slip store and, if the finder supports it, pings it.
+func (h *healthChecker) checkStore(ctx context.Context) (string, error) {
+	if h.cfg == nil {
+		return "", errCheckSkipped{reason: "configuration not loaded"}
+	}
+	finder, err := h.deps.SlipFinderFactory(h.cfg, h.log)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if closeErr := finder.Close(); closeErr != nil {
+			h.log.Warn(ctx, "failed to close slip finder", map[string]interface{}{
+				"error": closeErr.Error(),
+			})
+		}
+	}()
+
+	pinger, ok := finder.(domain.StorePinger)
+	if !ok {
+		return "connected", nil
+	}
+	if err := pinger.Ping(ctx); err != nil {
+		if errors.Is(err, domain.ErrPingUnsupported) {
+			return "connected", nil
+		}
+		return "", err
+	}
+	return "reachable", nil
+}
+
+// report assembles the checks into the report.
+func (h *healthChecker) report() healthReportJSON {
+	report := healthReportJSON{Status: healthOK, Version: Version, Checks: h.checks}
+	if len(report.failed()) > 0 {
+		report.Status = healthFail
+	}
+	return report
+}
+
+// failed returns the names of the failed checks, in order.
+func (r healthReportJSON) failed() []string {
+	var names []string
+	for _, check := range r.Checks {
+		if check.Status == healthFail {
+			names = append(names, check.Name)
+		}
+	}
+	return names
+}
+
+// writeHealthText writes one aligned row per check, then the overall status.
+func writeHealthText(w io.Writer, report healthReportJSON) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "CHECK\tSTATUS\tDURATION\tDETAIL"); err != nil {
+		return outputError(err)
+	}
+	for _, check := range report.Checks {
+		_, err := fmt.Fprintf(tw, "%s\t%s\t%dms\t%s\n", check.Name, check.Status, check.DurationMS, check.Detail)
+		if err != nil {
+			return outputError(err)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return outputError(err)
+	}
+	if _, err := fmt.Fprintf(w, "\nstatus: %s\n", report.Status); err != nil {
+		return outputError(err)
+	}
+	return nil
+}
+
+// writeHealthJSON writes the report as an indented JSON document.
+func writeHealthJSON(w io.Writer, report healthReportJSON) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return outputError(err)
+	}
+	return nil
+}
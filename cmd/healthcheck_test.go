@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// pingingSlipFinder is a mockSlipFinder that answers Ping with err.
+type pingingSlipFinder struct {
+	mockSlipFinder
+	err error
+}
+
+func (f *pingingSlipFinder) Ping(_ context.Context) error {
+	return f.err
+}
+
+func healthcheckTestDeps() *Dependencies {
+	deps := allTestDeps(&mockResolver{})
+	deps.GitRepoFactory = func(string, Logger) (domain.LocalGitRepository, error) {
+		return &mockGitRepo{gitContext: &domain.GitContext{Repository: "org/repo", HeadSHA: "c0"}}, nil
+	}
+	deps.SlipFinderFactory = func(*AppConfig, Logger) (domain.SlipFinder, error) {
+		return &pingingSlipFinder{}, nil
+	}
+	deps.VaultChecker = func(context.Context) (bool, error) { return true, nil }
+	return deps
+}
+
+func runHealthcheckCmd(t *testing.T, deps *Dependencies, args ...string) (healthReportJSON, error) {
+	t.Helper()
+	var stdout bytes.Buffer
+	deps.Stdout = &stdout
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs(append([]string{"healthcheck", "--format", "json"}, args...))
+	err := cmd.Execute()
+
+	var report healthReportJSON
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &report))
+	return report, err
+}
+
+// statuses maps each check in report to its status.
+func statuses(report healthReportJSON) map[string]string {
+	out := make(map[string]string, len(report.Checks))
+	for _, check := range report.Checks {
+		out[check.Name] = check.Status
+	}
+	return out
+}
+
+func TestHealthcheckCmd(t *testing.T) {
+	tests := []struct {
+		name       string
+		modify     func(deps *Dependencies)
+		wantStatus map[string]string
+		wantErr    string
+	}{
+		{
+			name: "healthy",
+			wantStatus: map[string]string{
+				"git": healthOK, "vault": healthOK, "config": healthOK, "store": healthOK, "cache": healthSkipped,
+			},
+		},
+		{
+			name: "not a repository",
+			modify: func(deps *Dependencies) {
+				deps.GitRepoFactory = func(string, Logger) (domain.LocalGitRepository, error) {
+					return nil, domain.ErrRepositoryNotFound
+				}
+			},
+			wantStatus: map[string]string{
+				"git": healthFail, "vault": healthOK, "config": healthOK, "store": healthOK, "cache": healthSkipped,
+			},
+			wantErr: "healthcheck failed: git",
+		},
+		{
+			name: "vault not used",
+			modify: func(deps *Dependencies) {
+				deps.VaultChecker = func(context.Context) (bool, error) { return false, nil }
+			},
+			wantStatus: map[string]string{
+				"git": healthOK, "vault": healthSkipped, "config": healthOK, "store": healthOK, "cache": healthSkipped,
+			},
+		},
+		{
+			name: "vault and config unavailable",
+			modify: func(deps *Dependencies) {
+				deps.VaultChecker = func(context.Context) (bool, error) { return true, errors.New("permission denied") }
+				deps.ConfigLoader = func() (*AppConfig, error) { return nil, errors.New("permission denied") }
+			},
+			wantStatus: map[string]string{
+				"git": healthOK, "vault": healthFail, "config": healthFail, "store": healthSkipped, "cache": healthSkipped,
+			},
+			wantErr: "healthcheck failed: vault, config",
+		},
+		{
+			name: "store unreachable",
+			modify: func(deps *Dependencies) {
+				deps.SlipFinderFactory = func(*AppConfig, Logger) (domain.SlipFinder, error) {
+					return &pingingSlipFinder{err: errors.New("connection refused")}, nil
+				}
+			},
+			wantStatus: map[string]string{
+				"git": healthOK, "vault": healthOK, "config": healthOK, "store": healthFail, "cache": healthSkipped,
+			},
+			wantErr: "healthcheck failed: store",
+		},
+		{
+			name: "store without ping",
+			modify: func(deps *Dependencies) {
+				deps.SlipFinderFactory = func(*AppConfig, Logger) (domain.SlipFinder, error) {
+					return &mockSlipFinder{}, nil
+				}
+			},
+			wantStatus: map[string]string{
+				"git": healthOK, "vault": healthOK, "config": healthOK, "store": healthOK, "cache": healthSkipped,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := healthcheckTestDeps()
+			if tt.modify != nil {
+				tt.modify(deps)
+			}
+
+			report, err := runHealthcheckCmd(t, deps)
+
+			assert.Equal(t, tt.wantStatus, statuses(report))
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantErr, err.Error())
+				assert.Equal(t, healthFail, report.Status)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, healthOK, report.Status)
+		})
+	}
+}
+
+func TestHealthcheckCmd_Details(t *testing.T) {
+	report, err := runHealthcheckCmd(t, healthcheckTestDeps())
+
+	require.NoError(t, err)
+	require.Len(t, report.Checks, 5)
+	assert.Equal(t, "git", report.Checks[0].Name)
+	assert.Equal(t, "org/repo at c0", report.Checks[0].Detail)
+	assert.Equal(t, "database ci", report.Checks[2].Detail)
+	assert.Equal(t, "no cache configured", report.Checks[4].Detail)
+	assert.Equal(t, Version, report.Version)
+}
+
+func TestHealthcheckCmd_Text(t *testing.T) {
+	var stdout bytes.Buffer
+	deps := healthcheckTestDeps()
+	deps.Stdout = &stdout
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{"healthcheck"})
+	require.NoError(t, cmd.Execute())
+
+	out := stdout.String()
+	assert.Contains(t, out, "CHECK   STATUS   DURATION  DETAIL")
+	assert.Contains(t, out, "cache   skipped")
+	assert.Contains(t, out, "status: ok")
+}
+
+func TestHealthcheckCmd_InvalidFormat(t *testing.T) {
+	cmd := NewRootCmdWithDeps(healthcheckTestDeps())
+	cmd.SetArgs([]string{"healthcheck", "--format", "yaml"})
+
+	err := cmd.Execute()
+
+	require.Error(t, err)
+	assert.Equal(t, domain.CodeInvalidInput, domain.CodeOf(err))
+}
@@ -0,0 +1,304 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// interactiveSHALength is how many characters of a commit SHA the
+// interactive listing shows.
+const interactiveSHALength = 12
+
+// newInteractiveCmd creates "interactive", which lets a person browse the
+// HEAD ancestry and inspect the slips of the commits they pick.
+func newInteractiveCmd(deps *Dependencies) *cobra.Command {
+	interactiveCmd := &cobra.Command{
+		Use:   "interactive [path]",
+		Short: "Browse the commit ancestry and inspect the slips of its commits",
+		Long: `List the HEAD ancestry, marking the commits that have slips, and show the
+slips of the commits you pick, with their step statuses.
+
+Pick a commit by its number (0 is HEAD) or a prefix of its SHA. Picking a
+commit again queries the store again, so step statuses are current. Enter l
+to list the ancestry again and q, or end the input, to quit.
+
+Examples:
+  slippy-find interactive
+  slippy-find interactive /path/to/repo --depth 50`,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runInteractive(cmd, args, deps)
+		},
+	}
+
+	interactiveCmd.Flags().IntVarP(&depth, "depth", "d", domain.DefaultAncestryDepth,
+		"Number of ancestry commits to list")
+	interactiveCmd.Flags().BoolVarP(&verbose, "verbose", "v", false,
+		"Enable verbose/debug logging")
+	interactiveCmd.Flags().StringVar(&logFormat, "log-format", "",
+		"Log format: json or console (default from LOG_FORMAT, else json)")
+	interactiveCmd.Flags().StringVar(&logFile, "log-file", "",
+		"Also write logs to this file, rotated by size (default from LOG_FILE)")
+
+	return interactiveCmd
+}
+
+// interactiveCommit is a commit of the listed ancestry.
+type interactiveCommit struct {
+	sha   string
+	slips int
+}
+
+// runInteractive lists the ancestry and answers picks read from stdin until
+// the user quits. A panic fails the run like any error, so the session is
+// still closed.
+func runInteractive(cmd *cobra.Command, args []string, deps *Dependencies) (err error) {
+	var s *session
+	defer func() {
+		if r := recover(); r != nil {
+			err = recoveredError(cmd.Context(), s, r)
+		}
+		closeSession(deps, s)
+	}()
+
+	s, err = openSession(cmd, args, deps, nil)
+	if err != nil {
+		return err
+	}
+	ctx, log := s.ctx, s.log
+
+	gitCtx, err := s.gitRepo.GetGitContext(ctx)
+	if err != nil {
+		log.Error(ctx, "failed to read git context", err, nil)
+		return domain.WithCode(domain.CodeGit, fmt.Errorf("failed to get git context: %w", err))
+	}
+	shas, err := s.gitRepo.GetCommitAncestry(ctx, s.input.Depth)
+	if err != nil {
+		log.Error(ctx, "failed to walk ancestry", err, nil)
+		return domain.WithCode(domain.CodeGit, fmt.Errorf("failed to get commit ancestry: %w", err))
+	}
+	matches, err := s.store.FindAllByCommits(ctx, gitCtx.Repository, shas)
+	if err != nil {
+		log.Error(ctx, "failed to find slips", err, nil)
+		return domain.WithCode(domain.CodeStore, fmt.Errorf("failed to find slips: %w", err))
+	}
+	slipCounts := make(map[string]int, len(matches))
+	for _, m := range matches {
+		slipCounts[m.MatchedCommit]++
+	}
+	commits := make([]interactiveCommit, len(shas))
+	for i, sha := range shas {
+		commits[i] = interactiveCommit{sha: sha, slips: slipCounts[sha]}
+	}
+
+	out := commandStdout(deps)
+	if err := writeInteractiveAncestry(out, gitCtx, commits); err != nil {
+		return outputError(err)
+	}
+
+	lines := readLines(ctx, cmd.InOrStdin())
+	for {
+		if _, err := fmt.Fprint(out, "\nCommit number or SHA prefix (l to list, q to quit): "); err != nil {
+			return outputError(err)
+		}
+		var line string
+		var ok bool
+		select {
+		case line, ok = <-lines:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if !ok {
+			_, _ = fmt.Fprintln(out)
+			return nil
+		}
+
+		var werr error
+		switch line = strings.TrimSpace(line); line {
+		case "":
+			continue
+		case "q", "quit", "exit":
+			return nil
+		case "l", "list":
+			werr = writeInteractiveAncestry(out, gitCtx, commits)
+		default:
+			werr = showInteractivePick(ctx, out, s.store, gitCtx.Repository, commits, line)
+		}
+		if werr != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return outputError(werr)
+		}
+	}
+}
+
+// readLines sends the lines of in on the returned channel, closing it at the
+// end of in. It stops early when ctx is done.
+func readLines(ctx context.Context, in io.Reader) <-chan string {
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(in)
+		for scanner.Scan() {
+			select {
+			case lines <- scanner.Text():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return lines
+}
+
+// writeInteractiveAncestry writes the numbered ancestry, newest first, with
+// the number of slips recorded for each commit.
+func writeInteractiveAncestry(w io.Writer, gitCtx *domain.GitContext, commits []interactiveCommit) error {
+	branch := gitCtx.Branch
+	if branch == "" {
+		branch = "detached HEAD"
+	}
+	if _, err := fmt.Fprintf(w, "Ancestry of %s (%s), newest first:\n\n", gitCtx.Repository, branch); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for i, c := range commits {
+		var err error
+		switch c.slips {
+		case 0:
+			_, err = fmt.Fprintf(tw, "%4d\t%s\n", i, shortSHA(c.sha))
+		case 1:
+			_, err = fmt.Fprintf(tw, "%4d\t%s\t1 slip\n", i, shortSHA(c.sha))
+		default:
+			_, err = fmt.Fprintf(tw, "%4d\t%s\t%d slips\n", i, shortSHA(c.sha), c.slips)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// showInteractivePick queries the slips of the commit pick names and writes
+// them, or why the pick names no commit.
+func showInteractivePick(
+	ctx context.Context,
+	w io.Writer,
+	finder domain.SlipFinder,
+	repository string,
+	commits []interactiveCommit,
+	pick string,
+) error {
+	sha, problem := pickCommit(commits, pick)
+	if problem != "" {
+		_, err := fmt.Fprintln(w, problem)
+		return err
+	}
+
+	matches, err := finder.FindAllByCommits(ctx, repository, []string{sha})
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		_, err = fmt.Fprintf(w, "Failed to find slips for %s: %v\n", shortSHA(sha), err)
+		return err
+	}
+	if len(matches) == 0 {
+		_, err = fmt.Fprintf(w, "No slip recorded for %s.\n", shortSHA(sha))
+		return err
+	}
+
+	slips := make([]*domain.Slip, len(matches))
+	for i, m := range matches {
+		slips[i] = m.Slip
+	}
+	slices.SortFunc(slips, func(a, b *domain.Slip) int {
+		if a.Precedes(b) {
+			return -1
+		}
+		return 1
+	})
+	for _, slip := range slips {
+		if err := writeInteractiveSlip(w, slip); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pickCommit returns the commit pick names: a number in the listing, or a
+// SHA prefix of a single listed commit. Otherwise it returns a message saying
+// why pick names none.
+func pickCommit(commits []interactiveCommit, pick string) (string, string) {
+	if n, err := strconv.Atoi(pick); err == nil && len(pick) < 4 {
+		if n < 0 || n >= len(commits) {
+			return "", fmt.Sprintf("No commit %d: pick 0 to %d.", n, len(commits)-1)
+		}
+		return commits[n].sha, ""
+	}
+
+	pick = strings.ToLower(pick)
+	var found []string
+	for _, c := range commits {
+		if strings.HasPrefix(c.sha, pick) {
+			found = append(found, c.sha)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return "", fmt.Sprintf("No listed commit starts with %q.", pick)
+	case 1:
+		return found[0], ""
+	default:
+		return "", fmt.Sprintf("%q matches %d listed commits; enter more of the SHA.", pick, len(found))
+	}
+}
+
+// writeInteractiveSlip writes slip with its step statuses, sorted by step.
+func writeInteractiveSlip(w io.Writer, slip *domain.Slip) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "\nSlip %s\n", slip.CorrelationID)
+	if slip.Mock {
+		_, _ = fmt.Fprintln(tw, "  (invented by the mock store)")
+	}
+	_, _ = fmt.Fprintf(tw, "  commit:\t%s\n", slip.CommitSHA)
+	if slip.Branch != "" {
+		_, _ = fmt.Fprintf(tw, "  branch:\t%s\n", slip.Branch)
+	}
+	if !slip.CreatedAt.IsZero() {
+		_, _ = fmt.Fprintf(tw, "  created:\t%s\n", slip.CreatedAt.UTC().Format(time.RFC3339))
+	}
+	if len(slip.Components) > 0 {
+		_, _ = fmt.Fprintf(tw, "  components:\t%s\n", strings.Join(slip.Components, ", "))
+	}
+	if len(slip.Steps) == 0 {
+		_, _ = fmt.Fprintln(tw, "  steps:\tnone recorded")
+	} else {
+		_, _ = fmt.Fprintln(tw, "  steps:")
+		for _, step := range slices.Sorted(maps.Keys(slip.Steps)) {
+			_, _ = fmt.Fprintf(tw, "    %s\t%s\n", step, slip.Steps[step])
+		}
+	}
+	return tw.Flush()
+}
+
+// shortSHA abbreviates sha for display.
+func shortSHA(sha string) string {
+	if len(sha) > interactiveSHALength {
+		return sha[:interactiveSHALength]
+	}
+	return sha
+}
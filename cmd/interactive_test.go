@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+const (
+	interactiveHead   = "aaaa1111aaaa1111aaaa1111aaaa1111aaaa1111"
+	interactiveParent = "bbbb2222bbbb2222bbbb2222bbbb2222bbbb2222"
+)
+
+// interactiveTestDeps returns allTestDeps with a two-commit ancestry whose
+// HEAD has finder's slip.
+func interactiveTestDeps(finder *mockSlipFinder) *Dependencies {
+	deps := allTestDeps(&mockResolver{})
+	deps.GitRepoFactory = func(_ string, _ Logger) (domain.LocalGitRepository, error) {
+		return &mockGitRepo{
+			gitContext: &domain.GitContext{Repository: "org/repo", Branch: "main", HeadSHA: interactiveHead},
+			commits:    []string{interactiveHead, interactiveParent},
+		}, nil
+	}
+	deps.SlipFinderFactory = func(_ *AppConfig, _ Logger) (domain.SlipFinder, error) {
+		return finder, nil
+	}
+	return deps
+}
+
+func runInteractiveCmd(t *testing.T, deps *Dependencies, input string) (string, error) {
+	t.Helper()
+	var stdout bytes.Buffer
+	deps.Stdout = &stdout
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{"interactive", "."})
+	cmd.SetIn(strings.NewReader(input))
+	err := cmd.Execute()
+	return stdout.String(), err
+}
+
+func TestInteractiveCmd_ShowsPickedSlip(t *testing.T) {
+	finder := &mockSlipFinder{
+		slip: &domain.Slip{
+			CorrelationID: "slip-1",
+			Branch:        "main",
+			CommitSHA:     interactiveHead,
+			CreatedAt:     time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC),
+			Components:    []string{"api", "web"},
+			Steps:         map[string]string{"deploy": "running", "build": "completed"},
+		},
+		matchCommit: interactiveHead,
+	}
+
+	for _, pick := range []string{"0", "aaaa11", "AAAA11"} {
+		t.Run(pick, func(t *testing.T) {
+			out, err := runInteractiveCmd(t, interactiveTestDeps(finder), pick+"\nq\n")
+
+			require.NoError(t, err)
+			assert.Contains(t, out, "Ancestry of org/repo (main), newest first:")
+			assert.Regexp(t, `0  aaaa1111aaaa  1 slip\n`, out)
+			assert.Regexp(t, `1  bbbb2222bbbb\n`, out)
+			assert.Contains(t, out, "Slip slip-1\n")
+			assert.Contains(t, out, "created:     2026-10-17T09:30:00Z\n")
+			assert.Contains(t, out, "components:  api, web\n")
+			assert.Regexp(t, `build\s+completed\n\s+deploy\s+running\n`, out)
+		})
+	}
+}
+
+func TestInteractiveCmd_BadPicks(t *testing.T) {
+	finder := &mockSlipFinder{slip: &domain.Slip{CorrelationID: "slip-1"}, matchCommit: interactiveHead}
+
+	out, err := runInteractiveCmd(t, interactiveTestDeps(finder), "7\nffff\n\nl\n")
+
+	require.NoError(t, err, "the end of the input quits")
+	assert.Contains(t, out, "No commit 7: pick 0 to 1.")
+	assert.Contains(t, out, `No listed commit starts with "ffff".`)
+	assert.Equal(t, 2, strings.Count(out, "Ancestry of org/repo"), "l lists the ancestry again")
+	assert.NotContains(t, out, "Slip slip-1")
+}
+
+func TestInteractiveCmd_NoSlip(t *testing.T) {
+	out, err := runInteractiveCmd(t, interactiveTestDeps(&mockSlipFinder{}), "1\nquit\n")
+
+	require.NoError(t, err)
+	assert.Contains(t, out, "No slip recorded for bbbb2222bbbb.")
+}
+
+func TestInteractiveCmd_StoreError(t *testing.T) {
+	finder := &mockSlipFinder{findErr: errors.New("connection refused")}
+
+	_, err := runInteractiveCmd(t, interactiveTestDeps(finder), "q\n")
+
+	require.Error(t, err)
+	assert.Equal(t, domain.CodeStore, domain.CodeOf(err))
+}
+
+func TestPickCommit(t *testing.T) {
+	commits := []interactiveCommit{{sha: "abc123"}, {sha: "abd456"}, {sha: "123999"}}
+	tests := []struct {
+		pick    string
+		want    string
+		problem string
+	}{
+		{pick: "2", want: "123999"},
+		{pick: "abc", want: "abc123"},
+		{pick: "1239", want: "123999"},
+		{pick: "ab", problem: `"ab" matches 2 listed commits; enter more of the SHA.`},
+		{pick: "-1", problem: "No commit -1: pick 0 to 2."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pick, func(t *testing.T) {
+			got, problem := pickCommit(commits, tt.pick)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.problem, problem)
+		})
+	}
+}
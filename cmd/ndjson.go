@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"syscall"
+)
+
+// errOutputClosed reports that the reader of the command's output went away,
+// such as "slippy-find batch | head -1" once head exits.
+var errOutputClosed = errors.New("output closed by its reader")
+
+// ndjsonWriter writes values as newline-delimited JSON. Each line is encoded
+// into a reused buffer and written whole in one call, and writers on several
+// goroutines are serialized, so lines never interleave. A buffered writer
+// holds lines until Flush; an unbuffered one writes each line as it comes.
+//
+// The first write error is kept: later writes and Flush return it without
+// writing, so nothing is written after a line is lost.
+type ndjsonWriter struct {
+	mu       sync.Mutex
+	out      io.Writer
+	buffered *bufio.Writer
+	line     bytes.Buffer
+	encoder  *json.Encoder
+	err      error
+}
+
+// newNDJSONWriter returns a writer of NDJSON lines to out, holding them until
+// Flush if buffered.
+func newNDJSONWriter(out io.Writer, buffered bool) *ndjsonWriter {
+	w := &ndjsonWriter{out: out}
+	if buffered {
+		w.buffered = bufio.NewWriter(out)
+		w.out = w.buffered
+	}
+	w.encoder = json.NewEncoder(&w.line)
+	return w
+}
+
+// Write writes v as one line.
+func (w *ndjsonWriter) Write(v any) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return w.err
+	}
+	w.line.Reset()
+	if err := w.encoder.Encode(v); err != nil {
+		return err
+	}
+	if _, err := w.out.Write(w.line.Bytes()); err != nil {
+		w.err = writeError(err)
+	}
+	return w.err
+}
+
+// Flush writes the lines a buffered writer holds.
+func (w *ndjsonWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil || w.buffered == nil {
+		return w.err
+	}
+	if err := w.buffered.Flush(); err != nil {
+		w.err = writeError(err)
+	}
+	return w.err
+}
+
+// writeError returns errOutputClosed, wrapping err, if err means the output's
+// reader went away, and err otherwise.
+func writeError(err error) error {
+	if brokenPipe(err) {
+		return fmt.Errorf("%w: %w", errOutputClosed, err)
+	}
+	return err
+}
+
+// brokenPipe reports whether err is a write to a pipe whose reader has
+// closed it.
+func brokenPipe(err error) bool {
+	return errors.Is(err, syscall.EPIPE) || errors.Is(err, io.ErrClosedPipe)
+}
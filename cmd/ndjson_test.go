@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingWriter counts the Write calls made on it.
+type countingWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+// pipeWriter fails every write with err.
+type pipeWriter struct {
+	err    error
+	writes int
+}
+
+func (w *pipeWriter) Write([]byte) (int, error) {
+	w.writes++
+	return 0, w.err
+}
+
+func TestNDJSONWriter_Unbuffered(t *testing.T) {
+	out := &countingWriter{}
+	w := newNDJSONWriter(out, false)
+
+	require.NoError(t, w.Write(map[string]string{"id": "a"}))
+	assert.Equal(t, "{\"id\":\"a\"}\n", out.String(), "written at once")
+	require.NoError(t, w.Write(map[string]string{"id": "b"}))
+	require.NoError(t, w.Flush())
+
+	assert.Equal(t, 2, out.writes, "one write per line")
+}
+
+func TestNDJSONWriter_Buffered(t *testing.T) {
+	out := &countingWriter{}
+	w := newNDJSONWriter(out, true)
+
+	require.NoError(t, w.Write(map[string]string{"id": "a"}))
+	require.NoError(t, w.Write(map[string]string{"id": "b"}))
+	assert.Zero(t, out.writes, "held until Flush")
+	require.NoError(t, w.Flush())
+
+	assert.Equal(t, "{\"id\":\"a\"}\n{\"id\":\"b\"}\n", out.String())
+	assert.Equal(t, 1, out.writes)
+}
+
+func TestNDJSONWriter_Concurrent(t *testing.T) {
+	out := &countingWriter{}
+	w := newNDJSONWriter(out, false)
+	value := strings.Repeat("x", 4096)
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, w.Write(map[string]string{"id": fmt.Sprint(i), "value": value}))
+		}()
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	require.Len(t, lines, 50)
+	for _, line := range lines {
+		assert.True(t, strings.HasPrefix(line, `{"id":"`) && strings.HasSuffix(line, `"}`), "whole line")
+	}
+}
+
+func TestNDJSONWriter_BrokenPipe(t *testing.T) {
+	for _, pipeErr := range []error{syscall.EPIPE, io.ErrClosedPipe} {
+		t.Run(pipeErr.Error(), func(t *testing.T) {
+			out := &pipeWriter{err: fmt.Errorf("write /dev/stdout: %w", pipeErr)}
+			w := newNDJSONWriter(out, false)
+
+			err := w.Write(map[string]string{"id": "a"})
+
+			require.ErrorIs(t, err, errOutputClosed)
+			assert.ErrorIs(t, err, pipeErr)
+			assert.ErrorIs(t, w.Write(map[string]string{"id": "b"}), errOutputClosed)
+			assert.ErrorIs(t, w.Flush(), errOutputClosed)
+			assert.Equal(t, 1, out.writes, "nothing is written after a lost line")
+		})
+	}
+}
+
+func TestNDJSONWriter_FlushError(t *testing.T) {
+	out := &pipeWriter{err: syscall.EPIPE}
+	w := newNDJSONWriter(out, true)
+
+	require.NoError(t, w.Write(map[string]string{"id": "a"}))
+
+	assert.ErrorIs(t, w.Flush(), errOutputClosed)
+}
@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// pendingFinder is a slip finder that is still being opened in the
+// background, so connecting to the store overlaps opening the repository and
+// walking its ancestry. Calls wait for the open to finish, and fail with its
+// error if it failed.
+type pendingFinder struct {
+	done     chan struct{}
+	finder   domain.SlipFinder
+	err      error
+	duration time.Duration
+}
+
+// openFinderAsync starts opening a finder with open, logging a failure to log.
+func openFinderAsync(ctx context.Context, log Logger, open func() (domain.SlipFinder, error)) *pendingFinder {
+	p := &pendingFinder{done: make(chan struct{})}
+	go func() {
+		defer close(p.done)
+		start := time.Now()
+		finder, err := open()
+		p.duration = time.Since(start)
+		if err != nil {
+			p.err = domain.WithCode(domain.CodeStore, fmt.Errorf("database error: %w", err))
+			log.Error(ctx, "failed to initialize slip finder", p.err, nil)
+			return
+		}
+		p.finder = finder
+	}()
+	return p
+}
+
+// wait returns the opened finder once the open finishes, or ctx's error if
+// it is done first.
+func (p *pendingFinder) wait(ctx context.Context) (domain.SlipFinder, error) {
+	select {
+	case <-p.done:
+		return p.finder, p.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// opened waits for the open to finish, returning its error.
+func (p *pendingFinder) opened() error {
+	<-p.done
+	return p.err
+}
+
+// timing returns how long the open took. It waits for the open to finish.
+func (p *pendingFinder) timing() domain.StageTiming {
+	<-p.done
+	return domain.StageTiming{Stage: domain.StageStoreOpen, Duration: p.duration}
+}
+
+// FindByCommits implements domain.SlipFinder.
+func (p *pendingFinder) FindByCommits(
+	ctx context.Context,
+	repository string,
+	commits []string,
+) (*domain.Slip, string, error) {
+	finder, err := p.wait(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	return finder.FindByCommits(ctx, repository, commits)
+}
+
+// FindAllByCommits implements domain.SlipFinder.
+func (p *pendingFinder) FindAllByCommits(
+	ctx context.Context,
+	repository string,
+	commits []string,
+) ([]domain.SlipMatch, error) {
+	finder, err := p.wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return finder.FindAllByCommits(ctx, repository, commits)
+}
+
+// FindNearestByCommits delegates to the opened finder if it is a
+// domain.NearestSlipsFinder. Returns domain.ErrNearestUnsupported otherwise.
+func (p *pendingFinder) FindNearestByCommits(
+	ctx context.Context,
+	repository string,
+	commits []string,
+) ([]domain.SlipMatch, error) {
+	finder, err := p.wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nearest, ok := finder.(domain.NearestSlipsFinder)
+	if !ok {
+		return nil, domain.ErrNearestUnsupported
+	}
+	return nearest.FindNearestByCommits(ctx, repository, commits)
+}
+
+// FindLatestByBranch implements domain.SlipFinder.
+func (p *pendingFinder) FindLatestByBranch(ctx context.Context, repository, branch string) (*domain.Slip, error) {
+	finder, err := p.wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return finder.FindLatestByBranch(ctx, repository, branch)
+}
+
+// FindByID implements domain.SlipFinder.
+func (p *pendingFinder) FindByID(ctx context.Context, correlationID string) (*domain.Slip, error) {
+	finder, err := p.wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return finder.FindByID(ctx, correlationID)
+}
+
+// AppendHistory delegates to the opened finder if it is a domain.SlipAnnotator.
+// Returns domain.ErrAnnotationUnsupported otherwise.
+func (p *pendingFinder) AppendHistory(ctx context.Context, correlationID string, entry domain.HistoryEntry) error {
+	finder, err := p.wait(ctx)
+	if err != nil {
+		return err
+	}
+	annotator, ok := finder.(domain.SlipAnnotator)
+	if !ok {
+		return domain.ErrAnnotationUnsupported
+	}
+	return annotator.AppendHistory(ctx, correlationID, entry)
+}
+
+// Ping delegates to the opened finder if it is a domain.StorePinger.
+// Returns domain.ErrPingUnsupported otherwise.
+func (p *pendingFinder) Ping(ctx context.Context) error {
+	finder, err := p.wait(ctx)
+	if err != nil {
+		return err
+	}
+	pinger, ok := finder.(domain.StorePinger)
+	if !ok {
+		return domain.ErrPingUnsupported
+	}
+	return pinger.Ping(ctx)
+}
+
+// ChunkSize delegates to the opened finder if it is a domain.ChunkSizer,
+// waiting for the open. Returns 0, the resolver's default, otherwise, or if
+// the open failed.
+func (p *pendingFinder) ChunkSize() int {
+	if p.opened() != nil {
+		return 0
+	}
+	if sizer, ok := p.finder.(domain.ChunkSizer); ok {
+		return sizer.ChunkSize()
+	}
+	return 0
+}
+
+// DescribeEnvironment delegates to the opened finder if it is a
+// domain.EnvironmentDescriber, waiting for the open. It describes nothing if
+// the open failed.
+func (p *pendingFinder) DescribeEnvironment(env *domain.Environment) {
+	if p.opened() != nil {
+		return
+	}
+	if describer, ok := p.finder.(domain.EnvironmentDescriber); ok {
+		describer.DescribeEnvironment(env)
+	}
+}
+
+// Close waits for the open to finish and closes the finder if it opened.
+func (p *pendingFinder) Close() error {
+	if p.opened() != nil {
+		return nil
+	}
+	return p.finder.Close()
+}
+
+// storeResolver is a resolver whose store is opened by a pendingFinder. A
+// failure to open the store fails every call, even one that never reached
+// the store, so the run fails as it would have had the store been opened
+// first.
+type storeResolver struct {
+	domain.Resolver
+	store *pendingFinder
+}
+
+// Resolve implements domain.Resolver.
+func (r *storeResolver) Resolve(ctx context.Context, input domain.ResolveInput) (*domain.ResolveOutput, error) {
+	result, err := r.Resolver.Resolve(ctx, input)
+	if openErr := r.store.opened(); openErr != nil {
+		return nil, openErr
+	}
+	return result, err
+}
+
+// ResolveAll implements domain.Resolver.
+func (r *storeResolver) ResolveAll(ctx context.Context, input domain.ResolveInput) (*domain.ResolveAllOutput, error) {
+	result, err := r.Resolver.ResolveAll(ctx, input)
+	if openErr := r.store.opened(); openErr != nil {
+		return nil, openErr
+	}
+	return result, err
+}
+
+// ResolveComponents implements domain.Resolver.
+func (r *storeResolver) ResolveComponents(
+	ctx context.Context,
+	input domain.ResolveInput,
+	components map[string][]string,
+) (map[string]*domain.ResolveOutput, error) {
+	results, err := r.Resolver.ResolveComponents(ctx, input, components)
+	if openErr := r.store.opened(); openErr != nil {
+		return nil, openErr
+	}
+	return results, err
+}
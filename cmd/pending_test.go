@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// walkingResolver signals walking, as if its ancestry walk had begun, and
+// then queries finder.
+type walkingResolver struct {
+	mockResolver
+	finder  domain.SlipFinder
+	walking chan struct{}
+}
+
+func (r *walkingResolver) Resolve(ctx context.Context, _ domain.ResolveInput) (*domain.ResolveOutput, error) {
+	close(r.walking)
+	slip, commit, err := r.finder.FindByCommits(ctx, "org/repo", []string{"abc123"})
+	if err != nil {
+		return nil, err
+	}
+	return &domain.ResolveOutput{CorrelationID: slip.CorrelationID, MatchedCommit: commit}, nil
+}
+
+func TestRunResolve_OpensStoreDuringWalk(t *testing.T) {
+	walking := make(chan struct{})
+	finder := &mockSlipFinder{slip: &domain.Slip{CorrelationID: "corr-1"}, matchCommit: "abc123"}
+	deps := allTestDeps(&mockResolver{})
+	deps.SlipFinderFactory = func(*AppConfig, Logger) (domain.SlipFinder, error) {
+		select {
+		case <-walking:
+			return finder, nil
+		case <-time.After(5 * time.Second):
+			return nil, errors.New("store opened before the walk began")
+		}
+	}
+	deps.ResolverFactory = func(_ domain.LocalGitRepository, f domain.SlipFinder, _ Logger) domain.Resolver {
+		return &walkingResolver{finder: f, walking: walking}
+	}
+	writer := &mockOutputWriter{}
+	deps.OutputWriterFactory = func() domain.OutputWriter { return writer }
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{"."})
+	err := cmd.Execute()
+
+	require.NoError(t, err)
+	assert.Equal(t, "corr-1", writer.writtenID)
+	assert.True(t, finder.closeCalled, "finder closed with the session")
+}
+
+func TestPendingFinder(t *testing.T) {
+	finder := &mockSlipFinder{slip: &domain.Slip{CorrelationID: "corr-1"}, matchCommit: "abc123"}
+	p := openFinderAsync(context.Background(), &mockLogger{}, func() (domain.SlipFinder, error) {
+		return finder, nil
+	})
+
+	slip, commit, err := p.FindByCommits(context.Background(), "org/repo", []string{"abc123"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "corr-1", slip.CorrelationID)
+	assert.Equal(t, "abc123", commit)
+	assert.ErrorIs(t, p.Ping(context.Background()), domain.ErrPingUnsupported)
+	assert.Zero(t, p.ChunkSize(), "the resolver's default")
+	assert.Equal(t, domain.StageStoreOpen, p.timing().Stage)
+	require.NoError(t, p.Close())
+	assert.True(t, finder.closeCalled)
+}
+
+func TestPendingFinder_OpenFails(t *testing.T) {
+	p := openFinderAsync(context.Background(), &mockLogger{}, func() (domain.SlipFinder, error) {
+		return nil, errors.New("connection refused")
+	})
+
+	_, err := p.FindLatestByBranch(context.Background(), "org/repo", "main")
+
+	require.Error(t, err)
+	assert.Equal(t, "database error: connection refused", err.Error())
+	assert.Equal(t, domain.CodeStore, domain.CodeOf(err))
+	assert.NoError(t, p.Close(), "nothing to close")
+}
+
+func TestPendingFinder_WaitCancelled(t *testing.T) {
+	release := make(chan struct{})
+	p := openFinderAsync(context.Background(), &mockLogger{}, func() (domain.SlipFinder, error) {
+		<-release
+		return &mockSlipFinder{}, nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := p.FindByID(ctx, "corr-1")
+
+	assert.ErrorIs(t, err, context.Canceled)
+	close(release)
+	assert.NoError(t, p.Close())
+}
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// publishResolutions sends an event for each result to deps.ResolutionPublisher,
+// when one is configured. Like auditing, it runs after the resolution was
+// written to stdout, so failures are logged as warnings only.
+func publishResolutions(s *session, deps *Dependencies, elapsed time.Duration, results ...*domain.ResolveOutput) {
+	if deps.ResolutionPublisher == nil {
+		return
+	}
+	ctx := s.ctx
+	if err := deps.ResolutionPublisher.PublishResolutions(ctx, auditEvents(s, deps, elapsed, results)); err != nil {
+		s.log.Warn(ctx, "failed to publish resolution event", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
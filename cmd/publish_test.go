@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// mockResolutionPublisher implements domain.ResolutionPublisher, recording events.
+type mockResolutionPublisher struct {
+	events []domain.AuditEvent
+	err    error
+}
+
+func (m *mockResolutionPublisher) PublishResolutions(_ context.Context, events []domain.AuditEvent) error {
+	m.events = append(m.events, events...)
+	return m.err
+}
+
+func TestRootCmd_PublishesResolutions(t *testing.T) {
+	publisher := &mockResolutionPublisher{}
+	deps := auditTestDeps(&mockAuditWriter{}, &domain.ResolveOutput{
+		CorrelationID: "slip-1",
+		MatchedCommit: "c1",
+		Repository:    "org/repo",
+		ResolvedBy:    domain.ResolvedByAncestry,
+	})
+	deps.ResolutionPublisher = publisher
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{"."})
+	require.NoError(t, cmd.Execute())
+
+	require.Len(t, publisher.events, 1)
+	event := publisher.events[0]
+	assert.Equal(t, "slip-1", event.CorrelationID)
+	assert.Equal(t, "c1", event.MatchedCommit)
+	assert.Equal(t, "c0", event.HeadSHA)
+	assert.Equal(t, "org/repo", event.Repository)
+	assert.Equal(t, "build/42", event.Caller.Pipeline)
+	assert.False(t, event.Timestamp.IsZero())
+}
+
+func TestRootCmd_PublishesComponentResolutions(t *testing.T) {
+	publisher := &mockResolutionPublisher{}
+	deps := auditTestDeps(&mockAuditWriter{}, nil)
+	deps.ResolverFactory = func(domain.LocalGitRepository, domain.SlipFinder, Logger) domain.Resolver {
+		return &mockResolver{componentOutputs: map[string]*domain.ResolveOutput{
+			"web": {CorrelationID: "slip-web", Component: "web"},
+			"api": {CorrelationID: "slip-api", Component: "api"},
+		}}
+	}
+	deps.ComponentLoader = func(string) (map[string][]string, error) {
+		return map[string][]string{"api": {"api/"}, "web": {"web/"}}, nil
+	}
+	deps.ResolutionPublisher = publisher
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{".", "--components", "api,web"})
+	require.NoError(t, cmd.Execute())
+
+	require.Len(t, publisher.events, 2)
+	assert.Equal(t, "slip-api", publisher.events[0].CorrelationID)
+	assert.Equal(t, "slip-web", publisher.events[1].CorrelationID)
+}
+
+func TestRootCmd_PublishFailureIsOnlyAWarning(t *testing.T) {
+	deps := auditTestDeps(&mockAuditWriter{}, &domain.ResolveOutput{CorrelationID: "slip-1"})
+	deps.ResolutionPublisher = &mockResolutionPublisher{err: errors.New("no brokers available")}
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{"."})
+
+	assert.NoError(t, cmd.Execute())
+}
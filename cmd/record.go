@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// recordSlip records result against its matched commit in the repository, as
+// a git note with --record-note and a lightweight tag with --record-tag,
+// pushing each to origin with --record-push. The resolution has already been
+// written to stdout, so failures are logged as warnings rather than failing
+// the run.
+func recordSlip(s *session, result *domain.ResolveOutput) {
+	var kinds []domain.RecordKind
+	if recordNote {
+		kinds = append(kinds, domain.RecordNote)
+	}
+	if recordTag {
+		kinds = append(kinds, domain.RecordTag)
+	}
+	if len(kinds) == 0 {
+		return
+	}
+	ctx, log := s.ctx, s.log
+
+	recorder, ok := s.gitRepo.(domain.SlipRecorder)
+	if !ok {
+		log.Warn(ctx, "failed to record resolved slip in the repository", map[string]interface{}{
+			"error": domain.ErrRecordUnsupported.Error(),
+		})
+		return
+	}
+	if result.MatchedCommit == "" {
+		log.Debug(ctx, "no matched commit to record the resolved slip against", map[string]interface{}{
+			"resolved_by": result.ResolvedBy,
+		})
+		return
+	}
+
+	for _, kind := range kinds {
+		err := recorder.RecordSlip(ctx, domain.SlipRecord{
+			Commit:        result.MatchedCommit,
+			CorrelationID: result.CorrelationID,
+			Kind:          kind,
+			Push:          recordPush,
+		})
+		if err != nil {
+			log.Warn(ctx, "failed to record resolved slip in the repository", map[string]interface{}{
+				"kind":           string(kind),
+				"matched_commit": result.MatchedCommit,
+				"error":          err.Error(),
+			})
+		}
+	}
+}
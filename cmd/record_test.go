@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// recordingGitRepo is a mockGitRepo that implements domain.SlipRecorder.
+type recordingGitRepo struct {
+	mockGitRepo
+	records []domain.SlipRecord
+	err     error
+}
+
+func (r *recordingGitRepo) RecordSlip(_ context.Context, record domain.SlipRecord) error {
+	r.records = append(r.records, record)
+	return r.err
+}
+
+func recordTestDeps(repo domain.LocalGitRepository, output *domain.ResolveOutput) *Dependencies {
+	deps := allTestDeps(&mockResolver{output: output})
+	deps.OutputWriterFactory = func() domain.OutputWriter { return &mockOutputWriter{} }
+	deps.GitRepoFactory = func(string, Logger) (domain.LocalGitRepository, error) { return repo, nil }
+	return deps
+}
+
+func TestRootCmd_RecordSlip(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          []string
+		matchedCommit string
+		want          []domain.SlipRecord
+	}{
+		{name: "disabled by default", matchedCommit: "c1"},
+		{
+			name:          "note",
+			args:          []string{"--record-note"},
+			matchedCommit: "c1",
+			want:          []domain.SlipRecord{{Commit: "c1", CorrelationID: "slip-1", Kind: domain.RecordNote}},
+		},
+		{
+			name:          "note and tag, pushed",
+			args:          []string{"--record-note", "--record-tag", "--record-push"},
+			matchedCommit: "c1",
+			want: []domain.SlipRecord{
+				{Commit: "c1", CorrelationID: "slip-1", Kind: domain.RecordNote, Push: true},
+				{Commit: "c1", CorrelationID: "slip-1", Kind: domain.RecordTag, Push: true},
+			},
+		},
+		{name: "no matched commit", args: []string{"--record-tag"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &recordingGitRepo{}
+			deps := recordTestDeps(repo, &domain.ResolveOutput{CorrelationID: "slip-1", MatchedCommit: tt.matchedCommit})
+
+			cmd := NewRootCmdWithDeps(deps)
+			cmd.SetArgs(append([]string{"."}, tt.args...))
+			require.NoError(t, cmd.Execute())
+
+			assert.Equal(t, tt.want, repo.records)
+		})
+	}
+}
+
+func TestRootCmd_RecordSlip_FailuresAreWarnings(t *testing.T) {
+	tests := []struct {
+		name string
+		repo domain.LocalGitRepository
+	}{
+		{name: "recording fails", repo: &recordingGitRepo{err: errors.New("failed to push refs/notes/slippy")}},
+		{name: "repository cannot record", repo: &mockGitRepo{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer := &mockOutputWriter{}
+			deps := recordTestDeps(tt.repo, &domain.ResolveOutput{CorrelationID: "slip-1", MatchedCommit: "c1"})
+			deps.OutputWriterFactory = func() domain.OutputWriter { return writer }
+
+			cmd := NewRootCmdWithDeps(deps)
+			cmd.SetArgs([]string{".", "--record-note", "--record-push"})
+			require.NoError(t, cmd.Execute())
+
+			assert.Equal(t, "slip-1", writer.writtenID)
+		})
+	}
+}
+
+func TestRootCmd_RecordSlip_UsageErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantMsg string
+	}{
+		{
+			name:    "push alone",
+			args:    []string{"--record-push"},
+			wantMsg: "--record-push requires --record-note or --record-tag",
+		},
+		{
+			name:    "with --components",
+			args:    []string{"--record-tag", "--components", "web"},
+			wantMsg: "cannot be combined with --components",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := recordTestDeps(&recordingGitRepo{}, &domain.ResolveOutput{CorrelationID: "slip-1"})
+
+			cmd := NewRootCmdWithDeps(deps)
+			cmd.SetArgs(append([]string{"."}, tt.args...))
+			err := cmd.Execute()
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantMsg)
+			assert.Equal(t, domain.CodeInvalidInput, domain.CodeOf(err))
+		})
+	}
+}
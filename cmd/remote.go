@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// remoteFlags are the flags that apply when resolving through a serve-mode
+// server; the server decides everything else.
+var remoteFlags = []string{
+	"via-socket", "depth", "selection-policy", "format",
+	"gitlab-dotenv", "buildkite", "argocd-app", "octopus", "backstage", "spinnaker-artifact", "azure-devops-tag",
+	"notify-url", "github-deployment",
+	"verbose", "log-format", "log-file",
+}
+
+// runViaSocket resolves the slip for the repository at args[0] (or the
+// current directory) through the serve-mode server on the --via-socket
+// socket, and writes the result like a local resolution.
+func runViaSocket(cmd *cobra.Command, args []string, deps *Dependencies) error {
+	if deps == nil || deps.SocketResolverFactory == nil {
+		return configError(errors.New("socket client not configured"))
+	}
+	if unsupported := unsupportedRemoteFlags(cmd); len(unsupported) > 0 {
+		return usageErrorf("--via-socket cannot be combined with %s", strings.Join(unsupported, ", "))
+	}
+	if err := applyLogFlags(deps); err != nil {
+		return err
+	}
+	log := deps.LoggerFactory()
+	ctx := cmd.Context()
+
+	result, err := resolveRemote(ctx, repoRoot(deps, args), deps.SocketResolverFactory(viaSocket))
+	if err != nil {
+		log.Error(ctx, "failed to resolve slip via socket", err, map[string]interface{}{
+			"socket": viaSocket,
+		})
+		return err
+	}
+	return writeRemoteResult(ctx, log, deps, result, viaSocket)
+}
+
+// tryDaemon resolves through the local daemon named by SLIPPY_DAEMON, and
+// reports whether it did. It reports false, for the command to resolve
+// directly, when no daemon is configured, the run uses flags or a hint the
+// daemon cannot apply, or the daemon fails for any reason other than finding
+// no slip, which resolving directly would only repeat.
+func tryDaemon(cmd *cobra.Command, args []string, deps *Dependencies) (bool, error) {
+	if noDaemon || deps == nil || deps.DaemonResolverFactory == nil || len(unsupportedRemoteFlags(cmd)) > 0 {
+		return false, nil
+	}
+	if deps.HintLoader != nil {
+		if hint, err := deps.HintLoader(repoRoot(deps, args)); err != nil || hint != "" {
+			return false, nil
+		}
+	}
+	if err := applyLogFlags(deps); err != nil {
+		return true, err
+	}
+	log := deps.LoggerFactory()
+	ctx := cmd.Context()
+
+	result, err := resolveRemote(ctx, repoRoot(deps, args), deps.DaemonResolverFactory())
+	if err != nil {
+		if domain.CodeOf(err) == domain.CodeNoSlip {
+			log.Error(ctx, "failed to resolve slip via daemon", err, nil)
+			return true, err
+		}
+		log.Warn(ctx, "daemon unavailable, resolving directly", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return false, nil
+	}
+	return true, writeRemoteResult(ctx, log, deps, result, "daemon")
+}
+
+// unsupportedRemoteFlags returns the flags set on cmd that a server cannot apply.
+func unsupportedRemoteFlags(cmd *cobra.Command) []string {
+	var unsupported []string
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if !slices.Contains(remoteFlags, f.Name) {
+			unsupported = append(unsupported, "--"+f.Name)
+		}
+	})
+	return unsupported
+}
+
+// repoPathArg returns the repository path argument, defaulting to the current directory.
+func repoPathArg(args []string) string {
+	if len(args) > 0 {
+		return args[0]
+	}
+	return "."
+}
+
+// repoRoot returns the root of the repository containing the path argument,
+// or the current directory without one, so "slippy-find ./services/foo" and
+// a plain "slippy-find" work from anywhere in the worktree, as git does.
+// Without a RepoRootFinder, or when no repository contains the path, the
+// path is returned as given, for opening it to report the failure.
+func repoRoot(deps *Dependencies, args []string) string {
+	path := repoPathArg(args)
+	if deps.RepoRootFinder == nil {
+		return path
+	}
+	root, err := deps.RepoRootFinder(path)
+	if err != nil {
+		return path
+	}
+	return root
+}
+
+// resolveRemote sends the resolution for the repository at repoPath to remote.
+// The path is made absolute, since the server has its own working directory.
+func resolveRemote(ctx context.Context, repoPath string, remote RemoteResolver) (*domain.ResolveOutput, error) {
+	policy, err := domain.ParseSelectionPolicy(selectionPolicy)
+	if err != nil {
+		return nil, err
+	}
+	absPath, err := filepath.Abs(repoPath)
+	if err != nil {
+		return nil, usageErrorf("invalid repository path %q: %v", repoPath, err)
+	}
+	return remote.Resolve(ctx, domain.ResolveRequest{
+		Path:            absPath,
+		Depth:           depth,
+		SelectionPolicy: policy,
+	})
+}
+
+// writeRemoteResult writes a result obtained from a server like a local
+// resolution's. via names the server in the log.
+func writeRemoteResult(
+	ctx context.Context,
+	log Logger,
+	deps *Dependencies,
+	result *domain.ResolveOutput,
+	via string,
+) error {
+	if err := exportResult(ctx, log, deps, result); err != nil {
+		return err
+	}
+	if err := writeResult(deps, result); err != nil {
+		err = outputError(err)
+		log.Error(ctx, "failed to write output", err, nil)
+		return err
+	}
+
+	log.Info(ctx, "slip resolution complete", map[string]interface{}{
+		"correlation_id": result.CorrelationID,
+		"repository":     result.Repository,
+		"resolved_by":    result.ResolvedBy,
+		"via":            via,
+	})
+	return nil
+}
@@ -0,0 +1,261 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// mockRemoteResolver records the request it is sent.
+type mockRemoteResolver struct {
+	output *domain.ResolveOutput
+	err    error
+	req    domain.ResolveRequest
+}
+
+func (m *mockRemoteResolver) Resolve(_ context.Context, req domain.ResolveRequest) (*domain.ResolveOutput, error) {
+	m.req = req
+	return m.output, m.err
+}
+
+// writerFunc adapts a function to domain.OutputWriter.
+type writerFunc func(id string) error
+
+func (f writerFunc) WriteCorrelationID(id string) error {
+	return f(id)
+}
+
+// socketTestDeps returns dependencies whose socket client is remote, and
+// whose local adapters fail the test if used.
+func socketTestDeps(t *testing.T, remote *mockRemoteResolver) (*Dependencies, *bytes.Buffer, *string) {
+	t.Helper()
+	var stdout bytes.Buffer
+	var socketPath string
+	deps := &Dependencies{
+		LoggerFactory: func() Logger { return &mockLogger{} },
+		ConfigLoader: func() (*AppConfig, error) {
+			t.Fatal("configuration must not be loaded with --via-socket")
+			return nil, nil
+		},
+		SocketResolverFactory: func(path string) RemoteResolver {
+			socketPath = path
+			return remote
+		},
+		OutputWriterFactory: func() domain.OutputWriter {
+			return writerFunc(func(id string) error {
+				_, err := stdout.WriteString(id + "\n")
+				return err
+			})
+		},
+		Stdout: &stdout,
+	}
+	return deps, &stdout, &socketPath
+}
+
+func TestRootCmd_ViaSocket(t *testing.T) {
+	remote := &mockRemoteResolver{output: &domain.ResolveOutput{CorrelationID: "slip-1", Repository: "org/repo"}}
+	deps, stdout, socketPath := socketTestDeps(t, remote)
+	repo := t.TempDir()
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{repo, "--via-socket", "/run/slippy.sock", "--depth", "40",
+		"--selection-policy", "newest-created"})
+	require.NoError(t, cmd.Execute())
+
+	assert.Equal(t, "slip-1\n", stdout.String())
+	assert.Equal(t, "/run/slippy.sock", *socketPath)
+	assert.Equal(t, domain.ResolveRequest{
+		Path:            repo,
+		Depth:           40,
+		SelectionPolicy: domain.SelectionNewestCreated,
+	}, remote.req)
+}
+
+func TestRootCmd_ViaSocket_RelativePath(t *testing.T) {
+	remote := &mockRemoteResolver{output: &domain.ResolveOutput{CorrelationID: "slip-1"}}
+	deps, _, _ := socketTestDeps(t, remote)
+	want, err := filepath.Abs("repo")
+	require.NoError(t, err)
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{"repo", "--via-socket", "/run/slippy.sock"})
+	require.NoError(t, cmd.Execute())
+
+	assert.Equal(t, want, remote.req.Path)
+	assert.Equal(t, domain.DefaultAncestryDepth, remote.req.Depth)
+}
+
+func TestRootCmd_ViaSocket_JSON(t *testing.T) {
+	remote := &mockRemoteResolver{output: &domain.ResolveOutput{CorrelationID: "slip-1", Repository: "org/repo"}}
+	deps, stdout, _ := socketTestDeps(t, remote)
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{"--via-socket", "/run/slippy.sock", "--format", "json"})
+	require.NoError(t, cmd.Execute())
+
+	var result resultJSON
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &result))
+	assert.Equal(t, "slip-1", result.CorrelationID)
+	assert.Equal(t, "org/repo", result.Repository)
+}
+
+func TestRootCmd_ViaSocket_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		modify   func(deps *Dependencies)
+		wantMsg  string
+		wantCode domain.ErrorCode
+	}{
+		{
+			name:     "not configured",
+			modify:   func(deps *Dependencies) { deps.SocketResolverFactory = nil },
+			wantMsg:  "socket client not configured",
+			wantCode: domain.CodeConfiguration,
+		},
+		{
+			name:     "unsupported flags",
+			args:     []string{"--component", "web", "--pr"},
+			wantMsg:  "--via-socket cannot be combined with --component, --pr",
+			wantCode: domain.CodeInvalidInput,
+		},
+		{
+			name:     "invalid policy",
+			args:     []string{"--selection-policy", "random"},
+			wantMsg:  "invalid selection policy",
+			wantCode: domain.CodeInvalidInput,
+		},
+		{
+			name: "server error",
+			modify: func(deps *Dependencies) {
+				deps.SocketResolverFactory = func(string) RemoteResolver {
+					return &mockRemoteResolver{err: domain.WithCode(domain.CodeNoSlip, errors.New("no slip found"))}
+				}
+			},
+			wantMsg:  "no slip found",
+			wantCode: domain.CodeNoSlip,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps, _, _ := socketTestDeps(t, &mockRemoteResolver{})
+			if tt.modify != nil {
+				tt.modify(deps)
+			}
+
+			cmd := NewRootCmdWithDeps(deps)
+			cmd.SetArgs(append([]string{"--via-socket", "/run/slippy.sock"}, tt.args...))
+			err := cmd.Execute()
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantMsg)
+			assert.Equal(t, tt.wantCode, domain.CodeOf(err))
+		})
+	}
+}
+
+// daemonTestDeps returns dependencies that resolve directly with resolver,
+// and through remote when the daemon is tried.
+func daemonTestDeps(resolver *mockResolver, remote *mockRemoteResolver) (*Dependencies, *bytes.Buffer, *int) {
+	var stdout bytes.Buffer
+	calls := 0
+	deps := allTestDeps(resolver)
+	deps.GitRepoFactory = func(string, Logger) (domain.LocalGitRepository, error) {
+		return &mockGitRepo{gitContext: &domain.GitContext{Repository: "org/repo", HeadSHA: "c0"}}, nil
+	}
+	deps.Stdout = &stdout
+	deps.DaemonResolverFactory = func() RemoteResolver {
+		calls++
+		return remote
+	}
+	return deps, &stdout, &calls
+}
+
+func TestRootCmd_Daemon(t *testing.T) {
+	direct := &domain.ResolveOutput{CorrelationID: "slip-direct", Repository: "org/repo"}
+	tests := []struct {
+		name      string
+		args      []string
+		remote    *mockRemoteResolver
+		hint      string
+		wantID    string
+		wantCalls int
+		wantCode  domain.ErrorCode
+	}{
+		{
+			name:      "daemon answers",
+			remote:    &mockRemoteResolver{output: &domain.ResolveOutput{CorrelationID: "slip-daemon"}},
+			wantID:    "slip-daemon",
+			wantCalls: 1,
+		},
+		{
+			name:      "daemon unavailable",
+			remote:    &mockRemoteResolver{err: errors.New("dial unix /run/slippy.sock: connect: no such file")},
+			wantID:    "slip-direct",
+			wantCalls: 1,
+		},
+		{
+			name: "daemon refuses the path",
+			remote: &mockRemoteResolver{
+				err: domain.WithCode(domain.CodeInvalidInput, errors.New("path is outside the served roots")),
+			},
+			wantID:    "slip-direct",
+			wantCalls: 1,
+		},
+		{
+			name:      "daemon finds no slip",
+			remote:    &mockRemoteResolver{err: domain.WithCode(domain.CodeNoSlip, errors.New("no slip found"))},
+			wantCalls: 1,
+			wantCode:  domain.CodeNoSlip,
+		},
+		{
+			name:   "flag the daemon cannot apply",
+			args:   []string{"--max-age", "72h"},
+			remote: &mockRemoteResolver{output: &domain.ResolveOutput{CorrelationID: "slip-daemon"}},
+			wantID: "slip-direct",
+		},
+		{
+			name:   "hint",
+			hint:   "slip-hinted",
+			remote: &mockRemoteResolver{output: &domain.ResolveOutput{CorrelationID: "slip-daemon"}},
+			wantID: "slip-direct",
+		},
+		{
+			name:   "opted out",
+			args:   []string{"--no-daemon"},
+			remote: &mockRemoteResolver{output: &domain.ResolveOutput{CorrelationID: "slip-daemon"}},
+			wantID: "slip-direct",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps, stdout, calls := daemonTestDeps(&mockResolver{output: direct}, tt.remote)
+			deps.HintLoader = func(string) (string, error) { return tt.hint, nil }
+
+			cmd := NewRootCmdWithDeps(deps)
+			cmd.SetArgs(append([]string{"--format", "json"}, tt.args...))
+			err := cmd.Execute()
+
+			assert.Equal(t, tt.wantCalls, *calls)
+			if tt.wantCode != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantCode, domain.CodeOf(err))
+				return
+			}
+			require.NoError(t, err)
+			var result resultJSON
+			require.NoError(t, json.Unmarshal(stdout.Bytes(), &result))
+			assert.Equal(t, tt.wantID, result.CorrelationID)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// maxRetryBackoff caps the doubling delay between resolution attempts.
+const maxRetryBackoff = 30 * time.Second
+
+// retryPolicy controls --retry-resolution.
+type retryPolicy struct {
+	// retries is how many times a failed resolution is run again.
+	retries int
+
+	// backoff is the delay before the first retry; it doubles for each
+	// further retry, up to maxRetryBackoff or backoff if that is longer.
+	backoff time.Duration
+
+	// budget is the most time all attempts and delays may take; a retry that
+	// would start after it is not made. Zero means no limit.
+	budget time.Duration
+}
+
+// retryable reports whether a resolution that failed with err may succeed if
+// run again: no slip was found yet (slip writes can lag a push by seconds),
+// or the store failed or was too slow. Git, input, and configuration errors
+// would fail the same way again.
+func retryable(err error) bool {
+	switch domain.CodeOf(err) {
+	case domain.CodeNoSlip, domain.CodeStore, domain.CodeStageTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryingResolver runs the whole resolution again, after a growing delay,
+// when it fails with a retryable error. A resolution has no side effects
+// until it succeeds, so a retry cannot record anything twice.
+type retryingResolver struct {
+	domain.Resolver
+	policy retryPolicy
+	log    Logger
+
+	// store is the session's finder; a failure to open it is not retried,
+	// since every attempt would fail with it.
+	store *pendingFinder
+}
+
+// Resolve implements domain.Resolver.
+func (r *retryingResolver) Resolve(ctx context.Context, input domain.ResolveInput) (*domain.ResolveOutput, error) {
+	return withRetries(ctx, r, func() (*domain.ResolveOutput, error) {
+		return r.Resolver.Resolve(ctx, input)
+	})
+}
+
+// ResolveAll implements domain.Resolver.
+func (r *retryingResolver) ResolveAll(ctx context.Context, input domain.ResolveInput) (*domain.ResolveAllOutput, error) {
+	return withRetries(ctx, r, func() (*domain.ResolveAllOutput, error) {
+		return r.Resolver.ResolveAll(ctx, input)
+	})
+}
+
+// ResolveComponents implements domain.Resolver.
+func (r *retryingResolver) ResolveComponents(
+	ctx context.Context,
+	input domain.ResolveInput,
+	components map[string][]string,
+) (map[string]*domain.ResolveOutput, error) {
+	return withRetries(ctx, r, func() (map[string]*domain.ResolveOutput, error) {
+		return r.Resolver.ResolveComponents(ctx, input, components)
+	})
+}
+
+// withRetries calls resolve until it succeeds, fails with an error that is
+// not retryable, or r.policy's retries or budget run out, or ctx is done
+// while waiting to retry. Returns the last attempt's result.
+func withRetries[T any](ctx context.Context, r *retryingResolver, resolve func() (T, error)) (T, error) {
+	start := time.Now()
+	delay := r.policy.backoff
+	for attempt := 1; ; attempt++ {
+		result, err := resolve()
+		if err == nil || attempt > r.policy.retries || !retryable(err) || r.storeFailed() {
+			return result, err
+		}
+		if r.policy.budget > 0 && time.Since(start)+delay > r.policy.budget {
+			r.log.Warn(ctx, "resolution retry budget exhausted", map[string]interface{}{
+				"attempts":  attempt,
+				"budget_ms": r.policy.budget.Milliseconds(),
+			})
+			return result, err
+		}
+
+		r.log.Warn(ctx, "resolution failed, retrying", map[string]interface{}{
+			"attempt":    attempt,
+			"delay_ms":   delay.Milliseconds(),
+			"error_code": string(domain.CodeOf(err)),
+			"error":      err.Error(),
+		})
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return result, err
+		case <-timer.C:
+		}
+		delay = min(2*delay, max(maxRetryBackoff, r.policy.backoff))
+	}
+}
+
+// storeFailed reports whether the session's store failed to open.
+func (r *retryingResolver) storeFailed() bool {
+	return r.store != nil && r.store.opened() != nil
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// flakyResolver fails its first failures resolutions with err.
+type flakyResolver struct {
+	mockResolver
+	failures int
+	calls    int
+}
+
+func (f *flakyResolver) Resolve(_ context.Context, _ domain.ResolveInput) (*domain.ResolveOutput, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return nil, f.err
+	}
+	return &domain.ResolveOutput{CorrelationID: "slip-1"}, nil
+}
+
+func TestRetryingResolver_Resolve(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		failures  int
+		policy    retryPolicy
+		wantCalls int
+		wantErr   error
+	}{
+		{
+			name:      "succeeds after a missing slip",
+			err:       domain.ErrNoAncestorSlip,
+			failures:  2,
+			policy:    retryPolicy{retries: 3, backoff: time.Millisecond},
+			wantCalls: 3,
+		},
+		{
+			name:      "retries store failures",
+			err:       domain.WithCode(domain.CodeStore, errors.New("connection refused")),
+			failures:  1,
+			policy:    retryPolicy{retries: 1, backoff: time.Millisecond},
+			wantCalls: 2,
+		},
+		{
+			name:      "gives up after the retries",
+			err:       domain.ErrNoAncestorSlip,
+			failures:  5,
+			policy:    retryPolicy{retries: 2, backoff: time.Millisecond},
+			wantCalls: 3,
+			wantErr:   domain.ErrNoAncestorSlip,
+		},
+		{
+			name:      "does not retry git errors",
+			err:       domain.ErrRepositoryNotFound,
+			failures:  1,
+			policy:    retryPolicy{retries: 3, backoff: time.Millisecond},
+			wantCalls: 1,
+			wantErr:   domain.ErrRepositoryNotFound,
+		},
+		{
+			name:      "stops when the budget would be exceeded",
+			err:       domain.ErrNoAncestorSlip,
+			failures:  5,
+			policy:    retryPolicy{retries: 5, backoff: time.Hour, budget: time.Minute},
+			wantCalls: 1,
+			wantErr:   domain.ErrNoAncestorSlip,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flaky := &flakyResolver{mockResolver: mockResolver{err: tt.err}, failures: tt.failures}
+			resolver := &retryingResolver{Resolver: flaky, policy: tt.policy, log: &mockLogger{}}
+
+			result, err := resolver.Resolve(context.Background(), domain.ResolveInput{})
+
+			assert.Equal(t, tt.wantCalls, flaky.calls)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "slip-1", result.CorrelationID)
+		})
+	}
+}
+
+func TestRetryingResolver_CancelledWhileWaiting(t *testing.T) {
+	flaky := &flakyResolver{mockResolver: mockResolver{err: domain.ErrNoAncestorSlip}, failures: 5}
+	resolver := &retryingResolver{
+		Resolver: flaky,
+		policy:   retryPolicy{retries: 5, backoff: time.Hour},
+		log:      &mockLogger{},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := resolver.Resolve(ctx, domain.ResolveInput{})
+
+	require.ErrorIs(t, err, domain.ErrNoAncestorSlip)
+	assert.Equal(t, 1, flaky.calls)
+}
+
+func TestRetryingResolver_StoreOpenFailure(t *testing.T) {
+	store := openFinderAsync(context.Background(), &mockLogger{}, func() (domain.SlipFinder, error) {
+		return nil, errors.New("connection refused")
+	})
+	flaky := &flakyResolver{mockResolver: mockResolver{err: store.opened()}, failures: 5}
+	resolver := &retryingResolver{
+		Resolver: flaky,
+		policy:   retryPolicy{retries: 5, backoff: time.Millisecond},
+		log:      &mockLogger{},
+		store:    store,
+	}
+
+	_, err := resolver.Resolve(context.Background(), domain.ResolveInput{})
+
+	assert.Equal(t, domain.CodeStore, domain.CodeOf(err))
+	assert.Equal(t, 1, flaky.calls)
+}
+
+func TestRootCmd_NegativeRetry(t *testing.T) {
+	deps := &Dependencies{
+		LoggerFactory: func() Logger { return &mockLogger{} },
+		Stderr:        io.Discard,
+	}
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{"--retry-resolution", "-1"})
+
+	err := cmd.Execute()
+
+	require.Error(t, err)
+	assert.Equal(t, domain.CodeInvalidInput, domain.CodeOf(err))
+}
@@ -3,14 +3,23 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 
-	"github.com/MyCarrier-DevOps/slippy-find/internal/domain"
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
 )
 
 // Logger defines the logging interface used by the command.
@@ -21,18 +30,50 @@ type Logger interface {
 	Error(ctx context.Context, msg string, err error, fields map[string]interface{})
 }
 
+// ErrorReporter sends unexpected failures to an error tracking service.
+type ErrorReporter interface {
+	// Report sends err with sanitized context tags.
+	Report(ctx context.Context, err error, tags map[string]string) error
+}
+
+// MetricsExporter writes the metrics recorded during a CLI run.
+type MetricsExporter interface {
+	// WriteTextfile writes the metrics to path for the node_exporter textfile collector.
+	WriteTextfile(path string) error
+
+	// Push sends the metrics to the Pushgateway at url.
+	Push(ctx context.Context, url string) error
+}
+
+// DebugBundleWriter writes the --debug-bundle archive.
+type DebugBundleWriter interface {
+	// WriteBundle writes files (name -> contents) as an archive at path,
+	// scrubbing secrets from their contents.
+	WriteBundle(path string, files map[string][]byte) error
+}
+
 // Dependencies holds all injectable dependencies for the command.
 // This enables testing by allowing mock implementations to be injected.
 type Dependencies struct {
 	// LoggerFactory creates a logger instance.
 	LoggerFactory func() Logger
 
+	// DebugLogging lowers the level of the application's loggers to debug,
+	// including loggers already created, for --verbose. Optional; without
+	// it, --verbose does not change the log level.
+	DebugLogging func()
+
 	// ConfigLoader loads application configuration.
 	ConfigLoader func() (*AppConfig, error)
 
 	// GitRepoFactory creates a LocalGitRepository for the given path.
 	GitRepoFactory func(path string, log Logger) (domain.LocalGitRepository, error)
 
+	// RepoRootFinder returns the root of the repository containing path, with
+	// symlinks resolved, so a subdirectory names its repository. Optional;
+	// without it, paths are opened as given.
+	RepoRootFinder func(path string) (string, error)
+
 	// SlipFinderFactory creates a SlipFinder using the given config.
 	SlipFinderFactory func(cfg *AppConfig, log Logger) (domain.SlipFinder, error)
 
@@ -43,9 +84,135 @@ type Dependencies struct {
 		log Logger,
 	) domain.Resolver
 
+	// ComponentLoader reads the monorepo component mapping (component name ->
+	// path prefixes) for the repository at repoPath. Only used with --component.
+	ComponentLoader func(repoPath string) (map[string][]string, error)
+
+	// PullRequestLoader detects the pull request being built from the CI
+	// environment. Only used in PR-aware mode, for values not given as flags.
+	PullRequestLoader func() (*domain.PullRequest, error)
+
+	// HintLoader reads the correlation ID hint that overrides resolution for the
+	// repository at repoPath, returning "" when none is set. Optional.
+	HintLoader func(repoPath string) (string, error)
+
+	// AnnotationLoader identifies the host and pipeline running the resolution
+	// for --annotate.
+	AnnotationLoader func() domain.Annotation
+
+	// AuditWriterFactory opens the writer that records each resolution for
+	// --audit (or SLIPPY_AUDIT). Optional.
+	AuditWriterFactory func(cfg *AppConfig, log Logger) (domain.AuditWriter, error)
+
+	// VaultChecker checks that Vault serves the pipeline configuration, for
+	// healthcheck. It reports false when configuration is not read from Vault.
+	// Optional.
+	VaultChecker func(ctx context.Context) (bool, error)
+
+	// ServerFactory builds the server for "serve", which resolves each request
+	// with resolve. Optional; serve fails without it.
+	ServerFactory func(opts ServeOptions, resolve ResolveRequestFunc, log Logger) (Server, error)
+
+	// SecretsLoader loads a mounted secrets directory into the environment,
+	// returning the variables it set, for serve --secrets-dir. Optional.
+	SecretsLoader func(dir string) ([]string, error)
+
+	// TenantLoader reads the tenants file of a multi-tenant server, returning
+	// each tenant's configuration (tenant name -> config) derived from base,
+	// for serve --tenants-file. Optional.
+	TenantLoader func(path string, base *AppConfig) (map[string]*AppConfig, error)
+
+	// WorkerFactory connects the queue worker for "worker", which resolves
+	// each request with resolve. Optional; worker fails without it.
+	WorkerFactory func(opts WorkerOptions, resolve ResolveRequestFunc, log Logger) (Worker, error)
+
+	// StoreReloader keeps the default slip store of serve and worker current:
+	// it returns a finder that delegates to finder, opened for cfg, until the
+	// store settings change, and then to one opened for the new settings. It
+	// stops watching when ctx is done. Optional; without it, the finder opened
+	// at startup is kept.
+	StoreReloader func(ctx context.Context, cfg *AppConfig, finder domain.SlipFinder, log Logger) domain.SlipFinder
+
+	// AncestryRepoFactory wraps a commit list supplied by a serve-mode client
+	// (repository, branch, and commits, newest first) as a repository. Optional.
+	AncestryRepoFactory func(repository, branch string, commits []string) domain.LocalGitRepository
+
+	// SocketResolverFactory connects to the serve-mode server listening on the
+	// unix socket at path, for --via-socket. Optional.
+	SocketResolverFactory func(path string) RemoteResolver
+
+	// DaemonResolverFactory connects to the local daemon that resolution is
+	// tried through first. Set only when a daemon is configured.
+	DaemonResolverFactory func() RemoteResolver
+
+	// DaemonStarter starts this executable in the background with args,
+	// detached from the calling session, appending its output to logPath, for
+	// "daemonize". It returns the process ID. Optional; daemonize fails
+	// without it.
+	DaemonStarter func(args []string, logPath string) (pid int, err error)
+
+	// ErrorReporter receives unexpected failures (optional).
+	ErrorReporter ErrorReporter
+
+	// FailureNotifier is alerted when resolution finds no slip or the store
+	// fails (optional).
+	FailureNotifier domain.FailureNotifier
+
+	// ResolutionPublisher streams each successful local resolution to a
+	// message queue (optional).
+	ResolutionPublisher domain.ResolutionPublisher
+
+	// MetricsExporter exports the metrics recorded during a run, for
+	// --metrics-textfile and --metrics-pushgateway. Optional.
+	MetricsExporter MetricsExporter
+
+	// DebugBundleWriter writes --debug-bundle archives. Optional.
+	DebugBundleWriter DebugBundleWriter
+
+	// DotenvWriter writes variables (name -> value) to path as a GitLab CI
+	// dotenv report, for --gitlab-dotenv. Optional.
+	DotenvWriter func(path string, vars map[string]string) error
+
+	// BuildkiteMetaData sets meta-data (key -> value) on the current Buildkite
+	// build, skipping empty values, for --buildkite. Optional.
+	BuildkiteMetaData func(ctx context.Context, data map[string]string) error
+
+	// ArgoCDAnnotator merges annotations (key -> value) into the Argo CD
+	// Application named app ("name" or "namespace/name"), for --argocd-app.
+	// Optional.
+	ArgoCDAnnotator func(ctx context.Context, app string, annotations map[string]string) error
+
+	// OctopusVariableWriter writes variables (name -> value) to w as Octopus
+	// Deploy output variable service messages, for --octopus. Optional.
+	OctopusVariableWriter func(w io.Writer, vars map[string]string) error
+
+	// BackstageWriter writes annotations (key -> value) to path as a Backstage
+	// catalog entity fragment, for --backstage. Optional.
+	BackstageWriter func(path string, annotations map[string]string) error
+
+	// SpinnakerArtifactWriter writes result to path as a Spinnaker webhook
+	// trigger payload, for --spinnaker-artifact. Optional.
+	SpinnakerArtifactWriter func(path string, result *domain.ResolveOutput) error
+
+	// AzureDevOpsTagger adds tag to the running Azure Pipelines build, for
+	// --azure-devops-tag. Optional.
+	AzureDevOpsTagger func(ctx context.Context, tag string) error
+
+	// ResultPoster POSTs body, a JSON result, to url, for --notify-url.
+	// Optional.
+	ResultPoster func(ctx context.Context, url string, body []byte) error
+
+	// GitHubDeployer creates a GitHub Deployment of ref in repository (owner/repo)
+	// to environment carrying payload, for --github-deployment. Optional.
+	GitHubDeployer func(ctx context.Context, repository, ref, environment string, payload map[string]string) error
+
 	// OutputWriterFactory creates an OutputWriter.
 	OutputWriterFactory func() domain.OutputWriter
 
+	// OutputFormats are the --format values added by extensions, by name.
+	// Optional.
+	OutputFormats map[string]domain.ResultWriter
+
 	// Stdout is the writer for standard output (for correlation ID).
 	Stdout io.Writer
 
@@ -69,6 +236,45 @@ type AppConfig struct {
 
 	// LogAppName is the application name for logging.
 	LogAppName string
+
+	// LogFormat is the log encoding (json, console).
+	LogFormat string
+
+	// LogFile is a file that receives a copy of the logs, if any.
+	LogFile string
+
+	// Audit enables recording each resolution with the AuditWriterFactory.
+	Audit bool
+
+	// DenyList holds correlation IDs that must never be returned.
+	DenyList []string
+
+	// CorrelationIDFormat is the pattern resolved correlation IDs must match;
+	// nil accepts any non-empty ID.
+	CorrelationIDFormat *regexp.Regexp
+
+	// FinderPlugin is an executable the SlipFinderFactory searches instead of
+	// ClickHouse, if any.
+	FinderPlugin string
+
+	// Store is the slip store the SlipFinderFactory searches when there is no
+	// FinderPlugin: "clickhouse" or "mock".
+	Store string
+
+	// Settings lists each effective configuration value and its source.
+	Settings []ConfigSetting
+
+	// GitTimeout is the most time a resolution may spend reading git; zero
+	// means no limit.
+	GitTimeout time.Duration
+
+	// StoreTimeout is the most time a resolution may spend querying the slip
+	// store; zero means no limit.
+	StoreTimeout time.Duration
+
+	// StoreFingerprint identifies the settings the slip store is opened with;
+	// configurations with the same fingerprint can share a slip finder.
+	StoreFingerprint string
 }
 
 // Version is set at build time via ldflags.
@@ -77,23 +283,77 @@ var Version = "dev"
 
 // Command-line flags.
 var (
-	depth   int
-	verbose bool
+	depth                int
+	maxDepth             int
+	verbose              bool
+	selectionPolicy      string
+	maxAge               time.Duration
+	since                string
+	fallbackMergeBase    bool
+	fallbackBranchLatest bool
+	defaultBranch        string
+	component            string
+	componentNames       []string
+	resolveFormat        string
+	printSchema          bool
+	requireSteps         []string
+	pullRequestMode      bool
+	prHeadSHA            string
+	prBase               string
+	branchAffinity       bool
+	annotate             bool
+	audit                bool
+	metricsTextfile      string
+	metricsPushgateway   string
+	logFormat            string
+	logFile              string
+	debugBundlePath      string
+	debugBundleAlways    bool
+	slowWalkThreshold    time.Duration
+	slowQueryThreshold   time.Duration
+	gitTimeout           time.Duration
+	storeTimeout         time.Duration
+	retryResolution      int
+	retryBackoff         time.Duration
+	retryBudget          time.Duration
+	crlfOutput           bool
+	viaSocket            string
+	noDaemon             bool
+	gitlabDotenv         string
+	buildkiteMetaData    bool
+	recordNote           bool
+	recordTag            bool
+	recordPush           bool
+	argoCDApp            string
+	octopusVariables     bool
+	backstageFragment    string
+	spinnakerArtifact    string
+	azureDevOpsTag       bool
+	notifyURL            string
+	githubDeployment     string
 )
 
-// defaultDeps holds the production dependencies.
-// This is set by the production wiring in main or via SetDefaultDependencies.
-var defaultDeps *Dependencies
+// Process exit codes returned by Execute.
+const (
+	// ExitCodeError covers every failure without a dedicated code.
+	ExitCodeError = 1
 
-// SetDefaultDependencies sets the default dependencies for production use.
-// This should be called from main() before Execute().
-func SetDefaultDependencies(deps *Dependencies) {
-	defaultDeps = deps
-}
+	// ExitCodeStepRequirementUnmet means a slip was resolved but a --require-step assertion failed.
+	ExitCodeStepRequirementUnmet = 2
+
+	// ExitCodePanic means the run panicked; a crash report was written to stderr.
+	ExitCodePanic = 3
+)
+
+// tracerName names the tracer for command spans.
+const tracerName = "github.com/MyCarrier-DevOps/slippy-find/cmd"
 
-// NewRootCmd creates the root command for slippy-find.
+// NewRootCmd creates the root command for slippy-find with the dependencies
+// set by SetDefaultDependencies.
+//
+// Deprecated: Use NewRootCmdWithDeps, or run command lines with an App.
 func NewRootCmd() *cobra.Command {
-	return NewRootCmdWithDeps(defaultDeps)
+	return NewRootCmdWithDeps(defaultDeps.Load())
 }
 
 // NewRootCmdWithDeps creates the root command with explicit dependencies.
@@ -122,28 +382,316 @@ Examples:
   # Increase ancestry search depth
   slippy-find --depth 50
 
+  # Retry at 100, then 400 commits if nothing is found in the first 25
+  slippy-find --max-depth 400
+
+  # Prefer the slip created for the current branch when several match
+  slippy-find --selection-policy branch-preferred
+
+  # Never return a slip created more than three days ago
+  slippy-find --max-age 72h
+
+  # Fall back to the merge base with develop, then the branch's latest slip
+  slippy-find --fallback-merge-base --default-branch develop --fallback-branch-latest
+
+  # Resolve the slip for one component of a monorepo (paths from .slippy.yaml)
+  slippy-find --component payments-api
+
+  # Resolve several components in one run, as a JSON map of component -> ID
+  slippy-find --components payments-api,web --format json
+
+  # In a PR build, prefer the PR head's slip, then the base branch's
+  slippy-find --pr
+
+  # Same, with the SHAs given explicitly instead of read from GITHUB_EVENT_PATH
+  slippy-find --pr-head-sha "$PR_HEAD_SHA" --pr-base main
+
+  # Fail (exit code 2) unless the slip's push_parsed step has completed
+  slippy-find --require-step push_parsed=completed
+
+  # Emit the full result, including distance and confidence, as JSON
+  slippy-find --format json
+
+  # In GitLab CI, pass the result to later jobs as SLIPPY_CORRELATION_ID
+  slippy-find --gitlab-dotenv slippy.env
+
+  # In Buildkite, set the result as build meta-data for later steps
+  slippy-find --buildkite
+
+  # Link the Argo CD Application being deployed back to its slip
+  slippy-find --argocd-app payments-api
+
+  # In an Octopus Deploy script step, set the result as output variables
+  slippy-find --octopus
+
+  # Link the repository's developer portal entry to the slip
+  slippy-find --backstage backstage-slip.json
+
+  # Hand the slip to a Spinnaker pipeline through its webhook trigger
+  slippy-find --spinnaker-artifact spinnaker.json
+
+  # In Azure Pipelines, make the build searchable by its slip
+  slippy-find --azure-devops-tag
+
+  # Send the result to any other system that accepts a webhook
+  slippy-find --notify-url https://deploy-tracker.example.com/hooks/slippy
+
+  # Show the slip in the repository's GitHub deployment timeline
+  slippy-find --github-deployment production
+
+  # Note the slip on the matched commit, for git log --notes=slippy
+  slippy-find --record-note --record-push
+
+  # Resolve through the slippy-find daemon on this host
+  slippy-find --via-socket /var/run/slippy-find.sock
+
   # Enable verbose logging
   slippy-find -v`,
 		Args:         cobra.MaximumNArgs(1),
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if printSchema {
+				return writeSchema(commandStdout(deps))
+			}
 			return runResolve(cmd, args, deps)
 		},
 	}
 
 	// Define flags
-	rootCmd.Flags().IntVarP(&depth, "depth", "d", domain.DefaultAncestryDepth,
+	addSearchFlags(rootCmd)
+	rootCmd.Flags().IntVar(&maxDepth, "max-depth", 0,
+		"On a miss, retry with 4x larger depths up to this limit (0 disables)")
+	rootCmd.Flags().StringVar(&selectionPolicy, "selection-policy", string(domain.DefaultSelectionPolicy),
+		"Policy when several slips match: nearest-commit, newest-created, or branch-preferred")
+	rootCmd.Flags().BoolVar(&fallbackMergeBase, "fallback-merge-base", false,
+		"On an ancestry miss, search from the merge base with the default branch")
+	rootCmd.Flags().BoolVar(&fallbackBranchLatest, "fallback-branch-latest", false,
+		"On an ancestry miss, use the latest slip recorded for the current branch")
+	rootCmd.Flags().StringVar(&defaultBranch, "default-branch", "",
+		"Default branch used by the fallbacks (default origin/HEAD's branch, else main or master)")
+	rootCmd.Flags().BoolVar(&branchAffinity, "branch-affinity", true,
+		"Prefer matching slips recorded for the current branch (--branch-affinity=false disables)")
+	rootCmd.Flags().StringSliceVar(&componentNames, "components", nil,
+		"Resolve several monorepo components in one run (comma-separated), printing component -> correlation ID")
+	rootCmd.Flags().BoolVar(&pullRequestMode, "pr", false,
+		"PR-aware mode: search the PR head's ancestry, then the base branch's (SHAs from GITHUB_EVENT_PATH)")
+	rootCmd.Flags().StringVar(&prHeadSHA, "pr-head-sha", "",
+		"Pull request head commit SHA (implies --pr)")
+	rootCmd.Flags().StringVar(&prBase, "pr-base", "",
+		"Pull request base branch (implies --pr)")
+	rootCmd.Flags().BoolVar(&annotate, "annotate", false,
+		"Record the host and pipeline that resolved the slip in its state history")
+	rootCmd.Flags().BoolVar(&audit, "audit", false,
+		"Record the resolution in the ClickHouse audit table (default from SLIPPY_AUDIT)")
+	rootCmd.Flags().StringArrayVar(&requireSteps, "require-step", nil,
+		"Require a step status on the resolved slip, as step=status (repeatable)")
+	rootCmd.Flags().StringVar(&resolveFormat, "format", "text",
+		"Output format: text (correlation ID only), json (full result), or a format added by an extension")
+	rootCmd.Flags().BoolVar(&printSchema, "schema", false,
+		"Print the JSON Schema of the --format json result and error documents, and exit")
+	rootCmd.Flags().StringVar(&viaSocket, "via-socket", "",
+		"Resolve through the slippy-find server listening on this unix socket (see serve --unix)")
+	rootCmd.Flags().BoolVar(&noDaemon, "no-daemon", false,
+		"Resolve directly, without first trying the daemon named by SLIPPY_DAEMON")
+	rootCmd.Flags().StringVar(&gitlabDotenv, "gitlab-dotenv", "",
+		"Also write the result to this file as a GitLab CI dotenv report (artifacts:reports:dotenv)")
+	rootCmd.Flags().BoolVar(&buildkiteMetaData, "buildkite", false,
+		"Also set the result as Buildkite build meta-data (buildkite-agent meta-data set)")
+	rootCmd.Flags().StringVar(&argoCDApp, "argocd-app", "",
+		"Also annotate this Argo CD Application (name or namespace/name) with the correlation ID")
+	rootCmd.Flags().BoolVar(&octopusVariables, "octopus", false,
+		"Also print the result as Octopus Deploy output variables (setVariable service messages)")
+	rootCmd.Flags().StringVar(&backstageFragment, "backstage", "",
+		"Also write the result to this file as Backstage entity annotations (a catalog-info JSON fragment)")
+	rootCmd.Flags().StringVar(&spinnakerArtifact, "spinnaker-artifact", "",
+		"Also write the result to this file as a Spinnaker webhook trigger payload with a custom/object artifact")
+	rootCmd.Flags().BoolVar(&azureDevOpsTag, "azure-devops-tag", false,
+		"Also tag the running Azure Pipelines build with the correlation ID (Azure DevOps REST API)")
+	rootCmd.Flags().StringVar(&notifyURL, "notify-url", "",
+		"Also POST the JSON result to this URL, signed with SLIPPY_NOTIFY_SECRET when set")
+	rootCmd.Flags().StringVar(&githubDeployment, "github-deployment", "",
+		"Also record the slip as a GitHub Deployment of the matched commit to this environment")
+	rootCmd.Flags().BoolVar(&recordNote, "record-note", false,
+		"Record the correlation ID as a git note (refs/notes/slippy) on the matched commit")
+	rootCmd.Flags().BoolVar(&recordTag, "record-tag", false,
+		"Record the correlation ID as a lightweight tag, slippy/<correlation_id>, on the matched commit")
+	rootCmd.Flags().BoolVar(&recordPush, "record-push", false,
+		"Push the note or tag from --record-note or --record-tag to origin")
+
+	rootCmd.AddCommand(newConfigCmd(deps))
+	rootCmd.AddCommand(newAllCmd(deps))
+	rootCmd.AddCommand(newBatchCmd(deps))
+	rootCmd.AddCommand(newHealthcheckCmd(deps))
+	rootCmd.AddCommand(newServeCmd(deps))
+	rootCmd.AddCommand(newDaemonizeCmd(deps))
+	rootCmd.AddCommand(newWorkerCmd(deps))
+	rootCmd.AddCommand(newInteractiveCmd(deps))
+
+	return rootCmd
+}
+
+// addSearchFlags defines the flags shared by every command that searches the ancestry.
+func addSearchFlags(c *cobra.Command) {
+	c.Flags().IntVarP(&depth, "depth", "d", domain.DefaultAncestryDepth,
 		"Maximum ancestry depth to search for matching slips")
-	rootCmd.Flags().BoolVarP(&verbose, "verbose", "v", false,
+	c.Flags().BoolVarP(&verbose, "verbose", "v", false,
 		"Enable verbose/debug logging")
+	c.Flags().StringVar(&logFormat, "log-format", "",
+		"Log format: json or console (default from LOG_FORMAT, else json)")
+	c.Flags().StringVar(&logFile, "log-file", "",
+		"Also write logs to this file, rotated by size (default from LOG_FILE)")
+	c.Flags().StringVar(&debugBundlePath, "debug-bundle", "",
+		"On failure, write a tar.gz of sanitized config, git context, ancestry, and logs to this path")
+	c.Flags().BoolVar(&debugBundleAlways, "debug-bundle-always", false,
+		"Write the --debug-bundle even when the run succeeds")
+	c.Flags().DurationVar(&maxAge, "max-age", 0,
+		"Ignore slips created longer ago than this duration, e.g. 72h (0 disables)")
+	c.Flags().StringVar(&since, "since", "",
+		"Ignore slips created before this RFC 3339 timestamp")
+	c.Flags().StringVar(&component, "component", "",
+		"Monorepo component to resolve for, as defined in .slippy.yaml")
+	c.Flags().StringVar(&metricsTextfile, "metrics-textfile", "",
+		"Write run metrics to this file for the node_exporter textfile collector")
+	c.Flags().StringVar(&metricsPushgateway, "metrics-pushgateway", "",
+		"Push run metrics to the Prometheus Pushgateway at this URL")
+	c.Flags().DurationVar(&slowWalkThreshold, "slow-walk-threshold", domain.DefaultSlowGitWalk,
+		"Log a warning for each git history walk slower than this (0 disables)")
+	c.Flags().DurationVar(&slowQueryThreshold, "slow-query-threshold", domain.DefaultSlowStoreQuery,
+		"Log a warning for each slip store query slower than this (0 disables)")
+	c.Flags().DurationVar(&gitTimeout, "git-timeout", 0,
+		"Fail if reading git takes longer than this in total, overriding SLIPPY_GIT_TIMEOUT (0 keeps it)")
+	c.Flags().DurationVar(&storeTimeout, "store-timeout", 0,
+		"Fail if slip store queries take longer than this in total, overriding SLIPPY_STORE_TIMEOUT (0 keeps it)")
+	c.Flags().IntVar(&retryResolution, "retry-resolution", 0,
+		"Run a resolution that found no slip or hit a store failure again, up to this many times")
+	c.Flags().DurationVar(&retryBackoff, "retry-backoff", 2*time.Second,
+		"Delay before the first --retry-resolution retry, doubling for each further one")
+	c.Flags().DurationVar(&retryBudget, "retry-budget", time.Minute,
+		"Most time --retry-resolution attempts and delays may take in total (0 disables)")
+	c.Flags().BoolVar(&crlfOutput, "crlf", false,
+		"End output lines with CRLF instead of LF, for Windows tools that expect it")
+}
 
-	return rootCmd
+// stageTimeouts returns the stage timeouts of cfg, overridden by
+// --git-timeout and --store-timeout where set.
+func stageTimeouts(cfg *AppConfig) domain.StageTimeouts {
+	timeouts := domain.StageTimeouts{Git: cfg.GitTimeout, Store: cfg.StoreTimeout}
+	if gitTimeout > 0 {
+		timeouts.Git = gitTimeout
+	}
+	if storeTimeout > 0 {
+		timeouts.Store = storeTimeout
+	}
+	return timeouts
 }
 
-// runResolve executes the slip resolution logic with injected dependencies.
-func runResolve(cmd *cobra.Command, args []string, deps *Dependencies) error {
+// session holds the adapters opened for one resolution command.
+type session struct {
+	ctx      context.Context
+	log      Logger
+	resolver domain.Resolver
+	input    domain.ResolveInput
+	closers  []func() error
+
+	// components maps each --components name to its path prefixes; nil without --components.
+	components map[string][]string
+
+	// timings records how long loading configuration and opening the
+	// repository took; stageTimings adds the store.
+	timings []domain.StageTiming
+
+	// store is the slip finder, opened in the background.
+	store *pendingFinder
+
+	// gitRepo is the opened repository, kept to describe failures.
+	gitRepo domain.LocalGitRepository
+
+	// cfg is the loaded configuration.
+	cfg *AppConfig
+
+	// env is the environment fingerprint, started by describeEnvironment
+	// and completed by environment; nil if the run reports none.
+	env *domain.Environment
+
+	// envLogged reports that environment has completed and logged env.
+	envLogged bool
+}
+
+// stageTimings returns how long loading configuration and opening the
+// repository and store took. It waits for the store to finish opening.
+func (s *session) stageTimings() []domain.StageTiming {
+	timings := slices.Clone(s.timings)
+	if s.store != nil {
+		timings = append(timings, s.store.timing())
+	}
+	return timings
+}
+
+// Close releases the session's adapters in reverse order of opening. Every
+// adapter is closed even if closing another fails or panics; the failures are
+// logged and returned joined.
+func (s *session) Close() error {
+	var errs []error
+	for i := len(s.closers) - 1; i >= 0; i-- {
+		if err := closeRecovered(s.closers[i]); err != nil {
+			s.log.Warn(s.ctx, "failed to close session", map[string]interface{}{
+				"error": err.Error(),
+			})
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// closeRecovered calls closer, returning a panic in it as an error.
+func closeRecovered(closer func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic while closing: %v", r)
+		}
+	}()
+	return closer()
+}
+
+// closeSession closes s, if it was opened, warning on stderr if that fails.
+// A failed close does not fail the command: the resolution is already done.
+func closeSession(deps *Dependencies, s *session) {
+	if s == nil {
+		return
+	}
+	if err := s.Close(); err != nil {
+		stderr := deps.Stderr
+		if stderr == nil {
+			stderr = os.Stderr
+		}
+		writeWarningf(stderr, "warning: %v\n", err)
+	}
+}
+
+// recoveredError converts a panic recovered from a resolution command into
+// a *PanicError, logging the stack, so the session can still be closed and the
+// failure reported. Execute writes its crash report.
+func recoveredError(ctx context.Context, s *session, r any) error {
+	var settings []ConfigSetting
+	if s != nil && s.cfg != nil {
+		settings = s.cfg.Settings
+	}
+	err := newPanicError(r, settings)
+	if s != nil {
+		s.log.Error(ctx, "resolution panicked", err, map[string]interface{}{
+			"stack": string(err.Stack),
+		})
+	}
+	return err
+}
+
+// openSession parses shared flags, loads configuration, and opens the git
+// repository and slip finder for a resolution command. The caller must Close
+// the returned session. When bundle is non-nil, it collects the logs,
+// settings, and git state for --debug-bundle as they become available.
+func openSession(cmd *cobra.Command, args []string, deps *Dependencies, bundle *debugBundle) (*session, error) {
 	if deps == nil {
-		return errors.New("dependencies not configured")
+		return nil, configError(errors.New("dependencies not configured"))
 	}
 
 	ctx := cmd.Context()
@@ -152,112 +700,545 @@ func runResolve(cmd *cobra.Command, args []string, deps *Dependencies) error {
 	}
 
 	// Determine repository path
-	repoPath := "."
-	if len(args) > 0 {
-		repoPath = args[0]
+	repoPath := repoRoot(deps, args)
+
+	policy, err := domain.ParseSelectionPolicy(selectionPolicy)
+	if err != nil {
+		return nil, err
 	}
 
-	// Get stderr for warnings
-	stderr := deps.Stderr
-	if stderr == nil {
-		stderr = os.Stderr
+	if component != "" && len(componentNames) > 0 {
+		return nil, usageErrorf("--component and --components are mutually exclusive")
 	}
 
-	// Set log level based on verbose flag (best-effort)
-	if verbose {
-		if err := os.Setenv("LOG_LEVEL", "debug"); err != nil {
-			// Best-effort warning: ignore fprintf error as this is non-critical
-			writeWarningf(stderr, "warning: could not set log level: %v\n", err)
+	var required []domain.StepRequirement
+	for _, raw := range requireSteps {
+		req, err := domain.ParseStepRequirement(raw)
+		if err != nil {
+			return nil, err
+		}
+		required = append(required, req)
+	}
+
+	var sinceTime time.Time
+	if since != "" {
+		sinceTime, err = time.Parse(time.RFC3339, since)
+		if err != nil {
+			return nil, usageErrorf("invalid --since value %q: expected RFC 3339 (e.g. 2026-01-02T15:04:05Z)", since)
 		}
 	}
 
+	if gitTimeout < 0 || storeTimeout < 0 {
+		return nil, usageErrorf("--git-timeout and --store-timeout must not be negative")
+	}
+	if retryResolution < 0 || retryBackoff < 0 || retryBudget < 0 {
+		return nil, usageErrorf("--retry-resolution, --retry-backoff, and --retry-budget must not be negative")
+	}
+
+	exportingMetrics := metricsTextfile != "" || metricsPushgateway != ""
+	if exportingMetrics && deps.MetricsExporter == nil {
+		return nil, configError(errors.New("metrics export not configured"))
+	}
+	if debugBundlePath != "" && deps.DebugBundleWriter == nil {
+		return nil, configError(errors.New("debug bundle not configured"))
+	}
+
+	if err := applyLogFlags(deps); err != nil {
+		return nil, err
+	}
+
 	// Initialize logger
 	log := deps.LoggerFactory()
+	if bundle != nil {
+		log = bundle.logger(log)
+	}
 
 	log.Info(ctx, "starting slippy-find", map[string]interface{}{
-		"path":    repoPath,
-		"depth":   depth,
-		"verbose": verbose,
+		"command":   cmd.Name(),
+		"path":      repoPath,
+		"depth":     depth,
+		"max_depth": maxDepth,
+		"verbose":   verbose,
 	})
 
 	// Load configuration
+	configStart := time.Now()
 	cfg, err := deps.ConfigLoader()
+	timings := []domain.StageTiming{{Stage: domain.StageConfigLoad, Duration: time.Since(configStart)}}
 	if err != nil {
+		err = configError(fmt.Errorf("configuration error: %w", err))
 		log.Error(ctx, "failed to load configuration", err, nil)
-		return fmt.Errorf("configuration error: %w", err)
+		return nil, err
+	}
+
+	cfg.Settings = applyFlagOverrides(cfg.Settings)
+	if bundle != nil {
+		bundle.settings = cfg.Settings
+	}
+	log.Debug(ctx, "configuration loaded", map[string]interface{}{
+		"origins": settingOrigins(cfg.Settings),
+	})
+
+	componentPaths, err := lookupComponent(deps, repoPath, component)
+	if err != nil {
+		log.Error(ctx, "failed to load component mapping", err, map[string]interface{}{
+			"component": component,
+		})
+		return nil, err
+	}
+
+	var components map[string][]string
+	if len(componentNames) > 0 {
+		components, err = lookupComponents(deps, repoPath, componentNames)
+		if err != nil {
+			log.Error(ctx, "failed to load component mapping", err, map[string]interface{}{
+				"components": componentNames,
+			})
+			return nil, err
+		}
+	}
+
+	var pullRequest *domain.PullRequest
+	if pullRequestMode || prHeadSHA != "" || prBase != "" {
+		pullRequest, err = loadPullRequest(deps)
+		if err != nil {
+			log.Error(ctx, "failed to determine pull request", err, nil)
+			return nil, err
+		}
+	}
+
+	var hint string
+	if deps.HintLoader != nil {
+		hint, err = deps.HintLoader(repoPath)
+		if err != nil {
+			err = configError(err)
+			log.Error(ctx, "failed to load resolution hint", err, nil)
+			return nil, err
+		}
+	}
+
+	var annotation *domain.Annotation
+	if annotate {
+		if deps.AnnotationLoader == nil {
+			return nil, configError(errors.New("annotation not configured"))
+		}
+		a := deps.AnnotationLoader()
+		annotation = &a
+	}
+
+	s := &session{
+		ctx:        ctx,
+		log:        log,
+		components: components,
+		timings:    timings,
+		cfg:        cfg,
+		input: domain.ResolveInput{
+			Depth:                depth,
+			MaxDepth:             maxDepth,
+			MaxAge:               maxAge,
+			Since:                sinceTime,
+			SelectionPolicy:      policy,
+			FallbackMergeBase:    fallbackMergeBase,
+			FallbackBranchLatest: fallbackBranchLatest,
+			DefaultBranch:        defaultBranch,
+			Component:            component,
+			ComponentPaths:       componentPaths,
+			RequiredSteps:        required,
+			PullRequest:          pullRequest,
+			DenyList:             cfg.DenyList,
+			CorrelationIDFormat:  cfg.CorrelationIDFormat,
+			BranchAffinity:       branchAffinity,
+			Hint:                 hint,
+			Annotation:           annotation,
+			SlowThresholds: domain.SlowThresholds{
+				GitWalk:    slowWalkThreshold,
+				StoreQuery: slowQueryThreshold,
+			},
+			StageTimeouts: stageTimeouts(cfg),
+		},
+	}
+
+	// Closers run in reverse, so metrics are exported after the adapters close
+	if exportingMetrics {
+		s.closers = append(s.closers, func() error {
+			exportMetrics(ctx, log, deps.MetricsExporter)
+			return nil
+		})
 	}
 
 	// Initialize Git repository adapter
+	gitStart := time.Now()
 	gitRepo, err := deps.GitRepoFactory(repoPath, log)
+	s.timings = append(s.timings, domain.StageTiming{Stage: domain.StageGitOpen, Duration: time.Since(gitStart)})
 	if err != nil {
 		log.Error(ctx, "failed to open git repository", err, map[string]interface{}{
 			"path": repoPath,
 		})
 		if errors.Is(err, domain.ErrRepositoryNotFound) {
-			return fmt.Errorf("not a git repository: %s", repoPath)
+			return nil, domain.WithCode(domain.CodeNotARepository, fmt.Errorf("not a git repository: %s", repoPath))
 		}
-		return err
+		return nil, domain.WithCode(domain.CodeGit, err)
 	}
-	defer func() {
+	s.gitRepo = gitRepo
+	s.describeEnvironment()
+	if bundle != nil {
+		bundle.recordGit(ctx, gitRepo)
+	}
+	s.closers = append(s.closers, func() error {
 		if closeErr := gitRepo.Close(); closeErr != nil {
-			log.Warn(ctx, "failed to close git repository", map[string]interface{}{
-				"error": closeErr.Error(),
-			})
+			return fmt.Errorf("failed to close git repository: %w", closeErr)
 		}
-	}()
+		return nil
+	})
 
-	// Initialize slip finder
-	finder, err := deps.SlipFinderFactory(cfg, log)
-	if err != nil {
-		log.Error(ctx, "failed to initialize slip finder", err, nil)
-		return fmt.Errorf("database error: %w", err)
+	if err := expandPullRequestHead(ctx, log, gitRepo, s.input.PullRequest); err != nil {
+		_ = s.Close()
+		return nil, err
 	}
-	defer func() {
+
+	// The store connects in the background while the resolver walks the ancestry
+	finder := openFinderAsync(ctx, log, func() (domain.SlipFinder, error) {
+		return deps.SlipFinderFactory(cfg, log)
+	})
+	s.closers = append(s.closers, func() error {
 		if closeErr := finder.Close(); closeErr != nil {
-			log.Warn(ctx, "failed to close slip finder", map[string]interface{}{
-				"error": closeErr.Error(),
+			return fmt.Errorf("failed to close slip finder: %w", closeErr)
+		}
+		return nil
+	})
+	s.store = finder
+	s.resolver = &storeResolver{Resolver: deps.ResolverFactory(gitRepo, finder, log), store: finder}
+	if retryResolution > 0 {
+		s.resolver = &retryingResolver{
+			Resolver: s.resolver,
+			policy:   retryPolicy{retries: retryResolution, backoff: retryBackoff, budget: retryBudget},
+			log:      log,
+			store:    finder,
+		}
+	}
+	return s, nil
+}
+
+// applyLogFlags checks --log-format, enables debug logging for --verbose, and
+// exports --log-format and --log-file to the environment the logger factory
+// reads. Failing to set the environment is only a warning.
+func applyLogFlags(deps *Dependencies) error {
+	if logFormat != "" && logFormat != "json" && logFormat != "console" {
+		return usageErrorf("unsupported log format %q: expected json or console", logFormat)
+	}
+
+	// Get stderr for warnings
+	stderr := deps.Stderr
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+
+	if verbose {
+		enableDebugLogging(deps)
+	}
+
+	// The log format and file are read by the logger factory (best-effort)
+	if logFormat != "" {
+		if err := os.Setenv("LOG_FORMAT", logFormat); err != nil {
+			writeWarningf(stderr, "warning: could not set log format: %v\n", err)
+		}
+	}
+	if logFile != "" {
+		if err := os.Setenv("LOG_FILE", logFile); err != nil {
+			writeWarningf(stderr, "warning: could not set log file: %v\n", err)
+		}
+	}
+	return nil
+}
+
+// exportMetrics writes the run's metrics to the --metrics-textfile and
+// --metrics-pushgateway targets. Failures are logged as warnings: metrics
+// must never fail a resolution.
+func exportMetrics(ctx context.Context, log Logger, exporter MetricsExporter) {
+	if metricsTextfile != "" {
+		if err := exporter.WriteTextfile(metricsTextfile); err != nil {
+			log.Warn(ctx, "failed to write metrics textfile", map[string]interface{}{
+				"error": err.Error(),
 			})
 		}
-	}()
+	}
+	if metricsPushgateway != "" {
+		if err := exporter.Push(ctx, metricsPushgateway); err != nil {
+			log.Warn(ctx, "failed to push metrics", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+}
 
-	// Create resolver and resolve slip
-	resolver := deps.ResolverFactory(gitRepo, finder, log)
-	result, err := resolver.Resolve(ctx, domain.ResolveInput{
-		Depth: depth,
-	})
-	if err != nil {
-		log.Error(ctx, "failed to resolve slip", err, nil)
-		if errors.Is(err, domain.ErrNoAncestorSlip) {
-			return fmt.Errorf("no slip found in commit ancestry")
+// resolveError maps resolver errors to user-facing messages, keeping their error codes.
+func resolveError(err error) error {
+	if errors.Is(err, domain.ErrNoAncestorSlip) {
+		return domain.WithCode(domain.CodeNoSlip, errors.New("no slip found in commit ancestry"))
+	}
+	if component != "" && errors.Is(err, domain.ErrEmptyAncestry) {
+		return domain.WithCode(domain.CodeEmptyAncestry, fmt.Errorf("no commits in history touch component %q", component))
+	}
+	if errors.Is(err, domain.ErrNoRemoteOrigin) {
+		return domain.WithCode(domain.CodeNoRemoteOrigin,
+			errors.New("no 'origin' remote configured; cannot determine repository name"))
+	}
+	return err
+}
+
+// runResolve executes the slip resolution logic with injected dependencies.
+// The run is traced as one span, parenting the git and store spans beneath it.
+// Unexpected failures are sent to deps.ErrorReporter, and misses and store
+// failures to deps.FailureNotifier, before the session closes. A panic fails
+// the run like any error, so the session is still closed.
+func runResolve(cmd *cobra.Command, args []string, deps *Dependencies) (err error) {
+	start := time.Now()
+	ctx, span := otel.Tracer(tracerName).Start(cmd.Context(), "runResolve")
+	var s *session
+	bundle := newDebugBundle()
+	defer func() {
+		if r := recover(); r != nil {
+			err = recoveredError(ctx, s, r)
 		}
-		if errors.Is(err, domain.ErrNoRemoteOrigin) {
-			return fmt.Errorf("no 'origin' remote configured; cannot determine repository name")
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			span.SetAttributes(attribute.String("slippy.error_code", string(domain.CodeOf(err))))
+			reportFailure(ctx, cmd, deps, s, err)
+			notifyFailure(ctx, deps, s, err)
+			env := s.environment()
+			if resolveFormat == "json" {
+				writeErrorJSON(resultStdout(deps), err, env)
+			}
 		}
+		writeDebugBundle(cmd, deps, bundle, err)
+		closeSession(deps, s)
+		span.End()
+	}()
+	cmd.SetContext(ctx)
+
+	if resolveFormat != "text" && resolveFormat != "json" && outputFormat(deps) == nil {
+		return usageErrorf("unsupported format %q: expected %s", resolveFormat, strings.Join(formatNames(deps), ", "))
+	}
+	if outputFormat(deps) != nil && len(componentNames) > 0 {
+		return usageErrorf("--format %s cannot be combined with --components", resolveFormat)
+	}
+	if gitlabDotenv != "" && len(componentNames) > 0 {
+		return usageErrorf("--gitlab-dotenv cannot be combined with --components")
+	}
+	if buildkiteMetaData && len(componentNames) > 0 {
+		return usageErrorf("--buildkite cannot be combined with --components")
+	}
+	if argoCDApp != "" && len(componentNames) > 0 {
+		return usageErrorf("--argocd-app cannot be combined with --components")
+	}
+	if octopusVariables && len(componentNames) > 0 {
+		return usageErrorf("--octopus cannot be combined with --components")
+	}
+	if octopusVariables && resolveFormat == "json" {
+		return usageErrorf("--octopus cannot be combined with --format json")
+	}
+	if backstageFragment != "" && len(componentNames) > 0 {
+		return usageErrorf("--backstage cannot be combined with --components")
+	}
+	if spinnakerArtifact != "" && len(componentNames) > 0 {
+		return usageErrorf("--spinnaker-artifact cannot be combined with --components")
+	}
+	if azureDevOpsTag && len(componentNames) > 0 {
+		return usageErrorf("--azure-devops-tag cannot be combined with --components")
+	}
+	if notifyURL != "" && len(componentNames) > 0 {
+		return usageErrorf("--notify-url cannot be combined with --components")
+	}
+	if githubDeployment != "" && len(componentNames) > 0 {
+		return usageErrorf("--github-deployment cannot be combined with --components")
+	}
+	if (recordNote || recordTag) && len(componentNames) > 0 {
+		return usageErrorf("--record-note and --record-tag cannot be combined with --components")
+	}
+	if recordPush && !recordNote && !recordTag {
+		return usageErrorf("--record-push requires --record-note or --record-tag")
+	}
+
+	if viaSocket != "" {
+		return runViaSocket(cmd, args, deps)
+	}
+	if handled, err := tryDaemon(cmd, args, deps); handled {
+		return err
+	}
+
+	s, err = openSession(cmd, args, deps, bundle)
+	if err != nil {
 		return err
 	}
+	log := s.log
+
+	if s.components != nil {
+		return runResolveComponents(s, deps, start)
+	}
 
-	// Write correlation ID to stdout
-	writer := deps.OutputWriterFactory()
-	if err := writer.WriteCorrelationID(result.CorrelationID); err != nil {
+	result, err := s.resolver.Resolve(ctx, s.input)
+	if err != nil {
+		log.Error(ctx, "failed to resolve slip", err, nil)
+		return resolveError(err)
+	}
+	result.Timings = append(s.stageTimings(), result.Timings...)
+	log.Debug(ctx, "resolution stage timings", map[string]interface{}{
+		"timings_ms": timingsMillis(result.Timings),
+	})
+	result.Environment = s.environment()
+	span.SetAttributes(
+		attribute.String("slippy.repository", result.Repository),
+		attribute.String("slippy.correlation_id", result.CorrelationID),
+		attribute.String("slippy.resolved_by", result.ResolvedBy),
+		attribute.Int("slippy.depth", result.Depth),
+	)
+
+	// Exports run first, so a failed one leaves only its error on stdout
+	if err := exportResult(ctx, log, deps, result); err != nil {
+		return err
+	}
+	// Write correlation ID (or the full result) to stdout
+	if err := writeResult(deps, result); err != nil {
+		err = outputError(err)
 		log.Error(ctx, "failed to write output", err, nil)
-		return fmt.Errorf("output error: %w", err)
+		return err
 	}
 
 	log.Info(ctx, "slip resolution complete", map[string]interface{}{
-		"correlation_id": result.CorrelationID,
-		"matched_commit": result.MatchedCommit,
-		"repository":     result.Repository,
-		"resolved_by":    result.ResolvedBy,
+		"correlation_id":   result.CorrelationID,
+		"matched_commit":   result.MatchedCommit,
+		"repository":       result.Repository,
+		"resolved_by":      result.ResolvedBy,
+		"selection_policy": string(result.SelectionPolicy),
+		"candidates":       result.Candidates,
+		"depth":            result.Depth,
+		"distance":         result.Distance,
+		"confidence":       string(result.Confidence),
+		"component":        result.Component,
+		"chunks_queried":   result.Chunks.Queried,
+		"chunks_needed":    result.Chunks.Needed,
+	})
+
+	recordAudit(s, deps, time.Since(start), result)
+	publishResolutions(s, deps, time.Since(start), result)
+	recordSlip(s, result)
+	return nil
+}
+
+// runResolveComponents resolves every --components component and writes the
+// component -> correlation ID map to stdout. start is when the run began.
+func runResolveComponents(s *session, deps *Dependencies, start time.Time) error {
+	ctx, log := s.ctx, s.log
+
+	results, err := s.resolver.ResolveComponents(ctx, s.input, s.components)
+	if err != nil {
+		log.Error(ctx, "failed to resolve component slips", err, nil)
+		return resolveError(err)
+	}
+
+	// Every component is resolved through the one finder the session opened.
+	var storeOpen time.Duration
+	for _, t := range s.stageTimings() {
+		if t.Stage == domain.StageStoreOpen {
+			storeOpen = t.Duration
+		}
+	}
+	// The ID map has no place for the fingerprint, so it is only logged
+	s.environment()
+
+	ids := make(map[string]string, len(results))
+	for name, result := range results {
+		ids[name] = result.CorrelationID
+	}
+
+	stdout := resultStdout(deps)
+	if resolveFormat == "json" {
+		err = json.NewEncoder(stdout).Encode(ids)
+	} else {
+		err = writeComponentIDs(stdout, ids)
+	}
+	if err != nil {
+		err = outputError(err)
+		log.Error(ctx, "failed to write output", err, nil)
+		return err
+	}
+
+	log.Info(ctx, "component slip resolution complete", map[string]interface{}{
+		"components":          ids,
+		"components_count":    len(ids),
+		"store_open_ms":       millis(storeOpen),
+		"store_open_saved_ms": millis(reuseSavings(storeOpen, len(ids))),
 	})
 
+	ordered := make([]*domain.ResolveOutput, 0, len(results))
+	for _, name := range slices.Sorted(maps.Keys(results)) {
+		ordered = append(ordered, results[name])
+	}
+	recordAudit(s, deps, time.Since(start), ordered...)
+	publishResolutions(s, deps, time.Since(start), ordered...)
+	return nil
+}
+
+// writeComponentIDs writes one component=correlation_id line per component, by name,
+// a form that can be appended to $GITHUB_OUTPUT as is.
+func writeComponentIDs(w io.Writer, ids map[string]string) error {
+	for _, name := range slices.Sorted(maps.Keys(ids)) {
+		if _, err := fmt.Fprintf(w, "%s=%s\n", name, ids[name]); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// Execute runs the root command.
+// Execute runs the command line in os.Args with the dependencies set by
+// SetDefaultDependencies, and exits with ExitCode on failure.
+//
+// Deprecated: Use Run, or App.Run, and exit with ExitCode.
 func Execute() {
-	rootCmd := NewRootCmd()
-	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+	if err := ExecuteContext(context.Background()); err != nil {
+		os.Exit(ExitCode(err))
+	}
+}
+
+// ExecuteContext runs the command line in os.Args with ctx and the
+// dependencies set by SetDefaultDependencies; see App.Run.
+//
+// Deprecated: Use Run, or App.Run.
+func ExecuteContext(ctx context.Context) error {
+	return Run(ctx, os.Args[1:], defaultDeps.Load())
+}
+
+// executeRecovered runs root with args, recovering a panic into a
+// *PanicError and writing the crash report of one to stderr.
+func executeRecovered(ctx context.Context, root *cobra.Command, args []string, stderr io.Writer) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = newPanicError(r, nil)
+		}
+		var panicErr *PanicError
+		if errors.As(err, &panicErr) {
+			commandPath := root.CommandPath()
+			if c, _, findErr := root.Find(args); findErr == nil {
+				commandPath = c.CommandPath()
+			}
+			writeCrashReport(stderr, panicErr, commandPath, args)
+		}
+	}()
+	root.SetArgs(args)
+	return root.ExecuteContext(ctx)
+}
+
+// ExitCode maps a command error to the process exit code.
+func ExitCode(err error) int {
+	var panicErr *PanicError
+	switch {
+	case errors.As(err, &panicErr):
+		return ExitCodePanic
+	case errors.Is(err, domain.ErrStepRequirementUnmet):
+		return ExitCodeStepRequirementUnmet
+	default:
+		return ExitCodeError
 	}
 }
 
@@ -271,3 +1252,209 @@ func writeWarningf(w io.Writer, format string, args ...any) {
 		return
 	}
 }
+
+// resultJSON is the JSON representation of a domain.ResolveOutput.
+type resultJSON struct {
+	SchemaVersion   string             `json:"schema_version"`
+	CorrelationID   string             `json:"correlation_id"`
+	MatchedCommit   string             `json:"matched_commit"`
+	Repository      string             `json:"repository"`
+	Branch          string             `json:"branch,omitempty"`
+	DefaultBranch   string             `json:"default_branch,omitempty"`
+	ResolvedBy      string             `json:"resolved_by"`
+	SelectionPolicy string             `json:"selection_policy"`
+	Candidates      int                `json:"candidates"`
+	Depth           int                `json:"depth"`
+	Distance        int                `json:"distance"`
+	Confidence      string             `json:"confidence"`
+	Component       string             `json:"component,omitempty"`
+	Timings         map[string]float64 `json:"timings_ms,omitempty"`
+	Mock            bool               `json:"mock,omitempty"`
+	Environment     *environmentJSON   `json:"environment,omitempty"`
+}
+
+// writeResult writes result to stdout in the --format format.
+func writeResult(deps *Dependencies, result *domain.ResolveOutput) error {
+	if resolveFormat == "json" {
+		return writeResultJSON(resultStdout(deps), result)
+	}
+	if writer := outputFormat(deps); writer != nil {
+		return writer.WriteResult(resultStdout(deps), result)
+	}
+	if crlfOutput {
+		// The output writer ends the line with LF
+		_, err := fmt.Fprintln(resultStdout(deps), result.CorrelationID)
+		return err
+	}
+	return deps.OutputWriterFactory().WriteCorrelationID(result.CorrelationID)
+}
+
+// outputFormat returns the extension writing the --format format, or nil if
+// the format is built in or unknown.
+func outputFormat(deps *Dependencies) domain.ResultWriter {
+	if deps == nil {
+		return nil
+	}
+	return deps.OutputFormats[resolveFormat]
+}
+
+// formatNames lists the --format values: text, json, then any added by
+// extensions, sorted.
+func formatNames(deps *Dependencies) []string {
+	names := []string{"text", "json"}
+	if deps == nil {
+		return names
+	}
+	return append(names, slices.Sorted(maps.Keys(deps.OutputFormats))...)
+}
+
+// writeResultJSON writes result as a single-line JSON object to w (stdout if nil).
+func writeResultJSON(w io.Writer, result *domain.ResolveOutput) error {
+	if w == nil {
+		w = os.Stdout
+	}
+	return json.NewEncoder(w).Encode(newResultJSON(result))
+}
+
+// newResultJSON converts result to its JSON representation.
+func newResultJSON(result *domain.ResolveOutput) resultJSON {
+	return resultJSON{
+		SchemaVersion:   SchemaVersion,
+		CorrelationID:   result.CorrelationID,
+		MatchedCommit:   result.MatchedCommit,
+		Repository:      result.Repository,
+		Branch:          result.Branch,
+		DefaultBranch:   result.DefaultBranch,
+		ResolvedBy:      result.ResolvedBy,
+		SelectionPolicy: string(result.SelectionPolicy),
+		Candidates:      result.Candidates,
+		Depth:           result.Depth,
+		Distance:        result.Distance,
+		Confidence:      string(result.Confidence),
+		Component:       result.Component,
+		Timings:         timingsMillis(result.Timings),
+		Mock:            result.Mock,
+		Environment:     newEnvironmentJSON(result.Environment),
+	}
+}
+
+// timingsMillis maps each stage to its duration in milliseconds; nil when empty.
+func timingsMillis(timings []domain.StageTiming) map[string]float64 {
+	if len(timings) == 0 {
+		return nil
+	}
+	ms := make(map[string]float64, len(timings))
+	for _, t := range timings {
+		ms[t.Stage] = millis(t.Duration)
+	}
+	return ms
+}
+
+// lookupComponent returns the path prefixes for name from the repository's
+// component mapping. An empty name disables component resolution.
+func lookupComponent(deps *Dependencies, repoPath, name string) ([]string, error) {
+	if name == "" {
+		return nil, nil
+	}
+	components, err := lookupComponents(deps, repoPath, []string{name})
+	if err != nil {
+		return nil, err
+	}
+	return components[name], nil
+}
+
+// lookupComponents returns the path prefixes of each name from the repository's
+// component mapping, loaded once.
+func lookupComponents(deps *Dependencies, repoPath string, names []string) (map[string][]string, error) {
+	if deps.ComponentLoader == nil {
+		return nil, configError(errors.New("component resolution not configured"))
+	}
+
+	components, err := deps.ComponentLoader(repoPath)
+	if err != nil {
+		return nil, configError(fmt.Errorf("component configuration error: %w", err))
+	}
+	selected := make(map[string][]string, len(names))
+	for _, name := range names {
+		paths, ok := components[name]
+		if !ok {
+			known := slices.Sorted(maps.Keys(components))
+			return nil, usageErrorf("unknown component %q (defined: %s)", name, strings.Join(known, ", "))
+		}
+		selected[name] = paths
+	}
+	return selected, nil
+}
+
+// expandPullRequestHead replaces an abbreviated pull request head SHA, as
+// pasted into --pr-head-sha, with the full SHA of its commit, so the search,
+// logs, and output all use the full SHA. A SHA that matches several commits,
+// or a full SHA of another object format than the repository's, fails the
+// run; one missing locally is left for the walk to report.
+func expandPullRequestHead(
+	ctx context.Context,
+	log Logger,
+	gitRepo domain.LocalGitRepository,
+	pr *domain.PullRequest,
+) error {
+	if pr == nil || pr.HeadSHA == "" {
+		return nil
+	}
+	resolver, ok := gitRepo.(domain.CommitResolver)
+	if !ok {
+		return nil
+	}
+	sha, err := resolver.ResolveCommit(ctx, pr.HeadSHA)
+	switch {
+	case errors.Is(err, domain.ErrAmbiguousCommit):
+		log.Error(ctx, "ambiguous pull request head", err, nil)
+		return fmt.Errorf("--pr-head-sha: %w", err)
+	case errors.Is(err, domain.ErrObjectFormatMismatch):
+		log.Error(ctx, "pull request head does not match the repository's object format", err, nil)
+		return fmt.Errorf("--pr-head-sha: %w", err)
+	case err != nil && domain.ObjectFormatOf(pr.HeadSHA) != "":
+		return nil // already full; the walk reports it if missing
+	case err != nil:
+		log.Warn(ctx, "failed to expand pull request head", map[string]interface{}{
+			"pr_head": pr.HeadSHA,
+			"error":   err.Error(),
+		})
+		return nil
+	}
+	pr.HeadSHA = sha
+	return nil
+}
+
+// loadPullRequest builds the PR-aware resolution input from --pr-head-sha and
+// --pr-base, detecting whatever the flags leave unset from the CI environment.
+// An explicit head SHA is enough on its own; detection failures are then ignored.
+func loadPullRequest(deps *Dependencies) (*domain.PullRequest, error) {
+	pr := &domain.PullRequest{HeadSHA: prHeadSHA, BaseRef: prBase}
+	if pr.HeadSHA != "" && pr.BaseRef != "" {
+		return pr, nil
+	}
+	if deps.PullRequestLoader == nil {
+		if pr.HeadSHA == "" {
+			return nil, configError(errors.New("pull request detection not configured: pass --pr-head-sha"))
+		}
+		return pr, nil
+	}
+
+	detected, err := deps.PullRequestLoader()
+	if err != nil {
+		if pr.HeadSHA != "" {
+			return pr, nil
+		}
+		return nil, configError(fmt.Errorf("pull request detection failed (pass --pr-head-sha): %w", err))
+	}
+
+	if pr.HeadSHA == "" {
+		pr.HeadSHA = detected.HeadSHA
+	}
+	// The detected base SHA only applies to the detected base branch
+	if pr.BaseRef == "" {
+		pr.BaseRef = detected.BaseRef
+		pr.BaseSHA = detected.BaseSHA
+	}
+	return pr, nil
+}
@@ -4,13 +4,25 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
-	"github.com/MyCarrier-DevOps/slippy-find/internal/domain"
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 // Test mocks for dependency injection testing.
@@ -41,6 +53,30 @@ func (m *mockGitRepo) GetCommitAncestry(_ context.Context, _ int) ([]string, err
 	return m.commits, m.commitsErr
 }
 
+func (m *mockGitRepo) GetMergeBaseAncestry(_ context.Context, _ string, _ int) ([]string, error) {
+	return m.commits, m.commitsErr
+}
+
+func (m *mockGitRepo) GetPathAncestry(_ context.Context, _ []string, _ int) ([]string, error) {
+	return m.commits, m.commitsErr
+}
+
+func (m *mockGitRepo) GetPathAncestries(
+	_ context.Context,
+	paths map[string][]string,
+	_ int,
+) (map[string][]string, error) {
+	ancestries := make(map[string][]string, len(paths))
+	for name := range paths {
+		ancestries[name] = m.commits
+	}
+	return ancestries, m.commitsErr
+}
+
+func (m *mockGitRepo) GetAncestryFrom(_ context.Context, _ string, _ int) ([]string, error) {
+	return m.commits, m.commitsErr
+}
+
 func (m *mockGitRepo) Close() error {
 	m.closeCalled = true
 	return m.closeErr
@@ -59,6 +95,27 @@ func (m *mockSlipFinder) FindByCommits(_ context.Context, _ string, _ []string)
 	return m.slip, m.matchCommit, m.findErr
 }
 
+func (m *mockSlipFinder) FindAllByCommits(_ context.Context, _ string, _ []string) ([]domain.SlipMatch, error) {
+	if m.slip == nil {
+		return nil, m.findErr
+	}
+	return []domain.SlipMatch{{Slip: m.slip, MatchedCommit: m.matchCommit}}, m.findErr
+}
+
+func (m *mockSlipFinder) FindLatestByBranch(_ context.Context, _, _ string) (*domain.Slip, error) {
+	if m.slip == nil && m.findErr == nil {
+		return nil, domain.ErrSlipNotFound
+	}
+	return m.slip, m.findErr
+}
+
+func (m *mockSlipFinder) FindByID(_ context.Context, _ string) (*domain.Slip, error) {
+	if m.slip == nil && m.findErr == nil {
+		return nil, domain.ErrSlipNotFound
+	}
+	return m.slip, m.findErr
+}
+
 func (m *mockSlipFinder) Close() error {
 	m.closeCalled = true
 	return m.closeErr
@@ -66,14 +123,34 @@ func (m *mockSlipFinder) Close() error {
 
 // mockResolver implements domain.Resolver for testing.
 type mockResolver struct {
-	output *domain.ResolveOutput
-	err    error
+	output           *domain.ResolveOutput
+	allOutput        *domain.ResolveAllOutput
+	componentOutputs map[string]*domain.ResolveOutput
+	err              error
+	input            domain.ResolveInput
+	components       map[string][]string
 }
 
-func (m *mockResolver) Resolve(_ context.Context, _ domain.ResolveInput) (*domain.ResolveOutput, error) {
+func (m *mockResolver) Resolve(_ context.Context, input domain.ResolveInput) (*domain.ResolveOutput, error) {
+	m.input = input
 	return m.output, m.err
 }
 
+func (m *mockResolver) ResolveAll(_ context.Context, input domain.ResolveInput) (*domain.ResolveAllOutput, error) {
+	m.input = input
+	return m.allOutput, m.err
+}
+
+func (m *mockResolver) ResolveComponents(
+	_ context.Context,
+	input domain.ResolveInput,
+	components map[string][]string,
+) (map[string]*domain.ResolveOutput, error) {
+	m.input = input
+	m.components = components
+	return m.componentOutputs, m.err
+}
+
 // mockOutputWriter implements domain.OutputWriter for testing.
 type mockOutputWriter struct {
 	writtenID string
@@ -224,6 +301,11 @@ func TestRootCmd_SlipFinderError(t *testing.T) {
 		SlipFinderFactory: func(_ *AppConfig, _ Logger) (domain.SlipFinder, error) {
 			return nil, errors.New("database connection failed")
 		},
+		// The store opens while the resolver runs; its failure fails the run
+		// even though this resolver never queries it.
+		ResolverFactory: func(_ domain.LocalGitRepository, _ domain.SlipFinder, _ Logger) domain.Resolver {
+			return &mockResolver{}
+		},
 		Stderr: io.Discard,
 	}
 
@@ -384,6 +466,75 @@ func TestRootCmd_Success(t *testing.T) {
 	assert.True(t, mockFinder.closeCalled)
 }
 
+// panickingResolver panics on Resolve.
+type panickingResolver struct {
+	mockResolver
+}
+
+func (p *panickingResolver) Resolve(_ context.Context, _ domain.ResolveInput) (*domain.ResolveOutput, error) {
+	panic("nil map")
+}
+
+func TestRootCmd_CloseErrors(t *testing.T) {
+	mockGit := &mockGitRepo{closeErr: errors.New("pack file busy")}
+	mockFinder := &mockSlipFinder{closeErr: errors.New("connection reset")}
+	var stderr bytes.Buffer
+	deps := allTestDeps(&mockResolver{output: &domain.ResolveOutput{CorrelationID: "slip-1"}})
+	deps.GitRepoFactory = func(_ string, _ Logger) (domain.LocalGitRepository, error) { return mockGit, nil }
+	deps.SlipFinderFactory = func(_ *AppConfig, _ Logger) (domain.SlipFinder, error) { return mockFinder, nil }
+	deps.OutputWriterFactory = func() domain.OutputWriter { return &mockOutputWriter{} }
+	deps.Stderr = &stderr
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{"."})
+
+	require.NoError(t, cmd.Execute(), "a failed close does not fail the resolution")
+	assert.True(t, mockGit.closeCalled)
+	assert.True(t, mockFinder.closeCalled)
+	assert.Contains(t, stderr.String(), "warning: failed to close slip finder: connection reset\n"+
+		"failed to close git repository: pack file busy")
+}
+
+func TestRootCmd_Panic(t *testing.T) {
+	mockGit := &mockGitRepo{}
+	mockFinder := &mockSlipFinder{}
+	deps := allTestDeps(nil)
+	deps.ResolverFactory = func(_ domain.LocalGitRepository, _ domain.SlipFinder, _ Logger) domain.Resolver {
+		return &panickingResolver{}
+	}
+	deps.GitRepoFactory = func(_ string, _ Logger) (domain.LocalGitRepository, error) { return mockGit, nil }
+	deps.SlipFinderFactory = func(_ *AppConfig, _ Logger) (domain.SlipFinder, error) { return mockFinder, nil }
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{"."})
+
+	err := cmd.Execute()
+
+	require.EqualError(t, err, "unexpected panic: nil map")
+	assert.Equal(t, domain.CodeUnknown, domain.CodeOf(err))
+	assert.Equal(t, ExitCodePanic, ExitCode(err))
+	assert.True(t, mockGit.closeCalled)
+	assert.True(t, mockFinder.closeCalled)
+}
+
+func TestSession_Close(t *testing.T) {
+	var closed []string
+	s := &session{
+		ctx: context.Background(),
+		log: &mockLogger{},
+		closers: []func() error{
+			func() error { closed = append(closed, "first"); return nil },
+			func() error { panic("closed twice") },
+			func() error { closed = append(closed, "last"); return errors.New("connection reset") },
+		},
+	}
+
+	err := s.Close()
+
+	assert.Equal(t, []string{"last", "first"}, closed, "closers run in reverse, past failures and panics")
+	assert.EqualError(t, err, "connection reset\npanic while closing: closed twice")
+}
+
 func TestRootCmd_Success_WithDepthFlag(t *testing.T) {
 	mockGit := &mockGitRepo{}
 	mockFinder := &mockSlipFinder{}
@@ -424,12 +575,15 @@ func TestRootCmd_Success_WithDepthFlag(t *testing.T) {
 }
 
 func TestRootCmd_Success_WithVerboseFlag(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "")
 	mockGit := &mockGitRepo{}
 	mockFinder := &mockSlipFinder{}
 	mockWriter := &mockOutputWriter{}
+	var debugLogging bool
 
 	deps := &Dependencies{
 		LoggerFactory: func() Logger { return &mockLogger{} },
+		DebugLogging:  func() { debugLogging = true },
 		ConfigLoader: func() (*AppConfig, error) {
 			return &AppConfig{Database: "ci"}, nil
 		},
@@ -460,6 +614,67 @@ func TestRootCmd_Success_WithVerboseFlag(t *testing.T) {
 
 	require.NoError(t, err)
 	assert.Equal(t, "verbose-test-id", mockWriter.writtenID)
+	assert.True(t, debugLogging)
+	assert.Empty(t, os.Getenv("LOG_LEVEL"), "the environment is left unchanged")
+}
+
+func TestRootCmd_LogFormat(t *testing.T) {
+	t.Setenv("LOG_FORMAT", "")
+	t.Setenv("LOG_FILE", "")
+	mockWriter := &mockOutputWriter{}
+	var loggerBuilt bool
+
+	deps := &Dependencies{
+		LoggerFactory: func() Logger {
+			loggerBuilt = os.Getenv("LOG_FORMAT") == "console" && os.Getenv("LOG_FILE") == "/tmp/slippy.log"
+			return &mockLogger{}
+		},
+		ConfigLoader: func() (*AppConfig, error) {
+			return &AppConfig{Database: "ci"}, nil
+		},
+		GitRepoFactory: func(_ string, _ Logger) (domain.LocalGitRepository, error) {
+			return &mockGitRepo{}, nil
+		},
+		SlipFinderFactory: func(_ *AppConfig, _ Logger) (domain.SlipFinder, error) {
+			return &mockSlipFinder{}, nil
+		},
+		ResolverFactory: func(_ domain.LocalGitRepository, _ domain.SlipFinder, _ Logger) domain.Resolver {
+			return &mockResolver{output: &domain.ResolveOutput{CorrelationID: "console-id"}}
+		},
+		OutputWriterFactory: func() domain.OutputWriter {
+			return mockWriter
+		},
+		Stdout: io.Discard,
+		Stderr: io.Discard,
+	}
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{"--log-format", "console", "--log-file", "/tmp/slippy.log", "."})
+
+	err := cmd.Execute()
+
+	require.NoError(t, err)
+	assert.Equal(t, "console-id", mockWriter.writtenID)
+	assert.True(t, loggerBuilt, "logger should be built after LOG_FORMAT is applied")
+}
+
+func TestRootCmd_InvalidLogFormat(t *testing.T) {
+	deps := &Dependencies{
+		LoggerFactory: func() Logger { return &mockLogger{} },
+		ConfigLoader: func() (*AppConfig, error) {
+			return &AppConfig{Database: "ci"}, nil
+		},
+		Stdout: io.Discard,
+		Stderr: io.Discard,
+	}
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{"--log-format", "logfmt", "."})
+
+	err := cmd.Execute()
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unsupported log format "logfmt"`)
 }
 
 func TestRootCmd_WithCustomPath(t *testing.T) {
@@ -503,6 +718,44 @@ func TestRootCmd_WithCustomPath(t *testing.T) {
 	assert.Equal(t, "/custom/repo/path", receivedPath)
 }
 
+func TestRootCmd_RepoRoot(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		finderErr error
+		wantPath  string
+	}{
+		{name: "subdirectory", args: []string{"services/foo"}, wantPath: "/repo"},
+		{name: "no repository", args: []string{"services/foo"}, finderErr: domain.ErrRepositoryNotFound, wantPath: "services/foo"},
+		{name: "no path argument", wantPath: "/repo"},
+		{name: "no path argument outside a repository", finderErr: domain.ErrRepositoryNotFound, wantPath: "."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var receivedPath, hintPath string
+			deps := allTestDeps(&mockResolver{output: &domain.ResolveOutput{CorrelationID: "slip-1"}})
+			deps.GitRepoFactory = func(path string, _ Logger) (domain.LocalGitRepository, error) {
+				receivedPath = path
+				return &mockGitRepo{}, nil
+			}
+			deps.HintLoader = func(path string) (string, error) {
+				hintPath = path
+				return "", nil
+			}
+			deps.RepoRootFinder = func(string) (string, error) { return "/repo", tt.finderErr }
+			deps.OutputWriterFactory = func() domain.OutputWriter { return &mockOutputWriter{} }
+
+			cmd := NewRootCmdWithDeps(deps)
+			cmd.SetArgs(append(tt.args, "--no-daemon"))
+			err := cmd.Execute()
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPath, receivedPath)
+			assert.Equal(t, tt.wantPath, hintPath)
+		})
+	}
+}
+
 func TestWriteWarningf(t *testing.T) {
 	t.Run("writes formatted warning to writer", func(t *testing.T) {
 		var buf bytes.Buffer
@@ -524,3 +777,902 @@ type failingWriter struct{}
 func (f *failingWriter) Write(_ []byte) (int, error) {
 	return 0, errors.New("write failed")
 }
+
+func TestRootCmd_InvalidSelectionPolicy(t *testing.T) {
+	deps := &Dependencies{
+		LoggerFactory: func() Logger { return &mockLogger{} },
+		Stderr:        io.Discard,
+	}
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{"--selection-policy", "random"})
+
+	err := cmd.Execute()
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, domain.ErrInvalidSelectionPolicy)
+}
+
+func TestRootCmd_SearchFlags(t *testing.T) {
+	defaultSlow := domain.SlowThresholds{GitWalk: domain.DefaultSlowGitWalk, StoreQuery: domain.DefaultSlowStoreQuery}
+
+	tests := []struct {
+		name      string
+		args      []string
+		wantInput domain.ResolveInput
+	}{
+		{
+			name: "defaults",
+			args: []string{"."},
+			wantInput: domain.ResolveInput{
+				Depth:           domain.DefaultAncestryDepth,
+				SelectionPolicy: domain.DefaultSelectionPolicy,
+				BranchAffinity:  true,
+				SlowThresholds:  defaultSlow,
+			},
+		},
+		{
+			name: "all fallbacks enabled",
+			args: []string{
+				".", "--fallback-merge-base", "--fallback-branch-latest", "--default-branch", "develop",
+			},
+			wantInput: domain.ResolveInput{
+				Depth:                domain.DefaultAncestryDepth,
+				SelectionPolicy:      domain.DefaultSelectionPolicy,
+				FallbackMergeBase:    true,
+				FallbackBranchLatest: true,
+				DefaultBranch:        "develop",
+				BranchAffinity:       true,
+				SlowThresholds:       defaultSlow,
+			},
+		},
+		{
+			name: "age limits",
+			args: []string{".", "--max-age", "72h", "--since", "2026-01-02T15:04:05Z"},
+			wantInput: domain.ResolveInput{
+				Depth:           domain.DefaultAncestryDepth,
+				MaxAge:          72 * time.Hour,
+				Since:           time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC),
+				SelectionPolicy: domain.DefaultSelectionPolicy,
+				BranchAffinity:  true,
+				SlowThresholds:  defaultSlow,
+			},
+		},
+		{
+			name: "component",
+			args: []string{".", "--component", "payments-api"},
+			wantInput: domain.ResolveInput{
+				Depth:           domain.DefaultAncestryDepth,
+				SelectionPolicy: domain.DefaultSelectionPolicy,
+				BranchAffinity:  true,
+				SlowThresholds:  defaultSlow,
+				Component:       "payments-api",
+				ComponentPaths:  []string{"services/payments"},
+			},
+		},
+		{
+			name: "pull request detected",
+			args: []string{".", "--pr"},
+			wantInput: domain.ResolveInput{
+				Depth:           domain.DefaultAncestryDepth,
+				SelectionPolicy: domain.DefaultSelectionPolicy,
+				BranchAffinity:  true,
+				SlowThresholds:  defaultSlow,
+				PullRequest:     &domain.PullRequest{HeadSHA: "event-head", BaseRef: "main", BaseSHA: "event-base"},
+			},
+		},
+		{
+			name: "pull request explicit",
+			args: []string{".", "--pr-head-sha", "abc123", "--pr-base", "develop"},
+			wantInput: domain.ResolveInput{
+				Depth:           domain.DefaultAncestryDepth,
+				SelectionPolicy: domain.DefaultSelectionPolicy,
+				BranchAffinity:  true,
+				SlowThresholds:  defaultSlow,
+				PullRequest:     &domain.PullRequest{HeadSHA: "abc123", BaseRef: "develop"},
+			},
+		},
+		{
+			name: "step requirements",
+			args: []string{".", "--require-step", "push_parsed=completed", "--require-step", "builds=skipped"},
+			wantInput: domain.ResolveInput{
+				Depth:           domain.DefaultAncestryDepth,
+				SelectionPolicy: domain.DefaultSelectionPolicy,
+				BranchAffinity:  true,
+				SlowThresholds:  defaultSlow,
+				RequiredSteps: []domain.StepRequirement{
+					{Step: "push_parsed", Status: "completed"},
+					{Step: "builds", Status: "skipped"},
+				},
+			},
+		},
+		{
+			name: "progressive deepening",
+			args: []string{".", "--depth", "10", "--max-depth", "400"},
+			wantInput: domain.ResolveInput{
+				Depth:           10,
+				MaxDepth:        400,
+				SelectionPolicy: domain.DefaultSelectionPolicy,
+				BranchAffinity:  true,
+				SlowThresholds:  defaultSlow,
+			},
+		},
+		{
+			name: "slow thresholds",
+			args: []string{".", "--slow-walk-threshold", "500ms", "--slow-query-threshold", "0"},
+			wantInput: domain.ResolveInput{
+				Depth:           domain.DefaultAncestryDepth,
+				SelectionPolicy: domain.DefaultSelectionPolicy,
+				BranchAffinity:  true,
+				SlowThresholds:  domain.SlowThresholds{GitWalk: 500 * time.Millisecond},
+			},
+		},
+		{
+			name: "stage timeouts",
+			args: []string{".", "--git-timeout", "30s", "--store-timeout", "5s"},
+			wantInput: domain.ResolveInput{
+				Depth:           domain.DefaultAncestryDepth,
+				SelectionPolicy: domain.DefaultSelectionPolicy,
+				BranchAffinity:  true,
+				SlowThresholds:  defaultSlow,
+				StageTimeouts:   domain.StageTimeouts{Git: 30 * time.Second, Store: 5 * time.Second},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := &mockResolver{output: &domain.ResolveOutput{CorrelationID: "id"}}
+			deps := &Dependencies{
+				LoggerFactory: func() Logger { return &mockLogger{} },
+				ConfigLoader: func() (*AppConfig, error) {
+					return &AppConfig{Database: "ci"}, nil
+				},
+				GitRepoFactory: func(_ string, _ Logger) (domain.LocalGitRepository, error) {
+					return &mockGitRepo{}, nil
+				},
+				SlipFinderFactory: func(_ *AppConfig, _ Logger) (domain.SlipFinder, error) {
+					return &mockSlipFinder{}, nil
+				},
+				ResolverFactory: func(_ domain.LocalGitRepository, _ domain.SlipFinder, _ Logger) domain.Resolver {
+					return resolver
+				},
+				ComponentLoader: func(_ string) (map[string][]string, error) {
+					return map[string][]string{"payments-api": {"services/payments"}}, nil
+				},
+				PullRequestLoader: func() (*domain.PullRequest, error) {
+					return &domain.PullRequest{HeadSHA: "event-head", BaseRef: "main", BaseSHA: "event-base"}, nil
+				},
+				AnnotationLoader: func() domain.Annotation {
+					return domain.Annotation{Host: "runner-7", Pipeline: "build#42"}
+				},
+				OutputWriterFactory: func() domain.OutputWriter { return &mockOutputWriter{} },
+				Stdout:              io.Discard,
+				Stderr:              io.Discard,
+			}
+
+			cmd := NewRootCmdWithDeps(deps)
+			cmd.SetArgs(tt.args)
+
+			require.NoError(t, cmd.Execute())
+			assert.Equal(t, tt.wantInput, resolver.input)
+		})
+	}
+}
+
+func TestRootCmd_InvalidSince(t *testing.T) {
+	deps := &Dependencies{
+		LoggerFactory: func() Logger { return &mockLogger{} },
+		Stderr:        io.Discard,
+	}
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{"--since", "yesterday"})
+
+	err := cmd.Execute()
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid --since value")
+}
+
+func TestRootCmd_NegativeStageTimeout(t *testing.T) {
+	deps := &Dependencies{
+		LoggerFactory: func() Logger { return &mockLogger{} },
+		Stderr:        io.Discard,
+	}
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{"--store-timeout", "-1s"})
+
+	err := cmd.Execute()
+
+	require.Error(t, err)
+	assert.Equal(t, domain.CodeInvalidInput, domain.CodeOf(err))
+	assert.Contains(t, err.Error(), "must not be negative")
+}
+
+func TestLookupComponent(t *testing.T) {
+	loader := func(_ string) (map[string][]string, error) {
+		return map[string][]string{
+			"payments-api": {"services/payments"},
+			"orders":       {"services/orders"},
+		}, nil
+	}
+
+	tests := []struct {
+		name      string
+		loader    func(string) (map[string][]string, error)
+		component string
+		wantPaths []string
+		wantErr   string
+	}{
+		{name: "disabled", component: ""},
+		{name: "found", loader: loader, component: "payments-api", wantPaths: []string{"services/payments"}},
+		{
+			name:      "unknown component lists known names",
+			loader:    loader,
+			component: "billing",
+			wantErr:   `unknown component "billing" (defined: orders, payments-api)`,
+		},
+		{name: "loader not configured", component: "payments-api", wantErr: "component resolution not configured"},
+		{
+			name: "loader error",
+			loader: func(_ string) (map[string][]string, error) {
+				return nil, errors.New("missing .slippy.yaml")
+			},
+			component: "payments-api",
+			wantErr:   "component configuration error: missing .slippy.yaml",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths, err := lookupComponent(&Dependencies{ComponentLoader: tt.loader}, ".", tt.component)
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantErr, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPaths, paths)
+		})
+	}
+}
+
+func TestRootCmd_FormatJSON(t *testing.T) {
+	var stdout bytes.Buffer
+	resolver := &mockResolver{output: &domain.ResolveOutput{
+		CorrelationID:   "slip-1",
+		MatchedCommit:   "c1",
+		Repository:      "org/repo",
+		Branch:          "main",
+		DefaultBranch:   "develop",
+		ResolvedBy:      domain.ResolvedByAncestry,
+		SelectionPolicy: domain.SelectionNearestCommit,
+		Candidates:      1,
+		Depth:           25,
+		Distance:        1,
+		Confidence:      domain.ConfidenceNearAncestor,
+	}}
+	deps := allTestDeps(resolver)
+	deps.Stdout = &stdout
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{".", "--format", "json"})
+
+	require.NoError(t, cmd.Execute())
+	assert.JSONEq(t, `{
+		"schema_version": "1.4",
+		"correlation_id": "slip-1",
+		"matched_commit": "c1",
+		"repository": "org/repo",
+		"branch": "main",
+		"default_branch": "develop",
+		"resolved_by": "ancestry",
+		"selection_policy": "nearest-commit",
+		"candidates": 1,
+		"depth": 25,
+		"distance": 1,
+		"confidence": "near-ancestor"
+	}`, withoutTimings(t, stdout.String()))
+}
+
+func TestRootCmd_FormatJSON_Mock(t *testing.T) {
+	var stdout bytes.Buffer
+	deps := allTestDeps(&mockResolver{output: &domain.ResolveOutput{CorrelationID: "slip-1", Mock: true}})
+	deps.Stdout = &stdout
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{".", "--format", "json"})
+
+	require.NoError(t, cmd.Execute())
+	var out map[string]any
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &out))
+	assert.Equal(t, true, out["mock"])
+}
+
+func TestRootCmd_FormatJSON_Timings(t *testing.T) {
+	var stdout bytes.Buffer
+	resolver := &mockResolver{output: &domain.ResolveOutput{
+		CorrelationID: "slip-1",
+		Timings: []domain.StageTiming{
+			{Stage: domain.StageGitContext, Duration: time.Millisecond},
+			{Stage: domain.StageStoreQuery, Duration: 1500 * time.Microsecond},
+		},
+	}}
+	deps := allTestDeps(resolver)
+	deps.Stdout = &stdout
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{".", "--format", "json"})
+
+	require.NoError(t, cmd.Execute())
+	var doc struct {
+		Timings map[string]float64 `json:"timings_ms"`
+	}
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &doc))
+	assert.Len(t, doc.Timings, 5)
+	assert.Contains(t, doc.Timings, domain.StageConfigLoad)
+	assert.Contains(t, doc.Timings, domain.StageGitOpen)
+	assert.Contains(t, doc.Timings, domain.StageStoreOpen)
+	assert.InDelta(t, 1.0, doc.Timings[domain.StageGitContext], 1e-9)
+	assert.InDelta(t, 1.5, doc.Timings[domain.StageStoreQuery], 1e-9)
+}
+
+// withoutTimings drops the run-dependent timings_ms field from a JSON result.
+func withoutTimings(t *testing.T, out string) string {
+	t.Helper()
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal([]byte(out), &doc))
+	delete(doc, "timings_ms")
+	data, err := json.Marshal(doc)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestRootCmd_Schema(t *testing.T) {
+	var stdout bytes.Buffer
+	deps := allTestDeps(&mockResolver{err: errors.New("resolver must not run")})
+	deps.Stdout = &stdout
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{"--schema"})
+
+	require.NoError(t, cmd.Execute())
+	var schema struct {
+		Defs map[string]struct {
+			Required   []string                   `json:"required"`
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"$defs"`
+	}
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &schema))
+
+	// Every field the documents can hold is described, and every required one is always written
+	result := schema.Defs["result"]
+	for _, field := range jsonFields(t, newResultJSON(&domain.ResolveOutput{Branch: "main", Component: "web",
+		Timings: []domain.StageTiming{{Stage: domain.StageGitOpen}}})) {
+		assert.Contains(t, result.Properties, field)
+	}
+	assert.Subset(t, jsonFields(t, newResultJSON(&domain.ResolveOutput{})), result.Required)
+	errorDoc := schema.Defs["error"]
+	errorFields := jsonFields(t, errorJSON{SchemaVersion: SchemaVersion})
+	assert.ElementsMatch(t, errorFields, errorDoc.Required)
+	for _, field := range errorFields {
+		assert.Contains(t, errorDoc.Properties, field)
+	}
+}
+
+// jsonFields returns the names of the fields v encodes to.
+func jsonFields(t *testing.T, v any) []string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(data, &doc))
+	return slices.Collect(maps.Keys(doc))
+}
+
+func TestRootCmd_InvalidFormat(t *testing.T) {
+	cmd := NewRootCmdWithDeps(allTestDeps(&mockResolver{}))
+	cmd.SetArgs([]string{"--format", "xml"})
+
+	err := cmd.Execute()
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported format")
+}
+
+// kvWriter is an extension output format writing key=value lines.
+type kvWriter struct{}
+
+func (kvWriter) WriteResult(w io.Writer, result *domain.ResolveOutput) error {
+	_, err := fmt.Fprintf(w, "correlation_id=%s\nrepository=%s\n", result.CorrelationID, result.Repository)
+	return err
+}
+
+func TestRootCmd_ExtensionFormat(t *testing.T) {
+	var stdout bytes.Buffer
+	deps := allTestDeps(&mockResolver{output: &domain.ResolveOutput{CorrelationID: "slip-1", Repository: "org/repo"}})
+	deps.Stdout = &stdout
+	deps.OutputFormats = map[string]domain.ResultWriter{"kv": kvWriter{}}
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{".", "--format", "kv"})
+
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, "correlation_id=slip-1\nrepository=org/repo\n", stdout.String())
+}
+
+func TestRootCmd_ExtensionFormat_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantMsg string
+	}{
+		{
+			name:    "unknown format lists extensions",
+			args:    []string{"--format", "xml"},
+			wantMsg: `unsupported format "xml": expected text, json, kv`,
+		},
+		{
+			name:    "with --components",
+			args:    []string{"--format", "kv", "--components", "web"},
+			wantMsg: "--format kv cannot be combined with --components",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := allTestDeps(&mockResolver{})
+			deps.OutputFormats = map[string]domain.ResultWriter{"kv": kvWriter{}}
+
+			cmd := NewRootCmdWithDeps(deps)
+			cmd.SetArgs(tt.args)
+			err := cmd.Execute()
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantMsg)
+			assert.Equal(t, domain.CodeInvalidInput, domain.CodeOf(err))
+		})
+	}
+}
+
+func TestRootCmd_InvalidRequireStep(t *testing.T) {
+	cmd := NewRootCmdWithDeps(allTestDeps(&mockResolver{}))
+	cmd.SetArgs([]string{"--require-step", "push_parsed=done"})
+
+	err := cmd.Execute()
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, domain.ErrInvalidStepRequirement)
+}
+
+func TestRootCmd_StepRequirementUnmet(t *testing.T) {
+	unmet := fmt.Errorf("%w: slip slip-1: push_parsed is running (want completed)", domain.ErrStepRequirementUnmet)
+	deps := allTestDeps(&mockResolver{err: unmet})
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{".", "--require-step", "push_parsed=completed"})
+
+	err := cmd.Execute()
+
+	require.Error(t, err)
+	assert.Equal(t, ExitCodeStepRequirementUnmet, ExitCode(err))
+	assert.Contains(t, err.Error(), "push_parsed is running (want completed)")
+}
+
+func TestExitCode(t *testing.T) {
+	assert.Equal(t, ExitCodeError, ExitCode(errors.New("boom")))
+	assert.Equal(t, ExitCodeError, ExitCode(domain.ErrNoAncestorSlip))
+	assert.Equal(t, ExitCodeStepRequirementUnmet, ExitCode(fmt.Errorf("wrapped: %w", domain.ErrStepRequirementUnmet)))
+}
+
+func TestLoadPullRequest(t *testing.T) {
+	detected := func() (*domain.PullRequest, error) {
+		return &domain.PullRequest{HeadSHA: "event-head", BaseRef: "main", BaseSHA: "event-base"}, nil
+	}
+	noEvent := func() (*domain.PullRequest, error) {
+		return nil, errors.New("GITHUB_EVENT_PATH is not set")
+	}
+
+	tests := []struct {
+		name    string
+		loader  func() (*domain.PullRequest, error)
+		head    string
+		base    string
+		want    *domain.PullRequest
+		wantErr string
+	}{
+		{
+			name:   "explicit head keeps detected base",
+			loader: detected,
+			head:   "abc123",
+			want:   &domain.PullRequest{HeadSHA: "abc123", BaseRef: "main", BaseSHA: "event-base"},
+		},
+		{
+			name:   "explicit base drops detected base SHA",
+			loader: detected,
+			base:   "develop",
+			want:   &domain.PullRequest{HeadSHA: "event-head", BaseRef: "develop"},
+		},
+		{
+			name:   "explicit head survives detection failure",
+			loader: noEvent,
+			head:   "abc123",
+			want:   &domain.PullRequest{HeadSHA: "abc123"},
+		},
+		{
+			name:    "detection failure without head",
+			loader:  noEvent,
+			wantErr: "pull request detection failed (pass --pr-head-sha): GITHUB_EVENT_PATH is not set",
+		},
+		{
+			name:    "loader not configured",
+			wantErr: "pull request detection not configured: pass --pr-head-sha",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prHeadSHA, prBase = tt.head, tt.base
+			t.Cleanup(func() { prHeadSHA, prBase = "", "" })
+
+			pr, err := loadPullRequest(&Dependencies{PullRequestLoader: tt.loader})
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantErr, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, pr)
+		})
+	}
+}
+
+func TestRootCmd_DenyListFromConfig(t *testing.T) {
+	resolver := &mockResolver{output: &domain.ResolveOutput{CorrelationID: "id"}}
+	deps := allTestDeps(resolver)
+	deps.ConfigLoader = func() (*AppConfig, error) {
+		return &AppConfig{Database: "ci", DenyList: []string{"rolled-back"}}, nil
+	}
+	deps.OutputWriterFactory = func() domain.OutputWriter { return &mockOutputWriter{} }
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{"."})
+
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, []string{"rolled-back"}, resolver.input.DenyList)
+}
+
+func TestRootCmd_AnnotateNotConfigured(t *testing.T) {
+	resolver := &mockResolver{output: &domain.ResolveOutput{CorrelationID: "id"}}
+	deps := allTestDeps(resolver)
+	deps.OutputWriterFactory = func() domain.OutputWriter { return &mockOutputWriter{} }
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{".", "--annotate"})
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Equal(t, "annotation not configured", err.Error())
+}
+
+func TestRootCmd_Hint(t *testing.T) {
+	tests := []struct {
+		name     string
+		hint     string
+		hintErr  error
+		wantHint string
+		wantErr  string
+	}{
+		{name: "hint passed to resolver", hint: "slip-override", wantHint: "slip-override"},
+		{name: "no hint", wantHint: ""},
+		{name: "loader error", hintErr: errors.New("permission denied"), wantErr: "permission denied"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := &mockResolver{output: &domain.ResolveOutput{CorrelationID: "id"}}
+			deps := allTestDeps(resolver)
+			deps.OutputWriterFactory = func() domain.OutputWriter { return &mockOutputWriter{} }
+			var loadedFrom string
+			deps.HintLoader = func(repoPath string) (string, error) {
+				loadedFrom = repoPath
+				return tt.hint, tt.hintErr
+			}
+
+			cmd := NewRootCmdWithDeps(deps)
+			cmd.SetArgs([]string{"/repo"})
+			err := cmd.Execute()
+
+			assert.Equal(t, "/repo", loadedFrom)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantHint, resolver.input.Hint)
+		})
+	}
+}
+
+func TestRootCmd_TraceSpan(t *testing.T) {
+	tests := []struct {
+		name       string
+		resolver   *mockResolver
+		wantStatus codes.Code
+		wantAttr   attribute.KeyValue
+	}{
+		{
+			name: "success",
+			resolver: &mockResolver{output: &domain.ResolveOutput{
+				CorrelationID: "slip-1",
+				Repository:    "org/repo",
+				ResolvedBy:    domain.ResolvedByAncestry,
+			}},
+			wantStatus: codes.Unset,
+			wantAttr:   attribute.String("slippy.correlation_id", "slip-1"),
+		},
+		{
+			name:       "failure",
+			resolver:   &mockResolver{err: domain.ErrNoAncestorSlip},
+			wantStatus: codes.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			previous := otel.GetTracerProvider()
+			otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+			t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+			deps := allTestDeps(tt.resolver)
+			deps.OutputWriterFactory = func() domain.OutputWriter { return &mockOutputWriter{} }
+			cmd := NewRootCmdWithDeps(deps)
+			cmd.SetArgs([]string{"."})
+			_ = cmd.Execute()
+
+			spans := recorder.Ended()
+			require.Len(t, spans, 1)
+			assert.Equal(t, "runResolve", spans[0].Name())
+			assert.Equal(t, tt.wantStatus, spans[0].Status().Code)
+			if tt.wantAttr.Valid() {
+				assert.Contains(t, spans[0].Attributes(), tt.wantAttr)
+			}
+		})
+	}
+}
+
+// mockMetricsExporter records metrics exports.
+type mockMetricsExporter struct {
+	textfile    string
+	pushURL     string
+	textfileErr error
+}
+
+func (m *mockMetricsExporter) WriteTextfile(path string) error {
+	m.textfile = path
+	return m.textfileErr
+}
+
+func (m *mockMetricsExporter) Push(_ context.Context, url string) error {
+	m.pushURL = url
+	return nil
+}
+
+func TestRootCmd_MetricsExport(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         []string
+		exporter     *mockMetricsExporter
+		resolveErr   error
+		wantTextfile string
+		wantPushURL  string
+		wantErr      string
+	}{
+		{
+			name:     "no export requested",
+			args:     []string{"."},
+			exporter: &mockMetricsExporter{},
+		},
+		{
+			name:         "textfile and pushgateway",
+			args:         []string{".", "--metrics-textfile", "/tmp/slippy.prom", "--metrics-pushgateway", "http://pgw:9091"},
+			exporter:     &mockMetricsExporter{},
+			wantTextfile: "/tmp/slippy.prom",
+			wantPushURL:  "http://pgw:9091",
+		},
+		{
+			name:         "exported after failed resolution",
+			args:         []string{".", "--metrics-textfile", "/tmp/slippy.prom"},
+			exporter:     &mockMetricsExporter{},
+			resolveErr:   domain.ErrNoAncestorSlip,
+			wantTextfile: "/tmp/slippy.prom",
+			wantErr:      "no slip found in commit ancestry",
+		},
+		{
+			name:         "export failure does not fail the run",
+			args:         []string{".", "--metrics-textfile", "/nonexistent/slippy.prom"},
+			exporter:     &mockMetricsExporter{textfileErr: errors.New("no such directory")},
+			wantTextfile: "/nonexistent/slippy.prom",
+		},
+		{
+			name:    "not configured",
+			args:    []string{".", "--metrics-pushgateway", "http://pgw:9091"},
+			wantErr: "metrics export not configured",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := &mockResolver{output: &domain.ResolveOutput{CorrelationID: "id"}, err: tt.resolveErr}
+			deps := allTestDeps(resolver)
+			deps.OutputWriterFactory = func() domain.OutputWriter { return &mockOutputWriter{} }
+			if tt.exporter != nil {
+				deps.MetricsExporter = tt.exporter
+			}
+
+			cmd := NewRootCmdWithDeps(deps)
+			cmd.SetArgs(tt.args)
+			err := cmd.Execute()
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			if tt.exporter != nil {
+				assert.Equal(t, tt.wantTextfile, tt.exporter.textfile)
+				assert.Equal(t, tt.wantPushURL, tt.exporter.pushURL)
+			}
+		})
+	}
+}
+
+func TestRootCmd_Components(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantOutput string
+		wantErr    string
+	}{
+		{
+			name:       "text",
+			args:       []string{".", "--components", "web,payments-api"},
+			wantOutput: "payments-api=api-slip\nweb=web-slip\n",
+		},
+		{
+			name:       "json",
+			args:       []string{".", "--components", "payments-api", "--components", "web", "--format", "json"},
+			wantOutput: `{"payments-api":"api-slip","web":"web-slip"}` + "\n",
+		},
+		{
+			name:    "exclusive with --component",
+			args:    []string{".", "--components", "web", "--component", "payments-api"},
+			wantErr: "--component and --components are mutually exclusive",
+		},
+		{
+			name:    "unknown component",
+			args:    []string{".", "--components", "web,billing"},
+			wantErr: `unknown component "billing" (defined: payments-api, web)`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := &mockResolver{componentOutputs: map[string]*domain.ResolveOutput{
+				"payments-api": {CorrelationID: "api-slip"},
+				"web":          {CorrelationID: "web-slip"},
+			}}
+			deps := allTestDeps(resolver)
+			deps.ComponentLoader = func(_ string) (map[string][]string, error) {
+				return map[string][]string{"payments-api": {"services/payments"}, "web": {"web"}}, nil
+			}
+			var stdout bytes.Buffer
+			deps.Stdout = &stdout
+
+			cmd := NewRootCmdWithDeps(deps)
+			cmd.SetArgs(tt.args)
+			err := cmd.Execute()
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantErr, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantOutput, stdout.String())
+			assert.Equal(t, map[string][]string{"payments-api": {"services/payments"}, "web": {"web"}}, resolver.components)
+			assert.Empty(t, resolver.input.Component)
+		})
+	}
+}
+
+func TestRootCmd_ComponentsResolveError(t *testing.T) {
+	resolver := &mockResolver{err: fmt.Errorf("component web: %w", domain.ErrStepRequirementUnmet)}
+	deps := allTestDeps(resolver)
+	deps.ComponentLoader = func(_ string) (map[string][]string, error) {
+		return map[string][]string{"web": {"web"}}, nil
+	}
+
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs([]string{".", "--components", "web"})
+	err := cmd.Execute()
+
+	require.Error(t, err)
+	assert.Equal(t, ExitCodeStepRequirementUnmet, ExitCode(err))
+}
+
+// resolvingGitRepo is a mockGitRepo that expands revisions with commits,
+// failing with err when set.
+type resolvingGitRepo struct {
+	mockGitRepo
+	commits map[string]string
+	err     error
+}
+
+func (m *resolvingGitRepo) ResolveCommit(_ context.Context, rev string) (string, error) {
+	if m.err != nil {
+		return "", m.err
+	}
+	return m.commits[rev], nil
+}
+
+func TestRootCmd_PullRequestHeadExpanded(t *testing.T) {
+	full := strings.Repeat("ab", 20)
+	other := strings.Repeat("cd", 20)
+	tests := []struct {
+		name     string
+		head     string
+		repoErr  error
+		wantHead string
+		wantErr  error
+		wantCode domain.ErrorCode
+	}{
+		{name: "abbreviated", head: "abab12", wantHead: full},
+		{name: "full", head: other, wantHead: other},
+		{name: "missing locally", head: "abab12", repoErr: domain.ErrCommitNotFound, wantHead: "abab12"},
+		{name: "full missing locally", head: other, repoErr: domain.ErrCommitNotFound, wantHead: other},
+		{
+			name:     "ambiguous",
+			head:     "abab",
+			repoErr:  domain.ErrAmbiguousCommit,
+			wantErr:  domain.ErrAmbiguousCommit,
+			wantCode: domain.CodeAmbiguousCommit,
+		},
+		{
+			name:     "object format mismatch",
+			head:     strings.Repeat("ab", 32),
+			repoErr:  domain.ErrObjectFormatMismatch,
+			wantErr:  domain.ErrObjectFormatMismatch,
+			wantCode: domain.CodeInvalidInput,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := &mockResolver{output: &domain.ResolveOutput{CorrelationID: "slip-1"}}
+			deps := allTestDeps(resolver)
+			deps.GitRepoFactory = func(string, Logger) (domain.LocalGitRepository, error) {
+				return &resolvingGitRepo{commits: map[string]string{"abab12": full, other: other}, err: tt.repoErr}, nil
+			}
+			deps.OutputWriterFactory = func() domain.OutputWriter { return &mockOutputWriter{} }
+
+			cmd := NewRootCmdWithDeps(deps)
+			cmd.SetArgs([]string{".", "--pr-head-sha", tt.head, "--pr-base", "main"})
+			err := cmd.Execute()
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				assert.Equal(t, tt.wantCode, domain.CodeOf(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantHead, resolver.input.PullRequest.HeadSHA)
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	_ "embed"
+	"io"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// SchemaVersion is the version of the --format json documents, written as
+// their schema_version field. It is shared with serve mode's responses.
+const SchemaVersion = domain.SchemaVersion
+
+// outputSchema is the JSON Schema of the --format json result and error
+// documents, printed by --schema.
+//
+//go:embed schema.json
+var outputSchema []byte
+
+// writeSchema writes the output schema to w.
+func writeSchema(w io.Writer) error {
+	_, err := w.Write(outputSchema)
+	return err
+}
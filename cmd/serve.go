@@ -0,0 +1,746 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// Serve command flags.
+var (
+	serveListen    string
+	serveSocket    string
+	serveTLSCert   string
+	serveTLSKey    string
+	serveClientCA  string
+	serveTokenFile string
+	serveRateLimit float64
+	serveRateBurst int
+	serveRepoRoots []string
+	serveDepth     int
+	serveCacheTTL  time.Duration
+	serveWebhook   string
+	serveShutdown  time.Duration
+	serveProbe     string
+	serveK8s       bool
+	serveSecrets   string
+	serveTenants   string
+	serveAdmin     string
+	serveVerbose   bool
+)
+
+// Defaults applied by serve --kubernetes.
+const (
+	// kubernetesSecretsDir is where the preset reads mounted secrets from.
+	kubernetesSecretsDir = "/etc/slippy-find/secrets"
+
+	// kubernetesProbeAddr serves the health endpoints to the kubelet, which
+	// probes the pod IP rather than localhost.
+	kubernetesProbeAddr = ":8081"
+)
+
+// ServeOptions configures the serve-mode server built by Dependencies.ServerFactory.
+type ServeOptions struct {
+	// Addr is the TCP address to listen on; empty when serving on SocketPath only.
+	Addr string
+
+	// SocketPath is a unix socket to serve on for clients on the same host.
+	SocketPath string
+
+	// TLSCertFile and TLSKeyFile enable HTTPS when set.
+	TLSCertFile string
+	TLSKeyFile  string
+
+	// ClientCAFile enables mutual TLS, requiring client certificates signed by these CAs.
+	ClientCAFile string
+
+	// TokenFile lists the accepted bearer tokens, one "<client> <token>" per line.
+	TokenFile string
+
+	// RateLimit is the sustained requests per second allowed for each client (0 disables).
+	RateLimit float64
+
+	// RateBurst is the number of requests a client may make at once.
+	RateBurst int
+
+	// WebhookSecretFile holds the secret GitHub push webhooks are signed with.
+	// When set, pushes are pre-resolved into the result cache.
+	WebhookSecretFile string
+
+	// Ping checks the connection to the shared slip store, for readiness
+	// probes. It returns domain.ErrPingUnsupported when the store cannot be checked.
+	Ping func(ctx context.Context) error
+
+	// ShutdownTimeout bounds how long requests in flight are drained on shutdown.
+	ShutdownTimeout time.Duration
+
+	// ProbeAddr serves only the health endpoints, without TLS or credentials.
+	ProbeAddr string
+
+	// AdminTokenFile lists the bearer tokens of operators allowed to use the
+	// admin endpoints, in the same format as TokenFile.
+	AdminTokenFile string
+
+	// Admin backs the admin endpoints; set only with AdminTokenFile.
+	Admin *AdminHooks
+}
+
+// AdminHooks lets the serve-mode admin endpoints inspect and reset the server.
+type AdminHooks struct {
+	// CacheStats reports the result cache of each store, by store name:
+	// DefaultStoreName or a tenant.
+	CacheStats func() map[string]CacheStats
+
+	// FlushCache empties every result cache and returns the entries dropped.
+	FlushCache func() int
+
+	// Settings returns the effective configuration, with secret values redacted.
+	Settings func() []ConfigSetting
+}
+
+// CacheStats describes a serve-mode result cache.
+type CacheStats struct {
+	// Enabled is false when the store has no cache (no --cache-ttl).
+	Enabled bool
+
+	// Entries is the number of results held, including expired ones not yet dropped.
+	Entries int
+
+	// Hits and Misses count lookups since the server started.
+	Hits   uint64
+	Misses uint64
+
+	// TTL is how long results are kept.
+	TTL time.Duration
+}
+
+// DefaultStoreName names the default slip store in admin cache statistics.
+const DefaultStoreName = "default"
+
+// Server serves slip resolution requests until its context is done.
+type Server interface {
+	Serve(ctx context.Context) error
+}
+
+// ResolveRequestFunc resolves one serve-mode or worker request.
+type ResolveRequestFunc func(ctx context.Context, req domain.ResolveRequest) (*domain.ResolveOutput, error)
+
+// RemoteResolver resolves slips through a running serve-mode server.
+type RemoteResolver interface {
+	Resolve(ctx context.Context, req domain.ResolveRequest) (*domain.ResolveOutput, error)
+}
+
+// newServeCmd creates "serve", which runs slippy-find as a long-lived
+// resolution service.
+func newServeCmd(deps *Dependencies) *cobra.Command {
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve slip resolution over HTTP",
+		Long: `Run slippy-find as a long-lived service that resolves slips over HTTP.
+
+The slip store connection is opened once and shared by every request.
+Requests are made with POST /v1/resolve and name either a repository checked
+out under one of the --repo-root directories:
+
+  {"path": "/workspace/payments-api"}
+
+or a repository and its commit ancestry, newest first, for callers without a
+checkout:
+
+  {"repository": "org/payments-api", "branch": "main",
+   "commits": ["9f2c1e7a4b3d5f6081a2b3c4d5e6f708192a3b4c",
+               "4e8d2a6c0b1f3e5d7c9a8b6f4d2e0c1a3b5d7f9e"]}
+
+Commits must be full 40- or 64-character SHAs; abbreviated ones are rejected.
+Both forms accept "depth" and "selection_policy". The response has the same
+fields as --format json.
+
+Clients authenticate with a bearer token from --token-file, or with a client
+certificate when --client-ca enables mutual TLS. Each client (token, certificate
+common name, or, without either, IP address) is rate limited separately.
+
+With --unix, the server also listens on a unix socket for build agents on the
+same host (only on the socket, unless --listen is given too). Socket requests
+need no credentials and are not rate limited; the socket is accessible to the
+server's user and group. Agents resolve through it with --via-socket.
+
+On SIGTERM or interrupt, the server stops accepting connections, waits up to
+--shutdown-timeout for requests in flight, cancels any still running, and
+closes the slip store connection. Keep the timeout below the pod's
+terminationGracePeriodSeconds.
+
+GET /livez and GET /readyz serve liveness and readiness probes without
+credentials. Readiness pings the slip store and reports when it was last
+reached, by a probe or a resolution. --probe-listen serves them alone on
+another address.
+
+--kubernetes runs the server as a resolution sidecar: it listens on localhost
+only, serves probes on ` + kubernetesProbeAddr + ` (unless --probe-listen is given), logs JSON,
+and loads configuration from the secret volume mounted at --secrets-dir
+(default ` + kubernetesSecretsDir + `), one file per environment variable.
+
+Identical requests (same repository, head commit, depth, and policy) arriving
+while one is being resolved share its result instead of querying the store
+again. With --cache-ttl, successful results are also kept for that long.
+Adding --webhook-secret-file enables
+POST /v1/webhooks/github: point a GitHub push webhook (content type
+application/json, same secret) at it and each pushed commit is resolved as it
+arrives, so the CI jobs that follow are answered from the cache. The webhook
+is authenticated by its signature alone, so it cannot be used with --client-ca.
+
+With --admin-token-file, operators can inspect and reset the server without
+restarting it, authenticating with a token from that file (client tokens and
+certificates are not accepted):
+
+  GET  /admin/v1/cache        result cache statistics per store
+  POST /admin/v1/cache/flush  empty every result cache
+  GET  /admin/v1/config       effective configuration, secrets redacted
+
+With --tenants-file, one server hosts several slip stores (databases or
+clusters), one per tenant. A request picks its tenant with the
+X-Slippy-Tenant header or by being sent to /t/<tenant>/v1/resolve; requests
+naming no tenant use the default store. Pushes for a tenant are pre-resolved
+at /t/<tenant>/v1/webhooks/github, into its own cache. Each tenant overrides the default
+store's connection settings, and reads its password from an environment
+variable or file:
+
+  tenants:
+    payments:
+      hostname: ch-payments.internal
+      password_env: PAYMENTS_CLICKHOUSE_PASSWORD
+      database: ci
+    logistics:
+      database: logistics_ci
+
+Examples:
+  slippy-find serve --repo-root /workspace
+  slippy-find serve --kubernetes --repo-root /workspace
+  slippy-find serve --repo-root /workspace --tenants-file tenants.yaml
+  slippy-find serve --unix /var/run/slippy-find.sock --repo-root /builds
+  slippy-find serve --listen :8443 --tls-cert tls.crt --tls-key tls.key --token-file tokens
+  slippy-find serve --listen :8443 --tls-cert tls.crt --tls-key tls.key --client-ca ca.crt
+  slippy-find serve --listen :8443 --tls-cert tls.crt --tls-key tls.key --token-file tokens \
+    --cache-ttl 10m --webhook-secret-file webhook-secret`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runServe(cmd, deps)
+		},
+	}
+
+	serveCmd.Flags().StringVar(&serveListen, "listen", "127.0.0.1:8080",
+		"Address to listen on")
+	serveCmd.Flags().StringVar(&serveSocket, "unix", "",
+		"Also serve on this unix socket, for agents on the same host (replaces --listen unless it is given)")
+	serveCmd.Flags().StringVar(&serveTLSCert, "tls-cert", "",
+		"PEM certificate for HTTPS (requires --tls-key)")
+	serveCmd.Flags().StringVar(&serveTLSKey, "tls-key", "",
+		"PEM private key for HTTPS (requires --tls-cert)")
+	serveCmd.Flags().StringVar(&serveClientCA, "client-ca", "",
+		"PEM CA bundle; clients must present a certificate it signed (mutual TLS)")
+	serveCmd.Flags().StringVar(&serveTokenFile, "token-file", "",
+		"File of accepted bearer tokens, one \"<client> <token>\" per line")
+	serveCmd.Flags().Float64Var(&serveRateLimit, "rate-limit", 10,
+		"Sustained requests per second allowed for each client (0 disables)")
+	serveCmd.Flags().IntVar(&serveRateBurst, "rate-burst", 20,
+		"Requests a client may make at once before --rate-limit applies")
+	serveCmd.Flags().StringArrayVar(&serveRepoRoots, "repo-root", nil,
+		"Directory whose repositories may be resolved by path (repeatable; path requests are refused without one)")
+	serveCmd.Flags().IntVarP(&serveDepth, "depth", "d", domain.DefaultAncestryDepth,
+		"Ancestry depth for requests that do not set one")
+	serveCmd.Flags().DurationVar(&serveCacheTTL, "cache-ttl", 0,
+		"How long successful results are cached (0 disables caching)")
+	serveCmd.Flags().StringVar(&serveWebhook, "webhook-secret-file", "",
+		"File holding the GitHub webhook secret; enables pre-resolving pushes (requires --cache-ttl)")
+	serveCmd.Flags().DurationVar(&serveShutdown, "shutdown-timeout", 25*time.Second,
+		"How long to drain requests in flight on shutdown before cancelling them")
+	serveCmd.Flags().StringVar(&serveProbe, "probe-listen", "",
+		"Also serve only /livez and /readyz on this address")
+	serveCmd.Flags().BoolVar(&serveK8s, "kubernetes", false,
+		"Sidecar preset: localhost only, probes on "+kubernetesProbeAddr+", JSON logs, config from --secrets-dir")
+	serveCmd.Flags().StringVar(&serveSecrets, "secrets-dir", "",
+		"Directory of mounted secrets, one file per environment variable (default "+kubernetesSecretsDir+
+			" with --kubernetes)")
+	serveCmd.Flags().StringVar(&serveTenants, "tenants-file", "",
+		"YAML file of additional slip stores, selected per request by tenant name")
+	serveCmd.Flags().StringVar(&serveAdmin, "admin-token-file", "",
+		"File of operator tokens enabling the /admin/v1/ endpoints, one \"<operator> <token>\" per line")
+	serveCmd.Flags().BoolVarP(&serveVerbose, "verbose", "v", false,
+		"Enable verbose/debug logging")
+
+	return serveCmd
+}
+
+// runServe opens the slip store and serves requests until the command's context is done.
+func runServe(cmd *cobra.Command, deps *Dependencies) error {
+	if deps == nil || deps.ServerFactory == nil {
+		return configError(errors.New("serve mode not configured"))
+	}
+	if serveDepth < 1 {
+		return usageErrorf("--depth must be at least 1")
+	}
+	if serveRateLimit < 0 {
+		return usageErrorf("--rate-limit cannot be negative")
+	}
+	if serveShutdown <= 0 {
+		return usageErrorf("--shutdown-timeout must be positive")
+	}
+	if serveCacheTTL < 0 {
+		return usageErrorf("--cache-ttl cannot be negative")
+	}
+	if serveWebhook != "" && serveCacheTTL == 0 {
+		return usageErrorf("--webhook-secret-file requires --cache-ttl, or pre-resolved results would be discarded")
+	}
+	if serveWebhook != "" && serveClientCA != "" {
+		return usageErrorf("--webhook-secret-file cannot be combined with --client-ca: GitHub has no client certificate")
+	}
+
+	roots, err := serveRoots(serveRepoRoots)
+	if err != nil {
+		return err
+	}
+
+	probeAddr, secretsDir := serveProbe, serveSecrets
+	if serveK8s {
+		if err := applyKubernetesPreset(cmd); err != nil {
+			return err
+		}
+		if probeAddr == "" {
+			probeAddr = kubernetesProbeAddr
+		}
+		if secretsDir == "" {
+			secretsDir = kubernetesSecretsDir
+		}
+	}
+	// Before the logger and configuration are created, since both read the environment
+	var secretKeys []string
+	if secretsDir != "" {
+		if deps.SecretsLoader == nil {
+			return configError(errors.New("mounted secrets not supported"))
+		}
+		secretKeys, err = deps.SecretsLoader(secretsDir)
+		if err != nil {
+			return configError(fmt.Errorf("failed to load mounted secrets: %w", err))
+		}
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if serveVerbose {
+		enableDebugLogging(deps)
+	}
+	log := deps.LoggerFactory()
+	if secretsDir != "" {
+		log.Info(ctx, "loaded mounted secrets", map[string]interface{}{
+			"dir":  secretsDir,
+			"keys": secretKeys,
+		})
+	}
+
+	resolver, closeResolver, err := openRequestResolver(ctx, deps, log, roots, serveDepth)
+	if err != nil {
+		return err
+	}
+	defer closeResolver()
+	if serveCacheTTL > 0 {
+		resolver.cache = newResultCache(serveCacheTTL)
+	}
+	tenants, closeTenants, err := openTenants(ctx, deps, log, resolver, serveTenants)
+	if err != nil {
+		return err
+	}
+	defer closeTenants()
+
+	opts := ServeOptions{
+		Addr:              serveListen,
+		SocketPath:        serveSocket,
+		TLSCertFile:       serveTLSCert,
+		TLSKeyFile:        serveTLSKey,
+		ClientCAFile:      serveClientCA,
+		TokenFile:         serveTokenFile,
+		RateLimit:         serveRateLimit,
+		RateBurst:         serveRateBurst,
+		WebhookSecretFile: serveWebhook,
+		Ping:              tenants.ping,
+		ShutdownTimeout:   serveShutdown,
+		ProbeAddr:         probeAddr,
+		AdminTokenFile:    serveAdmin,
+	}
+	if serveAdmin != "" {
+		opts.Admin = &AdminHooks{
+			CacheStats: tenants.cacheStats,
+			FlushCache: tenants.flushCache,
+			Settings:   tenants.settings,
+		}
+	}
+	if opts.SocketPath != "" && !cmd.Flags().Changed("listen") {
+		opts.Addr = ""
+	}
+	if opts.Addr != "" && opts.TokenFile == "" && opts.ClientCAFile == "" && !isLoopback(opts.Addr) {
+		log.Warn(ctx, "serving without authentication on a non-loopback address", map[string]interface{}{
+			"addr": opts.Addr,
+		})
+	}
+
+	srv, err := deps.ServerFactory(opts, tenants.resolve, log)
+	if err != nil {
+		return configError(fmt.Errorf("serve configuration error: %w", err))
+	}
+	return srv.Serve(ctx)
+}
+
+// applyKubernetesPreset checks that serve --kubernetes listens on localhost
+// only and switches logging to JSON.
+func applyKubernetesPreset(cmd *cobra.Command) error {
+	if cmd.Flags().Changed("listen") && !isLoopback(serveListen) {
+		return usageErrorf("--kubernetes listens on localhost only; %s is not a loopback address", serveListen)
+	}
+	if err := os.Setenv("LOG_FORMAT", "json"); err != nil {
+		return configError(fmt.Errorf("failed to set log format: %w", err))
+	}
+	return nil
+}
+
+// serveRoots resolves each --repo-root to an absolute path with symlinks
+// evaluated, so request paths can be compared against it.
+func serveRoots(raw []string) ([]string, error) {
+	roots := make([]string, 0, len(raw))
+	for _, root := range raw {
+		abs, err := filepath.Abs(root)
+		if err != nil {
+			return nil, usageErrorf("invalid --repo-root %q: %v", root, err)
+		}
+		resolved, err := filepath.EvalSymlinks(abs)
+		if err != nil {
+			return nil, usageErrorf("invalid --repo-root %q: %v", root, err)
+		}
+		roots = append(roots, resolved)
+	}
+	return roots, nil
+}
+
+// isLoopback reports whether addr listens only on a loopback interface.
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// enableDebugLogging lowers the log level to debug through
+// deps.DebugLogging, for the -v flag of each command.
+func enableDebugLogging(deps *Dependencies) {
+	if deps.DebugLogging != nil {
+		deps.DebugLogging()
+	}
+}
+
+// openRequestResolver loads configuration and opens the slip finder that
+// every request shares. The caller must call the returned close function.
+func openRequestResolver(
+	ctx context.Context,
+	deps *Dependencies,
+	log Logger,
+	roots []string,
+	depth int,
+) (*requestResolver, func(), error) {
+	cfg, err := deps.ConfigLoader()
+	if err != nil {
+		err = configError(fmt.Errorf("configuration error: %w", err))
+		log.Error(ctx, "failed to load configuration", err, nil)
+		return nil, nil, err
+	}
+
+	finder, closeFinder, err := openSlipFinder(ctx, deps, log, cfg, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if deps.StoreReloader != nil {
+		reloadCtx, stopReload := context.WithCancel(ctx)
+		finder = deps.StoreReloader(reloadCtx, cfg, finder, log)
+		closeFinder = func() {
+			stopReload()
+			closeSlipFinder(ctx, log, finder, nil)
+		}
+	}
+
+	resolver := &requestResolver{
+		deps:     deps,
+		log:      log,
+		cfg:      cfg,
+		finder:   finder,
+		roots:    roots,
+		depth:    depth,
+		inflight: new(singleflight.Group),
+	}
+	return resolver, closeFinder, nil
+}
+
+// openSlipFinder opens the slip finder for cfg, logging with fields. The
+// caller must call the returned close function.
+func openSlipFinder(
+	ctx context.Context,
+	deps *Dependencies,
+	log Logger,
+	cfg *AppConfig,
+	fields map[string]interface{},
+) (domain.SlipFinder, func(), error) {
+	finder, err := deps.SlipFinderFactory(cfg, log)
+	if err != nil {
+		err = domain.WithCode(domain.CodeStore, fmt.Errorf("database error: %w", err))
+		log.Error(ctx, "failed to initialize slip finder", err, fields)
+		return nil, nil, err
+	}
+	return finder, func() { closeSlipFinder(ctx, log, finder, fields) }, nil
+}
+
+// closeSlipFinder closes finder, logging with fields.
+func closeSlipFinder(ctx context.Context, log Logger, finder domain.SlipFinder, fields map[string]interface{}) {
+	if closeErr := finder.Close(); closeErr != nil {
+		warnFields := map[string]interface{}{"error": closeErr.Error()}
+		maps.Copy(warnFields, fields)
+		log.Warn(ctx, "failed to close slip finder", warnFields)
+		return
+	}
+	log.Debug(ctx, "closed slip store connection", fields)
+}
+
+// requestResolver resolves serve-mode and worker requests against the shared
+// slip finder.
+type requestResolver struct {
+	deps   *Dependencies
+	log    Logger
+	cfg    *AppConfig
+	finder domain.SlipFinder
+	roots  []string
+
+	// depth is the ancestry depth for requests that do not set one.
+	depth int
+
+	// cache holds recent results; nil disables caching.
+	cache *resultCache
+
+	// inflight coalesces identical requests being resolved at once.
+	inflight *singleflight.Group
+}
+
+// resolve opens the request's repository and resolves its slip with the
+// default search settings, overridden by the request's depth and policy.
+func (r *requestResolver) resolve(ctx context.Context, req domain.ResolveRequest) (*domain.ResolveOutput, error) {
+	gitRepo, err := r.openRepository(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := gitRepo.Close(); closeErr != nil {
+			r.log.Warn(ctx, "failed to close git repository", map[string]interface{}{
+				"error": closeErr.Error(),
+			})
+		}
+	}()
+
+	input := domain.ResolveInput{
+		Depth:               req.Depth,
+		SelectionPolicy:     req.SelectionPolicy,
+		DenyList:            r.cfg.DenyList,
+		CorrelationIDFormat: r.cfg.CorrelationIDFormat,
+		BranchAffinity:      true,
+		SlowThresholds: domain.SlowThresholds{
+			GitWalk:    domain.DefaultSlowGitWalk,
+			StoreQuery: domain.DefaultSlowStoreQuery,
+		},
+		StageTimeouts: domain.StageTimeouts{Git: r.cfg.GitTimeout, Store: r.cfg.StoreTimeout},
+	}
+	if input.Depth == 0 {
+		input.Depth = r.depth
+	}
+	if input.SelectionPolicy == "" {
+		input.SelectionPolicy = domain.DefaultSelectionPolicy
+	}
+
+	key, keyed := r.requestKey(ctx, gitRepo, input)
+	if !keyed {
+		return r.resolveRepository(ctx, gitRepo, input)
+	}
+	if req.Path == "" && !req.Verified {
+		key.ancestry = ancestryHash(req.Branch, req.Commits)
+	}
+	if r.cache != nil {
+		// A result from trusted history answers any request for its head
+		if result, ok := r.cache.get(key, key.trusted()); ok {
+			r.log.Debug(ctx, "resolution served from cache", map[string]interface{}{
+				"repository": key.repository,
+				"commit":     key.head,
+			})
+			return result, nil
+		}
+	}
+	return r.coalesce(ctx, key, func(ctx context.Context) (*domain.ResolveOutput, error) {
+		result, err := r.resolveRepository(ctx, gitRepo, input)
+		if err == nil && r.cache != nil {
+			r.cache.put(key, result)
+		}
+		return result, err
+	})
+}
+
+// resolveRepository resolves the slip of gitRepo against the store.
+func (r *requestResolver) resolveRepository(
+	ctx context.Context,
+	gitRepo domain.LocalGitRepository,
+	input domain.ResolveInput,
+) (*domain.ResolveOutput, error) {
+	result, err := r.deps.ResolverFactory(gitRepo, r.finder, r.log).Resolve(ctx, input)
+	if errors.Is(err, domain.ErrNoAncestorSlip) {
+		return nil, domain.WithCode(domain.CodeNoSlip, errors.New("no slip found in commit ancestry"))
+	}
+	return result, err
+}
+
+// coalesce runs resolve once for concurrent requests with the same key, as
+// fan-out CI jobs for one commit arrive together, and gives each caller its
+// own copy of the result. The shared run has the context of the request that
+// started it: if that request is cancelled, the others retry on their own.
+func (r *requestResolver) coalesce(
+	ctx context.Context,
+	key cacheKey,
+	resolve func(ctx context.Context) (*domain.ResolveOutput, error),
+) (*domain.ResolveOutput, error) {
+	done := r.inflight.DoChan(key.String(), func() (any, error) {
+		return resolve(ctx)
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case shared := <-done:
+		if shared.Err != nil {
+			if ctx.Err() == nil && (errors.Is(shared.Err, context.Canceled) ||
+				errors.Is(shared.Err, context.DeadlineExceeded)) {
+				return resolve(ctx)
+			}
+			return nil, shared.Err
+		}
+		result, ok := shared.Val.(*domain.ResolveOutput)
+		if !ok || result == nil {
+			return nil, errors.New("resolver returned no result")
+		}
+		if shared.Shared {
+			r.log.Debug(ctx, "resolution shared with an identical request", map[string]interface{}{
+				"repository": key.repository,
+				"commit":     key.head,
+			})
+		}
+		copied := *result
+		return &copied, nil
+	}
+}
+
+// requestKey returns the key identifying the resolution of gitRepo with
+// input, and false when the repository's head cannot be read.
+func (r *requestResolver) requestKey(
+	ctx context.Context,
+	gitRepo domain.LocalGitRepository,
+	input domain.ResolveInput,
+) (cacheKey, bool) {
+	gitCtx, err := gitRepo.GetGitContext(ctx)
+	if err != nil || gitCtx == nil {
+		// Left for the resolver to report.
+		return cacheKey{}, false
+	}
+	return cacheKey{
+		repository: gitCtx.Repository,
+		head:       gitCtx.HeadSHA,
+		depth:      input.Depth,
+		policy:     input.SelectionPolicy,
+	}, true
+}
+
+// cacheStats reports the resolver's result cache.
+func (r *requestResolver) cacheStats() CacheStats {
+	if r.cache == nil {
+		return CacheStats{}
+	}
+	return r.cache.stats()
+}
+
+// flushCache empties the resolver's result cache and returns the entries dropped.
+func (r *requestResolver) flushCache() int {
+	if r.cache == nil {
+		return 0
+	}
+	return r.cache.flush()
+}
+
+// ping checks the connection to the shared slip store.
+func (r *requestResolver) ping(ctx context.Context) error {
+	pinger, ok := r.finder.(domain.StorePinger)
+	if !ok {
+		return domain.ErrPingUnsupported
+	}
+	return pinger.Ping(ctx)
+}
+
+// openRepository opens the checkout named by req.Path, which must lie under
+// a --repo-root, or the history given by req.Repository and req.Commits.
+func (r *requestResolver) openRepository(req domain.ResolveRequest) (domain.LocalGitRepository, error) {
+	if req.Path == "" {
+		if r.deps.AncestryRepoFactory == nil {
+			return nil, configError(errors.New("commit-list requests not configured"))
+		}
+		return r.deps.AncestryRepoFactory(req.Repository, req.Branch, req.Commits), nil
+	}
+
+	path, err := r.allowedPath(req.Path)
+	if err != nil {
+		return nil, err
+	}
+	gitRepo, err := r.deps.GitRepoFactory(path, r.log)
+	if err != nil {
+		if errors.Is(err, domain.ErrRepositoryNotFound) {
+			return nil, domain.WithCode(domain.CodeNotARepository, fmt.Errorf("not a git repository: %s", req.Path))
+		}
+		return nil, domain.WithCode(domain.CodeGit, err)
+	}
+	return gitRepo, nil
+}
+
+// allowedPath resolves path and checks that it lies under one of the roots.
+func (r *requestResolver) allowedPath(path string) (string, error) {
+	if len(r.roots) == 0 {
+		return "", domain.WithCode(domain.CodeInvalidInput,
+			errors.New("path requests are disabled: the server has no --repo-root"))
+	}
+	if !filepath.IsAbs(path) {
+		return "", domain.WithCode(domain.CodeInvalidInput, fmt.Errorf("path must be absolute: %s", path))
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", domain.WithCode(domain.CodeNotARepository, fmt.Errorf("not a git repository: %s", path))
+	}
+	for _, root := range r.roots {
+		rel, err := filepath.Rel(root, resolved)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return resolved, nil
+		}
+	}
+	return "", domain.WithCode(domain.CodeInvalidInput, fmt.Errorf("path is outside the served roots: %s", path))
+}
@@ -0,0 +1,785 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// mockServer resolves each of its requests once when served, recording the results.
+type mockServer struct {
+	requests []domain.ResolveRequest
+	serveErr error
+
+	opts    ServeOptions
+	ping    func(ctx context.Context) error
+	admin   *AdminHooks
+	results []*domain.ResolveOutput
+	errs    []error
+}
+
+func (m *mockServer) Serve(_ context.Context) error {
+	return m.serveErr
+}
+
+// serveTestDeps returns dependencies whose ServerFactory resolves srv.requests.
+func serveTestDeps(srv *mockServer, resolver *mockResolver) *Dependencies {
+	deps := allTestDeps(resolver)
+	deps.ServerFactory = func(opts ServeOptions, resolve ResolveRequestFunc, _ Logger) (Server, error) {
+		// Funcs cannot be compared, so Ping and Admin are kept apart from the options.
+		srv.ping, opts.Ping = opts.Ping, nil
+		srv.admin, opts.Admin = opts.Admin, nil
+		srv.opts = opts
+		for _, req := range srv.requests {
+			result, err := resolve(context.Background(), req)
+			srv.results = append(srv.results, result)
+			srv.errs = append(srv.errs, err)
+		}
+		return srv, nil
+	}
+	deps.AncestryRepoFactory = func(repository, branch string, commits []string) domain.LocalGitRepository {
+		return &mockGitRepo{
+			gitContext: &domain.GitContext{Repository: repository, Branch: branch, HeadSHA: commits[0]},
+			commits:    commits,
+		}
+	}
+	return deps
+}
+
+func runServeCmd(deps *Dependencies, args ...string) error {
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs(append([]string{"serve"}, args...))
+	return cmd.Execute()
+}
+
+func TestServeCmd_Options(t *testing.T) {
+	srv := &mockServer{}
+
+	err := runServeCmd(serveTestDeps(srv, &mockResolver{}),
+		"--listen", ":8443", "--tls-cert", "tls.crt", "--tls-key", "tls.key", "--client-ca", "ca.crt",
+		"--token-file", "tokens", "--rate-limit", "2.5", "--rate-burst", "5", "--shutdown-timeout", "10s")
+
+	require.NoError(t, err)
+	assert.Equal(t, ServeOptions{
+		Addr:            ":8443",
+		TLSCertFile:     "tls.crt",
+		TLSKeyFile:      "tls.key",
+		ClientCAFile:    "ca.crt",
+		TokenFile:       "tokens",
+		RateLimit:       2.5,
+		RateBurst:       5,
+		ShutdownTimeout: 10 * time.Second,
+	}, srv.opts)
+}
+
+func TestServeCmd_Defaults(t *testing.T) {
+	srv := &mockServer{}
+
+	require.NoError(t, runServeCmd(serveTestDeps(srv, &mockResolver{})))
+
+	assert.Equal(t, ServeOptions{
+		Addr:            "127.0.0.1:8080",
+		RateLimit:       10,
+		RateBurst:       20,
+		ShutdownTimeout: 25 * time.Second,
+	}, srv.opts)
+}
+
+func TestServeCmd_Socket(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantAddr string
+	}{
+		{name: "socket only", args: []string{"--unix", "/run/slippy.sock"}},
+		{
+			name:     "socket and TCP",
+			args:     []string{"--unix", "/run/slippy.sock", "--listen", "127.0.0.1:9090"},
+			wantAddr: "127.0.0.1:9090",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &mockServer{}
+
+			require.NoError(t, runServeCmd(serveTestDeps(srv, &mockResolver{}), tt.args...))
+
+			assert.Equal(t, "/run/slippy.sock", srv.opts.SocketPath)
+			assert.Equal(t, tt.wantAddr, srv.opts.Addr)
+		})
+	}
+}
+
+func TestServeCmd_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		modify  func(deps *Dependencies)
+		wantMsg string
+	}{
+		{
+			name:    "not configured",
+			modify:  func(deps *Dependencies) { deps.ServerFactory = nil },
+			wantMsg: "serve mode not configured",
+		},
+		{name: "zero depth", args: []string{"--depth", "0"}, wantMsg: "--depth must be at least 1"},
+		{name: "negative rate limit", args: []string{"--rate-limit", "-1"}, wantMsg: "--rate-limit cannot be negative"},
+		{
+			name:    "zero shutdown timeout",
+			args:    []string{"--shutdown-timeout", "0s"},
+			wantMsg: "--shutdown-timeout must be positive",
+		},
+		{name: "negative cache ttl", args: []string{"--cache-ttl", "-1s"}, wantMsg: "--cache-ttl cannot be negative"},
+		{
+			name:    "webhook without cache",
+			args:    []string{"--webhook-secret-file", "webhook-secret"},
+			wantMsg: "--webhook-secret-file requires --cache-ttl",
+		},
+		{
+			name: "webhook with mutual TLS",
+			args: []string{
+				"--webhook-secret-file", "webhook-secret", "--cache-ttl", "1m",
+				"--tls-cert", "tls.crt", "--tls-key", "tls.key", "--client-ca", "ca.crt",
+			},
+			wantMsg: "cannot be combined with --client-ca",
+		},
+		{
+			name:    "missing repo root",
+			args:    []string{"--repo-root", filepath.Join(os.TempDir(), "slippy-find-no-such-root")},
+			wantMsg: "invalid --repo-root",
+		},
+		{
+			name: "config error",
+			modify: func(deps *Dependencies) {
+				deps.ConfigLoader = func() (*AppConfig, error) { return nil, errors.New("no database") }
+			},
+			wantMsg: "configuration error: no database",
+		},
+		{
+			name: "store error",
+			modify: func(deps *Dependencies) {
+				deps.SlipFinderFactory = func(*AppConfig, Logger) (domain.SlipFinder, error) {
+					return nil, errors.New("connection refused")
+				}
+			},
+			wantMsg: "database error: connection refused",
+		},
+		{
+			name: "server error",
+			modify: func(deps *Dependencies) {
+				deps.ServerFactory = func(ServeOptions, ResolveRequestFunc, Logger) (Server, error) {
+					return nil, errors.New("TLS needs both a certificate and a key")
+				}
+			},
+			wantMsg: "serve configuration error: TLS needs both",
+		},
+		{
+			name: "serve error",
+			modify: func(deps *Dependencies) {
+				deps.ServerFactory = func(ServeOptions, ResolveRequestFunc, Logger) (Server, error) {
+					return &mockServer{serveErr: errors.New("address already in use")}, nil
+				}
+			},
+			wantMsg: "address already in use",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := serveTestDeps(&mockServer{}, &mockResolver{})
+			if tt.modify != nil {
+				tt.modify(deps)
+			}
+
+			err := runServeCmd(deps, tt.args...)
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantMsg)
+		})
+	}
+}
+
+func TestServeCmd_ClosesFinder(t *testing.T) {
+	finder := &mockSlipFinder{}
+	deps := serveTestDeps(&mockServer{}, &mockResolver{})
+	deps.SlipFinderFactory = func(*AppConfig, Logger) (domain.SlipFinder, error) { return finder, nil }
+
+	require.NoError(t, runServeCmd(deps))
+
+	assert.True(t, finder.closeCalled)
+}
+
+func TestServeCmd_StoreReloader(t *testing.T) {
+	opened, reloaded := &mockSlipFinder{}, &mockSlipFinder{}
+	var watching context.Context
+	deps := serveTestDeps(&mockServer{}, &mockResolver{})
+	deps.SlipFinderFactory = func(*AppConfig, Logger) (domain.SlipFinder, error) { return opened, nil }
+	deps.StoreReloader = func(ctx context.Context, _ *AppConfig, finder domain.SlipFinder, _ Logger) domain.SlipFinder {
+		assert.Same(t, opened, finder)
+		watching = ctx
+		return reloaded
+	}
+
+	require.NoError(t, runServeCmd(deps))
+
+	assert.True(t, reloaded.closeCalled, "the reloading finder is closed")
+	assert.False(t, opened.closeCalled, "the reloading finder owns the one it wraps")
+	require.NotNil(t, watching)
+	assert.Error(t, watching.Err(), "reloading stops with the command")
+}
+
+func TestServeCmd_ResolveCommits(t *testing.T) {
+	resolver := &mockResolver{output: &domain.ResolveOutput{CorrelationID: "slip-1"}}
+	srv := &mockServer{requests: []domain.ResolveRequest{
+		{Repository: "org/repo", Branch: "main", Commits: []string{"c0", "c1"}},
+		{Repository: "org/repo", Commits: []string{"c0"}, Depth: 5, SelectionPolicy: domain.SelectionNewestCreated},
+	}}
+	deps := serveTestDeps(srv, resolver)
+	var gitRepos []domain.LocalGitRepository
+	deps.ConfigLoader = func() (*AppConfig, error) { return &AppConfig{DenyList: []string{"slip-bad"}}, nil }
+	deps.ResolverFactory = func(gitRepo domain.LocalGitRepository, _ domain.SlipFinder, _ Logger) domain.Resolver {
+		gitRepos = append(gitRepos, gitRepo)
+		return resolver
+	}
+
+	require.NoError(t, runServeCmd(deps, "--depth", "40"))
+
+	require.Equal(t, []error{nil, nil}, srv.errs)
+	assert.Equal(t, "slip-1", srv.results[0].CorrelationID)
+	gitCtx, err := gitRepos[0].GetGitContext(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &domain.GitContext{Repository: "org/repo", Branch: "main", HeadSHA: "c0"}, gitCtx)
+
+	// The last request's input: its own depth and policy, the served defaults otherwise
+	assert.Equal(t, domain.ResolveInput{
+		Depth:           5,
+		SelectionPolicy: domain.SelectionNewestCreated,
+		DenyList:        []string{"slip-bad"},
+		BranchAffinity:  true,
+		SlowThresholds: domain.SlowThresholds{
+			GitWalk:    domain.DefaultSlowGitWalk,
+			StoreQuery: domain.DefaultSlowStoreQuery,
+		},
+	}, resolver.input)
+}
+
+func TestServeCmd_ResolveDefaults(t *testing.T) {
+	resolver := &mockResolver{output: &domain.ResolveOutput{CorrelationID: "slip-1"}}
+	srv := &mockServer{requests: []domain.ResolveRequest{{Repository: "org/repo", Commits: []string{"c0"}}}}
+
+	require.NoError(t, runServeCmd(serveTestDeps(srv, resolver), "--depth", "40"))
+
+	assert.Equal(t, 40, resolver.input.Depth)
+	assert.Equal(t, domain.DefaultSelectionPolicy, resolver.input.SelectionPolicy)
+}
+
+func TestServeCmd_ResolvePath(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "payments-api")
+	require.NoError(t, os.Mkdir(repo, 0o755))
+	outside := t.TempDir()
+	require.NoError(t, os.Symlink(outside, filepath.Join(root, "escape")))
+	resolvedRepo, err := filepath.EvalSymlinks(repo)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		path     string
+		noRoot   bool
+		wantPath string
+		wantCode domain.ErrorCode
+		wantMsg  string
+	}{
+		{name: "under root", path: repo, wantPath: resolvedRepo},
+		{name: "root itself", path: root, wantPath: filepath.Dir(resolvedRepo)},
+		{name: "outside root", path: outside, wantCode: domain.CodeInvalidInput, wantMsg: "outside the served roots"},
+		{
+			name:     "dot-dot escape",
+			path:     filepath.Join(repo, "..", "..", filepath.Base(outside)),
+			wantCode: domain.CodeInvalidInput,
+			wantMsg:  "outside the served roots",
+		},
+		{
+			name:     "symlink escape",
+			path:     filepath.Join(root, "escape"),
+			wantCode: domain.CodeInvalidInput,
+			wantMsg:  "outside the served roots",
+		},
+		{name: "relative", path: "payments-api", wantCode: domain.CodeInvalidInput, wantMsg: "path must be absolute"},
+		{
+			name:     "missing",
+			path:     filepath.Join(root, "missing"),
+			wantCode: domain.CodeNotARepository,
+			wantMsg:  "not a git repository",
+		},
+		{
+			name:     "no repo root",
+			path:     repo,
+			noRoot:   true,
+			wantCode: domain.CodeInvalidInput,
+			wantMsg:  "path requests are disabled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &mockServer{requests: []domain.ResolveRequest{{Path: tt.path}}}
+			deps := serveTestDeps(srv, &mockResolver{output: &domain.ResolveOutput{CorrelationID: "slip-1"}})
+			var opened string
+			deps.GitRepoFactory = func(path string, _ Logger) (domain.LocalGitRepository, error) {
+				opened = path
+				return &mockGitRepo{}, nil
+			}
+			var args []string
+			if !tt.noRoot {
+				args = []string{"--repo-root", root}
+			}
+
+			require.NoError(t, runServeCmd(deps, args...))
+
+			if tt.wantMsg == "" {
+				require.NoError(t, srv.errs[0])
+				assert.Equal(t, tt.wantPath, opened)
+				return
+			}
+			require.Error(t, srv.errs[0])
+			assert.Contains(t, srv.errs[0].Error(), tt.wantMsg)
+			assert.Equal(t, tt.wantCode, domain.CodeOf(srv.errs[0]))
+			assert.Empty(t, opened)
+		})
+	}
+}
+
+func TestServeCmd_ResolveErrors(t *testing.T) {
+	root := t.TempDir()
+	tests := []struct {
+		name     string
+		modify   func(deps *Dependencies)
+		req      domain.ResolveRequest
+		wantCode domain.ErrorCode
+	}{
+		{
+			name: "not a repository",
+			modify: func(deps *Dependencies) {
+				deps.GitRepoFactory = func(string, Logger) (domain.LocalGitRepository, error) {
+					return nil, domain.ErrRepositoryNotFound
+				}
+			},
+			req:      domain.ResolveRequest{Path: root},
+			wantCode: domain.CodeNotARepository,
+		},
+		{
+			name: "git failure",
+			modify: func(deps *Dependencies) {
+				deps.GitRepoFactory = func(string, Logger) (domain.LocalGitRepository, error) {
+					return nil, errors.New("corrupt object")
+				}
+			},
+			req:      domain.ResolveRequest{Path: root},
+			wantCode: domain.CodeGit,
+		},
+		{
+			name:     "commit lists not configured",
+			modify:   func(deps *Dependencies) { deps.AncestryRepoFactory = nil },
+			req:      domain.ResolveRequest{Repository: "org/repo", Commits: []string{"c0"}},
+			wantCode: domain.CodeConfiguration,
+		},
+		{
+			name: "no slip",
+			modify: func(deps *Dependencies) {
+				deps.ResolverFactory = func(domain.LocalGitRepository, domain.SlipFinder, Logger) domain.Resolver {
+					return &mockResolver{err: domain.ErrNoAncestorSlip}
+				}
+			},
+			req:      domain.ResolveRequest{Repository: "org/repo", Commits: []string{"c0"}},
+			wantCode: domain.CodeNoSlip,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &mockServer{requests: []domain.ResolveRequest{tt.req}}
+			deps := serveTestDeps(srv, &mockResolver{})
+			tt.modify(deps)
+
+			require.NoError(t, runServeCmd(deps, "--repo-root", root))
+
+			require.Error(t, srv.errs[0])
+			assert.Equal(t, tt.wantCode, domain.CodeOf(srv.errs[0]))
+		})
+	}
+}
+
+func TestIsLoopback(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{addr: "127.0.0.1:8080", want: true},
+		{addr: "localhost:8080", want: true},
+		{addr: "[::1]:8080", want: true},
+		{addr: ":8080", want: false},
+		{addr: "0.0.0.0:8080", want: false},
+		{addr: "10.0.0.5:8080", want: false},
+		{addr: "not-an-address", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			assert.Equal(t, tt.want, isLoopback(tt.addr))
+		})
+	}
+}
+
+func TestServeCmd_Cache(t *testing.T) {
+	resolver := &mockResolver{output: &domain.ResolveOutput{CorrelationID: "slip-1"}}
+	srv := &mockServer{requests: []domain.ResolveRequest{
+		{Repository: "org/repo", Commits: []string{"c0"}},
+		{Repository: "org/repo", Commits: []string{"c0"}},
+		{Repository: "org/repo", Commits: []string{"c0", "c1"}},
+		{Repository: "org/repo", Commits: []string{"c0"}, Depth: 5},
+		{Repository: "org/other", Commits: []string{"c0"}},
+		{Repository: "org/repo", Branch: "main", Commits: []string{"c9", "c8"}, Verified: true},
+		{Repository: "org/repo", Commits: []string{"c9", "made-up"}},
+	}}
+	deps := serveTestDeps(srv, resolver)
+	resolutions := 0
+	deps.ResolverFactory = func(domain.LocalGitRepository, domain.SlipFinder, Logger) domain.Resolver {
+		resolutions++
+		return resolver
+	}
+
+	require.NoError(t, runServeCmd(deps, "--cache-ttl", "1m"))
+
+	require.Equal(t, make([]error, len(srv.requests)), srv.errs)
+	// The second request repeats the first; the last has the head of the
+	// verified push before it. Another commit list is another resolution.
+	assert.Equal(t, 5, resolutions)
+	assert.Equal(t, "slip-1", srv.results[1].CorrelationID)
+	assert.Equal(t, "slip-1", srv.results[6].CorrelationID)
+}
+
+func TestServeCmd_CacheSkipsFailures(t *testing.T) {
+	resolver := &mockResolver{err: domain.ErrNoAncestorSlip}
+	srv := &mockServer{requests: []domain.ResolveRequest{
+		{Repository: "org/repo", Commits: []string{"c0"}},
+		{Repository: "org/repo", Commits: []string{"c0"}},
+	}}
+	deps := serveTestDeps(srv, resolver)
+	resolutions := 0
+	deps.ResolverFactory = func(domain.LocalGitRepository, domain.SlipFinder, Logger) domain.Resolver {
+		resolutions++
+		return resolver
+	}
+
+	require.NoError(t, runServeCmd(deps, "--cache-ttl", "1m"))
+
+	assert.Equal(t, 2, resolutions, "a commit without a slip is looked up again")
+}
+
+func TestServeCmd_NoCacheByDefault(t *testing.T) {
+	resolver := &mockResolver{output: &domain.ResolveOutput{CorrelationID: "slip-1"}}
+	srv := &mockServer{requests: []domain.ResolveRequest{
+		{Repository: "org/repo", Commits: []string{"c0"}},
+		{Repository: "org/repo", Commits: []string{"c0"}},
+	}}
+	deps := serveTestDeps(srv, resolver)
+	resolutions := 0
+	deps.ResolverFactory = func(domain.LocalGitRepository, domain.SlipFinder, Logger) domain.Resolver {
+		resolutions++
+		return resolver
+	}
+
+	require.NoError(t, runServeCmd(deps))
+
+	assert.Equal(t, 2, resolutions)
+}
+
+func TestServeCmd_Webhook(t *testing.T) {
+	srv := &mockServer{}
+
+	require.NoError(t, runServeCmd(serveTestDeps(srv, &mockResolver{}),
+		"--cache-ttl", "10m", "--webhook-secret-file", "webhook-secret"))
+
+	assert.Equal(t, "webhook-secret", srv.opts.WebhookSecretFile)
+}
+
+func TestServeCmd_Admin(t *testing.T) {
+	srv := &mockServer{requests: []domain.ResolveRequest{
+		{Repository: "org/repo", Commits: []string{"c0"}},
+		{Repository: "org/repo", Commits: []string{"c0"}},
+	}}
+	deps := serveTestDeps(srv, &mockResolver{output: &domain.ResolveOutput{CorrelationID: "slip-1"}})
+	deps.ConfigLoader = func() (*AppConfig, error) {
+		return &AppConfig{Settings: []ConfigSetting{
+			{Key: "clickhouse.hostname", Value: "ch.internal", Source: "env", Detail: "CLICKHOUSE_HOSTNAME"},
+			{Key: "clickhouse.password", Value: "hunter2", Source: "vault", Secret: true},
+		}}, nil
+	}
+
+	require.NoError(t, runServeCmd(deps, "--admin-token-file", "admin-tokens", "--cache-ttl", "1m"))
+
+	assert.Equal(t, "admin-tokens", srv.opts.AdminTokenFile)
+	require.NotNil(t, srv.admin)
+	assert.Equal(t, map[string]CacheStats{
+		DefaultStoreName: {Enabled: true, Entries: 1, Hits: 1, Misses: 1, TTL: time.Minute},
+	}, srv.admin.CacheStats())
+	assert.Equal(t, 1, srv.admin.FlushCache())
+	assert.Equal(t, 0, srv.admin.CacheStats()[DefaultStoreName].Entries)
+	assert.Equal(t, []ConfigSetting{
+		{Key: "clickhouse.hostname", Value: "ch.internal", Source: "env", Detail: "CLICKHOUSE_HOSTNAME"},
+		{Key: "clickhouse.password", Value: redactedValue, Source: "vault", Secret: true},
+	}, srv.admin.Settings())
+}
+
+func TestServeCmd_AdminDisabled(t *testing.T) {
+	srv := &mockServer{}
+
+	require.NoError(t, runServeCmd(serveTestDeps(srv, &mockResolver{})))
+
+	assert.Nil(t, srv.admin)
+}
+
+func TestServeCmd_Ping(t *testing.T) {
+	tests := []struct {
+		name    string
+		finder  domain.SlipFinder
+		wantErr error
+	}{
+		{name: "store reachable", finder: &pingingSlipFinder{}},
+		{
+			name:    "store unreachable",
+			finder:  &pingingSlipFinder{err: errors.New("connection refused")},
+			wantErr: errors.New("connection refused"),
+		},
+		{name: "store cannot be checked", finder: &mockSlipFinder{}, wantErr: domain.ErrPingUnsupported},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &mockServer{}
+			deps := serveTestDeps(srv, &mockResolver{})
+			deps.SlipFinderFactory = func(*AppConfig, Logger) (domain.SlipFinder, error) { return tt.finder, nil }
+
+			require.NoError(t, runServeCmd(deps))
+
+			require.NotNil(t, srv.ping)
+			assert.Equal(t, tt.wantErr, srv.ping(context.Background()))
+		})
+	}
+}
+
+func TestServeCmd_Kubernetes(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          []string
+		wantProbeAddr string
+		wantSecrets   string
+		wantLogFormat string
+	}{
+		{
+			name:          "preset",
+			args:          []string{"--kubernetes"},
+			wantProbeAddr: ":8081",
+			wantSecrets:   "/etc/slippy-find/secrets",
+			wantLogFormat: "json",
+		},
+		{
+			name:          "preset with overrides",
+			args:          []string{"--kubernetes", "--probe-listen", ":9091", "--secrets-dir", "/mnt/secrets"},
+			wantProbeAddr: ":9091",
+			wantSecrets:   "/mnt/secrets",
+			wantLogFormat: "json",
+		},
+		{
+			name:          "probes and secrets without the preset",
+			args:          []string{"--probe-listen", ":9091", "--secrets-dir", "/mnt/secrets"},
+			wantProbeAddr: ":9091",
+			wantSecrets:   "/mnt/secrets",
+			wantLogFormat: "console",
+		},
+		{name: "neither", wantLogFormat: "console"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("LOG_FORMAT", "console")
+			srv := &mockServer{}
+			deps := serveTestDeps(srv, &mockResolver{})
+			var secretsDir string
+			deps.SecretsLoader = func(dir string) ([]string, error) {
+				secretsDir = dir
+				return []string{"CLICKHOUSE_PASSWORD"}, nil
+			}
+
+			require.NoError(t, runServeCmd(deps, tt.args...))
+
+			assert.Equal(t, "127.0.0.1:8080", srv.opts.Addr)
+			assert.Equal(t, tt.wantProbeAddr, srv.opts.ProbeAddr)
+			assert.Equal(t, tt.wantSecrets, secretsDir)
+			assert.Equal(t, tt.wantLogFormat, os.Getenv("LOG_FORMAT"))
+		})
+	}
+}
+
+func TestServeCmd_KubernetesErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		loader  func(dir string) ([]string, error)
+		wantMsg string
+	}{
+		{
+			name:    "non-loopback listen",
+			args:    []string{"--kubernetes", "--listen", ":8080"},
+			wantMsg: "--kubernetes listens on localhost only; :8080 is not a loopback address",
+		},
+		{
+			name:    "missing secrets",
+			args:    []string{"--kubernetes"},
+			loader:  func(string) ([]string, error) { return nil, errors.New("no such file or directory") },
+			wantMsg: "failed to load mounted secrets: no such file or directory",
+		},
+		{
+			name:    "secrets not supported",
+			args:    []string{"--secrets-dir", "/mnt/secrets"},
+			wantMsg: "mounted secrets not supported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("LOG_FORMAT", "console")
+			deps := serveTestDeps(&mockServer{}, &mockResolver{})
+			deps.SecretsLoader = tt.loader
+
+			err := runServeCmd(deps, tt.args...)
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantMsg)
+		})
+	}
+}
+
+// blockingResolver counts resolutions and blocks each until released or its
+// context is done.
+type blockingResolver struct {
+	mockResolver
+	calls   atomic.Int32
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingResolver) Resolve(ctx context.Context, _ domain.ResolveInput) (*domain.ResolveOutput, error) {
+	b.calls.Add(1)
+	b.started <- struct{}{}
+	select {
+	case <-b.release:
+		return &domain.ResolveOutput{CorrelationID: "slip-1"}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// coalescingTestResolver returns a requestResolver whose resolutions block on blocking.
+func coalescingTestResolver(blocking *blockingResolver) *requestResolver {
+	deps := serveTestDeps(&mockServer{}, &mockResolver{})
+	deps.ResolverFactory = func(domain.LocalGitRepository, domain.SlipFinder, Logger) domain.Resolver {
+		return blocking
+	}
+	return &requestResolver{
+		deps:     deps,
+		log:      &mockLogger{},
+		cfg:      &AppConfig{},
+		finder:   &mockSlipFinder{},
+		depth:    domain.DefaultAncestryDepth,
+		inflight: new(singleflight.Group),
+	}
+}
+
+func TestRequestResolver_CoalescesIdenticalRequests(t *testing.T) {
+	blocking := &blockingResolver{started: make(chan struct{}, 10), release: make(chan struct{})}
+	resolver := coalescingTestResolver(blocking)
+	req := domain.ResolveRequest{Repository: "org/repo", Commits: []string{"c0"}}
+
+	const callers = 5
+	var wg sync.WaitGroup
+	results := make([]*domain.ResolveOutput, callers)
+	errs := make([]error, callers)
+	resolveAt := func(i int) {
+		defer wg.Done()
+		results[i], errs[i] = resolver.resolve(context.Background(), req)
+	}
+	wg.Add(callers)
+	go resolveAt(0)
+	<-blocking.started
+	for i := 1; i < callers; i++ {
+		go resolveAt(i)
+	}
+	// Give the others time to join the resolution in flight
+	time.Sleep(50 * time.Millisecond)
+	close(blocking.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), blocking.calls.Load())
+	for i := range callers {
+		require.NoError(t, errs[i])
+		assert.Equal(t, "slip-1", results[i].CorrelationID)
+	}
+	results[0].CorrelationID = "changed"
+	assert.Equal(t, "slip-1", results[1].CorrelationID, "each caller gets its own copy")
+
+	// Another commit is another resolution
+	blocking.started = make(chan struct{}, 1)
+	other := domain.ResolveRequest{Repository: "org/repo", Commits: []string{"c1"}}
+	_, err := resolver.resolve(context.Background(), other)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), blocking.calls.Load())
+}
+
+func TestRequestResolver_CoalescedRequestOutlivesCancelledLeader(t *testing.T) {
+	blocking := &blockingResolver{started: make(chan struct{}, 10), release: make(chan struct{})}
+	resolver := coalescingTestResolver(blocking)
+	req := domain.ResolveRequest{Repository: "org/repo", Commits: []string{"c0"}}
+
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := resolver.resolve(leaderCtx, req)
+		leaderErr <- err
+	}()
+	<-blocking.started
+
+	type outcome struct {
+		result *domain.ResolveOutput
+		err    error
+	}
+	follower := make(chan outcome, 1)
+	go func() {
+		result, err := resolver.resolve(context.Background(), req)
+		follower <- outcome{result, err}
+	}()
+	time.Sleep(50 * time.Millisecond)
+	cancelLeader()
+	require.ErrorIs(t, <-leaderErr, context.Canceled)
+
+	// The follower resolves again on its own context
+	<-blocking.started
+	close(blocking.release)
+	got := <-follower
+	require.NoError(t, got.err)
+	assert.Equal(t, "slip-1", got.result.CorrelationID)
+	assert.Equal(t, int32(2), blocking.calls.Load())
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// tenantResolver dispatches serve-mode requests to the slip store of the
+// request's tenant. Requests that name no tenant use the default store.
+type tenantResolver struct {
+	defaultStore *requestResolver
+
+	// tenants maps each tenant of a --tenants-file to its store.
+	tenants map[string]*requestResolver
+}
+
+// openTenants opens the slip store of each tenant listed in the tenants file
+// at path. Each tenant starts from the default store's configuration and has
+// its own result cache; tenants with the same store share its connection. An empty path hosts the default store alone. The
+// caller must call the returned close function.
+func openTenants(
+	ctx context.Context,
+	deps *Dependencies,
+	log Logger,
+	defaultStore *requestResolver,
+	path string,
+) (*tenantResolver, func(), error) {
+	resolver := &tenantResolver{defaultStore: defaultStore}
+	if path == "" {
+		return resolver, func() {}, nil
+	}
+	if deps.TenantLoader == nil {
+		return nil, nil, configError(errors.New("tenants not supported"))
+	}
+	configs, err := deps.TenantLoader(path, defaultStore.cfg)
+	if err != nil {
+		err = configError(fmt.Errorf("failed to load tenants: %w", err))
+		log.Error(ctx, "failed to load tenants", err, nil)
+		return nil, nil, err
+	}
+	if _, ok := configs[DefaultStoreName]; ok {
+		return nil, nil, configError(fmt.Errorf("tenant name %q is reserved for the default store", DefaultStoreName))
+	}
+
+	finders := newFinderCache(deps, log)
+	var closers []func()
+	closeAll := func() {
+		for _, closeFinder := range closers {
+			closeFinder()
+		}
+	}
+	names := slices.Sorted(maps.Keys(configs))
+	resolver.tenants = make(map[string]*requestResolver, len(names))
+	for _, name := range names {
+		cfg := configs[name]
+		finder, closeFinder, err := finders.open(ctx, cfg, map[string]interface{}{"tenant": name})
+		if err != nil {
+			closeAll()
+			return nil, nil, fmt.Errorf("tenant %s: %w", name, err)
+		}
+		closers = append(closers, closeFinder)
+
+		tenant := *defaultStore
+		tenant.cfg, tenant.finder = cfg, finder
+		tenant.inflight = new(singleflight.Group)
+		if defaultStore.cache != nil {
+			tenant.cache = newResultCache(defaultStore.cache.ttl)
+		}
+		resolver.tenants[name] = &tenant
+	}
+	log.Info(ctx, "hosting tenants", map[string]interface{}{
+		"tenants":             names,
+		"stores":              len(names) - finders.reused,
+		"store_open_saved_ms": millis(finders.saved),
+	})
+	return resolver, closeAll, nil
+}
+
+// resolve resolves req against its tenant's store.
+func (t *tenantResolver) resolve(ctx context.Context, req domain.ResolveRequest) (*domain.ResolveOutput, error) {
+	if req.Tenant == "" {
+		return t.defaultStore.resolve(ctx, req)
+	}
+	tenant, ok := t.tenants[req.Tenant]
+	if !ok {
+		return nil, domain.WithCode(domain.CodeInvalidInput, fmt.Errorf("unknown tenant %q", req.Tenant))
+	}
+	return tenant.resolve(ctx, req)
+}
+
+// ping checks the connection to every store. It fails if any store that can
+// be checked fails, naming its tenant, and returns domain.ErrPingUnsupported
+// when none can be checked.
+func (t *tenantResolver) ping(ctx context.Context) error {
+	if len(t.tenants) == 0 {
+		return t.defaultStore.ping(ctx)
+	}
+	checked := false
+	var failures []error
+	check := func(name string, r *requestResolver) {
+		err := r.ping(ctx)
+		switch {
+		case errors.Is(err, domain.ErrPingUnsupported):
+		case err != nil:
+			checked = true
+			if name != "" {
+				err = fmt.Errorf("tenant %s: %w", name, err)
+			}
+			failures = append(failures, err)
+		default:
+			checked = true
+		}
+	}
+
+	check("", t.defaultStore)
+	for _, name := range slices.Sorted(maps.Keys(t.tenants)) {
+		check(name, t.tenants[name])
+	}
+	if !checked {
+		return domain.ErrPingUnsupported
+	}
+	return errors.Join(failures...)
+}
+
+// cacheStats reports each store's result cache, for the admin endpoints.
+func (t *tenantResolver) cacheStats() map[string]CacheStats {
+	stats := make(map[string]CacheStats, len(t.tenants)+1)
+	stats[DefaultStoreName] = t.defaultStore.cacheStats()
+	for name, tenant := range t.tenants {
+		stats[name] = tenant.cacheStats()
+	}
+	return stats
+}
+
+// flushCache empties every store's result cache and returns the entries dropped.
+func (t *tenantResolver) flushCache() int {
+	flushed := t.defaultStore.flushCache()
+	for _, tenant := range t.tenants {
+		flushed += tenant.flushCache()
+	}
+	return flushed
+}
+
+// settings returns the default store's effective configuration with secret
+// values redacted. Tenants only override its connection settings.
+func (t *tenantResolver) settings() []ConfigSetting {
+	settings := make([]ConfigSetting, 0, len(t.defaultStore.cfg.Settings))
+	for _, setting := range t.defaultStore.cfg.Settings {
+		setting.Value = displayValue(setting)
+		settings = append(settings, setting)
+	}
+	return settings
+}
@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// tenantTestDeps returns serve dependencies hosting a "payments" tenant. Each
+// store's finder is recorded in finders by database name.
+func tenantTestDeps(srv *mockServer, resolver *mockResolver, finders map[string]*mockSlipFinder) *Dependencies {
+	deps := serveTestDeps(srv, resolver)
+	deps.ConfigLoader = func() (*AppConfig, error) { return &AppConfig{Database: "ci"}, nil }
+	deps.TenantLoader = func(_ string, base *AppConfig) (map[string]*AppConfig, error) {
+		payments := *base
+		payments.Database = "payments_ci"
+		return map[string]*AppConfig{"payments": &payments}, nil
+	}
+	deps.SlipFinderFactory = func(cfg *AppConfig, _ Logger) (domain.SlipFinder, error) {
+		finder := &mockSlipFinder{}
+		finders[cfg.Database] = finder
+		return finder, nil
+	}
+	return deps
+}
+
+func TestServeCmd_Tenants(t *testing.T) {
+	resolver := &mockResolver{output: &domain.ResolveOutput{CorrelationID: "slip-1"}}
+	srv := &mockServer{requests: []domain.ResolveRequest{
+		{Repository: "org/repo", Commits: []string{"c0"}},
+		{Repository: "org/repo", Commits: []string{"c0"}, Tenant: "payments"},
+		{Repository: "org/repo", Commits: []string{"c0"}, Tenant: "logistics"},
+	}}
+	finders := map[string]*mockSlipFinder{}
+	deps := tenantTestDeps(srv, resolver, finders)
+	var used []domain.SlipFinder
+	deps.ResolverFactory = func(_ domain.LocalGitRepository, finder domain.SlipFinder, _ Logger) domain.Resolver {
+		used = append(used, finder)
+		return resolver
+	}
+
+	require.NoError(t, runServeCmd(deps, "--tenants-file", "tenants.yaml"))
+
+	require.Len(t, finders, 2)
+	assert.Equal(t, []domain.SlipFinder{finders["ci"], finders["payments_ci"]}, used)
+	assert.NoError(t, srv.errs[0])
+	assert.NoError(t, srv.errs[1])
+	require.Error(t, srv.errs[2])
+	assert.Equal(t, domain.CodeInvalidInput, domain.CodeOf(srv.errs[2]))
+	assert.Contains(t, srv.errs[2].Error(), `unknown tenant "logistics"`)
+	assert.True(t, finders["ci"].closeCalled)
+	assert.True(t, finders["payments_ci"].closeCalled)
+}
+
+func TestServeCmd_TenantsCacheSeparately(t *testing.T) {
+	resolver := &mockResolver{output: &domain.ResolveOutput{CorrelationID: "slip-1"}}
+	srv := &mockServer{requests: []domain.ResolveRequest{
+		{Repository: "org/repo", Commits: []string{"c0"}},
+		{Repository: "org/repo", Commits: []string{"c0"}, Tenant: "payments"},
+		{Repository: "org/repo", Commits: []string{"c0"}, Tenant: "payments"},
+	}}
+	deps := tenantTestDeps(srv, resolver, map[string]*mockSlipFinder{})
+	resolutions := 0
+	deps.ResolverFactory = func(domain.LocalGitRepository, domain.SlipFinder, Logger) domain.Resolver {
+		resolutions++
+		return resolver
+	}
+
+	require.NoError(t, runServeCmd(deps, "--tenants-file", "tenants.yaml", "--cache-ttl", "1m"))
+
+	require.Equal(t, []error{nil, nil, nil}, srv.errs)
+	assert.Equal(t, 2, resolutions, "the default store's result is not served to a tenant")
+}
+
+func TestServeCmd_TenantErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(deps *Dependencies)
+		wantMsg string
+	}{
+		{
+			name:    "not supported",
+			setup:   func(deps *Dependencies) { deps.TenantLoader = nil },
+			wantMsg: "tenants not supported",
+		},
+		{
+			name: "invalid file",
+			setup: func(deps *Dependencies) {
+				deps.TenantLoader = func(string, *AppConfig) (map[string]*AppConfig, error) {
+					return nil, errors.New("no tenants")
+				}
+			},
+			wantMsg: "failed to load tenants: no tenants",
+		},
+		{
+			name: "reserved name",
+			setup: func(deps *Dependencies) {
+				deps.TenantLoader = func(_ string, base *AppConfig) (map[string]*AppConfig, error) {
+					return map[string]*AppConfig{DefaultStoreName: base}, nil
+				}
+			},
+			wantMsg: `tenant name "default" is reserved`,
+		},
+		{
+			name: "tenant store unavailable",
+			setup: func(deps *Dependencies) {
+				open := deps.SlipFinderFactory
+				deps.SlipFinderFactory = func(cfg *AppConfig, log Logger) (domain.SlipFinder, error) {
+					if cfg.Database == "payments_ci" {
+						return nil, errors.New("connection refused")
+					}
+					return open(cfg, log)
+				}
+			},
+			wantMsg: "tenant payments: database error: connection refused",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &mockServer{}
+			finders := map[string]*mockSlipFinder{}
+			deps := tenantTestDeps(srv, &mockResolver{}, finders)
+			tt.setup(deps)
+
+			err := runServeCmd(deps, "--tenants-file", "tenants.yaml")
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantMsg)
+			assert.True(t, finders["ci"].closeCalled, "the default store is closed")
+		})
+	}
+}
+
+func TestServeCmd_TenantsAdmin(t *testing.T) {
+	srv := &mockServer{requests: []domain.ResolveRequest{
+		{Repository: "org/repo", Commits: []string{"c0"}},
+		{Repository: "org/repo", Commits: []string{"c0"}, Tenant: "payments"},
+	}}
+	deps := tenantTestDeps(srv, &mockResolver{output: &domain.ResolveOutput{CorrelationID: "slip-1"}},
+		map[string]*mockSlipFinder{})
+
+	require.NoError(t, runServeCmd(deps, "--tenants-file", "tenants.yaml", "--admin-token-file", "admin-tokens"))
+
+	require.NotNil(t, srv.admin)
+	assert.Equal(t, map[string]CacheStats{DefaultStoreName: {}, "payments": {}}, srv.admin.CacheStats(),
+		"without --cache-ttl no store has a cache")
+	assert.Zero(t, srv.admin.FlushCache())
+}
+
+func TestTenantResolver_Ping(t *testing.T) {
+	tests := []struct {
+		name    string
+		stores  map[string]domain.SlipFinder
+		wantErr string
+		wantIs  error
+	}{
+		{
+			name:   "all reachable",
+			stores: map[string]domain.SlipFinder{"": &pingingSlipFinder{}, "payments": &pingingSlipFinder{}},
+		},
+		{
+			name: "tenant unreachable",
+			stores: map[string]domain.SlipFinder{
+				"":         &pingingSlipFinder{},
+				"payments": &pingingSlipFinder{err: errors.New("connection refused")},
+			},
+			wantErr: "tenant payments: connection refused",
+		},
+		{
+			name:   "default store cannot be checked",
+			stores: map[string]domain.SlipFinder{"": &mockSlipFinder{}, "payments": &pingingSlipFinder{}},
+		},
+		{
+			name:   "no store can be checked",
+			stores: map[string]domain.SlipFinder{"": &mockSlipFinder{}, "payments": &mockSlipFinder{}},
+			wantIs: domain.ErrPingUnsupported,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := &tenantResolver{tenants: map[string]*requestResolver{}}
+			for name, finder := range tt.stores {
+				if name == "" {
+					resolver.defaultStore = &requestResolver{finder: finder}
+					continue
+				}
+				resolver.tenants[name] = &requestResolver{finder: finder}
+			}
+
+			err := resolver.ping(context.Background())
+
+			switch {
+			case tt.wantErr != "":
+				assert.EqualError(t, err, tt.wantErr)
+			case tt.wantIs != nil:
+				assert.ErrorIs(t, err, tt.wantIs)
+			default:
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestServeCmd_TenantsShareStore(t *testing.T) {
+	srv := &mockServer{}
+	deps := serveTestDeps(srv, &mockResolver{})
+	deps.ConfigLoader = func() (*AppConfig, error) { return &AppConfig{Database: "ci"}, nil }
+	deps.TenantLoader = func(_ string, base *AppConfig) (map[string]*AppConfig, error) {
+		tenants := make(map[string]*AppConfig)
+		for _, name := range []string{"payments", "billing"} {
+			cfg := *base
+			cfg.Database, cfg.StoreFingerprint = "shared_ci", "shared"
+			tenants[name] = &cfg
+		}
+		return tenants, nil
+	}
+	var opened []*mockSlipFinder
+	deps.SlipFinderFactory = func(*AppConfig, Logger) (domain.SlipFinder, error) {
+		finder := &mockSlipFinder{}
+		opened = append(opened, finder)
+		return finder, nil
+	}
+
+	require.NoError(t, runServeCmd(deps, "--tenants-file", "tenants.yaml"))
+
+	require.Len(t, opened, 2, "the default store and one store shared by both tenants")
+	assert.True(t, opened[1].closeCalled)
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// Worker command flags.
+var (
+	workerQueue       string
+	workerSubject     string
+	workerGroup       string
+	workerConcurrency int
+	workerDepth       int
+	workerVerbose     bool
+)
+
+// WorkerOptions configures the queue worker built by Dependencies.WorkerFactory.
+type WorkerOptions struct {
+	// QueueURL is the queue to consume from: nats://host:port or
+	// kafka://broker:port[,broker:port...].
+	QueueURL string
+
+	// Subject is the NATS subject or Kafka topic requests are consumed from.
+	Subject string
+
+	// Group is the NATS queue group or Kafka consumer group shared by workers,
+	// so each request is handled once.
+	Group string
+
+	// Concurrency is the number of requests resolved at once.
+	Concurrency int
+}
+
+// Worker resolves requests consumed from a queue until its context is done.
+type Worker interface {
+	Run(ctx context.Context) error
+}
+
+// newWorkerCmd creates "worker", which resolves requests consumed from a
+// message queue.
+func newWorkerCmd(deps *Dependencies) *cobra.Command {
+	workerCmd := &cobra.Command{
+		Use:   "worker",
+		Short: "Resolve slips for requests consumed from NATS or Kafka",
+		Long: `Run slippy-find as a queue worker that resolves slips asynchronously.
+
+Requests are consumed from --subject (a NATS subject or Kafka topic) as a
+member of --group, so running several workers spreads requests between them.
+Each request names a repository and its commit ancestry, newest first:
+
+  {"id": "req-1", "repository": "org/payments-api", "branch": "main",
+   "commits": ["9f2c1e7a4b3d5f6081a2b3c4d5e6f708192a3b4c",
+               "4e8d2a6c0b1f3e5d7c9a8b6f4d2e0c1a3b5d7f9e"],
+   "reply_to": "slippy.results"}
+
+Commits must be full 40- or 64-character SHAs; abbreviated ones are rejected.
+"depth" and "selection_policy" are accepted as well. The reply is published
+to reply_to, or on NATS to the message's reply subject when reply_to is not
+set, and echoes the request's id:
+
+  {"id": "req-1", "result": {...same fields as --format json...}}
+  {"id": "req-1", "error": "no slip found in commit ancestry", "code": "SLIPPY_E003"}
+
+A Kafka message is committed once its reply is published, so a request in
+progress when a worker dies is delivered again; requests without a reply
+address are resolved and dropped.
+
+Examples:
+  slippy-find worker --queue nats://nats:4222
+  slippy-find worker --queue kafka://kafka-0:9092,kafka-1:9092 --subject slippy-requests --concurrency 16`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runWorker(cmd, deps)
+		},
+	}
+
+	workerCmd.Flags().StringVar(&workerQueue, "queue", "",
+		"Queue to consume from: nats://host:port or kafka://broker:port[,broker:port...] (required)")
+	workerCmd.Flags().StringVar(&workerSubject, "subject", "slippy.resolve",
+		"NATS subject or Kafka topic to consume requests from")
+	workerCmd.Flags().StringVar(&workerGroup, "group", "slippy-find",
+		"NATS queue group or Kafka consumer group shared by workers")
+	workerCmd.Flags().IntVar(&workerConcurrency, "concurrency", 4,
+		"Number of requests resolved at once")
+	workerCmd.Flags().IntVarP(&workerDepth, "depth", "d", domain.DefaultAncestryDepth,
+		"Ancestry depth for requests that do not set one")
+	workerCmd.Flags().BoolVarP(&workerVerbose, "verbose", "v", false,
+		"Enable verbose/debug logging")
+
+	return workerCmd
+}
+
+// runWorker opens the slip store and resolves queued requests until the
+// command's context is done.
+func runWorker(cmd *cobra.Command, deps *Dependencies) error {
+	if deps == nil || deps.WorkerFactory == nil {
+		return configError(errors.New("worker mode not configured"))
+	}
+	switch {
+	case workerQueue == "":
+		return usageErrorf("--queue is required")
+	case workerSubject == "":
+		return usageErrorf("--subject cannot be empty")
+	case workerConcurrency < 1:
+		return usageErrorf("--concurrency must be at least 1")
+	case workerDepth < 1:
+		return usageErrorf("--depth must be at least 1")
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if workerVerbose {
+		enableDebugLogging(deps)
+	}
+	log := deps.LoggerFactory()
+
+	// Requests carry their own commits, so no repository roots are needed.
+	resolver, closeResolver, err := openRequestResolver(ctx, deps, log, nil, workerDepth)
+	if err != nil {
+		return err
+	}
+	defer closeResolver()
+
+	worker, err := deps.WorkerFactory(WorkerOptions{
+		QueueURL:    workerQueue,
+		Subject:     workerSubject,
+		Group:       workerGroup,
+		Concurrency: workerConcurrency,
+	}, resolver.resolve, log)
+	if err != nil {
+		return configError(fmt.Errorf("worker configuration error: %w", err))
+	}
+	log.Info(ctx, "worker started", map[string]interface{}{
+		"subject":     workerSubject,
+		"group":       workerGroup,
+		"concurrency": workerConcurrency,
+	})
+	return worker.Run(ctx)
+}
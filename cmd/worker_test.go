@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// mockWorker resolves each of its requests once when run, recording the results.
+type mockWorker struct {
+	requests []domain.ResolveRequest
+	runErr   error
+
+	opts    WorkerOptions
+	results []*domain.ResolveOutput
+	errs    []error
+}
+
+func (m *mockWorker) Run(_ context.Context) error {
+	return m.runErr
+}
+
+// workerTestDeps returns dependencies whose WorkerFactory resolves worker.requests.
+func workerTestDeps(worker *mockWorker, resolver *mockResolver) *Dependencies {
+	deps := serveTestDeps(&mockServer{}, resolver)
+	deps.WorkerFactory = func(opts WorkerOptions, resolve ResolveRequestFunc, _ Logger) (Worker, error) {
+		worker.opts = opts
+		for _, req := range worker.requests {
+			result, err := resolve(context.Background(), req)
+			worker.results = append(worker.results, result)
+			worker.errs = append(worker.errs, err)
+		}
+		return worker, nil
+	}
+	return deps
+}
+
+func runWorkerCmd(deps *Dependencies, args ...string) error {
+	cmd := NewRootCmdWithDeps(deps)
+	cmd.SetArgs(append([]string{"worker"}, args...))
+	return cmd.Execute()
+}
+
+func TestWorkerCmd_Options(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want WorkerOptions
+	}{
+		{
+			name: "defaults",
+			args: []string{"--queue", "nats://nats:4222"},
+			want: WorkerOptions{
+				QueueURL:    "nats://nats:4222",
+				Subject:     "slippy.resolve",
+				Group:       "slippy-find",
+				Concurrency: 4,
+			},
+		},
+		{
+			name: "explicit",
+			args: []string{
+				"--queue", "kafka://k0:9092,k1:9092", "--subject", "requests", "--group", "ci", "--concurrency", "16",
+			},
+			want: WorkerOptions{
+				QueueURL:    "kafka://k0:9092,k1:9092",
+				Subject:     "requests",
+				Group:       "ci",
+				Concurrency: 16,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			worker := &mockWorker{}
+
+			require.NoError(t, runWorkerCmd(workerTestDeps(worker, &mockResolver{}), tt.args...))
+
+			assert.Equal(t, tt.want, worker.opts)
+		})
+	}
+}
+
+func TestWorkerCmd_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		modify  func(deps *Dependencies)
+		wantMsg string
+	}{
+		{
+			name:    "not configured",
+			args:    []string{"--queue", "nats://nats:4222"},
+			modify:  func(deps *Dependencies) { deps.WorkerFactory = nil },
+			wantMsg: "worker mode not configured",
+		},
+		{name: "missing queue", wantMsg: "--queue is required"},
+		{
+			name:    "empty subject",
+			args:    []string{"--queue", "nats://nats:4222", "--subject", ""},
+			wantMsg: "--subject cannot be empty",
+		},
+		{
+			name:    "zero concurrency",
+			args:    []string{"--queue", "nats://nats:4222", "--concurrency", "0"},
+			wantMsg: "--concurrency must be at least 1",
+		},
+		{
+			name:    "zero depth",
+			args:    []string{"--queue", "nats://nats:4222", "--depth", "0"},
+			wantMsg: "--depth must be at least 1",
+		},
+		{
+			name: "store error",
+			args: []string{"--queue", "nats://nats:4222"},
+			modify: func(deps *Dependencies) {
+				deps.SlipFinderFactory = func(*AppConfig, Logger) (domain.SlipFinder, error) {
+					return nil, errors.New("connection refused")
+				}
+			},
+			wantMsg: "database error: connection refused",
+		},
+		{
+			name: "worker error",
+			args: []string{"--queue", "amqp://rabbit:5672"},
+			modify: func(deps *Dependencies) {
+				deps.WorkerFactory = func(WorkerOptions, ResolveRequestFunc, Logger) (Worker, error) {
+					return nil, errors.New("unsupported queue")
+				}
+			},
+			wantMsg: "worker configuration error: unsupported queue",
+		},
+		{
+			name: "run error",
+			args: []string{"--queue", "nats://nats:4222"},
+			modify: func(deps *Dependencies) {
+				deps.WorkerFactory = func(WorkerOptions, ResolveRequestFunc, Logger) (Worker, error) {
+					return &mockWorker{runErr: errors.New("NATS connection closed")}, nil
+				}
+			},
+			wantMsg: "NATS connection closed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := workerTestDeps(&mockWorker{}, &mockResolver{})
+			if tt.modify != nil {
+				tt.modify(deps)
+			}
+
+			err := runWorkerCmd(deps, tt.args...)
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantMsg)
+		})
+	}
+}
+
+func TestWorkerCmd_Resolve(t *testing.T) {
+	resolver := &mockResolver{output: &domain.ResolveOutput{CorrelationID: "slip-1"}}
+	finder := &mockSlipFinder{}
+	worker := &mockWorker{requests: []domain.ResolveRequest{
+		{Repository: "org/repo", Commits: []string{"c0"}},
+		{Path: "/builds/repo"},
+	}}
+	deps := workerTestDeps(worker, resolver)
+	deps.SlipFinderFactory = func(*AppConfig, Logger) (domain.SlipFinder, error) { return finder, nil }
+
+	require.NoError(t, runWorkerCmd(deps, "--queue", "nats://nats:4222", "--depth", "40"))
+
+	require.NoError(t, worker.errs[0])
+	assert.Equal(t, "slip-1", worker.results[0].CorrelationID)
+	assert.Equal(t, 40, resolver.input.Depth)
+	// Workers serve no repository roots, so path requests are refused
+	assert.Equal(t, domain.CodeInvalidInput, domain.CodeOf(worker.errs[1]))
+	assert.True(t, finder.closeCalled)
+}
@@ -0,0 +1,104 @@
+// Package argocd publishes resolution results to Argo CD Applications through
+// the Argo CD API.
+package argocd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// requestTimeout bounds one API call.
+const requestTimeout = 10 * time.Second
+
+// maxErrorBody caps how much of an error response is read for its message.
+const maxErrorBody = 4 << 10
+
+// Client calls the Argo CD API with a bearer token.
+type Client struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// patchRequest is the body of PATCH /api/v1/applications/{name}.
+type patchRequest struct {
+	Name         string `json:"name"`
+	AppNamespace string `json:"appNamespace,omitempty"`
+	Patch        string `json:"patch"`
+	PatchType    string `json:"patchType"`
+}
+
+// apiError is the body of a failed API call.
+type apiError struct {
+	Message string `json:"message"`
+}
+
+// NewClient creates a client for the Argo CD server at baseURL (e.g.
+// https://argocd.example.com), authenticating with token.
+func NewClient(baseURL, token string) *Client {
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		client:  &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// Annotate merges annotations into the metadata of the Application named app.
+// app is "name", or "namespace/name" for an Application outside Argo CD's own
+// namespace.
+func (c *Client) Annotate(ctx context.Context, app string, annotations map[string]string) error {
+	namespace, name, found := strings.Cut(app, "/")
+	if !found {
+		namespace, name = "", app
+	}
+	if name == "" || strings.Contains(name, "/") {
+		return fmt.Errorf("invalid Argo CD application %q: expected name or namespace/name", app)
+	}
+
+	patch, err := json.Marshal(map[string]any{"metadata": map[string]any{"annotations": annotations}})
+	if err != nil {
+		return fmt.Errorf("failed to encode Argo CD patch: %w", err)
+	}
+	body, err := json.Marshal(patchRequest{
+		Name:         name,
+		AppNamespace: namespace,
+		Patch:        string(patch),
+		PatchType:    "merge",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode Argo CD patch: %w", err)
+	}
+
+	endpoint := c.baseURL + "/api/v1/applications/" + url.PathEscape(name)
+	if namespace != "" {
+		endpoint += "?appNamespace=" + url.QueryEscape(namespace)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build Argo CD request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to patch Argo CD application %s: %w", app, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var apiErr apiError
+		_ = json.NewDecoder(io.LimitReader(resp.Body, maxErrorBody)).Decode(&apiErr)
+		if apiErr.Message != "" {
+			return fmt.Errorf("patch of Argo CD application %s rejected: %s: %s", app, resp.Status, apiErr.Message)
+		}
+		return fmt.Errorf("patch of Argo CD application %s rejected: %s", app, resp.Status)
+	}
+	return nil
+}
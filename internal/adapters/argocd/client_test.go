@@ -0,0 +1,95 @@
+package argocd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Annotate(t *testing.T) {
+	tests := []struct {
+		name          string
+		app           string
+		wantPath      string
+		wantNamespace string
+	}{
+		{name: "name", app: "payments-api", wantPath: "/api/v1/applications/payments-api"},
+		{
+			name:          "namespaced",
+			app:           "team-a/payments-api",
+			wantPath:      "/api/v1/applications/payments-api",
+			wantNamespace: "team-a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got patchRequest
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPatch, r.Method)
+				assert.Equal(t, tt.wantPath, r.URL.Path)
+				assert.Equal(t, tt.wantNamespace, r.URL.Query().Get("appNamespace"))
+				assert.Equal(t, "Bearer s3cret", r.Header.Get("Authorization"))
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+				_, _ = w.Write([]byte(`{"metadata":{"name":"payments-api"}}`))
+			}))
+			defer server.Close()
+
+			err := NewClient(server.URL+"/", "s3cret").Annotate(context.Background(), tt.app, map[string]string{
+				"slippy-find/correlation-id": "slip-1",
+			})
+
+			require.NoError(t, err)
+			assert.Equal(t, "payments-api", got.Name)
+			assert.Equal(t, tt.wantNamespace, got.AppNamespace)
+			assert.Equal(t, "merge", got.PatchType)
+			assert.JSONEq(t, `{"metadata":{"annotations":{"slippy-find/correlation-id":"slip-1"}}}`, got.Patch)
+		})
+	}
+}
+
+func TestClient_Annotate_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		app     string
+		status  int
+		body    string
+		wantMsg string
+	}{
+		{
+			name:    "not found",
+			app:     "payments-api",
+			status:  http.StatusNotFound,
+			body:    `{"error":"not found","code":5,"message":"applications.argoproj.io \"payments-api\" not found"}`,
+			wantMsg: `404 Not Found: applications.argoproj.io "payments-api" not found`,
+		},
+		{
+			name:    "no message",
+			app:     "payments-api",
+			status:  http.StatusForbidden,
+			wantMsg: "patch of Argo CD application payments-api rejected: 403 Forbidden",
+		},
+		{name: "empty name", app: "team-a/", wantMsg: `invalid Argo CD application "team-a/"`},
+		{name: "too many segments", app: "a/b/c", wantMsg: `invalid Argo CD application "a/b/c"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			err := NewClient(server.URL, "s3cret").Annotate(context.Background(), tt.app, map[string]string{"k": "v"})
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantMsg)
+		})
+	}
+}
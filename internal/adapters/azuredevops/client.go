@@ -0,0 +1,82 @@
+// Package azuredevops publishes resolution results to Azure Pipelines builds
+// through the Azure DevOps REST API.
+package azuredevops
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// apiVersion is the REST API version requested.
+const apiVersion = "7.1"
+
+// requestTimeout bounds one API call.
+const requestTimeout = 10 * time.Second
+
+// maxErrorBody caps how much of an error response is read for its message.
+const maxErrorBody = 4 << 10
+
+// Client calls the Azure DevOps REST API of one project with a personal access
+// token or a job access token.
+type Client struct {
+	projectURL string
+	token      string
+	client     *http.Client
+}
+
+// apiError is the body of a failed API call.
+type apiError struct {
+	Message string `json:"message"`
+}
+
+// NewClient creates a client for project in the organization or collection at
+// collectionURL (e.g. https://dev.azure.com/myorg), authenticating with token.
+func NewClient(collectionURL, project, token string) *Client {
+	return &Client{
+		projectURL: strings.TrimSuffix(collectionURL, "/") + "/" + url.PathEscape(project),
+		token:      token,
+		client:     &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// AddBuildTag tags the build with ID buildID, which makes it searchable by the
+// tag in the Azure DevOps UI. Adding a tag the build already has is a no-op.
+func (c *Client) AddBuildTag(ctx context.Context, buildID, tag string) error {
+	if strings.TrimSpace(tag) == "" {
+		return fmt.Errorf("invalid build tag %q", tag)
+	}
+	endpoint := fmt.Sprintf("%s/_apis/build/builds/%s/tags/%s?api-version=%s",
+		c.projectURL, url.PathEscape(buildID), url.PathEscape(tag), apiVersion)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, http.NoBody)
+	if err != nil {
+		return fmt.Errorf("failed to build Azure DevOps request: %w", err)
+	}
+	// Both kinds of token are accepted as the password of basic authentication
+	req.SetBasicAuth("", c.token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to tag Azure DevOps build %s: %w", buildID, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	switch {
+	case resp.StatusCode == http.StatusNonAuthoritativeInfo:
+		// An invalid token is answered with the sign-in page rather than a 401
+		return fmt.Errorf("tag of Azure DevOps build %s rejected: token not accepted", buildID)
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		var apiErr apiError
+		_ = json.NewDecoder(io.LimitReader(resp.Body, maxErrorBody)).Decode(&apiErr)
+		if apiErr.Message != "" {
+			return fmt.Errorf("tag of Azure DevOps build %s rejected: %s: %s", buildID, resp.Status, apiErr.Message)
+		}
+		return fmt.Errorf("tag of Azure DevOps build %s rejected: %s", buildID, resp.Status)
+	}
+	return nil
+}
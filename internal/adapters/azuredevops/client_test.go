@@ -0,0 +1,77 @@
+package azuredevops
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_AddBuildTag(t *testing.T) {
+	var requested string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		user, password, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Empty(t, user)
+		assert.Equal(t, "s3cret", password)
+		requested = r.URL.EscapedPath() + "?" + r.URL.RawQuery
+		_, _ = w.Write([]byte(`{"count":1,"value":["slip 1"]}`))
+	}))
+	defer server.Close()
+
+	err := NewClient(server.URL+"/myorg/", "my project", "s3cret").AddBuildTag(context.Background(), "42", "slip 1")
+
+	require.NoError(t, err)
+	assert.Equal(t, "/myorg/my%20project/_apis/build/builds/42/tags/slip%201?api-version=7.1", requested)
+}
+
+func TestClient_AddBuildTag_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		tag     string
+		status  int
+		body    string
+		wantMsg string
+	}{
+		{
+			name:    "not found",
+			tag:     "slip-1",
+			status:  http.StatusNotFound,
+			body:    `{"$id":"1","message":"The requested build 42 could not be found.","typeKey":"BuildNotFoundException"}`,
+			wantMsg: "tag of Azure DevOps build 42 rejected: 404 Not Found: The requested build 42 could not be found.",
+		},
+		{
+			name:    "no message",
+			tag:     "slip-1",
+			status:  http.StatusForbidden,
+			wantMsg: "tag of Azure DevOps build 42 rejected: 403 Forbidden",
+		},
+		{
+			name:    "token not accepted",
+			tag:     "slip-1",
+			status:  http.StatusNonAuthoritativeInfo,
+			body:    "<html>Sign In</html>",
+			wantMsg: "tag of Azure DevOps build 42 rejected: token not accepted",
+		},
+		{name: "empty tag", tag: " ", wantMsg: `invalid build tag " "`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			err := NewClient(server.URL, "proj-1", "s3cret").AddBuildTag(context.Background(), "42", tt.tag)
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantMsg)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+// Package buildkite publishes resolution results to Buildkite builds through
+// the buildkite-agent CLI.
+package buildkite
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// DefaultAgentPath is the agent binary used when none is given; the Buildkite
+// agent puts it on the PATH of every job.
+const DefaultAgentPath = "buildkite-agent"
+
+// Agent sets build meta-data by running `buildkite-agent meta-data set`, which
+// authenticates with the job's BUILDKITE_AGENT_ACCESS_TOKEN.
+type Agent struct {
+	path string
+}
+
+// NewAgent returns an Agent running the agent binary at path, or
+// DefaultAgentPath when path is empty.
+func NewAgent(path string) *Agent {
+	if path == "" {
+		path = DefaultAgentPath
+	}
+	return &Agent{path: path}
+}
+
+// SetMetaData sets each key of data on the current build, in key order,
+// stopping at the first failure. Buildkite rejects empty values, so keys with
+// an empty value are skipped.
+func (a *Agent) SetMetaData(ctx context.Context, data map[string]string) error {
+	for _, key := range slices.Sorted(maps.Keys(data)) {
+		value := data[key]
+		if value == "" {
+			continue
+		}
+		var out bytes.Buffer
+		cmd := exec.CommandContext(ctx, a.path, "meta-data", "set", key, value)
+		cmd.Stdout, cmd.Stderr = &out, &out
+		if err := cmd.Run(); err != nil {
+			if msg := strings.TrimSpace(out.String()); msg != "" {
+				return fmt.Errorf("failed to set meta-data %q: %w: %s", key, err, msg)
+			}
+			return fmt.Errorf("failed to set meta-data %q: %w", key, err)
+		}
+	}
+	return nil
+}
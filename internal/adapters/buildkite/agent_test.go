@@ -0,0 +1,81 @@
+package buildkite
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAgent writes a buildkite-agent stand-in that appends its arguments to a
+// log file and exits with code, returning its path and the log's.
+func fakeAgent(t *testing.T, code int) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "calls.log")
+	script := fmt.Sprintf("#!/bin/sh\necho \"$*\" >> '%s'\necho 'agent says no' >&2\nexit %d\n", logPath, code)
+	agentPath := filepath.Join(dir, "buildkite-agent")
+	require.NoError(t, os.WriteFile(agentPath, []byte(script), 0o755))
+	return agentPath, logPath
+}
+
+func TestNewAgent(t *testing.T) {
+	assert.Equal(t, DefaultAgentPath, NewAgent("").path)
+	assert.Equal(t, "/opt/bk/agent", NewAgent("/opt/bk/agent").path)
+}
+
+func TestAgent_SetMetaData(t *testing.T) {
+	agentPath, logPath := fakeAgent(t, 0)
+
+	err := NewAgent(agentPath).SetMetaData(context.Background(), map[string]string{
+		"slippy-repository":     "org/repo",
+		"slippy-correlation-id": "slip-1",
+		"slippy-component":      "",
+	})
+
+	require.NoError(t, err)
+	calls, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	assert.Equal(t,
+		"meta-data set slippy-correlation-id slip-1\nmeta-data set slippy-repository org/repo\n",
+		string(calls))
+}
+
+func TestAgent_SetMetaData_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		agentPath func(t *testing.T) string
+		wantMsg   string
+	}{
+		{
+			name: "agent fails",
+			agentPath: func(t *testing.T) string {
+				path, _ := fakeAgent(t, 1)
+				return path
+			},
+			wantMsg: `failed to set meta-data "slippy-correlation-id": exit status 1: agent says no`,
+		},
+		{
+			name: "agent missing",
+			agentPath: func(t *testing.T) string {
+				return filepath.Join(t.TempDir(), "buildkite-agent")
+			},
+			wantMsg: `failed to set meta-data "slippy-correlation-id"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewAgent(tt.agentPath(t)).SetMetaData(context.Background(), map[string]string{
+				"slippy-correlation-id": "slip-1",
+			})
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantMsg)
+		})
+	}
+}
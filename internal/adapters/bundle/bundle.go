@@ -0,0 +1,71 @@
+// Package bundle writes debug bundles: a single tar.gz archive of the files
+// describing a run, for attaching to support tickets.
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// Writer writes debug bundles, passing every file through a redaction
+// function first.
+type Writer struct {
+	redact func(string) string
+	now    func() time.Time
+}
+
+// NewWriter creates a Writer that scrubs file contents with redact.
+func NewWriter(redact func(string) string) *Writer {
+	return &Writer{redact: redact, now: time.Now}
+}
+
+// WriteBundle writes files (name -> contents) to a gzip-compressed tar archive
+// at path, in name order. The archive is readable only by its owner, and
+// replaces any existing file at path.
+func (w *Writer) WriteBundle(path string, files map[string][]byte) (err error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create debug bundle directory: %w", err)
+		}
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create debug bundle: %w", err)
+	}
+	defer func() {
+		err = errors.Join(err, f.Close())
+	}()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	modTime := w.now()
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		data := []byte(w.redact(string(files[name])))
+		header := &tar.Header{
+			Name:    name,
+			Mode:    0o600,
+			Size:    int64(len(data)),
+			ModTime: modTime,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write debug bundle: %w", err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			return fmt.Errorf("failed to write debug bundle: %w", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write debug bundle: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write debug bundle: %w", err)
+	}
+	return nil
+}
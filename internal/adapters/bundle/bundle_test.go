@@ -0,0 +1,86 @@
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readBundle returns the names and contents of the archive at path, in order.
+func readBundle(t *testing.T, path string) ([]string, map[string]string) {
+	t.Helper()
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+
+	var names []string
+	contents := map[string]string{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		data, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		names = append(names, header.Name)
+		contents[header.Name] = string(data)
+	}
+	return names, contents
+}
+
+func TestWriter_WriteBundle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "support", "bundle.tar.gz")
+	writer := NewWriter(func(s string) string { return strings.ReplaceAll(s, "hunter22", "REDACTED") })
+	writer.now = func() time.Time { return time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC) }
+
+	err := writer.WriteBundle(path, map[string][]byte{
+		"run.json":  []byte(`{"error":"password hunter22 rejected"}`),
+		"git.json":  []byte(`{"repository":"org/repo"}`),
+		"logs.json": nil,
+	})
+
+	require.NoError(t, err)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	names, contents := readBundle(t, path)
+	assert.Equal(t, []string{"git.json", "logs.json", "run.json"}, names)
+	assert.Equal(t, `{"error":"password REDACTED rejected"}`, contents["run.json"])
+	assert.Empty(t, contents["logs.json"])
+}
+
+func TestWriter_WriteBundle_Replaces(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	require.NoError(t, os.WriteFile(path, []byte("stale"), 0o600))
+	writer := NewWriter(func(s string) string { return s })
+
+	require.NoError(t, writer.WriteBundle(path, map[string][]byte{"run.json": []byte("{}")}))
+
+	names, _ := readBundle(t, path)
+	assert.Equal(t, []string{"run.json"}, names)
+}
+
+func TestWriter_WriteBundle_Unwritable(t *testing.T) {
+	dir := t.TempDir()
+	writer := NewWriter(func(s string) string { return s })
+
+	// A directory cannot be opened as the archive
+	err := writer.WriteBundle(dir, map[string][]byte{"run.json": []byte("{}")})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to create debug bundle")
+}
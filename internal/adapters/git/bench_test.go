@@ -0,0 +1,72 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/go-git/go-billy/v5/osfs"
+
+	"github.com/MyCarrier-DevOps/slippy-find/internal/benchrepo"
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/gitcontext"
+)
+
+// BenchmarkGoGitRepository_GetCommitAncestry times the adapter end to end on
+// packed repositories on disk, as slippy-find reads them in CI.
+func BenchmarkGoGitRepository_GetCommitAncestry(b *testing.B) {
+	for _, shape := range benchrepo.Shapes {
+		for _, n := range []int{1_000, 10_000, 100_000} {
+			b.Run(fmt.Sprintf("%s/%d", shape, n), func(b *testing.B) {
+				if n > 10_000 && testing.Short() {
+					b.Skip("large repository skipped in short mode")
+				}
+				dir := b.TempDir()
+				if _, err := benchrepo.WriteFS(osfs.New(dir), shape, n); err != nil {
+					b.Fatal(err)
+				}
+				repo, err := NewGoGitRepository(dir, &testLogger{})
+				if err != nil {
+					b.Fatal(err)
+				}
+				ctx := context.Background()
+				b.ReportAllocs()
+				for b.Loop() {
+					if _, err := repo.GetCommitAncestry(ctx, n); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkGoGitRepository_GetCommitAncestryCached times the adapter walking
+// a HEAD whose ancestry is already in the repository's ancestry cache, as in
+// the later stages of a pipeline.
+func BenchmarkGoGitRepository_GetCommitAncestryCached(b *testing.B) {
+	for _, n := range []int{1_000, 10_000, 100_000} {
+		b.Run(fmt.Sprintf("%s/%d", benchrepo.Linear, n), func(b *testing.B) {
+			if n > 10_000 && testing.Short() {
+				b.Skip("large repository skipped in short mode")
+			}
+			dir := b.TempDir()
+			if _, err := benchrepo.WriteFS(osfs.New(dir), benchrepo.Linear, n); err != nil {
+				b.Fatal(err)
+			}
+			repo, err := NewGoGitRepository(dir, &testLogger{}, gitcontext.WithAncestryCache())
+			if err != nil {
+				b.Fatal(err)
+			}
+			ctx := context.Background()
+			if _, err := repo.GetCommitAncestry(ctx, n); err != nil {
+				b.Fatal(err)
+			}
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := repo.GetCommitAncestry(ctx, n); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -1,18 +1,23 @@
 // Package git provides adapters for interacting with local Git repositories.
-// This package implements the domain.LocalGitRepository interface using go-git/v5.
+// This package implements the domain.LocalGitRepository interface on
+// pkg/gitcontext, adding logging, tracing, and slip recording.
 package git
 
 import (
 	"context"
-	"fmt"
-	"regexp"
-	"strings"
 
-	"github.com/go-git/go-git/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 
-	"github.com/MyCarrier-DevOps/slippy-find/internal/domain"
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/gitcontext"
 )
 
+// tracerName names the tracer for git walk spans.
+const tracerName = "github.com/MyCarrier-DevOps/slippy-find/internal/adapters/git"
+
 // Logger defines the logging interface for the git adapter.
 // This interface enables dependency injection and testability.
 type Logger interface {
@@ -23,18 +28,19 @@ type Logger interface {
 // GoGitRepository implements domain.LocalGitRepository using go-git/v5.
 // It provides local Git repository operations for commit ancestry resolution.
 type GoGitRepository struct {
-	repo   *git.Repository
+	repo   *gitcontext.Repository
 	path   string
 	logger Logger
 }
 
 // NewGoGitRepository creates a new GoGitRepository for the given path.
-// The path can be either a working directory or a bare repository.
+// The path can be either a working directory or a bare repository, opened
+// with opts, such as gitcontext.WithAncestryCache.
 // Returns domain.ErrRepositoryNotFound if the path is not a valid Git repository.
-func NewGoGitRepository(path string, log Logger) (*GoGitRepository, error) {
-	repo, err := git.PlainOpen(path)
+func NewGoGitRepository(path string, log Logger, opts ...gitcontext.Option) (*GoGitRepository, error) {
+	repo, err := gitcontext.Open(path, opts...)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %s", domain.ErrRepositoryNotFound, path)
+		return nil, err
 	}
 
 	return &GoGitRepository{
@@ -44,26 +50,23 @@ func NewGoGitRepository(path string, log Logger) (*GoGitRepository, error) {
 	}, nil
 }
 
+// NewGoGitRepositoryFrom creates a GoGitRepository for a repository already
+// opened with gitcontext, such as one from gitcontext.OpenFS.
+func NewGoGitRepositoryFrom(repo *gitcontext.Repository, log Logger) *GoGitRepository {
+	return &GoGitRepository{repo: repo, path: repo.Path(), logger: log}
+}
+
 // GetGitContext extracts all necessary context from the repository.
 // Returns GitContext with HEAD SHA, branch name, and repository name.
 // Logs a warning if HEAD is detached but continues with empty branch name.
 // Returns domain.ErrNoRemoteOrigin if no origin remote is configured.
 func (r *GoGitRepository) GetGitContext(ctx context.Context) (*domain.GitContext, error) {
-	// Get HEAD reference
-	head, err := r.repo.Head()
+	gitCtx, err := r.repo.Context(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+		return nil, err
 	}
 
-	gitCtx := &domain.GitContext{
-		HeadSHA:    head.Hash().String(),
-		IsDetached: !head.Name().IsBranch(),
-	}
-
-	// Get branch name if on a branch
-	if head.Name().IsBranch() {
-		gitCtx.Branch = head.Name().Short()
-	} else {
+	if gitCtx.IsDetached {
 		// HEAD is detached - warn but continue
 		r.logger.Warn(ctx, "HEAD is detached; branch name will be empty", map[string]interface{}{
 			"head_sha": gitCtx.HeadSHA,
@@ -71,28 +74,12 @@ func (r *GoGitRepository) GetGitContext(ctx context.Context) (*domain.GitContext
 		})
 	}
 
-	// Get repository name from origin remote
-	remote, err := r.repo.Remote("origin")
-	if err != nil {
-		return nil, fmt.Errorf("%w: failed to get origin remote: %w", domain.ErrNoRemoteOrigin, err)
-	}
-
-	urls := remote.Config().URLs
-	if len(urls) == 0 {
-		return nil, fmt.Errorf("%w: origin remote has no URLs configured", domain.ErrNoRemoteOrigin)
-	}
-
-	repoName, err := parseRepoFromURL(urls[0])
-	if err != nil {
-		return nil, fmt.Errorf("%w: failed to parse URL: %w", domain.ErrInvalidRemoteURL, err)
-	}
-	gitCtx.Repository = repoName
-
 	r.logger.Debug(ctx, "extracted git context", map[string]interface{}{
-		"head_sha":    gitCtx.HeadSHA,
-		"branch":      gitCtx.Branch,
-		"repository":  gitCtx.Repository,
-		"is_detached": gitCtx.IsDetached,
+		"head_sha":       gitCtx.HeadSHA,
+		"branch":         gitCtx.Branch,
+		"default_branch": gitCtx.DefaultBranch,
+		"repository":     gitCtx.Repository,
+		"is_detached":    gitCtx.IsDetached,
 	})
 
 	return gitCtx, nil
@@ -110,94 +97,185 @@ func (r *GoGitRepository) GetCommitAncestry(ctx context.Context, depth int) ([]s
 		depth = domain.DefaultAncestryDepth
 	}
 
-	// Get HEAD reference
-	head, err := r.repo.Head()
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "GoGitRepository.GetCommitAncestry", trace.WithAttributes(
+		attribute.Int("git.depth", depth),
+	))
+	defer span.End()
+
+	commits, err := r.repo.Ancestry(ctx, depth)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
 	}
+	span.SetAttributes(attribute.Int("git.commits", len(commits)))
 
-	// Get the commit object for HEAD
-	current, err := r.repo.CommitObject(head.Hash())
-	if err != nil {
-		return nil, fmt.Errorf("failed to get commit object for HEAD: %w", err)
+	r.logger.Debug(ctx, "walked commit ancestry (first-parent)", map[string]interface{}{
+		"depth_requested": depth,
+		"commits_count":   len(commits),
+		"head_sha":        commits[0],
+		"oldest_sha":      commits[len(commits)-1],
+	})
+
+	return commits, nil
+}
+
+// StreamCommitAncestry walks the first-parent chain from HEAD like
+// GetCommitAncestry, calling yield with each commit SHA as it is reached.
+// It implements domain.AncestryStreamer.
+func (r *GoGitRepository) StreamCommitAncestry(ctx context.Context, depth int, yield func(sha string) bool) error {
+	if depth <= 0 {
+		depth = domain.DefaultAncestryDepth
 	}
 
-	// Walk first-parent chain only (equivalent to git log --first-parent).
-	// For merge commits, parent 0 is the branch you were on when you ran
-	// git merge, and parent 1+ are the branches merged in.
-	var commits []string
-	for len(commits) < depth {
-		// Check context for cancellation
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		default:
-		}
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "GoGitRepository.StreamCommitAncestry", trace.WithAttributes(
+		attribute.Int("git.depth", depth),
+	))
+	defer span.End()
 
-		commits = append(commits, current.Hash.String())
+	walked := 0
+	err := r.repo.WalkAncestry(ctx, depth, func(sha string) bool {
+		walked++
+		return yield(sha)
+	})
+	span.SetAttributes(attribute.Int("git.commits", walked))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	r.logger.Debug(ctx, "streamed commit ancestry (first-parent)", map[string]interface{}{
+		"depth_requested": depth,
+		"commits_count":   walked,
+	})
+	return nil
+}
 
-		// Follow first parent only — stop at root commits
-		if current.NumParents() == 0 {
-			break
-		}
-		parent, err := current.Parent(0)
-		if err != nil {
-			break
-		}
-		current = parent
+// GetMergeBaseAncestry finds the merge base of HEAD and the given branch, then walks
+// the first-parent chain from that merge base, returning up to depth commit SHAs.
+// The branch is looked up as refs/remotes/origin/<branch> first, then refs/heads/<branch>.
+// Returns domain.ErrBranchNotFound if neither ref exists and domain.ErrNoMergeBase if
+// the histories are unrelated.
+func (r *GoGitRepository) GetMergeBaseAncestry(ctx context.Context, branch string, depth int) ([]string, error) {
+	if depth <= 0 {
+		depth = domain.DefaultAncestryDepth
 	}
 
-	if len(commits) == 0 {
-		return nil, domain.ErrEmptyAncestry
+	commits, err := r.repo.MergeBaseAncestry(ctx, branch, depth)
+	if err != nil {
+		return nil, err
 	}
 
-	r.logger.Debug(ctx, "walked commit ancestry (first-parent)", map[string]interface{}{
+	r.logger.Debug(ctx, "walked merge-base ancestry (first-parent)", map[string]interface{}{
+		"branch":          branch,
+		"merge_base":      commits[0],
 		"depth_requested": depth,
-		"commits_found":   len(commits),
-		"head_sha":        commits[0],
-		"oldest_sha":      commits[len(commits)-1],
+		"commits_count":   len(commits),
 	})
 
 	return commits, nil
 }
 
-// Close releases any resources held by the repository.
-// For go-git, this is a no-op as the repository doesn't hold persistent resources.
-func (r *GoGitRepository) Close() error {
-	return nil
+// GetPathAncestry walks the first-parent chain from HEAD and returns up to depth
+// commits whose diff against their first parent touches one of paths.
+// Root commits are compared against an empty tree. The walk continues past
+// non-matching commits until depth matches are found or history runs out.
+func (r *GoGitRepository) GetPathAncestry(ctx context.Context, paths []string, depth int) ([]string, error) {
+	if depth <= 0 {
+		depth = domain.DefaultAncestryDepth
+	}
+
+	commits, err := r.repo.With(gitcontext.WithPaths(paths...)).Ancestry(ctx, depth)
+	if err != nil {
+		return nil, err
+	}
+
+	r.logger.Debug(ctx, "walked path-filtered ancestry (first-parent)", map[string]interface{}{
+		"paths":           paths,
+		"depth_requested": depth,
+		"commits_count":   len(commits),
+	})
+
+	return commits, nil
 }
 
-// Regular expressions for parsing Git remote URLs.
-var (
-	// httpsURLPattern matches HTTPS URLs like:
-	// https://github.com/owner/repo.git
-	// https://github.com/owner/repo
-	httpsURLPattern = regexp.MustCompile(`^https?://[^/]+/([^/]+)/([^/]+?)(?:\.git)?$`)
-
-	// sshURLPattern matches SSH URLs like:
-	// git@github.com:owner/repo.git
-	// git@github.com:owner/repo
-	sshURLPattern = regexp.MustCompile(`^git@[^:]+:([^/]+)/([^/]+?)(?:\.git)?$`)
-)
+// GetPathAncestries is GetPathAncestry for several path sets at once, keyed by
+// name. It walks the first-parent chain from HEAD a single time, diffing each
+// commit once, until every key has depth commits or history runs out.
+// Keys whose paths no commit touches are absent from the result.
+func (r *GoGitRepository) GetPathAncestries(
+	ctx context.Context,
+	paths map[string][]string,
+	depth int,
+) (map[string][]string, error) {
+	if depth <= 0 {
+		depth = domain.DefaultAncestryDepth
+	}
 
-// parseRepoFromURL extracts owner/repo from a Git remote URL.
-// Supports both HTTPS and SSH formats:
-//   - https://github.com/owner/repo.git -> owner/repo
-//   - https://github.com/owner/repo -> owner/repo
-//   - git@github.com:owner/repo.git -> owner/repo
-//   - git@github.com:owner/repo -> owner/repo
-func parseRepoFromURL(url string) (string, error) {
-	url = strings.TrimSpace(url)
+	ancestries, err := r.repo.PathAncestries(ctx, paths, depth)
+	if err != nil {
+		return nil, err
+	}
+
+	r.logger.Debug(ctx, "walked path-filtered ancestries (first-parent)", map[string]interface{}{
+		"path_sets":       len(paths),
+		"depth_requested": depth,
+	})
 
-	// Try HTTPS pattern first
-	if matches := httpsURLPattern.FindStringSubmatch(url); len(matches) == 3 {
-		return matches[1] + "/" + matches[2], nil
+	return ancestries, nil
+}
+
+// GetAncestryFrom walks the first-parent chain from rev, returning up to depth commit SHAs.
+// rev is a full or abbreviated commit SHA or a branch name, looked up like the branch
+// in GetMergeBaseAncestry. Returns domain.ErrCommitNotFound if a SHA is not present
+// locally (e.g., a shallow checkout), domain.ErrAmbiguousCommit if an abbreviated SHA
+// matches several commits, and domain.ErrBranchNotFound for an unknown branch.
+func (r *GoGitRepository) GetAncestryFrom(ctx context.Context, rev string, depth int) ([]string, error) {
+	if depth <= 0 {
+		depth = domain.DefaultAncestryDepth
+	}
+
+	commits, err := r.repo.AncestryFrom(ctx, rev, depth)
+	if err != nil {
+		return nil, err
 	}
 
-	// Try SSH pattern
-	if matches := sshURLPattern.FindStringSubmatch(url); len(matches) == 3 {
-		return matches[1] + "/" + matches[2], nil
+	r.logger.Debug(ctx, "walked revision ancestry (first-parent)", map[string]interface{}{
+		"revision":        rev,
+		"start_sha":       commits[0],
+		"depth_requested": depth,
+		"commits_count":   len(commits),
+	})
+
+	return commits, nil
+}
+
+// ResolveCommit implements domain.CommitResolver, expanding an abbreviated
+// SHA or a branch name to the full SHA of its commit.
+func (r *GoGitRepository) ResolveCommit(ctx context.Context, rev string) (string, error) {
+	sha, err := r.repo.ResolveCommit(rev)
+	if err != nil {
+		return "", err
+	}
+	if sha != rev {
+		r.logger.Debug(ctx, "resolved revision to commit", map[string]interface{}{
+			"revision": rev,
+			"sha":      sha,
+		})
 	}
+	return sha, nil
+}
 
-	return "", fmt.Errorf("unrecognized URL format: %s", url)
+// DescribeEnvironment implements domain.EnvironmentDescriber.
+func (r *GoGitRepository) DescribeEnvironment(env *domain.Environment) {
+	env.GitBackend = domain.GitBackendGoGit
+	env.Shallow = r.repo.Shallow()
+	env.PartialClone = r.repo.PartialClone()
+	env.AncestryCache = r.repo.AncestryCacheState()
+}
+
+// Close releases the packfiles the repository keeps open between reads.
+func (r *GoGitRepository) Close() error {
+	return r.repo.Close()
 }
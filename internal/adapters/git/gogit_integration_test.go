@@ -15,7 +15,8 @@ import (
 
 	"github.com/MyCarrier-DevOps/goLibMyCarrier/logger"
 
-	"github.com/MyCarrier-DevOps/slippy-find/internal/domain"
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/gitcontext"
 )
 
 // testLogger is a minimal logger for testing that doesn't output anything.
@@ -236,6 +237,35 @@ func TestGoGitRepository_GetCommitAncestry_DepthLimit(t *testing.T) {
 	assert.Len(t, commits, 5)
 }
 
+func TestGoGitRepository_StreamCommitAncestry(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	for i := 0; i < 3; i++ {
+		testFile := filepath.Join(repoPath, "test.txt")
+		require.NoError(t, os.WriteFile(testFile, []byte("content "+string(rune('a'+i))), 0o644))
+		runGit(t, repoPath, "add", ".")
+		runGit(t, repoPath, "commit", "-m", "Commit "+string(rune('A'+i)))
+	}
+
+	repo, err := NewGoGitRepository(repoPath, &testLogger{})
+	require.NoError(t, err)
+	defer repo.Close()
+
+	ctx := context.Background()
+	want, err := repo.GetCommitAncestry(ctx, 10)
+	require.NoError(t, err)
+
+	var streamed []string
+	err = repo.StreamCommitAncestry(ctx, 10, func(sha string) bool {
+		streamed = append(streamed, sha)
+		return true
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, want, streamed)
+}
+
 func TestGoGitRepository_GetCommitAncestry_ZeroDepth(t *testing.T) {
 	repoPath, cleanup := setupTestRepo(t)
 	defer cleanup()
@@ -273,6 +303,27 @@ func TestGoGitRepository_GetCommitAncestry_ContextCancellation(t *testing.T) {
 	assert.ErrorIs(t, err, context.Canceled)
 }
 
+func TestGoGitRepository_AncestryCache(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	repo, err := NewGoGitRepository(repoPath, &testLogger{}, gitcontext.WithAncestryCache())
+	require.NoError(t, err)
+	defer func() { _ = repo.Close() }()
+
+	commits, err := repo.GetCommitAncestry(context.Background(), 0)
+	require.NoError(t, err)
+	streamed := 0
+	err = repo.StreamCommitAncestry(context.Background(), 0, func(string) bool {
+		streamed++
+		return true
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, len(commits), streamed)
+	assert.FileExists(t, filepath.Join(repoPath, ".git", gitcontext.AncestryCacheFile))
+}
+
 func TestGoGitRepository_Close(t *testing.T) {
 	repoPath, cleanup := setupTestRepo(t)
 	defer cleanup()
@@ -352,6 +403,202 @@ func TestGoGitRepository_GetCommitAncestry_FirstParentOnly(t *testing.T) {
 	assert.Equal(t, featureCommit2, commits[0], "HEAD should be the first commit")
 }
 
+func TestGoGitRepository_GetMergeBaseAncestry_Success(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	runGit(t, repoPath, "branch", "-M", "trunk")
+	baseCommit := getGitOutput(t, repoPath, "rev-parse", "HEAD")
+
+	// Advance trunk past the fork point
+	trunkFile := filepath.Join(repoPath, "trunk.txt")
+	require.NoError(t, os.WriteFile(trunkFile, []byte("trunk work"), 0o644))
+	runGit(t, repoPath, "add", ".")
+	runGit(t, repoPath, "commit", "-m", "Trunk commit")
+	trunkCommit := getGitOutput(t, repoPath, "rev-parse", "HEAD")
+
+	// Fork a feature branch from the base commit
+	runGit(t, repoPath, "checkout", "-b", "feature", baseCommit)
+	featureFile := filepath.Join(repoPath, "feature.txt")
+	require.NoError(t, os.WriteFile(featureFile, []byte("feature work"), 0o644))
+	runGit(t, repoPath, "add", ".")
+	runGit(t, repoPath, "commit", "-m", "Feature commit")
+
+	repo, err := NewGoGitRepository(repoPath, &testLogger{})
+	require.NoError(t, err)
+	defer repo.Close()
+
+	commits, err := repo.GetMergeBaseAncestry(context.Background(), "trunk", 10)
+
+	require.NoError(t, err)
+	require.NotEmpty(t, commits)
+	assert.Equal(t, baseCommit, commits[0], "walk should start at the merge base")
+	assert.NotContains(t, commits, trunkCommit)
+}
+
+func TestGoGitRepository_GetMergeBaseAncestry_BranchNotFound(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	repo, err := NewGoGitRepository(repoPath, &testLogger{})
+	require.NoError(t, err)
+	defer repo.Close()
+
+	commits, err := repo.GetMergeBaseAncestry(context.Background(), "does-not-exist", 10)
+
+	require.Error(t, err)
+	assert.Nil(t, commits)
+	assert.ErrorIs(t, err, domain.ErrBranchNotFound)
+}
+
+func TestGoGitRepository_GetMergeBaseAncestry_NoCommonHistory(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	defaultBranch := getGitOutput(t, repoPath, "branch", "--show-current")
+
+	// An orphan branch shares no history with the default branch
+	runGit(t, repoPath, "checkout", "--orphan", "orphan")
+	orphanFile := filepath.Join(repoPath, "orphan.txt")
+	require.NoError(t, os.WriteFile(orphanFile, []byte("orphan work"), 0o644))
+	runGit(t, repoPath, "add", ".")
+	runGit(t, repoPath, "commit", "-m", "Orphan commit")
+
+	repo, err := NewGoGitRepository(repoPath, &testLogger{})
+	require.NoError(t, err)
+	defer repo.Close()
+
+	_, err = repo.GetMergeBaseAncestry(context.Background(), defaultBranch, 10)
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, domain.ErrNoMergeBase)
+}
+
+func TestGoGitRepository_GetPathAncestry(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	commitFile := func(rel, content string) string {
+		full := filepath.Join(repoPath, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0o755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0o644))
+		runGit(t, repoPath, "add", ".")
+		runGit(t, repoPath, "commit", "-m", "change "+rel)
+		return getGitOutput(t, repoPath, "rev-parse", "HEAD")
+	}
+
+	payments1 := commitFile("services/payments/main.go", "v1")
+	commitFile("services/orders/main.go", "v1")
+	payments2 := commitFile("services/payments/main.go", "v2")
+	commitFile("README.md", "docs")
+
+	repo, err := NewGoGitRepository(repoPath, &testLogger{})
+	require.NoError(t, err)
+	defer repo.Close()
+
+	ctx := context.Background()
+
+	commits, err := repo.GetPathAncestry(ctx, []string{"services/payments"}, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{payments2, payments1}, commits)
+
+	commits, err = repo.GetPathAncestry(ctx, []string{"services/payments"}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{payments2}, commits)
+
+	// The initial commit (test.txt) is diffed against an empty tree
+	commits, err = repo.GetPathAncestry(ctx, []string{"test.txt"}, 10)
+	require.NoError(t, err)
+	assert.Len(t, commits, 1)
+
+	_, err = repo.GetPathAncestry(ctx, []string{"services/unknown"}, 10)
+	assert.ErrorIs(t, err, domain.ErrEmptyAncestry)
+}
+
+func TestGoGitRepository_GetPathAncestries(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	commitFile := func(rel, content string) string {
+		full := filepath.Join(repoPath, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0o755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0o644))
+		runGit(t, repoPath, "add", ".")
+		runGit(t, repoPath, "commit", "-m", "change "+rel)
+		return getGitOutput(t, repoPath, "rev-parse", "HEAD")
+	}
+
+	payments1 := commitFile("services/payments/main.go", "v1")
+	orders := commitFile("services/orders/main.go", "v1")
+	payments2 := commitFile("services/payments/main.go", "v2")
+
+	repo, err := NewGoGitRepository(repoPath, &testLogger{})
+	require.NoError(t, err)
+	defer repo.Close()
+
+	ancestries, err := repo.GetPathAncestries(context.Background(), map[string][]string{
+		"payments": {"services/payments"},
+		"orders":   {"services/orders"},
+		"services": {"services"},
+		"unknown":  {"services/unknown"},
+	}, 2)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"payments": {payments2, payments1},
+		"orders":   {orders},
+		"services": {payments2, orders},
+	}, ancestries)
+}
+
+func TestGoGitRepository_GetAncestryFrom(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	defaultBranch := getGitOutput(t, repoPath, "branch", "--show-current")
+	baseCommit := getGitOutput(t, repoPath, "rev-parse", "HEAD")
+
+	// A PR branch one commit ahead of the default branch
+	runGit(t, repoPath, "checkout", "-b", "pr")
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "pr.txt"), []byte("pr work"), 0o644))
+	runGit(t, repoPath, "add", ".")
+	runGit(t, repoPath, "commit", "-m", "PR commit")
+	prCommit := getGitOutput(t, repoPath, "rev-parse", "HEAD")
+	runGit(t, repoPath, "checkout", defaultBranch)
+
+	repo, err := NewGoGitRepository(repoPath, &testLogger{})
+	require.NoError(t, err)
+	defer repo.Close()
+
+	ctx := context.Background()
+
+	commits, err := repo.GetAncestryFrom(ctx, prCommit, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{prCommit, baseCommit}, commits)
+
+	commits, err = repo.GetAncestryFrom(ctx, "pr", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{prCommit}, commits)
+
+	commits, err = repo.GetAncestryFrom(ctx, defaultBranch, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{baseCommit}, commits)
+
+	_, err = repo.GetAncestryFrom(ctx, strings.Repeat("ab", 20), 10)
+	assert.ErrorIs(t, err, domain.ErrCommitNotFound)
+
+	_, err = repo.GetAncestryFrom(ctx, "does-not-exist", 10)
+	assert.ErrorIs(t, err, domain.ErrBranchNotFound)
+
+	commits, err = repo.GetAncestryFrom(ctx, prCommit[:7], 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{prCommit, baseCommit}, commits, "an abbreviated SHA")
+
+	sha, err := repo.ResolveCommit(ctx, prCommit[:7])
+	require.NoError(t, err)
+	assert.Equal(t, prCommit, sha)
+}
+
 // getGitOutput runs a git command and returns its trimmed stdout.
 func getGitOutput(t *testing.T, dir string, args ...string) string {
 	t.Helper()
@@ -0,0 +1,89 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// KnownAncestry implements domain.LocalGitRepository over a fixed list of
+// commits, for callers that send their ancestry instead of a checkout. Walks
+// that need the commit graph (merge bases, path filtering) are unavailable.
+type KnownAncestry struct {
+	repository string
+	branch     string
+	commits    []string
+}
+
+// NewKnownAncestry creates a KnownAncestry for repository (owner/repo) whose
+// HEAD ancestry is commits, newest first. An empty branch means detached HEAD.
+func NewKnownAncestry(repository, branch string, commits []string) *KnownAncestry {
+	return &KnownAncestry{repository: repository, branch: branch, commits: slices.Clone(commits)}
+}
+
+// GetGitContext returns the repository and branch given, with the first commit
+// as HEAD and its length giving the object format.
+func (k *KnownAncestry) GetGitContext(_ context.Context) (*domain.GitContext, error) {
+	gitCtx := &domain.GitContext{
+		Repository: k.repository,
+		Branch:     k.branch,
+		IsDetached: k.branch == "",
+	}
+	if len(k.commits) > 0 {
+		gitCtx.HeadSHA = k.commits[0]
+		gitCtx.ObjectFormat = domain.ObjectFormatOf(k.commits[0])
+	}
+	return gitCtx, nil
+}
+
+// GetCommitAncestry returns up to depth commits from HEAD.
+func (k *KnownAncestry) GetCommitAncestry(_ context.Context, depth int) ([]string, error) {
+	return firstN(k.commits, depth), nil
+}
+
+// GetMergeBaseAncestry returns domain.ErrHistoryUnavailable.
+func (k *KnownAncestry) GetMergeBaseAncestry(_ context.Context, branch string, _ int) ([]string, error) {
+	return nil, fmt.Errorf("%w: merge base with %s", domain.ErrHistoryUnavailable, branch)
+}
+
+// GetPathAncestry returns domain.ErrHistoryUnavailable.
+func (k *KnownAncestry) GetPathAncestry(_ context.Context, _ []string, _ int) ([]string, error) {
+	return nil, fmt.Errorf("%w: path-filtered ancestry", domain.ErrHistoryUnavailable)
+}
+
+// GetPathAncestries returns domain.ErrHistoryUnavailable.
+func (k *KnownAncestry) GetPathAncestries(
+	_ context.Context,
+	_ map[string][]string,
+	_ int,
+) (map[string][]string, error) {
+	return nil, fmt.Errorf("%w: path-filtered ancestry", domain.ErrHistoryUnavailable)
+}
+
+// GetAncestryFrom returns up to depth commits starting at rev, which must be
+// one of the known commits. Branch names cannot be resolved.
+// Returns domain.ErrCommitNotFound otherwise.
+func (k *KnownAncestry) GetAncestryFrom(_ context.Context, rev string, depth int) ([]string, error) {
+	i := slices.Index(k.commits, rev)
+	if i < 0 {
+		return nil, fmt.Errorf("%w: %s", domain.ErrCommitNotFound, rev)
+	}
+	return firstN(k.commits[i:], depth), nil
+}
+
+// DescribeEnvironment implements domain.EnvironmentDescriber.
+func (k *KnownAncestry) DescribeEnvironment(env *domain.Environment) {
+	env.GitBackend = domain.GitBackendCommitList
+}
+
+// Close does nothing; a KnownAncestry holds no resources.
+func (k *KnownAncestry) Close() error {
+	return nil
+}
+
+// firstN returns a copy of the first n commits, or all of them if there are fewer.
+func firstN(commits []string, n int) []string {
+	return slices.Clone(commits[:min(max(n, 0), len(commits))])
+}
@@ -0,0 +1,97 @@
+package git
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+func TestKnownAncestry_GetGitContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		branch  string
+		commits []string
+		want    *domain.GitContext
+	}{
+		{
+			name:    "on a branch",
+			branch:  "main",
+			commits: []string{"c0", "c1"},
+			want:    &domain.GitContext{Repository: "org/repo", Branch: "main", HeadSHA: "c0"},
+		},
+		{
+			name:    "detached",
+			commits: []string{"c0"},
+			want:    &domain.GitContext{Repository: "org/repo", HeadSHA: "c0", IsDetached: true},
+		},
+		{
+			name: "no commits",
+			want: &domain.GitContext{Repository: "org/repo", IsDetached: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewKnownAncestry("org/repo", tt.branch, tt.commits)
+
+			gitCtx, err := repo.GetGitContext(context.Background())
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, gitCtx)
+		})
+	}
+}
+
+func TestKnownAncestry_GetCommitAncestry(t *testing.T) {
+	repo := NewKnownAncestry("org/repo", "main", []string{"c0", "c1", "c2"})
+
+	tests := []struct {
+		name  string
+		depth int
+		want  []string
+	}{
+		{name: "within history", depth: 2, want: []string{"c0", "c1"}},
+		{name: "beyond history", depth: 10, want: []string{"c0", "c1", "c2"}},
+		{name: "zero depth", depth: 0, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commits, err := repo.GetCommitAncestry(context.Background(), tt.depth)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, commits)
+		})
+	}
+}
+
+func TestKnownAncestry_GetAncestryFrom(t *testing.T) {
+	repo := NewKnownAncestry("org/repo", "main", []string{"c0", "c1", "c2"})
+
+	commits, err := repo.GetAncestryFrom(context.Background(), "c1", 5)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c1", "c2"}, commits)
+
+	_, err = repo.GetAncestryFrom(context.Background(), "main", 5)
+	assert.ErrorIs(t, err, domain.ErrCommitNotFound)
+}
+
+func TestKnownAncestry_HistoryUnavailable(t *testing.T) {
+	repo := NewKnownAncestry("org/repo", "main", []string{"c0"})
+	ctx := context.Background()
+
+	_, err := repo.GetMergeBaseAncestry(ctx, "main", 5)
+	assert.ErrorIs(t, err, domain.ErrHistoryUnavailable)
+
+	_, err = repo.GetPathAncestry(ctx, []string{"src"}, 5)
+	assert.ErrorIs(t, err, domain.ErrHistoryUnavailable)
+
+	_, err = repo.GetPathAncestries(ctx, map[string][]string{"api": {"src"}}, 5)
+	assert.ErrorIs(t, err, domain.ErrHistoryUnavailable)
+
+	assert.NoError(t, repo.Close())
+}
@@ -0,0 +1,242 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// NotesRef is the notes ref resolved slips are recorded under, so
+// `git log --notes=slippy` shows them.
+const NotesRef = plumbing.ReferenceName("refs/notes/slippy")
+
+// TagPrefix prefixes the lightweight tags resolved slips are recorded as.
+const TagPrefix = "slippy/"
+
+// recorder signs the notes commits slippy-find writes.
+var recorder = object.Signature{Name: "slippy-find", Email: "slippy-find@localhost"}
+
+// RecordSlip implements domain.SlipRecorder: it records the slip as a note on
+// NotesRef or as a lightweight tag, then pushes the ref to origin if asked.
+//
+// When pushing a note, origin's notes are fetched first so the new note is
+// added on top of them rather than rejected as a non-fast-forward; local notes
+// that were never pushed are replaced.
+func (r *GoGitRepository) RecordSlip(ctx context.Context, record domain.SlipRecord) error {
+	if !plumbing.IsHash(record.Commit) {
+		return fmt.Errorf("%w: %s", domain.ErrCommitNotFound, record.Commit)
+	}
+	// Reads go through gitcontext; writes need go-git itself
+	repo, err := git.PlainOpen(r.path)
+	if err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrRepositoryNotFound, r.path)
+	}
+	w := refWriter{repo: repo}
+	commit := plumbing.NewHash(record.Commit)
+	if _, err := repo.CommitObject(commit); err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrCommitNotFound, record.Commit)
+	}
+
+	var ref plumbing.ReferenceName
+	switch record.Kind {
+	case domain.RecordNote:
+		ref = NotesRef
+		if record.Push {
+			if err := w.fetchRef(ctx, ref); err != nil {
+				return err
+			}
+		}
+		if err := w.addNote(commit, record.CorrelationID); err != nil {
+			return err
+		}
+	case domain.RecordTag:
+		ref = plumbing.NewTagReferenceName(TagPrefix + record.CorrelationID)
+		if err := w.setTag(ref, commit); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported record kind %q", record.Kind)
+	}
+
+	r.logger.Debug(ctx, "recorded resolved slip", map[string]interface{}{
+		"ref":            ref.String(),
+		"matched_commit": record.Commit,
+		"correlation_id": record.CorrelationID,
+	})
+	if !record.Push {
+		return nil
+	}
+	return w.pushRef(ctx, ref)
+}
+
+// refWriter writes the refs slips are recorded as.
+type refWriter struct {
+	repo *git.Repository
+}
+
+// addNote sets the note on commit to correlationID, replacing any earlier
+// note, in a new commit on NotesRef.
+func (w refWriter) addNote(commit plumbing.Hash, correlationID string) error {
+	s := w.repo.Storer
+	blob, err := storeBlob(s, correlationID+"\n")
+	if err != nil {
+		return err
+	}
+
+	var entries []object.TreeEntry
+	var parents []plumbing.Hash
+	old, err := w.repo.Reference(NotesRef, true)
+	switch {
+	case err == nil:
+		parent, err := w.repo.CommitObject(old.Hash())
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", NotesRef, err)
+		}
+		tree, err := parent.Tree()
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", NotesRef, err)
+		}
+		for _, entry := range tree.Entries {
+			if entry.Name != commit.String() {
+				entries = append(entries, entry)
+			}
+		}
+		parents = []plumbing.Hash{old.Hash()}
+	case errors.Is(err, plumbing.ErrReferenceNotFound):
+		old = nil
+	default:
+		return fmt.Errorf("failed to read %s: %w", NotesRef, err)
+	}
+	entries = append(entries, object.TreeEntry{Name: commit.String(), Mode: filemode.Regular, Hash: blob})
+	slices.SortFunc(entries, func(a, b object.TreeEntry) int {
+		return strings.Compare(treeSortName(a), treeSortName(b))
+	})
+
+	tree, err := storeObject(s, &object.Tree{Entries: entries})
+	if err != nil {
+		return err
+	}
+	sig := recorder
+	sig.When = time.Now()
+	notes, err := storeObject(s, &object.Commit{
+		Author:       sig,
+		Committer:    sig,
+		Message:      "Notes added by 'slippy-find --record-note'\n",
+		TreeHash:     tree,
+		ParentHashes: parents,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.CheckAndSetReference(plumbing.NewHashReference(NotesRef, notes), old); err != nil {
+		return fmt.Errorf("failed to update %s: %w", NotesRef, err)
+	}
+	return nil
+}
+
+// setTag points the lightweight tag ref at commit. An existing tag is left
+// alone if it already points there.
+func (w refWriter) setTag(ref plumbing.ReferenceName, commit plumbing.Hash) error {
+	existing, err := w.repo.Reference(ref, false)
+	switch {
+	case err == nil && existing.Hash() == commit:
+		return nil
+	case err == nil:
+		return fmt.Errorf("tag %s already points at %s", ref.Short(), existing.Hash())
+	case !errors.Is(err, plumbing.ErrReferenceNotFound):
+		return fmt.Errorf("failed to read tag %s: %w", ref.Short(), err)
+	}
+	if err := w.repo.Storer.SetReference(plumbing.NewHashReference(ref, commit)); err != nil {
+		return fmt.Errorf("failed to create tag %s: %w", ref.Short(), err)
+	}
+	return nil
+}
+
+// fetchRef force-updates ref from origin's copy. A ref origin does not have
+// yet is not an error.
+func (w refWriter) fetchRef(ctx context.Context, ref plumbing.ReferenceName) error {
+	err := w.repo.FetchContext(ctx, &git.FetchOptions{
+		RemoteName: "origin",
+		RefSpecs:   []config.RefSpec{config.RefSpec("+" + ref.String() + ":" + ref.String())},
+	})
+	var noMatch git.NoMatchingRefSpecError
+	if err == nil || errors.Is(err, git.NoErrAlreadyUpToDate) || errors.As(err, &noMatch) {
+		return nil
+	}
+	return fmt.Errorf("failed to fetch %s from origin: %w", ref, err)
+}
+
+// pushRef pushes ref to origin, with the credentials in origin's URL or, for
+// SSH remotes, from the SSH agent.
+func (w refWriter) pushRef(ctx context.Context, ref plumbing.ReferenceName) error {
+	err := w.repo.PushContext(ctx, &git.PushOptions{
+		RemoteName: "origin",
+		RefSpecs:   []config.RefSpec{config.RefSpec(ref.String() + ":" + ref.String())},
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("failed to push %s to origin: %w", ref, err)
+	}
+	return nil
+}
+
+// encodable is a git object go-git can serialize.
+type encodable interface {
+	Encode(o plumbing.EncodedObject) error
+}
+
+// storeObject writes obj to s, returning its hash.
+func storeObject(s storer.EncodedObjectStorer, obj encodable) (plumbing.Hash, error) {
+	encoded := s.NewEncodedObject()
+	if err := obj.Encode(encoded); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to encode object: %w", err)
+	}
+	hash, err := s.SetEncodedObject(encoded)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to write object: %w", err)
+	}
+	return hash, nil
+}
+
+// storeBlob writes content to s as a blob, returning its hash.
+func storeBlob(s storer.EncodedObjectStorer, content string) (plumbing.Hash, error) {
+	blob := s.NewEncodedObject()
+	blob.SetType(plumbing.BlobObject)
+	w, err := blob.Writer()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to write note: %w", err)
+	}
+	if _, err := w.Write([]byte(content)); err != nil {
+		_ = w.Close()
+		return plumbing.ZeroHash, fmt.Errorf("failed to write note: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to write note: %w", err)
+	}
+	hash, err := s.SetEncodedObject(blob)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to write note: %w", err)
+	}
+	return hash, nil
+}
+
+// treeSortName is the name git orders tree entries by: subtrees sort as if
+// their name ended in a slash.
+func treeSortName(entry object.TreeEntry) string {
+	if entry.Mode == filemode.Dir {
+		return entry.Name + "/"
+	}
+	return entry.Name
+}
@@ -0,0 +1,157 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// addCommit commits a change to dir and returns the new HEAD.
+func addCommit(t *testing.T, dir, content string) string {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "test.txt"), []byte(content), 0o644))
+	runGit(t, dir, "commit", "-am", content)
+	return getGitOutput(t, dir, "rev-parse", "HEAD")
+}
+
+// setupRecordRepo creates a repository whose origin is a local bare clone.
+// Returns the repository path and the bare remote's.
+func setupRecordRepo(t *testing.T) (string, string) {
+	t.Helper()
+	repoPath, cleanup := setupTestRepo(t)
+	t.Cleanup(cleanup)
+	remote := filepath.Join(t.TempDir(), "origin.git")
+	runGit(t, repoPath, "clone", "--bare", ".", remote)
+	runGit(t, repoPath, "remote", "set-url", "origin", remote)
+	return repoPath, remote
+}
+
+func TestGoGitRepository_RecordSlip_Note(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	first := getGitOutput(t, repoPath, "rev-parse", "HEAD")
+	second := addCommit(t, repoPath, "second")
+	repo, err := NewGoGitRepository(repoPath, &testLogger{})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	for _, record := range []domain.SlipRecord{
+		{Commit: first, CorrelationID: "slip-old", Kind: domain.RecordNote},
+		{Commit: second, CorrelationID: "slip-2", Kind: domain.RecordNote},
+		{Commit: first, CorrelationID: "slip-1", Kind: domain.RecordNote},
+	} {
+		require.NoError(t, repo.RecordSlip(ctx, record))
+	}
+
+	assert.Equal(t, "slip-1", getGitOutput(t, repoPath, "notes", "--ref", "slippy", "show", first),
+		"a later note replaces the earlier one")
+	assert.Equal(t, "slip-2", getGitOutput(t, repoPath, "notes", "--ref", "slippy", "show", second))
+	assert.Equal(t, "3", getGitOutput(t, repoPath, "rev-list", "--count", "refs/notes/slippy"))
+	runGit(t, repoPath, "fsck", "--strict")
+}
+
+func TestGoGitRepository_RecordSlip_Tag(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	head := getGitOutput(t, repoPath, "rev-parse", "HEAD")
+	other := addCommit(t, repoPath, "second")
+	repo, err := NewGoGitRepository(repoPath, &testLogger{})
+	require.NoError(t, err)
+	ctx := context.Background()
+	record := domain.SlipRecord{Commit: head, CorrelationID: "slip-1", Kind: domain.RecordTag}
+
+	require.NoError(t, repo.RecordSlip(ctx, record))
+	require.NoError(t, repo.RecordSlip(ctx, record), "re-recording the same commit is a no-op")
+
+	assert.Equal(t, head, getGitOutput(t, repoPath, "rev-parse", "slippy/slip-1"))
+	record.Commit = other
+	err = repo.RecordSlip(ctx, record)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tag slippy/slip-1 already points at "+head)
+}
+
+func TestGoGitRepository_RecordSlip_Push(t *testing.T) {
+	repoPath, remote := setupRecordRepo(t)
+	head := getGitOutput(t, repoPath, "rev-parse", "HEAD")
+	// Another build has already pushed a note for an earlier commit
+	runGit(t, remote, "-c", "user.name=CI", "-c", "user.email=ci@example.com",
+		"notes", "--ref", "slippy", "add", "-m", "slip-0", head)
+	next := addCommit(t, repoPath, "second")
+	runGit(t, repoPath, "push", "origin", "HEAD")
+	repo, err := NewGoGitRepository(repoPath, &testLogger{})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, repo.RecordSlip(ctx, domain.SlipRecord{
+		Commit: next, CorrelationID: "slip-1", Kind: domain.RecordNote, Push: true,
+	}))
+	require.NoError(t, repo.RecordSlip(ctx, domain.SlipRecord{
+		Commit: next, CorrelationID: "slip-1", Kind: domain.RecordTag, Push: true,
+	}))
+
+	assert.Equal(t, "slip-0", getGitOutput(t, remote, "notes", "--ref", "slippy", "show", head))
+	assert.Equal(t, "slip-1", getGitOutput(t, remote, "notes", "--ref", "slippy", "show", next))
+	assert.Equal(t, next, getGitOutput(t, remote, "rev-parse", "slippy/slip-1"))
+}
+
+func TestGoGitRepository_RecordSlip_Errors(t *testing.T) {
+	repoPath, cleanup := setupTestRepo(t)
+	defer cleanup()
+	head := getGitOutput(t, repoPath, "rev-parse", "HEAD")
+	repo, err := NewGoGitRepository(repoPath, &testLogger{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		record  domain.SlipRecord
+		wantErr error
+		wantMsg string
+	}{
+		{
+			name:    "not a sha",
+			record:  domain.SlipRecord{Commit: "main", CorrelationID: "slip-1", Kind: domain.RecordNote},
+			wantErr: domain.ErrCommitNotFound,
+		},
+		{
+			name: "unknown commit",
+			record: domain.SlipRecord{
+				Commit: "0123456789012345678901234567890123456789", CorrelationID: "slip-1", Kind: domain.RecordNote,
+			},
+			wantErr: domain.ErrCommitNotFound,
+		},
+		{
+			name:    "unknown kind",
+			record:  domain.SlipRecord{Commit: head, CorrelationID: "slip-1", Kind: "branch"},
+			wantMsg: `unsupported record kind "branch"`,
+		},
+		{
+			name:    "push without a reachable origin",
+			record:  domain.SlipRecord{Commit: head, CorrelationID: "slip-1", Kind: domain.RecordTag, Push: true},
+			wantMsg: "failed to push refs/tags/slippy/slip-1 to origin",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			if tt.record.Push {
+				cancel() // origin is on github.com; never dial it from tests
+			}
+			defer cancel()
+
+			err := repo.RecordSlip(ctx, tt.record)
+
+			require.Error(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+			assert.Contains(t, err.Error(), tt.wantMsg)
+		})
+	}
+}
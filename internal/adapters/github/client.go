@@ -0,0 +1,97 @@
+// Package github publishes resolution results to GitHub through its REST API.
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// apiVersion is the REST API version requested.
+const apiVersion = "2022-11-28"
+
+// requestTimeout bounds one API call.
+const requestTimeout = 10 * time.Second
+
+// maxErrorBody caps how much of an error response is read for its message.
+const maxErrorBody = 4 << 10
+
+// Client calls the GitHub REST API with a token.
+type Client struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// apiError is the body of a failed API call.
+type apiError struct {
+	Message string `json:"message"`
+}
+
+// NewClient creates a client for the REST API at baseURL (https://api.github.com,
+// or https://<host>/api/v3 for GitHub Enterprise Server), authenticating with token.
+func NewClient(baseURL, token string) *Client {
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		client:  &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// do sends a request to path with body (if not nil) encoded as JSON, and
+// decodes a 2xx response into out (if not nil). action names the call in errors.
+func (c *Client) do(ctx context.Context, method, path string, body, out any, action string) error {
+	var reader io.Reader = http.NoBody
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request to %s: %w", action, err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to build request to %s: %w", action, err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("X-GitHub-Api-Version", apiVersion)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to %s: %w", action, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var apiErr apiError
+		_ = json.NewDecoder(io.LimitReader(resp.Body, maxErrorBody)).Decode(&apiErr)
+		if apiErr.Message != "" {
+			return fmt.Errorf("GitHub rejected request to %s: %s: %s", action, resp.Status, apiErr.Message)
+		}
+		return fmt.Errorf("GitHub rejected request to %s: %s", action, resp.Status)
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to decode response to %s: %w", action, err)
+		}
+	}
+	return nil
+}
+
+// repoPath returns the API path of repository (owner/repo).
+func repoPath(repository string) (string, error) {
+	owner, name, ok := strings.Cut(repository, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return "", fmt.Errorf("invalid GitHub repository %q: expected owner/repo", repository)
+	}
+	return "/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(name), nil
+}
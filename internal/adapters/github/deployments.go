@@ -0,0 +1,97 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// PayloadCorrelationID is the deployment payload key holding the correlation ID.
+const PayloadCorrelationID = "correlation_id"
+
+// Deployment is a GitHub Deployment to create.
+type Deployment struct {
+	// Repository is the repository to deploy (owner/repo).
+	Repository string
+
+	// Ref is the commit SHA deployed.
+	Ref string
+
+	// Environment is the deployment environment, e.g. "production".
+	Environment string
+
+	// Payload is attached to the deployment. It must hold PayloadCorrelationID.
+	Payload map[string]string
+}
+
+// deploymentJSON is a deployment as the API returns it. The payload is
+// whatever its creator sent, so it is decoded separately.
+type deploymentJSON struct {
+	ID      int64           `json:"id"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// correlationID returns the correlation ID in the deployment's payload, or ""
+// if the payload holds none.
+func (d deploymentJSON) correlationID() string {
+	var payload map[string]any
+	if err := json.Unmarshal(d.Payload, &payload); err != nil {
+		return ""
+	}
+	id, _ := payload[PayloadCorrelationID].(string)
+	return id
+}
+
+// createDeploymentRequest is the body of POST /repos/{owner}/{repo}/deployments.
+type createDeploymentRequest struct {
+	Ref              string            `json:"ref"`
+	Environment      string            `json:"environment"`
+	Payload          map[string]string `json:"payload"`
+	Description      string            `json:"description"`
+	AutoMerge        bool              `json:"auto_merge"`
+	RequiredContexts []string          `json:"required_contexts"`
+}
+
+// CreateDeployment creates deployment, so the slip appears in the repository's
+// deployment timeline. When a deployment of the same commit to the same
+// environment already carries the same correlation ID, as on a re-run job, it
+// is reused instead. Returns the deployment's ID, for reporting its status.
+//
+// The deployment skips GitHub's merge and commit status checks: the slip has
+// already been resolved for the commit, and the deployment records it.
+func (c *Client) CreateDeployment(ctx context.Context, deployment Deployment) (int64, error) {
+	repo, err := repoPath(deployment.Repository)
+	if err != nil {
+		return 0, err
+	}
+	query := url.Values{
+		"sha":         {deployment.Ref},
+		"environment": {deployment.Environment},
+		"per_page":    {"100"},
+	}
+	var existing []deploymentJSON
+	if err := c.do(ctx, http.MethodGet, repo+"/deployments?"+query.Encode(), nil, &existing,
+		"list deployments"); err != nil {
+		return 0, err
+	}
+	for _, d := range existing {
+		if id := d.correlationID(); id != "" && id == deployment.Payload[PayloadCorrelationID] {
+			return d.ID, nil
+		}
+	}
+
+	var created deploymentJSON
+	err = c.do(ctx, http.MethodPost, repo+"/deployments", createDeploymentRequest{
+		Ref:              deployment.Ref,
+		Environment:      deployment.Environment,
+		Payload:          deployment.Payload,
+		Description:      fmt.Sprintf("slip %s", deployment.Payload[PayloadCorrelationID]),
+		RequiredContexts: []string{},
+	}, &created, "create deployment")
+	if err != nil {
+		return 0, err
+	}
+	return created.ID, nil
+}
@@ -0,0 +1,132 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// deploymentsServer serves GET and POST /repos/org/repo/deployments: GET
+// answers with existing, and POST records the request and creates ID 7.
+func deploymentsServer(t *testing.T, existing string, created *createDeploymentRequest) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/repos/org/repo/deployments", r.URL.Path)
+		assert.Equal(t, "Bearer s3cret", r.Header.Get("Authorization"))
+		assert.Equal(t, apiVersion, r.Header.Get("X-GitHub-Api-Version"))
+		switch r.Method {
+		case http.MethodGet:
+			assert.Equal(t, "c1", r.URL.Query().Get("sha"))
+			assert.Equal(t, "production", r.URL.Query().Get("environment"))
+			_, _ = w.Write([]byte(existing))
+		case http.MethodPost:
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(created))
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":7}`))
+		default:
+			t.Errorf("unexpected %s", r.Method)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// testDeployment is the deployment of slip-1 created by the tests.
+var testDeployment = Deployment{
+	Repository:  "org/repo",
+	Ref:         "c1",
+	Environment: "production",
+	Payload:     map[string]string{PayloadCorrelationID: "slip-1"},
+}
+
+func TestClient_CreateDeployment(t *testing.T) {
+	tests := []struct {
+		name        string
+		existing    string
+		wantID      int64
+		wantCreated bool
+	}{
+		{name: "first deployment", existing: `[]`, wantID: 7, wantCreated: true},
+		{
+			name:        "earlier deployments of other slips",
+			existing:    `[{"id":3,"payload":{"correlation_id":"slip-0"}},{"id":4,"payload":""},{"id":5,"payload":{}}]`,
+			wantID:      7,
+			wantCreated: true,
+		},
+		{
+			name:     "re-run of the same slip",
+			existing: `[{"id":3,"payload":{"correlation_id":"slip-0"}},{"id":5,"payload":{"correlation_id":"slip-1"}}]`,
+			wantID:   5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created createDeploymentRequest
+			server := deploymentsServer(t, tt.existing, &created)
+
+			id, err := NewClient(server.URL+"/", "s3cret").CreateDeployment(context.Background(), testDeployment)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantID, id)
+			if !tt.wantCreated {
+				assert.Empty(t, created.Ref)
+				return
+			}
+			assert.Equal(t, createDeploymentRequest{
+				Ref:              "c1",
+				Environment:      "production",
+				Payload:          map[string]string{"correlation_id": "slip-1"},
+				Description:      "slip slip-1",
+				RequiredContexts: []string{},
+			}, created)
+		})
+	}
+}
+
+func TestClient_CreateDeployment_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		repository string
+		status     int
+		body       string
+		wantMsg    string
+	}{
+		{
+			name:       "no access",
+			repository: "org/repo",
+			status:     http.StatusNotFound,
+			body:       `{"message":"Not Found","documentation_url":"https://docs.github.com/rest"}`,
+			wantMsg:    "GitHub rejected request to list deployments: 404 Not Found: Not Found",
+		},
+		{
+			name:       "no message",
+			repository: "org/repo",
+			status:     http.StatusForbidden,
+			wantMsg:    "GitHub rejected request to list deployments: 403 Forbidden",
+		},
+		{name: "not owner/repo", repository: "repo", wantMsg: `invalid GitHub repository "repo"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+			deployment := testDeployment
+			deployment.Repository = tt.repository
+
+			_, err := NewClient(server.URL, "s3cret").CreateDeployment(context.Background(), deployment)
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantMsg)
+		})
+	}
+}
@@ -3,6 +3,9 @@ package logger
 
 import (
 	"context"
+	"maps"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
 )
 
 // Logger defines the logging interface used throughout the application.
@@ -24,6 +27,12 @@ func NewZapAdapter(log Logger) *ZapAdapter {
 	return &ZapAdapter{log: log}
 }
 
+// SetLogger replaces the wrapped logger, e.g. once command-line flags have
+// changed the logging configuration. It must not race with logging calls.
+func (a *ZapAdapter) SetLogger(log Logger) {
+	a.log = log
+}
+
 // Info logs an info message.
 func (a *ZapAdapter) Info(ctx context.Context, msg string, fields map[string]any) {
 	a.log.Info(ctx, msg, fields)
@@ -39,7 +48,15 @@ func (a *ZapAdapter) Warn(ctx context.Context, msg string, fields map[string]any
 	a.log.Warn(ctx, msg, fields)
 }
 
-// Error logs an error message.
+// Error logs an error message, adding the error's domain.ErrorCode as the
+// error_code field. The caller's fields map is not modified.
 func (a *ZapAdapter) Error(ctx context.Context, msg string, err error, fields map[string]any) {
+	if err != nil {
+		fields = maps.Clone(fields)
+		if fields == nil {
+			fields = make(map[string]any, 1)
+		}
+		fields["error_code"] = string(domain.CodeOf(err))
+	}
 	a.log.Error(ctx, msg, err, fields)
 }
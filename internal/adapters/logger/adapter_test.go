@@ -2,9 +2,13 @@ package logger
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
 )
 
 // mockLogger implements Logger interface for testing.
@@ -50,6 +54,17 @@ func TestNewZapAdapter(t *testing.T) {
 	assert.NotNil(t, adapter)
 }
 
+func TestZapAdapter_SetLogger(t *testing.T) {
+	first, second := &mockLogger{}, &mockLogger{}
+	adapter := NewZapAdapter(first)
+
+	adapter.SetLogger(second)
+	adapter.Info(context.Background(), "after swap", nil)
+
+	assert.False(t, first.infoCalled)
+	assert.True(t, second.infoCalled)
+}
+
 func TestZapAdapter_Info(t *testing.T) {
 	mock := &mockLogger{}
 	adapter := NewZapAdapter(mock)
@@ -101,5 +116,36 @@ func TestZapAdapter_Error(t *testing.T) {
 	assert.True(t, mock.errorCalled)
 	assert.Equal(t, "error message", mock.lastMsg)
 	assert.Equal(t, testErr, mock.lastErr)
-	assert.Equal(t, fields, mock.lastFields)
+	assert.Equal(t, map[string]any{"error_context": "test", "error_code": "SLIPPY_E000"}, mock.lastFields)
+	assert.Equal(t, map[string]any{"error_context": "test"}, fields, "caller's fields must not change")
+}
+
+func TestZapAdapter_Error_Code(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode string
+	}{
+		{
+			name:     "domain sentinel",
+			err:      fmt.Errorf("failed to get git context: %w", domain.ErrNoRemoteOrigin),
+			wantCode: "SLIPPY_E002",
+		},
+		{
+			name:     "attached code",
+			err:      domain.WithCode(domain.CodeStore, errors.New("connection refused")),
+			wantCode: "SLIPPY_E014",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockLogger{}
+			adapter := NewZapAdapter(mock)
+
+			adapter.Error(context.Background(), "failed", tt.err, nil)
+
+			assert.Equal(t, map[string]any{"error_code": tt.wantCode}, mock.lastFields)
+		})
+	}
 }
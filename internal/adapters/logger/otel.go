@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"go.opentelemetry.io/contrib/bridges/otelzap"
+	"go.opentelemetry.io/otel/log"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// NewOTelCore returns a core that emits entries as OpenTelemetry log records
+// through provider, for use as one of NewZapLogger's extra cores. Records are
+// scoped by logger name, the application name.
+func NewOTelCore(provider log.LoggerProvider, version string) zapcore.Core {
+	return otelzap.NewCore(defaultAppName, otelzap.WithLoggerProvider(provider), otelzap.WithVersion(version))
+}
+
+// redactingCore wraps an extra core that receives structured fields rather
+// than encoded output, redacting the message and field values itself and
+// applying the logger's level.
+type redactingCore struct {
+	zapcore.Core
+
+	level    zapcore.LevelEnabler
+	redactor *Redactor
+}
+
+// Enabled reports whether both the logger's level and the wrapped core allow level.
+func (c redactingCore) Enabled(level zapcore.Level) bool {
+	return c.level.Enabled(level) && c.Core.Enabled(level)
+}
+
+// With adds redacted context fields.
+func (c redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return redactingCore{Core: c.Core.With(c.redactFields(fields)), level: c.level, redactor: c.redactor}
+}
+
+// Check adds the core to ce if the entry's level is enabled.
+func (c redactingCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return ce.AddCore(entry, c)
+	}
+	return ce
+}
+
+// Write redacts the entry and passes it to the wrapped core.
+func (c redactingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = c.redactor.Redact(entry.Message)
+	return c.Core.Write(entry, c.redactFields(fields))
+}
+
+// redactFields returns fields with secrets removed from every value that
+// renders as text. A structured value containing a secret is replaced by its
+// redacted JSON.
+func (c redactingCore) redactFields(fields []zapcore.Field) []zapcore.Field {
+	redacted := make([]zapcore.Field, len(fields))
+	for i, f := range fields {
+		switch f.Type {
+		case zapcore.StringType:
+			f.String = c.redactor.Redact(f.String)
+		case zapcore.ErrorType:
+			if err, ok := f.Interface.(error); ok && err != nil {
+				f = zap.String(f.Key, c.redactor.Redact(err.Error()))
+			}
+		case zapcore.StringerType:
+			f = zap.String(f.Key, c.redactor.Redact(fmt.Sprint(f.Interface)))
+		case zapcore.ReflectType:
+			if data, err := json.Marshal(f.Interface); err == nil {
+				if text := c.redactor.Redact(string(data)); text != string(data) {
+					f = zap.String(f.Key, text)
+				}
+			}
+		}
+		redacted[i] = f
+	}
+	return redacted
+}
@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// recordingExporter implements sdklog.Exporter, keeping exported records.
+type recordingExporter struct {
+	mu      sync.Mutex
+	records []sdklog.Record
+}
+
+func (e *recordingExporter) Export(_ context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, r := range records {
+		e.records = append(e.records, r.Clone())
+	}
+	return nil
+}
+
+func (e *recordingExporter) Shutdown(context.Context) error   { return nil }
+func (e *recordingExporter) ForceFlush(context.Context) error { return nil }
+
+func TestRedactingCore(t *testing.T) {
+	observed, logs := observer.New(zapcore.DebugLevel)
+	core := redactingCore{Core: observed, level: zapcore.InfoLevel, redactor: NewRedactor("hunter22")}
+	log := zap.New(core).With(zap.String("dsn", "clickhouse://default:hunter22@ch:9000"))
+
+	log.Debug("hidden by the level")
+	log.Info("password hunter22 rejected",
+		zap.Error(errors.New("auth failed: hunter22")),
+		zap.Any("settings", map[string]string{"password": "hunter22"}),
+		zap.Any("depth", 25),
+		zap.Stringer("id", stringer("slip-hunter22")),
+	)
+
+	require.Equal(t, 1, logs.Len())
+	entry := logs.All()[0]
+	assert.Equal(t, "password REDACTED rejected", entry.Message)
+	fields := entry.ContextMap()
+	assert.Equal(t, "clickhouse://REDACTED@ch:9000", fields["dsn"])
+	assert.Equal(t, "auth failed: REDACTED", fields["error"])
+	assert.Equal(t, `{"password":"REDACTED"}`, fields["settings"])
+	assert.Equal(t, int64(25), fields["depth"])
+	assert.Equal(t, "slip-REDACTED", fields["id"])
+}
+
+// stringer is a fmt.Stringer for zap.Stringer fields.
+type stringer string
+
+func (s stringer) String() string { return string(s) }
+
+func TestNewZapLogger_OTelCore(t *testing.T) {
+	t.Setenv(EnvLogFormat, "")
+	t.Setenv(EnvLogLevel, "info")
+	t.Setenv(EnvLogFile, "")
+	t.Setenv("CLICKHOUSE_PASSWORD", "hunter22")
+	exporter := &recordingExporter{}
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(exporter)))
+
+	log, closeLog, err := NewZapLogger(NewLevel(), NewOTelCore(provider, "1.0.0"))
+	require.NoError(t, err)
+	log.Debug(context.Background(), "below the level", nil)
+	log.Info(context.Background(), "connected with hunter22", map[string]any{"repository": "org/repo"})
+	require.NoError(t, closeLog())
+	require.NoError(t, provider.Shutdown(context.Background()))
+
+	require.Len(t, exporter.records, 1)
+	record := exporter.records[0]
+	assert.Equal(t, "connected with REDACTED", record.Body().AsString())
+	assert.Equal(t, "slippy-find", record.InstrumentationScope().Name)
+	assert.Equal(t, "1.0.0", record.InstrumentationScope().Version)
+}
@@ -0,0 +1,164 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	golib "github.com/MyCarrier-DevOps/goLibMyCarrier/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Environment variables read by NewZapLogger.
+const (
+	// EnvLogLevel is the log level (debug, info, error).
+	EnvLogLevel = "LOG_LEVEL"
+	// EnvLogFormat is the log encoding, FormatJSON or FormatConsole.
+	EnvLogFormat = "LOG_FORMAT"
+	// EnvLogAppName names the logger.
+	EnvLogAppName = "LOG_APP_NAME"
+	// EnvLogFile is a file that receives a copy of the logs, in addition to stderr.
+	EnvLogFile = "LOG_FILE"
+	// EnvLogFileMaxSize is the size in megabytes at which EnvLogFile is rotated.
+	EnvLogFileMaxSize = "LOG_FILE_MAX_SIZE_MB"
+	// EnvLogFileMaxBackups is the number of rotated log files kept.
+	EnvLogFileMaxBackups = "LOG_FILE_MAX_BACKUPS"
+)
+
+// Log file rotation defaults.
+const (
+	DefaultLogFileMaxSize    = 50
+	DefaultLogFileMaxBackups = 3
+)
+
+// Log encodings accepted by ParseFormat.
+const (
+	// FormatJSON writes one JSON object per line, for log pipelines (the default).
+	FormatJSON = "json"
+	// FormatConsole writes human-readable, tab-separated lines.
+	FormatConsole = "console"
+)
+
+// defaultAppName names the logger when EnvLogAppName is unset.
+const defaultAppName = "slippy-find"
+
+// ErrInvalidLogFormat indicates an unknown log format.
+var ErrInvalidLogFormat = errors.New("invalid log format")
+
+// ErrInvalidLogFile indicates unusable log file rotation settings.
+var ErrInvalidLogFile = errors.New("invalid log file setting")
+
+// CloseFunc releases the resources held by a logger, such as its log file.
+type CloseFunc func() error
+
+// ParseFormat validates a log format name. Empty selects FormatJSON.
+func ParseFormat(format string) (string, error) {
+	switch f := strings.ToLower(strings.TrimSpace(format)); f {
+	case "":
+		return FormatJSON, nil
+	case FormatJSON, FormatConsole:
+		return f, nil
+	default:
+		return "", fmt.Errorf("%w %q: expected %s or %s", ErrInvalidLogFormat, format, FormatJSON, FormatConsole)
+	}
+}
+
+// NewLevel returns a log level set from EnvLogLevel, for NewZapLogger. Loggers
+// built with it follow later changes to it, so --verbose can enable debug
+// logging in loggers already in use without changing the process environment.
+// Changing it is safe for concurrent use.
+func NewLevel() zap.AtomicLevel {
+	return golib.ConfigureLogLevelLogger(os.Getenv(EnvLogLevel)).Level
+}
+
+// NewZapLogger builds a zap logger writing to stderr at level, configured from
+// EnvLogFormat and EnvLogAppName. It matches goLibMyCarrier's app logger apart
+// from the selectable encoding and the shared level.
+//
+// When EnvLogFile is set, every entry is also written to that file, which is
+// rotated once it exceeds EnvLogFileMaxSize megabytes. The returned CloseFunc
+// closes the file and must be called once the logger is no longer used.
+//
+// Every entry is scrubbed of the values of SecretEnvVars, bearer tokens, and
+// URL credentials before it is written, whichever field carries them.
+//
+// Entries are also passed to each of extra (e.g. NewOTelCore) at the same
+// level, redacted the same way.
+func NewZapLogger(level zap.AtomicLevel, extra ...zapcore.Core) (*golib.ZapLogger, CloseFunc, error) {
+	format, err := ParseFormat(os.Getenv(EnvLogFormat))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	config := zap.NewProductionConfig()
+	config.Level = level
+	config.EncoderConfig.EncodeTime = zapcore.RFC3339NanoTimeEncoder
+	encoder := zapcore.NewJSONEncoder(config.EncoderConfig)
+	if format == FormatConsole {
+		config.EncoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+		encoder = zapcore.NewConsoleEncoder(config.EncoderConfig)
+	}
+	redactor := NewRedactor(SecretsFromEnv()...)
+	encoder = redactingEncoder{Encoder: encoder, redactor: redactor}
+
+	appName := os.Getenv(EnvLogAppName)
+	if appName == "" {
+		appName = defaultAppName
+	}
+
+	// stdout carries the correlation ID, so logs must stay on stderr. The core
+	// is sampled like zap's production logger; the file copy is not.
+	stderr := zapcore.Lock(os.Stderr)
+	core := zapcore.NewCore(encoder, stderr, config.Level)
+	if sampling := config.Sampling; sampling != nil {
+		core = zapcore.NewSamplerWithOptions(core, time.Second, sampling.Initial, sampling.Thereafter)
+	}
+
+	closeFile := func() error { return nil }
+	if path := os.Getenv(EnvLogFile); path != "" {
+		file, err := newLogFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		core = zapcore.NewTee(core, zapcore.NewCore(encoder.Clone(), zapcore.AddSync(file), config.Level))
+		closeFile = file.Close
+	}
+	for _, c := range extra {
+		core = zapcore.NewTee(core, redactingCore{Core: c, level: config.Level, redactor: redactor})
+	}
+
+	log := zap.New(core, zap.ErrorOutput(stderr), zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel))
+	return golib.NewZapLogger(log.Named(appName).Sugar()), closeFile, nil
+}
+
+// newLogFile returns a writer appending to path, rotated per
+// EnvLogFileMaxSize and EnvLogFileMaxBackups. The file is opened on first write.
+func newLogFile(path string) (*lumberjack.Logger, error) {
+	maxSize, err := positiveEnvInt(EnvLogFileMaxSize, DefaultLogFileMaxSize)
+	if err != nil {
+		return nil, err
+	}
+	maxBackups, err := positiveEnvInt(EnvLogFileMaxBackups, DefaultLogFileMaxBackups)
+	if err != nil {
+		return nil, err
+	}
+	return &lumberjack.Logger{Filename: path, MaxSize: maxSize, MaxBackups: maxBackups}, nil
+}
+
+// positiveEnvInt reads a positive integer from envVar, or fallback when unset.
+func positiveEnvInt(envVar string, fallback int) (int, error) {
+	raw := os.Getenv(envVar)
+	if raw == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%w: %s=%q must be a positive integer", ErrInvalidLogFile, envVar, raw)
+	}
+	return n, nil
+}
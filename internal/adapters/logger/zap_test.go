@@ -0,0 +1,123 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		want    string
+		wantErr bool
+	}{
+		{name: "empty defaults to json", format: "", want: FormatJSON},
+		{name: "json", format: "json", want: FormatJSON},
+		{name: "console is case-insensitive", format: " Console ", want: FormatConsole},
+		{name: "unknown", format: "logfmt", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFormat(tt.format)
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidLogFormat)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewZapLogger(t *testing.T) {
+	for _, format := range []string{"", FormatJSON, FormatConsole} {
+		t.Run("format "+format, func(t *testing.T) {
+			t.Setenv(EnvLogFormat, format)
+			t.Setenv(EnvLogLevel, "debug")
+
+			log, closeLog, err := NewZapLogger(NewLevel())
+
+			require.NoError(t, err)
+			assert.NotNil(t, log)
+			assert.NoError(t, closeLog())
+		})
+	}
+}
+
+func TestNewZapLogger_InvalidFormat(t *testing.T) {
+	t.Setenv(EnvLogFormat, "xml")
+
+	_, _, err := NewZapLogger(NewLevel())
+
+	assert.ErrorIs(t, err, ErrInvalidLogFormat)
+}
+
+func TestNewZapLogger_LogFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "slippy-find.log")
+	t.Setenv(EnvLogFormat, FormatJSON)
+	t.Setenv(EnvLogLevel, "info")
+	t.Setenv(EnvLogFile, path)
+
+	log, closeLog, err := NewZapLogger(NewLevel())
+	require.NoError(t, err)
+	log.Info(context.Background(), "written to file", map[string]any{"repository": "org/repo"})
+	require.NoError(t, closeLog())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"msg":"written to file"`)
+	assert.Contains(t, string(data), `"repository":"org/repo"`)
+}
+
+func TestNewZapLogger_LevelChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "slippy-find.log")
+	t.Setenv(EnvLogFormat, FormatJSON)
+	t.Setenv(EnvLogLevel, "info")
+	t.Setenv(EnvLogFile, path)
+	level := NewLevel()
+
+	log, closeLog, err := NewZapLogger(level)
+	require.NoError(t, err)
+	log.Debug(context.Background(), "before the change", nil)
+	level.SetLevel(zapcore.DebugLevel)
+	log.Debug(context.Background(), "after the change", nil)
+	require.NoError(t, closeLog())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "before the change")
+	assert.Contains(t, string(data), `"msg":"after the change"`)
+}
+
+func TestNewZapLogger_InvalidLogFileSettings(t *testing.T) {
+	tests := []struct {
+		name   string
+		envVar string
+		value  string
+	}{
+		{name: "non-numeric size", envVar: EnvLogFileMaxSize, value: "big"},
+		{name: "zero size", envVar: EnvLogFileMaxSize, value: "0"},
+		{name: "negative backups", envVar: EnvLogFileMaxBackups, value: "-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvLogFormat, "")
+			t.Setenv(EnvLogFile, filepath.Join(t.TempDir(), "slippy-find.log"))
+			t.Setenv(tt.envVar, tt.value)
+
+			_, _, err := NewZapLogger(NewLevel())
+
+			assert.ErrorIs(t, err, ErrInvalidLogFile)
+		})
+	}
+}
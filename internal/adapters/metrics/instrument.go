@@ -0,0 +1,256 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// InstrumentGit wraps repo so every history walk is timed as a stage.
+func (m *Metrics) InstrumentGit(repo domain.LocalGitRepository) domain.LocalGitRepository {
+	return &instrumentedGit{LocalGitRepository: repo, metrics: m}
+}
+
+// InstrumentFinder wraps finder so every store call is timed as a stage and
+// failures are counted.
+func (m *Metrics) InstrumentFinder(finder domain.SlipFinder) domain.SlipFinder {
+	return &instrumentedFinder{SlipFinder: finder, metrics: m}
+}
+
+// InstrumentResolver wraps resolver so every resolution is counted by result
+// and timed.
+func (m *Metrics) InstrumentResolver(resolver domain.Resolver) domain.Resolver {
+	return &instrumentedResolver{resolver: resolver, metrics: m}
+}
+
+// instrumentedGit times the wrapped repository's calls.
+type instrumentedGit struct {
+	domain.LocalGitRepository
+	metrics *Metrics
+}
+
+// GetGitContext implements domain.LocalGitRepository.
+func (g *instrumentedGit) GetGitContext(ctx context.Context) (*domain.GitContext, error) {
+	defer g.metrics.observeStage("git_context", time.Now())
+	return g.LocalGitRepository.GetGitContext(ctx)
+}
+
+// GetCommitAncestry implements domain.LocalGitRepository.
+func (g *instrumentedGit) GetCommitAncestry(ctx context.Context, depth int) ([]string, error) {
+	defer g.metrics.observeStage("git_ancestry", time.Now())
+	return g.LocalGitRepository.GetCommitAncestry(ctx, depth)
+}
+
+// StreamCommitAncestry delegates to the wrapped repository if it is a
+// domain.AncestryStreamer. Returns domain.ErrStreamUnsupported otherwise.
+func (g *instrumentedGit) StreamCommitAncestry(ctx context.Context, depth int, yield func(sha string) bool) error {
+	streamer, ok := g.LocalGitRepository.(domain.AncestryStreamer)
+	if !ok {
+		return domain.ErrStreamUnsupported
+	}
+	defer g.metrics.observeStage("git_ancestry", time.Now())
+	return streamer.StreamCommitAncestry(ctx, depth, yield)
+}
+
+// GetMergeBaseAncestry implements domain.LocalGitRepository.
+func (g *instrumentedGit) GetMergeBaseAncestry(ctx context.Context, branch string, depth int) ([]string, error) {
+	defer g.metrics.observeStage("git_merge_base_ancestry", time.Now())
+	return g.LocalGitRepository.GetMergeBaseAncestry(ctx, branch, depth)
+}
+
+// GetPathAncestry implements domain.LocalGitRepository.
+func (g *instrumentedGit) GetPathAncestry(ctx context.Context, paths []string, depth int) ([]string, error) {
+	defer g.metrics.observeStage("git_path_ancestry", time.Now())
+	return g.LocalGitRepository.GetPathAncestry(ctx, paths, depth)
+}
+
+// GetPathAncestries implements domain.LocalGitRepository.
+func (g *instrumentedGit) GetPathAncestries(
+	ctx context.Context,
+	paths map[string][]string,
+	depth int,
+) (map[string][]string, error) {
+	defer g.metrics.observeStage("git_path_ancestry", time.Now())
+	return g.LocalGitRepository.GetPathAncestries(ctx, paths, depth)
+}
+
+// GetAncestryFrom implements domain.LocalGitRepository.
+func (g *instrumentedGit) GetAncestryFrom(ctx context.Context, rev string, depth int) ([]string, error) {
+	defer g.metrics.observeStage("git_ancestry_from", time.Now())
+	return g.LocalGitRepository.GetAncestryFrom(ctx, rev, depth)
+}
+
+// RecordSlip delegates to the wrapped repository if it is a
+// domain.SlipRecorder. Returns domain.ErrRecordUnsupported otherwise.
+func (g *instrumentedGit) RecordSlip(ctx context.Context, record domain.SlipRecord) error {
+	recorder, ok := g.LocalGitRepository.(domain.SlipRecorder)
+	if !ok {
+		return domain.ErrRecordUnsupported
+	}
+	defer g.metrics.observeStage("git_record", time.Now())
+	return recorder.RecordSlip(ctx, record)
+}
+
+// ResolveCommit delegates to the wrapped repository if it is a
+// domain.CommitResolver. Returns domain.ErrResolveCommitUnsupported otherwise.
+func (g *instrumentedGit) ResolveCommit(ctx context.Context, rev string) (string, error) {
+	resolver, ok := g.LocalGitRepository.(domain.CommitResolver)
+	if !ok {
+		return "", domain.ErrResolveCommitUnsupported
+	}
+	return resolver.ResolveCommit(ctx, rev)
+}
+
+// DescribeEnvironment delegates to the wrapped repository if it is a
+// domain.EnvironmentDescriber.
+func (g *instrumentedGit) DescribeEnvironment(env *domain.Environment) {
+	if describer, ok := g.LocalGitRepository.(domain.EnvironmentDescriber); ok {
+		describer.DescribeEnvironment(env)
+	}
+}
+
+// instrumentedFinder times the wrapped finder's calls and counts failures.
+type instrumentedFinder struct {
+	domain.SlipFinder
+	metrics *Metrics
+}
+
+// FindByCommits implements domain.SlipFinder.
+func (f *instrumentedFinder) FindByCommits(
+	ctx context.Context,
+	repository string,
+	commits []string,
+) (*domain.Slip, string, error) {
+	defer f.metrics.observeStage("store_find_by_commits", time.Now())
+	slip, commit, err := f.SlipFinder.FindByCommits(ctx, repository, commits)
+	f.metrics.countStoreError("find_by_commits", err)
+	return slip, commit, err
+}
+
+// FindAllByCommits implements domain.SlipFinder.
+func (f *instrumentedFinder) FindAllByCommits(
+	ctx context.Context,
+	repository string,
+	commits []string,
+) ([]domain.SlipMatch, error) {
+	defer f.metrics.observeStage("store_find_all_by_commits", time.Now())
+	matches, err := f.SlipFinder.FindAllByCommits(ctx, repository, commits)
+	f.metrics.countStoreError("find_all_by_commits", err)
+	return matches, err
+}
+
+// FindNearestByCommits delegates to the wrapped finder if it is a
+// domain.NearestSlipsFinder. Returns domain.ErrNearestUnsupported otherwise.
+func (f *instrumentedFinder) FindNearestByCommits(
+	ctx context.Context,
+	repository string,
+	commits []string,
+) ([]domain.SlipMatch, error) {
+	nearest, ok := f.SlipFinder.(domain.NearestSlipsFinder)
+	if !ok {
+		return nil, domain.ErrNearestUnsupported
+	}
+	defer f.metrics.observeStage("store_find_nearest_by_commits", time.Now())
+	matches, err := nearest.FindNearestByCommits(ctx, repository, commits)
+	f.metrics.countStoreError("find_nearest_by_commits", err)
+	return matches, err
+}
+
+// FindLatestByBranch implements domain.SlipFinder.
+func (f *instrumentedFinder) FindLatestByBranch(ctx context.Context, repository, branch string) (*domain.Slip, error) {
+	defer f.metrics.observeStage("store_find_latest_by_branch", time.Now())
+	slip, err := f.SlipFinder.FindLatestByBranch(ctx, repository, branch)
+	f.metrics.countStoreError("find_latest_by_branch", err)
+	return slip, err
+}
+
+// FindByID implements domain.SlipFinder.
+func (f *instrumentedFinder) FindByID(ctx context.Context, correlationID string) (*domain.Slip, error) {
+	defer f.metrics.observeStage("store_find_by_id", time.Now())
+	slip, err := f.SlipFinder.FindByID(ctx, correlationID)
+	f.metrics.countStoreError("find_by_id", err)
+	return slip, err
+}
+
+// AppendHistory delegates to the wrapped finder if it is a domain.SlipAnnotator.
+// Returns domain.ErrAnnotationUnsupported otherwise.
+func (f *instrumentedFinder) AppendHistory(ctx context.Context, correlationID string, entry domain.HistoryEntry) error {
+	annotator, ok := f.SlipFinder.(domain.SlipAnnotator)
+	if !ok {
+		return domain.ErrAnnotationUnsupported
+	}
+	defer f.metrics.observeStage("store_append_history", time.Now())
+	err := annotator.AppendHistory(ctx, correlationID, entry)
+	f.metrics.countStoreError("append_history", err)
+	return err
+}
+
+// Ping delegates to the wrapped finder if it is a domain.StorePinger.
+// Returns domain.ErrPingUnsupported otherwise. It is not timed.
+func (f *instrumentedFinder) Ping(ctx context.Context) error {
+	pinger, ok := f.SlipFinder.(domain.StorePinger)
+	if !ok {
+		return domain.ErrPingUnsupported
+	}
+	return pinger.Ping(ctx)
+}
+
+// ChunkSize delegates to the wrapped finder if it is a domain.ChunkSizer.
+// Returns 0, the resolver's default, otherwise.
+func (f *instrumentedFinder) ChunkSize() int {
+	if sizer, ok := f.SlipFinder.(domain.ChunkSizer); ok {
+		return sizer.ChunkSize()
+	}
+	return 0
+}
+
+// DescribeEnvironment delegates to the wrapped finder if it is a
+// domain.EnvironmentDescriber.
+func (f *instrumentedFinder) DescribeEnvironment(env *domain.Environment) {
+	if describer, ok := f.SlipFinder.(domain.EnvironmentDescriber); ok {
+		describer.DescribeEnvironment(env)
+	}
+}
+
+// instrumentedResolver counts and times the wrapped resolver's calls.
+type instrumentedResolver struct {
+	resolver domain.Resolver
+	metrics  *Metrics
+}
+
+// Resolve implements domain.Resolver.
+func (r *instrumentedResolver) Resolve(ctx context.Context, input domain.ResolveInput) (*domain.ResolveOutput, error) {
+	start := time.Now()
+	output, err := r.resolver.Resolve(ctx, input)
+	resolvedBy := ""
+	if output != nil {
+		resolvedBy = output.ResolvedBy
+		r.metrics.observeChunks(output.Chunks)
+	}
+	r.metrics.observeResolution("resolve", resolvedBy, start, err)
+	return output, err
+}
+
+// ResolveAll implements domain.Resolver.
+func (r *instrumentedResolver) ResolveAll(
+	ctx context.Context,
+	input domain.ResolveInput,
+) (*domain.ResolveAllOutput, error) {
+	start := time.Now()
+	output, err := r.resolver.ResolveAll(ctx, input)
+	r.metrics.observeResolution("resolve_all", "", start, err)
+	return output, err
+}
+
+// ResolveComponents implements domain.Resolver.
+func (r *instrumentedResolver) ResolveComponents(
+	ctx context.Context,
+	input domain.ResolveInput,
+	components map[string][]string,
+) (map[string]*domain.ResolveOutput, error) {
+	start := time.Now()
+	outputs, err := r.resolver.ResolveComponents(ctx, input, components)
+	r.metrics.observeResolution("resolve_components", "", start, err)
+	return outputs, err
+}
@@ -0,0 +1,383 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// timeZero is a fixed start time for observations whose duration is irrelevant.
+var timeZero = time.Unix(0, 0)
+
+// stubGit implements domain.LocalGitRepository with fixed results.
+type stubGit struct {
+	domain.LocalGitRepository
+}
+
+func (stubGit) GetGitContext(_ context.Context) (*domain.GitContext, error) {
+	return &domain.GitContext{Repository: "org/repo"}, nil
+}
+
+func (stubGit) GetCommitAncestry(_ context.Context, _ int) ([]string, error) {
+	return []string{"c0", "c1"}, nil
+}
+
+// recordingGit is a stubGit that records resolved slips.
+type recordingGit struct {
+	stubGit
+	records []domain.SlipRecord
+}
+
+func (g *recordingGit) RecordSlip(_ context.Context, record domain.SlipRecord) error {
+	g.records = append(g.records, record)
+	return nil
+}
+
+// stubFinder implements domain.SlipFinder with fixed results.
+type stubFinder struct {
+	domain.SlipFinder
+	err error
+}
+
+func (f stubFinder) FindByCommits(_ context.Context, _ string, commits []string) (*domain.Slip, string, error) {
+	if f.err != nil {
+		return nil, "", f.err
+	}
+	return &domain.Slip{CorrelationID: "slip-1"}, commits[0], nil
+}
+
+func (f stubFinder) FindByID(_ context.Context, _ string) (*domain.Slip, error) {
+	return nil, f.err
+}
+
+// annotatingFinder is a stubFinder that records history entries.
+type annotatingFinder struct {
+	stubFinder
+	appended int
+}
+
+func (f *annotatingFinder) AppendHistory(_ context.Context, _ string, _ domain.HistoryEntry) error {
+	f.appended++
+	return nil
+}
+
+// pingingFinder is a stubFinder that counts pings.
+type pingingFinder struct {
+	stubFinder
+	pinged int
+}
+
+func (f *pingingFinder) Ping(_ context.Context) error {
+	f.pinged++
+	return nil
+}
+
+// nearestFinder is a stubFinder that counts nearest-commit lookups.
+type nearestFinder struct {
+	stubFinder
+	lookups int
+}
+
+func (f *nearestFinder) FindNearestByCommits(_ context.Context, _ string, _ []string) ([]domain.SlipMatch, error) {
+	f.lookups++
+	return nil, nil
+}
+
+// stubResolver implements domain.Resolver with fixed results.
+type stubResolver struct {
+	output *domain.ResolveOutput
+	err    error
+}
+
+func (r stubResolver) Resolve(_ context.Context, _ domain.ResolveInput) (*domain.ResolveOutput, error) {
+	return r.output, r.err
+}
+
+func (r stubResolver) ResolveAll(_ context.Context, _ domain.ResolveInput) (*domain.ResolveAllOutput, error) {
+	return nil, r.err
+}
+
+func (r stubResolver) ResolveComponents(
+	_ context.Context,
+	_ domain.ResolveInput,
+	_ map[string][]string,
+) (map[string]*domain.ResolveOutput, error) {
+	return nil, r.err
+}
+
+func TestInstrumentGit(t *testing.T) {
+	m := New()
+	repo := m.InstrumentGit(stubGit{})
+
+	commits, err := repo.GetCommitAncestry(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c0", "c1"}, commits)
+	_, err = repo.GetGitContext(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, testutil.CollectAndCount(m.stageDuration))
+}
+
+func TestInstrumentFinder(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantErrors float64
+	}{
+		{name: "success"},
+		{name: "not found is not a store error", err: domain.ErrSlipNotFound},
+		{name: "store error", err: assert.AnError, wantErrors: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New()
+			finder := m.InstrumentFinder(stubFinder{err: tt.err})
+
+			_, _ = finder.FindByID(context.Background(), "slip-1")
+
+			assert.Equal(t, 1, testutil.CollectAndCount(m.stageDuration))
+			assert.InDelta(t, tt.wantErrors, testutil.ToFloat64(m.storeErrors.WithLabelValues("find_by_id")), 0)
+		})
+	}
+}
+
+func TestInstrumentFinder_FindByCommits(t *testing.T) {
+	m := New()
+	finder := m.InstrumentFinder(stubFinder{})
+
+	slip, commit, err := finder.FindByCommits(context.Background(), "org/repo", []string{"c0"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "slip-1", slip.CorrelationID)
+	assert.Equal(t, "c0", commit)
+	assert.Equal(t, 1, testutil.CollectAndCount(m.stageDuration, "slippy_find_stage_duration_seconds"))
+}
+
+func TestInstrumentGit_StreamCommitAncestryUnsupported(t *testing.T) {
+	streamer, ok := New().InstrumentGit(stubGit{}).(domain.AncestryStreamer)
+	require.True(t, ok)
+
+	err := streamer.StreamCommitAncestry(context.Background(), 10, func(string) bool { return true })
+
+	assert.ErrorIs(t, err, domain.ErrStreamUnsupported)
+}
+
+func TestInstrumentGit_RecordSlip(t *testing.T) {
+	m := New()
+	record := domain.SlipRecord{Commit: "c0", CorrelationID: "slip-1", Kind: domain.RecordNote}
+
+	plain, ok := m.InstrumentGit(stubGit{}).(domain.SlipRecorder)
+	require.True(t, ok)
+	assert.ErrorIs(t, plain.RecordSlip(context.Background(), record), domain.ErrRecordUnsupported)
+
+	inner := &recordingGit{}
+	recorder, ok := m.InstrumentGit(inner).(domain.SlipRecorder)
+	require.True(t, ok)
+	require.NoError(t, recorder.RecordSlip(context.Background(), record))
+	assert.Equal(t, []domain.SlipRecord{record}, inner.records)
+}
+
+// resolvingGit is a stubGit that expands revisions by appending "-full".
+type resolvingGit struct {
+	stubGit
+}
+
+func (resolvingGit) ResolveCommit(_ context.Context, rev string) (string, error) {
+	return rev + "-full", nil
+}
+
+func TestInstrumentGit_ResolveCommit(t *testing.T) {
+	m := New()
+
+	plain, ok := m.InstrumentGit(stubGit{}).(domain.CommitResolver)
+	require.True(t, ok)
+	_, err := plain.ResolveCommit(context.Background(), "abc1")
+	assert.ErrorIs(t, err, domain.ErrResolveCommitUnsupported)
+
+	resolver, ok := m.InstrumentGit(resolvingGit{}).(domain.CommitResolver)
+	require.True(t, ok)
+	sha, err := resolver.ResolveCommit(context.Background(), "abc1")
+	require.NoError(t, err)
+	assert.Equal(t, "abc1-full", sha)
+}
+
+func TestInstrumentFinder_AppendHistory(t *testing.T) {
+	m := New()
+
+	plain, ok := m.InstrumentFinder(stubFinder{}).(domain.SlipAnnotator)
+	require.True(t, ok)
+	assert.ErrorIs(t, plain.AppendHistory(context.Background(), "slip-1", domain.HistoryEntry{}),
+		domain.ErrAnnotationUnsupported)
+
+	inner := &annotatingFinder{}
+	annotator, ok := m.InstrumentFinder(inner).(domain.SlipAnnotator)
+	require.True(t, ok)
+	require.NoError(t, annotator.AppendHistory(context.Background(), "slip-1", domain.HistoryEntry{}))
+	assert.Equal(t, 1, inner.appended)
+}
+
+func TestInstrumentFinder_Ping(t *testing.T) {
+	m := New()
+
+	plain, ok := m.InstrumentFinder(stubFinder{}).(domain.StorePinger)
+	require.True(t, ok)
+	assert.ErrorIs(t, plain.Ping(context.Background()), domain.ErrPingUnsupported)
+
+	inner := &pingingFinder{}
+	pinger, ok := m.InstrumentFinder(inner).(domain.StorePinger)
+	require.True(t, ok)
+	require.NoError(t, pinger.Ping(context.Background()))
+	assert.Equal(t, 1, inner.pinged)
+}
+
+// sizedFinder is a stubFinder choosing its chunk size.
+type sizedFinder struct {
+	stubFinder
+	size int
+}
+
+func (f sizedFinder) ChunkSize() int { return f.size }
+
+func TestInstrumentFinder_ChunkSize(t *testing.T) {
+	m := New()
+
+	plain, ok := m.InstrumentFinder(stubFinder{}).(domain.ChunkSizer)
+	require.True(t, ok)
+	assert.Zero(t, plain.ChunkSize())
+
+	sized, ok := m.InstrumentFinder(sizedFinder{size: 250}).(domain.ChunkSizer)
+	require.True(t, ok)
+	assert.Equal(t, 250, sized.ChunkSize())
+}
+
+// describingGit is a stubGit describing itself as a go-git checkout.
+type describingGit struct{ stubGit }
+
+func (describingGit) DescribeEnvironment(env *domain.Environment) {
+	env.GitBackend = domain.GitBackendGoGit
+}
+
+// describingFinder is a stubFinder describing itself as the mock store.
+type describingFinder struct{ stubFinder }
+
+func (describingFinder) DescribeEnvironment(env *domain.Environment) { env.Store = domain.StoreMock }
+
+func TestInstrument_DescribeEnvironment(t *testing.T) {
+	m := New()
+	var env domain.Environment
+
+	for _, wrapped := range []any{
+		m.InstrumentGit(stubGit{}),
+		m.InstrumentFinder(stubFinder{}),
+		m.InstrumentGit(describingGit{}),
+		m.InstrumentFinder(describingFinder{}),
+	} {
+		describer, ok := wrapped.(domain.EnvironmentDescriber)
+		require.True(t, ok)
+		describer.DescribeEnvironment(&env)
+	}
+
+	assert.Equal(t, domain.Environment{GitBackend: domain.GitBackendGoGit, Store: domain.StoreMock}, env)
+}
+
+func TestInstrumentFinder_FindNearestByCommits(t *testing.T) {
+	m := New()
+
+	plain, ok := m.InstrumentFinder(stubFinder{}).(domain.NearestSlipsFinder)
+	require.True(t, ok)
+	_, err := plain.FindNearestByCommits(context.Background(), "org/repo", []string{"c1"})
+	assert.ErrorIs(t, err, domain.ErrNearestUnsupported)
+
+	inner := &nearestFinder{}
+	nearest, ok := m.InstrumentFinder(inner).(domain.NearestSlipsFinder)
+	require.True(t, ok)
+	_, err = nearest.FindNearestByCommits(context.Background(), "org/repo", []string{"c1"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, inner.lookups)
+}
+
+func TestInstrumentResolver(t *testing.T) {
+	tests := []struct {
+		name           string
+		resolver       stubResolver
+		wantResult     string
+		wantResolvedBy string
+	}{
+		{
+			name:           "hit",
+			resolver:       stubResolver{output: &domain.ResolveOutput{ResolvedBy: domain.ResolvedByMergeBase}},
+			wantResult:     resultHit,
+			wantResolvedBy: domain.ResolvedByMergeBase,
+		},
+		{
+			name:       "miss",
+			resolver:   stubResolver{err: fmt.Errorf("%w: searched 25 commits", domain.ErrNoAncestorSlip)},
+			wantResult: resultMiss,
+		},
+		{
+			name:       "error",
+			resolver:   stubResolver{err: assert.AnError},
+			wantResult: resultError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New()
+			resolver := m.InstrumentResolver(tt.resolver)
+
+			_, _ = resolver.Resolve(context.Background(), domain.ResolveInput{})
+			_, _ = resolver.ResolveAll(context.Background(), domain.ResolveInput{})
+
+			assert.InDelta(t, 1, testutil.ToFloat64(
+				m.resolutions.WithLabelValues("resolve", tt.wantResult, tt.wantResolvedBy)), 0)
+			assert.InDelta(t, 1, testutil.ToFloat64(
+				m.resolutions.WithLabelValues("resolve_all", tt.wantResult, "")), 0)
+			assert.Equal(t, 2, testutil.CollectAndCount(m.resolutionDuration))
+		})
+	}
+}
+
+func TestInstrumentResolver_Chunks(t *testing.T) {
+	m := New()
+	chunked := m.InstrumentResolver(stubResolver{output: &domain.ResolveOutput{
+		Chunks: domain.ChunkStats{Queried: 4, Needed: 2},
+	}})
+	unchunked := m.InstrumentResolver(stubResolver{output: &domain.ResolveOutput{}})
+
+	_, _ = chunked.Resolve(context.Background(), domain.ResolveInput{})
+	_, _ = unchunked.Resolve(context.Background(), domain.ResolveInput{})
+
+	assert.Equal(t, 2, testutil.CollectAndCount(m.storeChunks), "one series per kind")
+	want := `
+# HELP slippy_find_store_chunks Chunked store queries per resolution: started (queried) and up to the nearest match (needed).
+# TYPE slippy_find_store_chunks histogram
+`
+	err := testutil.CollectAndCompare(m.storeChunks, strings.NewReader(want+chunkSeries("needed", 2)+chunkSeries("queried", 4)))
+	assert.NoError(t, err, "resolutions not queried in chunks are not observed")
+}
+
+// chunkSeries renders the store_chunks histogram of kind holding one sample of v.
+func chunkSeries(kind string, v int) string {
+	var b strings.Builder
+	for bucket := 1; bucket <= 128; bucket *= 2 {
+		n := 0
+		if v <= bucket {
+			n = 1
+		}
+		fmt.Fprintf(&b, "slippy_find_store_chunks_bucket{kind=%q,le=\"%d\"} %d\n", kind, bucket, n)
+	}
+	fmt.Fprintf(&b, "slippy_find_store_chunks_bucket{kind=%q,le=\"+Inf\"} 1\n", kind)
+	fmt.Fprintf(&b, "slippy_find_store_chunks_sum{kind=%q} %d\n", kind, v)
+	fmt.Fprintf(&b, "slippy_find_store_chunks_count{kind=%q} 1\n", kind)
+	return b.String()
+}
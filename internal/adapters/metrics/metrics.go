@@ -0,0 +1,182 @@
+// Package metrics instruments slip resolution with Prometheus metrics.
+// The decorators in this package wrap the domain adapters and resolver, and
+// Metrics exposes what they record for scraping, as a node_exporter textfile,
+// or by pushing to a Pushgateway, optionally mirroring it to StatsD.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/push"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+const (
+	// namespace prefixes every metric name.
+	namespace = "slippy_find"
+
+	// pushJob is the Pushgateway job label for pushed metrics.
+	pushJob = "slippy-find"
+)
+
+// Resolution result label values.
+const (
+	resultHit   = "hit"
+	resultMiss  = "miss"
+	resultError = "error"
+)
+
+// Metrics holds the collectors recorded by the instrumented adapters, in a
+// registry of its own so exports carry only slippy-find's metrics. Each
+// observation is also sent to StatsD when a client is attached.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	// statsd, when set, receives every observation as well.
+	statsd *StatsD
+
+	// resolutions counts resolver calls by operation, result, and resolved_by.
+	resolutions *prometheus.CounterVec
+
+	// resolutionDuration observes whole resolver calls by operation.
+	resolutionDuration *prometheus.HistogramVec
+
+	// stageDuration observes individual git and store calls by stage.
+	stageDuration *prometheus.HistogramVec
+
+	// storeErrors counts failed store calls by operation.
+	storeErrors *prometheus.CounterVec
+
+	// storeChunks observes the chunked store queries of each resolution that
+	// queried its ancestry in chunks, by kind (queried, needed).
+	storeChunks *prometheus.HistogramVec
+}
+
+// New creates Metrics with its collectors registered on a new registry.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		resolutions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "resolutions_total",
+			Help:      "Slip resolutions by operation, result (hit, miss, error), and resolving strategy.",
+		}, []string{"operation", "result", "resolved_by"}),
+		resolutionDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "resolution_duration_seconds",
+			Help:      "Duration of slip resolutions by operation.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation"}),
+		stageDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "stage_duration_seconds",
+			Help:      "Duration of git and store calls made during resolution, by stage.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"stage"}),
+		storeErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "store_errors_total",
+			Help:      "Failed slip store calls by operation.",
+		}, []string{"operation"}),
+		storeChunks: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "store_chunks",
+			Help:      "Chunked store queries per resolution: started (queried) and up to the nearest match (needed).",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 8),
+		}, []string{"kind"}),
+	}
+	m.registry.MustRegister(m.resolutions, m.resolutionDuration, m.stageDuration, m.storeErrors, m.storeChunks)
+	return m
+}
+
+// SendToStatsD also emits every later observation to client.
+// It must be called before the instrumented adapters are used.
+func (m *Metrics) SendToStatsD(client *StatsD) {
+	m.statsd = client
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// WriteTextfile writes the metrics to path for the node_exporter textfile
+// collector. The file is replaced atomically.
+func (m *Metrics) WriteTextfile(path string) error {
+	if err := prometheus.WriteToTextfile(path, m.registry); err != nil {
+		return fmt.Errorf("failed to write metrics textfile %s: %w", path, err)
+	}
+	return nil
+}
+
+// Push replaces the slippy-find job's metrics on the Pushgateway at url.
+func (m *Metrics) Push(ctx context.Context, url string) error {
+	if err := push.New(url, pushJob).Gatherer(m.registry).PushContext(ctx); err != nil {
+		return fmt.Errorf("failed to push metrics to %s: %w", url, err)
+	}
+	return nil
+}
+
+// observeStage records the duration of a git or store call started at start.
+func (m *Metrics) observeStage(stage string, start time.Time) {
+	elapsed := time.Since(start)
+	m.stageDuration.WithLabelValues(stage).Observe(elapsed.Seconds())
+	if m.statsd != nil {
+		m.statsd.timing("stage_duration", elapsed, tag{"stage", stage})
+	}
+}
+
+// observeResolution records a resolver call started at start.
+// resolvedBy is only recorded for hits.
+func (m *Metrics) observeResolution(operation, resolvedBy string, start time.Time, err error) {
+	elapsed := time.Since(start)
+	m.resolutionDuration.WithLabelValues(operation).Observe(elapsed.Seconds())
+
+	result := resultHit
+	switch {
+	case errors.Is(err, domain.ErrNoAncestorSlip):
+		result, resolvedBy = resultMiss, ""
+	case err != nil:
+		result, resolvedBy = resultError, ""
+	}
+	m.resolutions.WithLabelValues(operation, result, resolvedBy).Inc()
+
+	if m.statsd != nil {
+		m.statsd.timing("resolution_duration", elapsed, tag{"operation", operation})
+		m.statsd.count("resolutions",
+			tag{"operation", operation}, tag{"result", result}, tag{"resolved_by", resolvedBy})
+	}
+}
+
+// observeChunks records the chunked store queries of a resolution, if its
+// ancestry was queried in chunks.
+func (m *Metrics) observeChunks(stats domain.ChunkStats) {
+	if stats.Queried == 0 {
+		return
+	}
+	m.storeChunks.WithLabelValues("queried").Observe(float64(stats.Queried))
+	m.storeChunks.WithLabelValues("needed").Observe(float64(stats.Needed))
+	if m.statsd != nil {
+		m.statsd.histogram("store_chunks", stats.Queried, tag{"kind", "queried"})
+		m.statsd.histogram("store_chunks", stats.Needed, tag{"kind", "needed"})
+	}
+}
+
+// countStoreError records a failed store call. A slip that does not exist is
+// a normal outcome rather than a store error.
+func (m *Metrics) countStoreError(operation string, err error) {
+	if err == nil || errors.Is(err, domain.ErrSlipNotFound) {
+		return
+	}
+	m.storeErrors.WithLabelValues(operation).Inc()
+	if m.statsd != nil {
+		m.statsd.count("store_errors", tag{"operation", operation})
+	}
+}
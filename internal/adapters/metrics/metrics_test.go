@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+func TestMetrics_Handler(t *testing.T) {
+	m := New()
+	m.resolutions.WithLabelValues("resolve", resultHit, domain.ResolvedByAncestry).Inc()
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(),
+		`slippy_find_resolutions_total{operation="resolve",resolved_by="ancestry",result="hit"} 1`)
+}
+
+func TestMetrics_WriteTextfile(t *testing.T) {
+	m := New()
+	m.storeErrors.WithLabelValues("find_by_commits").Inc()
+	path := filepath.Join(t.TempDir(), "slippy_find.prom")
+
+	require.NoError(t, m.WriteTextfile(path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `slippy_find_store_errors_total{operation="find_by_commits"} 1`)
+}
+
+func TestMetrics_WriteTextfile_Error(t *testing.T) {
+	err := New().WriteTextfile(filepath.Join(t.TempDir(), "missing", "slippy_find.prom"))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to write metrics textfile")
+}
+
+func TestMetrics_Push(t *testing.T) {
+	var method, path, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	m := New()
+	m.resolutions.WithLabelValues("resolve", resultMiss, "").Inc()
+
+	require.NoError(t, m.Push(context.Background(), server.URL))
+
+	assert.Equal(t, http.MethodPut, method)
+	assert.Equal(t, "/metrics/job/slippy-find", path)
+	assert.Contains(t, body, "slippy_find_resolutions_total")
+}
+
+func TestMetrics_Push_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	err := New().Push(context.Background(), server.URL)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to push metrics to")
+}
+
+func TestMetrics_ObserveResolution(t *testing.T) {
+	tests := []struct {
+		name           string
+		resolvedBy     string
+		err            error
+		wantResult     string
+		wantResolvedBy string
+	}{
+		{name: "hit", resolvedBy: domain.ResolvedByAncestry, wantResult: resultHit, wantResolvedBy: "ancestry"},
+		{name: "miss", err: domain.ErrNoAncestorSlip, wantResult: resultMiss},
+		{name: "error", resolvedBy: "ignored", err: assert.AnError, wantResult: resultError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := New()
+
+			m.observeResolution("resolve", tt.resolvedBy, timeZero, tt.err)
+
+			assert.InDelta(t, 1, testutil.ToFloat64(
+				m.resolutions.WithLabelValues("resolve", tt.wantResult, tt.wantResolvedBy)), 0)
+			assert.Equal(t, 1, testutil.CollectAndCount(m.resolutionDuration))
+		})
+	}
+}
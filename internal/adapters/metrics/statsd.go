@@ -0,0 +1,110 @@
+package metrics
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tag is a metric label, kept ordered so plain StatsD names are stable.
+type tag struct {
+	key, value string
+}
+
+// StatsD sends metrics to a StatsD or DogStatsD agent over UDP. Sends are
+// fire-and-forget: a missing agent never affects resolution.
+type StatsD struct {
+	conn      net.Conn
+	prefix    string
+	dogStatsD bool
+}
+
+// NewStatsD creates a StatsD client for the agent at addr ("host:port").
+// Metric names are prefixed with prefix. With dogStatsD, labels are sent as
+// DogStatsD tags; otherwise their values are appended to the metric name.
+func NewStatsD(addr, prefix string, dogStatsD bool) (*StatsD, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open StatsD connection to %s: %w", addr, err)
+	}
+	return &StatsD{conn: conn, prefix: prefix, dogStatsD: dogStatsD}, nil
+}
+
+// Close closes the UDP connection.
+func (s *StatsD) Close() error {
+	return s.conn.Close()
+}
+
+// count sends a counter increment of one.
+func (s *StatsD) count(name string, tags ...tag) {
+	s.send(name, "1|c", tags)
+}
+
+// histogram sends one sample of a distribution.
+func (s *StatsD) histogram(name string, v int, tags ...tag) {
+	s.send(name, strconv.Itoa(v)+"|h", tags)
+}
+
+// timing sends a duration in milliseconds.
+func (s *StatsD) timing(name string, d time.Duration, tags ...tag) {
+	ms := strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 3, 64)
+	s.send(name, ms+"|ms", tags)
+}
+
+// send writes one metric line. Write errors are ignored, as UDP delivery is
+// best-effort anyway.
+func (s *StatsD) send(name, value string, tags []tag) {
+	_, _ = s.conn.Write([]byte(s.line(name, value, tags)))
+}
+
+// line formats a metric in the client's wire format, e.g.
+// "slippy_find.resolutions:1|c|#operation:resolve,result:hit" (DogStatsD) or
+// "slippy_find.resolutions.resolve.hit:1|c" (StatsD). Empty tags are omitted.
+func (s *StatsD) line(name, value string, tags []tag) string {
+	var b strings.Builder
+	if s.prefix != "" {
+		b.WriteString(s.prefix)
+		b.WriteByte('.')
+	}
+	b.WriteString(name)
+
+	if !s.dogStatsD {
+		for _, t := range tags {
+			if t.value != "" {
+				b.WriteByte('.')
+				b.WriteString(sanitizeSegment(t.value))
+			}
+		}
+		b.WriteByte(':')
+		b.WriteString(value)
+		return b.String()
+	}
+
+	b.WriteByte(':')
+	b.WriteString(value)
+	sep := "|#"
+	for _, t := range tags {
+		if t.value == "" {
+			continue
+		}
+		b.WriteString(sep)
+		b.WriteString(t.key)
+		b.WriteByte(':')
+		b.WriteString(t.value)
+		sep = ","
+	}
+	return b.String()
+}
+
+// sanitizeSegment replaces characters that would break a plain StatsD name.
+func sanitizeSegment(value string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.', ':', '|', '@', '#', ' ':
+			return '_'
+		}
+		return r
+	}, value)
+}
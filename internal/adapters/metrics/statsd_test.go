@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+func TestStatsD_Line(t *testing.T) {
+	tags := []tag{{"operation", "resolve"}, {"result", "hit"}, {"resolved_by", ""}}
+
+	tests := []struct {
+		name      string
+		prefix    string
+		dogStatsD bool
+		tags      []tag
+		want      string
+	}{
+		{
+			name:      "dogstatsd tags",
+			prefix:    "slippy_find",
+			dogStatsD: true,
+			tags:      tags,
+			want:      "slippy_find.resolutions:1|c|#operation:resolve,result:hit",
+		},
+		{
+			name:   "plain statsd name segments",
+			prefix: "slippy_find",
+			tags:   tags,
+			want:   "slippy_find.resolutions.resolve.hit:1|c",
+		},
+		{
+			name:      "no prefix or tags",
+			dogStatsD: true,
+			want:      "resolutions:1|c",
+		},
+		{
+			name:   "segments sanitized",
+			prefix: "ci",
+			tags:   []tag{{"stage", "git.walk:deep"}},
+			want:   "ci.resolutions.git_walk_deep:1|c",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &StatsD{prefix: tt.prefix, dogStatsD: tt.dogStatsD}
+
+			assert.Equal(t, tt.want, s.line("resolutions", "1|c", tt.tags))
+		})
+	}
+}
+
+func TestMetrics_SendToStatsD(t *testing.T) {
+	agent, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = agent.Close() }()
+
+	client, err := NewStatsD(agent.LocalAddr().String(), "slippy_find", true)
+	require.NoError(t, err)
+	defer func() { _ = client.Close() }()
+
+	m := New()
+	m.SendToStatsD(client)
+	m.observeResolution("resolve", domain.ResolvedByPRHead, time.Now(), nil)
+	m.countStoreError("find_by_commits", assert.AnError)
+
+	var lines []string
+	buf := make([]byte, 1024)
+	require.NoError(t, agent.SetReadDeadline(time.Now().Add(5*time.Second)))
+	for range 3 {
+		n, _, err := agent.ReadFrom(buf)
+		require.NoError(t, err)
+		lines = append(lines, string(buf[:n]))
+	}
+
+	assert.True(t, strings.HasPrefix(lines[0], "slippy_find.resolution_duration:"))
+	assert.True(t, strings.HasSuffix(lines[0], "|ms|#operation:resolve"))
+	assert.Equal(t, "slippy_find.resolutions:1|c|#operation:resolve,result:hit,resolved_by:pr-head", lines[1])
+	assert.Equal(t, "slippy_find.store_errors:1|c|#operation:find_by_commits", lines[2])
+}
+
+func TestNewStatsD_InvalidAddr(t *testing.T) {
+	_, err := NewStatsD("not-an-address", "slippy_find", true)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to open StatsD connection")
+}
@@ -0,0 +1,55 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  string
+		want string
+	}{
+		{name: "no credentials", msg: "dial tcp ch:9000: refused", want: "dial tcp ch:9000: refused"},
+		{
+			name: "url userinfo",
+			msg:  "open clickhouse://svc:s3cr3t@ch:9000/ci: refused",
+			want: "open clickhouse://REDACTED@ch:9000/ci: refused",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, sanitize(tt.msg))
+		})
+	}
+}
+
+// recordingNotifier records alerts for Multi tests.
+type recordingNotifier struct {
+	failures []domain.ResolutionFailure
+	err      error
+}
+
+func (r *recordingNotifier) Notify(_ context.Context, failure domain.ResolutionFailure) error {
+	r.failures = append(r.failures, failure)
+	return r.err
+}
+
+func TestMulti(t *testing.T) {
+	failErr := errors.New("unreachable")
+	failing := &recordingNotifier{err: failErr}
+	working := &recordingNotifier{}
+
+	err := Multi{failing, working}.Notify(context.Background(), domain.ResolutionFailure{Code: domain.CodeNoSlip})
+
+	assert.ErrorIs(t, err, failErr)
+	assert.Len(t, failing.failures, 1)
+	assert.Len(t, working.failures, 1, "a failing notifier must not stop the others")
+}
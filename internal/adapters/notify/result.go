@@ -0,0 +1,59 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// SignatureHeader carries the "sha256=<hex>" HMAC of a signed result's body,
+// in the format GitHub uses for X-Hub-Signature-256.
+const SignatureHeader = "X-Slippy-Signature-256"
+
+// ResultWebhook POSTs resolution results to arbitrary endpoints, for systems
+// without first-class support.
+type ResultWebhook struct {
+	secret []byte
+	client *http.Client
+}
+
+// NewResultWebhook creates a webhook that signs each body with secret, or
+// sends it unsigned when secret is empty.
+func NewResultWebhook(secret string) *ResultWebhook {
+	return &ResultWebhook{secret: []byte(secret), client: &http.Client{Timeout: webhookTimeout}}
+}
+
+// Post POSTs body, a JSON document, to endpoint, which must be an http(s)
+// URL. Any non-2xx response is an error.
+func (w *ResultWebhook) Post(ctx context.Context, endpoint string, body []byte) error {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid webhook URL %q: expected an http(s) URL", sanitize(endpoint))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(w.secret) > 0 {
+		mac := hmac.New(sha256.New, w.secret)
+		mac.Write(body)
+		req.Header.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %s", sanitize(err.Error()))
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook rejected: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// Slack posts failures to a Slack incoming webhook.
+type Slack struct {
+	url     string
+	channel string
+	client  *http.Client
+}
+
+// slackMessage is the incoming-webhook payload.
+type slackMessage struct {
+	Channel string `json:"channel,omitempty"`
+	Text    string `json:"text"`
+}
+
+// NewSlack creates a notifier posting to the incoming webhook at url. A
+// non-empty channel overrides the webhook's default channel, where the
+// webhook allows it.
+func NewSlack(url, channel string) *Slack {
+	return &Slack{
+		url:     url,
+		channel: channel,
+		client:  &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+// Notify posts failure synchronously.
+func (s *Slack) Notify(ctx context.Context, failure domain.ResolutionFailure) error {
+	return postJSON(ctx, s.client, s.url, "Slack", slackMessage{Channel: s.channel, Text: slackText(failure)})
+}
+
+// slackText renders failure as Slack mrkdwn, one fact per line.
+func slackText(failure domain.ResolutionFailure) string {
+	var b strings.Builder
+	b.WriteString(":rotating_light: *" + title + "*")
+	for _, f := range facts(failure) {
+		fmt.Fprintf(&b, "\n*%s:* `%s`", f.name, f.value)
+	}
+	fmt.Fprintf(&b, "\n*Error:* %s (%s)", sanitize(failure.Error), failure.Code)
+	return b.String()
+}
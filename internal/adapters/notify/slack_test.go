@@ -0,0 +1,83 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+func TestSlack_Notify(t *testing.T) {
+	var got slackMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	err := NewSlack(server.URL, "#platform-alerts").Notify(context.Background(), domain.ResolutionFailure{
+		Repository: "org/repo",
+		Branch:     "main",
+		HeadSHA:    "abc123",
+		Code:       domain.CodeNoSlip,
+		Error:      "no slip found in commit ancestry",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "#platform-alerts", got.Channel)
+	assert.Equal(t, ":rotating_light: *slippy-find could not resolve a slip*\n"+
+		"*Repository:* `org/repo`\n*Branch:* `main`\n*Head:* `abc123`\n"+
+		"*Error:* no slip found in commit ancestry (SLIPPY_E003)", got.Text)
+}
+
+func TestSlack_Notify_Rejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	err := NewSlack(server.URL, "").Notify(context.Background(), domain.ResolutionFailure{})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "404")
+}
+
+func TestSlackText(t *testing.T) {
+	tests := []struct {
+		name    string
+		failure domain.ResolutionFailure
+		want    string
+	}{
+		{
+			name:    "unknown repository",
+			failure: domain.ResolutionFailure{Code: domain.CodeStore, Error: "store unreachable"},
+			want:    ":rotating_light: *slippy-find could not resolve a slip*\n*Error:* store unreachable (SLIPPY_E014)",
+		},
+		{
+			name: "credentials redacted",
+			failure: domain.ResolutionFailure{
+				Repository: "org/repo",
+				Component:  "payments-api",
+				Code:       domain.CodeStore,
+				Error:      "dial clickhouse://ci:hunter2@db:9000: refused",
+			},
+			want: ":rotating_light: *slippy-find could not resolve a slip*\n" +
+				"*Repository:* `org/repo`\n*Component:* `payments-api`\n" +
+				"*Error:* dial clickhouse://REDACTED@db:9000: refused (SLIPPY_E014)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, slackText(tt.failure))
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// Teams posts failures to a Microsoft Teams incoming webhook as an Adaptive
+// Card, which both connector webhooks and Workflows ("Post to a channel when
+// a webhook request is received") accept.
+type Teams struct {
+	url    string
+	client *http.Client
+}
+
+// teamsMessage is the webhook payload: a message carrying one card.
+type teamsMessage struct {
+	Type        string            `json:"type"`
+	Attachments []teamsAttachment `json:"attachments"`
+}
+
+type teamsAttachment struct {
+	ContentType string    `json:"contentType"`
+	Content     teamsCard `json:"content"`
+}
+
+type teamsCard struct {
+	Schema  string           `json:"$schema"`
+	Type    string           `json:"type"`
+	Version string           `json:"version"`
+	Body    []map[string]any `json:"body"`
+}
+
+// NewTeams creates a notifier posting to the incoming webhook at url.
+func NewTeams(url string) *Teams {
+	return &Teams{url: url, client: &http.Client{Timeout: webhookTimeout}}
+}
+
+// Notify posts failure synchronously.
+func (t *Teams) Notify(ctx context.Context, failure domain.ResolutionFailure) error {
+	return postJSON(ctx, t.client, t.url, "Teams", teamsMessage{
+		Type: "message",
+		Attachments: []teamsAttachment{{
+			ContentType: "application/vnd.microsoft.card.adaptive",
+			Content:     teamsCardFor(failure),
+		}},
+	})
+}
+
+// teamsCardFor renders failure as a card: a title, the known facts, and the
+// error.
+func teamsCardFor(failure domain.ResolutionFailure) teamsCard {
+	var factSet []map[string]string
+	for _, f := range facts(failure) {
+		factSet = append(factSet, map[string]string{"title": f.name, "value": f.value})
+	}
+	body := []map[string]any{
+		{"type": "TextBlock", "text": title, "weight": "Bolder", "size": "Medium", "color": "Attention"},
+	}
+	if len(factSet) > 0 {
+		body = append(body, map[string]any{"type": "FactSet", "facts": factSet})
+	}
+	body = append(body, map[string]any{
+		"type": "TextBlock",
+		"text": fmt.Sprintf("%s (%s)", sanitize(failure.Error), failure.Code),
+		"wrap": true,
+	})
+	return teamsCard{
+		Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+		Type:    "AdaptiveCard",
+		Version: "1.4",
+		Body:    body,
+	}
+}
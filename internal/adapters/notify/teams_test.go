@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+func TestTeams_Notify(t *testing.T) {
+	var got []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		got, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	err := NewTeams(server.URL).Notify(context.Background(), domain.ResolutionFailure{
+		Repository: "org/repo",
+		Branch:     "main",
+		Code:       domain.CodeStore,
+		Error:      "dial clickhouse://ci:hunter2@db:9000: refused",
+	})
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "message",
+		"attachments": [{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content": {
+				"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+				"type": "AdaptiveCard",
+				"version": "1.4",
+				"body": [
+					{"type": "TextBlock", "text": "slippy-find could not resolve a slip",
+						"weight": "Bolder", "size": "Medium", "color": "Attention"},
+					{"type": "FactSet", "facts": [
+						{"title": "Repository", "value": "org/repo"},
+						{"title": "Branch", "value": "main"}
+					]},
+					{"type": "TextBlock", "text": "dial clickhouse://REDACTED@db:9000: refused (SLIPPY_E014)", "wrap": true}
+				]
+			}
+		}]
+	}`, string(got))
+}
+
+func TestTeams_Notify_Rejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	err := NewTeams(server.URL).Notify(context.Background(), domain.ResolutionFailure{})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Teams message rejected: 400")
+}
+
+func TestTeamsCardFor_NoFacts(t *testing.T) {
+	card := teamsCardFor(domain.ResolutionFailure{Code: domain.CodeStore, Error: "store unreachable"})
+
+	require.Len(t, card.Body, 2, "the fact set is left out when nothing is known")
+	assert.Equal(t, "store unreachable (SLIPPY_E014)", card.Body[1]["text"])
+}
@@ -0,0 +1,32 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// backstageFragment is the part of a Backstage catalog entity (catalog-info.yaml)
+// that WriteBackstageFragment fills in.
+type backstageFragment struct {
+	Metadata struct {
+		Annotations map[string]string `json:"annotations"`
+	} `json:"metadata"`
+}
+
+// WriteBackstageFragment writes annotations to path as a Backstage entity
+// fragment, {"metadata": {"annotations": {...}}}, ready to be merged into the
+// component's catalog entity. Keys are sorted, so the file is stable across
+// runs that resolve the same slip.
+func WriteBackstageFragment(path string, annotations map[string]string) error {
+	var fragment backstageFragment
+	fragment.Metadata.Annotations = annotations
+	data, err := json.MarshalIndent(fragment, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode Backstage fragment: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write Backstage fragment: %w", err)
+	}
+	return nil
+}
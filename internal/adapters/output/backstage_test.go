@@ -0,0 +1,38 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteBackstageFragment(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "backstage.json")
+
+	err := WriteBackstageFragment(path, map[string]string{
+		"slippy-find/repository":     "org/repo",
+		"slippy-find/correlation-id": "slip-1",
+	})
+
+	require.NoError(t, err)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `{
+  "metadata": {
+    "annotations": {
+      "slippy-find/correlation-id": "slip-1",
+      "slippy-find/repository": "org/repo"
+    }
+  }
+}
+`, string(content))
+}
+
+func TestWriteBackstageFragment_Unwritable(t *testing.T) {
+	err := WriteBackstageFragment(filepath.Join(t.TempDir(), "missing", "backstage.json"), map[string]string{"a": "b"})
+
+	assert.ErrorContains(t, err, "failed to write Backstage fragment")
+}
@@ -0,0 +1,39 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ErrDotenvInvalid indicates a variable cannot be represented in a dotenv report.
+var ErrDotenvInvalid = errors.New("invalid dotenv variable")
+
+// dotenvKey matches the variable names GitLab accepts in dotenv reports.
+var dotenvKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// WriteDotenv writes vars to path as a GitLab CI dotenv report
+// (artifacts:reports:dotenv): one KEY=VALUE line per variable, sorted by name.
+// GitLab does not support quoting or multi-line values, so values must be a
+// single line.
+func WriteDotenv(path string, vars map[string]string) error {
+	var b strings.Builder
+	for _, key := range slices.Sorted(maps.Keys(vars)) {
+		value := vars[key]
+		if !dotenvKey.MatchString(key) {
+			return fmt.Errorf("%w: name %q", ErrDotenvInvalid, key)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("%w: %s spans several lines", ErrDotenvInvalid, key)
+		}
+		fmt.Fprintf(&b, "%s=%s\n", key, value)
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		return fmt.Errorf("failed to write dotenv report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteDotenv(t *testing.T) {
+	tests := []struct {
+		name    string
+		vars    map[string]string
+		want    string
+		wantErr error
+	}{
+		{
+			name: "sorted by name",
+			vars: map[string]string{"SLIPPY_RESOLVED_BY": "ancestry", "SLIPPY_CORRELATION_ID": "slip-1"},
+			want: "SLIPPY_CORRELATION_ID=slip-1\nSLIPPY_RESOLVED_BY=ancestry\n",
+		},
+		{name: "empty value", vars: map[string]string{"SLIPPY_COMPONENT": ""}, want: "SLIPPY_COMPONENT=\n"},
+		{name: "none", vars: map[string]string{}, want: ""},
+		{name: "invalid name", vars: map[string]string{"SLIPPY-ID": "slip-1"}, wantErr: ErrDotenvInvalid},
+		{name: "multi-line value", vars: map[string]string{"SLIPPY_ID": "slip-1\nslip-2"}, wantErr: ErrDotenvInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "slippy.env")
+
+			err := WriteDotenv(path, tt.vars)
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				assert.NoFileExists(t, path)
+				return
+			}
+			require.NoError(t, err)
+			content, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(content))
+		})
+	}
+}
+
+func TestWriteDotenv_Unwritable(t *testing.T) {
+	err := WriteDotenv(filepath.Join(t.TempDir(), "missing", "slippy.env"), map[string]string{"A": "b"})
+
+	assert.ErrorContains(t, err, "failed to write dotenv report")
+}
@@ -0,0 +1,32 @@
+package output
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+)
+
+// WriteOctopusVariables writes one Octopus Deploy setVariable service message
+// per variable to w, sorted by name. Octopus reads them from a script step's
+// output and exposes each as an output variable of the step. Names and values
+// are base64 encoded, as the service message format requires, so any value is
+// passed through unchanged.
+func WriteOctopusVariables(w io.Writer, vars map[string]string) error {
+	bw := bufio.NewWriter(w)
+	for _, name := range slices.Sorted(maps.Keys(vars)) {
+		fmt.Fprintf(bw, "##octopus[setVariable name='%s' value='%s']\n", encodeServiceMessage(name),
+			encodeServiceMessage(vars[name]))
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write Octopus service messages: %w", err)
+	}
+	return nil
+}
+
+// encodeServiceMessage encodes a service message attribute.
+func encodeServiceMessage(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
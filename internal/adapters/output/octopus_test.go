@@ -0,0 +1,54 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("broken pipe")
+}
+
+func TestWriteOctopusVariables(t *testing.T) {
+	tests := []struct {
+		name string
+		vars map[string]string
+		want string
+	}{
+		{
+			name: "sorted by name",
+			vars: map[string]string{"SlippyResolvedBy": "ancestry", "SlippyCorrelationId": "slip-1"},
+			want: "##octopus[setVariable name='U2xpcHB5Q29ycmVsYXRpb25JZA==' value='c2xpcC0x']\n" +
+				"##octopus[setVariable name='U2xpcHB5UmVzb2x2ZWRCeQ==' value='YW5jZXN0cnk=']\n",
+		},
+		{
+			name: "empty value",
+			vars: map[string]string{"SlippyComponent": ""},
+			want: "##octopus[setVariable name='U2xpcHB5Q29tcG9uZW50' value='']\n",
+		},
+		{name: "none", vars: map[string]string{}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			require.NoError(t, WriteOctopusVariables(&buf, tt.vars))
+
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestWriteOctopusVariables_Unwritable(t *testing.T) {
+	err := WriteOctopusVariables(failingWriter{}, map[string]string{"SlippyCorrelationId": "slip-1"})
+
+	assert.ErrorContains(t, err, "failed to write Octopus service messages: broken pipe")
+}
@@ -0,0 +1,68 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// SpinnakerArtifactType is the Spinnaker artifact type results are written as.
+const SpinnakerArtifactType = "custom/object"
+
+// spinnakerArtifact is a Spinnaker artifact. reference carries the correlation
+// ID, the value stages usually need; metadata carries the rest of the result.
+type spinnakerArtifact struct {
+	Type       string            `json:"type"`
+	CustomKind bool              `json:"customKind"`
+	Name       string            `json:"name"`
+	Reference  string            `json:"reference"`
+	Version    string            `json:"version,omitempty"`
+	Metadata   map[string]string `json:"metadata"`
+}
+
+// spinnakerPayload is the body of a Spinnaker webhook trigger.
+type spinnakerPayload struct {
+	Artifacts []spinnakerArtifact `json:"artifacts"`
+}
+
+// WriteSpinnakerArtifact writes result to path as the payload of a Spinnaker
+// webhook trigger carrying one custom/object artifact, named after the
+// repository, whose reference is the correlation ID and version the matched
+// commit. The payload can be sent to Gate's /webhooks/webhook/<source> as is.
+func WriteSpinnakerArtifact(path string, result *domain.ResolveOutput) error {
+	metadata := map[string]string{
+		"correlationId": result.CorrelationID,
+		"matchedCommit": result.MatchedCommit,
+		"repository":    result.Repository,
+		"resolvedBy":    result.ResolvedBy,
+		"confidence":    string(result.Confidence),
+	}
+	if result.Branch != "" {
+		metadata["branch"] = result.Branch
+	}
+	if result.Component != "" {
+		metadata["component"] = result.Component
+	}
+	name := "slippy-find"
+	if result.Repository != "" {
+		name += "/" + result.Repository
+	}
+
+	data, err := json.MarshalIndent(spinnakerPayload{Artifacts: []spinnakerArtifact{{
+		Type:       SpinnakerArtifactType,
+		CustomKind: true,
+		Name:       name,
+		Reference:  result.CorrelationID,
+		Version:    result.MatchedCommit,
+		Metadata:   metadata,
+	}}}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode Spinnaker artifact: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write Spinnaker artifact: %w", err)
+	}
+	return nil
+}
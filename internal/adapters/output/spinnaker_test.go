@@ -0,0 +1,71 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+func TestWriteSpinnakerArtifact(t *testing.T) {
+	tests := []struct {
+		name   string
+		result *domain.ResolveOutput
+		want   string
+	}{
+		{
+			name: "component",
+			result: &domain.ResolveOutput{
+				CorrelationID: "slip-1",
+				MatchedCommit: "c1",
+				Repository:    "org/repo",
+				Branch:        "main",
+				ResolvedBy:    domain.ResolvedByAncestry,
+				Confidence:    domain.ConfidenceExactHead,
+				Component:     "web",
+			},
+			want: `{"artifacts":[{
+				"type": "custom/object", "customKind": true, "name": "slippy-find/org/repo",
+				"reference": "slip-1", "version": "c1",
+				"metadata": {
+					"correlationId": "slip-1", "matchedCommit": "c1", "repository": "org/repo",
+					"resolvedBy": "ancestry", "confidence": "exact-head", "branch": "main", "component": "web"
+				}
+			}]}`,
+		},
+		{
+			name:   "unknown repository",
+			result: &domain.ResolveOutput{CorrelationID: "slip-1", ResolvedBy: "override"},
+			want: `{"artifacts":[{
+				"type": "custom/object", "customKind": true, "name": "slippy-find", "reference": "slip-1",
+				"metadata": {
+					"correlationId": "slip-1", "matchedCommit": "", "repository": "",
+					"resolvedBy": "override", "confidence": ""
+				}
+			}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "artifact.json")
+
+			require.NoError(t, WriteSpinnakerArtifact(path, tt.result))
+
+			content, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(content))
+		})
+	}
+}
+
+func TestWriteSpinnakerArtifact_Unwritable(t *testing.T) {
+	err := WriteSpinnakerArtifact(filepath.Join(t.TempDir(), "missing", "artifact.json"),
+		&domain.ResolveOutput{CorrelationID: "slip-1"})
+
+	assert.ErrorContains(t, err, "failed to write Spinnaker artifact")
+}
@@ -0,0 +1,74 @@
+package queue
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnsupportedQueue indicates a queue URL with a scheme other than nats or kafka.
+var ErrUnsupportedQueue = errors.New("unsupported queue: use nats://host:port or kafka://broker:port[,broker:port...]")
+
+// Open connects to the queue at rawURL and consumes requests from subject
+// (a NATS subject or Kafka topic) as a member of group.
+//
+// rawURL is either nats://host:port, passed to the NATS client as is, or
+// kafka://broker:port[,broker:port...].
+func Open(rawURL, subject, group string) (Broker, error) {
+	scheme, rest, ok := strings.Cut(rawURL, "://")
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedQueue, rawURL)
+	}
+	switch scheme {
+	case "nats", "tls":
+		return newNATSBroker(rawURL, subject, group)
+	case "kafka":
+		brokers, err := parseKafkaBrokers(rest)
+		if err != nil {
+			return nil, err
+		}
+		return newKafkaBroker(brokers, subject, group), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedQueue, rawURL)
+	}
+}
+
+// OpenPublisher connects to the queue at rawURL, in the form Open accepts, to
+// publish messages without consuming any.
+func OpenPublisher(rawURL string) (Publisher, error) {
+	scheme, rest, ok := strings.Cut(rawURL, "://")
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedQueue, rawURL)
+	}
+	switch scheme {
+	case "nats", "tls":
+		return newNATSPublisher(rawURL)
+	case "kafka":
+		brokers, err := parseKafkaBrokers(rest)
+		if err != nil {
+			return nil, err
+		}
+		return newKafkaPublisher(brokers), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedQueue, rawURL)
+	}
+}
+
+// parseKafkaBrokers splits a comma-separated list of host:port addresses.
+func parseKafkaBrokers(list string) ([]string, error) {
+	var brokers []string
+	for broker := range strings.SplitSeq(list, ",") {
+		broker = strings.TrimSpace(broker)
+		if broker == "" {
+			continue
+		}
+		if !strings.Contains(broker, ":") || strings.Contains(broker, "/") {
+			return nil, fmt.Errorf("invalid Kafka broker %q: expected host:port", broker)
+		}
+		brokers = append(brokers, broker)
+	}
+	if len(brokers) == 0 {
+		return nil, errors.New("no Kafka brokers given")
+	}
+	return brokers, nil
+}
@@ -0,0 +1,89 @@
+package queue
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpen(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		wantErr string
+	}{
+		{name: "kafka", url: "kafka://b1:9092,b2:9092"},
+		{name: "no scheme", url: "b1:9092", wantErr: "unsupported queue"},
+		{name: "unknown scheme", url: "amqp://rabbit:5672", wantErr: "unsupported queue"},
+		{name: "kafka without brokers", url: "kafka://", wantErr: "no Kafka brokers given"},
+		{name: "nats unreachable", url: "nats://127.0.0.1:1", wantErr: "failed to connect to NATS at nats://127.0.0.1:1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broker, err := Open(tt.url, "slippy.resolve", "slippy-find")
+
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.NoError(t, broker.Close())
+		})
+	}
+}
+
+func TestOpenPublisher(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		wantErr string
+	}{
+		{name: "kafka", url: "kafka://b1:9092,b2:9092"},
+		{name: "unknown scheme", url: "amqp://rabbit:5672", wantErr: "unsupported queue"},
+		{name: "kafka without brokers", url: "kafka://", wantErr: "no Kafka brokers given"},
+		{name: "nats unreachable", url: "nats://127.0.0.1:1", wantErr: "failed to connect to NATS at nats://127.0.0.1:1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pub, err := OpenPublisher(tt.url)
+
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.NoError(t, pub.Close())
+		})
+	}
+}
+
+func TestParseKafkaBrokers(t *testing.T) {
+	tests := []struct {
+		name    string
+		list    string
+		want    []string
+		wantErr string
+	}{
+		{name: "one broker", list: "b1:9092", want: []string{"b1:9092"}},
+		{name: "several brokers", list: "b1:9092, b2:9092,", want: []string{"b1:9092", "b2:9092"}},
+		{name: "missing port", list: "b1", wantErr: `invalid Kafka broker "b1"`},
+		{name: "path", list: "b1:9092/topic", wantErr: `invalid Kafka broker "b1:9092/topic"`},
+		{name: "empty", list: " , ", wantErr: "no Kafka brokers given"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			brokers, err := parseKafkaBrokers(tt.list)
+
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, brokers)
+		})
+	}
+}
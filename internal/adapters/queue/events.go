@@ -0,0 +1,99 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// publishTimeout bounds connecting and publishing a run's events, so an
+// unreachable queue cannot hold up the build.
+const publishTimeout = 10 * time.Second
+
+// Publisher sends messages to a queue without consuming from it.
+type Publisher interface {
+	// Publish sends data to topic.
+	Publish(ctx context.Context, topic string, data []byte) error
+
+	// Close releases the connection.
+	Close() error
+}
+
+// eventJSON is a successful resolution published for downstream consumers.
+type eventJSON struct {
+	Timestamp     time.Time `json:"timestamp"`
+	Repository    string    `json:"repository"`
+	Branch        string    `json:"branch,omitempty"`
+	HeadSHA       string    `json:"head_sha,omitempty"`
+	MatchedCommit string    `json:"matched_commit"`
+	CorrelationID string    `json:"correlation_id"`
+	ResolvedBy    string    `json:"resolved_by"`
+	Component     string    `json:"component,omitempty"`
+	Pipeline      string    `json:"pipeline,omitempty"`
+	Host          string    `json:"host,omitempty"`
+	DurationMS    float64   `json:"duration_ms"`
+}
+
+// newEventJSON converts event to its JSON representation.
+func newEventJSON(event domain.AuditEvent) eventJSON {
+	return eventJSON{
+		Timestamp:     event.Timestamp.UTC(),
+		Repository:    event.Repository,
+		Branch:        event.Branch,
+		HeadSHA:       event.HeadSHA,
+		MatchedCommit: event.MatchedCommit,
+		CorrelationID: event.CorrelationID,
+		ResolvedBy:    event.ResolvedBy,
+		Component:     event.Component,
+		Pipeline:      event.Caller.Pipeline,
+		Host:          event.Caller.Host,
+		DurationMS:    float64(event.Duration) / float64(time.Millisecond),
+	}
+}
+
+// EventPublisher implements domain.ResolutionPublisher, publishing each event
+// as a JSON message to one NATS subject or Kafka topic. It connects for each
+// call, as a CLI run publishes once before it exits.
+type EventPublisher struct {
+	url   string
+	topic string
+	open  func(rawURL string) (Publisher, error)
+}
+
+// NewEventPublisher creates a publisher to topic on the queue at rawURL, in
+// the form Open accepts. It does not connect until events are published.
+func NewEventPublisher(rawURL, topic string) *EventPublisher {
+	return &EventPublisher{url: rawURL, topic: topic, open: OpenPublisher}
+}
+
+// PublishResolutions implements domain.ResolutionPublisher.
+func (p *EventPublisher) PublishResolutions(ctx context.Context, events []domain.AuditEvent) (err error) {
+	if len(events) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, publishTimeout)
+	defer cancel()
+
+	pub, err := p.open(p.url)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, pub.Close())
+	}()
+
+	for _, event := range events {
+		data, err := json.Marshal(newEventJSON(event))
+		if err != nil {
+			return fmt.Errorf("failed to encode resolution event: %w", err)
+		}
+		if err := pub.Publish(ctx, p.topic, data); err != nil {
+			return fmt.Errorf("failed to publish resolution event to %s: %w", p.topic, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// fakePublisher records published messages.
+type fakePublisher struct {
+	topics     []string
+	messages   []map[string]interface{}
+	publishErr error
+	closed     bool
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, topic string, data []byte) error {
+	if _, ok := ctx.Deadline(); !ok {
+		return errors.New("publish without a deadline")
+	}
+	if p.publishErr != nil {
+		return p.publishErr
+	}
+	var msg map[string]interface{}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return err
+	}
+	p.topics = append(p.topics, topic)
+	p.messages = append(p.messages, msg)
+	return nil
+}
+
+func (p *fakePublisher) Close() error {
+	p.closed = true
+	return nil
+}
+
+// testEventPublisher returns an EventPublisher to "slippy.resolved" that
+// publishes through pub.
+func testEventPublisher(pub Publisher, openErr error) *EventPublisher {
+	p := NewEventPublisher("nats://nats:4222", "slippy.resolved")
+	p.open = func(string) (Publisher, error) {
+		if openErr != nil {
+			return nil, openErr
+		}
+		return pub, nil
+	}
+	return p
+}
+
+func TestEventPublisher_PublishResolutions(t *testing.T) {
+	pub := &fakePublisher{}
+	at := time.Date(2026, 10, 16, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+
+	err := testEventPublisher(pub, nil).PublishResolutions(context.Background(), []domain.AuditEvent{
+		{
+			Timestamp:     at,
+			Repository:    "org/repo",
+			Branch:        "main",
+			HeadSHA:       "c0",
+			MatchedCommit: "c1",
+			CorrelationID: "slip-1",
+			ResolvedBy:    domain.ResolvedByAncestry,
+			Caller:        domain.Annotation{Host: "runner-1", Pipeline: "build/42"},
+			Duration:      1500 * time.Microsecond,
+		},
+		{Timestamp: at, Repository: "org/repo", CorrelationID: "slip-web", Component: "web"},
+	})
+
+	require.NoError(t, err)
+	assert.True(t, pub.closed)
+	assert.Equal(t, []string{"slippy.resolved", "slippy.resolved"}, pub.topics)
+	require.Len(t, pub.messages, 2)
+	assert.Equal(t, map[string]interface{}{
+		"timestamp":      "2026-10-16T10:00:00Z",
+		"repository":     "org/repo",
+		"branch":         "main",
+		"head_sha":       "c0",
+		"matched_commit": "c1",
+		"correlation_id": "slip-1",
+		"resolved_by":    domain.ResolvedByAncestry,
+		"pipeline":       "build/42",
+		"host":           "runner-1",
+		"duration_ms":    1.5,
+	}, pub.messages[0])
+	assert.Equal(t, "web", pub.messages[1]["component"])
+}
+
+func TestEventPublisher_PublishResolutions_NoEvents(t *testing.T) {
+	p := testEventPublisher(nil, errors.New("must not connect"))
+
+	assert.NoError(t, p.PublishResolutions(context.Background(), nil))
+}
+
+func TestEventPublisher_PublishResolutions_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		pub     *fakePublisher
+		openErr error
+		wantMsg string
+	}{
+		{
+			name:    "connect fails",
+			openErr: errors.New("failed to connect to NATS at nats://nats:4222"),
+			wantMsg: "failed to connect to NATS",
+		},
+		{
+			name:    "publish fails",
+			pub:     &fakePublisher{publishErr: errors.New("no responders")},
+			wantMsg: "failed to publish resolution event to slippy.resolved: no responders",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := testEventPublisher(tt.pub, tt.openErr).PublishResolutions(context.Background(),
+				[]domain.AuditEvent{{CorrelationID: "slip-1"}})
+
+			assert.ErrorContains(t, err, tt.wantMsg)
+			if tt.pub != nil {
+				assert.True(t, tt.pub.closed)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package queue
+
+import (
+	"context"
+	"errors"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// kafkaBroker consumes a Kafka topic as part of a consumer group. A message's
+// offset is committed by Ack. Kafka commits are cumulative, so with more than
+// one request in flight a crash can skip requests whose successors were
+// already committed.
+type kafkaBroker struct {
+	reader *kafka.Reader
+	writer *kafka.Writer
+}
+
+// newKafkaBroker creates a consumer of topic in group on brokers. Connections
+// are opened lazily.
+func newKafkaBroker(brokers []string, topic, group string) *kafkaBroker {
+	return &kafkaBroker{
+		reader: kafka.NewReader(kafka.ReaderConfig{
+			Brokers: brokers,
+			GroupID: group,
+			Topic:   topic,
+		}),
+		writer: &kafka.Writer{
+			Addr:     kafka.TCP(brokers...),
+			Balancer: &kafka.Hash{},
+		},
+	}
+}
+
+// Consume implements Broker.
+func (b *kafkaBroker) Consume(ctx context.Context, handle func(ctx context.Context, msg Message)) error {
+	for {
+		m, err := b.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		handle(ctx, Message{
+			Data: m.Value,
+			Ack: func(ctx context.Context) error {
+				return b.reader.CommitMessages(ctx, m)
+			},
+		})
+	}
+}
+
+// Publish implements Broker.
+func (b *kafkaBroker) Publish(ctx context.Context, topic string, data []byte) error {
+	return b.writer.WriteMessages(ctx, kafka.Message{Topic: topic, Value: data})
+}
+
+// Close implements Broker.
+func (b *kafkaBroker) Close() error {
+	return errors.Join(b.reader.Close(), b.writer.Close())
+}
+
+// kafkaPublisher writes messages to Kafka, waiting for the partition leader to
+// acknowledge each one.
+type kafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+// newKafkaPublisher creates a publisher to brokers. Connections are opened
+// lazily.
+func newKafkaPublisher(brokers []string) *kafkaPublisher {
+	return &kafkaPublisher{writer: &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireOne,
+	}}
+}
+
+// Publish implements Publisher.
+func (p *kafkaPublisher) Publish(ctx context.Context, topic string, data []byte) error {
+	return p.writer.WriteMessages(ctx, kafka.Message{Topic: topic, Value: data})
+}
+
+// Close implements Publisher.
+func (p *kafkaPublisher) Close() error {
+	return p.writer.Close()
+}
@@ -0,0 +1,131 @@
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// requestJSON is a resolution request consumed from the queue.
+type requestJSON struct {
+	// ID is echoed in the reply so the requester can match them up.
+	ID              string   `json:"id,omitempty"`
+	Repository      string   `json:"repository"`
+	Branch          string   `json:"branch,omitempty"`
+	Commits         []string `json:"commits"`
+	Depth           int      `json:"depth,omitempty"`
+	SelectionPolicy string   `json:"selection_policy,omitempty"`
+
+	// ReplyTo is the subject or topic to publish the reply to. When empty,
+	// the transport's own reply address is used.
+	ReplyTo string `json:"reply_to,omitempty"`
+
+	policy domain.SelectionPolicy
+}
+
+// replyJSON is published for every request: Result on success, otherwise
+// Error and Code.
+type replyJSON struct {
+	ID     string      `json:"id,omitempty"`
+	Result *resultJSON `json:"result,omitempty"`
+	Error  string      `json:"error,omitempty"`
+	Code   string      `json:"code,omitempty"`
+}
+
+// resultJSON is a successful resolution. It has the same fields as the CLI's
+// --format json output.
+type resultJSON struct {
+	CorrelationID   string             `json:"correlation_id"`
+	MatchedCommit   string             `json:"matched_commit"`
+	Repository      string             `json:"repository"`
+	Branch          string             `json:"branch,omitempty"`
+	DefaultBranch   string             `json:"default_branch,omitempty"`
+	ResolvedBy      string             `json:"resolved_by"`
+	SelectionPolicy string             `json:"selection_policy"`
+	Candidates      int                `json:"candidates"`
+	Depth           int                `json:"depth"`
+	Distance        int                `json:"distance"`
+	Confidence      string             `json:"confidence"`
+	Component       string             `json:"component,omitempty"`
+	Timings         map[string]float64 `json:"timings_ms,omitempty"`
+}
+
+// decodeRequest parses and validates a request. The fields decoded before a
+// validation error, such as ID and ReplyTo, are still returned so the error
+// can be reported.
+func decodeRequest(data []byte) (requestJSON, error) {
+	var req requestJSON
+	if err := json.Unmarshal(data, &req); err != nil {
+		return requestJSON{}, errors.New("invalid request: " + err.Error())
+	}
+
+	switch {
+	case req.Repository == "" || len(req.Commits) == 0:
+		return req, errors.New("repository and commits are required")
+	case req.Depth < 0:
+		return req, errors.New("depth cannot be negative")
+	}
+	repository, err := domain.ParseRepository(req.Repository)
+	if err != nil {
+		return req, err
+	}
+	req.Repository = repository
+	if req.Commits, err = domain.ParseCommitList(req.Commits); err != nil {
+		return req, err
+	}
+
+	policy, err := domain.ParseSelectionPolicy(req.SelectionPolicy)
+	if err != nil {
+		return req, err
+	}
+	req.policy = policy
+	return req, nil
+}
+
+// resolveRequest converts the request to a domain.ResolveRequest.
+func (r requestJSON) resolveRequest() domain.ResolveRequest {
+	return domain.ResolveRequest{
+		Repository:      r.Repository,
+		Branch:          r.Branch,
+		Commits:         r.Commits,
+		Depth:           r.Depth,
+		SelectionPolicy: r.policy,
+	}
+}
+
+// newErrorReply builds the reply to a failed request.
+func newErrorReply(id string, err error) replyJSON {
+	return replyJSON{ID: id, Error: err.Error(), Code: string(domain.CodeOf(err))}
+}
+
+// encodeReply marshals reply for publishing.
+func encodeReply(reply replyJSON) ([]byte, error) {
+	return json.Marshal(reply)
+}
+
+// newResultJSON converts result to its JSON representation.
+func newResultJSON(result *domain.ResolveOutput) *resultJSON {
+	out := &resultJSON{
+		CorrelationID:   result.CorrelationID,
+		MatchedCommit:   result.MatchedCommit,
+		Repository:      result.Repository,
+		Branch:          result.Branch,
+		DefaultBranch:   result.DefaultBranch,
+		ResolvedBy:      result.ResolvedBy,
+		SelectionPolicy: string(result.SelectionPolicy),
+		Candidates:      result.Candidates,
+		Depth:           result.Depth,
+		Distance:        result.Distance,
+		Confidence:      string(result.Confidence),
+		Component:       result.Component,
+	}
+	if len(result.Timings) > 0 {
+		out.Timings = make(map[string]float64, len(result.Timings))
+		for _, t := range result.Timings {
+			out.Timings[t.Stage] = float64(t.Duration) / float64(time.Millisecond)
+		}
+	}
+	return out
+}
@@ -0,0 +1,106 @@
+package queue
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+func TestDecodeRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    domain.ResolveRequest
+		wantErr string
+	}{
+		{
+			name: "full request",
+			data: `{"id":"r1","repository":"org/repo","branch":"main","commits":["c111111111111111111111111111111111111111"],` +
+				`"depth":10,"selection_policy":"newest-created","reply_to":"replies"}`,
+			want: domain.ResolveRequest{
+				Repository:      "org/repo",
+				Branch:          "main",
+				Commits:         []string{"c111111111111111111111111111111111111111"},
+				Depth:           10,
+				SelectionPolicy: domain.SelectionNewestCreated,
+			},
+		},
+		{
+			name: "repository normalized",
+			data: `{"repository":" github.com/org/repo.git ","commits":["c111111111111111111111111111111111111111"]}`,
+			want: domain.ResolveRequest{
+				Repository:      "org/repo",
+				Commits:         []string{"c111111111111111111111111111111111111111"},
+				SelectionPolicy: domain.DefaultSelectionPolicy,
+			},
+		},
+		{
+			name: "commits normalized",
+			data: `{"repository":"org/repo","commits":["C111111111111111111111111111111111111111","c222222222222222222222222222222222222222","c111111111111111111111111111111111111111"]}`,
+			want: domain.ResolveRequest{
+				Repository:      "org/repo",
+				Commits:         []string{"c111111111111111111111111111111111111111", "c222222222222222222222222222222222222222"},
+				SelectionPolicy: domain.DefaultSelectionPolicy,
+			},
+		},
+		{
+			name:    "malformed commit",
+			data:    `{"repository":"org/repo","commits":["c111111111111111111111111111111111111111","main"]}`,
+			wantErr: `commits[1] "main" contains 'm'`,
+		},
+		{
+			name:    "empty owner",
+			data:    `{"repository":"/repo","commits":["c111111111111111111111111111111111111111"]}`,
+			wantErr: "empty owner",
+		},
+		{
+			name:    "not json",
+			data:    `not json`,
+			wantErr: "invalid request",
+		},
+		{
+			name:    "missing commits",
+			data:    `{"repository":"org/repo"}`,
+			wantErr: "repository and commits are required",
+		},
+		{
+			name:    "missing repository",
+			data:    `{"commits":["c111111111111111111111111111111111111111"]}`,
+			wantErr: "repository and commits are required",
+		},
+		{
+			name:    "negative depth",
+			data:    `{"repository":"org/repo","commits":["c111111111111111111111111111111111111111"],"depth":-1}`,
+			wantErr: "depth cannot be negative",
+		},
+		{
+			name:    "unknown selection policy",
+			data:    `{"repository":"org/repo","commits":["c111111111111111111111111111111111111111"],"selection_policy":"oldest"}`,
+			wantErr: "oldest",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := decodeRequest([]byte(tt.data))
+
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, req.resolveRequest())
+		})
+	}
+}
+
+func TestDecodeRequest_KeepsReplyAddressOnError(t *testing.T) {
+	req, err := decodeRequest([]byte(`{"id":"r1","repository":"org/repo","reply_to":"replies"}`))
+
+	require.Error(t, err)
+	assert.Equal(t, "r1", req.ID)
+	assert.Equal(t, "replies", req.ReplyTo)
+}
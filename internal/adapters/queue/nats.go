@@ -0,0 +1,102 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// natsBuffer is the number of NATS messages held while every worker is busy.
+const natsBuffer = 64
+
+// natsFlushTimeout bounds how long a publish waits for the NATS server to
+// confirm it received the message.
+const natsFlushTimeout = 5 * time.Second
+
+// natsBroker consumes from a NATS subject as part of a queue group, so each
+// request goes to one worker. Core NATS does not redeliver, so Ack is a no-op.
+type natsBroker struct {
+	conn    *nats.Conn
+	subject string
+	group   string
+	closed  chan struct{}
+}
+
+// newNATSBroker connects to the NATS server at url.
+func newNATSBroker(url, subject, group string) (*natsBroker, error) {
+	closed := make(chan struct{})
+	conn, err := nats.Connect(url,
+		nats.Name("slippy-find"),
+		nats.ClosedHandler(func(*nats.Conn) { close(closed) }),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS at %s: %w", url, err)
+	}
+	return &natsBroker{conn: conn, subject: subject, group: group, closed: closed}, nil
+}
+
+// Consume implements Broker.
+func (b *natsBroker) Consume(ctx context.Context, handle func(ctx context.Context, msg Message)) error {
+	messages := make(chan *nats.Msg, natsBuffer)
+	sub, err := b.conn.ChanQueueSubscribe(b.subject, b.group, messages)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", b.subject, err)
+	}
+	defer func() {
+		// Close drains the connection, which also ends the subscription.
+		_ = sub.Unsubscribe()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-b.closed:
+			return errors.New("NATS connection closed")
+		case m := <-messages:
+			handle(ctx, Message{Data: m.Data, ReplyTo: m.Reply})
+		}
+	}
+}
+
+// Publish implements Broker.
+func (b *natsBroker) Publish(_ context.Context, topic string, data []byte) error {
+	return b.conn.Publish(topic, data)
+}
+
+// Close implements Broker. Pending replies are flushed first.
+func (b *natsBroker) Close() error {
+	return b.conn.Drain()
+}
+
+// natsPublisher publishes to NATS subjects.
+type natsPublisher struct {
+	conn *nats.Conn
+}
+
+// newNATSPublisher connects to the NATS server at url.
+func newNATSPublisher(url string) (*natsPublisher, error) {
+	conn, err := nats.Connect(url, nats.Name("slippy-find"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS at %s: %w", url, err)
+	}
+	return &natsPublisher{conn: conn}, nil
+}
+
+// Publish implements Publisher. It returns once the server has the message,
+// since core NATS publishes are otherwise only buffered.
+func (p *natsPublisher) Publish(_ context.Context, topic string, data []byte) error {
+	if err := p.conn.Publish(topic, data); err != nil {
+		return err
+	}
+	return p.conn.FlushTimeout(natsFlushTimeout)
+}
+
+// Close implements Publisher.
+func (p *natsPublisher) Close() error {
+	p.conn.Close()
+	return nil
+}
@@ -0,0 +1,165 @@
+// Package queue consumes resolution requests from a message queue and
+// publishes their results, for resolving slips asynchronously (worker mode).
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// Logger defines the logging interface required by the worker.
+type Logger interface {
+	Info(ctx context.Context, msg string, fields map[string]interface{})
+	Warn(ctx context.Context, msg string, fields map[string]interface{})
+	Error(ctx context.Context, msg string, err error, fields map[string]interface{})
+}
+
+// ResolveFunc resolves one request.
+type ResolveFunc func(ctx context.Context, req domain.ResolveRequest) (*domain.ResolveOutput, error)
+
+// Message is a request received from a Broker.
+type Message struct {
+	// Data is the message body.
+	Data []byte
+
+	// ReplyTo is the reply subject or topic set by the transport, if any.
+	ReplyTo string
+
+	// Ack marks the message as processed, so it is not delivered again.
+	Ack func(ctx context.Context) error
+}
+
+// Broker is a message queue connection.
+type Broker interface {
+	// Consume delivers messages to handle until ctx is done or the
+	// connection fails. handle may be called concurrently.
+	Consume(ctx context.Context, handle func(ctx context.Context, msg Message)) error
+
+	// Publish sends data to topic.
+	Publish(ctx context.Context, topic string, data []byte) error
+
+	// Close releases the connection.
+	Close() error
+}
+
+// Worker resolves requests consumed from a Broker.
+type Worker struct {
+	broker      Broker
+	resolve     ResolveFunc
+	log         Logger
+	concurrency int
+}
+
+// NewWorker creates a worker that resolves up to concurrency requests at once.
+// Values below 1 are treated as 1.
+func NewWorker(broker Broker, resolve ResolveFunc, log Logger, concurrency int) *Worker {
+	return &Worker{
+		broker:      broker,
+		resolve:     resolve,
+		log:         log,
+		concurrency: max(concurrency, 1),
+	}
+}
+
+// Run consumes requests until ctx is done, then waits for requests in flight
+// and closes the broker. A message is acknowledged only once its reply is
+// published, so a request interrupted by a crash is delivered again.
+func (w *Worker) Run(ctx context.Context) error {
+	slots := make(chan struct{}, w.concurrency)
+	var inFlight sync.WaitGroup
+
+	err := w.broker.Consume(ctx, func(msgCtx context.Context, msg Message) {
+		select {
+		case slots <- struct{}{}:
+		case <-msgCtx.Done():
+			return
+		}
+		inFlight.Add(1)
+		go func() {
+			defer func() {
+				<-slots
+				inFlight.Done()
+			}()
+			// Requests already taken are finished even when shutting down.
+			w.handle(context.WithoutCancel(msgCtx), msg)
+		}()
+	})
+	inFlight.Wait()
+
+	if closeErr := w.broker.Close(); closeErr != nil {
+		w.log.Warn(ctx, "failed to close queue connection", map[string]interface{}{
+			"error": closeErr.Error(),
+		})
+	}
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+// handle resolves one request and publishes its reply.
+func (w *Worker) handle(ctx context.Context, msg Message) {
+	start := time.Now()
+	req, err := decodeRequest(msg.Data)
+	replyTo := req.ReplyTo
+	if replyTo == "" {
+		replyTo = msg.ReplyTo
+	}
+	fields := map[string]interface{}{
+		"request_id": req.ID,
+		"repository": req.Repository,
+	}
+
+	var reply replyJSON
+	if err != nil {
+		reply = newErrorReply(req.ID, domain.WithCode(domain.CodeInvalidInput, err))
+	} else {
+		result, resolveErr := w.resolve(ctx, req.resolveRequest())
+		if resolveErr != nil {
+			reply = newErrorReply(req.ID, resolveErr)
+		} else {
+			reply = replyJSON{ID: req.ID, Result: newResultJSON(result)}
+			fields["correlation_id"] = result.CorrelationID
+		}
+	}
+	fields["duration_ms"] = time.Since(start).Milliseconds()
+	if reply.Error != "" {
+		fields["error"] = reply.Error
+		fields["code"] = reply.Code
+	}
+
+	if replyTo == "" {
+		w.log.Warn(ctx, "request has no reply address; dropping its result", fields)
+		w.ack(ctx, msg, fields)
+		return
+	}
+	data, err := encodeReply(reply)
+	if err != nil {
+		w.log.Error(ctx, "failed to encode reply", err, fields)
+		return
+	}
+	if err := w.broker.Publish(ctx, replyTo, data); err != nil {
+		// Left unacknowledged so the request is delivered again.
+		w.log.Error(ctx, "failed to publish reply", err, fields)
+		return
+	}
+	w.log.Info(ctx, "resolution request served", fields)
+	w.ack(ctx, msg, fields)
+}
+
+// ack acknowledges msg, logging a failure.
+func (w *Worker) ack(ctx context.Context, msg Message, fields map[string]interface{}) {
+	if msg.Ack == nil {
+		return
+	}
+	if err := msg.Ack(ctx); err != nil {
+		w.log.Warn(ctx, "failed to acknowledge message", map[string]interface{}{
+			"request_id": fields["request_id"],
+			"error":      err.Error(),
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// nopLogger discards log output.
+type nopLogger struct{}
+
+func (nopLogger) Info(context.Context, string, map[string]interface{})         {}
+func (nopLogger) Warn(context.Context, string, map[string]interface{})         {}
+func (nopLogger) Error(context.Context, string, error, map[string]interface{}) {}
+
+// published is a message sent through fakeBroker.Publish.
+type published struct {
+	topic string
+	reply replyJSON
+}
+
+// fakeBroker delivers a fixed list of messages, then waits for its context.
+type fakeBroker struct {
+	messages   []Message
+	consumeErr error
+	publishErr error
+	delivered  chan struct{}
+
+	mu        sync.Mutex
+	published []published
+	acked     int
+	closed    bool
+}
+
+func (b *fakeBroker) Consume(ctx context.Context, handle func(ctx context.Context, msg Message)) error {
+	for _, msg := range b.messages {
+		msg.Ack = func(context.Context) error {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			b.acked++
+			return nil
+		}
+		handle(ctx, msg)
+	}
+	close(b.delivered)
+	if b.consumeErr != nil {
+		return b.consumeErr
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (b *fakeBroker) Publish(_ context.Context, topic string, data []byte) error {
+	if b.publishErr != nil {
+		return b.publishErr
+	}
+	var reply replyJSON
+	if err := json.Unmarshal(data, &reply); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.published = append(b.published, published{topic: topic, reply: reply})
+	return nil
+}
+
+func (b *fakeBroker) Close() error {
+	b.closed = true
+	return nil
+}
+
+// runWorker runs a worker over broker until every message is handled.
+func runWorker(t *testing.T, broker *fakeBroker, resolve ResolveFunc, concurrency int) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	broker.delivered = make(chan struct{})
+	go func() { done <- NewWorker(broker, resolve, nopLogger{}, concurrency).Run(ctx) }()
+	// Run waits for requests in flight before returning.
+	<-broker.delivered
+	cancel()
+	require.NoError(t, <-done)
+	assert.True(t, broker.closed)
+}
+
+func TestWorker_Run(t *testing.T) {
+	var mu sync.Mutex
+	var got []domain.ResolveRequest
+	resolve := func(_ context.Context, req domain.ResolveRequest) (*domain.ResolveOutput, error) {
+		mu.Lock()
+		got = append(got, req)
+		mu.Unlock()
+		if req.Repository == "org/missing" {
+			return nil, domain.WithCode(domain.CodeNoSlip, errors.New("no slip found in commit ancestry"))
+		}
+		return &domain.ResolveOutput{
+			CorrelationID: "slip-1",
+			MatchedCommit: req.Commits[0],
+			Repository:    req.Repository,
+			Timings:       []domain.StageTiming{{Stage: domain.StageStoreQuery, Duration: 2 * time.Millisecond}},
+		}, nil
+	}
+	broker := &fakeBroker{messages: []Message{
+		{Data: []byte(`{"id":"r1","repository":"org/repo","commits":["c111111111111111111111111111111111111111","c222222222222222222222222222222222222222"],"depth":5,"reply_to":"replies.a"}`)},
+		{Data: []byte(`{"id":"r2","repository":"org/missing","commits":["c333333333333333333333333333333333333333"]}`), ReplyTo: "_INBOX.1"},
+		{Data: []byte(`{"id":"r3","repository":"org/repo"}`), ReplyTo: "_INBOX.2"},
+	}}
+
+	runWorker(t, broker, resolve, 1)
+
+	assert.Equal(t, []domain.ResolveRequest{
+		{Repository: "org/repo", Commits: []string{"c111111111111111111111111111111111111111", "c222222222222222222222222222222222222222"}, Depth: 5, SelectionPolicy: domain.DefaultSelectionPolicy},
+		{Repository: "org/missing", Commits: []string{"c333333333333333333333333333333333333333"}, SelectionPolicy: domain.DefaultSelectionPolicy},
+	}, got)
+	assert.Equal(t, []published{
+		{topic: "replies.a", reply: replyJSON{ID: "r1", Result: &resultJSON{
+			CorrelationID: "slip-1",
+			MatchedCommit: "c111111111111111111111111111111111111111",
+			Repository:    "org/repo",
+			Timings:       map[string]float64{domain.StageStoreQuery: 2},
+		}}},
+		{topic: "_INBOX.1", reply: replyJSON{
+			ID: "r2", Error: "no slip found in commit ancestry", Code: string(domain.CodeNoSlip),
+		}},
+		{topic: "_INBOX.2", reply: replyJSON{
+			ID: "r3", Error: "repository and commits are required", Code: string(domain.CodeInvalidInput),
+		}},
+	}, broker.published)
+	assert.Equal(t, 3, broker.acked)
+}
+
+func TestWorker_Run_NoReplyAddress(t *testing.T) {
+	broker := &fakeBroker{messages: []Message{
+		{Data: []byte(`{"repository":"org/repo","commits":["c111111111111111111111111111111111111111"]}`)},
+	}}
+
+	runWorker(t, broker, func(context.Context, domain.ResolveRequest) (*domain.ResolveOutput, error) {
+		return &domain.ResolveOutput{CorrelationID: "slip-1"}, nil
+	}, 1)
+
+	assert.Empty(t, broker.published)
+	assert.Equal(t, 1, broker.acked, "a request that cannot be answered is not redelivered")
+}
+
+func TestWorker_Run_PublishFailure(t *testing.T) {
+	broker := &fakeBroker{
+		messages:   []Message{{Data: []byte(`{"repository":"org/repo","commits":["c111111111111111111111111111111111111111"]}`), ReplyTo: "_INBOX.1"}},
+		publishErr: errors.New("connection reset"),
+	}
+
+	runWorker(t, broker, func(context.Context, domain.ResolveRequest) (*domain.ResolveOutput, error) {
+		return &domain.ResolveOutput{CorrelationID: "slip-1"}, nil
+	}, 1)
+
+	assert.Equal(t, 0, broker.acked, "an unanswered request must be delivered again")
+}
+
+func TestWorker_Run_Concurrency(t *testing.T) {
+	const concurrency = 3
+	var messages []Message
+	for range 9 {
+		messages = append(messages, Message{
+			Data:    []byte(`{"repository":"org/repo","commits":["c111111111111111111111111111111111111111"]}`),
+			ReplyTo: "_INBOX.1",
+		})
+	}
+	broker := &fakeBroker{messages: messages}
+	var mu sync.Mutex
+	var active, peak int
+
+	runWorker(t, broker, func(context.Context, domain.ResolveRequest) (*domain.ResolveOutput, error) {
+		mu.Lock()
+		active++
+		peak = max(peak, active)
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		active--
+		mu.Unlock()
+		return &domain.ResolveOutput{CorrelationID: "slip-1"}, nil
+	}, concurrency)
+
+	assert.Len(t, broker.published, 9)
+	assert.LessOrEqual(t, peak, concurrency)
+}
+
+func TestWorker_Run_ConsumeError(t *testing.T) {
+	broker := &fakeBroker{consumeErr: errors.New("NATS connection closed"), delivered: make(chan struct{})}
+
+	err := NewWorker(broker, nil, nopLogger{}, 1).Run(context.Background())
+
+	assert.EqualError(t, err, "NATS connection closed")
+	assert.True(t, broker.closed)
+}
@@ -0,0 +1,63 @@
+package reporting
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  string
+		want string
+	}{
+		{name: "no credentials", msg: "dial tcp ch:9000: refused", want: "dial tcp ch:9000: refused"},
+		{
+			name: "url userinfo",
+			msg:  "open clickhouse://svc:s3cr3t@ch:9000/ci: refused",
+			want: "open clickhouse://REDACTED@ch:9000/ci: refused",
+		},
+		{name: "user only", msg: "https://token@git.example.com/org/repo", want: "https://REDACTED@git.example.com/org/repo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, sanitize(tt.msg))
+		})
+	}
+}
+
+// recordingReporter records reports for Multi tests.
+type recordingReporter struct {
+	reports []map[string]string
+	err     error
+	drained bool
+}
+
+func (r *recordingReporter) Report(_ context.Context, _ error, tags map[string]string) error {
+	r.reports = append(r.reports, tags)
+	return r.err
+}
+
+func (r *recordingReporter) Flush(_ time.Duration) bool {
+	return r.drained
+}
+
+func TestMulti(t *testing.T) {
+	failErr := errors.New("unreachable")
+	failing := &recordingReporter{err: failErr, drained: false}
+	working := &recordingReporter{drained: true}
+	multi := Multi{failing, working}
+
+	err := multi.Report(context.Background(), errors.New("boom"), map[string]string{"error_code": "SLIPPY_E014"})
+
+	assert.ErrorIs(t, err, failErr)
+	assert.Len(t, failing.reports, 1)
+	assert.Len(t, working.reports, 1, "a failing reporter must not stop the others")
+	assert.False(t, multi.Flush(time.Second))
+	assert.True(t, Multi{working}.Flush(time.Second))
+}
@@ -0,0 +1,66 @@
+package reporting
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// Sentry reports failures as Sentry events. Events carry only the sanitized
+// error message and the given tags; request and user data are never attached.
+type Sentry struct {
+	client *sentry.Client
+	hub    *sentry.Hub
+	tags   map[string]string
+}
+
+// NewSentry creates a Sentry reporter for dsn. Every event is tagged with tags
+// and attributed to release and environment.
+func NewSentry(dsn, environment, release string, tags map[string]string) (*Sentry, error) {
+	return newSentry(sentry.ClientOptions{
+		Dsn:         dsn,
+		Environment: environment,
+		Release:     release,
+	}, tags)
+}
+
+// newSentry creates a Sentry reporter from options, e.g. with a test transport.
+func newSentry(options sentry.ClientOptions, tags map[string]string) (*Sentry, error) {
+	options.SendDefaultPII = false
+	options.AttachStacktrace = false
+	client, err := sentry.NewClient(options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Sentry client: %w", err)
+	}
+	return &Sentry{
+		client: client,
+		hub:    sentry.NewHub(client, sentry.NewScope()),
+		tags:   tags,
+	}, nil
+}
+
+// Report queues err as an error event. Events are grouped by the error_code
+// tag when present, so one systemic failure is one Sentry issue.
+func (s *Sentry) Report(_ context.Context, err error, tags map[string]string) error {
+	merged := mergeTags(s.tags, tags)
+	msg := sanitize(err.Error())
+
+	event := sentry.NewEvent()
+	event.Level = sentry.LevelError
+	event.Message = msg
+	event.Tags = merged
+	event.Exception = []sentry.Exception{{Type: "error", Value: msg}}
+	if code := merged["error_code"]; code != "" {
+		event.Exception[0].Type = code
+		event.Fingerprint = []string{code}
+	}
+	s.hub.CaptureEvent(event)
+	return nil
+}
+
+// Flush waits up to timeout for queued events to be sent.
+func (s *Sentry) Flush(timeout time.Duration) bool {
+	return s.client.Flush(timeout)
+}
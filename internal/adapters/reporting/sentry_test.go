@@ -0,0 +1,50 @@
+package reporting
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSentry_Report(t *testing.T) {
+	transport := &sentry.MockTransport{}
+	reporter, err := newSentry(sentry.ClientOptions{
+		Dsn:       "https://public@sentry.example.com/1",
+		Release:   "v1.2.3",
+		Transport: transport,
+	}, map[string]string{"backend": "clickhouse"})
+	require.NoError(t, err)
+
+	reportErr := reporter.Report(context.Background(),
+		errors.New("database error: dial clickhouse://svc:s3cr3t@ch:9000: refused"),
+		map[string]string{"error_code": "SLIPPY_E014", "repository": "org/repo"})
+	require.NoError(t, reportErr)
+	assert.True(t, reporter.Flush(time.Second))
+
+	events := transport.Events()
+	require.Len(t, events, 1)
+	event := events[0]
+	assert.Equal(t, sentry.LevelError, event.Level)
+	assert.Equal(t, "database error: dial clickhouse://REDACTED@ch:9000: refused", event.Message)
+	assert.NotContains(t, event.Message, "s3cr3t")
+	assert.Equal(t, "v1.2.3", event.Release)
+	assert.Equal(t, map[string]string{
+		"backend":    "clickhouse",
+		"error_code": "SLIPPY_E014",
+		"repository": "org/repo",
+	}, event.Tags)
+	assert.Equal(t, []string{"SLIPPY_E014"}, event.Fingerprint)
+	require.Len(t, event.Exception, 1)
+	assert.Equal(t, "SLIPPY_E014", event.Exception[0].Type)
+}
+
+func TestNewSentry_InvalidDSN(t *testing.T) {
+	_, err := NewSentry("not a dsn", "", "", nil)
+
+	assert.Error(t, err)
+}
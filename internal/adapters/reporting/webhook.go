@@ -0,0 +1,74 @@
+package reporting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// webhookTimeout bounds one webhook delivery; reporting must not hold up the build.
+const webhookTimeout = 5 * time.Second
+
+// Webhook reports failures by POSTing a JSON document to a URL.
+type Webhook struct {
+	url     string
+	client  *http.Client
+	release string
+	tags    map[string]string
+}
+
+// webhookPayload is the JSON document sent for each failure.
+type webhookPayload struct {
+	Error     string            `json:"error"`
+	Release   string            `json:"release,omitempty"`
+	Tags      map[string]string `json:"tags"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+// NewWebhook creates a reporter POSTing to url. Every payload is tagged with
+// tags and attributed to release.
+func NewWebhook(url, release string, tags map[string]string) *Webhook {
+	return &Webhook{
+		url:     url,
+		client:  &http.Client{Timeout: webhookTimeout},
+		release: release,
+		tags:    tags,
+	}
+}
+
+// Report POSTs err synchronously. Any non-2xx response is an error.
+func (w *Webhook) Report(ctx context.Context, err error, tags map[string]string) error {
+	body, marshalErr := json.Marshal(webhookPayload{
+		Error:     sanitize(err.Error()),
+		Release:   w.release,
+		Tags:      mergeTags(w.tags, tags),
+		Timestamp: time.Now().UTC(),
+	})
+	if marshalErr != nil {
+		return fmt.Errorf("failed to encode error report: %w", marshalErr)
+	}
+
+	req, reqErr := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if reqErr != nil {
+		return fmt.Errorf("failed to build error report request: %w", reqErr)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, doErr := w.client.Do(req)
+	if doErr != nil {
+		return fmt.Errorf("failed to send error report: %w", doErr)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("error report rejected: %s", resp.Status)
+	}
+	return nil
+}
+
+// Flush is a no-op: reports are delivered synchronously.
+func (w *Webhook) Flush(_ time.Duration) bool {
+	return true
+}
@@ -0,0 +1,49 @@
+package reporting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhook_Report(t *testing.T) {
+	var got webhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	reporter := NewWebhook(server.URL, "v1.2.3", map[string]string{"backend": "clickhouse"})
+	err := reporter.Report(context.Background(),
+		errors.New("open https://user:pw@vault.example.com: refused"),
+		map[string]string{"error_code": "SLIPPY_E012"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "open https://REDACTED@vault.example.com: refused", got.Error)
+	assert.Equal(t, "v1.2.3", got.Release)
+	assert.Equal(t, map[string]string{"backend": "clickhouse", "error_code": "SLIPPY_E012"}, got.Tags)
+	assert.False(t, got.Timestamp.IsZero())
+	assert.True(t, reporter.Flush(time.Second))
+}
+
+func TestWebhook_Report_Rejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	err := NewWebhook(server.URL, "", nil).Report(context.Background(), errors.New("boom"), nil)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "403")
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// ErrAdminWithoutTokens indicates admin endpoints were enabled without any
+// admin token to authenticate operators with.
+var ErrAdminWithoutTokens = errors.New("admin endpoints need at least one admin token")
+
+// Admin backs the admin endpoints, which let operators inspect and reset the
+// server's state without restarting it.
+type Admin struct {
+	// Tokens maps each admin bearer token to the name of the operator using
+	// it. Admin requests must present one of them, even over the unix socket.
+	Tokens map[string]string
+
+	// CacheStats reports each result cache, by store name.
+	CacheStats func() map[string]CacheStats
+
+	// FlushCache empties every result cache and returns the entries dropped.
+	FlushCache func() int
+
+	// Settings returns the effective configuration, with secrets already redacted.
+	Settings func() []Setting
+}
+
+// CacheStats describes a result cache.
+type CacheStats struct {
+	Enabled bool
+	Entries int
+	Hits    uint64
+	Misses  uint64
+	TTL     time.Duration
+}
+
+// Setting is one effective configuration value and its origin.
+type Setting struct {
+	Key    string
+	Value  string
+	Source string
+	Detail string
+}
+
+// cacheStatsJSON is one store's entry in the body of GET /admin/v1/cache.
+type cacheStatsJSON struct {
+	Enabled    bool    `json:"enabled"`
+	Entries    int     `json:"entries"`
+	Hits       uint64  `json:"hits"`
+	Misses     uint64  `json:"misses"`
+	TTLSeconds float64 `json:"ttl_seconds"`
+}
+
+// settingJSON is one entry in the body of GET /admin/v1/config.
+type settingJSON struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// adminKey is the context key of the authenticated operator's name.
+type adminKey struct{}
+
+// registerAdmin adds the admin endpoints to mux.
+func (s *Server) registerAdmin(mux *http.ServeMux) {
+	mux.Handle("GET /admin/v1/cache", s.authenticateAdmin(http.HandlerFunc(s.handleCacheStats)))
+	mux.Handle("POST /admin/v1/cache/flush", s.authenticateAdmin(http.HandlerFunc(s.handleCacheFlush)))
+	mux.Handle("GET /admin/v1/config", s.authenticateAdmin(http.HandlerFunc(s.handleConfig)))
+}
+
+// authenticateAdmin rejects requests without an admin token. Resolution
+// credentials (client tokens, certificates, the unix socket) are not enough.
+func (s *Server) authenticateAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		operator, ok := matchToken(s.cfg.Admin.Tokens, r.Header.Get("Authorization"))
+		if !ok {
+			s.log.Warn(r.Context(), "rejected admin request", map[string]interface{}{
+				"path":   r.URL.Path,
+				"remote": r.RemoteAddr,
+			})
+			w.Header().Set("WWW-Authenticate", `Bearer realm="slippy-find-admin"`)
+			writeError(w, http.StatusUnauthorized, domain.CodeInvalidInput, "missing or invalid admin token")
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), adminKey{}, operator)))
+	})
+}
+
+// handleCacheStats serves GET /admin/v1/cache.
+func (s *Server) handleCacheStats(w http.ResponseWriter, _ *http.Request) {
+	stores := make(map[string]cacheStatsJSON)
+	if s.cfg.Admin.CacheStats != nil {
+		for name, stats := range s.cfg.Admin.CacheStats() {
+			stores[name] = cacheStatsJSON{
+				Enabled:    stats.Enabled,
+				Entries:    stats.Entries,
+				Hits:       stats.Hits,
+				Misses:     stats.Misses,
+				TTLSeconds: stats.TTL.Seconds(),
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"stores": stores})
+}
+
+// handleCacheFlush serves POST /admin/v1/cache/flush.
+func (s *Server) handleCacheFlush(w http.ResponseWriter, r *http.Request) {
+	flushed := 0
+	if s.cfg.Admin.FlushCache != nil {
+		flushed = s.cfg.Admin.FlushCache()
+	}
+	operator, _ := r.Context().Value(adminKey{}).(string)
+	s.log.Info(r.Context(), "result cache flushed", map[string]interface{}{
+		"operator": operator,
+		"flushed":  flushed,
+	})
+	writeJSON(w, http.StatusOK, map[string]int{"flushed": flushed})
+}
+
+// handleConfig serves GET /admin/v1/config.
+func (s *Server) handleConfig(w http.ResponseWriter, _ *http.Request) {
+	settings := []settingJSON{}
+	if s.cfg.Admin.Settings != nil {
+		for _, setting := range s.cfg.Admin.Settings() {
+			settings = append(settings, settingJSON(setting))
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"settings": settings})
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testAdmin returns admin hooks over a cache of entries entries.
+func testAdmin(entries *int) *Admin {
+	return &Admin{
+		Tokens: map[string]string{"admin-s3cret": "oncall"},
+		CacheStats: func() map[string]CacheStats {
+			return map[string]CacheStats{
+				"default":  {Enabled: true, Entries: *entries, Hits: 7, Misses: 3, TTL: 30 * time.Second},
+				"payments": {},
+			}
+		},
+		FlushCache: func() int {
+			flushed := *entries
+			*entries = 0
+			return flushed
+		},
+		Settings: func() []Setting {
+			return []Setting{
+				{Key: "clickhouse.hostname", Value: "ch.internal", Source: "env", Detail: "CLICKHOUSE_HOSTNAME"},
+				{Key: "clickhouse.password", Value: "********", Source: "vault"},
+			}
+		},
+	}
+}
+
+// adminRequest sends method path to handler with the Authorization header auth.
+func adminRequest(handler http.Handler, method, path, auth string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAdmin_Endpoints(t *testing.T) {
+	entries := 12
+	srv, err := New(Config{Admin: testAdmin(&entries)}, clientEcho, nopLogger{})
+	require.NoError(t, err)
+	handler := srv.Handler()
+
+	rec := adminRequest(handler, http.MethodGet, "/admin/v1/cache", "Bearer admin-s3cret")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"stores": {
+		"default": {"enabled": true, "entries": 12, "hits": 7, "misses": 3, "ttl_seconds": 30},
+		"payments": {"enabled": false, "entries": 0, "hits": 0, "misses": 0, "ttl_seconds": 0}
+	}}`, rec.Body.String())
+
+	rec = adminRequest(handler, http.MethodPost, "/admin/v1/cache/flush", "Bearer admin-s3cret")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"flushed": 12}`, rec.Body.String())
+	assert.Zero(t, entries)
+
+	rec = adminRequest(handler, http.MethodGet, "/admin/v1/config", "Bearer admin-s3cret")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var body struct {
+		Settings []settingJSON `json:"settings"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, []settingJSON{
+		{Key: "clickhouse.hostname", Value: "ch.internal", Source: "env", Detail: "CLICKHOUSE_HOSTNAME"},
+		{Key: "clickhouse.password", Value: "********", Source: "vault"},
+	}, body.Settings)
+}
+
+func TestAdmin_Authentication(t *testing.T) {
+	entries := 1
+	admin := testAdmin(&entries)
+	srv, err := New(Config{Tokens: map[string]string{"client-s3cret": "team-a"}, Admin: admin}, clientEcho, nopLogger{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		auth       string
+		wantStatus int
+	}{
+		{name: "admin token", auth: "Bearer admin-s3cret", wantStatus: http.StatusOK},
+		{name: "no token", wantStatus: http.StatusUnauthorized},
+		{name: "client token", auth: "Bearer client-s3cret", wantStatus: http.StatusUnauthorized},
+		{name: "wrong scheme", auth: "Basic admin-s3cret", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := adminRequest(srv.Handler(), http.MethodPost, "/admin/v1/cache/flush", tt.auth)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+
+	t.Run("admin token cannot resolve", func(t *testing.T) {
+		rec := post(srv.Handler(), `{"path":"/repo"}`, map[string]string{"Authorization": "Bearer admin-s3cret"})
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
+
+func TestAdmin_Disabled(t *testing.T) {
+	srv, err := New(Config{}, clientEcho, nopLogger{})
+	require.NoError(t, err)
+
+	rec := adminRequest(srv.Handler(), http.MethodGet, "/admin/v1/cache", "Bearer admin-s3cret")
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestNew_AdminWithoutTokens(t *testing.T) {
+	_, err := New(Config{Admin: &Admin{}}, clientEcho, nopLogger{})
+
+	assert.ErrorIs(t, err, ErrAdminWithoutTokens)
+}
@@ -0,0 +1,259 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// maxRequestBytes bounds the size of a request body.
+const maxRequestBytes = 1 << 20
+
+// TenantHeader names the tenant whose slip store a request is resolved
+// against, on a server hosting several. Requests may instead be sent to
+// /t/{tenant}/v1/resolve.
+const TenantHeader = "X-Slippy-Tenant"
+
+// resolveRequestJSON is the body of POST /v1/resolve. Either Path, or
+// Repository and Commits, must be set.
+type resolveRequestJSON struct {
+	Path            string   `json:"path,omitempty"`
+	Repository      string   `json:"repository,omitempty"`
+	Branch          string   `json:"branch,omitempty"`
+	Commits         []string `json:"commits,omitempty"`
+	Depth           int      `json:"depth,omitempty"`
+	SelectionPolicy string   `json:"selection_policy,omitempty"`
+}
+
+// resultJSON is the response to a successful resolution. It has the same
+// fields as the CLI's --format json output.
+type resultJSON struct {
+	SchemaVersion   string             `json:"schema_version"`
+	CorrelationID   string             `json:"correlation_id"`
+	MatchedCommit   string             `json:"matched_commit"`
+	Repository      string             `json:"repository"`
+	Branch          string             `json:"branch,omitempty"`
+	DefaultBranch   string             `json:"default_branch,omitempty"`
+	ResolvedBy      string             `json:"resolved_by"`
+	SelectionPolicy string             `json:"selection_policy"`
+	Candidates      int                `json:"candidates"`
+	Depth           int                `json:"depth"`
+	Distance        int                `json:"distance"`
+	Confidence      string             `json:"confidence"`
+	Component       string             `json:"component,omitempty"`
+	Timings         map[string]float64 `json:"timings_ms,omitempty"`
+	Mock            bool               `json:"mock,omitempty"`
+}
+
+// errorJSON is the response to a failed request.
+type errorJSON struct {
+	SchemaVersion string          `json:"schema_version"`
+	Error         string          `json:"error"`
+	Code          string          `json:"code"`
+	Validation    *validationJSON `json:"validation,omitempty"`
+}
+
+// validationJSON points at the part of a request that failed validation.
+type validationJSON struct {
+	// Field is the rejected request field.
+	Field string `json:"field"`
+
+	// Index is the position of the rejected element of a list field; absent
+	// when the list as a whole was rejected.
+	Index *int `json:"index,omitempty"`
+
+	// Value is the rejected element.
+	Value string `json:"value,omitempty"`
+
+	// Reason says what is wrong.
+	Reason string `json:"reason"`
+}
+
+// newValidationJSON describes err if it is a *domain.CommitListError, and
+// returns nil otherwise.
+func newValidationJSON(err error) *validationJSON {
+	var invalid *domain.CommitListError
+	if !errors.As(err, &invalid) {
+		return nil
+	}
+	v := &validationJSON{Field: "commits", Value: invalid.Commit, Reason: invalid.Reason}
+	if invalid.Index >= 0 {
+		v.Index = &invalid.Index
+	}
+	return v
+}
+
+// handleResolve serves POST /v1/resolve and POST /t/{tenant}/v1/resolve.
+func (s *Server) handleResolve(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	start := time.Now()
+
+	tenant, err := tenantOf(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.CodeInvalidInput, err.Error())
+		return
+	}
+	req, err := decodeResolveRequest(w, r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorJSON{
+			SchemaVersion: domain.SchemaVersion,
+			Error:         err.Error(),
+			Code:          string(domain.CodeInvalidInput),
+			Validation:    newValidationJSON(err),
+		})
+		return
+	}
+	req.Tenant = tenant
+
+	result, err := s.resolve(ctx, req)
+	fields := map[string]interface{}{
+		"client":      ClientFromContext(ctx),
+		"tenant":      req.Tenant,
+		"repository":  req.Repository,
+		"path":        req.Path,
+		"duration_ms": time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		status := statusOf(err)
+		fields["status"] = status
+		if status >= http.StatusInternalServerError {
+			s.log.Error(ctx, "failed to resolve request", err, fields)
+		} else {
+			fields["error"] = err.Error()
+			s.log.Info(ctx, "resolution request served", fields)
+		}
+		writeError(w, status, domain.CodeOf(err), err.Error())
+		return
+	}
+
+	fields["status"] = http.StatusOK
+	fields["correlation_id"] = result.CorrelationID
+	s.log.Info(ctx, "resolution request served", fields)
+	writeJSON(w, http.StatusOK, newResultJSON(result))
+}
+
+// tenantOf returns the tenant named by the request's path or TenantHeader;
+// empty for the server's default store.
+func tenantOf(r *http.Request) (string, error) {
+	fromPath, fromHeader := r.PathValue("tenant"), r.Header.Get(TenantHeader)
+	if fromPath != "" && fromHeader != "" && fromPath != fromHeader {
+		return "", fmt.Errorf("tenant %q in the path conflicts with %s %q", fromPath, TenantHeader, fromHeader)
+	}
+	if fromPath != "" {
+		return fromPath, nil
+	}
+	return fromHeader, nil
+}
+
+// decodeResolveRequest reads and validates the request body.
+func decodeResolveRequest(w http.ResponseWriter, r *http.Request) (domain.ResolveRequest, error) {
+	var body resolveRequestJSON
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&body); err != nil {
+		return domain.ResolveRequest{}, errors.New("invalid request body: " + err.Error())
+	}
+
+	switch {
+	case body.Path != "" && (body.Repository != "" || len(body.Commits) > 0):
+		return domain.ResolveRequest{}, errors.New("path cannot be combined with repository or commits")
+	case body.Path == "" && (body.Repository == "" || len(body.Commits) == 0):
+		return domain.ResolveRequest{}, errors.New("either path, or repository and commits, is required")
+	case body.Depth < 0:
+		return domain.ResolveRequest{}, errors.New("depth cannot be negative")
+	}
+	if body.Path == "" {
+		commits, err := domain.ParseCommitList(body.Commits)
+		if err != nil {
+			return domain.ResolveRequest{}, err
+		}
+		body.Commits = commits
+	}
+	if body.Repository != "" {
+		repository, err := domain.ParseRepository(body.Repository)
+		if err != nil {
+			return domain.ResolveRequest{}, err
+		}
+		body.Repository = repository
+	}
+
+	policy, err := domain.ParseSelectionPolicy(body.SelectionPolicy)
+	if err != nil {
+		return domain.ResolveRequest{}, err
+	}
+	return domain.ResolveRequest{
+		Path:            body.Path,
+		Repository:      body.Repository,
+		Branch:          body.Branch,
+		Commits:         body.Commits,
+		Depth:           body.Depth,
+		SelectionPolicy: policy,
+	}, nil
+}
+
+// statusOf maps a resolution error to an HTTP status by its error code.
+func statusOf(err error) int {
+	switch domain.CodeOf(err) {
+	case domain.CodeNoSlip, domain.CodeEmptyAncestry:
+		return http.StatusNotFound
+	case domain.CodeInvalidInput, domain.CodeInvalidHint:
+		return http.StatusBadRequest
+	case domain.CodeNotARepository, domain.CodeNoRemoteOrigin, domain.CodeInvalidRemoteURL,
+		domain.CodeBranchNotFound, domain.CodeCommitNotFound, domain.CodeNoMergeBase:
+		return http.StatusUnprocessableEntity
+	case domain.CodeStepRequirementUnmet:
+		return http.StatusConflict
+	case domain.CodeStore:
+		return http.StatusServiceUnavailable
+	case domain.CodeMalformedCorrelationID:
+		return http.StatusBadGateway
+	case domain.CodeStageTimeout:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// newResultJSON converts result to its JSON representation.
+func newResultJSON(result *domain.ResolveOutput) resultJSON {
+	out := resultJSON{
+		SchemaVersion:   domain.SchemaVersion,
+		CorrelationID:   result.CorrelationID,
+		MatchedCommit:   result.MatchedCommit,
+		Repository:      result.Repository,
+		Branch:          result.Branch,
+		DefaultBranch:   result.DefaultBranch,
+		ResolvedBy:      result.ResolvedBy,
+		SelectionPolicy: string(result.SelectionPolicy),
+		Candidates:      result.Candidates,
+		Depth:           result.Depth,
+		Distance:        result.Distance,
+		Confidence:      string(result.Confidence),
+		Component:       result.Component,
+		Mock:            result.Mock,
+	}
+	if len(result.Timings) > 0 {
+		out.Timings = make(map[string]float64, len(result.Timings))
+		for _, t := range result.Timings {
+			out.Timings[t.Stage] = float64(t.Duration) / float64(time.Millisecond)
+		}
+	}
+	return out
+}
+
+// writeError writes an errorJSON response.
+func writeError(w http.ResponseWriter, status int, code domain.ErrorCode, msg string) {
+	writeJSON(w, status, errorJSON{SchemaVersion: domain.SchemaVersion, Error: msg, Code: string(code)})
+}
+
+// writeJSON writes v as a JSON response with status. Best-effort: a client
+// that has gone away cannot be told.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
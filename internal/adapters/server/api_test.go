@@ -0,0 +1,268 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// nopLogger discards everything.
+type nopLogger struct{}
+
+func (nopLogger) Info(context.Context, string, map[string]interface{})         {}
+func (nopLogger) Warn(context.Context, string, map[string]interface{})         {}
+func (nopLogger) Error(context.Context, string, error, map[string]interface{}) {}
+
+// stubResolve returns a ResolveFunc that records its request and returns result and err.
+func stubResolve(got *domain.ResolveRequest, result *domain.ResolveOutput, err error) ResolveFunc {
+	return func(_ context.Context, req domain.ResolveRequest) (*domain.ResolveOutput, error) {
+		*got = req
+		return result, err
+	}
+}
+
+// post sends body to POST /v1/resolve on handler, with headers.
+func post(handler http.Handler, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/v1/resolve", strings.NewReader(body))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandleResolve(t *testing.T) {
+	var got domain.ResolveRequest
+	resolve := stubResolve(&got, &domain.ResolveOutput{
+		CorrelationID: "slip-1",
+		MatchedCommit: "c111111111111111111111111111111111111111",
+		Repository:    "org/repo",
+		ResolvedBy:    domain.ResolvedByAncestry,
+		Timings:       []domain.StageTiming{{Stage: domain.StageStoreQuery, Duration: 2 * time.Millisecond}},
+	}, nil)
+	srv, err := New(Config{}, resolve, nopLogger{})
+	require.NoError(t, err)
+
+	rec := post(srv.Handler(), `{"repository":"org/repo","branch":"main","commits":["c000000000000000000000000000000000000000","c111111111111111111111111111111111111111"],"depth":10}`, nil)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.Equal(t, domain.ResolveRequest{
+		Repository:      "org/repo",
+		Branch:          "main",
+		Commits:         []string{"c000000000000000000000000000000000000000", "c111111111111111111111111111111111111111"},
+		Depth:           10,
+		SelectionPolicy: domain.DefaultSelectionPolicy,
+	}, got)
+
+	var result resultJSON
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, domain.SchemaVersion, result.SchemaVersion)
+	assert.Equal(t, "slip-1", result.CorrelationID)
+	assert.Equal(t, "c111111111111111111111111111111111111111", result.MatchedCommit)
+	assert.InDelta(t, 2.0, result.Timings[domain.StageStoreQuery], 0.001)
+}
+
+func TestHandleResolve_InvalidRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantMsg string
+	}{
+		{name: "not JSON", body: "correlation please", wantMsg: "invalid request body"},
+		{name: "unknown field", body: `{"path":"/repo","color":"blue"}`, wantMsg: "unknown field"},
+		{name: "empty", body: `{}`, wantMsg: "either path, or repository and commits, is required"},
+		{name: "repository without commits", body: `{"repository":"org/repo"}`, wantMsg: "is required"},
+		{name: "both forms", body: `{"path":"/repo","commits":["c000000000000000000000000000000000000000"]}`, wantMsg: "cannot be combined"},
+		{name: "negative depth", body: `{"path":"/repo","depth":-1}`, wantMsg: "depth cannot be negative"},
+		{name: "unknown policy", body: `{"path":"/repo","selection_policy":"random"}`, wantMsg: "invalid selection policy"},
+		{
+			name:    "malformed repository",
+			body:    `{"repository":"org/team/repo","commits":["c000000000000000000000000000000000000000"]}`,
+			wantMsg: "expected owner/repo",
+		},
+		{
+			name:    "mixed object formats",
+			body:    `{"repository":"org/repo","commits":["` + strings.Repeat("a", 64) + `","` + strings.Repeat("b", 40) + `"]}`,
+			wantMsg: "does not match the object format",
+		},
+		{
+			name:    "malformed commit",
+			body:    `{"repository":"org/repo","commits":["c000000000000000000000000000000000000000","HEAD~1"]}`,
+			wantMsg: `invalid commit list: commits[1] "HEAD~1" contains 'h'`,
+		},
+		{
+			name:    "abbreviated commit",
+			body:    `{"repository":"org/repo","commits":["c000000000000000000000000000000000000000","abc123"]}`,
+			wantMsg: `invalid commit list: commits[1] "abc123" is 6 characters long, expected a full SHA of 40 or 64`,
+		},
+		{
+			name: "too many commits",
+			body: `{"repository":"org/repo","commits":[` +
+				strings.Repeat(`"c000000000000000000000000000000000000000",`, domain.MaxCommitList) + `"c000000000000000000000000000000000000000"]}`,
+			wantMsg: "more than the limit of 10000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, err := New(Config{}, func(context.Context, domain.ResolveRequest) (*domain.ResolveOutput, error) {
+				t.Fatal("invalid requests must not be resolved")
+				return nil, nil
+			}, nopLogger{})
+			require.NoError(t, err)
+
+			rec := post(srv.Handler(), tt.body, nil)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			var body errorJSON
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Contains(t, body.Error, tt.wantMsg)
+			assert.Equal(t, string(domain.CodeInvalidInput), body.Code)
+		})
+	}
+}
+
+func TestHandleResolve_InvalidCommit(t *testing.T) {
+	var got domain.ResolveRequest
+	srv, err := New(Config{}, stubResolve(&got, nil, nil), nopLogger{})
+	require.NoError(t, err)
+
+	rec := post(srv.Handler(), `{"repository":"org/repo","commits":["c000000000000000000000000000000000000000","xyz1"]}`, nil)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.JSONEq(t, `{
+		"schema_version": "`+domain.SchemaVersion+`",
+		"error": "invalid commit list: commits[1] \"xyz1\" contains 'x', expected hexadecimal digits",
+		"code": "SLIPPY_E011",
+		"validation": {"field": "commits", "index": 1, "value": "xyz1", "reason": "contains 'x', expected hexadecimal digits"}
+	}`, rec.Body.String())
+}
+
+func TestHandleResolve_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   domain.ErrorCode
+	}{
+		{name: "no slip", err: domain.ErrNoAncestorSlip, wantStatus: http.StatusNotFound, wantCode: domain.CodeNoSlip},
+		{
+			name:       "not a repository",
+			err:        domain.ErrRepositoryNotFound,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantCode:   domain.CodeNotARepository,
+		},
+		{
+			name:       "history unavailable",
+			err:        domain.ErrHistoryUnavailable,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.CodeInvalidInput,
+		},
+		{
+			name:       "store down",
+			err:        domain.WithCode(domain.CodeStore, errors.New("connection refused")),
+			wantStatus: http.StatusServiceUnavailable,
+			wantCode:   domain.CodeStore,
+		},
+		{
+			name: "stage timeout",
+			err: domain.WithCode(domain.CodeStore,
+				fmt.Errorf("%w: the store stage exceeded its 5s timeout", domain.ErrStageTimeout)),
+			wantStatus: http.StatusGatewayTimeout,
+			wantCode:   domain.CodeStageTimeout,
+		},
+		{
+			name:       "malformed correlation ID",
+			err:        fmt.Errorf("slip of commit c1: %w: \"null\" is not a UUID", domain.ErrMalformedCorrelationID),
+			wantStatus: http.StatusBadGateway,
+			wantCode:   domain.CodeMalformedCorrelationID,
+		},
+		{
+			name:       "unexpected",
+			err:        errors.New("boom"),
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   domain.CodeUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got domain.ResolveRequest
+			srv, err := New(Config{}, stubResolve(&got, nil, tt.err), nopLogger{})
+			require.NoError(t, err)
+
+			rec := post(srv.Handler(), `{"path":"/workspace/repo"}`, nil)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			var body errorJSON
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, domain.SchemaVersion, body.SchemaVersion)
+			assert.Equal(t, tt.err.Error(), body.Error)
+			assert.Equal(t, string(tt.wantCode), body.Code)
+		})
+	}
+}
+
+func TestHandleResolve_Tenant(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		header     string
+		wantStatus int
+		wantTenant string
+	}{
+		{name: "default store", path: "/v1/resolve", wantStatus: http.StatusOK},
+		{name: "header", path: "/v1/resolve", header: "payments", wantStatus: http.StatusOK, wantTenant: "payments"},
+		{name: "path", path: "/t/payments/v1/resolve", wantStatus: http.StatusOK, wantTenant: "payments"},
+		{
+			name:       "path and matching header",
+			path:       "/t/payments/v1/resolve",
+			header:     "payments",
+			wantStatus: http.StatusOK,
+			wantTenant: "payments",
+		},
+		{name: "conflict", path: "/t/payments/v1/resolve", header: "logistics", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got domain.ResolveRequest
+			srv, err := New(Config{}, stubResolve(&got, &domain.ResolveOutput{CorrelationID: "slip-1"}, nil), nopLogger{})
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(`{"path":"/repo"}`))
+			if tt.header != "" {
+				req.Header.Set(TenantHeader, tt.header)
+			}
+			rec := httptest.NewRecorder()
+
+			srv.Handler().ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantTenant, got.Tenant)
+		})
+	}
+}
+
+func TestHandler_MethodNotAllowed(t *testing.T) {
+	srv, err := New(Config{}, nil, nopLogger{})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/resolve", nil)
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// clientKey is the context key of the authenticated client's name.
+type clientKey struct{}
+
+// ClientFromContext returns the name of the client making the request, as
+// established by authentication; "" outside a request.
+func ClientFromContext(ctx context.Context) string {
+	client, _ := ctx.Value(clientKey{}).(string)
+	return client
+}
+
+// authenticate identifies the client of each request and rejects requests
+// without valid credentials. Requests over the unix socket come from client
+// "unix" and need no credentials. Otherwise, with tokens configured, the
+// client is named by its bearer token; without them it is the common name of
+// its verified certificate (mutual TLS), or failing that its IP address.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client, ok := s.identify(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="slippy-find"`)
+			writeError(w, http.StatusUnauthorized, domain.CodeInvalidInput, "missing or invalid bearer token")
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientKey{}, client)))
+	})
+}
+
+// identify returns the client making r, and false if r must be rejected.
+func (s *Server) identify(r *http.Request) (string, bool) {
+	if isLocal(r.Context()) {
+		return "unix", true
+	}
+	if len(s.cfg.Tokens) > 0 {
+		return s.tokenClient(r.Header.Get("Authorization"))
+	}
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 {
+		return "cn:" + r.TLS.PeerCertificates[0].Subject.CommonName, true
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host, true
+}
+
+// tokenClient returns the client whose token the Authorization header carries.
+func (s *Server) tokenClient(header string) (string, bool) {
+	return matchToken(s.cfg.Tokens, header)
+}
+
+// matchToken returns the name tokens gives the bearer token in the
+// Authorization header. Every token is compared, in constant time, so timing
+// reveals nothing about which tokens exist.
+func matchToken(tokens map[string]string, header string) (string, bool) {
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	var name string
+	for candidate, candidateName := range tokens {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(token)) == 1 {
+			name = candidateName
+		}
+	}
+	return name, name != ""
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// clientEcho is a ResolveFunc that returns the authenticated client as the correlation ID.
+func clientEcho(ctx context.Context, _ domain.ResolveRequest) (*domain.ResolveOutput, error) {
+	return &domain.ResolveOutput{CorrelationID: ClientFromContext(ctx)}, nil
+}
+
+func TestAuthenticate_Tokens(t *testing.T) {
+	srv, err := New(Config{Tokens: map[string]string{"s3cret-a": "team-a", "s3cret-b": "team-b"}}, clientEcho, nopLogger{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		header     string
+		wantStatus int
+		wantClient string
+	}{
+		{name: "valid token", header: "Bearer s3cret-b", wantStatus: http.StatusOK, wantClient: "team-b"},
+		{name: "scheme is case-insensitive", header: "bearer s3cret-a", wantStatus: http.StatusOK, wantClient: "team-a"},
+		{name: "missing", wantStatus: http.StatusUnauthorized},
+		{name: "wrong token", header: "Bearer guess", wantStatus: http.StatusUnauthorized},
+		{name: "wrong scheme", header: "Basic s3cret-a", wantStatus: http.StatusUnauthorized},
+		{name: "empty token", header: "Bearer ", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := post(srv.Handler(), `{"path":"/repo"}`, map[string]string{"Authorization": tt.header})
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantStatus == http.StatusUnauthorized {
+				assert.Equal(t, `Bearer realm="slippy-find"`, rec.Header().Get("WWW-Authenticate"))
+				return
+			}
+			assert.Contains(t, rec.Body.String(), `"correlation_id":"`+tt.wantClient+`"`)
+		})
+	}
+}
+
+func TestAuthenticate_Anonymous(t *testing.T) {
+	srv, err := New(Config{}, clientEcho, nopLogger{})
+	require.NoError(t, err)
+
+	rec := post(srv.Handler(), `{"path":"/repo"}`, nil)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	// httptest requests come from 192.0.2.1
+	assert.Contains(t, rec.Body.String(), `"correlation_id":"ip:192.0.2.1"`)
+}
+
+func TestAuthenticate_ClientCertificate(t *testing.T) {
+	srv, err := New(Config{}, clientEcho, nopLogger{})
+	require.NoError(t, err)
+
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "build-farm"}}
+	req := httptest.NewRequest(http.MethodPost, "/v1/resolve", nil)
+	req.TLS = &tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{cert},
+		VerifiedChains:   [][]*x509.Certificate{{cert}},
+	}
+
+	client, ok := srv.identify(req)
+
+	assert.True(t, ok)
+	assert.Equal(t, "cn:build-farm", client)
+}
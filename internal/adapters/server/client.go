@@ -0,0 +1,172 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// socketURL is the request URL used over a unix socket; the host is ignored.
+const socketURL = "http://slippy-find/v1/resolve"
+
+// dialTimeout bounds connecting to the server, so a missing server is noticed quickly.
+const dialTimeout = time.Second
+
+// stageOrder is the order in which a run's stages happen, for rebuilding
+// timings from their JSON map.
+var stageOrder = []string{
+	domain.StageConfigLoad,
+	domain.StageGitOpen,
+	domain.StageStoreOpen,
+	domain.StageGitContext,
+	domain.StageAncestryWalk,
+	domain.StageStoreQuery,
+}
+
+// Client resolves slips through a running server.
+type Client struct {
+	url    string
+	target string
+	token  string
+	client *http.Client
+}
+
+// NewSocketClient creates a client for the server listening on the unix socket at path.
+func NewSocketClient(path string) *Client {
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	return &Client{
+		url:    socketURL,
+		target: path,
+		client: &http.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, "unix", path)
+			},
+		}},
+	}
+}
+
+// NewHTTPClient creates a client for the server at baseURL (e.g. "http://127.0.0.1:8080").
+func NewHTTPClient(baseURL string) *Client {
+	return NewHTTPClientWithToken(baseURL, "")
+}
+
+// NewHTTPClientWithToken creates a client for the server at baseURL that
+// authenticates with a bearer token. An empty token sends none.
+func NewHTTPClientWithToken(baseURL, token string) *Client {
+	return &Client{
+		url:    strings.TrimSuffix(baseURL, "/") + "/v1/resolve",
+		target: baseURL,
+		token:  token,
+		client: &http.Client{Transport: httpTransport()},
+	}
+}
+
+// Resolve sends req to the server and returns its result. Errors reported by
+// the server keep their error codes.
+func (c *Client) Resolve(ctx context.Context, req domain.ResolveRequest) (*domain.ResolveOutput, error) {
+	body, err := json.Marshal(resolveRequestJSON{
+		Path:            req.Path,
+		Repository:      req.Repository,
+		Branch:          req.Branch,
+		Commits:         req.Commits,
+		Depth:           req.Depth,
+		SelectionPolicy: string(req.SelectionPolicy),
+	})
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if req.Tenant != "" {
+		httpReq.Header.Set(TenantHeader, req.Tenant)
+	}
+	if c.token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach slippy-find server at %s: %w", c.target, err)
+	}
+	defer func() {
+		// Fully read below; a close failure cannot lose data.
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		var failure errorJSON
+		if err := json.NewDecoder(resp.Body).Decode(&failure); err != nil || failure.Error == "" {
+			return nil, fmt.Errorf("slippy-find server returned %s", resp.Status)
+		}
+		return nil, domain.WithCode(domain.ErrorCode(failure.Code), failure.err())
+	}
+
+	var result resultJSON
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("invalid response from slippy-find server: %w", err)
+	}
+	return result.output(), nil
+}
+
+// output converts the JSON result back to a domain.ResolveOutput.
+func (r resultJSON) output() *domain.ResolveOutput {
+	out := &domain.ResolveOutput{
+		CorrelationID:   r.CorrelationID,
+		MatchedCommit:   r.MatchedCommit,
+		Repository:      r.Repository,
+		Branch:          r.Branch,
+		DefaultBranch:   r.DefaultBranch,
+		ResolvedBy:      r.ResolvedBy,
+		SelectionPolicy: domain.SelectionPolicy(r.SelectionPolicy),
+		Candidates:      r.Candidates,
+		Depth:           r.Depth,
+		Distance:        r.Distance,
+		Confidence:      domain.Confidence(r.Confidence),
+		Component:       r.Component,
+		Mock:            r.Mock,
+	}
+	for _, stage := range slices.Sorted(maps.Keys(r.Timings)) {
+		out.Timings = append(out.Timings, domain.StageTiming{
+			Stage:    stage,
+			Duration: time.Duration(r.Timings[stage] * float64(time.Millisecond)),
+		})
+	}
+	slices.SortStableFunc(out.Timings, func(a, b domain.StageTiming) int {
+		return stageIndex(a.Stage) - stageIndex(b.Stage)
+	})
+	return out
+}
+
+// stageIndex returns stage's position in stageOrder, placing unknown stages last.
+func stageIndex(stage string) int {
+	if i := slices.Index(stageOrder, stage); i >= 0 {
+		return i
+	}
+	return len(stageOrder)
+}
+
+// err returns the failure as an error: a *domain.CommitListError if the
+// server rejected the commit list, so callers can find the rejected commit.
+func (e errorJSON) err() error {
+	if v := e.Validation; v != nil && v.Field == "commits" {
+		invalid := &domain.CommitListError{Index: -1, Commit: v.Value, Reason: v.Reason}
+		if v.Index != nil {
+			invalid.Index = *v.Index
+		}
+		return invalid
+	}
+	return errors.New(e.Error)
+}
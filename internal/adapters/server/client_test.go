@@ -0,0 +1,215 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// serveSocket serves handler on a unix socket until the test ends and returns its path.
+func serveSocket(t *testing.T, handler http.Handler) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "slippy.sock")
+	listener, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	srv := &http.Server{Handler: handler, ReadHeaderTimeout: time.Second}
+	go func() { _ = srv.Serve(listener) }()
+	t.Cleanup(func() { _ = srv.Close() })
+	return path
+}
+
+func TestSocketClient_Resolve(t *testing.T) {
+	var got domain.ResolveRequest
+	srv, err := New(Config{}, stubResolve(&got, &domain.ResolveOutput{
+		CorrelationID:   "slip-1",
+		MatchedCommit:   "c111111111111111111111111111111111111111",
+		Repository:      "org/repo",
+		Branch:          "main",
+		DefaultBranch:   "main",
+		ResolvedBy:      domain.ResolvedByAncestry,
+		SelectionPolicy: domain.SelectionNearestCommit,
+		Candidates:      2,
+		Depth:           25,
+		Distance:        1,
+		Confidence:      domain.ConfidenceNearAncestor,
+		Timings: []domain.StageTiming{
+			{Stage: domain.StageGitContext, Duration: time.Millisecond},
+			{Stage: domain.StageAncestryWalk, Duration: 3 * time.Millisecond},
+			{Stage: domain.StageStoreQuery, Duration: 2 * time.Millisecond},
+		},
+	}, nil), nopLogger{})
+	require.NoError(t, err)
+	client := NewSocketClient(serveSocket(t, srv.Handler()))
+
+	result, err := client.Resolve(context.Background(), domain.ResolveRequest{
+		Path:            "/builds/repo",
+		Depth:           25,
+		SelectionPolicy: domain.SelectionNearestCommit,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.ResolveRequest{
+		Path:            "/builds/repo",
+		Depth:           25,
+		SelectionPolicy: domain.SelectionNearestCommit,
+	}, got)
+	assert.Equal(t, &domain.ResolveOutput{
+		CorrelationID:   "slip-1",
+		MatchedCommit:   "c111111111111111111111111111111111111111",
+		Repository:      "org/repo",
+		Branch:          "main",
+		DefaultBranch:   "main",
+		ResolvedBy:      domain.ResolvedByAncestry,
+		SelectionPolicy: domain.SelectionNearestCommit,
+		Candidates:      2,
+		Depth:           25,
+		Distance:        1,
+		Confidence:      domain.ConfidenceNearAncestor,
+		Timings: []domain.StageTiming{
+			{Stage: domain.StageGitContext, Duration: time.Millisecond},
+			{Stage: domain.StageAncestryWalk, Duration: 3 * time.Millisecond},
+			{Stage: domain.StageStoreQuery, Duration: 2 * time.Millisecond},
+		},
+	}, result)
+}
+
+func TestSocketClient_Resolve_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		handler  http.HandlerFunc
+		wantMsg  string
+		wantCode domain.ErrorCode
+	}{
+		{
+			name: "server error keeps its code",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				writeError(w, http.StatusNotFound, domain.CodeNoSlip, "no slip found in commit ancestry")
+			},
+			wantMsg:  "no slip found in commit ancestry",
+			wantCode: domain.CodeNoSlip,
+		},
+		{
+			name: "error without a body",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+			},
+			wantMsg:  "slippy-find server returned 502 Bad Gateway",
+			wantCode: domain.CodeUnknown,
+		},
+		{
+			name: "invalid result",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte("not json"))
+			},
+			wantMsg:  "invalid response from slippy-find server",
+			wantCode: domain.CodeUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewSocketClient(serveSocket(t, tt.handler))
+
+			_, err := client.Resolve(context.Background(), domain.ResolveRequest{Path: "/builds/repo"})
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantMsg)
+			assert.Equal(t, tt.wantCode, domain.CodeOf(err))
+		})
+	}
+}
+
+func TestSocketClient_Resolve_Unreachable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.sock")
+
+	_, err := NewSocketClient(path).Resolve(context.Background(), domain.ResolveRequest{Path: "/builds/repo"})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to reach slippy-find server at "+path)
+	var opErr *net.OpError
+	assert.True(t, errors.As(err, &opErr))
+}
+
+func TestResultJSON_Output_UnknownStagesLast(t *testing.T) {
+	result := resultJSON{Timings: map[string]float64{
+		"z_custom":               1,
+		"a_custom":               1,
+		domain.StageStoreQuery:   1,
+		domain.StageConfigLoad:   1,
+		domain.StageAncestryWalk: 1,
+	}}
+
+	var stages []string
+	for _, timing := range result.output().Timings {
+		stages = append(stages, timing.Stage)
+	}
+
+	assert.Equal(t, []string{
+		domain.StageConfigLoad, domain.StageAncestryWalk, domain.StageStoreQuery, "a_custom", "z_custom",
+	}, stages)
+}
+
+func TestHTTPClient_Resolve(t *testing.T) {
+	var got domain.ResolveRequest
+	srv, err := New(Config{}, stubResolve(&got, &domain.ResolveOutput{CorrelationID: "slip-1"}, nil), nopLogger{})
+	require.NoError(t, err)
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+
+	result, err := NewHTTPClient(ts.URL+"/").Resolve(context.Background(), domain.ResolveRequest{
+		Path:   "/builds/repo",
+		Tenant: "payments",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "slip-1", result.CorrelationID)
+	assert.Equal(t, "/builds/repo", got.Path)
+	assert.Equal(t, "payments", got.Tenant)
+}
+
+func TestHTTPClient_Resolve_Token(t *testing.T) {
+	var got domain.ResolveRequest
+	srv, err := New(Config{Tokens: map[string]string{"s3cret": "console"}},
+		stubResolve(&got, &domain.ResolveOutput{CorrelationID: "slip-1"}, nil), nopLogger{})
+	require.NoError(t, err)
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+	req := domain.ResolveRequest{Repository: "org/repo", Commits: []string{"c111111111111111111111111111111111111111"}}
+
+	result, err := NewHTTPClientWithToken(ts.URL, "s3cret").Resolve(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, "slip-1", result.CorrelationID)
+
+	_, err = NewHTTPClient(ts.URL).Resolve(context.Background(), req)
+	assert.ErrorContains(t, err, "missing or invalid bearer token")
+}
+
+func TestHTTPClient_Resolve_InvalidCommit(t *testing.T) {
+	var got domain.ResolveRequest
+	srv, err := New(Config{}, stubResolve(&got, nil, nil), nopLogger{})
+	require.NoError(t, err)
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+
+	_, err = NewHTTPClient(ts.URL).Resolve(context.Background(), domain.ResolveRequest{
+		Repository: "org/repo",
+		Commits:    []string{"c111111111111111111111111111111111111111", "zzzz"},
+	})
+
+	var invalid *domain.CommitListError
+	require.ErrorAs(t, err, &invalid)
+	assert.Equal(t, domain.CommitListError{Index: 1, Commit: "zzzz", Reason: "contains 'z', expected hexadecimal digits"},
+		*invalid)
+	assert.ErrorIs(t, err, domain.ErrInvalidCommitList)
+	assert.Equal(t, domain.CodeInvalidInput, domain.CodeOf(err))
+}
@@ -0,0 +1,36 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// StartDaemon starts this executable in the background with args, appending
+// its output to logPath. The process is detached from the caller's session,
+// so it outlives the caller and is not sent the caller's terminal signals.
+// It returns the process ID.
+func StartDaemon(args []string, logPath string) (int, error) {
+	self, err := os.Executable()
+	if err != nil {
+		return 0, fmt.Errorf("failed to locate executable: %w", err)
+	}
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open daemon log: %w", err)
+	}
+	// The child holds its own descriptor once started.
+	defer func() { _ = logFile.Close() }()
+
+	daemon := exec.Command(self, args...)
+	daemon.Stdout = logFile
+	daemon.Stderr = logFile
+	daemon.SysProcAttr = detachedProcAttr()
+	if err := daemon.Start(); err != nil {
+		return 0, fmt.Errorf("failed to start %s: %w", self, err)
+	}
+	pid := daemon.Process.Pid
+	// Not waited for: the daemon runs on after the caller exits.
+	_ = daemon.Process.Release()
+	return pid, nil
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package server
+
+import "syscall"
+
+// detachedProcAttr starts the daemon with default attributes where sessions
+// are not supported.
+func detachedProcAttr() *syscall.SysProcAttr {
+	return nil
+}
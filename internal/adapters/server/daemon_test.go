@@ -0,0 +1,31 @@
+//go:build unix
+
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartDaemon(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "daemon.log")
+
+	// The test binary stands in for slippy-find: it runs no tests and exits.
+	pid, err := StartDaemon([]string{"-test.run=^$"}, logPath)
+
+	require.NoError(t, err)
+	assert.Positive(t, pid)
+	_, err = os.Stat(logPath)
+	assert.NoError(t, err, "log file created")
+}
+
+func TestStartDaemon_LogUnwritable(t *testing.T) {
+	_, err := StartDaemon(nil, filepath.Join(t.TempDir(), "missing", "daemon.log"))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to open daemon log")
+}
@@ -0,0 +1,11 @@
+//go:build unix
+
+package server
+
+import "syscall"
+
+// detachedProcAttr starts the daemon in a new session, without a controlling
+// terminal.
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
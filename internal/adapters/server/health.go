@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// pingTimeout bounds the store check made for each readiness probe.
+const pingTimeout = 2 * time.Second
+
+// Statuses of a health check.
+const (
+	checkOK        = "ok"
+	checkFailed    = "failed"
+	checkUnchecked = "unchecked"
+)
+
+// livenessJSON is the body of GET /livez.
+type livenessJSON struct {
+	Status     string  `json:"status"`
+	Uptime     float64 `json:"uptime_seconds"`
+	Goroutines int     `json:"goroutines"`
+}
+
+// readinessJSON is the body of GET /readyz.
+type readinessJSON struct {
+	Status string                    `json:"status"`
+	Checks map[string]checkStateJSON `json:"checks"`
+}
+
+// checkStateJSON is the state of one dependency.
+type checkStateJSON struct {
+	Status      string `json:"status"`
+	Error       string `json:"error,omitempty"`
+	LastSuccess string `json:"last_success,omitempty"`
+}
+
+// probeHandler returns a handler serving only the health endpoints and, when
+// configured, the metrics.
+func (s *Server) probeHandler() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /livez", s.handleLive)
+	mux.HandleFunc("GET /readyz", s.handleReady)
+	if s.cfg.Metrics != nil {
+		mux.Handle("GET /metrics", s.cfg.Metrics)
+	}
+	return mux
+}
+
+// handleLive serves GET /livez: the process is up and serving requests.
+func (s *Server) handleLive(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, livenessJSON{
+		Status:     "alive",
+		Uptime:     time.Since(s.started).Seconds(),
+		Goroutines: runtime.NumGoroutine(),
+	})
+}
+
+// handleReady serves GET /readyz: whether the slip store can be reached, and
+// when it last was, by a probe or a resolution. A draining server is not ready.
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	store := s.checkStore(r.Context())
+	if last := s.lastStoreSuccess.Load(); last != 0 {
+		store.LastSuccess = time.Unix(0, last).UTC().Format(time.RFC3339)
+	}
+
+	body := readinessJSON{Status: "ready", Checks: map[string]checkStateJSON{"store": store}}
+	status := http.StatusOK
+	switch {
+	case s.draining.Load():
+		body.Status = "draining"
+		status = http.StatusServiceUnavailable
+	case store.Status == checkFailed:
+		body.Status = "not_ready"
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, body)
+}
+
+// checkStore pings the slip store with cfg.Ping.
+func (s *Server) checkStore(ctx context.Context) checkStateJSON {
+	if s.cfg.Ping == nil {
+		return checkStateJSON{Status: checkUnchecked}
+	}
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+	if err := s.cfg.Ping(ctx); err != nil {
+		if errors.Is(err, domain.ErrPingUnsupported) {
+			return checkStateJSON{Status: checkUnchecked}
+		}
+		return checkStateJSON{Status: checkFailed, Error: err.Error()}
+	}
+	s.markStoreReachable()
+	return checkStateJSON{Status: checkOK}
+}
+
+// trackStore wraps resolve so that every resolution the store answered, with
+// a slip or without one, counts as the store's last success.
+func (s *Server) trackStore(resolve ResolveFunc) ResolveFunc {
+	return func(ctx context.Context, req domain.ResolveRequest) (*domain.ResolveOutput, error) {
+		result, err := resolve(ctx, req)
+		if err == nil || domain.CodeOf(err) == domain.CodeNoSlip {
+			s.markStoreReachable()
+		}
+		return result, err
+	}
+}
+
+// markStoreReachable records that the store was reached just now.
+func (s *Server) markStoreReachable() {
+	s.lastStoreSuccess.Store(time.Now().UnixNano())
+}
@@ -0,0 +1,207 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/internal/adapters/metrics"
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// get sends GET path to handler.
+func get(handler http.Handler, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec
+}
+
+func TestHandleLive(t *testing.T) {
+	// Health endpoints need no token
+	srv, err := New(Config{Tokens: map[string]string{"token-a": "team-a"}}, clientEcho, nopLogger{})
+	require.NoError(t, err)
+
+	rec := get(srv.Handler(), "/livez")
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var body livenessJSON
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "alive", body.Status)
+	assert.Positive(t, body.Goroutines)
+}
+
+func TestHandleReady(t *testing.T) {
+	tests := []struct {
+		name       string
+		ping       func(ctx context.Context) error
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "store reachable",
+			ping:       func(context.Context) error { return nil },
+			wantStatus: http.StatusOK,
+			wantBody:   `"status":"ready","checks":{"store":{"status":"ok","last_success":"`,
+		},
+		{
+			name:       "store unreachable",
+			ping:       func(context.Context) error { return errors.New("connection refused") },
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   `{"status":"not_ready","checks":{"store":{"status":"failed","error":"connection refused"}}}`,
+		},
+		{
+			name:       "ping unsupported",
+			ping:       func(context.Context) error { return domain.ErrPingUnsupported },
+			wantStatus: http.StatusOK,
+			wantBody:   `{"status":"ready","checks":{"store":{"status":"unchecked"}}}`,
+		},
+		{
+			name:       "no ping",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"status":"ready","checks":{"store":{"status":"unchecked"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, err := New(Config{Tokens: map[string]string{"token-a": "team-a"}, Ping: tt.ping}, clientEcho, nopLogger{})
+			require.NoError(t, err)
+
+			rec := get(srv.Handler(), "/readyz")
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.wantBody)
+		})
+	}
+}
+
+func TestHandleReady_LastSuccessFromResolutions(t *testing.T) {
+	reachable := true
+	resolveErr := domain.WithCode(domain.CodeNoSlip, errors.New("no slip found in commit ancestry"))
+	srv, err := New(Config{Ping: func(context.Context) error {
+		if reachable {
+			return nil
+		}
+		return errors.New("connection refused")
+	}}, func(context.Context, domain.ResolveRequest) (*domain.ResolveOutput, error) {
+		return nil, resolveErr
+	}, nopLogger{})
+	require.NoError(t, err)
+
+	reachable = false
+	rec := get(srv.Handler(), "/readyz")
+	assert.NotContains(t, rec.Body.String(), "last_success", "never reached yet")
+
+	// A resolution without a slip still reached the store
+	post(srv.Handler(), `{"repository":"org/repo","commits":["c111111111111111111111111111111111111111"]}`, nil)
+	rec = get(srv.Handler(), "/readyz")
+	var body readinessJSON
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "not_ready", body.Status)
+	last, err := time.Parse(time.RFC3339, body.Checks["store"].LastSuccess)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), last, time.Minute)
+
+	// A store failure does not count
+	srv.lastStoreSuccess.Store(0)
+	resolveErr = domain.WithCode(domain.CodeStore, errors.New("database error"))
+	post(srv.Handler(), `{"repository":"org/repo","commits":["c111111111111111111111111111111111111111"]}`, nil)
+	rec = get(srv.Handler(), "/readyz")
+	assert.NotContains(t, rec.Body.String(), "last_success")
+}
+
+func TestHandleReady_Draining(t *testing.T) {
+	srv, err := New(Config{Ping: func(context.Context) error { return nil }}, clientEcho, nopLogger{})
+	require.NoError(t, err)
+	srv.draining.Store(true)
+
+	rec := get(srv.Handler(), "/readyz")
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"status":"draining"`)
+}
+
+func TestServer_Serve_ProbeAddr(t *testing.T) {
+	probe, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	probeAddr := probe.Addr().String()
+	require.NoError(t, probe.Close())
+
+	srv, err := New(Config{
+		SocketPath: filepath.Join(t.TempDir(), "slippy.sock"),
+		ProbeAddr:  probeAddr,
+		Tokens:     map[string]string{"token-a": "team-a"},
+	}, clientEcho, nopLogger{})
+	require.NoError(t, err)
+	startServer(t, srv)
+	waitForSocket(t, srv.cfg.SocketPath)
+
+	require.Eventually(t, func() bool {
+		resp, err := http.Get("http://" + probeAddr + "/livez")
+		if err != nil {
+			return false
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// Resolution is not served on the probe address
+	resp, err := http.Post("http://"+probeAddr+"/v1/resolve", "application/json", strings.NewReader(`{"path":"/repo"}`))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+// resolveOnly is a domain.Resolver implementing only Resolve.
+type resolveOnly struct {
+	domain.Resolver
+	output *domain.ResolveOutput
+}
+
+func (r resolveOnly) Resolve(context.Context, domain.ResolveInput) (*domain.ResolveOutput, error) {
+	return r.output, nil
+}
+
+func TestHandleMetrics(t *testing.T) {
+	recorder := metrics.New()
+	resolver := recorder.InstrumentResolver(resolveOnly{output: &domain.ResolveOutput{
+		CorrelationID: "slip-1",
+		ResolvedBy:    "ancestry",
+	}})
+	srv, err := New(Config{
+		Tokens:  map[string]string{"token-a": "team-a"},
+		Metrics: recorder.Handler(),
+	}, func(ctx context.Context, _ domain.ResolveRequest) (*domain.ResolveOutput, error) {
+		return resolver.Resolve(ctx, domain.ResolveInput{})
+	}, nopLogger{})
+	require.NoError(t, err)
+
+	rec := post(srv.Handler(), `{"repository":"org/repo","commits":["c111111111111111111111111111111111111111"]}`,
+		map[string]string{"Authorization": "Bearer token-a"})
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	// Scraped without a token, on the main listener and the probe listener
+	for name, handler := range map[string]http.Handler{"main": srv.Handler(), "probe": srv.probeHandler()} {
+		rec = get(handler, "/metrics")
+		assert.Equal(t, http.StatusOK, rec.Code, name)
+		assert.Contains(t, rec.Body.String(),
+			`slippy_find_resolutions_total{operation="resolve",resolved_by="ancestry",result="hit"} 1`, name)
+	}
+}
+
+func TestHandleMetrics_Disabled(t *testing.T) {
+	srv, err := New(Config{}, clientEcho, nopLogger{})
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusNotFound, get(srv.probeHandler(), "/metrics").Code)
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// maxTrackedClients bounds the number of per-client limiters kept. When it is
+// reached, limiters idle for limiterIdleTime are dropped; a dropped client
+// starts again with a full burst.
+const (
+	maxTrackedClients = 10000
+	limiterIdleTime   = 10 * time.Minute
+)
+
+// clientLimiter applies a separate token-bucket rate limit to each client.
+type clientLimiter struct {
+	limit rate.Limit
+	burst int
+	now   func() time.Time
+
+	mu      sync.Mutex
+	clients map[string]*trackedLimiter
+}
+
+// trackedLimiter is a client's limiter and when it was last used.
+type trackedLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// newClientLimiter allows each client perSecond requests per second, in bursts of up to burst.
+func newClientLimiter(perSecond float64, burst int) *clientLimiter {
+	return &clientLimiter{
+		limit:   rate.Limit(perSecond),
+		burst:   max(burst, 1),
+		now:     time.Now,
+		clients: make(map[string]*trackedLimiter),
+	}
+}
+
+// allow reports whether client may make a request now, and if not, how long
+// until it may.
+func (l *clientLimiter) allow(client string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	tracked, ok := l.clients[client]
+	if !ok {
+		if len(l.clients) >= maxTrackedClients {
+			l.evictIdle(now)
+		}
+		tracked = &trackedLimiter{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.clients[client] = tracked
+	}
+	tracked.lastSeen = now
+
+	reservation := tracked.limiter.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+// evictIdle drops the limiters of clients idle since before now-limiterIdleTime.
+func (l *clientLimiter) evictIdle(now time.Time) {
+	for client, tracked := range l.clients {
+		if now.Sub(tracked.lastSeen) > limiterIdleTime {
+			delete(l.clients, client)
+		}
+	}
+}
+
+// rateLimit rejects requests from clients over their rate limit with 429 Too
+// Many Requests and a Retry-After header. Unix socket requests are exempt. It
+// must run after authenticate.
+func (s *Server) rateLimit(next http.Handler) http.Handler {
+	if s.limiter == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isLocal(r.Context()) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		allowed, retryAfter := s.limiter.allow(ClientFromContext(r.Context()))
+		if !allowed {
+			seconds := int(retryAfter.Round(time.Second) / time.Second)
+			w.Header().Set("Retry-After", strconv.Itoa(max(seconds, 1)))
+			writeError(w, http.StatusTooManyRequests, domain.CodeInvalidInput, "rate limit exceeded")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
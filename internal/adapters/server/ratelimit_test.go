@@ -0,0 +1,76 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+func TestClientLimiter(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	limiter := newClientLimiter(1, 2)
+	limiter.now = func() time.Time { return now }
+
+	// The burst is available at once, per client
+	for range 2 {
+		allowed, _ := limiter.allow("team-a")
+		assert.True(t, allowed)
+	}
+	allowed, retryAfter := limiter.allow("team-a")
+	assert.False(t, allowed)
+	assert.Equal(t, time.Second, retryAfter)
+
+	allowed, _ = limiter.allow("team-b")
+	assert.True(t, allowed, "clients are limited separately")
+
+	// A rejected request does not use up the next token
+	now = now.Add(time.Second)
+	allowed, _ = limiter.allow("team-a")
+	assert.True(t, allowed)
+}
+
+func TestClientLimiter_EvictsIdleClients(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	limiter := newClientLimiter(1, 1)
+	limiter.now = func() time.Time { return now }
+
+	for i := range maxTrackedClients {
+		limiter.allow(string(rune(i)))
+	}
+	now = now.Add(limiterIdleTime + time.Second)
+	limiter.allow("newcomer")
+
+	assert.Len(t, limiter.clients, 1)
+}
+
+func TestRateLimit(t *testing.T) {
+	srv, err := New(Config{
+		Tokens:    map[string]string{"token-a": "team-a", "token-b": "team-b"},
+		RateLimit: 0.01,
+		RateBurst: 1,
+	}, func(context.Context, domain.ResolveRequest) (*domain.ResolveOutput, error) {
+		return &domain.ResolveOutput{CorrelationID: "slip-1"}, nil
+	}, nopLogger{})
+	require.NoError(t, err)
+	handler := srv.Handler()
+	teamA := map[string]string{"Authorization": "Bearer token-a"}
+
+	assert.Equal(t, http.StatusOK, post(handler, `{"path":"/repo"}`, teamA).Code)
+
+	rec := post(handler, `{"path":"/repo"}`, teamA)
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "100", rec.Header().Get("Retry-After"))
+
+	rec = post(handler, `{"path":"/repo"}`, map[string]string{"Authorization": "Bearer token-b"})
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// Unauthenticated requests are rejected before they count against anyone
+	rec = post(handler, `{"path":"/repo"}`, nil)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
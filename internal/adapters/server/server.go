@@ -0,0 +1,362 @@
+// Package server serves slip resolution over HTTP, for running slippy-find as
+// a long-lived service (serve mode).
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// Logger defines the logging interface required by the server.
+type Logger interface {
+	Info(ctx context.Context, msg string, fields map[string]interface{})
+	Warn(ctx context.Context, msg string, fields map[string]interface{})
+	Error(ctx context.Context, msg string, err error, fields map[string]interface{})
+}
+
+// ResolveFunc resolves one request.
+type ResolveFunc func(ctx context.Context, req domain.ResolveRequest) (*domain.ResolveOutput, error)
+
+// Config configures a Server.
+type Config struct {
+	// Addr is the TCP address to listen on (e.g. "127.0.0.1:8080"). Empty
+	// disables TCP, leaving only SocketPath.
+	Addr string
+
+	// SocketPath is a unix socket to serve on as well, for clients on the same
+	// host. Access is governed by the socket's file permissions: its requests
+	// need no credentials and are not rate limited. Empty disables it.
+	SocketPath string
+
+	// TLSCertFile and TLSKeyFile are PEM files enabling HTTPS. Both or neither must be set.
+	TLSCertFile string
+	TLSKeyFile  string
+
+	// ClientCAFile is a PEM file of CAs that enables mutual TLS: every client
+	// must present a certificate signed by one of them. Requires HTTPS.
+	ClientCAFile string
+
+	// Tokens maps each accepted bearer token to the name of the client using it.
+	// When non-empty, every request must present one of them.
+	Tokens map[string]string
+
+	// RateLimit is the sustained number of requests per second allowed for each
+	// client. Zero disables rate limiting.
+	RateLimit float64
+
+	// RateBurst is the number of requests a client may make at once before
+	// RateLimit applies. Values below 1 are treated as 1.
+	RateBurst int
+
+	// WebhookSecret enables POST /v1/webhooks/github, which pre-resolves the
+	// head commit of each GitHub push. Payloads must be signed with it. Empty
+	// disables the endpoint.
+	WebhookSecret []byte
+
+	// Ping checks the connection to the slip store for GET /readyz. It may
+	// return domain.ErrPingUnsupported. Nil leaves the store unchecked.
+	Ping func(ctx context.Context) error
+
+	// ProbeAddr is a TCP address serving only GET /livez and GET /readyz,
+	// without TLS or credentials, for when Addr is not reachable by the
+	// kubelet (e.g. a sidecar listening on localhost). Empty disables it.
+	ProbeAddr string
+
+	// Metrics serves GET /metrics, in the Prometheus exposition format, on
+	// every listener including ProbeAddr. Like the probes it needs no
+	// credentials. Nil disables it.
+	Metrics http.Handler
+
+	// Admin enables the admin endpoints under /admin/v1/. Nil disables them.
+	Admin *Admin
+
+	// ShutdownTimeout bounds how long Serve drains requests in flight once
+	// its context is done, before cancelling them. Zero means 5 seconds.
+	ShutdownTimeout time.Duration
+}
+
+// Errors returned for inconsistent settings.
+var (
+	// ErrTLSIncomplete indicates only one of the certificate and key was given.
+	ErrTLSIncomplete = errors.New("TLS needs both a certificate and a key")
+
+	// ErrClientCAWithoutTLS indicates mutual TLS was requested without HTTPS.
+	ErrClientCAWithoutTLS = errors.New("client CA requires a TLS certificate and key")
+
+	// ErrNoListener indicates Serve was given neither a TCP address nor a socket path.
+	ErrNoListener = errors.New("nothing to listen on: set an address or a socket path")
+)
+
+// readHeaderTimeout bounds how long a client may take to send request headers.
+const readHeaderTimeout = 10 * time.Second
+
+// defaultShutdownTimeout is used when Config.ShutdownTimeout is not set.
+const defaultShutdownTimeout = 5 * time.Second
+
+// Server is the serve-mode HTTP server.
+type Server struct {
+	cfg       Config
+	resolve   ResolveFunc
+	log       Logger
+	limiter   *clientLimiter
+	tlsConfig *tls.Config
+
+	// warmSlots and warming track webhook pre-resolutions in the background.
+	warmSlots chan struct{}
+	warming   sync.WaitGroup
+
+	// abandoned is cancelled, through abandon, when draining takes too long;
+	// requests and pre-resolutions still running are cancelled with it.
+	abandoned context.Context
+	abandon   context.CancelFunc
+
+	// started, lastStoreSuccess (Unix nanoseconds), and draining feed the
+	// health endpoints.
+	started          time.Time
+	lastStoreSuccess atomic.Int64
+	draining         atomic.Bool
+}
+
+// New creates a Server from cfg that resolves requests with resolve.
+// Returns an error if the TLS settings are inconsistent or the client CA
+// file cannot be read.
+func New(cfg Config, resolve ResolveFunc, log Logger) (*Server, error) {
+	if (cfg.TLSCertFile == "") != (cfg.TLSKeyFile == "") {
+		return nil, ErrTLSIncomplete
+	}
+	if cfg.Admin != nil && len(cfg.Admin.Tokens) == 0 {
+		return nil, ErrAdminWithoutTokens
+	}
+	s := &Server{cfg: cfg, log: log, warmSlots: make(chan struct{}, maxWarming), started: time.Now()}
+	s.resolve = s.trackStore(resolve)
+	s.abandoned, s.abandon = context.WithCancel(context.Background())
+
+	if cfg.ClientCAFile != "" {
+		if cfg.TLSCertFile == "" {
+			return nil, ErrClientCAWithoutTLS
+		}
+		pem, err := os.ReadFile(cfg.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("failed to read client CA: no certificates in %s", cfg.ClientCAFile)
+		}
+		s.tlsConfig = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			ClientCAs:  pool,
+			ClientAuth: tls.RequireAndVerifyClientCert,
+		}
+	} else if cfg.TLSCertFile != "" {
+		s.tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	if cfg.RateLimit > 0 {
+		s.limiter = newClientLimiter(cfg.RateLimit, cfg.RateBurst)
+	}
+	return s, nil
+}
+
+// Handler returns the server's routes with authentication and rate limiting
+// applied. The health endpoints are open, for probes that cannot authenticate.
+func (s *Server) Handler() http.Handler {
+	mux := s.probeHandler()
+	resolve := s.authenticate(s.rateLimit(http.HandlerFunc(s.handleResolve)))
+	mux.Handle("POST /v1/resolve", resolve)
+	mux.Handle("POST /t/{tenant}/v1/resolve", resolve)
+	if len(s.cfg.WebhookSecret) > 0 {
+		// GitHub cannot present a token; the payload signature authenticates it.
+		mux.HandleFunc("POST /v1/webhooks/github", s.handleGitHubWebhook)
+		mux.HandleFunc("POST /t/{tenant}/v1/webhooks/github", s.handleGitHubWebhook)
+	}
+	if s.cfg.Admin != nil {
+		s.registerAdmin(mux)
+	}
+	return mux
+}
+
+// Serve listens on cfg.Addr and cfg.SocketPath and serves requests until ctx
+// is done. It then stops accepting connections and drains requests in flight
+// and webhook pre-resolutions for up to cfg.ShutdownTimeout.
+func (s *Server) Serve(ctx context.Context) error {
+	if s.cfg.Addr == "" && s.cfg.SocketPath == "" {
+		return ErrNoListener
+	}
+	var listeners []net.Listener
+	closeAll := func() {
+		for _, l := range listeners {
+			_ = l.Close()
+		}
+	}
+	if s.cfg.Addr != "" {
+		listener, err := net.Listen("tcp", s.cfg.Addr)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", s.cfg.Addr, err)
+		}
+		listeners = append(listeners, listener)
+	}
+	if s.cfg.SocketPath != "" {
+		listener, err := listenUnix(s.cfg.SocketPath)
+		if err != nil {
+			closeAll()
+			return err
+		}
+		listeners = append(listeners, listener)
+	}
+	if s.cfg.ProbeAddr != "" {
+		listener, err := net.Listen("tcp", s.cfg.ProbeAddr)
+		if err != nil {
+			closeAll()
+			return fmt.Errorf("failed to listen on %s: %w", s.cfg.ProbeAddr, err)
+		}
+		// Stopped only once draining is over, so probes see it
+		stopProbes := s.serveProbes(ctx, listener)
+		defer stopProbes()
+	}
+	return s.serve(ctx, listeners...)
+}
+
+// serveProbes serves the health endpoints on listener until the returned
+// function is called.
+func (s *Server) serveProbes(ctx context.Context, listener net.Listener) func() {
+	srv := &http.Server{Handler: s.probeHandler(), ReadHeaderTimeout: readHeaderTimeout}
+	go func() {
+		if err := srv.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+			s.log.Warn(ctx, "probe listener failed", map[string]interface{}{
+				"addr":  s.cfg.ProbeAddr,
+				"error": err.Error(),
+			})
+		}
+	}()
+	return func() { _ = srv.Close() }
+}
+
+// serve serves requests on every listener until ctx is done or one of them
+// fails. HTTPS applies to TCP listeners only.
+func (s *Server) serve(ctx context.Context, listeners ...net.Listener) error {
+	// Also stops the other listeners and the shutdown goroutine if serving fails
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Requests outlive ctx while draining, until the server gives up on them.
+	base, cancelBase := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelBase()
+	stopBase := context.AfterFunc(s.abandoned, cancelBase)
+	defer stopBase()
+
+	srv := &http.Server{
+		Handler:           s.Handler(),
+		TLSConfig:         s.tlsConfig,
+		ReadHeaderTimeout: readHeaderTimeout,
+		BaseContext:       func(net.Listener) context.Context { return base },
+		ConnContext:       markLocalConn,
+	}
+
+	shutdownErr := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		shutdownErr <- s.shutdown(context.WithoutCancel(ctx), srv)
+	}()
+
+	addrs := make([]string, 0, len(listeners))
+	for _, l := range listeners {
+		addrs = append(addrs, l.Addr().Network()+":"+l.Addr().String())
+	}
+	s.log.Info(ctx, "serving slip resolution", map[string]interface{}{
+		"addrs":      addrs,
+		"tls":        s.tlsConfig != nil,
+		"mtls":       s.cfg.ClientCAFile != "",
+		"tokens":     len(s.cfg.Tokens),
+		"rate_limit": s.cfg.RateLimit,
+		"webhook":    len(s.cfg.WebhookSecret) > 0,
+		"probe_addr": s.cfg.ProbeAddr,
+	})
+
+	serveErrs := make(chan error, len(listeners))
+	for _, l := range listeners {
+		go func() {
+			var err error
+			if s.tlsConfig != nil && l.Addr().Network() == "tcp" {
+				err = srv.ServeTLS(l, s.cfg.TLSCertFile, s.cfg.TLSKeyFile)
+			} else {
+				err = srv.Serve(l)
+			}
+			if !errors.Is(err, http.ErrServerClosed) {
+				cancel()
+			}
+			serveErrs <- err
+		}()
+	}
+
+	var firstErr error
+	for range listeners {
+		if err := <-serveErrs; firstErr == nil && !errors.Is(err, http.ErrServerClosed) {
+			firstErr = err
+		}
+	}
+	cancel()
+	if err := <-shutdownErr; firstErr == nil {
+		return err
+	}
+	return firstErr
+}
+
+// shutdown stops accepting connections and waits for requests in flight and
+// webhook pre-resolutions to finish. Once cfg.ShutdownTimeout has passed, the
+// rest are cancelled and their connections closed.
+func (s *Server) shutdown(ctx context.Context, srv *http.Server) error {
+	timeout := s.cfg.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+	s.draining.Store(true)
+	s.log.Info(ctx, "shutting down, draining requests", map[string]interface{}{
+		"timeout_ms": timeout.Milliseconds(),
+	})
+	drainCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := srv.Shutdown(drainCtx)
+	if err == nil {
+		err = waitContext(drainCtx, &s.warming)
+	}
+	if err != nil {
+		s.log.Warn(ctx, "drain deadline passed, cancelling requests in flight", map[string]interface{}{
+			"timeout_ms": timeout.Milliseconds(),
+		})
+		s.abandon()
+		if closeErr := srv.Close(); closeErr != nil {
+			return closeErr
+		}
+	}
+	s.warming.Wait()
+	s.log.Info(ctx, "server stopped", nil)
+	return nil
+}
+
+// waitContext waits for wg, or until ctx is done.
+func waitContext(ctx context.Context, wg *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
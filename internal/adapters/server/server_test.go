@@ -0,0 +1,296 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// testCA is a throwaway certificate authority.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns a PEM certificate and key for commonName, signed by the CA.
+func (ca *testCA) issue(t *testing.T, commonName string, usage x509.ExtKeyUsage) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// writeFile writes data to name in dir and returns its path.
+func writeFile(t *testing.T, dir, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func TestNew_TLSSettings(t *testing.T) {
+	dir := t.TempDir()
+	caFile := writeFile(t, dir, "ca.pem", newTestCA(t).pem)
+	notPEM := writeFile(t, dir, "not.pem", []byte("not a certificate"))
+
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr error
+		wantMsg string
+		wantTLS bool
+	}{
+		{name: "plain HTTP", cfg: Config{}},
+		{name: "HTTPS", cfg: Config{TLSCertFile: "cert.pem", TLSKeyFile: "key.pem"}, wantTLS: true},
+		{
+			name:    "mutual TLS",
+			cfg:     Config{TLSCertFile: "cert.pem", TLSKeyFile: "key.pem", ClientCAFile: caFile},
+			wantTLS: true,
+		},
+		{name: "certificate without key", cfg: Config{TLSCertFile: "cert.pem"}, wantErr: ErrTLSIncomplete},
+		{name: "key without certificate", cfg: Config{TLSKeyFile: "key.pem"}, wantErr: ErrTLSIncomplete},
+		{name: "client CA without TLS", cfg: Config{ClientCAFile: caFile}, wantErr: ErrClientCAWithoutTLS},
+		{
+			name:    "client CA missing",
+			cfg:     Config{TLSCertFile: "cert.pem", TLSKeyFile: "key.pem", ClientCAFile: filepath.Join(dir, "nope")},
+			wantMsg: "failed to read client CA",
+		},
+		{
+			name:    "client CA not PEM",
+			cfg:     Config{TLSCertFile: "cert.pem", TLSKeyFile: "key.pem", ClientCAFile: notPEM},
+			wantMsg: "no certificates in",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, err := New(tt.cfg, clientEcho, nopLogger{})
+
+			switch {
+			case tt.wantErr != nil:
+				require.ErrorIs(t, err, tt.wantErr)
+			case tt.wantMsg != "":
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantMsg)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantTLS, srv.tlsConfig != nil)
+			}
+		})
+	}
+}
+
+func TestServer_Serve_Shutdown(t *testing.T) {
+	srv, err := New(Config{}, clientEcho, nopLogger{})
+	require.NoError(t, err)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.serve(ctx, listener) }()
+
+	resp, err := http.Post("http://"+listener.Addr().String()+"/v1/resolve", "application/json",
+		strings.NewReader(`{"path":"/repo"}`))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(defaultShutdownTimeout):
+		t.Fatal("serve did not return after its context was cancelled")
+	}
+}
+
+func TestServer_Serve_DrainsRequests(t *testing.T) {
+	tests := []struct {
+		name       string
+		timeout    time.Duration
+		release    bool
+		wantStatus int
+	}{
+		{
+			name:       "request finishes within the deadline",
+			timeout:    5 * time.Second,
+			release:    true,
+			wantStatus: http.StatusOK,
+		},
+		{
+			// Its connection is closed: the client gets no response at all
+			name:       "request cancelled at the deadline",
+			timeout:    50 * time.Millisecond,
+			wantStatus: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			started := make(chan struct{})
+			release := make(chan struct{})
+			srv, err := New(Config{ShutdownTimeout: tt.timeout}, func(ctx context.Context, _ domain.ResolveRequest) (
+				*domain.ResolveOutput, error,
+			) {
+				close(started)
+				select {
+				case <-release:
+					return &domain.ResolveOutput{CorrelationID: "slip-1"}, nil
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
+			}, nopLogger{})
+			require.NoError(t, err)
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() { done <- srv.serve(ctx, listener) }()
+
+			status := make(chan int, 1)
+			go func() {
+				resp, err := http.Post("http://"+listener.Addr().String()+"/v1/resolve", "application/json",
+					strings.NewReader(`{"path":"/repo"}`))
+				if err != nil {
+					status <- 0
+					return
+				}
+				_ = resp.Body.Close()
+				status <- resp.StatusCode
+			}()
+			<-started
+			cancel()
+
+			// No new connections are accepted while draining
+			require.Eventually(t, func() bool {
+				conn, err := net.Dial("tcp", listener.Addr().String())
+				if err == nil {
+					_ = conn.Close()
+				}
+				return err != nil
+			}, time.Second, 10*time.Millisecond)
+			if tt.release {
+				close(release)
+			}
+
+			assert.Equal(t, tt.wantStatus, <-status)
+			assert.NoError(t, <-done)
+		})
+	}
+}
+
+func TestServer_Serve_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	serverCert, serverKey := ca.issue(t, "slippy-find", x509.ExtKeyUsageServerAuth)
+	clientCert, clientKey := ca.issue(t, "build-farm", x509.ExtKeyUsageClientAuth)
+
+	srv, err := New(Config{
+		TLSCertFile:  writeFile(t, dir, "server.pem", serverCert),
+		TLSKeyFile:   writeFile(t, dir, "server-key.pem", serverKey),
+		ClientCAFile: writeFile(t, dir, "ca.pem", ca.pem),
+	}, clientEcho, nopLogger{})
+	require.NoError(t, err)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.serve(ctx, listener) }()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	keyPair, err := tls.X509KeyPair(clientCert, clientKey)
+	require.NoError(t, err)
+	url := "https://" + listener.Addr().String() + "/v1/resolve"
+
+	t.Run("with client certificate", func(t *testing.T) {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs:      roots,
+			Certificates: []tls.Certificate{keyPair},
+			MinVersion:   tls.VersionTLS12,
+		}}}
+
+		resp, err := client.Post(url, "application/json", strings.NewReader(`{"path":"/repo"}`))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var body bytes.Buffer
+		_, err = body.ReadFrom(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Contains(t, body.String(), `"correlation_id":"cn:build-farm"`)
+	})
+
+	t.Run("without client certificate", func(t *testing.T) {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs:    roots,
+			MinVersion: tls.VersionTLS12,
+		}}}
+
+		resp, err := client.Post(url, "application/json", strings.NewReader(`{"path":"/repo"}`))
+		if err == nil {
+			resp.Body.Close()
+		}
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+)
+
+// socketMode lets the server's user and group use the socket, so build agents
+// are granted access by group membership.
+const socketMode = 0o660
+
+// localConnKey is the context key marking connections accepted on the unix socket.
+type localConnKey struct{}
+
+// markLocalConn is an http.Server ConnContext that marks unix socket connections.
+func markLocalConn(ctx context.Context, conn net.Conn) context.Context {
+	if conn.LocalAddr().Network() == "unix" {
+		return context.WithValue(ctx, localConnKey{}, true)
+	}
+	return ctx
+}
+
+// isLocal reports whether the request on ctx arrived over the unix socket.
+func isLocal(ctx context.Context) bool {
+	local, _ := ctx.Value(localConnKey{}).(bool)
+	return local
+}
+
+// listenUnix listens on the unix socket at path with socketMode permissions.
+// A socket left behind by a previous run is replaced; a socket another server
+// is still answering on, or any other file, is an error.
+func listenUnix(path string) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("failed to listen on %s: file exists and is not a socket", path)
+		}
+		if conn, err := net.Dial("unix", path); err == nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("failed to listen on %s: socket is in use by another server", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	if err := os.Chmod(path, socketMode); err != nil {
+		_ = listener.Close()
+		return nil, fmt.Errorf("failed to set socket permissions: %w", err)
+	}
+	return listener, nil
+}
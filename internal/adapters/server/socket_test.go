@@ -0,0 +1,163 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// socketHTTPClient returns an HTTP client that connects to the unix socket at path.
+func socketHTTPClient(path string) *http.Client {
+	return &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+}
+
+// startServer serves srv on its configured listeners until the test ends.
+func startServer(t *testing.T, srv *Server) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-done)
+	})
+}
+
+// waitForSocket waits until something listens on the unix socket at path.
+func waitForSocket(t *testing.T, path string) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("unix", path)
+		if err != nil {
+			return false
+		}
+		_ = conn.Close()
+		return true
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestListenUnix(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "slippy.sock")
+
+	listener, err := listenUnix(path)
+	require.NoError(t, err)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(socketMode), info.Mode().Perm())
+
+	// A second server must not take over a socket in use
+	_, err = listenUnix(path)
+	assert.ErrorContains(t, err, "socket is in use by another server")
+
+	// A socket left behind without a server is replaced
+	stale := filepath.Join(dir, "stale.sock")
+	staleListener, err := net.Listen("unix", stale)
+	require.NoError(t, err)
+	staleListener.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, staleListener.Close())
+	replaced, err := listenUnix(stale)
+	require.NoError(t, err)
+
+	require.NoError(t, listener.Close())
+	require.NoError(t, replaced.Close())
+}
+
+func TestListenUnix_NotASocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "slippy.sock")
+	require.NoError(t, os.WriteFile(path, []byte("keep me"), 0o600))
+
+	_, err := listenUnix(path)
+
+	assert.ErrorContains(t, err, "file exists and is not a socket")
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "keep me", string(content))
+}
+
+func TestServer_Serve_NoListener(t *testing.T) {
+	srv, err := New(Config{}, clientEcho, nopLogger{})
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, srv.Serve(context.Background()), ErrNoListener)
+}
+
+func TestServer_Serve_Socket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "slippy.sock")
+	srv, err := New(Config{
+		SocketPath: path,
+		Tokens:     map[string]string{"token-a": "team-a"},
+		RateLimit:  0.01,
+		RateBurst:  1,
+	}, clientEcho, nopLogger{})
+	require.NoError(t, err)
+	startServer(t, srv)
+	waitForSocket(t, path)
+	client := socketHTTPClient(path)
+
+	// No token needed, and no rate limit applies
+	for range 3 {
+		resp, err := client.Post(socketURL, "application/json", strings.NewReader(`{"path":"/repo"}`))
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Contains(t, string(body), `"correlation_id":"unix"`)
+	}
+}
+
+func TestServer_Serve_SocketAndTCP(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "slippy.sock")
+	tcp, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := tcp.Addr().String()
+	require.NoError(t, tcp.Close())
+
+	srv, err := New(Config{
+		Addr:       addr,
+		SocketPath: path,
+		Tokens:     map[string]string{"token-a": "team-a"},
+	}, clientEcho, nopLogger{})
+	require.NoError(t, err)
+	startServer(t, srv)
+	waitForSocket(t, path)
+
+	// TCP requests still need a token
+	resp, err := http.Post("http://"+addr+"/v1/resolve", "application/json", strings.NewReader(`{"path":"/repo"}`))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp, err = socketHTTPClient(path).Post(socketURL, "application/json", strings.NewReader(`{"path":"/repo"}`))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestServer_Serve_SocketInUse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "slippy.sock")
+	first, err := New(Config{SocketPath: path}, clientEcho, nopLogger{})
+	require.NoError(t, err)
+	startServer(t, first)
+	waitForSocket(t, path)
+
+	second, err := New(Config{SocketPath: path}, clientEcho, nopLogger{})
+	require.NoError(t, err)
+
+	assert.ErrorContains(t, second.Serve(context.Background()), "socket is in use")
+}
@@ -0,0 +1,15 @@
+//go:build !js
+
+package server
+
+import (
+	"net"
+	"net/http"
+)
+
+// httpTransport returns the transport of HTTP clients, which gives up
+// connecting after dialTimeout.
+func httpTransport() *http.Transport {
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	return &http.Transport{DialContext: dialer.DialContext}
+}
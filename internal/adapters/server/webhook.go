@@ -0,0 +1,163 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// maxWebhookBytes bounds the size of a webhook payload. GitHub caps payloads at 25 MB.
+const maxWebhookBytes = 25 << 20
+
+// maxWarming bounds the number of pushes pre-resolved at once.
+const maxWarming = 8
+
+// warmTimeout bounds a single pre-resolution.
+const warmTimeout = 30 * time.Second
+
+// zeroSHA is the "after" commit of a push that deletes its branch.
+const zeroSHA = "0000000000000000000000000000000000000000"
+
+// pushEventJSON holds the fields of a GitHub push event used for pre-warming.
+type pushEventJSON struct {
+	Ref     string `json:"ref"`
+	After   string `json:"after"`
+	Deleted bool   `json:"deleted"`
+	Commits []struct {
+		ID string `json:"id"`
+	} `json:"commits"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// handleGitHubWebhook serves POST /v1/webhooks/github, and the same path
+// under /t/{tenant}/ for a tenant's store. A verified push event is answered
+// at once, and its head commit is resolved in the background so that the
+// server's cache already holds the slip when CI jobs for the commit ask for it.
+func (s *Server) handleGitHubWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBytes))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, domain.CodeInvalidInput, "invalid request body: "+err.Error())
+		return
+	}
+	if !validSignature(s.cfg.WebhookSecret, body, r.Header.Get("X-Hub-Signature-256")) {
+		s.log.Warn(ctx, "rejected webhook with invalid signature", map[string]interface{}{
+			"delivery": r.Header.Get("X-GitHub-Delivery"),
+			"remote":   r.RemoteAddr,
+		})
+		writeError(w, http.StatusUnauthorized, domain.CodeInvalidInput, "missing or invalid webhook signature")
+		return
+	}
+
+	event := r.Header.Get("X-GitHub-Event")
+	if event != "push" {
+		// Includes the "ping" sent when the webhook is created.
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ignored", "event": event})
+		return
+	}
+	var push pushEventJSON
+	if err := json.Unmarshal(body, &push); err != nil {
+		writeError(w, http.StatusBadRequest, domain.CodeInvalidInput, "invalid push event: "+err.Error())
+		return
+	}
+	req, ok := push.resolveRequest()
+	if !ok {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ignored", "event": event})
+		return
+	}
+	req.Tenant = r.PathValue("tenant")
+
+	select {
+	case s.warmSlots <- struct{}{}:
+	default:
+		writeError(w, http.StatusServiceUnavailable, domain.CodeUnknown, "too many pushes being pre-resolved")
+		return
+	}
+	s.warming.Add(1)
+	go func() {
+		defer func() {
+			<-s.warmSlots
+			s.warming.Done()
+		}()
+		s.warm(context.WithoutCancel(ctx), req, r.Header.Get("X-GitHub-Delivery"))
+	}()
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "accepted", "commit": push.After})
+}
+
+// warm resolves req, logging the outcome. The result itself is kept by the
+// resolver's cache.
+func (s *Server) warm(ctx context.Context, req domain.ResolveRequest, delivery string) {
+	ctx, cancel := context.WithTimeout(ctx, warmTimeout)
+	defer cancel()
+	stop := context.AfterFunc(s.abandoned, cancel)
+	defer stop()
+
+	start := time.Now()
+	result, err := s.resolve(ctx, req)
+	fields := map[string]interface{}{
+		"delivery":    delivery,
+		"tenant":      req.Tenant,
+		"repository":  req.Repository,
+		"commit":      req.Commits[0],
+		"duration_ms": time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+		s.log.Info(ctx, "push not pre-resolved", fields)
+		return
+	}
+	fields["correlation_id"] = result.CorrelationID
+	s.log.Info(ctx, "push pre-resolved", fields)
+}
+
+// resolveRequest converts a push to the request resolving its head commit.
+// The push's commits, newest first, are the known ancestry. Returns false
+// for pushes with nothing to resolve: tags and deleted branches.
+func (p pushEventJSON) resolveRequest() (domain.ResolveRequest, bool) {
+	branch, isBranch := strings.CutPrefix(p.Ref, "refs/heads/")
+	if !isBranch || p.Deleted || p.After == "" || p.After == zeroSHA || p.Repository.FullName == "" {
+		return domain.ResolveRequest{}, false
+	}
+
+	commits := make([]string, 0, len(p.Commits)+1)
+	for _, c := range slices.Backward(p.Commits) {
+		commits = append(commits, c.ID)
+	}
+	// A push of existing commits (e.g. a fast-forward merge) lists none of them.
+	if len(commits) == 0 || commits[0] != p.After {
+		commits = slices.Insert(commits, 0, p.After)
+	}
+	return domain.ResolveRequest{
+		Repository: p.Repository.FullName,
+		Branch:     branch,
+		Commits:    commits,
+		Verified:   true,
+	}, true
+}
+
+// validSignature reports whether header is the "sha256=<hex>" HMAC of body
+// under secret, as GitHub sends in X-Hub-Signature-256.
+func validSignature(secret, body []byte, header string) bool {
+	hexSum, ok := strings.CutPrefix(header, "sha256=")
+	if !ok {
+		return false
+	}
+	sum, err := hex.DecodeString(hexSum)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), sum)
+}
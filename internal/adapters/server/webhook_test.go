@@ -0,0 +1,255 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+var testWebhookSecret = []byte("s3cret")
+
+// pushPayload is a GitHub push to main of two new commits.
+const pushPayload = `{
+	"ref": "refs/heads/main",
+	"after": "c2",
+	"deleted": false,
+	"commits": [{"id": "c1"}, {"id": "c2"}],
+	"repository": {"full_name": "org/repo"}
+}`
+
+// sign returns the X-Hub-Signature-256 header GitHub sends for body.
+func sign(secret []byte, body string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// postWebhook sends a GitHub event to handler, signed with signature.
+func postWebhook(handler http.Handler, event, body, signature string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/v1/webhooks/github", strings.NewReader(body))
+	req.Header.Set("X-GitHub-Event", event)
+	req.Header.Set("X-GitHub-Delivery", "delivery-1")
+	if signature != "" {
+		req.Header.Set("X-Hub-Signature-256", signature)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// chanResolve returns a ResolveFunc that sends each request to the returned channel.
+func chanResolve() (ResolveFunc, chan domain.ResolveRequest) {
+	requests := make(chan domain.ResolveRequest, 1)
+	return func(_ context.Context, req domain.ResolveRequest) (*domain.ResolveOutput, error) {
+		requests <- req
+		return &domain.ResolveOutput{CorrelationID: "slip-1"}, nil
+	}, requests
+}
+
+func TestHandleGitHubWebhook_Push(t *testing.T) {
+	resolve, requests := chanResolve()
+	srv, err := New(Config{WebhookSecret: testWebhookSecret}, resolve, nopLogger{})
+	require.NoError(t, err)
+
+	rec := postWebhook(srv.Handler(), "push", pushPayload, sign(testWebhookSecret, pushPayload))
+
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.JSONEq(t, `{"status":"accepted","commit":"c2"}`, rec.Body.String())
+	select {
+	case req := <-requests:
+		assert.Equal(t, domain.ResolveRequest{
+			Repository: "org/repo",
+			Branch:     "main",
+			Commits:    []string{"c2", "c1"},
+			Verified:   true,
+		}, req)
+	case <-time.After(2 * time.Second):
+		t.Fatal("push was not pre-resolved")
+	}
+	srv.warming.Wait()
+}
+
+func TestHandleGitHubWebhook_Tenant(t *testing.T) {
+	resolve, requests := chanResolve()
+	srv, err := New(Config{WebhookSecret: testWebhookSecret}, resolve, nopLogger{})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/t/payments/v1/webhooks/github", strings.NewReader(pushPayload))
+	req.Header.Set("X-GitHub-Event", "push")
+	req.Header.Set("X-Hub-Signature-256", sign(testWebhookSecret, pushPayload))
+	rec := httptest.NewRecorder()
+
+	srv.Handler().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	select {
+	case req := <-requests:
+		assert.Equal(t, "payments", req.Tenant)
+	case <-time.After(2 * time.Second):
+		t.Fatal("push was not pre-resolved")
+	}
+	srv.warming.Wait()
+}
+
+func TestHandleGitHubWebhook_Rejected(t *testing.T) {
+	tests := []struct {
+		name       string
+		event      string
+		body       string
+		signature  string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "missing signature",
+			event:      "push",
+			body:       pushPayload,
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   "missing or invalid webhook signature",
+		},
+		{
+			name:       "wrong secret",
+			event:      "push",
+			body:       pushPayload,
+			signature:  sign([]byte("other"), pushPayload),
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   "missing or invalid webhook signature",
+		},
+		{
+			name:       "ping",
+			event:      "ping",
+			body:       `{"zen":"Keep it logically awesome."}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `"status":"ignored"`,
+		},
+		{
+			name:       "tag push",
+			event:      "push",
+			body:       `{"ref":"refs/tags/v1.0.0","after":"c2","repository":{"full_name":"org/repo"}}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `"status":"ignored"`,
+		},
+		{
+			name:       "invalid payload",
+			event:      "push",
+			body:       `not json`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid push event",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got domain.ResolveRequest
+			srv, err := New(Config{WebhookSecret: testWebhookSecret}, stubResolve(&got, nil, nil), nopLogger{})
+			require.NoError(t, err)
+			signature := tt.signature
+			if signature == "" && tt.wantStatus != http.StatusUnauthorized {
+				signature = sign(testWebhookSecret, tt.body)
+			}
+
+			rec := postWebhook(srv.Handler(), tt.event, tt.body, signature)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.wantBody)
+			srv.warming.Wait()
+			assert.Empty(t, got.Repository, "nothing is pre-resolved")
+		})
+	}
+}
+
+func TestHandleGitHubWebhook_Busy(t *testing.T) {
+	srv, err := New(Config{WebhookSecret: testWebhookSecret}, clientEcho, nopLogger{})
+	require.NoError(t, err)
+	for range maxWarming {
+		srv.warmSlots <- struct{}{}
+	}
+
+	rec := postWebhook(srv.Handler(), "push", pushPayload, sign(testWebhookSecret, pushPayload))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestHandleGitHubWebhook_Disabled(t *testing.T) {
+	srv, err := New(Config{}, clientEcho, nopLogger{})
+	require.NoError(t, err)
+
+	rec := postWebhook(srv.Handler(), "push", pushPayload, sign(nil, pushPayload))
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestPushEvent_ResolveRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		push   pushEventJSON
+		want   []string
+		wantOK bool
+	}{
+		{
+			name:   "new commits, newest first",
+			push:   pushEvent("refs/heads/main", "c3", "c1", "c2", "c3"),
+			want:   []string{"c3", "c2", "c1"},
+			wantOK: true,
+		},
+		{
+			name:   "existing commit",
+			push:   pushEvent("refs/heads/main", "c3"),
+			want:   []string{"c3"},
+			wantOK: true,
+		},
+		{
+			name:   "head not among listed commits",
+			push:   pushEvent("refs/heads/main", "c9", "c1"),
+			want:   []string{"c9", "c1"},
+			wantOK: true,
+		},
+		{name: "tag", push: pushEvent("refs/tags/v1", "c3", "c3")},
+		{name: "deleted branch", push: pushEvent("refs/heads/old", zeroSHA)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, ok := tt.push.resolveRequest()
+
+			assert.Equal(t, tt.wantOK, ok)
+			if tt.wantOK {
+				assert.Equal(t, tt.want, req.Commits)
+				assert.Equal(t, "org/repo", req.Repository)
+			}
+		})
+	}
+}
+
+// pushEvent builds a push of commits (oldest first) to ref of org/repo.
+func pushEvent(ref, after string, commits ...string) pushEventJSON {
+	push := pushEventJSON{Ref: ref, After: after}
+	push.Repository.FullName = "org/repo"
+	for _, id := range commits {
+		push.Commits = append(push.Commits, struct {
+			ID string `json:"id"`
+		}{ID: id})
+	}
+	return push
+}
+
+func TestValidSignature(t *testing.T) {
+	body := []byte(`{"ref":"refs/heads/main"}`)
+	unprefixed := strings.TrimPrefix(sign(testWebhookSecret, string(body)), "sha256=")
+
+	assert.True(t, validSignature(testWebhookSecret, body, sign(testWebhookSecret, string(body))))
+	assert.False(t, validSignature(testWebhookSecret, body, sign(testWebhookSecret, "tampered")))
+	assert.False(t, validSignature(testWebhookSecret, body, unprefixed))
+	assert.False(t, validSignature(testWebhookSecret, body, "sha256=not-hex"))
+	assert.False(t, validSignature(testWebhookSecret, body, ""))
+}
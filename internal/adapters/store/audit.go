@@ -0,0 +1,82 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// AuditTable is the table, in the slip database, that audit events are written to.
+// slippy-find does not create it; see the README for its schema.
+const AuditTable = "slippy_find_audit"
+
+// AuditSession executes statements against ClickHouse.
+// It is satisfied by ch.ClickhouseSessionInterface.
+type AuditSession interface {
+	ExecWithArgs(ctx context.Context, stmt string, args ...interface{}) error
+	Close() error
+}
+
+// ClickHouseAuditWriter implements domain.AuditWriter by inserting one row
+// per resolution into AuditTable.
+type ClickHouseAuditWriter struct {
+	session  AuditSession
+	database string
+}
+
+// NewClickHouseAuditWriter creates a writer inserting into AuditTable in database.
+// The writer takes ownership of session and closes it on Close.
+func NewClickHouseAuditWriter(session AuditSession, database string) *ClickHouseAuditWriter {
+	return &ClickHouseAuditWriter{
+		session:  session,
+		database: database,
+	}
+}
+
+// insertAuditQuery inserts one audit row; %s is the qualified table name.
+const insertAuditQuery = `
+		INSERT INTO %s (
+			timestamp, repository, branch, head_sha, matched_commit, correlation_id,
+			resolved_by, component, host, pipeline, duration_ms
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+// WriteAudit inserts event into the audit table.
+func (w *ClickHouseAuditWriter) WriteAudit(ctx context.Context, event domain.AuditEvent) error {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "ClickHouseAuditWriter.WriteAudit", trace.WithAttributes(
+		attribute.String("slippy.repository", event.Repository),
+		attribute.String("slippy.correlation_id", event.CorrelationID),
+	))
+	defer span.End()
+
+	err := w.session.ExecWithArgs(ctx, fmt.Sprintf(insertAuditQuery, w.database+"."+AuditTable),
+		event.Timestamp.UTC(),
+		event.Repository,
+		event.Branch,
+		event.HeadSHA,
+		event.MatchedCommit,
+		event.CorrelationID,
+		event.ResolvedBy,
+		event.Component,
+		event.Caller.Host,
+		event.Caller.Pipeline,
+		event.Duration.Milliseconds(),
+	)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to write audit event: %w", err)
+	}
+	return nil
+}
+
+// Close closes the underlying session.
+func (w *ClickHouseAuditWriter) Close() error {
+	return w.session.Close()
+}
@@ -0,0 +1,79 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// mockAuditSession implements AuditSession, recording the last statement.
+type mockAuditSession struct {
+	stmt     string
+	args     []interface{}
+	execErr  error
+	closed   bool
+	closeErr error
+}
+
+func (m *mockAuditSession) ExecWithArgs(_ context.Context, stmt string, args ...interface{}) error {
+	m.stmt = stmt
+	m.args = args
+	return m.execErr
+}
+
+func (m *mockAuditSession) Close() error {
+	m.closed = true
+	return m.closeErr
+}
+
+func TestClickHouseAuditWriter_WriteAudit(t *testing.T) {
+	session := &mockAuditSession{}
+	writer := NewClickHouseAuditWriter(session, "ci")
+	at := time.Date(2026, 6, 1, 12, 0, 0, 0, time.FixedZone("EST", -5*3600))
+
+	err := writer.WriteAudit(context.Background(), domain.AuditEvent{
+		Timestamp:     at,
+		Repository:    "org/repo",
+		Branch:        "main",
+		HeadSHA:       "c0",
+		MatchedCommit: "c1",
+		CorrelationID: "slip-1",
+		ResolvedBy:    domain.ResolvedByAncestry,
+		Component:     "payments-api",
+		Caller:        domain.Annotation{Host: "runner-1", Pipeline: "build/42"},
+		Duration:      1500 * time.Millisecond,
+	})
+
+	require.NoError(t, err)
+	assert.Contains(t, session.stmt, "INSERT INTO ci.slippy_find_audit")
+	assert.Equal(t, []interface{}{
+		at.UTC(), "org/repo", "main", "c0", "c1", "slip-1", domain.ResolvedByAncestry,
+		"payments-api", "runner-1", "build/42", int64(1500),
+	}, session.args)
+}
+
+func TestClickHouseAuditWriter_WriteAudit_Error(t *testing.T) {
+	execErr := errors.New("table does not exist")
+	writer := NewClickHouseAuditWriter(&mockAuditSession{execErr: execErr}, "ci")
+
+	err := writer.WriteAudit(context.Background(), domain.AuditEvent{CorrelationID: "slip-1"})
+
+	require.ErrorIs(t, err, execErr)
+	assert.Contains(t, err.Error(), "failed to write audit event")
+}
+
+func TestClickHouseAuditWriter_Close(t *testing.T) {
+	closeErr := errors.New("already closed")
+	session := &mockAuditSession{closeErr: closeErr}
+
+	err := NewClickHouseAuditWriter(session, "ci").Close()
+
+	assert.ErrorIs(t, err, closeErr)
+	assert.True(t, session.closed)
+}
@@ -3,16 +3,47 @@ package store
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
 
+	ch "github.com/MyCarrier-DevOps/goLibMyCarrier/clickhouse"
 	"github.com/MyCarrier-DevOps/goLibMyCarrier/slippy"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 
-	"github.com/MyCarrier-DevOps/slippy-find/internal/domain"
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
 )
 
+// ErrSessionRequired indicates a direct query was attempted on an adapter without a session.
+var ErrSessionRequired = errors.New("clickhouse session required for this lookup")
+
+// tracerName identifies this package's spans. Tracers are looked up per call so
+// spans follow the tracer provider installed at startup.
+const tracerName = "github.com/MyCarrier-DevOps/slippy-find/internal/adapters/store"
+
 // ClickHouseAdapter wraps goLibMyCarrier's SlipStore to implement domain.SlipFinder.
 // This adapter translates between the external library types and our domain types.
 type ClickHouseAdapter struct {
-	store slippy.SlipStore
+	store    slippy.SlipStore
+	session  Querier
+	database string
+
+	// Query tuning set by ClickHouseOptions.
+	chunkSize       int
+	maxQueryCommits int
+	commitList      CommitList
+}
+
+// Querier runs a single-row query against ClickHouse.
+// It is satisfied by ch.ClickhouseSessionInterface and is used for lookups
+// that goLibMyCarrier's SlipStore does not provide.
+type Querier interface {
+	QueryRow(ctx context.Context, query string, args ...interface{}) ch.Row
 }
 
 // NewClickHouseAdapter creates a new adapter wrapping the given SlipStore.
@@ -22,6 +53,25 @@ func NewClickHouseAdapter(store slippy.SlipStore) *ClickHouseAdapter {
 	}
 }
 
+// NewClickHouseAdapterWithSession creates an adapter that can also run direct queries
+// (such as FindLatestByBranch) against the given session and database, tuned by opts.
+func NewClickHouseAdapterWithSession(
+	store slippy.SlipStore,
+	session Querier,
+	database string,
+	opts ...ClickHouseOption,
+) *ClickHouseAdapter {
+	a := &ClickHouseAdapter{
+		store:    store,
+		session:  session,
+		database: database,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
 // FindByCommits searches for a slip matching any of the given commits.
 // Returns the slip, the matched commit SHA, and any error.
 // Returns (nil, "", nil) if no matching slip is found.
@@ -30,22 +80,276 @@ func (a *ClickHouseAdapter) FindByCommits(
 	repository string,
 	commits []string,
 ) (*domain.Slip, string, error) {
-	slip, matchedCommit, err := a.store.FindByCommits(ctx, repository, commits)
-	if err != nil {
-		return nil, "", err
-	}
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "ClickHouseAdapter.FindByCommits", trace.WithAttributes(
+		attribute.String("slippy.repository", repository),
+		attribute.Int("slippy.commits", len(commits)),
+	))
+	defer span.End()
 
+	var (
+		slip          *slippy.Slip
+		matchedCommit string
+	)
+	for _, batch := range a.batches(commits) {
+		var err error
+		slip, matchedCommit, err = a.store.FindByCommits(ctx, repository, batch)
+		if errors.Is(err, slippy.ErrSlipNotFound) {
+			continue
+		}
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, "", err
+		}
+		if slip != nil {
+			break
+		}
+	}
 	if slip == nil {
 		return nil, "", nil
 	}
 
-	// Convert to domain type
-	return &domain.Slip{
-		CorrelationID: slip.CorrelationID,
-	}, matchedCommit, nil
+	span.SetAttributes(
+		attribute.String("slippy.correlation_id", slip.CorrelationID),
+		attribute.String("slippy.matched_commit", matchedCommit),
+	)
+	return toDomainSlip(slip), matchedCommit, nil
+}
+
+// FindAllByCommits returns every slip matching any of the given commits,
+// ordered by commit priority as returned by the store.
+func (a *ClickHouseAdapter) FindAllByCommits(
+	ctx context.Context,
+	repository string,
+	commits []string,
+) ([]domain.SlipMatch, error) {
+	var results []slippy.SlipWithCommit
+	for _, batch := range a.batches(commits) {
+		found, err := a.store.FindAllByCommits(ctx, repository, batch)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, found...)
+	}
+
+	matches := make([]domain.SlipMatch, 0, len(results))
+	for _, r := range results {
+		if r.Slip == nil {
+			continue
+		}
+		matches = append(matches, domain.SlipMatch{
+			Slip:          toDomainSlip(r.Slip),
+			MatchedCommit: r.MatchedCommit,
+		})
+	}
+	return matches, nil
+}
+
+// nearestByCommitsQuery selects the first of the commits with any active slip
+// and the correlation IDs of all its slips. Repository comparison is
+// case-insensitive to match the store's commit queries.
+const nearestByCommitsQuery = `
+		WITH commits AS (
+			SELECT
+				arrayJoin(range(1, length({commits:Array(String)}) + 1)) AS priority,
+				{commits:Array(String)}[priority] AS commit_sha
+		)
+		SELECT c.commit_sha, groupUniqArray(s.correlation_id)
+		FROM %s.routing_slips s
+		INNER JOIN commits c ON s.commit_sha = c.commit_sha
+		WHERE lower(s.repository) = lower({repository:String})
+		  AND s.sign = 1
+		GROUP BY c.priority, c.commit_sha
+		ORDER BY c.priority ASC
+		LIMIT 1
+	`
+
+// FindNearestByCommits returns every slip recorded for the first of commits
+// that has any: one query per batch finds the commit and its correlation IDs,
+// and each slip is then loaded. Returns domain.ErrNearestUnsupported if the
+// adapter has no session.
+func (a *ClickHouseAdapter) FindNearestByCommits(
+	ctx context.Context,
+	repository string,
+	commits []string,
+) ([]domain.SlipMatch, error) {
+	if a.session == nil {
+		return nil, domain.ErrNearestUnsupported
+	}
+	if len(commits) == 0 {
+		return []domain.SlipMatch{}, nil
+	}
+	for _, batch := range a.batches(commits) {
+		matches, err := a.findNearestInBatch(ctx, repository, batch)
+		if err != nil || len(matches) > 0 {
+			return matches, err
+		}
+	}
+	return []domain.SlipMatch{}, nil
+}
+
+// findNearestInBatch implements FindNearestByCommits for one batch of commits.
+func (a *ClickHouseAdapter) findNearestInBatch(
+	ctx context.Context,
+	repository string,
+	commits []string,
+) ([]domain.SlipMatch, error) {
+	ctx, query, args, err := a.nearestQuery(ctx, repository, commits)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		matchedCommit  string
+		correlationIDs []string
+	)
+	row := a.session.QueryRow(ctx, query, args...)
+	if err := row.Scan(&matchedCommit, &correlationIDs); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return []domain.SlipMatch{}, nil
+		}
+		return nil, fmt.Errorf("failed to query slips of the nearest commit: %w", err)
+	}
+
+	slices.Sort(correlationIDs)
+	matches := make([]domain.SlipMatch, 0, len(correlationIDs))
+	for _, id := range correlationIDs {
+		slip, err := a.store.Load(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load slip %s: %w", id, err)
+		}
+		matches = append(matches, domain.SlipMatch{Slip: toDomainSlip(slip), MatchedCommit: matchedCommit})
+	}
+	return matches, nil
+}
+
+// latestByBranchQuery selects the newest active slip for a repository and branch.
+// Repository comparison is case-insensitive to match the store's commit queries.
+const latestByBranchQuery = `
+		SELECT correlation_id
+		FROM %s.routing_slips
+		WHERE lower(repository) = lower({repository:String})
+		  AND branch = {branch:String}
+		  AND sign = 1
+		ORDER BY created_at DESC, version DESC
+		LIMIT 1
+	`
+
+// FindLatestByBranch returns the most recently created slip for the repository and branch.
+// Returns domain.ErrSlipNotFound if the branch has no slips.
+func (a *ClickHouseAdapter) FindLatestByBranch(
+	ctx context.Context,
+	repository, branch string,
+) (*domain.Slip, error) {
+	if a.session == nil {
+		return nil, ErrSessionRequired
+	}
+
+	var correlationID string
+	row := a.session.QueryRow(ctx, fmt.Sprintf(latestByBranchQuery, a.database),
+		ch.Named("repository", repository),
+		ch.Named("branch", branch),
+	)
+	if err := row.Scan(&correlationID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s@%s", domain.ErrSlipNotFound, repository, branch)
+		}
+		return nil, fmt.Errorf("failed to query latest slip for branch: %w", err)
+	}
+
+	slip, err := a.store.Load(ctx, correlationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load slip %s: %w", correlationID, err)
+	}
+	return toDomainSlip(slip), nil
+}
+
+// FindByID loads the slip with the given correlation ID.
+// Returns domain.ErrSlipNotFound if it does not exist.
+func (a *ClickHouseAdapter) FindByID(ctx context.Context, correlationID string) (*domain.Slip, error) {
+	slip, err := a.store.Load(ctx, correlationID)
+	if err != nil {
+		if errors.Is(err, slippy.ErrSlipNotFound) {
+			return nil, fmt.Errorf("%w: %s", domain.ErrSlipNotFound, correlationID)
+		}
+		return nil, fmt.Errorf("failed to load slip %s: %w", correlationID, err)
+	}
+	return toDomainSlip(slip), nil
+}
+
+// AppendHistory appends entry to the slip's state history.
+func (a *ClickHouseAdapter) AppendHistory(ctx context.Context, correlationID string, entry domain.HistoryEntry) error {
+	return a.store.AppendHistory(ctx, correlationID, slippy.StateHistoryEntry{
+		Step:      entry.Step,
+		Actor:     entry.Actor,
+		Message:   entry.Message,
+		Timestamp: entry.Timestamp,
+	})
+}
+
+// pingQuery is a query every ClickHouse server answers without touching a table.
+const pingQuery = "SELECT version()"
+
+// Ping runs a trivial query to check that ClickHouse can be reached.
+// Returns ErrSessionRequired if the adapter has no session.
+func (a *ClickHouseAdapter) Ping(ctx context.Context) error {
+	if a.session == nil {
+		return ErrSessionRequired
+	}
+	var version string
+	if err := a.session.QueryRow(ctx, pingQuery).Scan(&version); err != nil {
+		return fmt.Errorf("failed to ping clickhouse: %w", err)
+	}
+	return nil
+}
+
+// DescribeEnvironment implements domain.EnvironmentDescriber.
+func (a *ClickHouseAdapter) DescribeEnvironment(env *domain.Environment) {
+	env.Store = domain.StoreClickHouse
 }
 
 // Close releases any resources held by the store.
 func (a *ClickHouseAdapter) Close() error {
 	return a.store.Close()
 }
+
+// toDomainSlip converts a goLibMyCarrier slip to the domain representation.
+func toDomainSlip(slip *slippy.Slip) *domain.Slip {
+	return &domain.Slip{
+		CorrelationID: slip.CorrelationID,
+		Repository:    slip.Repository,
+		Branch:        slip.Branch,
+		CommitSHA:     slip.CommitSHA,
+		CreatedAt:     slip.CreatedAt,
+		Components:    slipComponents(slip),
+		Steps:         slipSteps(slip),
+	}
+}
+
+// slipSteps flattens the slip's step states to step name -> status.
+func slipSteps(slip *slippy.Slip) map[string]string {
+	if len(slip.Steps) == 0 {
+		return nil
+	}
+	steps := make(map[string]string, len(slip.Steps))
+	for name, step := range slip.Steps {
+		steps[name] = string(step.Status)
+	}
+	return steps
+}
+
+// slipComponents collects the distinct component names across all aggregate steps.
+func slipComponents(slip *slippy.Slip) []string {
+	seen := make(map[string]struct{})
+	for _, entries := range slip.Aggregates {
+		for _, entry := range entries {
+			if entry.Component != "" {
+				seen[entry.Component] = struct{}{}
+			}
+		}
+	}
+	if len(seen) == 0 {
+		return nil
+	}
+	return slices.Sorted(maps.Keys(seen))
+}
@@ -3,12 +3,22 @@ package store
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"testing"
+	"time"
 
+	ch "github.com/MyCarrier-DevOps/goLibMyCarrier/clickhouse"
 	"github.com/MyCarrier-DevOps/goLibMyCarrier/slippy"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
 )
 
 // mockSlipStore implements slippy.SlipStore for testing.
@@ -16,6 +26,14 @@ type mockSlipStore struct {
 	findByCommitsSlip   *slippy.Slip
 	findByCommitsCommit string
 	findByCommitsErr    error
+	findAllResults      []slippy.SlipWithCommit
+	findAllErr          error
+	loadSlip            *slippy.Slip
+	loadErr             error
+	loadedID            string
+	historyID           string
+	historyEntry        slippy.StateHistoryEntry
+	historyErr          error
 	closeErr            error
 	closeCalled         bool
 }
@@ -35,8 +53,9 @@ func (m *mockSlipStore) Close() error {
 
 // Implement other SlipStore methods as no-ops to satisfy the interface.
 func (m *mockSlipStore) Create(_ context.Context, _ *slippy.Slip) error { return nil }
-func (m *mockSlipStore) Load(_ context.Context, _ string) (*slippy.Slip, error) {
-	return nil, nil
+func (m *mockSlipStore) Load(_ context.Context, correlationID string) (*slippy.Slip, error) {
+	m.loadedID = correlationID
+	return m.loadSlip, m.loadErr
 }
 func (m *mockSlipStore) LoadByCommit(_ context.Context, _, _ string) (*slippy.Slip, error) {
 	return nil, nil
@@ -66,19 +85,68 @@ func (m *mockSlipStore) UpdateComponentStatus(
 }
 func (m *mockSlipStore) AppendHistory(
 	_ context.Context,
-	_ string,
-	_ slippy.StateHistoryEntry,
+	correlationID string,
+	entry slippy.StateHistoryEntry,
 ) error {
-	return nil
+	m.historyID = correlationID
+	m.historyEntry = entry
+	return m.historyErr
 }
 func (m *mockSlipStore) FindAllByCommits(
 	_ context.Context,
 	_ string,
 	_ []string,
 ) ([]slippy.SlipWithCommit, error) {
-	return nil, nil
+	return m.findAllResults, m.findAllErr
+}
+
+// mockQuerier implements Querier, returning a row that scans a fixed correlation ID,
+// followed by ids when a second column is scanned.
+type mockQuerier struct {
+	correlationID string
+	ids           []string
+	scanErr       error
+	query         string
+	args          []interface{}
 }
 
+func (m *mockQuerier) QueryRow(_ context.Context, query string, args ...interface{}) ch.Row {
+	m.query = query
+	m.args = args
+	return &mockRow{value: m.correlationID, ids: m.ids, err: m.scanErr}
+}
+
+// mockRow implements ch.Row for a string column, optionally followed by a
+// string array column.
+type mockRow struct {
+	value string
+	ids   []string
+	err   error
+}
+
+func (r *mockRow) Err() error { return r.err }
+
+func (r *mockRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	target, ok := dest[0].(*string)
+	if !ok {
+		return errors.New("mockRow scans into *string only")
+	}
+	*target = r.value
+	if len(dest) > 1 {
+		ids, ok := dest[1].(*[]string)
+		if !ok {
+			return errors.New("mockRow scans its second column into *[]string only")
+		}
+		*ids = r.ids
+	}
+	return nil
+}
+
+func (r *mockRow) ScanStruct(_ any) error { return r.err }
+
 func TestNewClickHouseAdapter(t *testing.T) {
 	mockStore := &mockSlipStore{}
 	adapter := NewClickHouseAdapter(mockStore)
@@ -144,6 +212,57 @@ func TestClickHouseAdapter_FindByCommits_Error(t *testing.T) {
 	assert.Equal(t, "", matchedCommit)
 }
 
+func TestClickHouseAdapter_FindByCommits_Span(t *testing.T) {
+	tests := []struct {
+		name       string
+		store      *mockSlipStore
+		wantStatus codes.Code
+		wantAttrs  []attribute.KeyValue
+	}{
+		{
+			name: "match",
+			store: &mockSlipStore{
+				findByCommitsSlip:   &slippy.Slip{CorrelationID: "slip-1"},
+				findByCommitsCommit: "abc123",
+			},
+			wantStatus: codes.Unset,
+			wantAttrs: []attribute.KeyValue{
+				attribute.String("slippy.repository", "test/repo"),
+				attribute.Int("slippy.commits", 2),
+				attribute.String("slippy.correlation_id", "slip-1"),
+				attribute.String("slippy.matched_commit", "abc123"),
+			},
+		},
+		{
+			name:       "error",
+			store:      &mockSlipStore{findByCommitsErr: errors.New("database connection failed")},
+			wantStatus: codes.Error,
+			wantAttrs: []attribute.KeyValue{
+				attribute.String("slippy.repository", "test/repo"),
+				attribute.Int("slippy.commits", 2),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			previous := otel.GetTracerProvider()
+			otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+			t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+			_, _, _ = NewClickHouseAdapter(tt.store).FindByCommits(
+				context.Background(), "test/repo", []string{"abc123", "def456"})
+
+			spans := recorder.Ended()
+			require.Len(t, spans, 1)
+			assert.Equal(t, "ClickHouseAdapter.FindByCommits", spans[0].Name())
+			assert.Equal(t, tt.wantStatus, spans[0].Status().Code)
+			assert.Equal(t, tt.wantAttrs, spans[0].Attributes())
+		})
+	}
+}
+
 func TestClickHouseAdapter_Close_Success(t *testing.T) {
 	mockStore := &mockSlipStore{}
 	adapter := NewClickHouseAdapter(mockStore)
@@ -166,3 +285,352 @@ func TestClickHouseAdapter_Close_Error(t *testing.T) {
 	assert.Contains(t, err.Error(), "close failed")
 	assert.True(t, mockStore.closeCalled)
 }
+
+func TestClickHouseAdapter_FindAllByCommits(t *testing.T) {
+	created := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	mockStore := &mockSlipStore{
+		findAllResults: []slippy.SlipWithCommit{
+			{
+				Slip: &slippy.Slip{
+					CorrelationID: "slip-1",
+					Repository:    "org/repo",
+					Branch:        "main",
+					CommitSHA:     "abc123",
+					CreatedAt:     created,
+				},
+				MatchedCommit: "abc123",
+			},
+			{Slip: nil, MatchedCommit: "skipped"},
+		},
+	}
+	adapter := NewClickHouseAdapter(mockStore)
+
+	matches, err := adapter.FindAllByCommits(context.Background(), "org/repo", []string{"abc123"})
+
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "abc123", matches[0].MatchedCommit)
+	assert.Equal(t, &domain.Slip{
+		CorrelationID: "slip-1",
+		Repository:    "org/repo",
+		Branch:        "main",
+		CommitSHA:     "abc123",
+		CreatedAt:     created,
+	}, matches[0].Slip)
+}
+
+func TestClickHouseAdapter_FindAllByCommits_Error(t *testing.T) {
+	adapter := NewClickHouseAdapter(&mockSlipStore{findAllErr: errors.New("query failed")})
+
+	matches, err := adapter.FindAllByCommits(context.Background(), "org/repo", []string{"abc123"})
+
+	require.Error(t, err)
+	assert.Nil(t, matches)
+}
+
+func TestClickHouseAdapter_FindByCommits_StoreNotFound(t *testing.T) {
+	adapter := NewClickHouseAdapter(&mockSlipStore{findByCommitsErr: slippy.ErrSlipNotFound})
+
+	slip, matchedCommit, err := adapter.FindByCommits(context.Background(), "org/repo", []string{"abc123"})
+
+	require.NoError(t, err)
+	assert.Nil(t, slip)
+	assert.Empty(t, matchedCommit)
+}
+
+func TestClickHouseAdapter_Ping(t *testing.T) {
+	tests := []struct {
+		name    string
+		querier *mockQuerier
+		wantErr string
+	}{
+		{name: "reachable", querier: &mockQuerier{correlationID: "24.8.1"}},
+		{
+			name:    "unreachable",
+			querier: &mockQuerier{scanErr: errors.New("connection refused")},
+			wantErr: "failed to ping clickhouse: connection refused",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := NewClickHouseAdapterWithSession(&mockSlipStore{}, tt.querier, "ci")
+
+			err := adapter.Ping(context.Background())
+
+			assert.Equal(t, pingQuery, tt.querier.query)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestClickHouseAdapter_Ping_NoSession(t *testing.T) {
+	adapter := NewClickHouseAdapter(&mockSlipStore{})
+
+	assert.ErrorIs(t, adapter.Ping(context.Background()), ErrSessionRequired)
+}
+
+func TestClickHouseAdapter_FindLatestByBranch(t *testing.T) {
+	created := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		querier    *mockQuerier
+		store      *mockSlipStore
+		wantSlip   *domain.Slip
+		wantErrIs  error
+		wantErrMsg string
+	}{
+		{
+			name:    "found",
+			querier: &mockQuerier{correlationID: "slip-9"},
+			store: &mockSlipStore{loadSlip: &slippy.Slip{
+				CorrelationID: "slip-9",
+				Repository:    "org/repo",
+				Branch:        "feature/x",
+				CommitSHA:     "def456",
+				CreatedAt:     created,
+			}},
+			wantSlip: &domain.Slip{
+				CorrelationID: "slip-9",
+				Repository:    "org/repo",
+				Branch:        "feature/x",
+				CommitSHA:     "def456",
+				CreatedAt:     created,
+			},
+		},
+		{
+			name:      "no rows",
+			querier:   &mockQuerier{scanErr: sql.ErrNoRows},
+			store:     &mockSlipStore{},
+			wantErrIs: domain.ErrSlipNotFound,
+		},
+		{
+			name:       "query error",
+			querier:    &mockQuerier{scanErr: errors.New("connection reset")},
+			store:      &mockSlipStore{},
+			wantErrMsg: "failed to query latest slip for branch",
+		},
+		{
+			name:       "load error",
+			querier:    &mockQuerier{correlationID: "slip-9"},
+			store:      &mockSlipStore{loadErr: errors.New("load failed")},
+			wantErrMsg: "failed to load slip slip-9",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := NewClickHouseAdapterWithSession(tt.store, tt.querier, "ci")
+
+			slip, err := adapter.FindLatestByBranch(context.Background(), "org/repo", "feature/x")
+
+			assert.Contains(t, tt.querier.query, "ci.routing_slips")
+			assert.Equal(t, []interface{}{
+				ch.Named("repository", "org/repo"),
+				ch.Named("branch", "feature/x"),
+			}, tt.querier.args)
+			if tt.wantErrIs != nil || tt.wantErrMsg != "" {
+				require.Error(t, err)
+				assert.Nil(t, slip)
+				if tt.wantErrIs != nil {
+					assert.ErrorIs(t, err, tt.wantErrIs)
+				}
+				if tt.wantErrMsg != "" {
+					assert.Contains(t, err.Error(), tt.wantErrMsg)
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "slip-9", tt.store.loadedID)
+			assert.Equal(t, tt.wantSlip, slip)
+		})
+	}
+}
+
+func TestClickHouseAdapter_FindNearestByCommits(t *testing.T) {
+	tests := []struct {
+		name        string
+		querier     *mockQuerier
+		store       *mockSlipStore
+		wantMatches []domain.SlipMatch
+		wantErrMsg  string
+	}{
+		{
+			name:    "found",
+			querier: &mockQuerier{correlationID: "c1", ids: []string{"slip-1"}},
+			store:   &mockSlipStore{loadSlip: &slippy.Slip{CorrelationID: "slip-1", CommitSHA: "c1"}},
+			wantMatches: []domain.SlipMatch{
+				{Slip: &domain.Slip{CorrelationID: "slip-1", CommitSHA: "c1"}, MatchedCommit: "c1"},
+			},
+		},
+		{
+			name:        "no rows",
+			querier:     &mockQuerier{scanErr: sql.ErrNoRows},
+			store:       &mockSlipStore{},
+			wantMatches: []domain.SlipMatch{},
+		},
+		{
+			name:       "query error",
+			querier:    &mockQuerier{scanErr: errors.New("connection reset")},
+			store:      &mockSlipStore{},
+			wantErrMsg: "failed to query slips of the nearest commit",
+		},
+		{
+			name:       "load error",
+			querier:    &mockQuerier{correlationID: "c1", ids: []string{"slip-1"}},
+			store:      &mockSlipStore{loadErr: errors.New("load failed")},
+			wantErrMsg: "failed to load slip slip-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := NewClickHouseAdapterWithSession(tt.store, tt.querier, "ci")
+
+			matches, err := adapter.FindNearestByCommits(context.Background(), "org/repo", []string{"c0", "c1"})
+
+			assert.Contains(t, tt.querier.query, "ci.routing_slips")
+			assert.Equal(t, []interface{}{
+				ch.Named("repository", "org/repo"),
+				ch.Named("commits", []string{"c0", "c1"}),
+			}, tt.querier.args)
+			if tt.wantErrMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantMatches, matches)
+		})
+	}
+}
+
+func TestClickHouseAdapter_FindNearestByCommits_NoSession(t *testing.T) {
+	adapter := NewClickHouseAdapter(&mockSlipStore{})
+
+	_, err := adapter.FindNearestByCommits(context.Background(), "org/repo", []string{"c0"})
+
+	assert.ErrorIs(t, err, domain.ErrNearestUnsupported)
+}
+
+func TestClickHouseAdapter_FindLatestByBranch_NoSession(t *testing.T) {
+	adapter := NewClickHouseAdapter(&mockSlipStore{})
+
+	slip, err := adapter.FindLatestByBranch(context.Background(), "org/repo", "main")
+
+	require.ErrorIs(t, err, ErrSessionRequired)
+	assert.Nil(t, slip)
+}
+
+func TestToDomainSlip_Components(t *testing.T) {
+	slip := &slippy.Slip{
+		CorrelationID: "slip-1",
+		Aggregates: map[string][]slippy.ComponentStepData{
+			"builds":       {{Component: "payments-api"}, {Component: "orders"}},
+			"unit_tests":   {{Component: "payments-api"}, {Component: ""}},
+			"empty_column": nil,
+		},
+	}
+
+	assert.Equal(t, []string{"orders", "payments-api"}, toDomainSlip(slip).Components)
+	assert.Nil(t, toDomainSlip(&slippy.Slip{}).Components)
+}
+
+func TestToDomainSlip_Steps(t *testing.T) {
+	slip := &slippy.Slip{
+		CorrelationID: "slip-1",
+		Steps: map[string]slippy.Step{
+			"push_parsed": {Status: slippy.StepStatusCompleted},
+			"builds":      {Status: slippy.StepStatusRunning},
+		},
+	}
+
+	assert.Equal(t, map[string]string{
+		"push_parsed": "completed",
+		"builds":      "running",
+	}, toDomainSlip(slip).Steps)
+	assert.Nil(t, toDomainSlip(&slippy.Slip{}).Steps)
+}
+
+func TestClickHouseAdapter_FindByID(t *testing.T) {
+	tests := []struct {
+		name       string
+		store      *mockSlipStore
+		wantID     string
+		wantErrIs  error
+		wantErrMsg string
+	}{
+		{
+			name:   "found",
+			store:  &mockSlipStore{loadSlip: &slippy.Slip{CorrelationID: "slip-7", Repository: "org/repo"}},
+			wantID: "slip-7",
+		},
+		{
+			name:      "not found",
+			store:     &mockSlipStore{loadErr: slippy.ErrSlipNotFound},
+			wantErrIs: domain.ErrSlipNotFound,
+		},
+		{
+			name:       "load error",
+			store:      &mockSlipStore{loadErr: errors.New("connection reset")},
+			wantErrMsg: "failed to load slip slip-7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := NewClickHouseAdapter(tt.store)
+
+			slip, err := adapter.FindByID(context.Background(), "slip-7")
+
+			assert.Equal(t, "slip-7", tt.store.loadedID)
+			if tt.wantErrIs != nil || tt.wantErrMsg != "" {
+				require.Error(t, err)
+				assert.Nil(t, slip)
+				if tt.wantErrIs != nil {
+					assert.ErrorIs(t, err, tt.wantErrIs)
+				}
+				assert.Contains(t, err.Error(), tt.wantErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantID, slip.CorrelationID)
+		})
+	}
+}
+
+func TestClickHouseAdapter_AppendHistory(t *testing.T) {
+	at := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	mockStore := &mockSlipStore{}
+	adapter := NewClickHouseAdapter(mockStore)
+
+	err := adapter.AppendHistory(context.Background(), "slip-1", domain.HistoryEntry{
+		Step:      "resolve",
+		Actor:     "slippy-find",
+		Message:   "resolved by slippy-find",
+		Timestamp: at,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "slip-1", mockStore.historyID)
+	assert.Equal(t, slippy.StateHistoryEntry{
+		Step:      "resolve",
+		Actor:     "slippy-find",
+		Message:   "resolved by slippy-find",
+		Timestamp: at,
+	}, mockStore.historyEntry)
+}
+
+func TestClickHouseAdapter_AppendHistory_Error(t *testing.T) {
+	adapter := NewClickHouseAdapter(&mockSlipStore{historyErr: errors.New("write failed")})
+
+	err := adapter.AppendHistory(context.Background(), "slip-1", domain.HistoryEntry{})
+
+	require.Error(t, err)
+	assert.Equal(t, "write failed", err.Error())
+}
@@ -0,0 +1,246 @@
+//go:build !js
+
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// ExecProtocolVersion is the version of the finder plugin protocol sent in
+// every request. It changes only when the protocol changes incompatibly.
+const ExecProtocolVersion = 1
+
+// Finder plugin methods.
+const (
+	// ExecMethodFindByCommits asks for every slip of the repository created for one of the commits.
+	ExecMethodFindByCommits = "find_by_commits"
+
+	// ExecMethodFindLatestByBranch asks for the newest slip of the repository's branch.
+	ExecMethodFindLatestByBranch = "find_latest_by_branch"
+
+	// ExecMethodFindByID asks for the slip with the correlation ID.
+	ExecMethodFindByID = "find_by_id"
+)
+
+// execTimeout bounds one plugin call.
+const execTimeout = 30 * time.Second
+
+// maxExecStderr caps how much of a failed plugin's stderr is put in the error.
+const maxExecStderr = 4 << 10
+
+// ErrPluginUnsupported indicates the finder plugin does not implement a method.
+var ErrPluginUnsupported = errors.New("finder plugin does not support this lookup")
+
+// ExecFinder is a domain.SlipFinder backed by an external executable, so teams
+// can search proprietary slip stores without changes to slippy-find.
+//
+// Each lookup runs the executable once, writes one JSON request to its stdin,
+// and reads one JSON response from its stdout:
+//
+//	{"version":1,"method":"find_by_commits","repository":"org/repo","commits":["<sha>",...]}
+//	{"version":1,"method":"find_latest_by_branch","repository":"org/repo","branch":"main"}
+//	{"version":1,"method":"find_by_id","correlation_id":"<id>"}
+//
+//	{"slips":[{"correlation_id":"...","repository":"org/repo","branch":"main",
+//	  "commit_sha":"<sha>","created_at":"2026-01-02T15:04:05Z",
+//	  "components":["web"],"steps":{"build":"completed"}}]}
+//
+// An empty slips list means nothing matched. A plugin that does not implement
+// a method responds {"unsupported":true}; one that fails responds
+// {"error":"message"} or exits non-zero.
+type ExecFinder struct {
+	path string
+}
+
+// NewExecFinder creates a finder that runs the executable at path.
+func NewExecFinder(path string) *ExecFinder {
+	return &ExecFinder{path: path}
+}
+
+// execRequest is the request a plugin reads from stdin.
+type execRequest struct {
+	Version       int      `json:"version"`
+	Method        string   `json:"method"`
+	Repository    string   `json:"repository,omitempty"`
+	Branch        string   `json:"branch,omitempty"`
+	Commits       []string `json:"commits,omitempty"`
+	CorrelationID string   `json:"correlation_id,omitempty"`
+}
+
+// execResponse is the response a plugin writes to stdout.
+type execResponse struct {
+	Slips       []execSlip `json:"slips"`
+	Unsupported bool       `json:"unsupported"`
+	Error       string     `json:"error"`
+}
+
+// execSlip is a slip as a plugin reports it.
+type execSlip struct {
+	CorrelationID string            `json:"correlation_id"`
+	Repository    string            `json:"repository"`
+	Branch        string            `json:"branch"`
+	CommitSHA     string            `json:"commit_sha"`
+	CreatedAt     time.Time         `json:"created_at"`
+	Components    []string          `json:"components"`
+	Steps         map[string]string `json:"steps"`
+}
+
+// toDomain converts the reported slip, sorting its components as the domain expects.
+func (s execSlip) toDomain() *domain.Slip {
+	components := slices.Clone(s.Components)
+	slices.Sort(components)
+	return &domain.Slip{
+		CorrelationID: s.CorrelationID,
+		Repository:    s.Repository,
+		Branch:        s.Branch,
+		CommitSHA:     s.CommitSHA,
+		CreatedAt:     s.CreatedAt,
+		Components:    components,
+		Steps:         s.Steps,
+	}
+}
+
+// FindByCommits returns the slip for the earliest of commits that has one,
+// choosing by Slip.Precedes among several slips of that commit.
+// Returns (nil, "", nil) if no commit has a slip.
+func (f *ExecFinder) FindByCommits(ctx context.Context, repository string, commits []string) (*domain.Slip, string, error) {
+	matches, err := f.FindAllByCommits(ctx, repository, commits)
+	if err != nil || len(matches) == 0 {
+		return nil, "", err
+	}
+	return matches[0].Slip, matches[0].MatchedCommit, nil
+}
+
+// FindAllByCommits returns every slip the plugin reports for commits, ordered
+// by commit priority (the earliest commit in the list first), then by
+// Slip.Precedes. Slips for commits that were not asked about are ignored.
+func (f *ExecFinder) FindAllByCommits(
+	ctx context.Context,
+	repository string,
+	commits []string,
+) ([]domain.SlipMatch, error) {
+	resp, err := f.call(ctx, execRequest{
+		Method:     ExecMethodFindByCommits,
+		Repository: repository,
+		Commits:    commits,
+	})
+	if err != nil {
+		return nil, err
+	}
+	priority := make(map[string]int, len(commits))
+	for i, c := range commits {
+		if _, ok := priority[c]; !ok {
+			priority[c] = i
+		}
+	}
+	matches := make([]domain.SlipMatch, 0, len(resp.Slips))
+	for _, s := range resp.Slips {
+		if _, ok := priority[s.CommitSHA]; ok {
+			matches = append(matches, domain.SlipMatch{Slip: s.toDomain(), MatchedCommit: s.CommitSHA})
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b domain.SlipMatch) int {
+		if d := priority[a.MatchedCommit] - priority[b.MatchedCommit]; d != 0 {
+			return d
+		}
+		if a.Slip.Precedes(b.Slip) {
+			return -1
+		}
+		if b.Slip.Precedes(a.Slip) {
+			return 1
+		}
+		return 0
+	})
+	return matches, nil
+}
+
+// FindLatestByBranch returns the slip the plugin reports as the branch's newest.
+// Returns domain.ErrSlipNotFound if it reports none.
+func (f *ExecFinder) FindLatestByBranch(ctx context.Context, repository, branch string) (*domain.Slip, error) {
+	resp, err := f.call(ctx, execRequest{
+		Method:     ExecMethodFindLatestByBranch,
+		Repository: repository,
+		Branch:     branch,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Slips) == 0 {
+		return nil, fmt.Errorf("%w: %s@%s", domain.ErrSlipNotFound, repository, branch)
+	}
+	return resp.Slips[0].toDomain(), nil
+}
+
+// FindByID returns the slip with correlationID.
+// Returns domain.ErrSlipNotFound if the plugin reports none.
+func (f *ExecFinder) FindByID(ctx context.Context, correlationID string) (*domain.Slip, error) {
+	resp, err := f.call(ctx, execRequest{
+		Method:        ExecMethodFindByID,
+		CorrelationID: correlationID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Slips) == 0 {
+		return nil, fmt.Errorf("%w: %s", domain.ErrSlipNotFound, correlationID)
+	}
+	return resp.Slips[0].toDomain(), nil
+}
+
+// DescribeEnvironment implements domain.EnvironmentDescriber.
+func (f *ExecFinder) DescribeEnvironment(env *domain.Environment) {
+	env.Store = domain.StoreExec
+}
+
+// Close does nothing: the plugin only runs for the duration of a lookup.
+func (f *ExecFinder) Close() error {
+	return nil
+}
+
+// call runs the plugin with req and decodes its response.
+func (f *ExecFinder) call(ctx context.Context, req execRequest) (*execResponse, error) {
+	req.Version = ExecProtocolVersion
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s request: %w", req.Method, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, execTimeout)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, f.path)
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if len(msg) > maxExecStderr {
+			msg = msg[:maxExecStderr]
+		}
+		if msg != "" {
+			return nil, fmt.Errorf("finder plugin %s failed on %s: %w: %s", f.path, req.Method, err, msg)
+		}
+		return nil, fmt.Errorf("finder plugin %s failed on %s: %w", f.path, req.Method, err)
+	}
+
+	var resp execResponse
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return nil, fmt.Errorf("finder plugin %s returned an invalid %s response: %w", f.path, req.Method, err)
+	}
+	switch {
+	case resp.Unsupported:
+		return nil, fmt.Errorf("%w: %s", ErrPluginUnsupported, req.Method)
+	case resp.Error != "":
+		return nil, fmt.Errorf("finder plugin %s failed on %s: %s", f.path, req.Method, resp.Error)
+	}
+	return &resp, nil
+}
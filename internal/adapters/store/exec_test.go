@@ -0,0 +1,317 @@
+//go:build !js
+
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/slippyfindtest"
+)
+
+// memoryPluginEnv names the file of slips the test binary serves when it runs
+// as a finder plugin.
+const memoryPluginEnv = "SLIPPY_FIND_TEST_MEMORY_PLUGIN"
+
+// TestMain lets the test binary stand in for a finder plugin backed by
+// slippyfindtest.MemoryFinder, so ExecFinder can run the conformance suite.
+func TestMain(m *testing.M) {
+	if path := os.Getenv(memoryPluginEnv); path != "" {
+		if err := serveMemoryPlugin(path); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// serveMemoryPlugin answers one plugin request from stdin with the slips
+// stored at path.
+func serveMemoryPlugin(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var stored []execSlip
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return err
+	}
+	slips := make([]*domain.Slip, 0, len(stored))
+	for _, s := range stored {
+		slips = append(slips, s.toDomain())
+	}
+	finder := slippyfindtest.NewMemoryFinder(slips...)
+
+	var req execRequest
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		return err
+	}
+	ctx := context.Background()
+	var found []*domain.Slip
+	switch req.Method {
+	case ExecMethodFindByCommits:
+		matches, err := finder.FindAllByCommits(ctx, req.Repository, req.Commits)
+		if err != nil {
+			return err
+		}
+		for _, m := range matches {
+			found = append(found, m.Slip)
+		}
+	case ExecMethodFindLatestByBranch, ExecMethodFindByID:
+		var slip *domain.Slip
+		if req.Method == ExecMethodFindByID {
+			slip, err = finder.FindByID(ctx, req.CorrelationID)
+		} else {
+			slip, err = finder.FindLatestByBranch(ctx, req.Repository, req.Branch)
+		}
+		if err != nil && !errors.Is(err, domain.ErrSlipNotFound) {
+			return err
+		}
+		if slip != nil {
+			found = append(found, slip)
+		}
+	default:
+		return json.NewEncoder(os.Stdout).Encode(execResponse{Unsupported: true})
+	}
+
+	resp := execResponse{Slips: []execSlip{}}
+	for _, s := range found {
+		resp.Slips = append(resp.Slips, execSlip{
+			CorrelationID: s.CorrelationID,
+			Repository:    s.Repository,
+			Branch:        s.Branch,
+			CommitSHA:     s.CommitSHA,
+			CreatedAt:     s.CreatedAt,
+			Components:    s.Components,
+			Steps:         s.Steps,
+		})
+	}
+	return json.NewEncoder(os.Stdout).Encode(resp)
+}
+
+// fakePlugin writes a finder plugin stand-in that saves its request, prints
+// response, writes stderr, and exits with code. Returns its path and the
+// path of the saved request.
+func fakePlugin(t *testing.T, response, stderr string, code int) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	requestPath := filepath.Join(dir, "request.json")
+	responsePath := filepath.Join(dir, "response.json")
+	require.NoError(t, os.WriteFile(responsePath, []byte(response), 0o600))
+	script := "#!/bin/sh\ncat > '" + requestPath + "'\ncat '" + responsePath + "'\n" +
+		"printf '%s' '" + stderr + "' >&2\nexit " + strconv.Itoa(code) + "\n"
+	pluginPath := filepath.Join(dir, "finder")
+	require.NoError(t, os.WriteFile(pluginPath, []byte(script), 0o755))
+	return pluginPath, requestPath
+}
+
+// readRequest decodes the request a fake plugin saved.
+func readRequest(t *testing.T, path string) execRequest {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var req execRequest
+	require.NoError(t, json.Unmarshal(data, &req))
+	return req
+}
+
+func TestExecFinder_FindAllByCommits(t *testing.T) {
+	pluginPath, requestPath := fakePlugin(t, `{"slips":[
+		{"correlation_id":"old","commit_sha":"c2","created_at":"2026-01-01T00:00:00Z"},
+		{"correlation_id":"other","commit_sha":"c9"},
+		{"correlation_id":"new","commit_sha":"c2","created_at":"2026-01-02T00:00:00Z","components":["web","api"]},
+		{"correlation_id":"head","commit_sha":"c1","steps":{"build":"completed"}}
+	]}`, "", 0)
+	finder := NewExecFinder(pluginPath)
+
+	matches, err := finder.FindAllByCommits(context.Background(), "org/repo", []string{"c1", "c2"})
+
+	require.NoError(t, err)
+	assert.Equal(t, execRequest{
+		Version:    ExecProtocolVersion,
+		Method:     ExecMethodFindByCommits,
+		Repository: "org/repo",
+		Commits:    []string{"c1", "c2"},
+	}, readRequest(t, requestPath))
+	require.Len(t, matches, 3)
+	assert.Equal(t, "head", matches[0].Slip.CorrelationID)
+	assert.Equal(t, map[string]string{"build": "completed"}, matches[0].Slip.Steps)
+	assert.Equal(t, "new", matches[1].Slip.CorrelationID)
+	assert.Equal(t, []string{"api", "web"}, matches[1].Slip.Components)
+	assert.Equal(t, time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), matches[1].Slip.CreatedAt)
+	assert.Equal(t, "old", matches[2].Slip.CorrelationID)
+	assert.Equal(t, "c2", matches[2].MatchedCommit)
+}
+
+func TestExecFinder_FindByCommits(t *testing.T) {
+	tests := []struct {
+		name       string
+		response   string
+		wantID     string
+		wantCommit string
+	}{
+		{
+			name:       "nearest commit wins",
+			response:   `{"slips":[{"correlation_id":"far","commit_sha":"c2"},{"correlation_id":"near","commit_sha":"c1"}]}`,
+			wantID:     "near",
+			wantCommit: "c1",
+		},
+		{name: "no slips", response: `{"slips":[]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pluginPath, _ := fakePlugin(t, tt.response, "", 0)
+
+			slip, commit, err := NewExecFinder(pluginPath).FindByCommits(
+				context.Background(), "org/repo", []string{"c1", "c2"})
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCommit, commit)
+			if tt.wantID == "" {
+				assert.Nil(t, slip)
+				return
+			}
+			assert.Equal(t, tt.wantID, slip.CorrelationID)
+		})
+	}
+}
+
+func TestExecFinder_FindLatestByBranch(t *testing.T) {
+	pluginPath, requestPath := fakePlugin(t, `{"slips":[{"correlation_id":"slip-1","branch":"main"}]}`, "", 0)
+
+	slip, err := NewExecFinder(pluginPath).FindLatestByBranch(context.Background(), "org/repo", "main")
+
+	require.NoError(t, err)
+	assert.Equal(t, "slip-1", slip.CorrelationID)
+	assert.Equal(t, execRequest{
+		Version:    ExecProtocolVersion,
+		Method:     ExecMethodFindLatestByBranch,
+		Repository: "org/repo",
+		Branch:     "main",
+	}, readRequest(t, requestPath))
+}
+
+func TestExecFinder_FindByID(t *testing.T) {
+	pluginPath, requestPath := fakePlugin(t, `{"slips":[{"correlation_id":"slip-1"}]}`, "", 0)
+
+	slip, err := NewExecFinder(pluginPath).FindByID(context.Background(), "slip-1")
+
+	require.NoError(t, err)
+	assert.Equal(t, "slip-1", slip.CorrelationID)
+	assert.Equal(t, execRequest{
+		Version:       ExecProtocolVersion,
+		Method:        ExecMethodFindByID,
+		CorrelationID: "slip-1",
+	}, readRequest(t, requestPath))
+}
+
+func TestExecFinder_NotFound(t *testing.T) {
+	pluginPath, _ := fakePlugin(t, `{"slips":[]}`, "", 0)
+	finder := NewExecFinder(pluginPath)
+
+	_, err := finder.FindByID(context.Background(), "slip-1")
+	assert.ErrorIs(t, err, domain.ErrSlipNotFound)
+
+	_, err = finder.FindLatestByBranch(context.Background(), "org/repo", "main")
+	assert.ErrorIs(t, err, domain.ErrSlipNotFound)
+}
+
+func TestExecFinder_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		stderr   string
+		code     int
+		wantErr  error
+		wantMsg  string
+	}{
+		{
+			name:     "unsupported",
+			response: `{"unsupported":true}`,
+			wantErr:  ErrPluginUnsupported,
+			wantMsg:  "find_by_id",
+		},
+		{
+			name:     "error response",
+			response: `{"error":"store unavailable"}`,
+			wantMsg:  "failed on find_by_id: store unavailable",
+		},
+		{
+			name:    "non-zero exit",
+			stderr:  "connection refused",
+			code:    2,
+			wantMsg: "failed on find_by_id: exit status 2: connection refused",
+		},
+		{
+			name:     "invalid response",
+			response: `not json`,
+			wantMsg:  "returned an invalid find_by_id response",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pluginPath, _ := fakePlugin(t, tt.response, tt.stderr, tt.code)
+
+			_, err := NewExecFinder(pluginPath).FindByID(context.Background(), "slip-1")
+
+			require.Error(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+			assert.Contains(t, err.Error(), tt.wantMsg)
+		})
+	}
+}
+
+func TestExecFinder_MissingExecutable(t *testing.T) {
+	finder := NewExecFinder(filepath.Join(t.TempDir(), "missing"))
+
+	_, _, err := finder.FindByCommits(context.Background(), "org/repo", []string{"c1"})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed on find_by_commits")
+	assert.NoError(t, finder.Close())
+}
+
+func TestExecFinder_Conformance(t *testing.T) {
+	binary, err := os.Executable()
+	require.NoError(t, err)
+
+	slippyfindtest.TestSlipFinder(t, func(t *testing.T, slips []*domain.Slip) domain.SlipFinder {
+		stored := make([]execSlip, 0, len(slips))
+		for _, s := range slips {
+			stored = append(stored, execSlip{
+				CorrelationID: s.CorrelationID,
+				Repository:    s.Repository,
+				Branch:        s.Branch,
+				CommitSHA:     s.CommitSHA,
+				CreatedAt:     s.CreatedAt,
+				Components:    s.Components,
+				Steps:         s.Steps,
+			})
+		}
+		data, err := json.Marshal(stored)
+		require.NoError(t, err)
+		dir := t.TempDir()
+		slipsPath := filepath.Join(dir, "slips.json")
+		require.NoError(t, os.WriteFile(slipsPath, data, 0o600))
+		script := "#!/bin/sh\nexec env " + memoryPluginEnv + "='" + slipsPath + "' '" + binary + "'\n"
+		pluginPath := filepath.Join(dir, "finder")
+		require.NoError(t, os.WriteFile(pluginPath, []byte(script), 0o755))
+		return NewExecFinder(pluginPath)
+	})
+}
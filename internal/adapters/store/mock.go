@@ -0,0 +1,85 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// mockNamespace derives mock correlation IDs, so they cannot collide with
+// the random IDs of real slips.
+var mockNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://github.com/MyCarrier-DevOps/slippy-find/mock"))
+
+// MockFinder is a domain.SlipFinder that needs no store: every search
+// matches the first commit searched, which is HEAD for a plain resolution,
+// with a slip whose correlation ID is derived from the repository and that
+// commit. Pipeline authors use it to dry-run their wiring in sandboxes.
+//
+// Mock slips have no components and no steps, and IDs cannot be looked up,
+// so hints and manual overrides are reported as not found.
+type MockFinder struct {
+	now func() time.Time
+}
+
+// NewMockFinder creates a MockFinder.
+func NewMockFinder() *MockFinder {
+	return &MockFinder{now: time.Now}
+}
+
+// MockCorrelationID returns the correlation ID MockFinder reports for the
+// slip of commit in repository. The repository is matched case-insensitively.
+func MockCorrelationID(repository, commit string) string {
+	return uuid.NewSHA1(mockNamespace, []byte(strings.ToLower(repository)+"@"+commit)).String()
+}
+
+// FindByCommits returns the mock slip of the first commit.
+// Returns (nil, "", nil) if commits is empty.
+func (f *MockFinder) FindByCommits(ctx context.Context, repository string, commits []string) (*domain.Slip, string, error) {
+	matches, err := f.FindAllByCommits(ctx, repository, commits)
+	if err != nil || len(matches) == 0 {
+		return nil, "", err
+	}
+	return matches[0].Slip, matches[0].MatchedCommit, nil
+}
+
+// FindAllByCommits returns the mock slip of the first commit, created now so
+// age limits accept it.
+func (f *MockFinder) FindAllByCommits(_ context.Context, repository string, commits []string) ([]domain.SlipMatch, error) {
+	if len(commits) == 0 {
+		return []domain.SlipMatch{}, nil
+	}
+	slip := &domain.Slip{
+		CorrelationID: MockCorrelationID(repository, commits[0]),
+		Repository:    repository,
+		CommitSHA:     commits[0],
+		CreatedAt:     f.now().UTC(),
+		Mock:          true,
+	}
+	return []domain.SlipMatch{{Slip: slip, MatchedCommit: commits[0]}}, nil
+}
+
+// FindLatestByBranch reports that the branch has no slips: every search
+// already matches its first commit, so fallbacks never need one.
+func (f *MockFinder) FindLatestByBranch(_ context.Context, repository, branch string) (*domain.Slip, error) {
+	return nil, fmt.Errorf("%w: %s@%s", domain.ErrSlipNotFound, repository, branch)
+}
+
+// FindByID reports that the slip does not exist.
+func (f *MockFinder) FindByID(_ context.Context, correlationID string) (*domain.Slip, error) {
+	return nil, fmt.Errorf("%w: %s", domain.ErrSlipNotFound, correlationID)
+}
+
+// DescribeEnvironment implements domain.EnvironmentDescriber.
+func (f *MockFinder) DescribeEnvironment(env *domain.Environment) {
+	env.Store = domain.StoreMock
+}
+
+// Close does nothing.
+func (f *MockFinder) Close() error {
+	return nil
+}
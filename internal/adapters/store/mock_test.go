@@ -0,0 +1,74 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+func TestMockCorrelationID(t *testing.T) {
+	id := MockCorrelationID("Org/Repo", "c1")
+
+	parsed, err := uuid.Parse(id)
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Version(5), parsed.Version())
+	assert.Equal(t, id, MockCorrelationID("org/repo", "c1"), "repository is case-insensitive")
+	assert.NotEqual(t, id, MockCorrelationID("org/repo", "c2"))
+	assert.NotEqual(t, id, MockCorrelationID("org/other", "c1"))
+}
+
+func TestMockFinder_FindAllByCommits(t *testing.T) {
+	now := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	finder := NewMockFinder()
+	finder.now = func() time.Time { return now }
+
+	matches, err := finder.FindAllByCommits(context.Background(), "org/repo", []string{"c1", "c2"})
+
+	require.NoError(t, err)
+	assert.Equal(t, []domain.SlipMatch{{
+		Slip: &domain.Slip{
+			CorrelationID: MockCorrelationID("org/repo", "c1"),
+			Repository:    "org/repo",
+			CommitSHA:     "c1",
+			CreatedAt:     now,
+			Mock:          true,
+		},
+		MatchedCommit: "c1",
+	}}, matches)
+
+	slip, matched, err := finder.FindByCommits(context.Background(), "org/repo", []string{"c1", "c2"})
+	require.NoError(t, err)
+	assert.Equal(t, "c1", matched)
+	assert.Equal(t, MockCorrelationID("org/repo", "c1"), slip.CorrelationID)
+}
+
+func TestMockFinder_NoCommits(t *testing.T) {
+	finder := NewMockFinder()
+
+	matches, err := finder.FindAllByCommits(context.Background(), "org/repo", nil)
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+
+	slip, matched, err := finder.FindByCommits(context.Background(), "org/repo", nil)
+	require.NoError(t, err)
+	assert.Nil(t, slip)
+	assert.Empty(t, matched)
+}
+
+func TestMockFinder_NotFound(t *testing.T) {
+	finder := NewMockFinder()
+
+	_, err := finder.FindLatestByBranch(context.Background(), "org/repo", "main")
+	assert.ErrorIs(t, err, domain.ErrSlipNotFound)
+
+	_, err = finder.FindByID(context.Background(), MockCorrelationID("org/repo", "c1"))
+	assert.ErrorIs(t, err, domain.ErrSlipNotFound)
+
+	assert.NoError(t, finder.Close())
+}
@@ -0,0 +1,149 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/ext"
+	ch "github.com/MyCarrier-DevOps/goLibMyCarrier/clickhouse"
+)
+
+// CommitList is how the ClickHouse adapter passes a commit set to the queries
+// it builds itself (see FindNearestByCommits). Queries run by goLibMyCarrier's
+// SlipStore keep their own shape.
+type CommitList string
+
+// Commit list strategies.
+const (
+	// CommitListArray binds the commits as one Array(String) parameter. It is
+	// the default.
+	CommitListArray CommitList = "array"
+
+	// CommitListIn binds each commit as its own String parameter of an IN
+	// list, for servers that plan IN lists better than array joins.
+	CommitListIn CommitList = "in"
+
+	// CommitListTemporaryTable sends the commits with the query as a
+	// temporary table (ClickHouse external data), joined against the slips,
+	// which keeps very large commit sets out of the query text.
+	CommitListTemporaryTable CommitList = "temporary-table"
+)
+
+// commitsTable names the temporary table of CommitListTemporaryTable.
+const commitsTable = "slippy_commits"
+
+// ClickHouseOption tunes the queries of a ClickHouseAdapter.
+type ClickHouseOption func(*ClickHouseAdapter)
+
+// WithChunkSize sets the commits the resolver sends in each chunked query
+// (see domain.ChunkSizer). Zero keeps the resolver's default.
+func WithChunkSize(n int) ClickHouseOption {
+	return func(a *ClickHouseAdapter) {
+		a.chunkSize = n
+	}
+}
+
+// WithMaxQueryCommits caps the commits bound into one query. Larger commit
+// sets are queried in consecutive batches, nearest first, stopping at the
+// first batch with a match. Zero means no cap.
+func WithMaxQueryCommits(n int) ClickHouseOption {
+	return func(a *ClickHouseAdapter) {
+		a.maxQueryCommits = n
+	}
+}
+
+// WithCommitList sets how commits are passed to the adapter's own queries.
+// The zero value means CommitListArray.
+func WithCommitList(list CommitList) ClickHouseOption {
+	return func(a *ClickHouseAdapter) {
+		a.commitList = list
+	}
+}
+
+// ChunkSize implements domain.ChunkSizer.
+func (a *ClickHouseAdapter) ChunkSize() int {
+	return a.chunkSize
+}
+
+// batches splits commits into consecutive batches of at most the adapter's
+// maxQueryCommits. Without a cap, commits are one batch, even when empty.
+func (a *ClickHouseAdapter) batches(commits []string) [][]string {
+	if a.maxQueryCommits <= 0 || len(commits) <= a.maxQueryCommits {
+		return [][]string{commits}
+	}
+	var batches [][]string
+	for start := 0; start < len(commits); start += a.maxQueryCommits {
+		batches = append(batches, commits[start:min(start+a.maxQueryCommits, len(commits))])
+	}
+	return batches
+}
+
+// nearestByCommitsIn is nearestByCommitsQuery with the commits as an IN
+// list; both %s are replaced by the same list of parameters.
+const nearestByCommitsIn = `
+		SELECT s.commit_sha, groupUniqArray(s.correlation_id)
+		FROM %s.routing_slips s
+		WHERE lower(s.repository) = lower({repository:String})
+		  AND s.sign = 1
+		  AND s.commit_sha IN (%s)
+		GROUP BY s.commit_sha
+		ORDER BY indexOf([%s], s.commit_sha) ASC
+		LIMIT 1
+	`
+
+// nearestByCommitsTable is nearestByCommitsQuery with the commits in the
+// temporary table commitsTable.
+const nearestByCommitsTable = `
+		SELECT c.commit_sha, groupUniqArray(s.correlation_id)
+		FROM %s.routing_slips s
+		INNER JOIN ` + commitsTable + ` c ON s.commit_sha = c.commit_sha
+		WHERE lower(s.repository) = lower({repository:String})
+		  AND s.sign = 1
+		GROUP BY c.priority, c.commit_sha
+		ORDER BY c.priority ASC
+		LIMIT 1
+	`
+
+// nearestQuery builds the query for the nearest of commits with a slip, in
+// the adapter's CommitList shape. The returned context carries the temporary
+// table, if any.
+func (a *ClickHouseAdapter) nearestQuery(
+	ctx context.Context,
+	repository string,
+	commits []string,
+) (context.Context, string, []interface{}, error) {
+	switch a.commitList {
+	case CommitListIn:
+		args := make([]interface{}, 0, len(commits)+1)
+		args = append(args, ch.Named("repository", repository))
+		params := make([]string, len(commits))
+		for i, commit := range commits {
+			name := fmt.Sprintf("c%d", i)
+			params[i] = "{" + name + ":String}"
+			args = append(args, ch.Named(name, commit))
+		}
+		list := strings.Join(params, ", ")
+		return ctx, fmt.Sprintf(nearestByCommitsIn, a.database, list, list), args, nil
+	case CommitListTemporaryTable:
+		table, err := ext.NewTable(commitsTable, ext.Column("priority", "UInt32"), ext.Column("commit_sha", "String"))
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("failed to create commits table: %w", err)
+		}
+		for i, commit := range commits {
+			if err := table.Append(uint32(i+1), commit); err != nil {
+				return nil, "", nil, fmt.Errorf("failed to fill commits table: %w", err)
+			}
+		}
+		ctx = clickhouse.Context(ctx, clickhouse.WithExternalTable(table))
+		return ctx, fmt.Sprintf(nearestByCommitsTable, a.database), []interface{}{
+			ch.Named("repository", repository),
+		}, nil
+	default:
+		return ctx, fmt.Sprintf(nearestByCommitsQuery, a.database), []interface{}{
+			ch.Named("repository", repository),
+			ch.Named("commits", commits),
+		}, nil
+	}
+}
@@ -0,0 +1,175 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"testing"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	ch "github.com/MyCarrier-DevOps/goLibMyCarrier/clickhouse"
+	"github.com/MyCarrier-DevOps/goLibMyCarrier/slippy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// batchStore is a mockSlipStore recording the commit batches it is queried
+// with, and finding slips only for the commits in found.
+type batchStore struct {
+	mockSlipStore
+	found   map[string]*slippy.Slip
+	batches [][]string
+}
+
+func (s *batchStore) FindByCommits(_ context.Context, _ string, commits []string) (*slippy.Slip, string, error) {
+	s.batches = append(s.batches, commits)
+	for _, commit := range commits {
+		if slip, ok := s.found[commit]; ok {
+			return slip, commit, nil
+		}
+	}
+	return nil, "", slippy.ErrSlipNotFound
+}
+
+func (s *batchStore) FindAllByCommits(_ context.Context, _ string, commits []string) ([]slippy.SlipWithCommit, error) {
+	s.batches = append(s.batches, commits)
+	var results []slippy.SlipWithCommit
+	for _, commit := range commits {
+		if slip, ok := s.found[commit]; ok {
+			results = append(results, slippy.SlipWithCommit{Slip: slip, MatchedCommit: commit})
+		}
+	}
+	return results, nil
+}
+
+// batchQuerier is a Querier whose nearest query finds a slip only when its
+// commits include commit, recording the commits of each query.
+type batchQuerier struct {
+	commit  string
+	batches [][]string
+}
+
+func (q *batchQuerier) QueryRow(_ context.Context, _ string, args ...interface{}) ch.Row {
+	commits, _ := args[1].(driver.NamedValue).Value.([]string)
+	q.batches = append(q.batches, commits)
+	if slices.Contains(commits, q.commit) {
+		return &mockRow{value: q.commit, ids: []string{"slip-1"}}
+	}
+	return &mockRow{err: sql.ErrNoRows}
+}
+
+func TestWithChunkSize(t *testing.T) {
+	var _ domain.ChunkSizer = (*ClickHouseAdapter)(nil)
+	assert.Zero(t, NewClickHouseAdapter(&mockSlipStore{}).ChunkSize())
+
+	adapter := NewClickHouseAdapterWithSession(&mockSlipStore{}, &mockQuerier{}, "ci", WithChunkSize(250))
+
+	assert.Equal(t, 250, adapter.ChunkSize())
+}
+
+func TestClickHouseAdapter_Batches(t *testing.T) {
+	commits := []string{"c0", "c1", "c2", "c3", "c4"}
+	tests := []struct {
+		name string
+		max  int
+		want [][]string
+	}{
+		{name: "no cap", want: [][]string{commits}},
+		{name: "fits", max: 5, want: [][]string{commits}},
+		{name: "split", max: 2, want: [][]string{{"c0", "c1"}, {"c2", "c3"}, {"c4"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := NewClickHouseAdapterWithSession(&mockSlipStore{}, nil, "ci", WithMaxQueryCommits(tt.max))
+
+			assert.Equal(t, tt.want, adapter.batches(commits))
+		})
+	}
+}
+
+func TestClickHouseAdapter_FindByCommits_Batched(t *testing.T) {
+	store := &batchStore{found: map[string]*slippy.Slip{"c3": {CorrelationID: "slip-3"}}}
+	adapter := NewClickHouseAdapterWithSession(store, nil, "ci", WithMaxQueryCommits(2))
+
+	slip, commit, err := adapter.FindByCommits(context.Background(), "org/repo", []string{"c0", "c1", "c2", "c3", "c4"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "slip-3", slip.CorrelationID)
+	assert.Equal(t, "c3", commit)
+	assert.Equal(t, [][]string{{"c0", "c1"}, {"c2", "c3"}}, store.batches, "stops at the first batch with a match")
+}
+
+func TestClickHouseAdapter_FindByCommits_BatchedNotFound(t *testing.T) {
+	store := &batchStore{}
+	adapter := NewClickHouseAdapterWithSession(store, nil, "ci", WithMaxQueryCommits(2))
+
+	slip, commit, err := adapter.FindByCommits(context.Background(), "org/repo", []string{"c0", "c1", "c2"})
+
+	require.NoError(t, err)
+	assert.Nil(t, slip)
+	assert.Empty(t, commit)
+	assert.Len(t, store.batches, 2)
+}
+
+func TestClickHouseAdapter_FindAllByCommits_Batched(t *testing.T) {
+	store := &batchStore{found: map[string]*slippy.Slip{
+		"c0": {CorrelationID: "slip-0"},
+		"c2": {CorrelationID: "slip-2"},
+	}}
+	adapter := NewClickHouseAdapterWithSession(store, nil, "ci", WithMaxQueryCommits(2))
+
+	matches, err := adapter.FindAllByCommits(context.Background(), "org/repo", []string{"c0", "c1", "c2"})
+
+	require.NoError(t, err)
+	require.Len(t, matches, 2)
+	assert.Equal(t, "c0", matches[0].MatchedCommit)
+	assert.Equal(t, "c2", matches[1].MatchedCommit)
+	assert.Len(t, store.batches, 2)
+}
+
+func TestClickHouseAdapter_FindNearestByCommits_Batched(t *testing.T) {
+	querier := &batchQuerier{commit: "c2"}
+	store := &mockSlipStore{loadSlip: &slippy.Slip{CorrelationID: "slip-1", CommitSHA: "c2"}}
+	adapter := NewClickHouseAdapterWithSession(store, querier, "ci", WithMaxQueryCommits(2))
+
+	matches, err := adapter.FindNearestByCommits(context.Background(), "org/repo", []string{"c0", "c1", "c2", "c3"})
+
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "c2", matches[0].MatchedCommit)
+	assert.Equal(t, [][]string{{"c0", "c1"}, {"c2", "c3"}}, querier.batches)
+}
+
+func TestClickHouseAdapter_FindNearestByCommits_InList(t *testing.T) {
+	querier := &mockQuerier{correlationID: "c1", ids: []string{"slip-1"}}
+	store := &mockSlipStore{loadSlip: &slippy.Slip{CorrelationID: "slip-1", CommitSHA: "c1"}}
+	adapter := NewClickHouseAdapterWithSession(store, querier, "ci", WithCommitList(CommitListIn))
+
+	matches, err := adapter.FindNearestByCommits(context.Background(), "org/repo", []string{"c0", "c1"})
+
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Contains(t, querier.query, "s.commit_sha IN ({c0:String}, {c1:String})")
+	assert.Contains(t, querier.query, "indexOf([{c0:String}, {c1:String}], s.commit_sha)")
+	assert.Equal(t, []interface{}{
+		ch.Named("repository", "org/repo"),
+		ch.Named("c0", "c0"),
+		ch.Named("c1", "c1"),
+	}, querier.args)
+}
+
+func TestClickHouseAdapter_NearestQuery_TemporaryTable(t *testing.T) {
+	adapter := NewClickHouseAdapterWithSession(&mockSlipStore{}, &mockQuerier{}, "ci",
+		WithCommitList(CommitListTemporaryTable))
+	ctx := context.Background()
+
+	queryCtx, query, args, err := adapter.nearestQuery(ctx, "org/repo", []string{"c0", "c1"})
+
+	require.NoError(t, err)
+	assert.NotEqual(t, ctx, queryCtx, "the context carries the commits table")
+	assert.Contains(t, query, "INNER JOIN "+commitsTable+" c ON s.commit_sha = c.commit_sha")
+	assert.Contains(t, query, "FROM ci.routing_slips s")
+	assert.Equal(t, []interface{}{ch.Named("repository", "org/repo")}, args)
+}
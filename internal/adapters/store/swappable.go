@@ -0,0 +1,150 @@
+package store
+
+import (
+	"context"
+	"sync"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// SwappableFinder is a domain.SlipFinder whose underlying finder can be replaced at runtime.
+// Long-running modes use it to apply reloaded configuration without dropping requests:
+// calls already in flight complete against the old finder, which is closed only after
+// they drain, while new calls go to the replacement immediately.
+type SwappableFinder struct {
+	mu      sync.RWMutex
+	current *finderLease
+}
+
+// finderLease tracks in-flight calls against a single finder generation.
+type finderLease struct {
+	finder   domain.SlipFinder
+	inflight sync.WaitGroup
+}
+
+// NewSwappableFinder creates a SwappableFinder that initially delegates to finder.
+func NewSwappableFinder(finder domain.SlipFinder) *SwappableFinder {
+	return &SwappableFinder{current: &finderLease{finder: finder}}
+}
+
+// FindByCommits delegates to the current finder.
+func (s *SwappableFinder) FindByCommits(
+	ctx context.Context,
+	repository string,
+	commits []string,
+) (*domain.Slip, string, error) {
+	lease := s.acquire()
+	defer lease.inflight.Done()
+	return lease.finder.FindByCommits(ctx, repository, commits)
+}
+
+// FindAllByCommits delegates to the current finder.
+func (s *SwappableFinder) FindAllByCommits(
+	ctx context.Context,
+	repository string,
+	commits []string,
+) ([]domain.SlipMatch, error) {
+	lease := s.acquire()
+	defer lease.inflight.Done()
+	return lease.finder.FindAllByCommits(ctx, repository, commits)
+}
+
+// FindLatestByBranch delegates to the current finder.
+func (s *SwappableFinder) FindLatestByBranch(
+	ctx context.Context,
+	repository, branch string,
+) (*domain.Slip, error) {
+	lease := s.acquire()
+	defer lease.inflight.Done()
+	return lease.finder.FindLatestByBranch(ctx, repository, branch)
+}
+
+// FindByID delegates to the current finder.
+func (s *SwappableFinder) FindByID(ctx context.Context, correlationID string) (*domain.Slip, error) {
+	lease := s.acquire()
+	defer lease.inflight.Done()
+	return lease.finder.FindByID(ctx, correlationID)
+}
+
+// FindNearestByCommits delegates to the current finder if it is a
+// domain.NearestSlipsFinder. Returns domain.ErrNearestUnsupported otherwise.
+func (s *SwappableFinder) FindNearestByCommits(
+	ctx context.Context,
+	repository string,
+	commits []string,
+) ([]domain.SlipMatch, error) {
+	lease := s.acquire()
+	defer lease.inflight.Done()
+	nearest, ok := lease.finder.(domain.NearestSlipsFinder)
+	if !ok {
+		return nil, domain.ErrNearestUnsupported
+	}
+	return nearest.FindNearestByCommits(ctx, repository, commits)
+}
+
+// AppendHistory delegates to the current finder if it is a domain.SlipAnnotator.
+// Returns domain.ErrAnnotationUnsupported otherwise.
+func (s *SwappableFinder) AppendHistory(ctx context.Context, correlationID string, entry domain.HistoryEntry) error {
+	lease := s.acquire()
+	defer lease.inflight.Done()
+	annotator, ok := lease.finder.(domain.SlipAnnotator)
+	if !ok {
+		return domain.ErrAnnotationUnsupported
+	}
+	return annotator.AppendHistory(ctx, correlationID, entry)
+}
+
+// Ping delegates to the current finder if it is a domain.StorePinger.
+// Returns domain.ErrPingUnsupported otherwise.
+func (s *SwappableFinder) Ping(ctx context.Context) error {
+	lease := s.acquire()
+	defer lease.inflight.Done()
+	pinger, ok := lease.finder.(domain.StorePinger)
+	if !ok {
+		return domain.ErrPingUnsupported
+	}
+	return pinger.Ping(ctx)
+}
+
+// ChunkSize delegates to the current finder if it is a domain.ChunkSizer.
+// Returns 0, the resolver's default, otherwise.
+func (s *SwappableFinder) ChunkSize() int {
+	lease := s.acquire()
+	defer lease.inflight.Done()
+	if sizer, ok := lease.finder.(domain.ChunkSizer); ok {
+		return sizer.ChunkSize()
+	}
+	return 0
+}
+
+// Swap installs next as the active finder. It blocks until calls in flight on the
+// previous finder have returned, then closes it and returns the close error.
+func (s *SwappableFinder) Swap(next domain.SlipFinder) error {
+	s.mu.Lock()
+	old := s.current
+	s.current = &finderLease{finder: next}
+	s.mu.Unlock()
+
+	old.inflight.Wait()
+	return old.finder.Close()
+}
+
+// Close closes the active finder.
+func (s *SwappableFinder) Close() error {
+	s.mu.Lock()
+	lease := s.current
+	s.mu.Unlock()
+
+	lease.inflight.Wait()
+	return lease.finder.Close()
+}
+
+// acquire returns the active lease with its in-flight counter incremented.
+// The increment happens under the read lock so Swap cannot observe a lease
+// as drained while a caller is about to use it.
+func (s *SwappableFinder) acquire() *finderLease {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	s.current.inflight.Add(1)
+	return s.current
+}
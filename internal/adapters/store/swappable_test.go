@@ -0,0 +1,216 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/slippyfindtest"
+)
+
+// stubFinder implements domain.SlipFinder for swap tests.
+type stubFinder struct {
+	id       string
+	release  chan struct{}
+	entered  chan struct{}
+	closeErr error
+
+	mu     sync.Mutex
+	closed bool
+}
+
+func (f *stubFinder) FindByCommits(_ context.Context, _ string, commits []string) (*domain.Slip, string, error) {
+	if f.entered != nil {
+		close(f.entered)
+	}
+	if f.release != nil {
+		<-f.release
+	}
+	return &domain.Slip{CorrelationID: f.id}, commits[0], nil
+}
+
+func (f *stubFinder) FindAllByCommits(_ context.Context, _ string, commits []string) ([]domain.SlipMatch, error) {
+	return []domain.SlipMatch{{Slip: &domain.Slip{CorrelationID: f.id}, MatchedCommit: commits[0]}}, nil
+}
+
+func (f *stubFinder) FindLatestByBranch(_ context.Context, _, _ string) (*domain.Slip, error) {
+	return &domain.Slip{CorrelationID: f.id}, nil
+}
+
+func (f *stubFinder) FindByID(_ context.Context, _ string) (*domain.Slip, error) {
+	return &domain.Slip{CorrelationID: f.id}, nil
+}
+
+func (f *stubFinder) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	return f.closeErr
+}
+
+func (f *stubFinder) isClosed() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.closed
+}
+
+func TestSwappableFinder_Conformance(t *testing.T) {
+	slippyfindtest.TestSlipFinder(t, func(_ *testing.T, slips []*domain.Slip) domain.SlipFinder {
+		return NewSwappableFinder(slippyfindtest.NewMemoryFinder(slips...))
+	})
+}
+
+func TestSwappableFinder_DelegatesToCurrent(t *testing.T) {
+	first := &stubFinder{id: "first"}
+	s := NewSwappableFinder(first)
+
+	slip, commit, err := s.FindByCommits(context.Background(), "org/repo", []string{"abc"})
+	require.NoError(t, err)
+	assert.Equal(t, "first", slip.CorrelationID)
+	assert.Equal(t, "abc", commit)
+
+	second := &stubFinder{id: "second"}
+	require.NoError(t, s.Swap(second))
+	assert.True(t, first.isClosed())
+
+	slip, _, err = s.FindByCommits(context.Background(), "org/repo", []string{"abc"})
+	require.NoError(t, err)
+	assert.Equal(t, "second", slip.CorrelationID)
+
+	matches, err := s.FindAllByCommits(context.Background(), "org/repo", []string{"abc"})
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "second", matches[0].Slip.CorrelationID)
+
+	latest, err := s.FindLatestByBranch(context.Background(), "org/repo", "main")
+	require.NoError(t, err)
+	assert.Equal(t, "second", latest.CorrelationID)
+
+	byID, err := s.FindByID(context.Background(), "slip-1")
+	require.NoError(t, err)
+	assert.Equal(t, "second", byID.CorrelationID)
+}
+
+func TestSwappableFinder_SwapWaitsForInFlight(t *testing.T) {
+	first := &stubFinder{id: "first", release: make(chan struct{}), entered: make(chan struct{})}
+	s := NewSwappableFinder(first)
+
+	done := make(chan string)
+	go func() {
+		slip, _, _ := s.FindByCommits(context.Background(), "org/repo", []string{"abc"})
+		done <- slip.CorrelationID
+	}()
+	<-first.entered
+
+	swapped := make(chan error)
+	go func() { swapped <- s.Swap(&stubFinder{id: "second"}) }()
+
+	select {
+	case <-swapped:
+		t.Fatal("swap returned before in-flight call drained")
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.False(t, first.isClosed())
+
+	close(first.release)
+	assert.Equal(t, "first", <-done)
+	require.NoError(t, <-swapped)
+	assert.True(t, first.isClosed())
+}
+
+func TestSwappableFinder_SwapReturnsCloseError(t *testing.T) {
+	s := NewSwappableFinder(&stubFinder{closeErr: errors.New("close failed")})
+
+	err := s.Swap(&stubFinder{})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "close failed")
+}
+
+func TestSwappableFinder_Close(t *testing.T) {
+	finder := &stubFinder{}
+	s := NewSwappableFinder(finder)
+
+	require.NoError(t, s.Close())
+	assert.True(t, finder.isClosed())
+}
+
+// annotatingFinder is a stubFinder that records appended history.
+type annotatingFinder struct {
+	stubFinder
+	entries []domain.HistoryEntry
+}
+
+func (f *annotatingFinder) AppendHistory(_ context.Context, _ string, entry domain.HistoryEntry) error {
+	f.entries = append(f.entries, entry)
+	return nil
+}
+
+func TestSwappableFinder_AppendHistory(t *testing.T) {
+	finder := &annotatingFinder{}
+	s := NewSwappableFinder(finder)
+
+	require.NoError(t, s.AppendHistory(context.Background(), "slip-1", domain.HistoryEntry{Step: "resolve"}))
+	assert.Equal(t, []domain.HistoryEntry{{Step: "resolve"}}, finder.entries)
+
+	require.NoError(t, s.Swap(&stubFinder{}))
+	err := s.AppendHistory(context.Background(), "slip-1", domain.HistoryEntry{Step: "resolve"})
+	assert.ErrorIs(t, err, domain.ErrAnnotationUnsupported)
+}
+
+// nearestFinder is a stubFinder that is also a domain.NearestSlipsFinder.
+type nearestFinder struct {
+	stubFinder
+	matches []domain.SlipMatch
+}
+
+func (f *nearestFinder) FindNearestByCommits(_ context.Context, _ string, _ []string) ([]domain.SlipMatch, error) {
+	return f.matches, nil
+}
+
+func TestSwappableFinder_FindNearestByCommits(t *testing.T) {
+	matches := []domain.SlipMatch{{Slip: &domain.Slip{CorrelationID: "slip-1"}, MatchedCommit: "c1"}}
+	s := NewSwappableFinder(&nearestFinder{matches: matches})
+
+	got, err := s.FindNearestByCommits(context.Background(), "org/repo", []string{"c1"})
+	require.NoError(t, err)
+	assert.Equal(t, matches, got)
+
+	require.NoError(t, s.Swap(&stubFinder{}))
+	_, err = s.FindNearestByCommits(context.Background(), "org/repo", []string{"c1"})
+	assert.ErrorIs(t, err, domain.ErrNearestUnsupported)
+}
+
+// pingingFinder is a stubFinder that answers Ping with err.
+type pingingFinder struct {
+	stubFinder
+	err error
+}
+
+func (f *pingingFinder) Ping(_ context.Context) error {
+	return f.err
+}
+
+func TestSwappableFinder_Ping(t *testing.T) {
+	s := NewSwappableFinder(&pingingFinder{err: errors.New("connection refused")})
+
+	assert.EqualError(t, s.Ping(context.Background()), "connection refused")
+
+	require.NoError(t, s.Swap(&stubFinder{}))
+	assert.ErrorIs(t, s.Ping(context.Background()), domain.ErrPingUnsupported)
+}
+
+func TestSwappableFinder_ChunkSize(t *testing.T) {
+	s := NewSwappableFinder(NewClickHouseAdapterWithSession(&mockSlipStore{}, nil, "ci", WithChunkSize(250)))
+
+	assert.Equal(t, 250, s.ChunkSize())
+
+	require.NoError(t, s.Swap(&stubFinder{}))
+	assert.Zero(t, s.ChunkSize())
+}
@@ -0,0 +1,268 @@
+// Package benchrepo generates synthetic Git repositories for benchmarking
+// history walks, without running git: objects are written straight to a
+// go-git storage, so repositories of 100k commits take seconds to build in
+// memory, and are written to disk as a single packfile.
+package benchrepo
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// Shape is the layout of a generated history.
+type Shape string
+
+const (
+	// Linear is a single chain of commits, each changing main.txt.
+	Linear Shape = "linear"
+
+	// Merged is a mainline changing main.txt that merges a two-commit side
+	// branch changing side.txt after every MergeEvery mainline commits.
+	Merged Shape = "merged"
+)
+
+// Shapes lists every Shape.
+var Shapes = []Shape{Linear, Merged}
+
+// MergeEvery is the number of mainline commits between merges in a Merged
+// history.
+const MergeEvery = 3
+
+// Repository is the remote name generated histories report as their origin.
+const Repository = "bench/repo"
+
+// epoch is the author time of the first commit; each commit is a second later.
+var epoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Generate writes a repository whose main branch has n first-parent commits
+// laid out as shape to st, typically a memory.Storage opened with git.Open.
+// HEAD is main and the origin remote points at Repository. Returns the HEAD
+// commit SHA.
+func Generate(st storage.Storer, shape Shape, n int) (string, error) {
+	if n < 1 {
+		return "", fmt.Errorf("benchrepo: need at least one commit, got %d", n)
+	}
+	if shape != Linear && shape != Merged {
+		return "", fmt.Errorf("benchrepo: unknown shape %q", shape)
+	}
+
+	if _, err := git.Init(st, nil); err != nil {
+		return "", fmt.Errorf("benchrepo: init: %w", err)
+	}
+	g := &generator{st: st}
+	head, err := g.history(shape, n)
+	if err != nil {
+		return "", err
+	}
+	if err := g.refs(head); err != nil {
+		return "", err
+	}
+	return head.String(), nil
+}
+
+// The number of versions of main.txt and side.txt. side.txt has three, so a
+// side branch changing it twice still changes it when merged.
+const (
+	mainVersions = 2
+	sideVersions = 3
+)
+
+// WriteFS generates a repository as Generate does and writes it to fs as a
+// bare Git directory, its objects in one packfile, that gitcontext.OpenFS or,
+// on disk, gitcontext.Open can read. Returns the HEAD commit SHA.
+func WriteFS(fs billy.Filesystem, shape Shape, n int) (string, error) {
+	mem := memory.NewStorage()
+	head, err := Generate(mem, shape, n)
+	if err != nil {
+		return "", err
+	}
+
+	st := filesystem.NewStorage(fs, cache.NewObjectLRUDefault())
+	if _, err := git.Init(st, nil); err != nil {
+		return "", fmt.Errorf("benchrepo: init: %w", err)
+	}
+	if err := writePack(mem, st); err != nil {
+		return "", err
+	}
+	g := &generator{st: st}
+	if err := g.refs(plumbing.NewHash(head)); err != nil {
+		return "", err
+	}
+	return head, nil
+}
+
+// writePack copies every object of from to to as one packfile, without deltas.
+func writePack(from *memory.Storage, to *filesystem.Storage) error {
+	var hashes []plumbing.Hash
+	for hash := range from.Objects {
+		hashes = append(hashes, hash)
+	}
+	w, err := to.PackfileWriter()
+	if err != nil {
+		return fmt.Errorf("benchrepo: pack: %w", err)
+	}
+	if _, err := packfile.NewEncoder(w, from, false).Encode(hashes, 0); err != nil {
+		_ = w.Close()
+		return fmt.Errorf("benchrepo: pack: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("benchrepo: pack: %w", err)
+	}
+	return nil
+}
+
+// generator writes the objects of one history.
+type generator struct {
+	st storage.Storer
+
+	// trees holds the trees a history uses, indexed by the versions of
+	// main.txt and side.txt they hold.
+	trees [mainVersions][sideVersions]plumbing.Hash
+
+	// commits counts the commits written, for their timestamps.
+	commits int
+}
+
+// history writes n mainline commits of shape and returns the last.
+func (g *generator) history(shape Shape, n int) (plumbing.Hash, error) {
+	if err := g.writeTrees(); err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	var (
+		head       plumbing.Hash
+		parents    []plumbing.Hash
+		mainV      int
+		sideV      int
+		sinceMerge int
+	)
+	for written := 0; written < n; written++ {
+		if shape == Merged && sinceMerge == MergeEvery {
+			// A side branch from head, merged back as the next mainline commit
+			side := head
+			for range 2 {
+				sideV = (sideV + 1) % sideVersions
+				var err error
+				if side, err = g.commit(g.trees[mainV][sideV], []plumbing.Hash{side}, "side"); err != nil {
+					return plumbing.ZeroHash, err
+				}
+			}
+			parents = []plumbing.Hash{head, side}
+			sinceMerge = 0
+		} else {
+			mainV = (mainV + 1) % mainVersions
+			sinceMerge++
+		}
+
+		var err error
+		if head, err = g.commit(g.trees[mainV][sideV], parents, "main"); err != nil {
+			return plumbing.ZeroHash, err
+		}
+		parents = []plumbing.Hash{head}
+	}
+	return head, nil
+}
+
+// writeTrees writes every combination of the versions of main.txt and
+// side.txt.
+func (g *generator) writeTrees() error {
+	var blobs [max(mainVersions, sideVersions)]plumbing.Hash
+	for v := range blobs {
+		obj := g.st.NewEncodedObject()
+		obj.SetType(plumbing.BlobObject)
+		w, err := obj.Writer()
+		if err != nil {
+			return fmt.Errorf("benchrepo: blob: %w", err)
+		}
+		if _, err := fmt.Fprintf(w, "version %d\n", v); err != nil {
+			return fmt.Errorf("benchrepo: blob: %w", err)
+		}
+		if err := w.Close(); err != nil {
+			return fmt.Errorf("benchrepo: blob: %w", err)
+		}
+		if blobs[v], err = g.st.SetEncodedObject(obj); err != nil {
+			return fmt.Errorf("benchrepo: blob: %w", err)
+		}
+	}
+
+	for mainV := range mainVersions {
+		for sideV := range sideVersions {
+			tree := &object.Tree{Entries: []object.TreeEntry{
+				{Name: "main.txt", Mode: filemode.Regular, Hash: blobs[mainV]},
+				{Name: "side.txt", Mode: filemode.Regular, Hash: blobs[sideV]},
+			}}
+			hash, err := g.store(tree)
+			if err != nil {
+				return fmt.Errorf("benchrepo: tree: %w", err)
+			}
+			g.trees[mainV][sideV] = hash
+		}
+	}
+	return nil
+}
+
+// commit writes a commit of tree with parents.
+func (g *generator) commit(tree plumbing.Hash, parents []plumbing.Hash, branch string) (plumbing.Hash, error) {
+	when := epoch.Add(time.Duration(g.commits) * time.Second)
+	sig := object.Signature{Name: "bench", Email: "bench@example.com", When: when}
+	g.commits++
+	hash, err := g.store(&object.Commit{
+		Author:       sig,
+		Committer:    sig,
+		Message:      fmt.Sprintf("%s commit %d\n", branch, g.commits),
+		TreeHash:     tree,
+		ParentHashes: parents,
+	})
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("benchrepo: commit: %w", err)
+	}
+	return hash, nil
+}
+
+// store encodes obj into the storage and returns its hash.
+func (g *generator) store(obj interface {
+	Encode(plumbing.EncodedObject) error
+}) (plumbing.Hash, error) {
+	encoded := g.st.NewEncodedObject()
+	if err := obj.Encode(encoded); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return g.st.SetEncodedObject(encoded)
+}
+
+// refs points main, and through it HEAD, at head and adds the origin remote.
+func (g *generator) refs(head plumbing.Hash) error {
+	main := plumbing.NewBranchReferenceName("main")
+	if err := g.st.SetReference(plumbing.NewHashReference(main, head)); err != nil {
+		return fmt.Errorf("benchrepo: refs: %w", err)
+	}
+	if err := g.st.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, main)); err != nil {
+		return fmt.Errorf("benchrepo: refs: %w", err)
+	}
+
+	cfg, err := g.st.Config()
+	if err != nil {
+		return fmt.Errorf("benchrepo: config: %w", err)
+	}
+	cfg.Remotes["origin"] = &config.RemoteConfig{
+		Name:  "origin",
+		URLs:  []string{"https://github.com/" + Repository + ".git"},
+		Fetch: []config.RefSpec{"+refs/heads/*:refs/remotes/origin/*"},
+	}
+	if err := g.st.SetConfig(cfg); err != nil {
+		return fmt.Errorf("benchrepo: config: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package benchrepo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/gitcontext"
+)
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		shape        Shape
+		allParents   int
+		sideTouching int
+	}{
+		// Only the root commit, which adds it, changes side.txt
+		{shape: Linear, allParents: 10, sideTouching: 1},
+		// Two merges, each bringing in two side commits
+		{shape: Merged, allParents: 14, sideTouching: 3},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.shape), func(t *testing.T) {
+			fs := memfs.New()
+
+			head, err := WriteFS(fs, tt.shape, 10)
+
+			require.NoError(t, err)
+			repo, err := gitcontext.OpenFS(fs)
+			require.NoError(t, err)
+			ctx := context.Background()
+			gc, err := repo.Context(ctx)
+			require.NoError(t, err)
+			want := &gitcontext.Context{
+				HeadSHA:       head,
+				ObjectFormat:  gitcontext.ObjectFormatSHA1,
+				Branch:        "main",
+				Repository:    Repository,
+				DefaultBranch: "main",
+			}
+			assert.Equal(t, want, gc)
+
+			commits, err := repo.Ancestry(ctx, 1000)
+			require.NoError(t, err)
+			assert.Len(t, commits, 10)
+			all, err := repo.With(gitcontext.WithAllParents()).Ancestry(ctx, 1000)
+			require.NoError(t, err)
+			assert.Len(t, all, tt.allParents)
+
+			side, err := repo.With(gitcontext.WithPaths("side.txt")).Ancestry(ctx, 1000)
+			require.NoError(t, err)
+			assert.Len(t, side, tt.sideTouching, "only the root and merges change side.txt on the first-parent chain")
+		})
+	}
+}
+
+func TestGenerate_Invalid(t *testing.T) {
+	_, err := WriteFS(memfs.New(), Linear, 0)
+	assert.ErrorContains(t, err, "at least one commit")
+
+	_, err = WriteFS(memfs.New(), "octopus", 10)
+	assert.ErrorContains(t, err, `unknown shape "octopus"`)
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Environment variables configuring the Argo CD API, named as the argocd CLI
+// reads them.
+const (
+	// EnvArgoCDServer is the Argo CD API server: host[:port], or a URL when
+	// the server is not served over HTTPS.
+	EnvArgoCDServer = "ARGOCD_SERVER"
+	// EnvArgoCDAuthToken is the bearer token for the Argo CD API.
+	EnvArgoCDAuthToken = "ARGOCD_AUTH_TOKEN"
+)
+
+// ErrArgoCDInvalid indicates the Argo CD settings are missing or malformed.
+var ErrArgoCDInvalid = errors.New("invalid Argo CD configuration")
+
+// ArgoCDConfig addresses the Argo CD API.
+type ArgoCDConfig struct {
+	// BaseURL is the server's URL, e.g. https://argocd.example.com.
+	BaseURL string
+	// Token authenticates API requests.
+	Token string
+}
+
+// LoadArgoCD reads the Argo CD API settings from the environment. Both
+// variables are required. A server given without a scheme is reached over
+// HTTPS, as the argocd CLI does.
+func LoadArgoCD() (ArgoCDConfig, error) {
+	server := strings.TrimSpace(os.Getenv(EnvArgoCDServer))
+	token := strings.TrimSpace(os.Getenv(EnvArgoCDAuthToken))
+	if server == "" || token == "" {
+		return ArgoCDConfig{}, fmt.Errorf("%w: %s and %s must be set",
+			ErrArgoCDInvalid, EnvArgoCDServer, EnvArgoCDAuthToken)
+	}
+
+	if !strings.Contains(server, "://") {
+		server = "https://" + server
+	}
+	u, err := url.Parse(server)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ArgoCDConfig{}, fmt.Errorf("%w: %s must be host[:port] or an http(s) URL",
+			ErrArgoCDInvalid, EnvArgoCDServer)
+	}
+	return ArgoCDConfig{BaseURL: strings.TrimSuffix(u.String(), "/"), Token: token}, nil
+}
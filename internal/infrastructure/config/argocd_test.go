@@ -0,0 +1,56 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadArgoCD(t *testing.T) {
+	tests := []struct {
+		name   string
+		server string
+		want   string
+	}{
+		{name: "host", server: "argocd.example.com", want: "https://argocd.example.com"},
+		{name: "host and port", server: " argocd.example.com:8443 ", want: "https://argocd.example.com:8443"},
+		{name: "plain http url", server: "http://argocd-server.argocd/", want: "http://argocd-server.argocd"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvArgoCDServer, tt.server)
+			t.Setenv(EnvArgoCDAuthToken, "token")
+
+			cfg, err := LoadArgoCD()
+
+			require.NoError(t, err)
+			assert.Equal(t, ArgoCDConfig{BaseURL: tt.want, Token: "token"}, cfg)
+		})
+	}
+}
+
+func TestLoadArgoCD_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		server string
+		token  string
+	}{
+		{name: "unset"},
+		{name: "no token", server: "argocd.example.com"},
+		{name: "no server", token: "token"},
+		{name: "unsupported scheme", server: "grpc://argocd.example.com", token: "token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvArgoCDServer, tt.server)
+			t.Setenv(EnvArgoCDAuthToken, tt.token)
+
+			_, err := LoadArgoCD()
+
+			assert.ErrorIs(t, err, ErrArgoCDInvalid)
+		})
+	}
+}
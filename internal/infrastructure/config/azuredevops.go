@@ -0,0 +1,74 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Environment variables locating the Azure DevOps build being run. The agent
+// sets all but the token in every pipeline job.
+const (
+	// EnvAzureDevOpsCollectionURI is the organization's URL, e.g.
+	// https://dev.azure.com/myorg/.
+	EnvAzureDevOpsCollectionURI = "SYSTEM_COLLECTIONURI"
+	// EnvAzureDevOpsProject is the ID of the project the build belongs to.
+	EnvAzureDevOpsProject = "SYSTEM_TEAMPROJECTID"
+	// EnvAzureDevOpsBuildID is the ID of the running build.
+	EnvAzureDevOpsBuildID = "BUILD_BUILDID"
+	// EnvAzureDevOpsPAT is a personal access token, read as the az devops
+	// CLI reads it.
+	EnvAzureDevOpsPAT = "AZURE_DEVOPS_EXT_PAT"
+	// EnvAzureDevOpsAccessToken is the job's own token, used when no personal
+	// access token is set. Pipelines must map it into the environment.
+	EnvAzureDevOpsAccessToken = "SYSTEM_ACCESSTOKEN"
+)
+
+// ErrAzureDevOpsInvalid indicates the Azure DevOps settings are missing or malformed.
+var ErrAzureDevOpsInvalid = errors.New("invalid Azure DevOps configuration")
+
+// AzureDevOpsConfig addresses the running build through the Azure DevOps REST API.
+type AzureDevOpsConfig struct {
+	// CollectionURL is the organization's URL, without a trailing slash.
+	CollectionURL string
+	// Project is the project ID.
+	Project string
+	// BuildID is the running build's ID.
+	BuildID string
+	// Token authenticates API requests.
+	Token string
+}
+
+// LoadAzureDevOps reads the build and token from the environment. All values
+// are required; the token is EnvAzureDevOpsPAT, or EnvAzureDevOpsAccessToken
+// when that is unset.
+func LoadAzureDevOps() (AzureDevOpsConfig, error) {
+	cfg := AzureDevOpsConfig{
+		CollectionURL: strings.TrimSuffix(strings.TrimSpace(os.Getenv(EnvAzureDevOpsCollectionURI)), "/"),
+		Project:       strings.TrimSpace(os.Getenv(EnvAzureDevOpsProject)),
+		BuildID:       strings.TrimSpace(os.Getenv(EnvAzureDevOpsBuildID)),
+		Token:         strings.TrimSpace(os.Getenv(EnvAzureDevOpsPAT)),
+	}
+	if cfg.Token == "" {
+		cfg.Token = strings.TrimSpace(os.Getenv(EnvAzureDevOpsAccessToken))
+	}
+
+	switch {
+	case cfg.CollectionURL == "" || cfg.Project == "" || cfg.BuildID == "":
+		return AzureDevOpsConfig{}, fmt.Errorf("%w: %s, %s, and %s must be set (is this an Azure Pipelines job?)",
+			ErrAzureDevOpsInvalid, EnvAzureDevOpsCollectionURI, EnvAzureDevOpsProject, EnvAzureDevOpsBuildID)
+	case cfg.Token == "":
+		return AzureDevOpsConfig{}, fmt.Errorf("%w: %s or %s must be set",
+			ErrAzureDevOpsInvalid, EnvAzureDevOpsPAT, EnvAzureDevOpsAccessToken)
+	case strings.Trim(cfg.BuildID, "0123456789") != "":
+		return AzureDevOpsConfig{}, fmt.Errorf("%w: %s must be a number", ErrAzureDevOpsInvalid, EnvAzureDevOpsBuildID)
+	}
+	u, err := url.Parse(cfg.CollectionURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return AzureDevOpsConfig{}, fmt.Errorf("%w: %s must be an http(s) URL",
+			ErrAzureDevOpsInvalid, EnvAzureDevOpsCollectionURI)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setAzureDevOpsEnv sets the Azure DevOps variables to values, in the order
+// collection URI, project, build ID, PAT, and access token.
+func setAzureDevOpsEnv(t *testing.T, values ...string) {
+	t.Helper()
+	keys := []string{
+		EnvAzureDevOpsCollectionURI, EnvAzureDevOpsProject, EnvAzureDevOpsBuildID,
+		EnvAzureDevOpsPAT, EnvAzureDevOpsAccessToken,
+	}
+	for i, key := range keys {
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+		t.Setenv(key, value)
+	}
+}
+
+func TestLoadAzureDevOps(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		want   AzureDevOpsConfig
+	}{
+		{
+			name:   "personal access token",
+			values: []string{"https://dev.azure.com/myorg/", "proj-1", "42", "pat", "job-token"},
+			want: AzureDevOpsConfig{
+				CollectionURL: "https://dev.azure.com/myorg", Project: "proj-1", BuildID: "42", Token: "pat",
+			},
+		},
+		{
+			name:   "job access token",
+			values: []string{"https://tfs.example.com/DefaultCollection", "proj-1", "42", "", "job-token"},
+			want: AzureDevOpsConfig{
+				CollectionURL: "https://tfs.example.com/DefaultCollection", Project: "proj-1", BuildID: "42",
+				Token: "job-token",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setAzureDevOpsEnv(t, tt.values...)
+
+			cfg, err := LoadAzureDevOps()
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, cfg)
+		})
+	}
+}
+
+func TestLoadAzureDevOps_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		wantMsg string
+	}{
+		{name: "not a pipeline job", wantMsg: "must be set (is this an Azure Pipelines job?)"},
+		{
+			name:    "no token",
+			values:  []string{"https://dev.azure.com/myorg/", "proj-1", "42"},
+			wantMsg: "AZURE_DEVOPS_EXT_PAT or SYSTEM_ACCESSTOKEN must be set",
+		},
+		{
+			name:    "build ID not a number",
+			values:  []string{"https://dev.azure.com/myorg/", "proj-1", "42/tags", "pat"},
+			wantMsg: "BUILD_BUILDID must be a number",
+		},
+		{
+			name:    "collection not a URL",
+			values:  []string{"dev.azure.com/myorg", "proj-1", "42", "pat"},
+			wantMsg: "SYSTEM_COLLECTIONURI must be an http(s) URL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setAzureDevOpsEnv(t, tt.values...)
+
+			_, err := LoadAzureDevOps()
+
+			assert.ErrorIs(t, err, ErrAzureDevOpsInvalid)
+			assert.ErrorContains(t, err, tt.wantMsg)
+		})
+	}
+}
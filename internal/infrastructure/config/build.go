@@ -0,0 +1,51 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// Environment variables GitHub Actions sets to identify the workflow run.
+const (
+	EnvGitHubWorkflow = "GITHUB_WORKFLOW"
+	EnvGitHubRunID    = "GITHUB_RUN_ID"
+)
+
+// unknownBuildValue stands in for a build identity value that cannot be determined.
+const unknownBuildValue = "unknown"
+
+// BuildIdentity identifies the host and CI pipeline running slippy-find.
+type BuildIdentity struct {
+	// Host is the machine's hostname.
+	Host string
+	// Pipeline is the workflow name and run ID (e.g., "build#123456").
+	Pipeline string
+}
+
+// DetectBuildIdentity reads the hostname and the GitHub Actions workflow run from
+// the environment. Values that cannot be determined are reported as "unknown".
+func DetectBuildIdentity() BuildIdentity {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = unknownBuildValue
+	}
+	return BuildIdentity{
+		Host:     host,
+		Pipeline: pipelineName(os.Getenv(EnvGitHubWorkflow), os.Getenv(EnvGitHubRunID)),
+	}
+}
+
+// pipelineName formats a workflow name and run ID as "workflow#run".
+func pipelineName(workflow, runID string) string {
+	workflow = strings.TrimSpace(workflow)
+	runID = strings.TrimSpace(runID)
+	switch {
+	case workflow == "" && runID == "":
+		return unknownBuildValue
+	case runID == "":
+		return workflow
+	case workflow == "":
+		return unknownBuildValue + "#" + runID
+	}
+	return workflow + "#" + runID
+}
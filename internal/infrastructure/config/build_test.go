@@ -0,0 +1,41 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectBuildIdentity(t *testing.T) {
+	t.Setenv(EnvGitHubWorkflow, "build")
+	t.Setenv(EnvGitHubRunID, "123456")
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+
+	identity := DetectBuildIdentity()
+
+	assert.Equal(t, BuildIdentity{Host: host, Pipeline: "build#123456"}, identity)
+}
+
+func TestPipelineName(t *testing.T) {
+	tests := []struct {
+		name     string
+		workflow string
+		runID    string
+		want     string
+	}{
+		{name: "workflow and run", workflow: "build", runID: "42", want: "build#42"},
+		{name: "workflow only", workflow: " build ", want: "build"},
+		{name: "run only", runID: "42", want: "unknown#42"},
+		{name: "neither", want: "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, pipelineName(tt.workflow, tt.runID))
+		})
+	}
+}
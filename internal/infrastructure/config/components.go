@@ -0,0 +1,92 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ComponentsFile is the repository-relative file that maps monorepo components to paths.
+//
+// Example:
+//
+//	components:
+//	  payments-api:
+//	    paths:
+//	      - services/payments
+//	      - libs/billing
+const ComponentsFile = ".slippy.yaml"
+
+// ErrComponentsInvalid indicates the component mapping file is malformed.
+var ErrComponentsInvalid = errors.New("invalid component configuration")
+
+// componentsDocument is the on-disk layout of ComponentsFile.
+type componentsDocument struct {
+	Components map[string]componentEntry `yaml:"components"`
+}
+
+// componentEntry lists the paths owned by one component.
+type componentEntry struct {
+	Paths []string `yaml:"paths"`
+}
+
+// LoadComponents reads ComponentsFile from the repository root and returns
+// component name -> repository-relative path prefixes.
+// Paths are normalized to slash-separated form without leading "./" or "/".
+func LoadComponents(repoPath string) (map[string][]string, error) {
+	file := filepath.Join(repoPath, ComponentsFile)
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+
+	components, err := parseComponents(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return components, nil
+}
+
+// parseComponents decodes and validates component mapping YAML.
+func parseComponents(data []byte) (map[string][]string, error) {
+	var doc componentsDocument
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrComponentsInvalid, err)
+	}
+
+	components := make(map[string][]string, len(doc.Components))
+	for name, entry := range doc.Components {
+		if len(entry.Paths) == 0 {
+			return nil, fmt.Errorf("%w: component %q has no paths", ErrComponentsInvalid, name)
+		}
+		paths := make([]string, 0, len(entry.Paths))
+		for _, p := range entry.Paths {
+			normalized := normalizeComponentPath(p)
+			if normalized == "" {
+				return nil, fmt.Errorf("%w: component %q has an empty path", ErrComponentsInvalid, name)
+			}
+			paths = append(paths, normalized)
+		}
+		components[name] = paths
+	}
+	return components, nil
+}
+
+// normalizeComponentPath cleans a path prefix so it can be compared with git tree paths.
+// The repository root itself (".") is kept as "." so a component may own everything.
+func normalizeComponentPath(p string) string {
+	p = strings.TrimSpace(filepath.ToSlash(p))
+	if p == "" {
+		return ""
+	}
+	cleaned := strings.TrimPrefix(path.Clean("/"+p), "/")
+	if cleaned == "" {
+		return "."
+	}
+	return cleaned
+}
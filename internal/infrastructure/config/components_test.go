@@ -0,0 +1,60 @@
+package config
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadComponents(t *testing.T) {
+	dir := t.TempDir()
+	content := `
+components:
+  payments-api:
+    paths:
+      - services/payments/
+      - ./libs/billing
+  everything:
+    paths:
+      - .
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ComponentsFile), []byte(content), 0o600))
+
+	components, err := LoadComponents(dir)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"payments-api": {"services/payments", "libs/billing"},
+		"everything":   {"."},
+	}, components)
+}
+
+func TestLoadComponents_MissingFile(t *testing.T) {
+	_, err := LoadComponents(t.TempDir())
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestParseComponents_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "malformed yaml", content: "components: [unclosed"},
+		{name: "no paths", content: "components:\n  api:\n    paths: []\n"},
+		{name: "blank path", content: "components:\n  api:\n    paths: ['  ']\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseComponents([]byte(tt.content))
+			require.Error(t, err)
+			assert.ErrorIs(t, err, ErrComponentsInvalid)
+		})
+	}
+}
@@ -9,11 +9,16 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	ch "github.com/MyCarrier-DevOps/goLibMyCarrier/clickhouse"
 	"github.com/MyCarrier-DevOps/goLibMyCarrier/slippy"
 	"github.com/MyCarrier-DevOps/goLibMyCarrier/vault"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
 )
 
 // Environment variable names.
@@ -30,6 +35,34 @@ const (
 	// EnvLogAppName is the application name for log context.
 	EnvLogAppName = "LOG_APP_NAME"
 
+	// EnvLogFormat is the log encoding (json, console).
+	EnvLogFormat = "LOG_FORMAT"
+
+	// EnvLogFile is a file that receives a copy of the logs.
+	EnvLogFile = "LOG_FILE"
+
+	// EnvAudit enables writing resolution audit events to ClickHouse (true/false).
+	EnvAudit = "SLIPPY_AUDIT"
+
+	// EnvFinderPlugin is an executable serving slip lookups over the finder
+	// plugin protocol, used instead of ClickHouse.
+	EnvFinderPlugin = "SLIPPY_FINDER_PLUGIN"
+
+	// EnvStore selects the slip store: clickhouse or mock.
+	EnvStore = "SLIPPY_STORE"
+
+	// EnvGitTimeout is the most time a resolution may spend reading git, as
+	// a Go duration; 0 means no limit.
+	EnvGitTimeout = "SLIPPY_GIT_TIMEOUT"
+
+	// EnvStoreTimeout is the most time a resolution may spend querying the
+	// slip store, as a Go duration; 0 means no limit.
+	EnvStoreTimeout = "SLIPPY_STORE_TIMEOUT"
+
+	// EnvCorrelationIDFormat is the format resolved correlation IDs must
+	// have: uuid, any, or a regular expression.
+	EnvCorrelationIDFormat = "SLIPPY_CORRELATION_ID_FORMAT"
+
 	// EnvVaultPipelineConfigPath is the path in Vault KV where pipeline config is stored.
 	EnvVaultPipelineConfigPath = "VAULT_PIPELINE_CONFIG_PATH"
 
@@ -41,10 +74,21 @@ const (
 const (
 	DefaultLogLevel           = "info"
 	DefaultLogAppName         = "slippy-find"
+	DefaultLogFormat          = "json"
 	DefaultDatabase           = "ci"
 	DefaultVaultPipelineMount = "secret"
 )
 
+// Slip stores selected by SLIPPY_STORE.
+const (
+	// StoreClickHouse searches the ClickHouse slip store. It is the default.
+	StoreClickHouse = "clickhouse"
+
+	// StoreMock reports a fake slip for every HEAD, for dry runs of pipelines
+	// in sandboxes without a database.
+	StoreMock = "mock"
+)
+
 // Configuration errors.
 var (
 	// ErrPipelineConfigRequired indicates pipeline config source is not available.
@@ -64,6 +108,21 @@ var (
 
 	// ErrVaultSecretNotFound indicates the secret was not found in Vault.
 	ErrVaultSecretNotFound = errors.New("pipeline configuration not found in Vault")
+
+	// ErrAuditInvalid indicates SLIPPY_AUDIT is not a boolean.
+	ErrAuditInvalid = errors.New("invalid audit setting")
+
+	// ErrStoreInvalid indicates SLIPPY_STORE names an unknown store, or one
+	// that conflicts with SLIPPY_FINDER_PLUGIN.
+	ErrStoreInvalid = errors.New("invalid store setting")
+
+	// ErrStageTimeoutInvalid indicates SLIPPY_GIT_TIMEOUT or
+	// SLIPPY_STORE_TIMEOUT is not a non-negative duration.
+	ErrStageTimeoutInvalid = errors.New("invalid stage timeout")
+
+	// ErrCorrelationIDFormatInvalid indicates SLIPPY_CORRELATION_ID_FORMAT is
+	// not uuid, any, or a valid regular expression.
+	ErrCorrelationIDFormatInvalid = errors.New("invalid correlation ID format")
 )
 
 // VaultClient defines the interface for Vault operations.
@@ -111,6 +170,42 @@ type Config struct {
 
 	// LogAppName is the application name for log context.
 	LogAppName string
+
+	// LogFormat is the log encoding (json, console).
+	LogFormat string
+
+	// LogFile is a file that receives a copy of the logs. Optional.
+	LogFile string
+
+	// Audit enables writing resolution audit events to ClickHouse.
+	Audit bool
+
+	// DenyList holds correlation IDs that must never be returned. Optional;
+	// loaded from VAULT_DENY_LIST_PATH or SLIPPY_DENY_LIST_FILE.
+	DenyList []string
+
+	// FinderPlugin is the executable searched for slips instead of ClickHouse,
+	// if any. When set, ClickHouse and pipeline configuration are not loaded.
+	FinderPlugin string
+
+	// Store is the slip store searched: StoreClickHouse or StoreMock. With
+	// StoreMock, ClickHouse and pipeline configuration are not loaded.
+	Store string
+
+	// GitTimeout is the most time a resolution may spend reading git; zero
+	// means no limit.
+	GitTimeout time.Duration
+
+	// StoreTimeout is the most time a resolution may spend querying the slip
+	// store; zero means no limit.
+	StoreTimeout time.Duration
+
+	// CorrelationIDFormat is the pattern resolved correlation IDs must match;
+	// nil accepts any non-empty ID.
+	CorrelationIDFormat *regexp.Regexp
+
+	// Settings records each effective value and the source that supplied it.
+	Settings []Setting
 }
 
 // Load loads the application configuration from environment variables.
@@ -135,64 +230,158 @@ func Load() (*Config, error) {
 // If vaultClientFactory is nil, DefaultVaultClientFactory is used.
 // This function enables dependency injection for testing.
 func LoadWithVaultClient(ctx context.Context, vaultClientFactory VaultClientFactory) (*Config, error) {
-	// Load ClickHouse configuration
-	chConfig, err := ch.ClickhouseLoadConfig()
-	if err != nil {
-		return nil, fmt.Errorf("failed to load ClickHouse config: %w", err)
+	// A finder plugin or the mock store replaces ClickHouse, and with it the
+	// pipeline configuration
+	finderPlugin := envSetting("finder_plugin", EnvFinderPlugin, "", false)
+	storeSetting := envSetting("store", EnvStore, StoreClickHouse, false)
+	switch {
+	case storeSetting.Value != StoreClickHouse && storeSetting.Value != StoreMock:
+		return nil, fmt.Errorf("%w: %s=%q is not %s or %s",
+			ErrStoreInvalid, EnvStore, storeSetting.Value, StoreClickHouse, StoreMock)
+	case storeSetting.Value == StoreMock && finderPlugin.Value != "":
+		return nil, fmt.Errorf("%w: %s=%s cannot be combined with %s", ErrStoreInvalid, EnvStore, StoreMock, EnvFinderPlugin)
 	}
+	useClickHouse := finderPlugin.Value == "" && storeSetting.Value == StoreClickHouse
+	var (
+		chConfig        *ch.ClickhouseConfig
+		pipelineConfig  *slippy.PipelineConfig
+		pipelineSetting Setting
+		settings        []Setting
+	)
+	if useClickHouse {
+		var err error
+		chConfig, err = ch.ClickhouseLoadConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load ClickHouse config: %w", err)
+		}
 
-	// Load pipeline configuration (try Vault first, then file fallback)
-	pipelineConfig, err := loadPipelineConfigWithVault(ctx, vaultClientFactory)
+		// Load pipeline configuration (try Vault first, then file fallback)
+		pipelineConfig, pipelineSetting, err = loadPipelineConfigWithVault(ctx, vaultClientFactory)
+		if err != nil {
+			return nil, err
+		}
+		settings = clickHouseSettings()
+	}
+
+	// Load the optional deny-list (Vault first, then file)
+	denyList, denyListSetting, err := loadDenyList(ctx, vaultClientFactory)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get log settings with defaults
-	logLevel := os.Getenv(EnvLogLevel)
-	if logLevel == "" {
-		logLevel = DefaultLogLevel
+	// Get log settings and database name with defaults
+	logLevel := envSetting("log_level", EnvLogLevel, DefaultLogLevel, false)
+	logAppName := envSetting("log_app_name", EnvLogAppName, DefaultLogAppName, false)
+	logFormat := envSetting("log_format", EnvLogFormat, DefaultLogFormat, false)
+	logFile := envSetting("log_file", EnvLogFile, "", false)
+	database := envSetting("database", EnvDatabase, DefaultDatabase, false)
+
+	auditSetting := envSetting("audit", EnvAudit, "false", false)
+	audit, err := strconv.ParseBool(auditSetting.Value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s=%q is not a boolean", ErrAuditInvalid, EnvAudit, auditSetting.Value)
 	}
 
-	logAppName := os.Getenv(EnvLogAppName)
-	if logAppName == "" {
-		logAppName = DefaultLogAppName
+	gitTimeoutSetting := envSetting("git_timeout", EnvGitTimeout, "0", false)
+	gitTimeout, err := stageTimeout(EnvGitTimeout, gitTimeoutSetting.Value)
+	if err != nil {
+		return nil, err
+	}
+	storeTimeoutSetting := envSetting("store_timeout", EnvStoreTimeout, "0", false)
+	storeTimeout, err := stageTimeout(EnvStoreTimeout, storeTimeoutSetting.Value)
+	if err != nil {
+		return nil, err
 	}
 
-	// Get database name with default
-	database := os.Getenv(EnvDatabase)
-	if database == "" {
-		database = DefaultDatabase
+	correlationIDFormatSetting := envSetting("correlation_id_format", EnvCorrelationIDFormat,
+		domain.CorrelationIDFormatUUID, false)
+	correlationIDFormat, err := domain.ParseCorrelationIDFormat(correlationIDFormatSetting.Value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s=%w", ErrCorrelationIDFormatInvalid, EnvCorrelationIDFormat, err)
 	}
 
+	settings = append(settings, database)
+	if useClickHouse {
+		settings = append(settings, pipelineSetting)
+	}
+	settings = append(settings,
+		storeSetting, finderPlugin, denyListSetting, logLevel, logAppName, logFormat, logFile, auditSetting,
+		gitTimeoutSetting, storeTimeoutSetting, correlationIDFormatSetting)
+
 	return &Config{
-		ClickHouse:     chConfig,
-		PipelineConfig: pipelineConfig,
-		Database:       database,
-		LogLevel:       logLevel,
-		LogAppName:     logAppName,
+		ClickHouse:          chConfig,
+		PipelineConfig:      pipelineConfig,
+		Database:            database.Value,
+		LogLevel:            logLevel.Value,
+		LogAppName:          logAppName.Value,
+		LogFormat:           logFormat.Value,
+		LogFile:             logFile.Value,
+		Audit:               audit,
+		DenyList:            denyList,
+		FinderPlugin:        finderPlugin.Value,
+		Store:               storeSetting.Value,
+		GitTimeout:          gitTimeout,
+		StoreTimeout:        storeTimeout,
+		Settings:            settings,
+		CorrelationIDFormat: correlationIDFormat,
 	}, nil
 }
 
+// stageTimeout parses the stage timeout value of the environment variable
+// name. Returns ErrStageTimeoutInvalid unless it is a non-negative duration.
+func stageTimeout(name, value string) (time.Duration, error) {
+	timeout, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil || timeout < 0 {
+		return 0, fmt.Errorf("%w: %s=%q is not a non-negative duration", ErrStageTimeoutInvalid, name, value)
+	}
+	return timeout, nil
+}
+
 // loadPipelineConfigWithVault attempts to load pipeline config from Vault first,
 // falling back to local file if Vault is not configured.
+// The returned Setting records which source supplied the pipeline config.
 func loadPipelineConfigWithVault(
 	ctx context.Context,
 	vaultClientFactory VaultClientFactory,
-) (*slippy.PipelineConfig, error) {
+) (*slippy.PipelineConfig, Setting, error) {
+	setting := Setting{Key: "pipeline_config"}
+
 	// Check if Vault configuration is available
 	vaultPath := os.Getenv(EnvVaultPipelineConfigPath)
 	if vaultPath != "" {
 		// Vault is configured, load from Vault
-		return loadPipelineConfigFromVault(ctx, vaultClientFactory, vaultPath)
+		cfg, err := loadPipelineConfigFromVault(ctx, vaultClientFactory, vaultPath)
+		if err != nil {
+			return nil, setting, err
+		}
+		setting.Value = cfg.Name
+		setting.Source = SourceVault
+		setting.Detail = vaultMount() + "/" + vaultPath
+		return cfg, setting, nil
 	}
 
 	// Fall back to local file
 	pipelineConfigPath := os.Getenv(EnvPipelineConfig)
 	if pipelineConfigPath == "" {
-		return nil, ErrPipelineConfigRequired
+		return nil, setting, ErrPipelineConfigRequired
 	}
 
-	return loadPipelineConfigFromFile(pipelineConfigPath)
+	cfg, err := loadPipelineConfigFromFile(pipelineConfigPath)
+	if err != nil {
+		return nil, setting, err
+	}
+	setting.Value = cfg.Name
+	setting.Source = SourceFile
+	setting.Detail = pipelineConfigPath
+	return cfg, setting, nil
+}
+
+// vaultMount returns the configured Vault KV mount, defaulting to "secret".
+func vaultMount() string {
+	if mount := os.Getenv(EnvVaultPipelineConfigMount); mount != "" {
+		return mount
+	}
+	return DefaultVaultPipelineMount
 }
 
 // DefaultSecretKey is the default key name to look for in Vault secrets.
@@ -231,10 +420,7 @@ func loadPipelineConfigFromVault(
 	}
 
 	// Get mount point (default to "secret")
-	mount := os.Getenv(EnvVaultPipelineConfigMount)
-	if mount == "" {
-		mount = DefaultVaultPipelineMount
-	}
+	mount := vaultMount()
 
 	// Read secret from Vault
 	secretData, err := client.GetKVSecret(ctx, path, mount)
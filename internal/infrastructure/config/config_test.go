@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -117,6 +118,72 @@ func TestLoad_ValidConfig(t *testing.T) {
 	assert.Equal(t, DefaultDatabase, cfg.Database)
 }
 
+func TestLoad_FinderPlugin(t *testing.T) {
+	// Neither ClickHouse nor a pipeline config is needed with a plugin
+	t.Setenv("CLICKHOUSE_HOSTNAME", "")
+	t.Setenv(EnvPipelineConfig, "")
+	t.Setenv(EnvVaultPipelineConfigPath, "")
+	t.Setenv(EnvFinderPlugin, "/usr/local/bin/slippy-finder")
+
+	cfg, err := Load()
+
+	require.NoError(t, err)
+	assert.Equal(t, "/usr/local/bin/slippy-finder", cfg.FinderPlugin)
+	assert.Nil(t, cfg.ClickHouse)
+	assert.Nil(t, cfg.PipelineConfig)
+	assert.Contains(t, cfg.Settings, Setting{
+		Key: "finder_plugin", Value: "/usr/local/bin/slippy-finder", Source: SourceEnv, Detail: EnvFinderPlugin,
+	})
+	for _, setting := range cfg.Settings {
+		assert.NotContains(t, setting.Key, "clickhouse.")
+	}
+}
+
+func TestLoad_MockStore(t *testing.T) {
+	// Neither ClickHouse nor a pipeline config is needed with the mock store
+	t.Setenv("CLICKHOUSE_HOSTNAME", "")
+	t.Setenv(EnvPipelineConfig, "")
+	t.Setenv(EnvVaultPipelineConfigPath, "")
+	t.Setenv(EnvFinderPlugin, "")
+	t.Setenv(EnvStore, StoreMock)
+
+	cfg, err := Load()
+
+	require.NoError(t, err)
+	assert.Equal(t, StoreMock, cfg.Store)
+	assert.Nil(t, cfg.ClickHouse)
+	assert.Nil(t, cfg.PipelineConfig)
+	assert.Contains(t, cfg.Settings, Setting{Key: "store", Value: StoreMock, Source: SourceEnv, Detail: EnvStore})
+}
+
+func TestLoad_InvalidStore(t *testing.T) {
+	tests := []struct {
+		name    string
+		store   string
+		plugin  string
+		wantMsg string
+	}{
+		{name: "unknown store", store: "postgres", wantMsg: `SLIPPY_STORE="postgres" is not clickhouse or mock`},
+		{
+			name:    "mock with plugin",
+			store:   StoreMock,
+			plugin:  "/usr/local/bin/slippy-finder",
+			wantMsg: "SLIPPY_STORE=mock cannot be combined with SLIPPY_FINDER_PLUGIN",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvStore, tt.store)
+			t.Setenv(EnvFinderPlugin, tt.plugin)
+
+			_, err := Load()
+
+			require.ErrorIs(t, err, ErrStoreInvalid)
+			assert.ErrorContains(t, err, tt.wantMsg)
+		})
+	}
+}
+
 func TestLoad_DefaultLogSettings(t *testing.T) {
 	// Create a temp file with valid pipeline config JSON
 	tmpDir := t.TempDir()
@@ -221,6 +288,114 @@ func TestLoad_CustomDatabase(t *testing.T) {
 	assert.Equal(t, "production", cfg.Database)
 }
 
+func TestLoad_Audit(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    bool
+		wantErr bool
+	}{
+		{name: "unset", value: "", want: false},
+		{name: "enabled", value: "true", want: true},
+		{name: "disabled", value: "0", want: false},
+		{name: "invalid", value: "sometimes", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "pipeline.json")
+			require.NoError(t, os.WriteFile(configPath, []byte(`{"version":"1","name":"test","steps":[]}`), 0o644))
+			setClickHouseEnvVars(t)
+			t.Setenv(EnvPipelineConfig, configPath)
+			t.Setenv(EnvVaultPipelineConfigPath, "")
+			t.Setenv(EnvAudit, tt.value)
+
+			cfg, err := Load()
+
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrAuditInvalid)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, cfg.Audit)
+		})
+	}
+}
+
+func TestLoad_StageTimeouts(t *testing.T) {
+	tests := []struct {
+		name      string
+		git       string
+		store     string
+		wantGit   time.Duration
+		wantStore time.Duration
+		wantErr   bool
+	}{
+		{name: "unset"},
+		{name: "both", git: "30s", store: "1m", wantGit: 30 * time.Second, wantStore: time.Minute},
+		{name: "zero", git: "0", store: "0s"},
+		{name: "invalid", git: "soon", wantErr: true},
+		{name: "negative", store: "-1s", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvStore, StoreMock)
+			t.Setenv(EnvGitTimeout, tt.git)
+			t.Setenv(EnvStoreTimeout, tt.store)
+
+			cfg, err := Load()
+
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrStageTimeoutInvalid)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantGit, cfg.GitTimeout)
+			assert.Equal(t, tt.wantStore, cfg.StoreTimeout)
+		})
+	}
+}
+
+func TestLoad_CorrelationIDFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		id      string
+		wantNil bool
+		wantErr bool
+	}{
+		{name: "default", id: "0B1E6F4C-6A0E-4D8E-9B43-1F1E2A3B4C5D"},
+		{name: "uuid", format: "uuid", id: "0b1e6f4c-6a0e-4d8e-9b43-1f1e2a3b4c5d"},
+		{name: "any", format: "any", wantNil: true},
+		{name: "regular expression", format: `[a-z]+-\d+`, id: "slip-42"},
+		{name: "invalid", format: "slip-(", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvStore, StoreMock)
+			t.Setenv(EnvCorrelationIDFormat, tt.format)
+
+			cfg, err := Load()
+
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrCorrelationIDFormatInvalid)
+				assert.Contains(t, err.Error(), EnvCorrelationIDFormat)
+				return
+			}
+			require.NoError(t, err)
+			if tt.wantNil {
+				assert.Nil(t, cfg.CorrelationIDFormat)
+				return
+			}
+			require.NotNil(t, cfg.CorrelationIDFormat)
+			assert.True(t, cfg.CorrelationIDFormat.MatchString(tt.id))
+			assert.False(t, cfg.CorrelationIDFormat.MatchString(" "+tt.id))
+		})
+	}
+}
+
 // Vault integration tests
 
 func TestLoadWithVaultClient_VaultConfigAsJSONString(t *testing.T) {
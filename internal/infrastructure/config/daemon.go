@@ -0,0 +1,63 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// EnvDaemon is the address of a local slippy-find server (see "serve") that
+// resolution is tried through first: a unix socket path, optionally as
+// unix:///path, or a loopback http:// URL.
+const EnvDaemon = "SLIPPY_DAEMON"
+
+// ErrDaemonAddressInvalid indicates EnvDaemon cannot be used.
+var ErrDaemonAddressInvalid = errors.New("invalid daemon address")
+
+// DaemonAddress locates a local slippy-find server. Exactly one field is set.
+type DaemonAddress struct {
+	// Socket is the path of the server's unix socket.
+	Socket string
+
+	// URL is the base URL of the server's loopback HTTP listener.
+	URL string
+}
+
+// LoadDaemonAddress reads EnvDaemon. It returns the zero DaemonAddress when
+// the variable is unset.
+func LoadDaemonAddress() (DaemonAddress, error) {
+	raw := strings.TrimSpace(os.Getenv(EnvDaemon))
+	if raw == "" {
+		return DaemonAddress{}, nil
+	}
+	addr, err := parseDaemonAddress(raw)
+	if err != nil {
+		return DaemonAddress{}, fmt.Errorf("%s: %w", EnvDaemon, err)
+	}
+	return addr, nil
+}
+
+// parseDaemonAddress parses the forms accepted by EnvDaemon. HTTP addresses
+// must be loopback, since the CLI sends the daemon no credentials.
+func parseDaemonAddress(raw string) (DaemonAddress, error) {
+	if path, ok := strings.CutPrefix(raw, "unix://"); ok {
+		raw = path
+	}
+	if filepath.IsAbs(raw) {
+		return DaemonAddress{Socket: raw}, nil
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "http" || u.Host == "" {
+		return DaemonAddress{}, fmt.Errorf("%w %q: expected a socket path or an http:// URL", ErrDaemonAddressInvalid, raw)
+	}
+	host := u.Hostname()
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return DaemonAddress{}, fmt.Errorf("%w %q: host must be loopback", ErrDaemonAddressInvalid, raw)
+	}
+	return DaemonAddress{URL: strings.TrimSuffix(raw, "/")}, nil
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDaemonAddress(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    DaemonAddress
+		wantMsg string
+	}{
+		{raw: "/var/run/slippy-find.sock", want: DaemonAddress{Socket: "/var/run/slippy-find.sock"}},
+		{raw: "unix:///var/run/slippy-find.sock", want: DaemonAddress{Socket: "/var/run/slippy-find.sock"}},
+		{raw: "http://127.0.0.1:8080", want: DaemonAddress{URL: "http://127.0.0.1:8080"}},
+		{raw: "http://localhost:8080/", want: DaemonAddress{URL: "http://localhost:8080"}},
+		{raw: "http://[::1]:8080", want: DaemonAddress{URL: "http://[::1]:8080"}},
+		{raw: "http://10.0.0.5:8080", wantMsg: "host must be loopback"},
+		{raw: "http://slippy-find.internal:8080", wantMsg: "host must be loopback"},
+		{raw: "https://127.0.0.1:8443", wantMsg: "expected a socket path or an http:// URL"},
+		{raw: "run/slippy-find.sock", wantMsg: "expected a socket path or an http:// URL"},
+		{raw: "unix://run/slippy-find.sock", wantMsg: "expected a socket path or an http:// URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			addr, err := parseDaemonAddress(tt.raw)
+
+			if tt.wantMsg != "" {
+				require.ErrorIs(t, err, ErrDaemonAddressInvalid)
+				assert.Contains(t, err.Error(), tt.wantMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, addr)
+		})
+	}
+}
+
+func TestLoadDaemonAddress(t *testing.T) {
+	t.Setenv(EnvDaemon, "")
+	addr, err := LoadDaemonAddress()
+	require.NoError(t, err)
+	assert.Equal(t, DaemonAddress{}, addr)
+
+	t.Setenv(EnvDaemon, " /var/run/slippy-find.sock ")
+	addr, err = LoadDaemonAddress()
+	require.NoError(t, err)
+	assert.Equal(t, DaemonAddress{Socket: "/var/run/slippy-find.sock"}, addr)
+
+	t.Setenv(EnvDaemon, "http://10.0.0.5:8080")
+	_, err = LoadDaemonAddress()
+	require.ErrorIs(t, err, ErrDaemonAddressInvalid)
+	assert.Contains(t, err.Error(), EnvDaemon)
+}
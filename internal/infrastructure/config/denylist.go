@@ -0,0 +1,136 @@
+package config
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Deny-list environment variables.
+const (
+	// EnvDenyListFile is the path to a local file of correlation IDs that must never be returned.
+	EnvDenyListFile = "SLIPPY_DENY_LIST_FILE"
+
+	// EnvVaultDenyListPath is the path in Vault KV where the deny-list is stored.
+	// Supports the same path#key syntax as EnvVaultPipelineConfigPath and the same mount.
+	EnvVaultDenyListPath = "VAULT_DENY_LIST_PATH"
+)
+
+// DefaultDenyListSecretKey is the Vault secret key read when EnvVaultDenyListPath has no #key suffix.
+const DefaultDenyListSecretKey = "correlation_ids"
+
+// ErrDenyListInvalid indicates the deny-list is malformed.
+var ErrDenyListInvalid = errors.New("invalid deny list")
+
+// loadDenyList loads the optional deny-list from Vault (preferred) or a local file.
+// Returns no IDs, and a default Setting, when neither source is configured.
+func loadDenyList(ctx context.Context, vaultClientFactory VaultClientFactory) ([]string, Setting, error) {
+	setting := Setting{Key: "deny_list", Source: SourceDefault}
+
+	if vaultPath := os.Getenv(EnvVaultDenyListPath); vaultPath != "" {
+		ids, err := loadDenyListFromVault(ctx, vaultClientFactory, vaultPath)
+		if err != nil {
+			return nil, setting, err
+		}
+		setting.Value = denyListSummary(ids)
+		setting.Source = SourceVault
+		setting.Detail = vaultMount() + "/" + vaultPath
+		return ids, setting, nil
+	}
+
+	path := os.Getenv(EnvDenyListFile)
+	if path == "" {
+		return nil, setting, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, setting, fmt.Errorf("failed to read deny list: %w", err)
+	}
+	defer func() {
+		// Read-only file; a close failure cannot lose data.
+		_ = f.Close()
+	}()
+
+	ids, err := parseDenyList(f)
+	if err != nil {
+		return nil, setting, fmt.Errorf("%s: %w", path, err)
+	}
+	setting.Value = denyListSummary(ids)
+	setting.Source = SourceFile
+	setting.Detail = path
+	return ids, setting, nil
+}
+
+// loadDenyListFromVault reads the deny-list from a Vault KV v2 secret.
+// The key may hold a string in the file format or a list of strings.
+func loadDenyListFromVault(
+	ctx context.Context,
+	vaultClientFactory VaultClientFactory,
+	fullPath string,
+) ([]string, error) {
+	if vaultClientFactory == nil {
+		vaultClientFactory = DefaultVaultClientFactory
+	}
+
+	path, secretKey := parseVaultPath(fullPath)
+	if !strings.Contains(fullPath, "#") {
+		secretKey = DefaultDenyListSecretKey
+	}
+
+	client, err := vaultClientFactory(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	secretData, err := client.GetKVSecret(ctx, path, vaultMount())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read deny list from Vault at path %s: %w", path, err)
+	}
+
+	switch value := secretData[secretKey].(type) {
+	case string:
+		return parseDenyList(strings.NewReader(value))
+	case []interface{}:
+		ids := make([]string, 0, len(value))
+		for i, item := range value {
+			id, ok := item.(string)
+			if !ok || strings.TrimSpace(id) == "" {
+				return nil, fmt.Errorf("%w: entry %d of %s#%s is not a correlation ID", ErrDenyListInvalid, i, path, secretKey)
+			}
+			ids = append(ids, strings.TrimSpace(id))
+		}
+		return ids, nil
+	default:
+		return nil, fmt.Errorf("%w: key %q not found at %s", ErrDenyListInvalid, secretKey, path)
+	}
+}
+
+// parseDenyList reads one correlation ID per line. Blank lines and lines
+// starting with '#' are ignored, as is anything after whitespace on a line,
+// so entries can carry a note (e.g. "550e8400-... rolled back, INC-1234").
+func parseDenyList(r io.Reader) ([]string, error) {
+	var ids []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		ids = append(ids, strings.Fields(line)[0])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDenyListInvalid, err)
+	}
+	return ids, nil
+}
+
+// denyListSummary describes a loaded deny-list for provenance output.
+func denyListSummary(ids []string) string {
+	return strconv.Itoa(len(ids)) + " correlation IDs"
+}
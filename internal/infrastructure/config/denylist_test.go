@@ -0,0 +1,116 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDenyList(t *testing.T) {
+	content := `
+# rolled back after INC-1234
+550e8400-e29b-41d4-a716-446655440000   INC-1234
+
+  6ba7b810-9dad-11d1-80b4-00c04fd430c8
+`
+	ids, err := parseDenyList(strings.NewReader(content))
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"550e8400-e29b-41d4-a716-446655440000",
+		"6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+	}, ids)
+}
+
+func TestLoadDenyList_NotConfigured(t *testing.T) {
+	t.Setenv(EnvVaultDenyListPath, "")
+	t.Setenv(EnvDenyListFile, "")
+
+	ids, setting, err := loadDenyList(context.Background(), nil)
+
+	require.NoError(t, err)
+	assert.Nil(t, ids)
+	assert.Equal(t, Setting{Key: "deny_list", Source: SourceDefault}, setting)
+}
+
+func TestLoadDenyList_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deny.txt")
+	require.NoError(t, os.WriteFile(path, []byte("slip-a\nslip-b\n"), 0o600))
+	t.Setenv(EnvVaultDenyListPath, "")
+	t.Setenv(EnvDenyListFile, path)
+
+	ids, setting, err := loadDenyList(context.Background(), nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"slip-a", "slip-b"}, ids)
+	assert.Equal(t, Setting{Key: "deny_list", Value: "2 correlation IDs", Source: SourceFile, Detail: path}, setting)
+}
+
+func TestLoadDenyList_MissingFile(t *testing.T) {
+	t.Setenv(EnvVaultDenyListPath, "")
+	t.Setenv(EnvDenyListFile, filepath.Join(t.TempDir(), "missing.txt"))
+
+	_, _, err := loadDenyList(context.Background(), nil)
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestLoadDenyList_Vault(t *testing.T) {
+	client := &mockVaultClient{
+		secrets: map[string]map[string]interface{}{
+			"ci/slippy/deny": {
+				"correlation_ids": "slip-a\n# note\nslip-b",
+				"list":            []interface{}{"slip-c", " slip-d "},
+				"bad":             []interface{}{"slip-e", 42},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantIDs []string
+		wantErr error
+	}{
+		{name: "default key as text", path: "ci/slippy/deny", wantIDs: []string{"slip-a", "slip-b"}},
+		{name: "explicit key as list", path: "ci/slippy/deny#list", wantIDs: []string{"slip-c", "slip-d"}},
+		{name: "non-string entry", path: "ci/slippy/deny#bad", wantErr: ErrDenyListInvalid},
+		{name: "missing key", path: "ci/slippy/deny#absent", wantErr: ErrDenyListInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvVaultDenyListPath, tt.path)
+			t.Setenv(EnvDenyListFile, "ignored-when-vault-is-set")
+
+			ids, setting, err := loadDenyList(context.Background(), mockVaultClientFactory(client, nil))
+
+			if tt.wantErr != nil {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantIDs, ids)
+			assert.Equal(t, SourceVault, setting.Source)
+			assert.Equal(t, "secret/"+tt.path, setting.Detail)
+		})
+	}
+}
+
+func TestLoadDenyList_VaultSecretError(t *testing.T) {
+	t.Setenv(EnvVaultDenyListPath, "ci/slippy/deny")
+	client := &mockVaultClient{err: errors.New("permission denied")}
+
+	_, _, err := loadDenyList(context.Background(), mockVaultClientFactory(client, nil))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "permission denied")
+}
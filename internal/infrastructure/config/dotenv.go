@@ -0,0 +1,180 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Dotenv environment variables.
+const (
+	// EnvDotEnvFile overrides the path of the .env file loaded for local development.
+	// When set explicitly, the file is loaded even in CI and must exist.
+	EnvDotEnvFile = "SLIPPY_ENV_FILE"
+
+	// EnvCI is the conventional variable set by CI systems (GitHub Actions, GitLab, Buildkite, ...).
+	EnvCI = "CI"
+)
+
+// DefaultDotEnvFile is the .env path tried when EnvDotEnvFile is unset.
+const DefaultDotEnvFile = ".env"
+
+// Dotenv errors.
+var (
+	// ErrDotEnvInvalid indicates a .env file contains a malformed line.
+	ErrDotEnvInvalid = errors.New("invalid .env file")
+
+	// ErrDotEnvUnavailable indicates the file named by SLIPPY_ENV_FILE is
+	// missing, unreadable, or malformed.
+	ErrDotEnvUnavailable = errors.New(EnvDotEnvFile + " cannot be loaded")
+)
+
+// LoadDotEnv loads KEY=VALUE pairs from a .env file into the process environment.
+// It must run before Load so the values participate in normal config resolution.
+//
+// Variables already present in the environment are never overridden, so an
+// exported shell variable always wins over the file.
+//
+// The file path is taken from SLIPPY_ENV_FILE, defaulting to ".env" in the
+// working directory. The default file is skipped when CI is truthy and is
+// optional; an explicitly configured file is always loaded and must exist.
+// Failing to load it returns an error wrapping ErrDotEnvUnavailable.
+//
+// Returns the path that was loaded (empty if none) and the keys that were applied.
+func LoadDotEnv() (string, []string, error) {
+	path := os.Getenv(EnvDotEnvFile)
+	explicit := path != ""
+	if !explicit {
+		if runningInCI() {
+			return "", nil, nil
+		}
+		path = DefaultDotEnvFile
+	}
+
+	keys, err := loadDotEnvFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return "", nil, nil
+		}
+		if explicit {
+			return path, nil, fmt.Errorf("%w: %w", ErrDotEnvUnavailable, err)
+		}
+		return path, nil, err
+	}
+	return path, keys, nil
+}
+
+// runningInCI reports whether the CI environment variable is set to a truthy value.
+func runningInCI() bool {
+	ci, err := strconv.ParseBool(os.Getenv(EnvCI))
+	return err == nil && ci
+}
+
+// loadDotEnvFile parses the file at path and sets any variables not already defined.
+func loadDotEnvFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		// Read-only file; a close failure cannot lose data.
+		_ = f.Close()
+	}()
+
+	pairs, err := parseDotEnv(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var applied []string
+	for _, kv := range pairs {
+		if _, exists := os.LookupEnv(kv[0]); exists {
+			continue
+		}
+		if err := os.Setenv(kv[0], kv[1]); err != nil {
+			return applied, fmt.Errorf("failed to set %s from %s: %w", kv[0], path, err)
+		}
+		recordDotEnvOrigin(kv[0], path)
+		applied = append(applied, kv[0])
+	}
+	return applied, nil
+}
+
+// parseDotEnv parses .env content into ordered key/value pairs.
+// Supported syntax:
+//   - blank lines and lines starting with '#' are ignored
+//   - an optional "export " prefix
+//   - double-quoted values with Go-style escapes (\n, \", ...)
+//   - single-quoted values taken literally
+//   - unquoted values with trailing " #" comments stripped
+func parseDotEnv(r io.Reader) ([][2]string, error) {
+	var pairs [][2]string
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("%w: line %d: expected KEY=VALUE", ErrDotEnvInvalid, lineNo)
+		}
+
+		parsed, err := parseDotEnvValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrDotEnvInvalid, lineNo, err)
+		}
+		pairs = append(pairs, [2]string{key, parsed})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return pairs, nil
+}
+
+// parseDotEnvValue interprets quoting and inline comments for a single value.
+func parseDotEnvValue(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, `"`):
+		end := closingQuote(value)
+		if end < 0 {
+			return "", errors.New("unterminated double quote")
+		}
+		return strconv.Unquote(value[:end+1])
+	case strings.HasPrefix(value, "'"):
+		end := strings.Index(value[1:], "'")
+		if end < 0 {
+			return "", errors.New("unterminated single quote")
+		}
+		return value[1 : end+1], nil
+	default:
+		if idx := strings.Index(value, " #"); idx >= 0 {
+			value = value[:idx]
+		}
+		return strings.TrimSpace(value), nil
+	}
+}
+
+// closingQuote returns the index of the unescaped double quote that closes
+// the string starting at value[0], or -1 if there is none.
+func closingQuote(value string) int {
+	for i := 1; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
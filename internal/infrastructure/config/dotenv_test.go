@@ -0,0 +1,139 @@
+package config
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDotEnv(t *testing.T) {
+	content := `
+# ClickHouse connection
+CLICKHOUSE_HOSTNAME=ch.local
+export CLICKHOUSE_PORT=9440
+CLICKHOUSE_PASSWORD="p@ss \"word\""
+SLIPPY_DATABASE='ci # not a comment'
+LOG_LEVEL=debug # trailing comment
+MULTILINE="a\nb"
+EMPTY=
+`
+	pairs, err := parseDotEnv(strings.NewReader(content))
+	require.NoError(t, err)
+
+	assert.Equal(t, [][2]string{
+		{"CLICKHOUSE_HOSTNAME", "ch.local"},
+		{"CLICKHOUSE_PORT", "9440"},
+		{"CLICKHOUSE_PASSWORD", `p@ss "word"`},
+		{"SLIPPY_DATABASE", "ci # not a comment"},
+		{"LOG_LEVEL", "debug"},
+		{"MULTILINE", "a\nb"},
+		{"EMPTY", ""},
+	}, pairs)
+}
+
+func TestParseDotEnv_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "missing equals", content: "JUSTAKEY"},
+		{name: "empty key", content: "=value"},
+		{name: "key with space", content: "BAD KEY=value"},
+		{name: "unterminated double quote", content: `KEY="value`},
+		{name: "unterminated single quote", content: `KEY='value`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseDotEnv(strings.NewReader(tt.content))
+			require.Error(t, err)
+			assert.ErrorIs(t, err, ErrDotEnvInvalid)
+		})
+	}
+}
+
+func TestLoadDotEnv_ExplicitFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "local.env")
+	require.NoError(t, os.WriteFile(path, []byte("SLIPPY_TEST_NEW=from-file\nSLIPPY_TEST_SET=from-file\n"), 0o600))
+
+	t.Setenv(EnvDotEnvFile, path)
+	t.Setenv(EnvCI, "true")
+	t.Setenv("SLIPPY_TEST_SET", "from-shell")
+	t.Setenv("SLIPPY_TEST_NEW", "")
+	require.NoError(t, os.Unsetenv("SLIPPY_TEST_NEW"))
+
+	loaded, keys, err := LoadDotEnv()
+
+	require.NoError(t, err)
+	assert.Equal(t, path, loaded)
+	assert.Equal(t, []string{"SLIPPY_TEST_NEW"}, keys)
+	assert.Equal(t, "from-file", os.Getenv("SLIPPY_TEST_NEW"))
+	assert.Equal(t, "from-shell", os.Getenv("SLIPPY_TEST_SET"), "existing env must win")
+}
+
+func TestLoadDotEnv_ExplicitFileMissing(t *testing.T) {
+	t.Setenv(EnvDotEnvFile, filepath.Join(t.TempDir(), "missing.env"))
+
+	_, _, err := LoadDotEnv()
+
+	require.ErrorIs(t, err, ErrDotEnvUnavailable)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestLoadDotEnv_ExplicitFileInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "local.env")
+	require.NoError(t, os.WriteFile(path, []byte("not a pair\n"), 0o600))
+	t.Setenv(EnvDotEnvFile, path)
+
+	_, _, err := LoadDotEnv()
+
+	require.ErrorIs(t, err, ErrDotEnvUnavailable)
+	assert.ErrorIs(t, err, ErrDotEnvInvalid)
+}
+
+func TestLoadDotEnv_DefaultFile(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, DefaultDotEnvFile), []byte("SLIPPY_TEST_DEFAULT=1\n"), 0o600))
+	t.Chdir(dir)
+	t.Setenv(EnvDotEnvFile, "")
+	t.Setenv(EnvCI, "")
+	t.Setenv("SLIPPY_TEST_DEFAULT", "")
+	require.NoError(t, os.Unsetenv("SLIPPY_TEST_DEFAULT"))
+
+	loaded, keys, err := LoadDotEnv()
+
+	require.NoError(t, err)
+	assert.Equal(t, DefaultDotEnvFile, loaded)
+	assert.Equal(t, []string{"SLIPPY_TEST_DEFAULT"}, keys)
+}
+
+func TestLoadDotEnv_DefaultFileSkippedInCI(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, DefaultDotEnvFile), []byte("SLIPPY_TEST_CI=1\n"), 0o600))
+	t.Chdir(dir)
+	t.Setenv(EnvDotEnvFile, "")
+	t.Setenv(EnvCI, "true")
+
+	loaded, keys, err := LoadDotEnv()
+
+	require.NoError(t, err)
+	assert.Empty(t, loaded)
+	assert.Empty(t, keys)
+}
+
+func TestLoadDotEnv_DefaultFileMissing(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv(EnvDotEnvFile, "")
+	t.Setenv(EnvCI, "")
+
+	loaded, keys, err := LoadDotEnv()
+
+	require.NoError(t, err)
+	assert.Empty(t, loaded)
+	assert.Empty(t, keys)
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Environment variables configuring error reporting.
+const (
+	// EnvSentryDSN enables reporting unexpected failures to this Sentry project.
+	EnvSentryDSN = "SENTRY_DSN"
+	// EnvSentryEnvironment is the Sentry environment events are filed under.
+	EnvSentryEnvironment = "SENTRY_ENVIRONMENT"
+	// EnvErrorWebhookURL enables POSTing unexpected failures as JSON to this URL.
+	EnvErrorWebhookURL = "SLIPPY_ERROR_WEBHOOK_URL"
+)
+
+// ErrErrorReportingInvalid indicates the error reporting settings are malformed.
+var ErrErrorReportingInvalid = errors.New("invalid error reporting configuration")
+
+// ErrorReportingConfig selects where unexpected failures are reported.
+// The zero value means reporting is disabled.
+type ErrorReportingConfig struct {
+	// SentryDSN is the Sentry project DSN; empty disables Sentry.
+	SentryDSN string
+	// SentryEnvironment is the Sentry environment, e.g. "production".
+	SentryEnvironment string
+	// WebhookURL receives a JSON POST per failure; empty disables the webhook.
+	WebhookURL string
+}
+
+// Enabled reports whether any reporting target is configured.
+func (c ErrorReportingConfig) Enabled() bool {
+	return c.SentryDSN != "" || c.WebhookURL != ""
+}
+
+// LoadErrorReporting reads the error reporting settings from the environment.
+// The Sentry DSN is validated when the client is created.
+func LoadErrorReporting() (ErrorReportingConfig, error) {
+	cfg := ErrorReportingConfig{
+		SentryDSN:         strings.TrimSpace(os.Getenv(EnvSentryDSN)),
+		SentryEnvironment: strings.TrimSpace(os.Getenv(EnvSentryEnvironment)),
+		WebhookURL:        strings.TrimSpace(os.Getenv(EnvErrorWebhookURL)),
+	}
+	if cfg.WebhookURL != "" {
+		u, err := url.Parse(cfg.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return ErrorReportingConfig{}, fmt.Errorf("%w: %s must be an http(s) URL",
+				ErrErrorReportingInvalid, EnvErrorWebhookURL)
+		}
+	}
+	return cfg, nil
+}
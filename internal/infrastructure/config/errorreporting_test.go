@@ -0,0 +1,64 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadErrorReporting(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want ErrorReportingConfig
+	}{
+		{name: "disabled"},
+		{
+			name: "sentry",
+			env:  map[string]string{EnvSentryDSN: " https://key@o1.ingest.sentry.io/2 ", EnvSentryEnvironment: "ci"},
+			want: ErrorReportingConfig{SentryDSN: "https://key@o1.ingest.sentry.io/2", SentryEnvironment: "ci"},
+		},
+		{
+			name: "webhook",
+			env:  map[string]string{EnvErrorWebhookURL: "https://hooks.example.com/slippy"},
+			want: ErrorReportingConfig{WebhookURL: "https://hooks.example.com/slippy"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearErrorReportingEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			cfg, err := LoadErrorReporting()
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, cfg)
+			assert.Equal(t, len(tt.env) > 0, cfg.Enabled())
+		})
+	}
+}
+
+func TestLoadErrorReporting_InvalidWebhook(t *testing.T) {
+	for _, raw := range []string{"hooks.example.com/slippy", "ftp://hooks.example.com", "https://"} {
+		t.Run(raw, func(t *testing.T) {
+			clearErrorReportingEnv(t)
+			t.Setenv(EnvErrorWebhookURL, raw)
+
+			_, err := LoadErrorReporting()
+
+			assert.ErrorIs(t, err, ErrErrorReportingInvalid)
+		})
+	}
+}
+
+// clearErrorReportingEnv unsets the error reporting variables for one test.
+func clearErrorReportingEnv(t *testing.T) {
+	t.Helper()
+	for _, key := range []string{EnvSentryDSN, EnvSentryEnvironment, EnvErrorWebhookURL} {
+		t.Setenv(key, "")
+	}
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Environment variables configuring resolution event publishing.
+const (
+	// EnvEventsURL enables publishing each successful resolution to this
+	// queue: nats://host:port or kafka://broker:port[,broker:port...].
+	EnvEventsURL = "SLIPPY_EVENTS_URL"
+	// EnvEventsTopic is the NATS subject or Kafka topic events are published to.
+	EnvEventsTopic = "SLIPPY_EVENTS_TOPIC"
+)
+
+// DefaultEventsTopic is the subject or topic used when EnvEventsTopic is unset.
+const DefaultEventsTopic = "slippy.resolved"
+
+// ErrEventsInvalid indicates the event publishing settings are malformed.
+var ErrEventsInvalid = errors.New("invalid event publishing configuration")
+
+// EventsConfig selects where resolution events are published.
+// The zero value means publishing is disabled.
+type EventsConfig struct {
+	// URL is the queue to publish to; empty disables publishing.
+	URL string
+	// Topic is the NATS subject or Kafka topic.
+	Topic string
+}
+
+// Enabled reports whether a queue is configured.
+func (c EventsConfig) Enabled() bool {
+	return c.URL != ""
+}
+
+// LoadEvents reads the event publishing settings from the environment.
+func LoadEvents() (EventsConfig, error) {
+	cfg := EventsConfig{
+		URL:   strings.TrimSpace(os.Getenv(EnvEventsURL)),
+		Topic: strings.TrimSpace(os.Getenv(EnvEventsTopic)),
+	}
+	if cfg.URL == "" {
+		if cfg.Topic != "" {
+			return EventsConfig{}, fmt.Errorf("%w: %s requires %s", ErrEventsInvalid, EnvEventsTopic, EnvEventsURL)
+		}
+		return EventsConfig{}, nil
+	}
+
+	scheme, rest, ok := strings.Cut(cfg.URL, "://")
+	if !ok || rest == "" || (scheme != "nats" && scheme != "tls" && scheme != "kafka") {
+		return EventsConfig{}, fmt.Errorf("%w: %s must be a nats:// or kafka:// URL", ErrEventsInvalid, EnvEventsURL)
+	}
+	if cfg.Topic == "" {
+		cfg.Topic = DefaultEventsTopic
+	}
+	return cfg, nil
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadEvents(t *testing.T) {
+	tests := []struct {
+		name  string
+		url   string
+		topic string
+		want  EventsConfig
+	}{
+		{name: "unset"},
+		{
+			name: "nats with default topic",
+			url:  " nats://nats:4222 ",
+			want: EventsConfig{URL: "nats://nats:4222", Topic: DefaultEventsTopic},
+		},
+		{
+			name:  "kafka with topic",
+			url:   "kafka://kafka-0:9092,kafka-1:9092",
+			topic: "ci.slips",
+			want:  EventsConfig{URL: "kafka://kafka-0:9092,kafka-1:9092", Topic: "ci.slips"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvEventsURL, tt.url)
+			t.Setenv(EnvEventsTopic, tt.topic)
+
+			cfg, err := LoadEvents()
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, cfg)
+			assert.Equal(t, tt.url != "", cfg.Enabled())
+		})
+	}
+}
+
+func TestLoadEvents_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		url   string
+		topic string
+	}{
+		{name: "topic without url", topic: "ci.slips"},
+		{name: "no scheme", url: "kafka-0:9092"},
+		{name: "unsupported scheme", url: "amqp://rabbit:5672"},
+		{name: "no address", url: "nats://"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvEventsURL, tt.url)
+			t.Setenv(EnvEventsTopic, tt.topic)
+
+			_, err := LoadEvents()
+
+			assert.ErrorIs(t, err, ErrEventsInvalid)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// EnvAncestryCache is the environment variable that turns the ancestry cache
+// kept in each repository's Git directory off ("false") or on ("true", the
+// default).
+const EnvAncestryCache = "SLIPPY_ANCESTRY_CACHE"
+
+// EnvMaxCommits is the environment variable holding the most commits a
+// history walk may hold in memory; 0 disables the limit.
+const EnvMaxCommits = "SLIPPY_MAX_COMMITS"
+
+// EnvCeilingDirectories is git's environment variable listing, separated as
+// PATH is, the directories the search for a repository root must not enter.
+const EnvCeilingDirectories = "GIT_CEILING_DIRECTORIES"
+
+// DefaultMaxCommits is the commit limit when EnvMaxCommits is unset: about
+// 40 MB of SHAs, far deeper than any ancestry a slip is found in.
+const DefaultMaxCommits = 500_000
+
+var (
+	// ErrAncestryCacheInvalid indicates SLIPPY_ANCESTRY_CACHE is not a boolean.
+	ErrAncestryCacheInvalid = errors.New("invalid ancestry cache setting")
+
+	// ErrMaxCommitsInvalid indicates SLIPPY_MAX_COMMITS is not a non-negative
+	// integer.
+	ErrMaxCommitsInvalid = errors.New("invalid commit limit")
+)
+
+// GitConfig holds the settings repositories are opened with.
+type GitConfig struct {
+	// AncestryCache enables caching HEAD's ancestry in the repository.
+	AncestryCache bool
+
+	// MaxCommits is the most commits a walk may hold; zero means no limit.
+	MaxCommits int
+
+	// CeilingDirectories are the directories the search for the repository
+	// root of a subdirectory stops below.
+	CeilingDirectories []string
+}
+
+// LoadGitConfig reads the repository settings from EnvAncestryCache,
+// EnvMaxCommits, and EnvCeilingDirectories, defaulting to a cached ancestry,
+// DefaultMaxCommits, and no ceilings.
+func LoadGitConfig() (GitConfig, error) {
+	cfg := GitConfig{AncestryCache: true, MaxCommits: DefaultMaxCommits}
+	if value := os.Getenv(EnvCeilingDirectories); value != "" {
+		cfg.CeilingDirectories = filepath.SplitList(value)
+	}
+	if value := strings.TrimSpace(os.Getenv(EnvAncestryCache)); value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return GitConfig{}, fmt.Errorf("%w: %s=%q is not a boolean", ErrAncestryCacheInvalid, EnvAncestryCache, value)
+		}
+		cfg.AncestryCache = enabled
+	}
+	if value := strings.TrimSpace(os.Getenv(EnvMaxCommits)); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			return GitConfig{}, fmt.Errorf("%w: %s=%q is not a non-negative integer", ErrMaxCommitsInvalid, EnvMaxCommits, value)
+		}
+		cfg.MaxCommits = limit
+	}
+	return cfg, nil
+}
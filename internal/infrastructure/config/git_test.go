@@ -0,0 +1,50 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadGitConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		cache      string
+		maxCommits string
+		ceilings   string
+		want       GitConfig
+		wantErr    error
+	}{
+		{name: "unset", want: GitConfig{AncestryCache: true, MaxCommits: DefaultMaxCommits}},
+		{name: "cache enabled", cache: "true", want: GitConfig{AncestryCache: true, MaxCommits: DefaultMaxCommits}},
+		{name: "cache disabled", cache: " 0 ", want: GitConfig{MaxCommits: DefaultMaxCommits}},
+		{name: "cache not a boolean", cache: "sometimes", wantErr: ErrAncestryCacheInvalid},
+		{name: "commit limit", maxCommits: "1000", want: GitConfig{AncestryCache: true, MaxCommits: 1000}},
+		{name: "no commit limit", maxCommits: "0", want: GitConfig{AncestryCache: true}},
+		{name: "negative commit limit", maxCommits: "-1", wantErr: ErrMaxCommitsInvalid},
+		{name: "commit limit not a number", maxCommits: "lots", wantErr: ErrMaxCommitsInvalid},
+		{
+			name:     "ceiling directories",
+			ceilings: "/home" + string(filepath.ListSeparator) + "/builds",
+			want:     GitConfig{AncestryCache: true, MaxCommits: DefaultMaxCommits, CeilingDirectories: []string{"/home", "/builds"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvAncestryCache, tt.cache)
+			t.Setenv(EnvMaxCommits, tt.maxCommits)
+			t.Setenv(EnvCeilingDirectories, tt.ceilings)
+
+			got, err := LoadGitConfig()
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
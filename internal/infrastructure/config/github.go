@@ -0,0 +1,50 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Environment variables configuring the GitHub REST API, as GitHub Actions
+// sets them.
+const (
+	// EnvGitHubToken authenticates GitHub API requests.
+	EnvGitHubToken = "GITHUB_TOKEN"
+	// EnvGitHubAPIURL is the REST API root, set for GitHub Enterprise Server.
+	EnvGitHubAPIURL = "GITHUB_API_URL"
+)
+
+// DefaultGitHubAPIURL is the REST API root of github.com.
+const DefaultGitHubAPIURL = "https://api.github.com"
+
+// ErrGitHubInvalid indicates the GitHub API settings are missing or malformed.
+var ErrGitHubInvalid = errors.New("invalid GitHub API configuration")
+
+// GitHubConfig addresses the GitHub REST API.
+type GitHubConfig struct {
+	// BaseURL is the API root, without a trailing slash.
+	BaseURL string
+	// Token authenticates API requests.
+	Token string
+}
+
+// LoadGitHub reads the GitHub API settings from the environment. The token is
+// required; the API URL defaults to DefaultGitHubAPIURL.
+func LoadGitHub() (GitHubConfig, error) {
+	token := strings.TrimSpace(os.Getenv(EnvGitHubToken))
+	if token == "" {
+		return GitHubConfig{}, fmt.Errorf("%w: %s must be set", ErrGitHubInvalid, EnvGitHubToken)
+	}
+	baseURL := strings.TrimSuffix(strings.TrimSpace(os.Getenv(EnvGitHubAPIURL)), "/")
+	if baseURL == "" {
+		return GitHubConfig{BaseURL: DefaultGitHubAPIURL, Token: token}, nil
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return GitHubConfig{}, fmt.Errorf("%w: %s must be an http(s) URL", ErrGitHubInvalid, EnvGitHubAPIURL)
+	}
+	return GitHubConfig{BaseURL: baseURL, Token: token}, nil
+}
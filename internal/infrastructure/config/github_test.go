@@ -0,0 +1,53 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadGitHub(t *testing.T) {
+	tests := []struct {
+		name   string
+		apiURL string
+		want   string
+	}{
+		{name: "github.com", want: DefaultGitHubAPIURL},
+		{name: "enterprise server", apiURL: "https://github.example.com/api/v3/", want: "https://github.example.com/api/v3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvGitHubToken, " token ")
+			t.Setenv(EnvGitHubAPIURL, tt.apiURL)
+
+			cfg, err := LoadGitHub()
+
+			require.NoError(t, err)
+			assert.Equal(t, GitHubConfig{BaseURL: tt.want, Token: "token"}, cfg)
+		})
+	}
+}
+
+func TestLoadGitHub_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		token  string
+		apiURL string
+	}{
+		{name: "no token"},
+		{name: "api url not a URL", token: "token", apiURL: "github.example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvGitHubToken, tt.token)
+			t.Setenv(EnvGitHubAPIURL, tt.apiURL)
+
+			_, err := LoadGitHub()
+
+			assert.ErrorIs(t, err, ErrGitHubInvalid)
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// CheckVault verifies that Vault can be reached, accepts the AppRole
+// credentials, and serves the pipeline configuration secret. It returns false
+// when pipeline configuration does not come from Vault (EnvVaultPipelineConfigPath
+// is unset), since there is then nothing to check.
+// If vaultClientFactory is nil, DefaultVaultClientFactory is used.
+func CheckVault(ctx context.Context, vaultClientFactory VaultClientFactory) (bool, error) {
+	fullPath := os.Getenv(EnvVaultPipelineConfigPath)
+	if fullPath == "" {
+		return false, nil
+	}
+	if vaultClientFactory == nil {
+		vaultClientFactory = DefaultVaultClientFactory
+	}
+
+	client, err := vaultClientFactory(ctx)
+	if err != nil {
+		return true, err
+	}
+	path, _ := parseVaultPath(fullPath)
+	if _, err := client.GetKVSecret(ctx, path, vaultMount()); err != nil {
+		return true, fmt.Errorf("%w at path %s: %w", ErrVaultSecretNotFound, path, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckVault(t *testing.T) {
+	client := &mockVaultClient{secrets: map[string]map[string]interface{}{
+		"ci/pipeline": {"config": "{}"},
+	}}
+
+	tests := []struct {
+		name           string
+		path           string
+		factory        VaultClientFactory
+		wantConfigured bool
+		wantErr        error
+		wantErrMsg     string
+	}{
+		{name: "not configured"},
+		{
+			name:           "reachable",
+			path:           "ci/pipeline#config",
+			factory:        mockVaultClientFactory(client, nil),
+			wantConfigured: true,
+		},
+		{
+			name:           "login fails",
+			path:           "ci/pipeline",
+			factory:        mockVaultClientFactory(nil, ErrVaultClientFailed),
+			wantConfigured: true,
+			wantErr:        ErrVaultClientFailed,
+		},
+		{
+			name:           "secret unreadable",
+			path:           "ci/missing",
+			factory:        mockVaultClientFactory(client, nil),
+			wantConfigured: true,
+			wantErr:        ErrVaultSecretNotFound,
+			wantErrMsg:     "at path ci/missing",
+		},
+		{
+			name:           "unreachable",
+			path:           "ci/pipeline",
+			factory:        mockVaultClientFactory(&mockVaultClient{err: errors.New("dial tcp: i/o timeout")}, nil),
+			wantConfigured: true,
+			wantErrMsg:     "dial tcp: i/o timeout",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.path == "" {
+				os.Unsetenv(EnvVaultPipelineConfigPath)
+			} else {
+				t.Setenv(EnvVaultPipelineConfigPath, tt.path)
+			}
+
+			configured, err := CheckVault(context.Background(), tt.factory)
+
+			assert.Equal(t, tt.wantConfigured, configured)
+			if tt.wantErr == nil && tt.wantErrMsg == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+			if tt.wantErrMsg != "" {
+				assert.Contains(t, err.Error(), tt.wantErrMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// HintFile is the repository-relative file holding a correlation ID that
+// overrides resolution. Blank lines and lines starting with "#" are ignored.
+const HintFile = ".slippy-hint"
+
+// EnvHint is the environment variable holding a correlation ID that overrides
+// resolution. It takes precedence over HintFile.
+const EnvHint = "SLIPPY_HINT"
+
+// LoadHint returns the correlation ID hint for the repository at repoPath, from
+// EnvHint or else the first entry of HintFile. Returns "" if neither is set.
+func LoadHint(repoPath string) (string, error) {
+	if hint := strings.TrimSpace(os.Getenv(EnvHint)); hint != "" {
+		return hint, nil
+	}
+
+	file := filepath.Join(repoPath, HintFile)
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	return parseHint(string(data)), nil
+}
+
+// parseHint returns the first non-blank, non-comment line of a hint file.
+func parseHint(content string) string {
+	for line := range strings.Lines(content) {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadHint(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     string
+		content *string
+		want    string
+	}{
+		{name: "nothing set", want: ""},
+		{name: "env only", env: "slip-env", want: "slip-env"},
+		{name: "file only", content: ptr("# incident 42\n\n  slip-file  \nslip-ignored\n"), want: "slip-file"},
+		{name: "env wins over file", env: "slip-env", content: ptr("slip-file\n"), want: "slip-env"},
+		{name: "comments only", content: ptr("# nothing yet\n"), want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvHint, tt.env)
+			dir := t.TempDir()
+			if tt.content != nil {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, HintFile), []byte(*tt.content), 0o600))
+			}
+
+			hint, err := LoadHint(dir)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, hint)
+		})
+	}
+}
+
+func TestLoadHint_Unreadable(t *testing.T) {
+	t.Setenv(EnvHint, "")
+	dir := t.TempDir()
+	// A directory in place of the file cannot be read
+	require.NoError(t, os.Mkdir(filepath.Join(dir, HintFile), 0o700))
+
+	_, err := LoadHint(dir)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read")
+}
+
+func ptr(s string) *string { return &s }
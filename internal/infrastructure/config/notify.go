@@ -0,0 +1,75 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Environment variables configuring failure notifications.
+const (
+	// EnvSlackWebhookURL enables posting resolution failures to this Slack
+	// incoming webhook.
+	EnvSlackWebhookURL = "SLIPPY_SLACK_WEBHOOK_URL"
+	// EnvSlackChannel overrides the webhook's default channel.
+	EnvSlackChannel = "SLIPPY_SLACK_CHANNEL"
+	// EnvTeamsWebhookURL enables posting resolution failures to this
+	// Microsoft Teams incoming webhook.
+	EnvTeamsWebhookURL = "SLIPPY_TEAMS_WEBHOOK_URL"
+	// EnvNotifySecret signs the results --notify-url sends with HMAC-SHA256.
+	EnvNotifySecret = "SLIPPY_NOTIFY_SECRET"
+)
+
+// ErrNotifyInvalid indicates the notification settings are malformed.
+var ErrNotifyInvalid = errors.New("invalid notification configuration")
+
+// NotifyConfig selects where resolution failures are announced.
+// The zero value means notifications are disabled.
+type NotifyConfig struct {
+	// SlackWebhookURL is the Slack incoming webhook; empty disables Slack.
+	SlackWebhookURL string
+	// SlackChannel is the channel to post to, e.g. "#platform-alerts"; empty
+	// uses the webhook's own.
+	SlackChannel string
+	// TeamsWebhookURL is the Teams incoming webhook; empty disables Teams.
+	TeamsWebhookURL string
+}
+
+// Enabled reports whether any notification target is configured.
+func (c NotifyConfig) Enabled() bool {
+	return c.SlackWebhookURL != "" || c.TeamsWebhookURL != ""
+}
+
+// LoadNotify reads the notification settings from the environment.
+func LoadNotify() (NotifyConfig, error) {
+	cfg := NotifyConfig{
+		SlackWebhookURL: strings.TrimSpace(os.Getenv(EnvSlackWebhookURL)),
+		SlackChannel:    strings.TrimSpace(os.Getenv(EnvSlackChannel)),
+		TeamsWebhookURL: strings.TrimSpace(os.Getenv(EnvTeamsWebhookURL)),
+	}
+	for _, webhook := range []struct{ key, url string }{
+		{EnvSlackWebhookURL, cfg.SlackWebhookURL},
+		{EnvTeamsWebhookURL, cfg.TeamsWebhookURL},
+	} {
+		if webhook.url == "" {
+			continue
+		}
+		u, err := url.Parse(webhook.url)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return NotifyConfig{}, fmt.Errorf("%w: %s must be an https URL", ErrNotifyInvalid, webhook.key)
+		}
+	}
+	if cfg.SlackChannel != "" && cfg.SlackWebhookURL == "" {
+		return NotifyConfig{}, fmt.Errorf("%w: %s requires %s",
+			ErrNotifyInvalid, EnvSlackChannel, EnvSlackWebhookURL)
+	}
+	return cfg, nil
+}
+
+// LoadNotifySecret reads the secret --notify-url deliveries are signed with,
+// returning "" when they are sent unsigned.
+func LoadNotifySecret() string {
+	return strings.TrimSpace(os.Getenv(EnvNotifySecret))
+}
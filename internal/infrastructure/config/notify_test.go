@@ -0,0 +1,95 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadNotify(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want NotifyConfig
+	}{
+		{name: "disabled"},
+		{
+			name: "slack",
+			env:  map[string]string{EnvSlackWebhookURL: " https://hooks.slack.com/services/T0/B0/x "},
+			want: NotifyConfig{SlackWebhookURL: "https://hooks.slack.com/services/T0/B0/x"},
+		},
+		{
+			name: "slack channel",
+			env: map[string]string{
+				EnvSlackWebhookURL: "https://hooks.slack.com/services/T0/B0/x",
+				EnvSlackChannel:    "#platform-alerts",
+			},
+			want: NotifyConfig{
+				SlackWebhookURL: "https://hooks.slack.com/services/T0/B0/x",
+				SlackChannel:    "#platform-alerts",
+			},
+		},
+		{
+			name: "teams",
+			env:  map[string]string{EnvTeamsWebhookURL: "https://example.webhook.office.com/webhookb2/x"},
+			want: NotifyConfig{TeamsWebhookURL: "https://example.webhook.office.com/webhookb2/x"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearNotifyEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			cfg, err := LoadNotify()
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, cfg)
+			assert.Equal(t, len(tt.env) > 0, cfg.Enabled())
+		})
+	}
+}
+
+func TestLoadNotify_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+	}{
+		{name: "no scheme", env: map[string]string{EnvSlackWebhookURL: "hooks.slack.com/services/x"}},
+		{name: "plain http", env: map[string]string{EnvSlackWebhookURL: "http://hooks.slack.com/services/x"}},
+		{name: "teams plain http", env: map[string]string{EnvTeamsWebhookURL: "http://example.webhook.office.com/x"}},
+		{name: "channel without webhook", env: map[string]string{EnvSlackChannel: "#platform-alerts"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearNotifyEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			_, err := LoadNotify()
+
+			assert.ErrorIs(t, err, ErrNotifyInvalid)
+		})
+	}
+}
+
+// clearNotifyEnv unsets the notification variables for one test.
+func clearNotifyEnv(t *testing.T) {
+	t.Helper()
+	for _, key := range []string{EnvSlackWebhookURL, EnvSlackChannel, EnvTeamsWebhookURL} {
+		t.Setenv(key, "")
+	}
+}
+
+func TestLoadNotifySecret(t *testing.T) {
+	t.Setenv(EnvNotifySecret, " s3cret\n")
+	assert.Equal(t, "s3cret", LoadNotifySecret())
+
+	t.Setenv(EnvNotifySecret, "")
+	assert.Empty(t, LoadNotifySecret())
+}
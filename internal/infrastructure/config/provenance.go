@@ -0,0 +1,98 @@
+package config
+
+import (
+	"os"
+	"sync"
+)
+
+// Configuration sources reported by provenance tracking.
+const (
+	// SourceDefault means the built-in default value was used.
+	SourceDefault = "default"
+
+	// SourceEnv means the value came from a process environment variable.
+	SourceEnv = "env"
+
+	// SourceFile means the value came from a file (.env or pipeline config JSON).
+	SourceFile = "file"
+
+	// SourceVault means the value came from HashiCorp Vault.
+	SourceVault = "vault"
+
+	// SourceFlag means the value was overridden by a command-line flag.
+	SourceFlag = "flag"
+)
+
+// Setting records one effective configuration value and where it came from.
+type Setting struct {
+	// Key is the stable, dotted name of the setting (e.g. "clickhouse.hostname").
+	Key string
+
+	// Value is the effective value as a string.
+	Value string
+
+	// Source is one of the Source* constants.
+	Source string
+
+	// Detail identifies the concrete origin: the env var name, file path, or Vault path.
+	Detail string
+
+	// Secret marks values that must be redacted when displayed.
+	Secret bool
+}
+
+// dotEnvOrigins maps variables applied by LoadDotEnv or LoadSecretsDir to the
+// file they came from, so provenance can distinguish a file value from a
+// genuinely exported one.
+var (
+	dotEnvOriginsMu sync.RWMutex
+	dotEnvOrigins   = map[string]string{}
+)
+
+// recordDotEnvOrigin marks key as having been set from the .env file at path.
+func recordDotEnvOrigin(key, path string) {
+	dotEnvOriginsMu.Lock()
+	defer dotEnvOriginsMu.Unlock()
+	dotEnvOrigins[key] = path
+}
+
+// envSetting resolves a setting backed by an environment variable with a default.
+func envSetting(key, envVar, defaultValue string, secret bool) Setting {
+	if value, ok := os.LookupEnv(envVar); ok && value != "" {
+		return Setting{Key: key, Value: value, Source: envSource(envVar), Detail: envDetail(envVar), Secret: secret}
+	}
+	return Setting{Key: key, Value: defaultValue, Source: SourceDefault, Secret: secret}
+}
+
+// envSource reports SourceFile for variables applied from .env and SourceEnv otherwise.
+func envSource(envVar string) string {
+	dotEnvOriginsMu.RLock()
+	defer dotEnvOriginsMu.RUnlock()
+	if _, ok := dotEnvOrigins[envVar]; ok {
+		return SourceFile
+	}
+	return SourceEnv
+}
+
+// envDetail names the variable and, for .env values, the file that supplied it.
+func envDetail(envVar string) string {
+	dotEnvOriginsMu.RLock()
+	defer dotEnvOriginsMu.RUnlock()
+	if path, ok := dotEnvOrigins[envVar]; ok {
+		return path + " (" + envVar + ")"
+	}
+	return envVar
+}
+
+// clickHouseSettings reports provenance for the ClickHouse connection settings.
+// Values mirror the environment bindings and defaults used by ch.ClickhouseLoadConfig.
+func clickHouseSettings() []Setting {
+	return []Setting{
+		envSetting("clickhouse.hostname", "CLICKHOUSE_HOSTNAME", "", false),
+		envSetting("clickhouse.port", "CLICKHOUSE_PORT", "9440", false),
+		envSetting("clickhouse.username", "CLICKHOUSE_USERNAME", "", false),
+		envSetting("clickhouse.password", "CLICKHOUSE_PASSWORD", "", true),
+		envSetting("clickhouse.database", "CLICKHOUSE_DATABASE", "", false),
+		envSetting("clickhouse.skip_verify", "CLICKHOUSE_SKIP_VERIFY", "false", false),
+	}
+}
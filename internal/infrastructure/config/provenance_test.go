@@ -0,0 +1,70 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvSetting(t *testing.T) {
+	t.Setenv("SLIPPY_TEST_SETTING", "value")
+	s := envSetting("test.key", "SLIPPY_TEST_SETTING", "fallback", true)
+	assert.Equal(t, Setting{Key: "test.key", Value: "value", Source: SourceEnv, Detail: "SLIPPY_TEST_SETTING", Secret: true}, s)
+
+	t.Setenv("SLIPPY_TEST_SETTING", "")
+	s = envSetting("test.key", "SLIPPY_TEST_SETTING", "fallback", false)
+	assert.Equal(t, Setting{Key: "test.key", Value: "fallback", Source: SourceDefault}, s)
+}
+
+func TestEnvSetting_FromDotEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dev.env")
+	require.NoError(t, os.WriteFile(path, []byte("SLIPPY_TEST_PROVENANCE=from-file\n"), 0o600))
+	t.Setenv(EnvDotEnvFile, path)
+	t.Setenv("SLIPPY_TEST_PROVENANCE", "")
+	require.NoError(t, os.Unsetenv("SLIPPY_TEST_PROVENANCE"))
+
+	_, _, err := LoadDotEnv()
+	require.NoError(t, err)
+
+	s := envSetting("test.key", "SLIPPY_TEST_PROVENANCE", "", false)
+	assert.Equal(t, SourceFile, s.Source)
+	assert.Equal(t, path+" (SLIPPY_TEST_PROVENANCE)", s.Detail)
+}
+
+func TestLoad_Settings(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "pipeline.json")
+	require.NoError(t, os.WriteFile(configPath, []byte(`{"version":"1","name":"test-pipeline","steps":[]}`), 0o644))
+
+	setClickHouseEnvVars(t)
+	t.Setenv(EnvPipelineConfig, configPath)
+	t.Setenv(EnvVaultPipelineConfigPath, "")
+	t.Setenv(EnvDatabase, "")
+	t.Setenv(EnvLogLevel, "debug")
+	t.Setenv(EnvLogFormat, "")
+	t.Setenv(EnvLogFile, "")
+	t.Setenv(EnvAudit, "")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+
+	byKey := map[string]Setting{}
+	for _, s := range cfg.Settings {
+		byKey[s.Key] = s
+	}
+
+	assert.Equal(t, Setting{Key: "database", Value: DefaultDatabase, Source: SourceDefault}, byKey["database"])
+	assert.Equal(t, SourceEnv, byKey["log_level"].Source)
+	assert.Equal(t, "debug", byKey["log_level"].Value)
+	assert.Equal(t, Setting{Key: "log_format", Value: DefaultLogFormat, Source: SourceDefault}, byKey["log_format"])
+	assert.Equal(t, Setting{Key: "log_file", Source: SourceDefault}, byKey["log_file"])
+	assert.Equal(t, Setting{Key: "audit", Value: "false", Source: SourceDefault}, byKey["audit"])
+	assert.Equal(t, Setting{
+		Key: "pipeline_config", Value: "test-pipeline", Source: SourceFile, Detail: configPath,
+	}, byKey["pipeline_config"])
+	assert.True(t, byKey["clickhouse.password"].Secret)
+	assert.Equal(t, "CLICKHOUSE_HOSTNAME", byKey["clickhouse.hostname"].Detail)
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// EnvGitHubEventPath is set by GitHub Actions to the path of the triggering event's JSON payload.
+const EnvGitHubEventPath = "GITHUB_EVENT_PATH"
+
+// ErrNoPullRequestEvent indicates no pull request event payload is available.
+var ErrNoPullRequestEvent = errors.New("no pull request event")
+
+// PullRequestEvent holds the SHAs and refs of a pull request build.
+type PullRequestEvent struct {
+	// HeadSHA is the tip of the pull request branch.
+	HeadSHA string
+	// BaseRef is the branch the pull request targets (e.g., "main").
+	BaseRef string
+	// BaseSHA is the base branch tip when the event was raised.
+	BaseSHA string
+}
+
+// githubEventDocument is the subset of a GitHub Actions event payload used here.
+// pull_request and pull_request_target events share this layout.
+type githubEventDocument struct {
+	PullRequest *struct {
+		Head struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+		Base struct {
+			Ref string `json:"ref"`
+			SHA string `json:"sha"`
+		} `json:"base"`
+	} `json:"pull_request"`
+}
+
+// LoadPullRequestEvent reads the pull request from the event payload named by GITHUB_EVENT_PATH.
+// Returns ErrNoPullRequestEvent if the variable is unset or the event is not a pull request.
+func LoadPullRequestEvent() (*PullRequestEvent, error) {
+	path := os.Getenv(EnvGitHubEventPath)
+	if path == "" {
+		return nil, fmt.Errorf("%w: %s is not set", ErrNoPullRequestEvent, EnvGitHubEventPath)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	event, err := parsePullRequestEvent(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return event, nil
+}
+
+// parsePullRequestEvent decodes a GitHub event payload and extracts its pull request.
+func parsePullRequestEvent(data []byte) (*PullRequestEvent, error) {
+	var doc githubEventDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode event payload: %w", err)
+	}
+	if doc.PullRequest == nil || doc.PullRequest.Head.SHA == "" {
+		return nil, fmt.Errorf("%w: payload has no pull_request.head.sha", ErrNoPullRequestEvent)
+	}
+	return &PullRequestEvent{
+		HeadSHA: doc.PullRequest.Head.SHA,
+		BaseRef: doc.PullRequest.Base.Ref,
+		BaseSHA: doc.PullRequest.Base.SHA,
+	}, nil
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPullRequestEvent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "event.json")
+	content := `{
+  "action": "synchronize",
+  "pull_request": {
+    "number": 42,
+    "head": {"ref": "feature/x", "sha": "aaa111"},
+    "base": {"ref": "main", "sha": "bbb222"}
+  }
+}`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	t.Setenv(EnvGitHubEventPath, path)
+
+	event, err := LoadPullRequestEvent()
+
+	require.NoError(t, err)
+	assert.Equal(t, &PullRequestEvent{HeadSHA: "aaa111", BaseRef: "main", BaseSHA: "bbb222"}, event)
+}
+
+func TestLoadPullRequestEvent_Errors(t *testing.T) {
+	t.Run("variable unset", func(t *testing.T) {
+		t.Setenv(EnvGitHubEventPath, "")
+
+		_, err := LoadPullRequestEvent()
+
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrNoPullRequestEvent)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		t.Setenv(EnvGitHubEventPath, filepath.Join(t.TempDir(), "missing.json"))
+
+		_, err := LoadPullRequestEvent()
+
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrNoPullRequestEvent)
+	})
+}
+
+func TestParsePullRequestEvent_Invalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		wantNoEvent bool
+	}{
+		{name: "malformed json", content: "{"},
+		{name: "push event", content: `{"ref": "refs/heads/main", "after": "abc"}`, wantNoEvent: true},
+		{name: "no head sha", content: `{"pull_request": {"base": {"ref": "main"}}}`, wantNoEvent: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parsePullRequestEvent([]byte(tt.content))
+			require.Error(t, err)
+			assert.Equal(t, tt.wantNoEvent, errors.Is(err, ErrNoPullRequestEvent))
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// EnvChunkSize is the environment variable holding the commits sent in each
+// chunked ancestry query; unset or 0 keeps the resolver's default.
+const EnvChunkSize = "SLIPPY_CLICKHOUSE_CHUNK_SIZE"
+
+// EnvMaxQueryCommits is the environment variable holding the most commits
+// bound into one ClickHouse query; larger sets are queried in batches. Unset
+// or 0 means no cap.
+const EnvMaxQueryCommits = "SLIPPY_CLICKHOUSE_MAX_QUERY_COMMITS"
+
+// EnvCommitList is the environment variable selecting how commit sets are
+// passed to ClickHouse: CommitListArray (the default), CommitListIn or
+// CommitListTemporaryTable.
+const EnvCommitList = "SLIPPY_CLICKHOUSE_COMMIT_LIST"
+
+// Commit list strategies accepted in EnvCommitList.
+const (
+	CommitListArray          = "array"
+	CommitListIn             = "in"
+	CommitListTemporaryTable = "temporary-table"
+)
+
+var (
+	// ErrChunkSizeInvalid indicates SLIPPY_CLICKHOUSE_CHUNK_SIZE is not a
+	// non-negative integer.
+	ErrChunkSizeInvalid = errors.New("invalid chunk size")
+
+	// ErrMaxQueryCommitsInvalid indicates SLIPPY_CLICKHOUSE_MAX_QUERY_COMMITS
+	// is not a non-negative integer.
+	ErrMaxQueryCommitsInvalid = errors.New("invalid query commit limit")
+
+	// ErrCommitListInvalid indicates SLIPPY_CLICKHOUSE_COMMIT_LIST names no
+	// known strategy.
+	ErrCommitListInvalid = errors.New("invalid commit list strategy")
+)
+
+// QueryConfig holds the settings that shape ClickHouse slip queries.
+type QueryConfig struct {
+	// ChunkSize is the commits per chunked ancestry query; zero means the
+	// resolver's default.
+	ChunkSize int
+
+	// MaxQueryCommits is the most commits in one query; zero means no cap.
+	MaxQueryCommits int
+
+	// CommitList is how commits are passed to queries.
+	CommitList string
+}
+
+// LoadQueryConfig reads the query settings from EnvChunkSize,
+// EnvMaxQueryCommits and EnvCommitList.
+func LoadQueryConfig() (QueryConfig, error) {
+	cfg := QueryConfig{CommitList: CommitListArray}
+	var err error
+	if cfg.ChunkSize, err = nonNegativeEnv(EnvChunkSize, ErrChunkSizeInvalid); err != nil {
+		return QueryConfig{}, err
+	}
+	if cfg.MaxQueryCommits, err = nonNegativeEnv(EnvMaxQueryCommits, ErrMaxQueryCommitsInvalid); err != nil {
+		return QueryConfig{}, err
+	}
+	if value := strings.ToLower(strings.TrimSpace(os.Getenv(EnvCommitList))); value != "" {
+		switch value {
+		case CommitListArray, CommitListIn, CommitListTemporaryTable:
+			cfg.CommitList = value
+		default:
+			return QueryConfig{}, fmt.Errorf("%w: %s=%q is not one of %s, %s or %s", ErrCommitListInvalid,
+				EnvCommitList, value, CommitListArray, CommitListIn, CommitListTemporaryTable)
+		}
+	}
+	return cfg, nil
+}
+
+// nonNegativeEnv reads the non-negative integer in the environment variable
+// name, 0 when unset, failing with sentinel otherwise.
+func nonNegativeEnv(name string, sentinel error) (int, error) {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%w: %s=%q is not a non-negative integer", sentinel, name, value)
+	}
+	return n, nil
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadQueryConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		chunkSize  string
+		maxCommits string
+		commitList string
+		want       QueryConfig
+		wantErr    error
+	}{
+		{name: "unset", want: QueryConfig{CommitList: CommitListArray}},
+		{
+			name:       "all set",
+			chunkSize:  "250",
+			maxCommits: " 4000 ",
+			commitList: "Temporary-Table",
+			want:       QueryConfig{ChunkSize: 250, MaxQueryCommits: 4000, CommitList: CommitListTemporaryTable},
+		},
+		{name: "in list", commitList: "in", want: QueryConfig{CommitList: CommitListIn}},
+		{name: "negative chunk size", chunkSize: "-5", wantErr: ErrChunkSizeInvalid},
+		{name: "chunk size not a number", chunkSize: "big", wantErr: ErrChunkSizeInvalid},
+		{name: "max commits not a number", maxCommits: "1e4", wantErr: ErrMaxQueryCommitsInvalid},
+		{name: "unknown commit list", commitList: "join", wantErr: ErrCommitListInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvChunkSize, tt.chunkSize)
+			t.Setenv(EnvMaxQueryCommits, tt.maxCommits)
+			t.Setenv(EnvCommitList, tt.commitList)
+
+			got, err := LoadQueryConfig()
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
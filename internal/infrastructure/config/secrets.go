@@ -0,0 +1,57 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// envName matches file names usable as environment variable names.
+var envName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// LoadSecretsDir loads a mounted secrets directory, such as a Kubernetes
+// secret volume, into the process environment: each file named like an
+// environment variable (e.g. CLICKHOUSE_PASSWORD) sets that variable to its
+// content, without a trailing newline. Like LoadDotEnv, it must run before
+// Load, and variables already in the environment are never overridden.
+//
+// Hidden entries (Kubernetes keeps its ..data bookkeeping there), directories,
+// and other file names are skipped. Returns the keys that were applied.
+func LoadSecretsDir(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets directory: %w", err)
+	}
+
+	var applied []string
+	for _, entry := range entries {
+		key := entry.Name()
+		if strings.HasPrefix(key, ".") || !envName.MatchString(key) {
+			continue
+		}
+		path := filepath.Join(dir, key)
+		// Secret volume entries are symlinks; follow them
+		info, err := os.Stat(path)
+		if err != nil {
+			return applied, fmt.Errorf("failed to read secret %s: %w", key, err)
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+		if _, exists := os.LookupEnv(key); exists {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return applied, fmt.Errorf("failed to read secret %s: %w", key, err)
+		}
+		if err := os.Setenv(key, strings.TrimRight(string(content), "\r\n")); err != nil {
+			return applied, fmt.Errorf("failed to set %s from %s: %w", key, path, err)
+		}
+		recordDotEnvOrigin(key, path)
+		applied = append(applied, key)
+	}
+	return applied, nil
+}
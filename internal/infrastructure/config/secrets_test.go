@@ -0,0 +1,47 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadSecretsDir(t *testing.T) {
+	// Laid out like a Kubernetes secret volume: keys are symlinks into ..data
+	dir := t.TempDir()
+	data := filepath.Join(dir, "..2026_10_16")
+	require.NoError(t, os.Mkdir(data, 0o755))
+	for name, content := range map[string]string{
+		"SLIPPY_TEST_PASSWORD": "hunter2\n",
+		"SLIPPY_TEST_SET":      "from-secret",
+		"not-an-env-name":      "ignored",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(data, name), []byte(content), 0o600))
+		require.NoError(t, os.Symlink(filepath.Join("..data", name), filepath.Join(dir, name)))
+	}
+	require.NoError(t, os.Symlink("..2026_10_16", filepath.Join(dir, "..data")))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "SLIPPY_TEST_DIR"), 0o755))
+
+	t.Setenv("SLIPPY_TEST_SET", "from-shell")
+	t.Setenv("SLIPPY_TEST_PASSWORD", "")
+	require.NoError(t, os.Unsetenv("SLIPPY_TEST_PASSWORD"))
+
+	applied, err := LoadSecretsDir(dir)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"SLIPPY_TEST_PASSWORD"}, applied)
+	assert.Equal(t, "hunter2", os.Getenv("SLIPPY_TEST_PASSWORD"))
+	assert.Equal(t, "from-shell", os.Getenv("SLIPPY_TEST_SET"), "the environment wins")
+	assert.Equal(t, SourceFile, envSource("SLIPPY_TEST_PASSWORD"))
+	assert.Equal(t, filepath.Join(dir, "SLIPPY_TEST_PASSWORD")+" (SLIPPY_TEST_PASSWORD)",
+		envDetail("SLIPPY_TEST_PASSWORD"))
+}
+
+func TestLoadSecretsDir_Missing(t *testing.T) {
+	_, err := LoadSecretsDir(filepath.Join(t.TempDir(), "missing"))
+
+	assert.ErrorContains(t, err, "failed to read secrets directory")
+}
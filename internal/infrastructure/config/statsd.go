@@ -0,0 +1,94 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Environment variables configuring StatsD emission.
+const (
+	// EnvStatsDHost enables StatsD emission to the agent at this host.
+	EnvStatsDHost = "SLIPPY_STATSD_HOST"
+	// EnvStatsDPort is the agent's UDP port (default DefaultStatsDPort).
+	EnvStatsDPort = "SLIPPY_STATSD_PORT"
+	// EnvStatsDPrefix prefixes every metric name (default DefaultStatsDPrefix).
+	EnvStatsDPrefix = "SLIPPY_STATSD_PREFIX"
+	// EnvStatsDFormat is "dogstatsd" (labels as tags, the default) or "statsd"
+	// (labels folded into the metric name).
+	EnvStatsDFormat = "SLIPPY_STATSD_FORMAT"
+)
+
+// StatsD defaults.
+const (
+	DefaultStatsDPort   = 8125
+	DefaultStatsDPrefix = "slippy_find"
+)
+
+// StatsD wire formats.
+const (
+	StatsDFormatDogStatsD = "dogstatsd"
+	StatsDFormatPlain     = "statsd"
+)
+
+// ErrStatsDInvalid indicates the StatsD environment settings are malformed.
+var ErrStatsDInvalid = errors.New("invalid StatsD configuration")
+
+// StatsDConfig addresses a StatsD or DogStatsD agent.
+// The zero value means emission is disabled.
+type StatsDConfig struct {
+	// Addr is the agent's UDP "host:port"; empty when disabled.
+	Addr string
+	// Prefix is prepended to every metric name.
+	Prefix string
+	// DogStatsD sends labels as DogStatsD tags rather than name segments.
+	DogStatsD bool
+}
+
+// Enabled reports whether an agent is configured.
+func (c StatsDConfig) Enabled() bool {
+	return c.Addr != ""
+}
+
+// LoadStatsD reads the StatsD settings from the environment.
+// Returns the zero (disabled) config if EnvStatsDHost is unset.
+func LoadStatsD() (StatsDConfig, error) {
+	host := strings.TrimSpace(os.Getenv(EnvStatsDHost))
+	if host == "" {
+		return StatsDConfig{}, nil
+	}
+
+	port := DefaultStatsDPort
+	if raw := strings.TrimSpace(os.Getenv(EnvStatsDPort)); raw != "" {
+		p, err := strconv.Atoi(raw)
+		if err != nil || p <= 0 || p > 65535 {
+			return StatsDConfig{}, fmt.Errorf("%w: %s=%q is not a port", ErrStatsDInvalid, EnvStatsDPort, raw)
+		}
+		port = p
+	}
+
+	prefix := DefaultStatsDPrefix
+	if raw := strings.TrimSpace(os.Getenv(EnvStatsDPrefix)); raw != "" {
+		prefix = raw
+	}
+
+	var dogStatsD bool
+	switch format := strings.ToLower(strings.TrimSpace(os.Getenv(EnvStatsDFormat))); format {
+	case "", StatsDFormatDogStatsD:
+		dogStatsD = true
+	case StatsDFormatPlain:
+		dogStatsD = false
+	default:
+		return StatsDConfig{}, fmt.Errorf("%w: %s=%q: expected %s or %s",
+			ErrStatsDInvalid, EnvStatsDFormat, format, StatsDFormatDogStatsD, StatsDFormatPlain)
+	}
+
+	return StatsDConfig{
+		Addr:      net.JoinHostPort(host, strconv.Itoa(port)),
+		Prefix:    prefix,
+		DogStatsD: dogStatsD,
+	}, nil
+}
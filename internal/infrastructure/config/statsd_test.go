@@ -0,0 +1,87 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadStatsD(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want StatsDConfig
+	}{
+		{name: "disabled"},
+		{
+			name: "defaults",
+			env:  map[string]string{EnvStatsDHost: "dd-agent"},
+			want: StatsDConfig{Addr: "dd-agent:8125", Prefix: "slippy_find", DogStatsD: true},
+		},
+		{
+			name: "explicit settings",
+			env: map[string]string{
+				EnvStatsDHost:   "10.0.0.5",
+				EnvStatsDPort:   "9125",
+				EnvStatsDPrefix: "ci.slippy",
+				EnvStatsDFormat: "StatsD",
+			},
+			want: StatsDConfig{Addr: "10.0.0.5:9125", Prefix: "ci.slippy"},
+		},
+		{
+			name: "ipv6 host",
+			env:  map[string]string{EnvStatsDHost: "::1"},
+			want: StatsDConfig{Addr: "[::1]:8125", Prefix: "slippy_find", DogStatsD: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearStatsDEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			cfg, err := LoadStatsD()
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, cfg)
+			assert.Equal(t, tt.want.Addr != "", cfg.Enabled())
+		})
+	}
+}
+
+func TestLoadStatsD_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+	}{
+		{name: "non-numeric port", env: map[string]string{EnvStatsDPort: "statsd"}},
+		{name: "port out of range", env: map[string]string{EnvStatsDPort: "70000"}},
+		{name: "unknown format", env: map[string]string{EnvStatsDFormat: "graphite"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearStatsDEnv(t)
+			t.Setenv(EnvStatsDHost, "dd-agent")
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			_, err := LoadStatsD()
+
+			require.Error(t, err)
+			assert.ErrorIs(t, err, ErrStatsDInvalid)
+		})
+	}
+}
+
+// clearStatsDEnv unsets every StatsD variable for the duration of the test.
+func clearStatsDEnv(t *testing.T) {
+	t.Helper()
+	for _, key := range []string{EnvStatsDHost, EnvStatsDPort, EnvStatsDPrefix, EnvStatsDFormat} {
+		t.Setenv(key, "")
+	}
+}
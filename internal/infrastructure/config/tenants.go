@@ -0,0 +1,125 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	ch "github.com/MyCarrier-DevOps/goLibMyCarrier/clickhouse"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrTenantsInvalid indicates the serve-mode tenants file is malformed.
+var ErrTenantsInvalid = errors.New("invalid tenants file")
+
+// tenantName matches valid tenant names, which appear in request paths.
+var tenantName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// TenantStore is the slip store of one tenant.
+type TenantStore struct {
+	ClickHouse *ch.ClickhouseConfig
+	Database   string
+}
+
+// tenantsDocument is the on-disk layout of the tenants file.
+//
+// Example:
+//
+//	tenants:
+//	  payments:
+//	    hostname: ch-payments.internal
+//	    username: slippy
+//	    password_env: PAYMENTS_CLICKHOUSE_PASSWORD
+//	    database: ci
+type tenantsDocument struct {
+	Tenants map[string]tenantEntry `yaml:"tenants"`
+}
+
+// tenantEntry overrides the default store's settings for one tenant. Unset
+// fields keep the default store's values. Passwords are never written in the
+// file: they are read from an environment variable or a file.
+type tenantEntry struct {
+	Hostname     string `yaml:"hostname"`
+	Port         string `yaml:"port"`
+	Username     string `yaml:"username"`
+	PasswordEnv  string `yaml:"password_env"`
+	PasswordFile string `yaml:"password_file"`
+	Database     string `yaml:"database"`
+	SkipVerify   *bool  `yaml:"skip_verify"`
+}
+
+// LoadTenants reads the stores of a multi-tenant server from the YAML file
+// at path. Each tenant starts from the default store (base and database) and
+// overrides the settings it lists. Returns tenant name -> store.
+func LoadTenants(path string, base *ch.ClickhouseConfig, database string) (map[string]TenantStore, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tenants file: %w", err)
+	}
+	var doc tenantsDocument
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrTenantsInvalid, path, err)
+	}
+	if len(doc.Tenants) == 0 {
+		return nil, fmt.Errorf("%w: %s: no tenants", ErrTenantsInvalid, path)
+	}
+
+	stores := make(map[string]TenantStore, len(doc.Tenants))
+	for name, entry := range doc.Tenants {
+		if !tenantName.MatchString(name) {
+			return nil, fmt.Errorf("%w: %s: tenant name %q must be lowercase letters, digits, '-' and '_'",
+				ErrTenantsInvalid, path, name)
+		}
+		store, err := entry.store(base, database)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: tenant %s: %w", ErrTenantsInvalid, path, name, err)
+		}
+		stores[name] = store
+	}
+	return stores, nil
+}
+
+// store applies the entry's overrides to a copy of the default store.
+func (e tenantEntry) store(base *ch.ClickhouseConfig, database string) (TenantStore, error) {
+	cfg := *base
+	if e.Hostname != "" {
+		cfg.ChHostname = e.Hostname
+	}
+	if e.Port != "" {
+		if _, err := strconv.Atoi(e.Port); err != nil {
+			return TenantStore{}, fmt.Errorf("port %q is not a number", e.Port)
+		}
+		cfg.ChPort = e.Port
+	}
+	if e.Username != "" {
+		cfg.ChUsername = e.Username
+	}
+	if e.SkipVerify != nil {
+		cfg.ChSkipVerify = strconv.FormatBool(*e.SkipVerify)
+	}
+	if e.Database != "" {
+		cfg.ChDatabase = e.Database
+		database = e.Database
+	}
+
+	switch {
+	case e.PasswordEnv != "" && e.PasswordFile != "":
+		return TenantStore{}, errors.New("set only one of password_env and password_file")
+	case e.PasswordEnv != "":
+		password, ok := os.LookupEnv(e.PasswordEnv)
+		if !ok {
+			return TenantStore{}, fmt.Errorf("password_env %s is not set", e.PasswordEnv)
+		}
+		cfg.ChPassword = password
+	case e.PasswordFile != "":
+		content, err := os.ReadFile(e.PasswordFile)
+		if err != nil {
+			return TenantStore{}, fmt.Errorf("failed to read password_file: %w", err)
+		}
+		cfg.ChPassword = strings.TrimRight(string(content), "\r\n")
+	}
+	return TenantStore{ClickHouse: &cfg, Database: database}, nil
+}
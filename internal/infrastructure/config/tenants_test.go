@@ -0,0 +1,125 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	ch "github.com/MyCarrier-DevOps/goLibMyCarrier/clickhouse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testBaseStore() *ch.ClickhouseConfig {
+	return &ch.ClickhouseConfig{
+		ChHostname:   "ch.internal",
+		ChPort:       "9440",
+		ChUsername:   "slippy",
+		ChPassword:   "default-secret",
+		ChDatabase:   "ci",
+		ChSkipVerify: "false",
+	}
+}
+
+func TestLoadTenants(t *testing.T) {
+	t.Setenv("PAYMENTS_CLICKHOUSE_PASSWORD", "payments-secret")
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "logistics-password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("logistics-secret\n"), 0o600))
+
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]TenantStore
+		wantErr error
+		wantMsg string
+	}{
+		{
+			name: "overrides and inherits",
+			content: `tenants:
+  payments:
+    hostname: ch-payments.internal
+    password_env: PAYMENTS_CLICKHOUSE_PASSWORD
+    database: payments_ci
+  logistics:
+    port: "9000"
+    username: logistics
+    password_file: ` + passwordFile + `
+    skip_verify: true
+`,
+			want: map[string]TenantStore{
+				"payments": {
+					ClickHouse: &ch.ClickhouseConfig{
+						ChHostname: "ch-payments.internal", ChPort: "9440", ChUsername: "slippy",
+						ChPassword: "payments-secret", ChDatabase: "payments_ci", ChSkipVerify: "false",
+					},
+					Database: "payments_ci",
+				},
+				"logistics": {
+					ClickHouse: &ch.ClickhouseConfig{
+						ChHostname: "ch.internal", ChPort: "9000", ChUsername: "logistics",
+						ChPassword: "logistics-secret", ChDatabase: "ci", ChSkipVerify: "true",
+					},
+					Database: "ci",
+				},
+			},
+		},
+		{name: "not YAML", content: "tenants: [", wantErr: ErrTenantsInvalid},
+		{name: "no tenants", content: "tenants: {}\n", wantErr: ErrTenantsInvalid, wantMsg: "no tenants"},
+		{
+			name:    "invalid name",
+			content: "tenants:\n  Payments/EU: {}\n",
+			wantErr: ErrTenantsInvalid,
+			wantMsg: "must be lowercase",
+		},
+		{
+			name:    "port not a number",
+			content: "tenants:\n  payments:\n    port: https\n",
+			wantErr: ErrTenantsInvalid,
+			wantMsg: "is not a number",
+		},
+		{
+			name:    "both password sources",
+			content: "tenants:\n  payments:\n    password_env: A\n    password_file: b\n",
+			wantErr: ErrTenantsInvalid,
+			wantMsg: "only one of",
+		},
+		{
+			name:    "password variable unset",
+			content: "tenants:\n  payments:\n    password_env: SLIPPY_TEST_UNSET_PASSWORD\n",
+			wantErr: ErrTenantsInvalid,
+			wantMsg: "SLIPPY_TEST_UNSET_PASSWORD is not set",
+		},
+		{
+			name:    "password file missing",
+			content: "tenants:\n  payments:\n    password_file: " + filepath.Join(dir, "missing") + "\n",
+			wantErr: ErrTenantsInvalid,
+			wantMsg: "failed to read password_file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tenants.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+			base := testBaseStore()
+
+			stores, err := LoadTenants(path, base, "ci")
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				assert.Contains(t, err.Error(), tt.wantMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, stores)
+			assert.Equal(t, testBaseStore(), base, "the default store is not modified")
+		})
+	}
+}
+
+func TestLoadTenants_Missing(t *testing.T) {
+	_, err := LoadTenants(filepath.Join(t.TempDir(), "missing"), testBaseStore(), "ci")
+
+	assert.ErrorContains(t, err, "failed to read tenants file")
+}
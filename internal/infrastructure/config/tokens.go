@@ -0,0 +1,64 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ErrTokenFileInvalid indicates the serve-mode token file is malformed.
+var ErrTokenFileInvalid = errors.New("invalid token file")
+
+// LoadServeTokens reads the bearer tokens accepted in serve mode from path.
+// Each line holds a client name and its token separated by whitespace, e.g.
+// "team-payments 9f2c...". Blank lines and lines starting with '#' are
+// ignored. Returns a map of token to client name.
+func LoadServeTokens(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token file: %w", err)
+	}
+	defer func() {
+		// Read-only file; a close failure cannot lose data.
+		_ = f.Close()
+	}()
+
+	tokens, err := parseServeTokens(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return tokens, nil
+}
+
+// parseServeTokens parses the token file format described on LoadServeTokens.
+// Tokens must be unique, since each identifies exactly one client.
+func parseServeTokens(r io.Reader) (map[string]string, error) {
+	tokens := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%w: line %d: want \"<client> <token>\"", ErrTokenFileInvalid, lineNo)
+		}
+		client, token := fields[0], fields[1]
+		if other, ok := tokens[token]; ok {
+			return nil, fmt.Errorf("%w: line %d: token of %s is already used by %s",
+				ErrTokenFileInvalid, lineNo, client, other)
+		}
+		tokens[token] = client
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTokenFileInvalid, err)
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("%w: no tokens", ErrTokenFileInvalid)
+	}
+	return tokens, nil
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseServeTokens(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]string
+		wantMsg string
+	}{
+		{
+			name:    "tokens with comments",
+			content: "# payments rotates quarterly\nteam-payments  tok-1\n\n  team-search\ttok-2\n",
+			want:    map[string]string{"tok-1": "team-payments", "tok-2": "team-search"},
+		},
+		{name: "missing token", content: "team-payments\n", wantMsg: "line 1"},
+		{name: "extra field", content: "team-payments tok-1\nteam-search tok-2 extra\n", wantMsg: "line 2"},
+		{
+			name:    "shared token",
+			content: "team-payments tok-1\nteam-search tok-1\n",
+			wantMsg: "token of team-search is already used by team-payments",
+		},
+		{name: "empty", content: "# nobody yet\n", wantMsg: "no tokens"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := parseServeTokens(strings.NewReader(tt.content))
+
+			if tt.wantMsg != "" {
+				require.ErrorIs(t, err, ErrTokenFileInvalid)
+				assert.Contains(t, err.Error(), tt.wantMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, tokens)
+		})
+	}
+}
+
+func TestLoadServeTokens(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens")
+	require.NoError(t, os.WriteFile(path, []byte("team-payments tok-1\n"), 0o600))
+
+	tokens, err := LoadServeTokens(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"tok-1": "team-payments"}, tokens)
+}
+
+func TestLoadServeTokens_Errors(t *testing.T) {
+	dir := t.TempDir()
+	bad := filepath.Join(dir, "bad")
+	require.NoError(t, os.WriteFile(bad, []byte("tok-1\n"), 0o600))
+
+	_, err := LoadServeTokens(filepath.Join(dir, "missing"))
+	assert.ErrorContains(t, err, "failed to read token file")
+
+	_, err = LoadServeTokens(bad)
+	require.ErrorIs(t, err, ErrTokenFileInvalid)
+	assert.Contains(t, err.Error(), bad)
+}
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// EnvConfigReloadInterval controls how often long-running modes re-read configuration.
+const EnvConfigReloadInterval = "SLIPPY_CONFIG_RELOAD_INTERVAL"
+
+// DefaultConfigReloadInterval is the reload interval used when EnvConfigReloadInterval is unset.
+const DefaultConfigReloadInterval = 30 * time.Second
+
+// ReloadIntervalFromEnv returns the configured reload interval.
+// Unset, unparsable, or non-positive values fall back to DefaultConfigReloadInterval.
+func ReloadIntervalFromEnv() time.Duration {
+	raw := os.Getenv(EnvConfigReloadInterval)
+	if raw == "" {
+		return DefaultConfigReloadInterval
+	}
+	interval, err := time.ParseDuration(raw)
+	if err != nil || interval <= 0 {
+		return DefaultConfigReloadInterval
+	}
+	return interval
+}
+
+// Fingerprint returns a stable hash of the settings that affect the slip store connection,
+// including the store kind and finder plugin.
+// Two configurations with the same fingerprint can share a SlipFinder; a changed
+// fingerprint means the finder must be rebuilt. Logging settings are excluded.
+func Fingerprint(cfg *Config) (string, error) {
+	if cfg == nil {
+		return "", nil
+	}
+
+	h := sha256.New()
+	if cfg.ClickHouse != nil {
+		// Field-by-field so the password is hashed but never serialized anywhere.
+		_, _ = fmt.Fprintf(h, "ch:%q|%q|%q|%q|%q|%q\n",
+			cfg.ClickHouse.ChHostname,
+			cfg.ClickHouse.ChPort,
+			cfg.ClickHouse.ChUsername,
+			cfg.ClickHouse.ChPassword,
+			cfg.ClickHouse.ChDatabase,
+			cfg.ClickHouse.ChSkipVerify,
+		)
+	}
+	_, _ = fmt.Fprintf(h, "db:%q\n", cfg.Database)
+	_, _ = fmt.Fprintf(h, "store:%q|%q\n", cfg.Store, cfg.FinderPlugin)
+
+	if cfg.PipelineConfig != nil {
+		pipeline, err := json.Marshal(cfg.PipelineConfig)
+		if err != nil {
+			return "", fmt.Errorf("failed to fingerprint pipeline config: %w", err)
+		}
+		_, _ = h.Write(pipeline)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Watcher periodically reloads configuration and invokes a callback when it changes.
+// It is intended for long-running modes (serve, watch) where credentials or the
+// pipeline config may rotate underneath the process.
+//
+// Change detection is based on a caller-supplied fingerprint function so the
+// watcher can be used with any configuration representation.
+type Watcher[T any] struct {
+	load        func() (T, error)
+	fingerprint func(T) (string, error)
+	interval    time.Duration
+	onChange    func(T)
+	onError     func(error)
+
+	mu   sync.Mutex
+	last string
+}
+
+// NewWatcher creates a Watcher that calls load every interval.
+// onChange is invoked with the new configuration whenever its fingerprint differs
+// from the previously observed one. onError receives load or fingerprint failures;
+// it may be nil, in which case failures are ignored and the previous config stays active.
+func NewWatcher[T any](
+	load func() (T, error),
+	fingerprint func(T) (string, error),
+	interval time.Duration,
+	onChange func(T),
+	onError func(error),
+) *Watcher[T] {
+	if interval <= 0 {
+		interval = DefaultConfigReloadInterval
+	}
+	return &Watcher[T]{
+		load:        load,
+		fingerprint: fingerprint,
+		interval:    interval,
+		onChange:    onChange,
+		onError:     onError,
+	}
+}
+
+// Prime records the fingerprint of the configuration already in use,
+// so the first poll does not report it as a change.
+func (w *Watcher[T]) Prime(current T) error {
+	fp, err := w.fingerprint(current)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	w.last = fp
+	w.mu.Unlock()
+	return nil
+}
+
+// Run polls until ctx is cancelled.
+func (w *Watcher[T]) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.Check()
+		}
+	}
+}
+
+// Check performs a single reload and reports whether a change was detected.
+func (w *Watcher[T]) Check() bool {
+	cfg, err := w.load()
+	if err != nil {
+		w.reportError(fmt.Errorf("config reload failed: %w", err))
+		return false
+	}
+
+	fp, err := w.fingerprint(cfg)
+	if err != nil {
+		w.reportError(fmt.Errorf("config reload failed: %w", err))
+		return false
+	}
+
+	w.mu.Lock()
+	changed := fp != w.last
+	w.last = fp
+	w.mu.Unlock()
+
+	if changed && w.onChange != nil {
+		w.onChange(cfg)
+	}
+	return changed
+}
+
+func (w *Watcher[T]) reportError(err error) {
+	if w.onError != nil {
+		w.onError(err)
+	}
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	ch "github.com/MyCarrier-DevOps/goLibMyCarrier/clickhouse"
+	"github.com/MyCarrier-DevOps/goLibMyCarrier/slippy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReloadIntervalFromEnv(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{name: "unset", value: "", want: DefaultConfigReloadInterval},
+		{name: "valid", value: "5s", want: 5 * time.Second},
+		{name: "invalid", value: "soon", want: DefaultConfigReloadInterval},
+		{name: "negative", value: "-1s", want: DefaultConfigReloadInterval},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvConfigReloadInterval, tt.value)
+			assert.Equal(t, tt.want, ReloadIntervalFromEnv())
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	base := func() *Config {
+		return &Config{
+			ClickHouse:     &ch.ClickhouseConfig{ChHostname: "ch.local", ChPassword: "secret"},
+			PipelineConfig: &slippy.PipelineConfig{Name: "pipeline"},
+			Database:       "ci",
+			LogLevel:       "info",
+		}
+	}
+
+	fp, err := Fingerprint(base())
+	require.NoError(t, err)
+	assert.Len(t, fp, 64)
+
+	same := base()
+	same.LogLevel = "debug"
+	fpSame, err := Fingerprint(same)
+	require.NoError(t, err)
+	assert.Equal(t, fp, fpSame, "log settings must not affect the fingerprint")
+
+	rotated := base()
+	rotated.ClickHouse.ChPassword = "rotated"
+	fpRotated, err := Fingerprint(rotated)
+	require.NoError(t, err)
+	assert.NotEqual(t, fp, fpRotated)
+
+	pipeline := base()
+	pipeline.PipelineConfig.Name = "other"
+	fpPipeline, err := Fingerprint(pipeline)
+	require.NoError(t, err)
+	assert.NotEqual(t, fp, fpPipeline)
+
+	plugin := base()
+	plugin.FinderPlugin = "/usr/local/bin/finder"
+	fpPlugin, err := Fingerprint(plugin)
+	require.NoError(t, err)
+	assert.NotEqual(t, fp, fpPlugin)
+
+	empty, err := Fingerprint(nil)
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+}
+
+func TestWatcher_Check(t *testing.T) {
+	value := "a"
+	var changes []string
+	var errs []error
+
+	w := NewWatcher(
+		func() (string, error) { return value, nil },
+		func(v string) (string, error) { return v, nil },
+		time.Hour,
+		func(v string) { changes = append(changes, v) },
+		func(err error) { errs = append(errs, err) },
+	)
+	require.NoError(t, w.Prime("a"))
+
+	assert.False(t, w.Check(), "unchanged config is not a change")
+
+	value = "b"
+	assert.True(t, w.Check())
+	assert.False(t, w.Check())
+
+	assert.Equal(t, []string{"b"}, changes)
+	assert.Empty(t, errs)
+}
+
+func TestWatcher_CheckLoadError(t *testing.T) {
+	var errs []error
+	w := NewWatcher(
+		func() (string, error) { return "", errors.New("vault unavailable") },
+		func(v string) (string, error) { return v, nil },
+		time.Hour,
+		func(string) { t.Fatal("onChange must not be called on error") },
+		func(err error) { errs = append(errs, err) },
+	)
+
+	assert.False(t, w.Check())
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "vault unavailable")
+}
+
+func TestWatcher_RunStopsOnCancel(t *testing.T) {
+	calls := make(chan struct{}, 10)
+	w := NewWatcher(
+		func() (int, error) {
+			calls <- struct{}{}
+			return 1, nil
+		},
+		func(int) (string, error) { return "fp", nil },
+		time.Millisecond,
+		nil,
+		nil,
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(done)
+	}()
+
+	<-calls
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("watcher did not stop after cancel")
+	}
+}
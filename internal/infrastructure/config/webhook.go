@@ -0,0 +1,26 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrWebhookSecretEmpty indicates the webhook secret file holds no secret.
+var ErrWebhookSecretEmpty = errors.New("webhook secret file is empty")
+
+// LoadWebhookSecret reads the secret GitHub webhooks are signed with from
+// path. Surrounding whitespace, such as the trailing newline of a mounted
+// Kubernetes secret, is not part of the secret.
+func LoadWebhookSecret(path string) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook secret: %w", err)
+	}
+	secret := bytes.TrimSpace(content)
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("%s: %w", path, ErrWebhookSecretEmpty)
+	}
+	return secret, nil
+}
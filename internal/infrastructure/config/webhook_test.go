@@ -0,0 +1,45 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadWebhookSecret(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr error
+	}{
+		{name: "trailing newline", content: "s3cret\n", want: "s3cret"},
+		{name: "inner whitespace kept", content: "  two words \n", want: "two words"},
+		{name: "empty", content: "\n", wantErr: ErrWebhookSecretEmpty},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "webhook-secret")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o600))
+
+			secret, err := LoadWebhookSecret(path)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(secret))
+		})
+	}
+}
+
+func TestLoadWebhookSecret_Missing(t *testing.T) {
+	_, err := LoadWebhookSecret(filepath.Join(t.TempDir(), "missing"))
+
+	assert.ErrorContains(t, err, "failed to read webhook secret")
+}
@@ -0,0 +1,57 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+const (
+	// EnvOTLPLogsEndpoint enables log export over OTLP/HTTP to the given
+	// endpoint. Headers come from OTEL_EXPORTER_OTLP_LOGS_HEADERS or
+	// OTEL_EXPORTER_OTLP_HEADERS, as for any OTLP exporter.
+	EnvOTLPLogsEndpoint = "OTEL_EXPORTER_OTLP_LOGS_ENDPOINT"
+
+	// EnvLogsExporter set to "otlp" enables log export to EnvOTLPEndpoint,
+	// alongside spans.
+	EnvLogsExporter = "OTEL_LOGS_EXPORTER"
+)
+
+// LogExportEnabled reports whether the environment asks for OTLP log export.
+func LogExportEnabled() bool {
+	return os.Getenv(EnvOTLPLogsEndpoint) != "" ||
+		strings.EqualFold(strings.TrimSpace(os.Getenv(EnvLogsExporter)), "otlp")
+}
+
+// SetupLogging creates a logger provider exporting log records over OTLP when
+// LogExportEnabled. Otherwise it returns a nil provider and a ShutdownFunc
+// that does nothing, and logs only go to stderr (and the log file).
+//
+// Records are batched; the ShutdownFunc flushes them and must be called
+// before exiting, or a short run's logs never leave the process.
+func SetupLogging(ctx context.Context, version string) (log.LoggerProvider, ShutdownFunc, error) {
+	if !LogExportEnabled() {
+		return nil, func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlploghttp.New(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create OTLP log exporter: %w", err)
+	}
+
+	res, err := newResource(version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	provider := sdklog.NewLoggerProvider(
+		sdklog.WithProcessor(sdklog.NewBatchProcessor(exporter)),
+		sdklog.WithResource(res),
+	)
+	return provider, provider.Shutdown, nil
+}
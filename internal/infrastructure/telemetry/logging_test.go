@@ -0,0 +1,55 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+func TestLogExportEnabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		exporter string
+		want     bool
+	}{
+		{name: "unset"},
+		{name: "logs endpoint", endpoint: "http://127.0.0.1:4318", want: true},
+		{name: "otlp exporter", exporter: " OTLP ", want: true},
+		{name: "other exporter", exporter: "none"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvOTLPLogsEndpoint, tt.endpoint)
+			t.Setenv(EnvLogsExporter, tt.exporter)
+
+			assert.Equal(t, tt.want, LogExportEnabled())
+		})
+	}
+}
+
+func TestSetupLogging_Disabled(t *testing.T) {
+	t.Setenv(EnvOTLPLogsEndpoint, "")
+	t.Setenv(EnvLogsExporter, "")
+
+	provider, shutdown, err := SetupLogging(context.Background(), "1.0.0")
+
+	require.NoError(t, err)
+	assert.Nil(t, provider)
+	assert.NoError(t, shutdown(context.Background()))
+}
+
+func TestSetupLogging_Enabled(t *testing.T) {
+	t.Setenv(EnvOTLPLogsEndpoint, "http://127.0.0.1:4318")
+
+	provider, shutdown, err := SetupLogging(context.Background(), "1.0.0")
+
+	require.NoError(t, err)
+	assert.IsType(t, &sdklog.LoggerProvider{}, provider)
+	// Nothing was logged, so shutdown has nothing to export
+	assert.NoError(t, shutdown(context.Background()))
+}
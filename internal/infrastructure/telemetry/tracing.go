@@ -0,0 +1,82 @@
+// Package telemetry configures OpenTelemetry tracing and log export for slippy-find.
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+const (
+	// EnvOTLPEndpoint enables span export over OTLP/HTTP to the given endpoint.
+	// The exporter's other OTEL_EXPORTER_OTLP_* variables are honored as well.
+	EnvOTLPEndpoint = "OTEL_EXPORTER_OTLP_ENDPOINT"
+
+	// EnvTraceParent and EnvTraceState carry the W3C trace context of the
+	// calling CI job, so resolution spans join its trace.
+	EnvTraceParent = "TRACEPARENT"
+	EnvTraceState  = "TRACESTATE"
+
+	// serviceName is reported as the service.name resource attribute.
+	serviceName = "slippy-find"
+)
+
+// ShutdownFunc flushes buffered spans and stops the exporter.
+type ShutdownFunc func(ctx context.Context) error
+
+// SetupTracing installs a global tracer provider exporting spans over OTLP when
+// EnvOTLPEndpoint is set. Otherwise tracing stays disabled (the global no-op
+// provider) and the returned ShutdownFunc does nothing.
+func SetupTracing(ctx context.Context, version string) (ShutdownFunc, error) {
+	if os.Getenv(EnvOTLPEndpoint) == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+
+	res, err := newResource(version)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return provider.Shutdown, nil
+}
+
+// newResource describes this service to the collector.
+func newResource(version string) (*resource.Resource, error) {
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+		semconv.ServiceVersion(version),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build telemetry resource: %w", err)
+	}
+	return res, nil
+}
+
+// ContextFromEnv returns ctx carrying the remote span context from
+// EnvTraceParent and EnvTraceState, or ctx unchanged if they are not set.
+func ContextFromEnv(ctx context.Context) context.Context {
+	carrier := propagation.MapCarrier{
+		"traceparent": os.Getenv(EnvTraceParent),
+		"tracestate":  os.Getenv(EnvTraceState),
+	}
+	return propagation.TraceContext{}.Extract(ctx, carrier)
+}
@@ -0,0 +1,69 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSetupTracing_Disabled(t *testing.T) {
+	t.Setenv(EnvOTLPEndpoint, "")
+	before := otel.GetTracerProvider()
+
+	shutdown, err := SetupTracing(context.Background(), "1.0.0")
+
+	require.NoError(t, err)
+	assert.Same(t, before, otel.GetTracerProvider())
+	assert.NoError(t, shutdown(context.Background()))
+}
+
+func TestSetupTracing_Enabled(t *testing.T) {
+	t.Setenv(EnvOTLPEndpoint, "http://127.0.0.1:4318")
+	before := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(before) })
+
+	shutdown, err := SetupTracing(context.Background(), "1.0.0")
+
+	require.NoError(t, err)
+	assert.IsType(t, &sdktrace.TracerProvider{}, otel.GetTracerProvider())
+	// Nothing was recorded, so shutdown has nothing to export
+	assert.NoError(t, shutdown(context.Background()))
+}
+
+func TestContextFromEnv(t *testing.T) {
+	tests := []struct {
+		name        string
+		traceParent string
+		wantTraceID string
+	}{
+		{
+			name:        "traceparent set",
+			traceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			wantTraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		},
+		{name: "traceparent unset"},
+		{name: "traceparent malformed", traceParent: "not-a-traceparent"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvTraceParent, tt.traceParent)
+			t.Setenv(EnvTraceState, "")
+
+			sc := trace.SpanContextFromContext(ContextFromEnv(context.Background()))
+
+			if tt.wantTraceID == "" {
+				assert.False(t, sc.IsValid())
+				return
+			}
+			require.True(t, sc.IsValid())
+			assert.True(t, sc.IsRemote())
+			assert.Equal(t, tt.wantTraceID, sc.TraceID().String())
+		})
+	}
+}
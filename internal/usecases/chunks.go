@@ -0,0 +1,162 @@
+package usecases
+
+import (
+	"context"
+	"math"
+	"sync"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// chunkWorkers caps the number of chunk queries in flight at once.
+const chunkWorkers = 4
+
+// chunkTally accumulates the ChunkStats of one resolution. It is safe for
+// concurrent use; a nil chunkTally counts nothing.
+type chunkTally struct {
+	mu    sync.Mutex
+	stats domain.ChunkStats
+}
+
+// Stats returns the counts accumulated so far.
+func (t *chunkTally) Stats() domain.ChunkStats {
+	if t == nil {
+		return domain.ChunkStats{}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stats
+}
+
+// counted wraps a chunk query so each call is counted as queried.
+func (t *chunkTally) counted(query func(context.Context, int) (bool, error)) func(context.Context, int) (bool, error) {
+	if t == nil {
+		return query
+	}
+	return func(ctx context.Context, i int) (bool, error) {
+		t.mu.Lock()
+		t.stats.Queried++
+		t.mu.Unlock()
+		return query(ctx, i)
+	}
+}
+
+// settle records the chunks a search needed: through nearest, the index
+// queryChunks returned, or all of the n chunks when it is -1.
+func (t *chunkTally) settle(nearest, n int) {
+	if t == nil {
+		return
+	}
+	if nearest >= 0 {
+		n = nearest + 1
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stats.Needed += n
+}
+
+// splitChunks splits commits into consecutive chunks of at most size.
+func splitChunks(commits []string, size int) [][]string {
+	var chunks [][]string
+	for start := 0; start < len(commits); start += size {
+		chunks = append(chunks, commits[start:min(start+size, len(commits))])
+	}
+	return chunks
+}
+
+// queryChunks calls query(ctx, i) for each chunk index i in [0, n) concurrently,
+// with at most chunkWorkers calls in flight, starting with the nearest chunk.
+// query reports whether chunk i settles the search (e.g. it found a slip).
+//
+// Once chunk i settles the search or fails, no later chunk can change the
+// outcome, so calls for chunks after i are cancelled or never started. Earlier
+// chunks still run to completion, since one of them may settle it first.
+//
+// Returns the lowest index that settled the search or failed, and that call's
+// error; -1 and nil if no chunk settled it. A query must store its result
+// itself (typically by index), and it may be read once queryChunks returns.
+func queryChunks(ctx context.Context, n int, query func(ctx context.Context, i int) (bool, error)) (int, error) {
+	ready := make(chan int, n)
+	for i := range n {
+		ready <- i
+	}
+	close(ready)
+	return queryChunkStream(ctx, ready, query)
+}
+
+// queryChunkStream is queryChunks for chunks that become ready over time, as
+// the ancestry walk reaches them: it receives chunk indexes from ready, in
+// order from 0, until ready is closed or a chunk settles the search. Its caller
+// must make sure the sender does not block once queryChunkStream returns.
+func queryChunkStream(
+	ctx context.Context,
+	ready <-chan int,
+	query func(ctx context.Context, i int) (bool, error),
+) (int, error) {
+	ctx, cancelAll := context.WithCancel(ctx)
+	defer cancelAll()
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		cutoff  = math.MaxInt // chunks at or after cutoff cannot change the outcome
+		cutErr  error
+		cancels []context.CancelFunc
+		slots   = make(chan struct{}, chunkWorkers)
+		decided = make(chan struct{}) // closed once any chunk settles the search or fails
+	)
+
+receive:
+	for {
+		var i int
+		select {
+		case next, ok := <-ready:
+			if !ok {
+				break receive
+			}
+			i = next
+		case <-decided:
+			break receive
+		}
+		slots <- struct{}{}
+
+		mu.Lock()
+		if i >= cutoff {
+			mu.Unlock()
+			<-slots
+			break
+		}
+		chunkCtx, cancel := context.WithCancel(ctx)
+		cancels = append(cancels, cancel)
+		mu.Unlock()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			defer cancel()
+
+			settled, err := query(chunkCtx, i)
+
+			mu.Lock()
+			defer mu.Unlock()
+			// A later chunk's result, or its cancellation error, is moot
+			if i >= cutoff || (!settled && err == nil) {
+				return
+			}
+			if cutoff == math.MaxInt {
+				close(decided)
+			}
+			cutoff, cutErr = i, err
+			for _, c := range cancels[i+1:] {
+				c()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if cutoff == math.MaxInt {
+		return -1, nil
+	}
+	return cutoff, cutErr
+}
@@ -0,0 +1,210 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+func TestSplitChunks(t *testing.T) {
+	commits := make([]string, 2*ancestryChunkSize+1)
+
+	chunks := splitChunks(commits, ancestryChunkSize)
+
+	require.Len(t, chunks, 3)
+	assert.Len(t, chunks[0], ancestryChunkSize)
+	assert.Len(t, chunks[1], ancestryChunkSize)
+	assert.Len(t, chunks[2], 1)
+	assert.Empty(t, splitChunks(nil, ancestryChunkSize))
+}
+
+func TestQueryChunks(t *testing.T) {
+	queryErr := errors.New("query failed")
+
+	tests := []struct {
+		name      string
+		n         int
+		settles   map[int]bool
+		errs      map[int]error
+		wantIndex int
+		wantErr   error
+	}{
+		{name: "no chunks", n: 0, wantIndex: -1},
+		{name: "nothing settles", n: 6, wantIndex: -1},
+		{name: "nearest settling chunk wins", n: 6, settles: map[int]bool{2: true, 4: true}, wantIndex: 2},
+		{
+			name:      "earlier error wins over later match",
+			n:         6,
+			settles:   map[int]bool{3: true},
+			errs:      map[int]error{1: queryErr},
+			wantIndex: 1,
+			wantErr:   queryErr,
+		},
+		{
+			name:      "later error is moot after a match",
+			n:         3,
+			settles:   map[int]bool{0: true},
+			errs:      map[int]error{2: queryErr},
+			wantIndex: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i, err := queryChunks(context.Background(), tt.n, func(_ context.Context, i int) (bool, error) {
+				return tt.settles[i], tt.errs[i]
+			})
+
+			assert.Equal(t, tt.wantIndex, i)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestQueryChunks_WaitsForNearerChunks(t *testing.T) {
+	laterDone := make(chan struct{})
+
+	i, err := queryChunks(context.Background(), 2, func(_ context.Context, i int) (bool, error) {
+		if i == 1 {
+			close(laterDone)
+			return true, nil
+		}
+		// The nearer chunk answers only after the later one has settled
+		<-laterDone
+		return true, nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, i)
+}
+
+func TestQueryChunks_CancelsLaterChunks(t *testing.T) {
+	started := make(chan struct{})
+	var cancelled atomic.Bool
+
+	i, err := queryChunks(context.Background(), 2, func(ctx context.Context, i int) (bool, error) {
+		if i == 1 {
+			close(started)
+			<-ctx.Done()
+			cancelled.Store(true)
+			return false, ctx.Err()
+		}
+		<-started
+		return true, nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, i)
+	assert.True(t, cancelled.Load())
+}
+
+func TestQueryChunks_SkipsChunksAfterMatch(t *testing.T) {
+	var calls atomic.Int32
+
+	i, err := queryChunks(context.Background(), 100, func(ctx context.Context, i int) (bool, error) {
+		calls.Add(1)
+		if i == 0 {
+			return true, nil
+		}
+		<-ctx.Done()
+		return false, ctx.Err()
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, i)
+	assert.Equal(t, int32(chunkWorkers), calls.Load())
+}
+
+func TestQueryChunks_BoundsConcurrency(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		inFlight int
+		peak     int
+	)
+	release := make(chan struct{})
+	go func() {
+		// Let the pool fill before releasing any query
+		for {
+			mu.Lock()
+			full := inFlight == chunkWorkers
+			mu.Unlock()
+			if full {
+				close(release)
+				return
+			}
+			runtime.Gosched()
+		}
+	}()
+
+	i, err := queryChunks(context.Background(), 3*chunkWorkers, func(_ context.Context, _ int) (bool, error) {
+		mu.Lock()
+		inFlight++
+		peak = max(peak, inFlight)
+		mu.Unlock()
+
+		<-release
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		return false, nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, -1, i)
+	assert.Equal(t, chunkWorkers, peak)
+}
+
+func TestQueryChunkStream(t *testing.T) {
+	ready := make(chan int)
+	done := make(chan struct{})
+	go func() {
+		// The walk never ends: the search must settle without ready closing
+		for i := 0; ; i++ {
+			select {
+			case ready <- i:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	i, err := queryChunkStream(context.Background(), ready, func(_ context.Context, i int) (bool, error) {
+		return i == 2, nil
+	})
+	close(done)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, i)
+}
+
+func TestChunkTally(t *testing.T) {
+	tally := &chunkTally{}
+	query := tally.counted(func(context.Context, int) (bool, error) { return false, nil })
+
+	for i := range 3 {
+		_, _ = query(context.Background(), i)
+	}
+	tally.settle(1, 3)
+	tally.settle(-1, 2)
+
+	assert.Equal(t, domain.ChunkStats{Queried: 3, Needed: 4}, tally.Stats())
+
+	var none *chunkTally
+	_, err := none.counted(query)(context.Background(), 0)
+	assert.NoError(t, err)
+	none.settle(0, 1)
+	assert.Zero(t, none.Stats(), "a nil tally counts nothing")
+}
@@ -0,0 +1,103 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// ResolveComponents resolves a slip for each component in components (name ->
+// path prefixes). The path-filtered ancestries of all components are collected
+// in one history walk up front, and each component is then resolved like
+// Resolve with that component set, reusing the walk and the resolver's store.
+// Components are resolved in name order; the first failure is returned,
+// wrapped with the component name.
+func (r *SlipResolver) ResolveComponents(
+	ctx context.Context,
+	input domain.ResolveInput,
+	components map[string][]string,
+) (map[string]*domain.ResolveOutput, error) {
+	depth := input.Depth
+	if depth <= 0 {
+		depth = domain.DefaultAncestryDepth
+	}
+
+	timed, _ := r.instrumented(input)
+	ancestries, err := timed.gitRepo.GetPathAncestries(ctx, components, depth)
+	if err != nil {
+		return nil, domain.WithCode(domain.CodeGit, fmt.Errorf("failed to get commit ancestry: %w", err))
+	}
+
+	gitCtx, err := r.gitContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	walked := &prewalkedRepository{
+		LocalGitRepository: r.gitRepo,
+		gitCtx:             gitCtx,
+		depth:              depth,
+		ancestries:         make(map[string][]string, len(ancestries)),
+	}
+	for name, commits := range ancestries {
+		walked.ancestries[pathsKey(components[name])] = commits
+	}
+	resolver := *r
+	resolver.gitRepo = walked
+
+	results := make(map[string]*domain.ResolveOutput, len(components))
+	for _, name := range slices.Sorted(maps.Keys(components)) {
+		componentInput := input
+		componentInput.Component = name
+		componentInput.ComponentPaths = components[name]
+
+		result, err := resolver.Resolve(ctx, componentInput)
+		if err != nil {
+			return nil, fmt.Errorf("component %s: %w", name, err)
+		}
+		results[name] = result
+	}
+	return results, nil
+}
+
+// prewalkedRepository serves the git context and path-filtered ancestries
+// collected by ResolveComponents, delegating everything else (including
+// deeper walks) to the wrapped repository.
+type prewalkedRepository struct {
+	domain.LocalGitRepository
+
+	gitCtx *domain.GitContext
+
+	// depth is the depth ancestries were walked to.
+	depth int
+
+	// ancestries maps pathsKey(paths) to the commits touching paths.
+	ancestries map[string][]string
+}
+
+// GetGitContext returns the git context read once by ResolveComponents.
+func (p *prewalkedRepository) GetGitContext(_ context.Context) (*domain.GitContext, error) {
+	return p.gitCtx, nil
+}
+
+// GetPathAncestry returns the prewalked ancestry for paths when depth is within
+// the walked depth, and walks the wrapped repository otherwise.
+func (p *prewalkedRepository) GetPathAncestry(ctx context.Context, paths []string, depth int) ([]string, error) {
+	if depth > p.depth {
+		return p.LocalGitRepository.GetPathAncestry(ctx, paths, depth)
+	}
+	commits, ok := p.ancestries[pathsKey(paths)]
+	if !ok {
+		return nil, domain.ErrEmptyAncestry
+	}
+	return commits[:min(depth, len(commits))], nil
+}
+
+// pathsKey identifies a path prefix list as a map key.
+func pathsKey(paths []string) string {
+	return strings.Join(paths, "\x00")
+}
@@ -0,0 +1,122 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+func TestSlipResolver_ResolveComponents(t *testing.T) {
+	components := map[string][]string{
+		"payments-api": {"services/payments"},
+		"web":          {"web"},
+	}
+	apiSlip := &domain.Slip{CorrelationID: "api-slip", Components: []string{"payments-api"}}
+	webSlip := &domain.Slip{CorrelationID: "web-slip", Components: []string{"web"}}
+
+	mockGit := &mockLocalGitRepository{
+		gitContext: &domain.GitContext{HeadSHA: "c0", Branch: "main", Repository: "org/repo"},
+		ancestries: map[string][]string{
+			"payments-api": {"a1", "a2"},
+			"web":          {"w1"},
+		},
+	}
+	mockFinder := &mockSlipFinder{slipsByCommit: map[string]*domain.Slip{"a2": apiSlip, "w1": webSlip}}
+	resolver := NewSlipResolver(mockGit, mockFinder, &mockLogger{})
+
+	results, err := resolver.ResolveComponents(context.Background(), domain.ResolveInput{Depth: 10}, components)
+
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "api-slip", results["payments-api"].CorrelationID)
+	assert.Equal(t, "a2", results["payments-api"].MatchedCommit)
+	assert.Equal(t, "payments-api", results["payments-api"].Component)
+	assert.Equal(t, "web-slip", results["web"].CorrelationID)
+	assert.Equal(t, "web", results["web"].Component)
+
+	assert.Equal(t, 1, mockGit.ancestryWalks, "history should be walked once for all components")
+	assert.Nil(t, mockGit.paths, "per-component walks should be served from the shared walk")
+}
+
+func TestSlipResolver_ResolveComponents_Errors(t *testing.T) {
+	components := map[string][]string{
+		"payments-api": {"services/payments"},
+		"web":          {"web"},
+	}
+	walkErr := errors.New("walk failed")
+
+	tests := []struct {
+		name       string
+		ancestries map[string][]string
+		slips      map[string]*domain.Slip
+		walkErr    error
+		wantErr    error
+		wantMsg    string
+	}{
+		{
+			name:       "component without a slip",
+			ancestries: map[string][]string{"payments-api": {"a1"}, "web": {"w1"}},
+			slips:      map[string]*domain.Slip{"a1": {CorrelationID: "api-slip"}},
+			wantErr:    domain.ErrNoAncestorSlip,
+			wantMsg:    "component web:",
+		},
+		{
+			name:       "component no commit touches",
+			ancestries: map[string][]string{"web": {"w1"}},
+			slips:      map[string]*domain.Slip{"w1": {CorrelationID: "web-slip"}},
+			wantErr:    domain.ErrEmptyAncestry,
+			wantMsg:    "component payments-api:",
+		},
+		{
+			name:    "walk failure",
+			walkErr: walkErr,
+			wantErr: walkErr,
+			wantMsg: "failed to get commit ancestry",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := &mockLocalGitRepository{
+				gitContext: &domain.GitContext{HeadSHA: "c0", Repository: "org/repo"},
+				ancestries: tt.ancestries,
+				commitsErr: tt.walkErr,
+			}
+			mockFinder := &mockSlipFinder{slipsByCommit: tt.slips}
+			resolver := NewSlipResolver(mockGit, mockFinder, &mockLogger{})
+
+			_, err := resolver.ResolveComponents(context.Background(), domain.ResolveInput{}, components)
+
+			require.Error(t, err)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Contains(t, err.Error(), tt.wantMsg)
+		})
+	}
+}
+
+func TestPrewalkedRepository_GetPathAncestry(t *testing.T) {
+	inner := &mockLocalGitRepository{pathCommits: []string{"deep1", "deep2", "deep3"}}
+	walked := &prewalkedRepository{
+		LocalGitRepository: inner,
+		depth:              2,
+		ancestries:         map[string][]string{pathsKey([]string{"web"}): {"w1", "w2"}},
+	}
+
+	commits, err := walked.GetPathAncestry(context.Background(), []string{"web"}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"w1"}, commits)
+	assert.Nil(t, inner.paths)
+
+	_, err = walked.GetPathAncestry(context.Background(), []string{"api"}, 2)
+	assert.ErrorIs(t, err, domain.ErrEmptyAncestry)
+
+	commits, err = walked.GetPathAncestry(context.Background(), []string{"web"}, 8)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"deep1", "deep2", "deep3"}, commits, "deeper walks go to the repository")
+	assert.Equal(t, []string{"web"}, inner.paths)
+}
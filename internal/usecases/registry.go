@@ -0,0 +1,97 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Registry errors.
+var (
+	// ErrDuplicateStrategy indicates a strategy with the same name is already registered.
+	ErrDuplicateStrategy = errors.New("strategy already registered")
+
+	// ErrUnknownStrategy indicates a referenced strategy is not registered.
+	ErrUnknownStrategy = errors.New("strategy not registered")
+)
+
+// StrategyRegistry is the ordered list of strategies a SlipResolver runs.
+// Organizations add custom strategies by registering them, typically relative
+// to a built-in, and passing the registry to NewSlipResolverWithRegistry.
+type StrategyRegistry struct {
+	strategies []Strategy
+}
+
+// NewStrategyRegistry creates an empty registry.
+func NewStrategyRegistry() *StrategyRegistry {
+	return &StrategyRegistry{}
+}
+
+// DefaultStrategyRegistry creates a registry holding the built-in strategies in
+// resolution order: hint, ancestry, pr-head, pr-base, merge-base, branch-latest.
+// Each built-in only runs when the request enables it.
+func DefaultStrategyRegistry() *StrategyRegistry {
+	return &StrategyRegistry{strategies: []Strategy{
+		hintStrategy{},
+		headAncestryStrategy{},
+		prHeadStrategy{},
+		prBaseStrategy{},
+		mergeBaseStrategy{},
+		branchLatestStrategy{},
+	}}
+}
+
+// Register appends s to the end of the pipeline.
+// Returns ErrDuplicateStrategy if its name is taken.
+func (r *StrategyRegistry) Register(s Strategy) error {
+	if r.index(s.Name()) >= 0 {
+		return fmt.Errorf("%w: %s", ErrDuplicateStrategy, s.Name())
+	}
+	r.strategies = append(r.strategies, s)
+	return nil
+}
+
+// RegisterBefore inserts s immediately before the strategy named before.
+// Returns ErrUnknownStrategy if before is not registered and
+// ErrDuplicateStrategy if the name of s is taken.
+func (r *StrategyRegistry) RegisterBefore(before string, s Strategy) error {
+	if r.index(s.Name()) >= 0 {
+		return fmt.Errorf("%w: %s", ErrDuplicateStrategy, s.Name())
+	}
+	i := r.index(before)
+	if i < 0 {
+		return fmt.Errorf("%w: %s", ErrUnknownStrategy, before)
+	}
+	r.strategies = slices.Insert(r.strategies, i, s)
+	return nil
+}
+
+// Remove drops the strategy named name.
+// Returns ErrUnknownStrategy if it is not registered.
+func (r *StrategyRegistry) Remove(name string) error {
+	i := r.index(name)
+	if i < 0 {
+		return fmt.Errorf("%w: %s", ErrUnknownStrategy, name)
+	}
+	r.strategies = slices.Delete(r.strategies, i, i+1)
+	return nil
+}
+
+// Names returns the registered strategy names in resolution order.
+func (r *StrategyRegistry) Names() []string {
+	names := make([]string, 0, len(r.strategies))
+	for _, s := range r.strategies {
+		names = append(names, s.Name())
+	}
+	return names
+}
+
+// Strategies returns a copy of the pipeline in resolution order.
+func (r *StrategyRegistry) Strategies() []Strategy {
+	return slices.Clone(r.strategies)
+}
+
+// index returns the position of the strategy named name, or -1.
+func (r *StrategyRegistry) index(name string) int {
+	return slices.IndexFunc(r.strategies, func(s Strategy) bool { return s.Name() == name })
+}
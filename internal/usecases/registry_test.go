@@ -0,0 +1,68 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// namedStrategy is a Strategy that always returns result.
+type namedStrategy struct {
+	name   string
+	result StrategyResult
+	calls  int
+}
+
+func (s *namedStrategy) Name() string { return s.name }
+
+func (s *namedStrategy) Resolve(_ context.Context, _ *StrategyRequest) (StrategyResult, error) {
+	s.calls++
+	return s.result, nil
+}
+
+func TestDefaultStrategyRegistry(t *testing.T) {
+	assert.Equal(t, []string{
+		domain.ResolvedByHint,
+		domain.ResolvedByAncestry,
+		domain.ResolvedByPRHead,
+		domain.ResolvedByPRBase,
+		domain.ResolvedByMergeBase,
+		domain.ResolvedByBranchLatest,
+	}, DefaultStrategyRegistry().Names())
+}
+
+func TestStrategyRegistry_Register(t *testing.T) {
+	registry := NewStrategyRegistry()
+
+	require.NoError(t, registry.Register(&namedStrategy{name: "a"}))
+	require.NoError(t, registry.Register(&namedStrategy{name: "c"}))
+	require.NoError(t, registry.RegisterBefore("c", &namedStrategy{name: "b"}))
+	assert.Equal(t, []string{"a", "b", "c"}, registry.Names())
+
+	err := registry.Register(&namedStrategy{name: "b"})
+	assert.ErrorIs(t, err, ErrDuplicateStrategy)
+
+	err = registry.RegisterBefore("a", &namedStrategy{name: "c"})
+	assert.ErrorIs(t, err, ErrDuplicateStrategy)
+
+	err = registry.RegisterBefore("missing", &namedStrategy{name: "d"})
+	assert.ErrorIs(t, err, ErrUnknownStrategy)
+
+	require.NoError(t, registry.Remove("b"))
+	assert.Equal(t, []string{"a", "c"}, registry.Names())
+	assert.ErrorIs(t, registry.Remove("b"), ErrUnknownStrategy)
+}
+
+func TestStrategyRegistry_StrategiesIsACopy(t *testing.T) {
+	registry := NewStrategyRegistry()
+	require.NoError(t, registry.Register(&namedStrategy{name: "a"}))
+
+	strategies := registry.Strategies()
+	require.NoError(t, registry.Register(&namedStrategy{name: "b"}))
+
+	assert.Len(t, strategies, 1)
+}
@@ -4,9 +4,15 @@ package usecases
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 
-	"github.com/MyCarrier-DevOps/slippy-find/internal/domain"
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
 )
 
 // Logger defines the logging interface required by the resolver.
@@ -22,32 +28,228 @@ type Logger interface {
 // It implements the core business logic for finding the correlation_id of a slip
 // that matches commits in the local repository's history.
 type SlipResolver struct {
-	gitRepo domain.LocalGitRepository
-	finder  domain.SlipFinder
-	logger  Logger
+	gitRepo    domain.LocalGitRepository
+	finder     domain.SlipFinder
+	logger     Logger
+	strategies []Strategy
+	now        func() time.Time
+
+	// chunks counts the chunked store queries of an instrumented resolution.
+	chunks *chunkTally
+}
+
+// matchCriteria are the per-request rules applied to every store lookup.
+type matchCriteria struct {
+	// policy chooses among several matching slips.
+	policy domain.SelectionPolicy
+
+	// notBefore rejects slips created before it; zero accepts any age.
+	notBefore time.Time
+
+	// component, when set, prefers slips whose aggregate steps include it.
+	component string
+
+	// denied holds correlation IDs that are skipped as if they did not match.
+	denied map[string]struct{}
+
+	// branch, when set, prefers slips recorded for it (branch affinity).
+	branch string
+
+	// searched holds the commits earlier lookups found no slip for; they are
+	// not queried again.
+	searched *searchedCommits
 }
 
 // NewSlipResolver creates a new SlipResolver with the given dependencies.
 // All dependencies are injected to support testing and SOLID principles.
+// It runs the built-in strategies from DefaultStrategyRegistry.
 func NewSlipResolver(
 	gitRepo domain.LocalGitRepository,
 	finder domain.SlipFinder,
 	log Logger,
+) *SlipResolver {
+	return NewSlipResolverWithRegistry(gitRepo, finder, log, DefaultStrategyRegistry())
+}
+
+// NewSlipResolverWithRegistry creates a SlipResolver that runs the strategies in
+// registry, in order. Later changes to registry do not affect the resolver.
+func NewSlipResolverWithRegistry(
+	gitRepo domain.LocalGitRepository,
+	finder domain.SlipFinder,
+	log Logger,
+	registry *StrategyRegistry,
 ) *SlipResolver {
 	return &SlipResolver{
-		gitRepo: gitRepo,
-		finder:  finder,
-		logger:  log,
+		gitRepo:    gitRepo,
+		finder:     finder,
+		logger:     log,
+		strategies: registry.Strategies(),
+		now:        time.Now,
 	}
 }
 
 // Resolve finds the routing slip that matches the local repository's commit ancestry.
-// It walks the commit history from HEAD up to the specified depth and queries
-// the SlipStore to find a matching slip.
+// It runs the resolver's strategies in order and returns the first match. With
+// the built-in strategies, it walks the commit history from HEAD up to the
+// specified depth and queries the SlipStore to find a matching slip. In PR-aware
+// mode the PR head's ancestry is walked instead, then the PR base's. When these
+// miss, the optional fallbacks enabled in input are tried in order: merge-base
+// with the default branch, then the latest slip recorded for the current branch.
 //
-// Returns the ResolveOutput containing the correlation_id and match details,
+// Returns the ResolveOutput containing the correlation_id, match details, and
+// the time spent reading git context, walking ancestry, and querying the store,
 // or an error if no slip is found or an operation fails.
 func (r *SlipResolver) Resolve(ctx context.Context, input domain.ResolveInput) (*domain.ResolveOutput, error) {
+	timed, clock := r.instrumented(input)
+
+	result, err := timed.resolve(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	result.Timings = clock.Timings()
+	result.Chunks = timed.chunks.Stats()
+	return result, nil
+}
+
+// ResolveAll returns every slip matching the HEAD ancestry up to input.Depth,
+// with each candidate's distance from HEAD. Age limits, the deny-list, and the
+// component path filter apply; component preference, selection policy,
+// progressive deepening, and fallbacks do not, since the caller chooses among
+// the results.
+func (r *SlipResolver) ResolveAll(ctx context.Context, input domain.ResolveInput) (*domain.ResolveAllOutput, error) {
+	depth := input.Depth
+	if depth <= 0 {
+		depth = domain.DefaultAncestryDepth
+	}
+	// Timings are not reported here, but slow calls are still logged
+	r, _ = r.instrumented(input)
+
+	gitCtx, err := r.gitContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	commits, err := walkAncestry(ctx, r.gitRepo, depth, input.ComponentPaths)
+	if err != nil {
+		return nil, domain.WithCode(domain.CodeGit, fmt.Errorf("failed to get commit ancestry: %w", err))
+	}
+	commits = dedupeCommits(commits)
+
+	chunks := splitChunks(commits, r.chunkSize())
+	found := make([][]domain.SlipMatch, len(chunks))
+	_, err = queryChunks(ctx, len(chunks), func(ctx context.Context, i int) (bool, error) {
+		var err error
+		found[i], err = r.finder.FindAllByCommits(ctx, gitCtx.Repository, chunks[i])
+		return false, err
+	})
+	if err != nil {
+		return nil, domain.WithCode(domain.CodeStore, fmt.Errorf("failed to find slips by commits: %w", err))
+	}
+	matches := slices.Concat(found...)
+	matches = r.dropStale(ctx, gitCtx, matches, r.ageCutoff(input))
+	matches = r.dropDenied(ctx, gitCtx, matches, denySet(input.DenyList))
+
+	if len(matches) == 0 {
+		return nil, fmt.Errorf(
+			"%w: searched %d commits from %s",
+			domain.ErrNoAncestorSlip,
+			len(commits),
+			gitCtx.HeadSHA,
+		)
+	}
+
+	distance := commitDistances(commits)
+	candidates := make([]domain.Candidate, 0, len(matches))
+	for _, m := range matches {
+		if err := r.checkCorrelationID(ctx, gitCtx, m, input.CorrelationIDFormat); err != nil {
+			return nil, err
+		}
+		d, ok := distance[m.MatchedCommit]
+		if !ok {
+			d = len(commits)
+		}
+		candidates = append(candidates, domain.Candidate{
+			CorrelationID: m.Slip.CorrelationID,
+			MatchedCommit: m.MatchedCommit,
+			Distance:      d,
+			CreatedAt:     m.Slip.CreatedAt,
+			Branch:        m.Slip.Branch,
+		})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Distance != candidates[j].Distance {
+			return candidates[i].Distance < candidates[j].Distance
+		}
+		if !candidates[i].CreatedAt.Equal(candidates[j].CreatedAt) {
+			return candidates[i].CreatedAt.After(candidates[j].CreatedAt)
+		}
+		return candidates[i].CorrelationID < candidates[j].CorrelationID
+	})
+
+	r.logger.Info(ctx, "resolved all candidate slips", map[string]interface{}{
+		"repository":    gitCtx.Repository,
+		"commits_count": len(commits),
+		"candidates":    len(candidates),
+	})
+
+	return &domain.ResolveAllOutput{
+		Repository: gitCtx.Repository,
+		Branch:     gitCtx.Branch,
+		HeadSHA:    gitCtx.HeadSHA,
+		Candidates: candidates,
+	}, nil
+}
+
+// gitContext reads the git context with its repository normalized by
+// domain.ParseRepository, so a malformed name fails before the store is
+// queried instead of matching no slip.
+func (r *SlipResolver) gitContext(ctx context.Context) (*domain.GitContext, error) {
+	gitCtx, err := r.gitRepo.GetGitContext(ctx)
+	if err != nil {
+		return nil, domain.WithCode(domain.CodeGit, fmt.Errorf("failed to get git context: %w", err))
+	}
+	repository, err := domain.ParseRepository(gitCtx.Repository)
+	if err != nil {
+		return nil, err
+	}
+	if repository != gitCtx.Repository {
+		normalized := *gitCtx
+		normalized.Repository = repository
+		gitCtx = &normalized
+	}
+	return gitCtx, nil
+}
+
+// instrumented returns a copy of r whose git and store calls are timed on the
+// returned clock, logged when slower than input.SlowThresholds, and bounded by
+// input.StageTimeouts.
+func (r *SlipResolver) instrumented(input domain.ResolveInput) (*SlipResolver, *stageClock) {
+	depth := input.Depth
+	if depth <= 0 {
+		depth = domain.DefaultAncestryDepth
+	}
+	clock := &stageClock{}
+	slow := &slowWatch{logger: r.logger, thresholds: input.SlowThresholds, depth: depth}
+
+	timed := *r
+	timed.gitRepo = &timedRepository{
+		LocalGitRepository: r.gitRepo,
+		clock:              clock,
+		slow:               slow,
+		budget:             newStageBudget("git", input.StageTimeouts.Git),
+	}
+	timed.finder = &timedFinder{
+		SlipFinder: r.finder,
+		clock:      clock,
+		slow:       slow,
+		budget:     newStageBudget("store", input.StageTimeouts.Store),
+	}
+	timed.chunks = &chunkTally{}
+	return &timed, clock
+}
+
+// resolve implements Resolve.
+func (r *SlipResolver) resolve(ctx context.Context, input domain.ResolveInput) (*domain.ResolveOutput, error) {
 	// Apply default depth if not specified
 	depth := input.Depth
 	if depth <= 0 {
@@ -59,62 +261,551 @@ func (r *SlipResolver) Resolve(ctx context.Context, input domain.ResolveInput) (
 	})
 
 	// Get git context (HEAD SHA, branch, repository name)
-	gitCtx, err := r.gitRepo.GetGitContext(ctx)
+	gitCtx, err := r.gitContext(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get git context: %w", err)
+		return nil, err
 	}
 
 	r.logger.Info(ctx, "extracted git context", map[string]interface{}{
-		"repository":  gitCtx.Repository,
-		"branch":      gitCtx.Branch,
-		"head_sha":    gitCtx.HeadSHA,
-		"is_detached": gitCtx.IsDetached,
+		"repository":     gitCtx.Repository,
+		"branch":         gitCtx.Branch,
+		"default_branch": gitCtx.DefaultBranch,
+		"head_sha":       gitCtx.HeadSHA,
+		"is_detached":    gitCtx.IsDetached,
 	})
 
-	// Get commit ancestry from HEAD
-	commits, err := r.gitRepo.GetCommitAncestry(ctx, depth)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get commit ancestry: %w", err)
+	policy := input.SelectionPolicy
+	if policy == "" {
+		policy = domain.DefaultSelectionPolicy
+	}
+	criteria := matchCriteria{
+		policy:    policy,
+		notBefore: r.ageCutoff(input),
+		component: input.Component,
+		denied:    denySet(input.DenyList),
+		searched:  &searchedCommits{},
+	}
+	if input.BranchAffinity {
+		criteria.branch = gitCtx.Branch
 	}
 
-	r.logger.Debug(ctx, "retrieved commit ancestry", map[string]interface{}{
-		"repository":    gitCtx.Repository,
-		"commits_count": len(commits),
-		"head":          commits[0],
-	})
+	req := &StrategyRequest{
+		Input:    input,
+		GitCtx:   gitCtx,
+		Depth:    depth,
+		Git:      r.gitRepo,
+		Finder:   r.finder,
+		Logger:   r.logger,
+		resolver: r,
+		criteria: criteria,
+	}
 
-	// Find slip matching any commit in ancestry
-	foundSlip, matchedCommit, err := r.finder.FindByCommits(ctx, gitCtx.Repository, commits)
-	if err != nil {
-		return nil, fmt.Errorf("failed to find slip by commits: %w", err)
+	// Run the strategy pipeline until one finds a slip
+	var result StrategyResult
+	var resolvedBy string
+	searchedDepth := 0
+	for _, strategy := range r.strategies {
+		res, err := strategy.Resolve(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		searchedDepth = max(searchedDepth, res.Walked)
+		if res.Match != nil {
+			result, resolvedBy = res, strategy.Name()
+			break
+		}
 	}
+	match := result.Match
 
-	if foundSlip == nil {
+	if match == nil {
+		from := gitCtx.HeadSHA
+		if input.PullRequest != nil {
+			from = input.PullRequest.HeadSHA
+		}
 		r.logger.Warn(ctx, "no slip found in commit ancestry", map[string]interface{}{
 			"repository":    gitCtx.Repository,
-			"commits_count": len(commits),
+			"commits_count": searchedDepth,
 			"head_sha":      gitCtx.HeadSHA,
 		})
 		return nil, fmt.Errorf(
 			"%w: searched %d commits from %s",
 			domain.ErrNoAncestorSlip,
-			len(commits),
-			gitCtx.HeadSHA,
+			searchedDepth,
+			from,
 		)
 	}
 
+	if err := r.checkCorrelationID(ctx, gitCtx, *match, input.CorrelationIDFormat); err != nil {
+		return nil, err
+	}
+
+	if err := checkRequiredSteps(match.Slip, input.RequiredSteps); err != nil {
+		r.logger.Warn(ctx, "resolved slip does not meet step requirements", map[string]interface{}{
+			"correlation_id": match.Slip.CorrelationID,
+			"error":          err.Error(),
+		})
+		return nil, err
+	}
+
+	distance := result.Distance
+	confidence := domain.ClassifyDistance(distance)
+
 	r.logger.Info(ctx, "slip resolved successfully", map[string]interface{}{
-		"correlation_id": foundSlip.CorrelationID,
-		"matched_commit": matchedCommit,
-		"repository":     gitCtx.Repository,
-		"resolved_by":    "ancestry",
+		"correlation_id":   match.Slip.CorrelationID,
+		"matched_commit":   match.MatchedCommit,
+		"repository":       gitCtx.Repository,
+		"resolved_by":      resolvedBy,
+		"selection_policy": string(policy),
+		"candidates":       result.Candidates,
+		"depth":            searchedDepth,
+		"distance":         distance,
+		"confidence":       string(confidence),
 	})
 
+	if input.Annotation != nil {
+		r.annotate(ctx, match.Slip.CorrelationID, input.Annotation)
+	}
+
 	return &domain.ResolveOutput{
-		CorrelationID: foundSlip.CorrelationID,
-		MatchedCommit: matchedCommit,
-		Repository:    gitCtx.Repository,
-		Branch:        gitCtx.Branch,
-		ResolvedBy:    "ancestry",
+		CorrelationID:   match.Slip.CorrelationID,
+		MatchedCommit:   match.MatchedCommit,
+		Repository:      gitCtx.Repository,
+		Branch:          gitCtx.Branch,
+		DefaultBranch:   gitCtx.DefaultBranch,
+		Depth:           searchedDepth,
+		Distance:        distance,
+		Confidence:      confidence,
+		ResolvedBy:      resolvedBy,
+		SelectionPolicy: policy,
+		Candidates:      result.Candidates,
+		Component:       input.Component,
+		Mock:            match.Slip.Mock,
 	}, nil
 }
+
+// checkCorrelationID returns ErrMalformedCorrelationID, naming the matched
+// commit, if match's correlation ID does not have format, so a store
+// returning garbage fails the resolution instead of handing it to a deploy.
+func (r *SlipResolver) checkCorrelationID(
+	ctx context.Context,
+	gitCtx *domain.GitContext,
+	match domain.SlipMatch,
+	format *regexp.Regexp,
+) error {
+	if err := domain.CheckCorrelationID(format, match.Slip.CorrelationID); err != nil {
+		r.logger.Error(ctx, "slip store returned a malformed correlation ID", err, map[string]interface{}{
+			"repository":     gitCtx.Repository,
+			"matched_commit": match.MatchedCommit,
+		})
+		return fmt.Errorf("slip of commit %s: %w", match.MatchedCommit, err)
+	}
+	return nil
+}
+
+// Annotation entries are recorded against this step and actor in the slip's state history.
+const (
+	annotationStep  = "resolve"
+	annotationActor = "slippy-find"
+)
+
+// annotate records the resolution in the slip's state history. Failures are
+// logged, not returned: the slip was resolved and the build may proceed.
+func (r *SlipResolver) annotate(ctx context.Context, correlationID string, annotation *domain.Annotation) {
+	fields := map[string]interface{}{
+		"correlation_id": correlationID,
+		"host":           annotation.Host,
+		"pipeline":       annotation.Pipeline,
+	}
+	annotator, ok := r.finder.(domain.SlipAnnotator)
+	if !ok {
+		fields["error"] = domain.ErrAnnotationUnsupported.Error()
+		r.logger.Warn(ctx, "failed to annotate resolved slip", fields)
+		return
+	}
+
+	err := annotator.AppendHistory(ctx, correlationID, domain.HistoryEntry{
+		Step:      annotationStep,
+		Actor:     annotationActor,
+		Message:   fmt.Sprintf("resolved by slippy-find on host %s for pipeline %s", annotation.Host, annotation.Pipeline),
+		Timestamp: r.now(),
+	})
+	if err != nil {
+		fields["error"] = err.Error()
+		r.logger.Warn(ctx, "failed to annotate resolved slip", fields)
+		return
+	}
+	r.logger.Debug(ctx, "annotated resolved slip", fields)
+}
+
+// ancestryChunkSize caps the number of commits sent in one store query
+// when deepening or collecting every candidate, unless the finder is a
+// domain.ChunkSizer choosing another size.
+const ancestryChunkSize = 100
+
+// chunkSize returns the number of commits to send in each chunked query.
+func (r *SlipResolver) chunkSize() int {
+	if sizer, ok := r.finder.(domain.ChunkSizer); ok {
+		if size := sizer.ChunkSize(); size > 0 {
+			return size
+		}
+	}
+	return ancestryChunkSize
+}
+
+// searchAncestry looks for a slip in commits, the ancestry walked to depth.
+// On a miss with maxDepth above depth, it re-walks the ancestry with walk at
+// DepthGrowthFactor times the previous depth (capped at maxDepth) and queries
+// only the newly reached commits, in concurrent chunks of chunkSize.
+// Deepening stops early once the walk returns fewer commits than requested,
+// since the history is exhausted. Repeated commits in a walk are dropped, so
+// each is queried once and chunks and distances count distinct commits.
+//
+// Returns the match (nil on a miss), the candidate count, and the full list of
+// distinct commits walked so far.
+func (r *SlipResolver) searchAncestry(
+	ctx context.Context,
+	gitCtx *domain.GitContext,
+	commits []string,
+	depth, maxDepth int,
+	walk func(depth int) ([]string, error),
+	criteria matchCriteria,
+) (*domain.SlipMatch, int, []string, error) {
+	walked := len(commits)
+	commits = dedupeCommits(commits)
+
+	// When the nearest chunk with a match decides, a deep ancestry is queried
+	// in chunks too, so the store stops scanning at the nearest match
+	find := r.findMatch
+	if criteria.streamable() && len(commits) > r.chunkSize() {
+		find = r.findMatchInChunks
+	}
+	match, candidates, err := find(ctx, gitCtx, commits, criteria)
+	if err != nil || match != nil {
+		return match, candidates, commits, err
+	}
+
+	for walked >= depth && depth < maxDepth {
+		searched := len(commits)
+		depth = min(depth*domain.DepthGrowthFactor, maxDepth)
+
+		commits, err = walk(depth)
+		if err != nil {
+			return nil, 0, nil, domain.WithCode(domain.CodeGit, fmt.Errorf("failed to get commit ancestry: %w", err))
+		}
+		walked = len(commits)
+		commits = dedupeCommits(commits)
+
+		r.logger.Info(ctx, "no slip found, deepening ancestry search", map[string]interface{}{
+			"repository":  gitCtx.Repository,
+			"depth":       depth,
+			"max_depth":   maxDepth,
+			"new_commits": len(commits) - searched,
+		})
+
+		match, candidates, err = r.findMatchInChunks(ctx, gitCtx, commits[searched:], criteria)
+		if err != nil || match != nil {
+			return match, candidates, commits, err
+		}
+	}
+	return nil, 0, commits, nil
+}
+
+// findMatchInChunks runs findMatch on commits in chunks of chunkSize,
+// querying the chunks concurrently. The nearest chunk with a match decides the
+// result; queries for chunks beyond it are cancelled as soon as it answers.
+func (r *SlipResolver) findMatchInChunks(
+	ctx context.Context,
+	gitCtx *domain.GitContext,
+	commits []string,
+	criteria matchCriteria,
+) (*domain.SlipMatch, int, error) {
+	chunks := splitChunks(commits, r.chunkSize())
+	matches := make([]*domain.SlipMatch, len(chunks))
+	candidates := make([]int, len(chunks))
+
+	nearest, err := queryChunks(ctx, len(chunks), r.chunks.counted(func(ctx context.Context, i int) (bool, error) {
+		match, n, err := r.findMatch(ctx, gitCtx, chunks[i], criteria)
+		matches[i], candidates[i] = match, n
+		return match != nil, err
+	}))
+	r.chunks.settle(nearest, len(chunks))
+	if err != nil || nearest < 0 {
+		return nil, 0, err
+	}
+	return matches[nearest], candidates[nearest], nil
+}
+
+// findMatch queries the store for the commits not searched before and applies
+// the match criteria, recording the commits as searched on a miss.
+// Returns the chosen match (nil if none) and the number of candidates considered.
+func (r *SlipResolver) findMatch(
+	ctx context.Context,
+	gitCtx *domain.GitContext,
+	commits []string,
+	criteria matchCriteria,
+) (*domain.SlipMatch, int, error) {
+	commits = criteria.searched.unsearched(commits)
+	if len(commits) == 0 {
+		return nil, 0, nil
+	}
+	match, candidates, err := r.matchCommits(ctx, gitCtx, commits, criteria)
+	if err == nil && match == nil {
+		criteria.searched.add(commits)
+	}
+	return match, candidates, err
+}
+
+// matchCommits queries the store and applies the match criteria.
+// The nearest-commit policy without an age limit, component, or deny-list only
+// needs the nearest commit's slips, so findNearest looks up just those, unless
+// branch affinity rejects the winner. Otherwise every candidate is fetched,
+// stale and denied slips are dropped, component and branch preferences narrow
+// the rest, and the policy chooses among what remains.
+// Returns the chosen match (nil if none) and the number of candidates considered.
+func (r *SlipResolver) matchCommits(
+	ctx context.Context,
+	gitCtx *domain.GitContext,
+	commits []string,
+	criteria matchCriteria,
+) (*domain.SlipMatch, int, error) {
+	if criteria.policy == domain.SelectionNearestCommit && criteria.notBefore.IsZero() &&
+		criteria.component == "" && len(criteria.denied) == 0 {
+		match, candidates, err := r.findNearest(ctx, gitCtx, commits, criteria.branch)
+		if err != nil || match == nil {
+			return nil, 0, err
+		}
+		// The nearest slip is also the nearest on its own branch
+		if criteria.branch == "" || match.Slip.Branch == criteria.branch {
+			return match, candidates, nil
+		}
+		r.logger.Debug(ctx, "nearest slip is from another branch, checking for one on this branch", map[string]interface{}{
+			"repository":     gitCtx.Repository,
+			"branch":         criteria.branch,
+			"correlation_id": match.Slip.CorrelationID,
+			"slip_branch":    match.Slip.Branch,
+		})
+	}
+
+	matches, err := r.finder.FindAllByCommits(ctx, gitCtx.Repository, commits)
+	if err != nil {
+		return nil, 0, err
+	}
+	matches = r.dropStale(ctx, gitCtx, matches, criteria.notBefore)
+	matches = r.dropDenied(ctx, gitCtx, matches, criteria.denied)
+	if len(matches) == 0 {
+		return nil, 0, nil
+	}
+	matches = preferComponent(matches, criteria.component)
+	matches = preferBranch(matches, criteria.branch)
+
+	selected := selectMatch(matches, commits, criteria.policy, gitCtx.Branch)
+	if len(matches) > 1 {
+		r.logger.Debug(ctx, "multiple slips matched ancestry", map[string]interface{}{
+			"repository":       gitCtx.Repository,
+			"candidates":       len(matches),
+			"selection_policy": string(criteria.policy),
+			"correlation_id":   selected.Slip.CorrelationID,
+			"matched_commit":   selected.MatchedCommit,
+		})
+	}
+	return &selected, len(matches), nil
+}
+
+// findNearest returns the winner among the slips of the nearest commit that
+// has any, and how many there are. A domain.NearestSlipsFinder returns them in
+// one lookup. Otherwise FindByCommits finds the commit and picks one of its
+// slips in store row order, so the commit's other slips are fetched as well.
+// Returns (nil, 0, nil) if no commit has a slip.
+func (r *SlipResolver) findNearest(
+	ctx context.Context,
+	gitCtx *domain.GitContext,
+	commits []string,
+	branch string,
+) (*domain.SlipMatch, int, error) {
+	if finder, ok := r.finder.(domain.NearestSlipsFinder); ok {
+		ties, err := finder.FindNearestByCommits(ctx, gitCtx.Repository, commits)
+		if !errors.Is(err, domain.ErrNearestUnsupported) {
+			if err != nil || len(ties) == 0 {
+				return nil, 0, err
+			}
+			best := r.breakCommitTie(ctx, gitCtx, ties, branch)
+			return &best, len(ties), nil
+		}
+	}
+
+	slip, matchedCommit, err := r.finder.FindByCommits(ctx, gitCtx.Repository, commits)
+	if err != nil || slip == nil {
+		return nil, 0, err
+	}
+	match := domain.SlipMatch{Slip: slip, MatchedCommit: matchedCommit}
+	found, err := r.finder.FindAllByCommits(ctx, gitCtx.Repository, []string{matchedCommit})
+	if err != nil {
+		return nil, 0, err
+	}
+	ties := []domain.SlipMatch{match}
+	for _, m := range found {
+		if m.MatchedCommit == matchedCommit && m.Slip.CorrelationID != slip.CorrelationID {
+			ties = append(ties, m)
+		}
+	}
+	best := r.breakCommitTie(ctx, gitCtx, ties, branch)
+	return &best, len(ties), nil
+}
+
+// breakCommitTie returns the winner among slips recorded for the same commit:
+// slips on branch first (when set), then domain.Slip.Precedes.
+func (r *SlipResolver) breakCommitTie(
+	ctx context.Context,
+	gitCtx *domain.GitContext,
+	ties []domain.SlipMatch,
+	branch string,
+) domain.SlipMatch {
+	if len(ties) == 1 {
+		return ties[0]
+	}
+
+	preferred := preferBranch(ties, branch)
+	best := preferred[0]
+	for _, m := range preferred[1:] {
+		if m.Slip.Precedes(best.Slip) {
+			best = m
+		}
+	}
+	r.logger.Debug(ctx, "multiple slips match the nearest commit", map[string]interface{}{
+		"repository":     gitCtx.Repository,
+		"matched_commit": best.MatchedCommit,
+		"candidates":     len(ties),
+		"correlation_id": best.Slip.CorrelationID,
+	})
+	return best
+}
+
+// dropStale removes matches whose slip was created before notBefore.
+// A zero notBefore keeps every match.
+func (r *SlipResolver) dropStale(
+	ctx context.Context,
+	gitCtx *domain.GitContext,
+	matches []domain.SlipMatch,
+	notBefore time.Time,
+) []domain.SlipMatch {
+	if notBefore.IsZero() {
+		return matches
+	}
+
+	fresh := matches[:0:0]
+	for _, m := range matches {
+		if !m.Slip.CreatedAt.Before(notBefore) {
+			fresh = append(fresh, m)
+		}
+	}
+	if stale := len(matches) - len(fresh); stale > 0 {
+		r.logger.Info(ctx, "ignoring slips older than the age limit", map[string]interface{}{
+			"repository": gitCtx.Repository,
+			"stale":      stale,
+			"not_before": notBefore,
+		})
+	}
+	return fresh
+}
+
+// dropDenied removes matches whose correlation ID is on the deny-list.
+// Each skipped slip is logged so an operator can see why it was passed over.
+func (r *SlipResolver) dropDenied(
+	ctx context.Context,
+	gitCtx *domain.GitContext,
+	matches []domain.SlipMatch,
+	denied map[string]struct{},
+) []domain.SlipMatch {
+	if len(denied) == 0 {
+		return matches
+	}
+
+	allowed := matches[:0:0]
+	for _, m := range matches {
+		if _, ok := denied[m.Slip.CorrelationID]; ok {
+			r.logger.Warn(ctx, "skipping denied slip", map[string]interface{}{
+				"repository":     gitCtx.Repository,
+				"correlation_id": m.Slip.CorrelationID,
+				"matched_commit": m.MatchedCommit,
+			})
+			continue
+		}
+		allowed = append(allowed, m)
+	}
+	return allowed
+}
+
+// denySet indexes a deny-list for lookup. An empty list yields nil.
+func denySet(ids []string) map[string]struct{} {
+	if len(ids) == 0 {
+		return nil
+	}
+	set := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		set[id] = struct{}{}
+	}
+	return set
+}
+
+// preferComponent narrows matches to slips that include component, when any do.
+// If none include it, every match is kept so a slip is still resolved.
+func preferComponent(matches []domain.SlipMatch, component string) []domain.SlipMatch {
+	if component == "" {
+		return matches
+	}
+	return prefer(matches, func(m domain.SlipMatch) bool { return m.Slip.HasComponent(component) })
+}
+
+// preferBranch narrows matches to slips recorded for branch, when any are.
+// If none are, every match is kept so a slip is still resolved.
+func preferBranch(matches []domain.SlipMatch, branch string) []domain.SlipMatch {
+	if branch == "" {
+		return matches
+	}
+	return prefer(matches, func(m domain.SlipMatch) bool { return m.Slip.Branch == branch })
+}
+
+// prefer returns the matches satisfying keep, or all matches if none do.
+func prefer(matches []domain.SlipMatch, keep func(domain.SlipMatch) bool) []domain.SlipMatch {
+	var preferred []domain.SlipMatch
+	for _, m := range matches {
+		if keep(m) {
+			preferred = append(preferred, m)
+		}
+	}
+	if len(preferred) == 0 {
+		return matches
+	}
+	return preferred
+}
+
+// checkRequiredSteps verifies every requirement against the slip's step states.
+// All unmet requirements are reported together, wrapped in ErrStepRequirementUnmet.
+func checkRequiredSteps(slip *domain.Slip, required []domain.StepRequirement) error {
+	var unmet []string
+	for _, req := range required {
+		status, ok := slip.Steps[req.Step]
+		switch {
+		case !ok:
+			unmet = append(unmet, fmt.Sprintf("%s is missing (want %s)", req.Step, req.Status))
+		case status != req.Status:
+			unmet = append(unmet, fmt.Sprintf("%s is %s (want %s)", req.Step, status, req.Status))
+		}
+	}
+	if len(unmet) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: slip %s: %s", domain.ErrStepRequirementUnmet, slip.CorrelationID, strings.Join(unmet, "; "))
+}
+
+// ageCutoff returns the earliest acceptable slip creation time for input,
+// the later of Since and now minus MaxAge. Zero means no limit.
+func (r *SlipResolver) ageCutoff(input domain.ResolveInput) time.Time {
+	cutoff := input.Since
+	if input.MaxAge > 0 {
+		if byAge := r.now().Add(-input.MaxAge); byAge.After(cutoff) {
+			cutoff = byAge
+		}
+	}
+	return cutoff
+}
@@ -3,9 +3,14 @@ package usecases
 import (
 	"context"
 	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sync"
 	"testing"
+	"time"
 
-	"github.com/MyCarrier-DevOps/slippy-find/internal/domain"
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -24,6 +29,17 @@ type mockLocalGitRepository struct {
 	gitContextErr error
 	commits       []string
 	commitsErr    error
+	mergeBase     []string
+	mergeBaseErr  error
+	mergeBranch   string
+	history       []string
+	depths        []int
+	pathCommits   []string
+	paths         []string
+	revAncestry   map[string][]string
+	revs          []string
+	ancestries    map[string][]string
+	ancestryWalks int
 	closeCalled   bool
 }
 
@@ -34,24 +50,83 @@ func (m *mockLocalGitRepository) GetGitContext(_ context.Context) (*domain.GitCo
 	return m.gitContext, nil
 }
 
-func (m *mockLocalGitRepository) GetCommitAncestry(_ context.Context, _ int) ([]string, error) {
+func (m *mockLocalGitRepository) GetCommitAncestry(_ context.Context, depth int) ([]string, error) {
+	m.depths = append(m.depths, depth)
 	if m.commitsErr != nil {
 		return nil, m.commitsErr
 	}
+	if m.history != nil {
+		return m.history[:min(depth, len(m.history))], nil
+	}
 	return m.commits, nil
 }
 
+func (m *mockLocalGitRepository) GetMergeBaseAncestry(_ context.Context, branch string, _ int) ([]string, error) {
+	m.mergeBranch = branch
+	if m.mergeBaseErr != nil {
+		return nil, m.mergeBaseErr
+	}
+	return m.mergeBase, nil
+}
+
+func (m *mockLocalGitRepository) GetPathAncestry(_ context.Context, paths []string, _ int) ([]string, error) {
+	m.paths = paths
+	if len(m.pathCommits) == 0 {
+		return nil, domain.ErrEmptyAncestry
+	}
+	return m.pathCommits, nil
+}
+
+func (m *mockLocalGitRepository) GetPathAncestries(
+	_ context.Context,
+	paths map[string][]string,
+	depth int,
+) (map[string][]string, error) {
+	m.ancestryWalks++
+	if m.commitsErr != nil {
+		return nil, m.commitsErr
+	}
+	ancestries := make(map[string][]string, len(paths))
+	for name := range paths {
+		if commits, ok := m.ancestries[name]; ok {
+			ancestries[name] = commits[:min(depth, len(commits))]
+		}
+	}
+	return ancestries, nil
+}
+
+func (m *mockLocalGitRepository) GetAncestryFrom(_ context.Context, rev string, depth int) ([]string, error) {
+	m.revs = append(m.revs, rev)
+	commits, ok := m.revAncestry[rev]
+	if !ok {
+		return nil, domain.ErrCommitNotFound
+	}
+	return commits[:min(depth, len(commits))], nil
+}
+
 func (m *mockLocalGitRepository) Close() error {
 	m.closeCalled = true
 	return nil
 }
 
 // mockSlipFinder implements domain.SlipFinder for testing.
+// Chunked searches query it concurrently, so calls are serialized by mu.
 type mockSlipFinder struct {
+	mu                  sync.Mutex
 	findByCommitsSlip   *domain.Slip
 	findByCommitsCommit string
 	findByCommitsErr    error
 	findByCommitsCalls  []findByCommitsCall
+	findAllMatches      []domain.SlipMatch
+	findAllErr          error
+	findAllCalls        int
+	slipsByCommit       map[string]*domain.Slip
+	latestSlip          *domain.Slip
+	latestErr           error
+	latestCalls         int
+	latestBranch        string
+	slipsByID           map[string]*domain.Slip
+	findByIDErr         error
 	closeCalled         bool
 }
 
@@ -61,13 +136,59 @@ type findByCommitsCall struct {
 }
 
 func (m *mockSlipFinder) FindByCommits(_ context.Context, repository string, commits []string) (*domain.Slip, string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.findByCommitsCalls = append(m.findByCommitsCalls, findByCommitsCall{
 		repository: repository,
 		commits:    commits,
 	})
+	if m.slipsByCommit != nil {
+		for _, c := range commits {
+			if slip, ok := m.slipsByCommit[c]; ok {
+				return slip, c, nil
+			}
+		}
+		return nil, "", m.findByCommitsErr
+	}
 	return m.findByCommitsSlip, m.findByCommitsCommit, m.findByCommitsErr
 }
 
+func (m *mockSlipFinder) FindAllByCommits(_ context.Context, _ string, commits []string) ([]domain.SlipMatch, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.findAllCalls++
+	if m.slipsByCommit != nil {
+		var matches []domain.SlipMatch
+		for _, c := range commits {
+			if slip, ok := m.slipsByCommit[c]; ok {
+				matches = append(matches, domain.SlipMatch{Slip: slip, MatchedCommit: c})
+			}
+		}
+		return matches, m.findAllErr
+	}
+	return m.findAllMatches, m.findAllErr
+}
+
+func (m *mockSlipFinder) FindLatestByBranch(_ context.Context, _, branch string) (*domain.Slip, error) {
+	m.latestCalls++
+	m.latestBranch = branch
+	if m.latestErr != nil {
+		return nil, m.latestErr
+	}
+	return m.latestSlip, nil
+}
+
+func (m *mockSlipFinder) FindByID(_ context.Context, correlationID string) (*domain.Slip, error) {
+	if m.findByIDErr != nil {
+		return nil, m.findByIDErr
+	}
+	slip, ok := m.slipsByID[correlationID]
+	if !ok {
+		return nil, domain.ErrSlipNotFound
+	}
+	return slip, nil
+}
+
 func (m *mockSlipFinder) Close() error {
 	m.closeCalled = true
 	return nil
@@ -340,3 +461,1609 @@ func TestSlipResolver_Resolve_StoreCalledWithCorrectArgs(t *testing.T) {
 	assert.Equal(t, "MyCarrier-DevOps/test-repo", call.repository)
 	assert.Equal(t, []string{"abc123", "def456", "ghi789"}, call.commits)
 }
+
+func TestSlipResolver_Resolve_SelectionPolicy(t *testing.T) {
+	older := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	matches := []domain.SlipMatch{
+		{Slip: &domain.Slip{CorrelationID: "near-main", Branch: "main", CreatedAt: older}, MatchedCommit: "c1"},
+		{Slip: &domain.Slip{CorrelationID: "far-feature", Branch: "feature/x", CreatedAt: newer}, MatchedCommit: "c3"},
+		{Slip: &domain.Slip{CorrelationID: "mid-feature", Branch: "feature/x", CreatedAt: older}, MatchedCommit: "c2"},
+	}
+
+	tests := []struct {
+		name           string
+		policy         domain.SelectionPolicy
+		wantID         string
+		wantCandidates int
+		wantFastPath   bool
+	}{
+		{
+			name:           "default policy uses the nearest store match",
+			policy:         "",
+			wantID:         "near-main",
+			wantCandidates: 1,
+			wantFastPath:   true,
+		},
+		{
+			name:           "newest-created",
+			policy:         domain.SelectionNewestCreated,
+			wantID:         "far-feature",
+			wantCandidates: 3,
+		},
+		{
+			name:           "branch-preferred",
+			policy:         domain.SelectionBranchPreferred,
+			wantID:         "mid-feature",
+			wantCandidates: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := &mockLocalGitRepository{
+				gitContext: &domain.GitContext{HeadSHA: "c1", Branch: "feature/x", Repository: "org/repo"},
+				commits:    []string{"c1", "c2", "c3"},
+			}
+			mockFinder := &mockSlipFinder{
+				findByCommitsSlip:   matches[0].Slip,
+				findByCommitsCommit: "c1",
+				findAllMatches:      matches,
+			}
+			resolver := NewSlipResolver(mockGit, mockFinder, &mockLogger{})
+
+			output, err := resolver.Resolve(context.Background(), domain.ResolveInput{SelectionPolicy: tt.policy})
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantID, output.CorrelationID)
+			assert.Equal(t, tt.wantCandidates, output.Candidates)
+			assert.Equal(t, 1, mockFinder.findAllCalls)
+			assert.Equal(t, tt.wantFastPath, len(mockFinder.findByCommitsCalls) == 1)
+			if tt.policy == "" {
+				assert.Equal(t, domain.DefaultSelectionPolicy, output.SelectionPolicy)
+			} else {
+				assert.Equal(t, tt.policy, output.SelectionPolicy)
+			}
+		})
+	}
+}
+
+func TestSlipResolver_Resolve_SelectionPolicyNoMatches(t *testing.T) {
+	mockGit := &mockLocalGitRepository{
+		gitContext: &domain.GitContext{HeadSHA: "c1", Repository: "org/repo"},
+		commits:    []string{"c1"},
+	}
+	resolver := NewSlipResolver(mockGit, &mockSlipFinder{}, &mockLogger{})
+
+	_, err := resolver.Resolve(context.Background(), domain.ResolveInput{
+		SelectionPolicy: domain.SelectionNewestCreated,
+	})
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, domain.ErrNoAncestorSlip)
+}
+
+func TestSlipResolver_Resolve_FindAllError(t *testing.T) {
+	mockGit := &mockLocalGitRepository{
+		gitContext: &domain.GitContext{HeadSHA: "c1", Repository: "org/repo"},
+		commits:    []string{"c1"},
+	}
+	mockFinder := &mockSlipFinder{findAllErr: errors.New("query failed")}
+	resolver := NewSlipResolver(mockGit, mockFinder, &mockLogger{})
+
+	_, err := resolver.Resolve(context.Background(), domain.ResolveInput{
+		SelectionPolicy: domain.SelectionBranchPreferred,
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "query failed")
+}
+
+func TestSlipResolver_Resolve_FallbackChain(t *testing.T) {
+	ancestrySlip := &domain.Slip{CorrelationID: "from-ancestry"}
+	mergeBaseSlip := &domain.Slip{CorrelationID: "from-merge-base"}
+	branchSlip := &domain.Slip{CorrelationID: "from-branch", CommitSHA: "b9"}
+
+	tests := []struct {
+		name           string
+		input          domain.ResolveInput
+		branch         string
+		defaultBranch  string
+		slipsByCommit  map[string]*domain.Slip
+		mergeBaseErr   error
+		latestSlip     *domain.Slip
+		latestErr      error
+		wantID         string
+		wantCommit     string
+		wantResolvedBy string
+		wantMergeRef   string
+		wantLatest     int
+		wantLatestRef  string
+		wantErrIs      error
+		wantErrMsg     string
+	}{
+		{
+			name:           "ancestry hit skips fallbacks",
+			input:          domain.ResolveInput{FallbackMergeBase: true, FallbackBranchLatest: true},
+			branch:         "feature/x",
+			slipsByCommit:  map[string]*domain.Slip{"c2": ancestrySlip, "m1": mergeBaseSlip},
+			wantID:         "from-ancestry",
+			wantCommit:     "c2",
+			wantResolvedBy: domain.ResolvedByAncestry,
+		},
+		{
+			name:           "merge-base hit uses default branch",
+			input:          domain.ResolveInput{FallbackMergeBase: true, FallbackBranchLatest: true},
+			branch:         "feature/x",
+			slipsByCommit:  map[string]*domain.Slip{"m1": mergeBaseSlip},
+			latestSlip:     branchSlip,
+			wantID:         "from-merge-base",
+			wantCommit:     "m1",
+			wantResolvedBy: domain.ResolvedByMergeBase,
+			wantMergeRef:   domain.DefaultBranchName,
+		},
+		{
+			name:           "merge-base honours configured default branch",
+			input:          domain.ResolveInput{FallbackMergeBase: true, DefaultBranch: "develop"},
+			branch:         "feature/x",
+			slipsByCommit:  map[string]*domain.Slip{"m2": mergeBaseSlip},
+			wantID:         "from-merge-base",
+			wantCommit:     "m2",
+			wantResolvedBy: domain.ResolvedByMergeBase,
+			wantMergeRef:   "develop",
+		},
+		{
+			name:           "merge-base uses the repository's default branch",
+			input:          domain.ResolveInput{FallbackMergeBase: true},
+			branch:         "feature/x",
+			defaultBranch:  "trunk",
+			slipsByCommit:  map[string]*domain.Slip{"m1": mergeBaseSlip},
+			wantID:         "from-merge-base",
+			wantCommit:     "m1",
+			wantResolvedBy: domain.ResolvedByMergeBase,
+			wantMergeRef:   "trunk",
+		},
+		{
+			name:           "configured default branch overrides the repository's",
+			input:          domain.ResolveInput{FallbackMergeBase: true, DefaultBranch: "develop"},
+			branch:         "feature/x",
+			defaultBranch:  "trunk",
+			slipsByCommit:  map[string]*domain.Slip{"m1": mergeBaseSlip},
+			wantID:         "from-merge-base",
+			wantCommit:     "m1",
+			wantResolvedBy: domain.ResolvedByMergeBase,
+			wantMergeRef:   "develop",
+		},
+		{
+			name:           "merge-base git error falls through to branch-latest",
+			input:          domain.ResolveInput{FallbackMergeBase: true, FallbackBranchLatest: true},
+			branch:         "feature/x",
+			mergeBaseErr:   domain.ErrBranchNotFound,
+			latestSlip:     branchSlip,
+			wantID:         "from-branch",
+			wantCommit:     "b9",
+			wantResolvedBy: domain.ResolvedByBranchLatest,
+			wantMergeRef:   domain.DefaultBranchName,
+			wantLatest:     1,
+		},
+		{
+			name:           "branch-latest only",
+			input:          domain.ResolveInput{FallbackBranchLatest: true},
+			branch:         "feature/x",
+			latestSlip:     branchSlip,
+			wantID:         "from-branch",
+			wantCommit:     "b9",
+			wantResolvedBy: domain.ResolvedByBranchLatest,
+			wantLatest:     1,
+		},
+		{
+			name:       "branch-latest miss returns no ancestor slip",
+			input:      domain.ResolveInput{FallbackBranchLatest: true},
+			branch:     "feature/x",
+			latestErr:  domain.ErrSlipNotFound,
+			wantLatest: 1,
+			wantErrIs:  domain.ErrNoAncestorSlip,
+		},
+		{
+			name:           "branch-latest on detached HEAD uses the default branch",
+			input:          domain.ResolveInput{FallbackBranchLatest: true},
+			defaultBranch:  "trunk",
+			latestSlip:     branchSlip,
+			wantID:         "from-branch",
+			wantCommit:     "b9",
+			wantResolvedBy: domain.ResolvedByBranchLatest,
+			wantLatest:     1,
+			wantLatestRef:  "trunk",
+		},
+		{
+			name:           "branch-latest on detached HEAD assumes main",
+			input:          domain.ResolveInput{FallbackBranchLatest: true},
+			latestSlip:     branchSlip,
+			wantID:         "from-branch",
+			wantCommit:     "b9",
+			wantResolvedBy: domain.ResolvedByBranchLatest,
+			wantLatest:     1,
+			wantLatestRef:  domain.DefaultBranchName,
+		},
+		{
+			name:       "branch-latest store error is returned",
+			input:      domain.ResolveInput{FallbackBranchLatest: true},
+			branch:     "feature/x",
+			latestErr:  errors.New("connection reset"),
+			wantLatest: 1,
+			wantErrMsg: "failed to find latest slip for branch",
+		},
+		{
+			name:          "fallbacks disabled",
+			branch:        "feature/x",
+			slipsByCommit: map[string]*domain.Slip{"m1": mergeBaseSlip},
+			latestSlip:    branchSlip,
+			wantErrIs:     domain.ErrNoAncestorSlip,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := &mockLocalGitRepository{
+				gitContext: &domain.GitContext{
+					HeadSHA: "c1", Branch: tt.branch, DefaultBranch: tt.defaultBranch, Repository: "org/repo",
+				},
+				commits:      []string{"c1", "c2"},
+				mergeBase:    []string{"m1", "m2"},
+				mergeBaseErr: tt.mergeBaseErr,
+			}
+			mockFinder := &mockSlipFinder{
+				slipsByCommit: tt.slipsByCommit,
+				latestSlip:    tt.latestSlip,
+				latestErr:     tt.latestErr,
+			}
+			if mockFinder.slipsByCommit == nil {
+				mockFinder.slipsByCommit = map[string]*domain.Slip{}
+			}
+			resolver := NewSlipResolver(mockGit, mockFinder, &mockLogger{})
+
+			output, err := resolver.Resolve(context.Background(), tt.input)
+
+			assert.Equal(t, tt.wantMergeRef, mockGit.mergeBranch)
+			assert.Equal(t, tt.wantLatest, mockFinder.latestCalls)
+			if tt.wantLatestRef != "" {
+				assert.Equal(t, tt.wantLatestRef, mockFinder.latestBranch)
+			}
+			if tt.wantErrIs != nil || tt.wantErrMsg != "" {
+				require.Error(t, err)
+				if tt.wantErrIs != nil {
+					assert.ErrorIs(t, err, tt.wantErrIs)
+				}
+				if tt.wantErrMsg != "" {
+					assert.Contains(t, err.Error(), tt.wantErrMsg)
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantID, output.CorrelationID)
+			assert.Equal(t, tt.wantCommit, output.MatchedCommit)
+			assert.Equal(t, tt.wantResolvedBy, output.ResolvedBy)
+			assert.Equal(t, tt.defaultBranch, output.DefaultBranch)
+		})
+	}
+}
+
+func TestSlipResolver_Resolve_ChunksDeepAncestry(t *testing.T) {
+	history := make([]string, 400)
+	for i := range history {
+		history[i] = fmt.Sprintf("c%03d", i)
+	}
+	slip := &domain.Slip{CorrelationID: "deep-slip"}
+	tests := []struct {
+		name        string
+		matchAt     string
+		policy      domain.SelectionPolicy
+		wantChunked bool
+		wantNeeded  int
+		wantErr     error
+	}{
+		{name: "match in the nearest chunk", matchAt: "c050", wantChunked: true, wantNeeded: 1},
+		{name: "match in a later chunk", matchAt: "c250", wantChunked: true, wantNeeded: 3},
+		{name: "miss", matchAt: "none", wantChunked: true, wantNeeded: 4, wantErr: domain.ErrNoAncestorSlip},
+		{name: "policy weighing every candidate", matchAt: "c050", policy: domain.SelectionNewestCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := &mockLocalGitRepository{
+				gitContext: &domain.GitContext{HeadSHA: history[0], Repository: "org/repo"},
+				history:    history,
+			}
+			mockFinder := &mockSlipFinder{slipsByCommit: map[string]*domain.Slip{tt.matchAt: slip}}
+
+			output, err := NewSlipResolver(mockGit, mockFinder, &mockLogger{}).Resolve(context.Background(),
+				domain.ResolveInput{Depth: len(history), SelectionPolicy: tt.policy})
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Len(t, mockFinder.findByCommitsCalls, tt.wantNeeded, "a miss queries every chunk")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "deep-slip", output.CorrelationID)
+			if !tt.wantChunked {
+				assert.Equal(t, 1, mockFinder.findAllCalls, "every candidate is fetched in one query")
+				assert.Zero(t, output.Chunks)
+				return
+			}
+			for _, call := range mockFinder.findByCommitsCalls {
+				assert.LessOrEqual(t, len(call.commits), ancestryChunkSize)
+			}
+			assert.Equal(t, tt.wantNeeded, output.Chunks.Needed)
+			assert.GreaterOrEqual(t, output.Chunks.Queried, output.Chunks.Needed)
+			assert.LessOrEqual(t, output.Chunks.Queried, tt.wantNeeded+chunkWorkers-1,
+				"no chunk is started once a nearer one matched")
+		})
+	}
+}
+
+// sizedFinder is a mockSlipFinder that chooses its chunk size.
+type sizedFinder struct {
+	*mockSlipFinder
+	size int
+}
+
+func (f *sizedFinder) ChunkSize() int { return f.size }
+
+func TestSlipResolver_Resolve_FinderChunkSize(t *testing.T) {
+	history := make([]string, 400)
+	for i := range history {
+		history[i] = fmt.Sprintf("c%03d", i)
+	}
+	tests := []struct {
+		name       string
+		size       int
+		wantNeeded int
+	}{
+		{name: "finder size", size: 50, wantNeeded: 6},
+		{name: "zero keeps the default", size: 0, wantNeeded: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := &mockLocalGitRepository{
+				gitContext: &domain.GitContext{HeadSHA: history[0], Repository: "org/repo"},
+				history:    history,
+			}
+			finder := &sizedFinder{
+				mockSlipFinder: &mockSlipFinder{slipsByCommit: map[string]*domain.Slip{
+					"c250": {CorrelationID: "deep-slip"},
+				}},
+				size: tt.size,
+			}
+
+			output, err := NewSlipResolver(mockGit, finder, &mockLogger{}).Resolve(context.Background(),
+				domain.ResolveInput{Depth: len(history)})
+
+			require.NoError(t, err)
+			assert.Equal(t, "deep-slip", output.CorrelationID)
+			assert.Equal(t, tt.wantNeeded, output.Chunks.Needed)
+		})
+	}
+}
+
+func TestSlipResolver_Resolve_ProgressiveDeepening(t *testing.T) {
+	history := make([]string, 500)
+	for i := range history {
+		history[i] = fmt.Sprintf("c%03d", i)
+	}
+	slip := &domain.Slip{CorrelationID: "deep-slip"}
+
+	tests := []struct {
+		name         string
+		history      []string
+		matchAt      string
+		depth        int
+		maxDepth     int
+		wantDepths   []int
+		wantQueries  int
+		wantFound    bool
+		wantSearched int
+	}{
+		{
+			name:         "found after one deepening step",
+			history:      history,
+			matchAt:      "c050",
+			depth:        25,
+			maxDepth:     400,
+			wantDepths:   []int{25, 100},
+			wantQueries:  2,
+			wantFound:    true,
+			wantSearched: 100,
+		},
+		{
+			name:         "deepening capped at max depth and chunked",
+			history:      history,
+			matchAt:      "c350",
+			depth:        25,
+			maxDepth:     400,
+			wantDepths:   []int{25, 100, 400},
+			wantQueries:  5, // 25, then 75 (to 100), then chunks 100-199, 200-299, 300-399
+			wantFound:    true,
+			wantSearched: 400,
+		},
+		{
+			name:         "miss beyond max depth",
+			history:      history,
+			matchAt:      "c450",
+			depth:        25,
+			maxDepth:     60,
+			wantDepths:   []int{25, 60},
+			wantQueries:  2,
+			wantSearched: 60,
+		},
+		{
+			name:         "stops when history is exhausted",
+			history:      history[:30],
+			matchAt:      "none",
+			depth:        25,
+			maxDepth:     400,
+			wantDepths:   []int{25, 100},
+			wantQueries:  2,
+			wantSearched: 30,
+		},
+		{
+			name:         "disabled when max depth not above depth",
+			history:      history,
+			matchAt:      "c050",
+			depth:        25,
+			maxDepth:     25,
+			wantDepths:   []int{25},
+			wantQueries:  1,
+			wantSearched: 25,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := &mockLocalGitRepository{
+				gitContext: &domain.GitContext{HeadSHA: tt.history[0], Repository: "org/repo"},
+				history:    tt.history,
+			}
+			mockFinder := &mockSlipFinder{slipsByCommit: map[string]*domain.Slip{tt.matchAt: slip}}
+			resolver := NewSlipResolver(mockGit, mockFinder, &mockLogger{})
+
+			output, err := resolver.Resolve(context.Background(), domain.ResolveInput{
+				Depth:    tt.depth,
+				MaxDepth: tt.maxDepth,
+			})
+
+			assert.Equal(t, tt.wantDepths, mockGit.depths)
+			assert.Len(t, mockFinder.findByCommitsCalls, tt.wantQueries)
+			for _, call := range mockFinder.findByCommitsCalls {
+				assert.LessOrEqual(t, len(call.commits), ancestryChunkSize)
+			}
+			if !tt.wantFound {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, domain.ErrNoAncestorSlip)
+				assert.Contains(t, err.Error(), fmt.Sprintf("searched %d commits", tt.wantSearched))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "deep-slip", output.CorrelationID)
+			assert.Equal(t, tt.matchAt, output.MatchedCommit)
+			assert.Equal(t, tt.wantSearched, output.Depth)
+			assert.Equal(t, slices.Index(tt.history, tt.matchAt), output.Distance)
+		})
+	}
+}
+
+func TestSlipResolver_Resolve_AgeLimit(t *testing.T) {
+	now := time.Date(2026, 6, 10, 12, 0, 0, 0, time.UTC)
+	fresh := &domain.Slip{CorrelationID: "fresh", CreatedAt: now.Add(-2 * time.Hour)}
+	stale := &domain.Slip{CorrelationID: "stale", CreatedAt: now.Add(-100 * time.Hour)}
+
+	tests := []struct {
+		name          string
+		input         domain.ResolveInput
+		matches       []domain.SlipMatch
+		latestSlip    *domain.Slip
+		wantID        string
+		wantFindAll   int
+		wantNotFound  bool
+		wantNearestOK bool
+	}{
+		{
+			name:  "nearest stale slip is skipped for an older fresh one",
+			input: domain.ResolveInput{MaxAge: 72 * time.Hour},
+			matches: []domain.SlipMatch{
+				{Slip: stale, MatchedCommit: "c1"},
+				{Slip: fresh, MatchedCommit: "c2"},
+			},
+			wantID:      "fresh",
+			wantFindAll: 1,
+		},
+		{
+			name:         "all matches stale",
+			input:        domain.ResolveInput{MaxAge: 72 * time.Hour},
+			matches:      []domain.SlipMatch{{Slip: stale, MatchedCommit: "c1"}},
+			wantFindAll:  1,
+			wantNotFound: true,
+		},
+		{
+			name:         "since rejects slips created earlier",
+			input:        domain.ResolveInput{Since: now.Add(-time.Hour)},
+			matches:      []domain.SlipMatch{{Slip: fresh, MatchedCommit: "c1"}},
+			wantFindAll:  1,
+			wantNotFound: true,
+		},
+		{
+			name:  "later of since and max age wins",
+			input: domain.ResolveInput{MaxAge: time.Hour, Since: now.Add(-200 * time.Hour)},
+			matches: []domain.SlipMatch{
+				{Slip: fresh, MatchedCommit: "c1"},
+			},
+			wantFindAll:  1,
+			wantNotFound: true,
+		},
+		{
+			name:         "stale branch-latest fallback is a miss",
+			input:        domain.ResolveInput{MaxAge: 72 * time.Hour, FallbackBranchLatest: true},
+			latestSlip:   stale,
+			wantFindAll:  1,
+			wantNotFound: true,
+		},
+		{
+			name:          "no limit keeps the cheap nearest-commit lookup",
+			input:         domain.ResolveInput{},
+			wantID:        "stale",
+			wantFindAll:   1, // ties on the nearest commit only
+			wantNearestOK: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := &mockLocalGitRepository{
+				gitContext: &domain.GitContext{HeadSHA: "c1", Branch: "main", Repository: "org/repo"},
+				commits:    []string{"c1", "c2"},
+			}
+			mockFinder := &mockSlipFinder{
+				findByCommitsSlip:   stale,
+				findByCommitsCommit: "c1",
+				findAllMatches:      tt.matches,
+				latestSlip:          tt.latestSlip,
+			}
+			resolver := NewSlipResolver(mockGit, mockFinder, &mockLogger{})
+			resolver.now = func() time.Time { return now }
+
+			output, err := resolver.Resolve(context.Background(), tt.input)
+
+			assert.Equal(t, tt.wantFindAll, mockFinder.findAllCalls)
+			assert.Equal(t, tt.wantNearestOK, len(mockFinder.findByCommitsCalls) == 1)
+			if tt.wantNotFound {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, domain.ErrNoAncestorSlip)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantID, output.CorrelationID)
+		})
+	}
+}
+
+func TestSlipResolver_Resolve_Component(t *testing.T) {
+	withComponent := &domain.Slip{CorrelationID: "with-payments", Components: []string{"orders", "payments-api"}}
+	without := &domain.Slip{CorrelationID: "orders-only", Components: []string{"orders"}}
+
+	tests := []struct {
+		name       string
+		matches    []domain.SlipMatch
+		wantID     string
+		wantCommit string
+	}{
+		{
+			name: "prefers slip including the component over a nearer one",
+			matches: []domain.SlipMatch{
+				{Slip: without, MatchedCommit: "p1"},
+				{Slip: withComponent, MatchedCommit: "p2"},
+			},
+			wantID:     "with-payments",
+			wantCommit: "p2",
+		},
+		{
+			name:       "falls back to nearest when no slip includes the component",
+			matches:    []domain.SlipMatch{{Slip: without, MatchedCommit: "p2"}, {Slip: without, MatchedCommit: "p1"}},
+			wantID:     "orders-only",
+			wantCommit: "p1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := &mockLocalGitRepository{
+				gitContext:  &domain.GitContext{HeadSHA: "c1", Repository: "org/repo"},
+				commits:     []string{"c1", "p1", "c2", "p2"},
+				pathCommits: []string{"p1", "p2"},
+			}
+			mockFinder := &mockSlipFinder{findAllMatches: tt.matches}
+			resolver := NewSlipResolver(mockGit, mockFinder, &mockLogger{})
+
+			output, err := resolver.Resolve(context.Background(), domain.ResolveInput{
+				Component:      "payments-api",
+				ComponentPaths: []string{"services/payments"},
+			})
+
+			require.NoError(t, err)
+			assert.Equal(t, []string{"services/payments"}, mockGit.paths)
+			assert.Empty(t, mockGit.depths, "unfiltered ancestry should not be walked")
+			assert.Equal(t, 1, mockFinder.findAllCalls)
+			assert.Equal(t, tt.wantID, output.CorrelationID)
+			assert.Equal(t, tt.wantCommit, output.MatchedCommit)
+			assert.Equal(t, "payments-api", output.Component)
+		})
+	}
+}
+
+func TestSlipResolver_Resolve_ComponentNoCommits(t *testing.T) {
+	mockGit := &mockLocalGitRepository{
+		gitContext: &domain.GitContext{HeadSHA: "c1", Repository: "org/repo"},
+	}
+	resolver := NewSlipResolver(mockGit, &mockSlipFinder{}, &mockLogger{})
+
+	_, err := resolver.Resolve(context.Background(), domain.ResolveInput{
+		Component:      "payments-api",
+		ComponentPaths: []string{"services/payments"},
+	})
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, domain.ErrEmptyAncestry)
+}
+
+func TestSlipResolver_ResolveAll(t *testing.T) {
+	base := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	mockGit := &mockLocalGitRepository{
+		gitContext: &domain.GitContext{HeadSHA: "c0", Branch: "feature/x", Repository: "org/repo"},
+		commits:    []string{"c0", "c1", "c2", "c3"},
+	}
+	mockFinder := &mockSlipFinder{findAllMatches: []domain.SlipMatch{
+		{Slip: &domain.Slip{CorrelationID: "far-b", CreatedAt: base}, MatchedCommit: "c3"},
+		{Slip: &domain.Slip{CorrelationID: "far", Branch: "main", CreatedAt: base}, MatchedCommit: "c3"},
+		{Slip: &domain.Slip{CorrelationID: "near-old", CreatedAt: base}, MatchedCommit: "c1"},
+		{Slip: &domain.Slip{CorrelationID: "near-new", CreatedAt: base.Add(time.Hour)}, MatchedCommit: "c1"},
+	}}
+	resolver := NewSlipResolver(mockGit, mockFinder, &mockLogger{})
+
+	output, err := resolver.ResolveAll(context.Background(), domain.ResolveInput{Depth: 4})
+
+	require.NoError(t, err)
+	assert.Equal(t, "org/repo", output.Repository)
+	assert.Equal(t, "feature/x", output.Branch)
+	assert.Equal(t, "c0", output.HeadSHA)
+	assert.Equal(t, []domain.Candidate{
+		{CorrelationID: "near-new", MatchedCommit: "c1", Distance: 1, CreatedAt: base.Add(time.Hour)},
+		{CorrelationID: "near-old", MatchedCommit: "c1", Distance: 1, CreatedAt: base},
+		{CorrelationID: "far", MatchedCommit: "c3", Distance: 3, CreatedAt: base, Branch: "main"},
+		{CorrelationID: "far-b", MatchedCommit: "c3", Distance: 3, CreatedAt: base},
+	}, output.Candidates)
+}
+
+func TestSlipResolver_ResolveAll_Errors(t *testing.T) {
+	now := time.Date(2026, 6, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		input     domain.ResolveInput
+		mockGit   *mockLocalGitRepository
+		finder    *mockSlipFinder
+		wantErrIs error
+		wantErr   string
+	}{
+		{
+			name:    "git context error",
+			mockGit: &mockLocalGitRepository{gitContextErr: domain.ErrNoRemoteOrigin},
+			finder:  &mockSlipFinder{},
+			wantErr: "failed to get git context",
+		},
+		{
+			name:      "invalid repository",
+			mockGit:   &mockLocalGitRepository{gitContext: &domain.GitContext{HeadSHA: "c0", Repository: "org/"}},
+			finder:    &mockSlipFinder{},
+			wantErrIs: domain.ErrInvalidRepository,
+			wantErr:   "empty repository name",
+		},
+		{
+			name: "ancestry error",
+			mockGit: &mockLocalGitRepository{
+				gitContext: &domain.GitContext{HeadSHA: "c0", Repository: "org/repo"},
+				commitsErr: errors.New("corrupt object"),
+			},
+			finder:  &mockSlipFinder{},
+			wantErr: "failed to get commit ancestry",
+		},
+		{
+			name:    "store error",
+			mockGit: &mockLocalGitRepository{gitContext: &domain.GitContext{HeadSHA: "c0", Repository: "org/repo"}, commits: []string{"c0"}},
+			finder:  &mockSlipFinder{findAllErr: errors.New("query failed")},
+			wantErr: "failed to find slips by commits",
+		},
+		{
+			name:      "no matches",
+			mockGit:   &mockLocalGitRepository{gitContext: &domain.GitContext{HeadSHA: "c0", Repository: "org/repo"}, commits: []string{"c0"}},
+			finder:    &mockSlipFinder{},
+			wantErrIs: domain.ErrNoAncestorSlip,
+		},
+		{
+			name:    "only stale matches",
+			input:   domain.ResolveInput{MaxAge: time.Hour},
+			mockGit: &mockLocalGitRepository{gitContext: &domain.GitContext{HeadSHA: "c0", Repository: "org/repo"}, commits: []string{"c0"}},
+			finder: &mockSlipFinder{findAllMatches: []domain.SlipMatch{
+				{Slip: &domain.Slip{CorrelationID: "old", CreatedAt: now.Add(-48 * time.Hour)}, MatchedCommit: "c0"},
+			}},
+			wantErrIs: domain.ErrNoAncestorSlip,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := NewSlipResolver(tt.mockGit, tt.finder, &mockLogger{})
+			resolver.now = func() time.Time { return now }
+
+			output, err := resolver.ResolveAll(context.Background(), tt.input)
+
+			require.Error(t, err)
+			assert.Nil(t, output)
+			if tt.wantErrIs != nil {
+				assert.ErrorIs(t, err, tt.wantErrIs)
+			}
+			if tt.wantErr != "" {
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSlipResolver_Resolve_DistanceAndConfidence(t *testing.T) {
+	commits := []string{"c0", "c1", "c2", "c3", "c4", "c5", "c6", "c7"}
+
+	tests := []struct {
+		name           string
+		matchAt        string
+		fallback       bool
+		wantDistance   int
+		wantConfidence domain.Confidence
+	}{
+		{name: "HEAD", matchAt: "c0", wantDistance: 0, wantConfidence: domain.ConfidenceExactHead},
+		{name: "one back", matchAt: "c1", wantDistance: 1, wantConfidence: domain.ConfidenceNearAncestor},
+		{
+			name:           "at near threshold",
+			matchAt:        "c5",
+			wantDistance:   domain.NearAncestorMaxDistance,
+			wantConfidence: domain.ConfidenceNearAncestor,
+		},
+		{name: "past threshold", matchAt: "c6", wantDistance: 6, wantConfidence: domain.ConfidenceDeepAncestor},
+		{name: "fallback", fallback: true, wantDistance: -1, wantConfidence: domain.ConfidenceDeepAncestor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := &mockLocalGitRepository{
+				gitContext: &domain.GitContext{HeadSHA: "c0", Branch: "main", Repository: "org/repo"},
+				commits:    commits,
+			}
+			mockFinder := &mockSlipFinder{
+				slipsByCommit: map[string]*domain.Slip{},
+				latestSlip:    &domain.Slip{CorrelationID: "latest", CommitSHA: "x9"},
+			}
+			if tt.matchAt != "" {
+				mockFinder.slipsByCommit[tt.matchAt] = &domain.Slip{CorrelationID: "slip"}
+			}
+			resolver := NewSlipResolver(mockGit, mockFinder, &mockLogger{})
+
+			output, err := resolver.Resolve(context.Background(), domain.ResolveInput{
+				FallbackBranchLatest: tt.fallback,
+			})
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantDistance, output.Distance)
+			assert.Equal(t, tt.wantConfidence, output.Confidence)
+		})
+	}
+}
+
+func TestSlipResolver_Resolve_RequiredSteps(t *testing.T) {
+	steps := map[string]string{"push_parsed": "completed", "builds": "running"}
+
+	tests := []struct {
+		name      string
+		required  []domain.StepRequirement
+		wantErr   bool
+		wantInErr []string
+	}{
+		{name: "no requirements"},
+		{
+			name:     "requirement met",
+			required: []domain.StepRequirement{{Step: "push_parsed", Status: "completed"}},
+		},
+		{
+			name: "all unmet requirements reported",
+			required: []domain.StepRequirement{
+				{Step: "push_parsed", Status: "completed"},
+				{Step: "builds", Status: "completed"},
+				{Step: "dev_deploy", Status: "completed"},
+			},
+			wantErr: true,
+			wantInErr: []string{
+				"slip slip-1",
+				"builds is running (want completed)",
+				"dev_deploy is missing (want completed)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := &mockLocalGitRepository{
+				gitContext: &domain.GitContext{HeadSHA: "c0", Branch: "main", Repository: "org/repo"},
+				commits:    []string{"c0", "c1"},
+			}
+			mockFinder := &mockSlipFinder{slipsByCommit: map[string]*domain.Slip{
+				"c1": {CorrelationID: "slip-1", Steps: steps},
+			}}
+			resolver := NewSlipResolver(mockGit, mockFinder, &mockLogger{})
+
+			output, err := resolver.Resolve(context.Background(), domain.ResolveInput{RequiredSteps: tt.required})
+
+			if !tt.wantErr {
+				require.NoError(t, err)
+				assert.Equal(t, "slip-1", output.CorrelationID)
+				return
+			}
+			require.Error(t, err)
+			assert.ErrorIs(t, err, domain.ErrStepRequirementUnmet)
+			assert.NotContains(t, err.Error(), "push_parsed")
+			for _, want := range tt.wantInErr {
+				assert.Contains(t, err.Error(), want)
+			}
+		})
+	}
+}
+
+func TestSlipResolver_Resolve_PullRequest(t *testing.T) {
+	prHead := []string{"p0", "p1", "b1", "b2"}
+	base := []string{"b1", "b2"}
+
+	tests := []struct {
+		name           string
+		pr             domain.PullRequest
+		revAncestry    map[string][]string
+		slips          map[string]*domain.Slip
+		wantID         string
+		wantResolvedBy string
+		wantDistance   int
+		wantRevs       []string
+		wantErr        error
+	}{
+		{
+			name:           "PR head slip preferred",
+			pr:             domain.PullRequest{HeadSHA: "p0", BaseSHA: "b1", BaseRef: "main"},
+			revAncestry:    map[string][]string{"p0": prHead, "b1": base},
+			slips:          map[string]*domain.Slip{"p1": {CorrelationID: "pr-slip"}, "b1": {CorrelationID: "base-slip"}},
+			wantID:         "pr-slip",
+			wantResolvedBy: domain.ResolvedByPRHead,
+			wantDistance:   1,
+			wantRevs:       []string{"p0"},
+		},
+		{
+			name:           "base SHA preferred over base ref",
+			pr:             domain.PullRequest{HeadSHA: "p0", BaseSHA: "b1", BaseRef: "main"},
+			revAncestry:    map[string][]string{"p0": {"p0", "p1"}, "b1": base},
+			slips:          map[string]*domain.Slip{"b2": {CorrelationID: "base-slip"}},
+			wantID:         "base-slip",
+			wantResolvedBy: domain.ResolvedByPRBase,
+			wantDistance:   -1,
+			wantRevs:       []string{"p0", "b1"},
+		},
+		{
+			name:           "head missing locally falls through to base ref",
+			pr:             domain.PullRequest{HeadSHA: "p0", BaseRef: "main"},
+			revAncestry:    map[string][]string{"main": base},
+			slips:          map[string]*domain.Slip{"b1": {CorrelationID: "base-slip"}},
+			wantID:         "base-slip",
+			wantResolvedBy: domain.ResolvedByPRBase,
+			wantDistance:   -1,
+			wantRevs:       []string{"p0", "main"},
+		},
+		{
+			name:           "HEAD ancestry stands in for unknown base",
+			pr:             domain.PullRequest{HeadSHA: "p0"},
+			revAncestry:    map[string][]string{"p0": {"p0"}},
+			slips:          map[string]*domain.Slip{"m1": {CorrelationID: "head-slip"}},
+			wantID:         "head-slip",
+			wantResolvedBy: domain.ResolvedByPRBase,
+			wantDistance:   -1,
+			wantRevs:       []string{"p0"},
+		},
+		{
+			name:        "no slip anywhere",
+			pr:          domain.PullRequest{HeadSHA: "p0", BaseRef: "main"},
+			revAncestry: map[string][]string{"p0": prHead},
+			slips:       map[string]*domain.Slip{},
+			wantRevs:    []string{"p0", "main"},
+			wantErr:     domain.ErrNoAncestorSlip,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := &mockLocalGitRepository{
+				gitContext:  &domain.GitContext{HeadSHA: "m0", Repository: "org/repo", IsDetached: true},
+				commits:     []string{"m0", "m1"},
+				revAncestry: tt.revAncestry,
+			}
+			mockFinder := &mockSlipFinder{slipsByCommit: tt.slips}
+			resolver := NewSlipResolver(mockGit, mockFinder, &mockLogger{})
+			pr := tt.pr
+
+			output, err := resolver.Resolve(context.Background(), domain.ResolveInput{PullRequest: &pr})
+
+			assert.Equal(t, tt.wantRevs, mockGit.revs)
+			if tt.wantErr != nil {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Contains(t, err.Error(), "from p0")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantID, output.CorrelationID)
+			assert.Equal(t, tt.wantResolvedBy, output.ResolvedBy)
+			assert.Equal(t, tt.wantDistance, output.Distance)
+		})
+	}
+}
+
+func TestSlipResolver_Resolve_PullRequestDeepening(t *testing.T) {
+	prHead := make([]string, 10)
+	for i := range prHead {
+		prHead[i] = fmt.Sprintf("p%d", i)
+	}
+	mockGit := &mockLocalGitRepository{
+		gitContext:  &domain.GitContext{HeadSHA: "m0", Repository: "org/repo"},
+		revAncestry: map[string][]string{"p0": prHead},
+	}
+	mockFinder := &mockSlipFinder{slipsByCommit: map[string]*domain.Slip{"p7": {CorrelationID: "deep"}}}
+	resolver := NewSlipResolver(mockGit, mockFinder, &mockLogger{})
+
+	output, err := resolver.Resolve(context.Background(), domain.ResolveInput{
+		Depth:       2,
+		MaxDepth:    10,
+		PullRequest: &domain.PullRequest{HeadSHA: "p0"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "deep", output.CorrelationID)
+	assert.Equal(t, domain.ResolvedByPRHead, output.ResolvedBy)
+	assert.Equal(t, 7, output.Distance)
+	assert.Equal(t, []string{"p0", "p0"}, mockGit.revs, "2 -> 8 reaches p7")
+	assert.Empty(t, mockGit.depths, "HEAD ancestry should not be walked")
+}
+
+func TestSlipResolver_Resolve_PullRequestSkipsSearchedCommits(t *testing.T) {
+	mockGit := &mockLocalGitRepository{
+		gitContext: &domain.GitContext{HeadSHA: "m0", Repository: "org/repo"},
+		revAncestry: map[string][]string{
+			"p0":   {"p0", "b1", "b2"},
+			"main": {"b1", "b2", "b3"},
+		},
+	}
+	mockFinder := &mockSlipFinder{slipsByCommit: map[string]*domain.Slip{"b3": {CorrelationID: "base-slip"}}}
+	resolver := NewSlipResolver(mockGit, mockFinder, &mockLogger{})
+
+	output, err := resolver.Resolve(context.Background(), domain.ResolveInput{
+		PullRequest: &domain.PullRequest{HeadSHA: "p0", BaseRef: "main"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "base-slip", output.CorrelationID)
+	assert.Equal(t, domain.ResolvedByPRBase, output.ResolvedBy)
+	require.Len(t, mockFinder.findByCommitsCalls, 2)
+	assert.Equal(t, []string{"p0", "b1", "b2"}, mockFinder.findByCommitsCalls[0].commits)
+	assert.Equal(t, []string{"b3"}, mockFinder.findByCommitsCalls[1].commits,
+		"base commits the PR head search missed are not queried again")
+}
+
+func TestSlipResolver_Resolve_RepeatedCommits(t *testing.T) {
+	mockGit := &mockLocalGitRepository{
+		gitContext: &domain.GitContext{HeadSHA: "c0", Repository: "org/repo"},
+		commits:    []string{"c0", "c1", "c0", "c2", "c1", "c3"},
+	}
+	mockFinder := &mockSlipFinder{slipsByCommit: map[string]*domain.Slip{"c3": {CorrelationID: "slip-3"}}}
+	resolver := NewSlipResolver(mockGit, mockFinder, &mockLogger{})
+
+	output, err := resolver.Resolve(context.Background(), domain.ResolveInput{})
+
+	require.NoError(t, err)
+	assert.Equal(t, "slip-3", output.CorrelationID)
+	assert.Equal(t, 3, output.Distance, "distance counts distinct commits")
+	assert.Equal(t, 4, output.Depth)
+	require.NotEmpty(t, mockFinder.findByCommitsCalls)
+	assert.Equal(t, []string{"c0", "c1", "c2", "c3"}, mockFinder.findByCommitsCalls[0].commits)
+}
+
+func TestSlipResolver_ResolveAll_RepeatedCommits(t *testing.T) {
+	mockGit := &mockLocalGitRepository{
+		gitContext: &domain.GitContext{HeadSHA: "c0", Repository: "org/repo"},
+		commits:    []string{"c0", "c1", "c1", "c2"},
+	}
+	mockFinder := &mockSlipFinder{slipsByCommit: map[string]*domain.Slip{"c2": {CorrelationID: "slip-2"}}}
+	resolver := NewSlipResolver(mockGit, mockFinder, &mockLogger{})
+
+	output, err := resolver.ResolveAll(context.Background(), domain.ResolveInput{})
+
+	require.NoError(t, err)
+	require.Len(t, output.Candidates, 1)
+	assert.Equal(t, 2, output.Candidates[0].Distance)
+}
+
+func TestSlipResolver_Resolve_DenyList(t *testing.T) {
+	matches := []domain.SlipMatch{
+		{Slip: &domain.Slip{CorrelationID: "rolled-back"}, MatchedCommit: "c0"},
+		{Slip: &domain.Slip{CorrelationID: "good"}, MatchedCommit: "c2"},
+	}
+
+	tests := []struct {
+		name           string
+		denyList       []string
+		latestSlip     *domain.Slip
+		wantID         string
+		wantResolvedBy string
+		wantErr        error
+	}{
+		{
+			name:           "denied nearest slip is skipped",
+			denyList:       []string{"rolled-back"},
+			wantID:         "good",
+			wantResolvedBy: domain.ResolvedByAncestry,
+		},
+		{
+			name:           "all ancestry slips denied falls back",
+			denyList:       []string{"rolled-back", "good"},
+			latestSlip:     &domain.Slip{CorrelationID: "latest", CommitSHA: "x1"},
+			wantID:         "latest",
+			wantResolvedBy: domain.ResolvedByBranchLatest,
+		},
+		{
+			name:       "denied branch-latest slip is a miss",
+			denyList:   []string{"rolled-back", "good", "latest"},
+			latestSlip: &domain.Slip{CorrelationID: "latest", CommitSHA: "x1"},
+			wantErr:    domain.ErrNoAncestorSlip,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := &mockLocalGitRepository{
+				gitContext: &domain.GitContext{HeadSHA: "c0", Branch: "feature", Repository: "org/repo"},
+				commits:    []string{"c0", "c1", "c2"},
+			}
+			mockFinder := &mockSlipFinder{findAllMatches: matches, latestSlip: tt.latestSlip}
+			resolver := NewSlipResolver(mockGit, mockFinder, &mockLogger{})
+
+			output, err := resolver.Resolve(context.Background(), domain.ResolveInput{
+				DenyList:             tt.denyList,
+				FallbackBranchLatest: true,
+			})
+
+			assert.Empty(t, mockFinder.findByCommitsCalls, "a deny-list needs every candidate")
+			if tt.wantErr != nil {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantID, output.CorrelationID)
+			assert.Equal(t, tt.wantResolvedBy, output.ResolvedBy)
+		})
+	}
+}
+
+func TestSlipResolver_ResolveAll_DenyList(t *testing.T) {
+	mockGit := &mockLocalGitRepository{
+		gitContext: &domain.GitContext{HeadSHA: "c0", Repository: "org/repo"},
+		commits:    []string{"c0", "c1"},
+	}
+	mockFinder := &mockSlipFinder{findAllMatches: []domain.SlipMatch{
+		{Slip: &domain.Slip{CorrelationID: "rolled-back"}, MatchedCommit: "c0"},
+		{Slip: &domain.Slip{CorrelationID: "good"}, MatchedCommit: "c1"},
+	}}
+	resolver := NewSlipResolver(mockGit, mockFinder, &mockLogger{})
+
+	output, err := resolver.ResolveAll(context.Background(), domain.ResolveInput{DenyList: []string{"rolled-back"}})
+
+	require.NoError(t, err)
+	require.Len(t, output.Candidates, 1)
+	assert.Equal(t, "good", output.Candidates[0].CorrelationID)
+}
+
+func TestSlipResolver_Resolve_CorrelationIDFormat(t *testing.T) {
+	uuidFormat, err := domain.ParseCorrelationIDFormat(domain.CorrelationIDFormatUUID)
+	require.NoError(t, err)
+	slipFormat, err := domain.ParseCorrelationIDFormat(`slip-\d+`)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		id        string
+		format    *regexp.Regexp
+		wantErr   bool
+		wantInErr string
+	}{
+		{name: "uuid", id: "0b1e6f4c-6a0e-4d8e-9b43-1f1e2a3b4c5d", format: uuidFormat},
+		{name: "custom format", id: "slip-12", format: slipFormat},
+		{name: "any format", id: "anything goes"},
+		{name: "not a uuid", id: "<html>502 Bad Gateway", format: uuidFormat, wantErr: true, wantInErr: "is not a UUID"},
+		{name: "partial match", id: "slip-12x", format: slipFormat, wantErr: true, wantInErr: `does not match`},
+		{name: "empty", id: "", wantErr: true, wantInErr: "is empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := &mockLocalGitRepository{
+				gitContext: &domain.GitContext{HeadSHA: "c0", Branch: "main", Repository: "org/repo"},
+				commits:    []string{"c0", "c1"},
+			}
+			mockFinder := &mockSlipFinder{slipsByCommit: map[string]*domain.Slip{"c1": {CorrelationID: tt.id}}}
+			resolver := NewSlipResolver(mockGit, mockFinder, &mockLogger{})
+
+			output, err := resolver.Resolve(context.Background(), domain.ResolveInput{CorrelationIDFormat: tt.format})
+
+			if !tt.wantErr {
+				require.NoError(t, err)
+				assert.Equal(t, tt.id, output.CorrelationID)
+				return
+			}
+			require.ErrorIs(t, err, domain.ErrMalformedCorrelationID)
+			assert.Equal(t, domain.CodeMalformedCorrelationID, domain.CodeOf(err))
+			assert.Contains(t, err.Error(), "slip of commit c1")
+			assert.Contains(t, err.Error(), tt.wantInErr)
+		})
+	}
+}
+
+func TestSlipResolver_ResolveAll_CorrelationIDFormat(t *testing.T) {
+	mockGit := &mockLocalGitRepository{
+		gitContext: &domain.GitContext{HeadSHA: "c0", Repository: "org/repo"},
+		commits:    []string{"c0", "c1"},
+	}
+	mockFinder := &mockSlipFinder{findAllMatches: []domain.SlipMatch{
+		{Slip: &domain.Slip{CorrelationID: "0b1e6f4c-6a0e-4d8e-9b43-1f1e2a3b4c5d"}, MatchedCommit: "c0"},
+		{Slip: &domain.Slip{CorrelationID: "null"}, MatchedCommit: "c1"},
+	}}
+	resolver := NewSlipResolver(mockGit, mockFinder, &mockLogger{})
+	format, err := domain.ParseCorrelationIDFormat(domain.CorrelationIDFormatUUID)
+	require.NoError(t, err)
+
+	_, err = resolver.ResolveAll(context.Background(), domain.ResolveInput{CorrelationIDFormat: format})
+
+	require.ErrorIs(t, err, domain.ErrMalformedCorrelationID)
+	assert.Contains(t, err.Error(), `"null" is not a UUID`)
+}
+
+func TestSlipResolver_Resolve_BranchAffinity(t *testing.T) {
+	matches := []domain.SlipMatch{
+		{Slip: &domain.Slip{CorrelationID: "other-branch", Branch: "main"}, MatchedCommit: "c0"},
+		{Slip: &domain.Slip{CorrelationID: "same-branch", Branch: "feature"}, MatchedCommit: "c2"},
+	}
+
+	tests := []struct {
+		name        string
+		branch      string
+		affinity    bool
+		nearest     *domain.Slip
+		matches     []domain.SlipMatch
+		wantID      string
+		wantFindAll int
+	}{
+		{
+			name:        "nearest slip on another branch defers to same-branch slip",
+			branch:      "feature",
+			affinity:    true,
+			nearest:     matches[0].Slip,
+			matches:     matches,
+			wantID:      "same-branch",
+			wantFindAll: 2, // tie check on c0, then the full search
+		},
+		{
+			name:        "nearest slip on the current branch is used directly",
+			branch:      "feature",
+			affinity:    true,
+			nearest:     matches[1].Slip,
+			matches:     matches,
+			wantID:      "same-branch",
+			wantFindAll: 1,
+		},
+		{
+			name:        "no same-branch slip keeps the nearest",
+			branch:      "feature",
+			affinity:    true,
+			nearest:     matches[0].Slip,
+			matches:     matches[:1],
+			wantID:      "other-branch",
+			wantFindAll: 2,
+		},
+		{
+			name:        "detached HEAD has no affinity",
+			affinity:    true,
+			nearest:     matches[0].Slip,
+			matches:     matches,
+			wantID:      "other-branch",
+			wantFindAll: 1,
+		},
+		{
+			name:        "affinity disabled",
+			branch:      "feature",
+			nearest:     matches[0].Slip,
+			matches:     matches,
+			wantID:      "other-branch",
+			wantFindAll: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := &mockLocalGitRepository{
+				gitContext: &domain.GitContext{HeadSHA: "c0", Branch: tt.branch, Repository: "org/repo"},
+				commits:    []string{"c0", "c1", "c2"},
+			}
+			mockFinder := &mockSlipFinder{
+				findByCommitsSlip:   tt.nearest,
+				findByCommitsCommit: "c0",
+				findAllMatches:      tt.matches,
+			}
+			resolver := NewSlipResolver(mockGit, mockFinder, &mockLogger{})
+
+			output, err := resolver.Resolve(context.Background(), domain.ResolveInput{BranchAffinity: tt.affinity})
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantID, output.CorrelationID)
+			assert.Equal(t, tt.wantFindAll, mockFinder.findAllCalls)
+		})
+	}
+}
+
+// annotatingSlipFinder is a mockSlipFinder that also implements domain.SlipAnnotator.
+type annotatingSlipFinder struct {
+	mockSlipFinder
+	annotatedID string
+	entries     []domain.HistoryEntry
+	appendErr   error
+}
+
+func (m *annotatingSlipFinder) AppendHistory(_ context.Context, correlationID string, entry domain.HistoryEntry) error {
+	m.annotatedID = correlationID
+	m.entries = append(m.entries, entry)
+	return m.appendErr
+}
+
+func TestSlipResolver_Resolve_Hint(t *testing.T) {
+	old := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	hinted := &domain.Slip{CorrelationID: "slip-hint", Repository: "Org/Repo", CommitSHA: "c9", CreatedAt: old}
+	foreign := &domain.Slip{CorrelationID: "slip-foreign", Repository: "org/other", CommitSHA: "f1"}
+
+	tests := []struct {
+		name       string
+		input      domain.ResolveInput
+		findErr    error
+		wantID     string
+		wantErrIs  error
+		wantErrMsg string
+	}{
+		{
+			name:   "no hint resolves normally",
+			wantID: "slip-1",
+		},
+		{
+			name:   "hint short-circuits ancestry and ignores age limit",
+			input:  domain.ResolveInput{Hint: "slip-hint", MaxAge: time.Hour},
+			wantID: "slip-hint",
+		},
+		{
+			name:       "unknown hint",
+			input:      domain.ResolveInput{Hint: "slip-missing"},
+			wantErrIs:  domain.ErrInvalidHint,
+			wantErrMsg: "slip slip-missing not found",
+		},
+		{
+			name:       "hint for another repository",
+			input:      domain.ResolveInput{Hint: "slip-foreign"},
+			wantErrIs:  domain.ErrInvalidHint,
+			wantErrMsg: "belongs to org/other, not org/repo",
+		},
+		{
+			name:       "denied hint",
+			input:      domain.ResolveInput{Hint: "slip-hint", DenyList: []string{"slip-hint"}},
+			wantErrIs:  domain.ErrInvalidHint,
+			wantErrMsg: "deny-list",
+		},
+		{
+			name:       "store error",
+			input:      domain.ResolveInput{Hint: "slip-hint"},
+			findErr:    errors.New("connection reset"),
+			wantErrMsg: "failed to load hinted slip",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := &mockLocalGitRepository{
+				gitContext: &domain.GitContext{HeadSHA: "c0", Repository: "org/repo"},
+				commits:    []string{"c0", "c1"},
+			}
+			finder := &mockSlipFinder{
+				findByCommitsSlip:   &domain.Slip{CorrelationID: "slip-1"},
+				findByCommitsCommit: "c0",
+				slipsByID:           map[string]*domain.Slip{"slip-hint": hinted, "slip-foreign": foreign},
+				findByIDErr:         tt.findErr,
+			}
+			resolver := NewSlipResolver(mockGit, finder, &mockLogger{})
+
+			output, err := resolver.Resolve(context.Background(), tt.input)
+
+			if tt.wantErrIs != nil || tt.wantErrMsg != "" {
+				require.Error(t, err)
+				if tt.wantErrIs != nil {
+					assert.ErrorIs(t, err, tt.wantErrIs)
+				}
+				assert.Contains(t, err.Error(), tt.wantErrMsg)
+				assert.Empty(t, finder.findByCommitsCalls)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantID, output.CorrelationID)
+			if tt.input.Hint != "" {
+				assert.Equal(t, domain.ResolvedByHint, output.ResolvedBy)
+				assert.Equal(t, "c9", output.MatchedCommit)
+				assert.Equal(t, -1, output.Distance)
+				assert.Empty(t, finder.findByCommitsCalls)
+			}
+		})
+	}
+}
+
+func TestSlipResolver_Resolve_Annotation(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	annotation := &domain.Annotation{Host: "runner-7", Pipeline: "build#42"}
+
+	tests := []struct {
+		name        string
+		annotation  *domain.Annotation
+		appendErr   error
+		wantEntries []domain.HistoryEntry
+	}{
+		{
+			name:       "annotation recorded",
+			annotation: annotation,
+			wantEntries: []domain.HistoryEntry{{
+				Step:      "resolve",
+				Actor:     "slippy-find",
+				Message:   "resolved by slippy-find on host runner-7 for pipeline build#42",
+				Timestamp: now,
+			}},
+		},
+		{
+			name:       "append failure does not fail resolution",
+			annotation: annotation,
+			appendErr:  errors.New("write failed"),
+			wantEntries: []domain.HistoryEntry{{
+				Step:      "resolve",
+				Actor:     "slippy-find",
+				Message:   "resolved by slippy-find on host runner-7 for pipeline build#42",
+				Timestamp: now,
+			}},
+		},
+		{
+			name: "disabled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := &mockLocalGitRepository{
+				gitContext: &domain.GitContext{HeadSHA: "c0", Repository: "org/repo"},
+				commits:    []string{"c0", "c1"},
+			}
+			finder := &annotatingSlipFinder{
+				mockSlipFinder: mockSlipFinder{
+					findByCommitsSlip:   &domain.Slip{CorrelationID: "slip-1"},
+					findByCommitsCommit: "c0",
+				},
+				appendErr: tt.appendErr,
+			}
+			resolver := NewSlipResolver(mockGit, finder, &mockLogger{})
+			resolver.now = func() time.Time { return now }
+
+			output, err := resolver.Resolve(context.Background(), domain.ResolveInput{Annotation: tt.annotation})
+
+			require.NoError(t, err)
+			assert.Equal(t, "slip-1", output.CorrelationID)
+			assert.Equal(t, tt.wantEntries, finder.entries)
+			if tt.wantEntries != nil {
+				assert.Equal(t, "slip-1", finder.annotatedID)
+			}
+		})
+	}
+}
+
+func TestSlipResolver_Resolve_AnnotationUnsupported(t *testing.T) {
+	mockGit := &mockLocalGitRepository{
+		gitContext: &domain.GitContext{HeadSHA: "c0", Repository: "org/repo"},
+		commits:    []string{"c0"},
+	}
+	mockFinder := &mockSlipFinder{findByCommitsSlip: &domain.Slip{CorrelationID: "slip-1"}, findByCommitsCommit: "c0"}
+	resolver := NewSlipResolver(mockGit, mockFinder, &mockLogger{})
+
+	output, err := resolver.Resolve(context.Background(), domain.ResolveInput{
+		Annotation: &domain.Annotation{Host: "runner-7"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "slip-1", output.CorrelationID)
+}
+
+func TestSlipResolver_Resolve_CommitTie(t *testing.T) {
+	older := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+
+	tests := []struct {
+		name           string
+		storeFirst     *domain.Slip
+		sameCommit     []domain.SlipMatch
+		affinity       bool
+		wantID         string
+		wantCandidates int
+	}{
+		{
+			name:       "newest created wins regardless of store order",
+			storeFirst: &domain.Slip{CorrelationID: "old", CreatedAt: older},
+			sameCommit: []domain.SlipMatch{
+				{Slip: &domain.Slip{CorrelationID: "old", CreatedAt: older}, MatchedCommit: "c0"},
+				{Slip: &domain.Slip{CorrelationID: "new", CreatedAt: newer}, MatchedCommit: "c0"},
+			},
+			wantID:         "new",
+			wantCandidates: 2,
+		},
+		{
+			name:       "equal creation times fall back to correlation ID",
+			storeFirst: &domain.Slip{CorrelationID: "slip-b", CreatedAt: older},
+			sameCommit: []domain.SlipMatch{
+				{Slip: &domain.Slip{CorrelationID: "slip-b", CreatedAt: older}, MatchedCommit: "c0"},
+				{Slip: &domain.Slip{CorrelationID: "slip-a", CreatedAt: older}, MatchedCommit: "c0"},
+			},
+			wantID:         "slip-a",
+			wantCandidates: 2,
+		},
+		{
+			name:       "branch affinity outranks creation time",
+			storeFirst: &domain.Slip{CorrelationID: "main", Branch: "main", CreatedAt: newer},
+			sameCommit: []domain.SlipMatch{
+				{Slip: &domain.Slip{CorrelationID: "main", Branch: "main", CreatedAt: newer}, MatchedCommit: "c0"},
+				{Slip: &domain.Slip{CorrelationID: "feature", Branch: "feature", CreatedAt: older}, MatchedCommit: "c0"},
+			},
+			affinity:       true,
+			wantID:         "feature",
+			wantCandidates: 2,
+		},
+		{
+			name:       "slips on other commits are not ties",
+			storeFirst: &domain.Slip{CorrelationID: "near", CreatedAt: older},
+			sameCommit: []domain.SlipMatch{
+				{Slip: &domain.Slip{CorrelationID: "far", CreatedAt: newer}, MatchedCommit: "c1"},
+			},
+			wantID:         "near",
+			wantCandidates: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := &mockLocalGitRepository{
+				gitContext: &domain.GitContext{HeadSHA: "c0", Branch: "feature", Repository: "org/repo"},
+				commits:    []string{"c0", "c1"},
+			}
+			mockFinder := &mockSlipFinder{
+				findByCommitsSlip:   tt.storeFirst,
+				findByCommitsCommit: "c0",
+				findAllMatches:      tt.sameCommit,
+			}
+			resolver := NewSlipResolver(mockGit, mockFinder, &mockLogger{})
+
+			output, err := resolver.Resolve(context.Background(), domain.ResolveInput{BranchAffinity: tt.affinity})
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantID, output.CorrelationID)
+			assert.Equal(t, "c0", output.MatchedCommit)
+			assert.Equal(t, tt.wantCandidates, output.Candidates)
+			assert.Equal(t, 1, mockFinder.findAllCalls)
+		})
+	}
+}
+
+// nearestSlipFinder is a mockSlipFinder that is also a domain.NearestSlipsFinder.
+type nearestSlipFinder struct {
+	*mockSlipFinder
+	nearest      []domain.SlipMatch
+	nearestErr   error
+	nearestCalls int
+}
+
+func (f *nearestSlipFinder) FindNearestByCommits(_ context.Context, _ string, _ []string) ([]domain.SlipMatch, error) {
+	f.nearestCalls++
+	return f.nearest, f.nearestErr
+}
+
+func TestSlipResolver_Resolve_NearestSlipsFinder(t *testing.T) {
+	older := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+
+	tests := []struct {
+		name           string
+		finder         *nearestSlipFinder
+		wantID         string
+		wantCandidates int
+		wantFallback   bool
+		wantNotFound   bool
+	}{
+		{
+			name: "single slip in one lookup",
+			finder: &nearestSlipFinder{mockSlipFinder: &mockSlipFinder{}, nearest: []domain.SlipMatch{
+				{Slip: &domain.Slip{CorrelationID: "only"}, MatchedCommit: "c0"},
+			}},
+			wantID:         "only",
+			wantCandidates: 1,
+		},
+		{
+			name: "tie broken without another lookup",
+			finder: &nearestSlipFinder{mockSlipFinder: &mockSlipFinder{}, nearest: []domain.SlipMatch{
+				{Slip: &domain.Slip{CorrelationID: "old", CreatedAt: older}, MatchedCommit: "c0"},
+				{Slip: &domain.Slip{CorrelationID: "new", CreatedAt: newer}, MatchedCommit: "c0"},
+			}},
+			wantID:         "new",
+			wantCandidates: 2,
+		},
+		{
+			name:         "no slip",
+			finder:       &nearestSlipFinder{mockSlipFinder: &mockSlipFinder{}, nearest: []domain.SlipMatch{}},
+			wantNotFound: true,
+		},
+		{
+			name: "unsupported falls back to FindByCommits",
+			finder: &nearestSlipFinder{
+				mockSlipFinder: &mockSlipFinder{
+					findByCommitsSlip:   &domain.Slip{CorrelationID: "fallback"},
+					findByCommitsCommit: "c0",
+				},
+				nearestErr: domain.ErrNearestUnsupported,
+			},
+			wantID:         "fallback",
+			wantCandidates: 1,
+			wantFallback:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := &mockLocalGitRepository{
+				gitContext: &domain.GitContext{HeadSHA: "c0", Repository: "org/repo"},
+				commits:    []string{"c0", "c1"},
+			}
+			resolver := NewSlipResolver(mockGit, tt.finder, &mockLogger{})
+
+			output, err := resolver.Resolve(context.Background(), domain.ResolveInput{})
+
+			assert.Equal(t, 1, tt.finder.nearestCalls)
+			assert.Equal(t, tt.wantFallback, len(tt.finder.findByCommitsCalls) == 1)
+			assert.Equal(t, tt.wantFallback, tt.finder.findAllCalls == 1)
+			if tt.wantNotFound {
+				assert.ErrorIs(t, err, domain.ErrNoAncestorSlip)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantID, output.CorrelationID)
+			assert.Equal(t, "c0", output.MatchedCommit)
+			assert.Equal(t, tt.wantCandidates, output.Candidates)
+		})
+	}
+}
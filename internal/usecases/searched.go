@@ -0,0 +1,65 @@
+package usecases
+
+import "sync"
+
+// dedupeCommits returns commits without repeats, keeping each commit at its
+// first, nearest position. Returns commits itself if it has none.
+func dedupeCommits(commits []string) []string {
+	return dropCommits(commits, nil)
+}
+
+// dropCommits returns commits without repeats and without the commits in skip,
+// in order. Returns commits itself if none are dropped.
+func dropCommits(commits []string, skip map[string]struct{}) []string {
+	seen := make(map[string]struct{}, len(commits))
+	kept := make([]string, 0, len(commits))
+	for _, c := range commits {
+		if _, repeat := seen[c]; repeat {
+			continue
+		}
+		seen[c] = struct{}{}
+		if _, skipped := skip[c]; !skipped {
+			kept = append(kept, c)
+		}
+	}
+	if len(kept) == len(commits) {
+		return commits
+	}
+	return kept
+}
+
+// searchedCommits records the commits a resolution searched without finding a
+// slip. Strategies often walk overlapping ancestries (the PR head's and the PR
+// base's share the base branch), and a commit that matched no slip for one
+// cannot match one for the next, since the criteria are the same. It is safe
+// for concurrent use; a nil searchedCommits records nothing.
+type searchedCommits struct {
+	mu      sync.Mutex
+	commits map[string]struct{}
+}
+
+// unsearched returns commits without repeats and without the commits already
+// searched, in order.
+func (s *searchedCommits) unsearched(commits []string) []string {
+	if s == nil {
+		return dedupeCommits(commits)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return dropCommits(commits, s.commits)
+}
+
+// add records commits as searched without a match.
+func (s *searchedCommits) add(commits []string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.commits == nil {
+		s.commits = make(map[string]struct{}, len(commits))
+	}
+	for _, c := range commits {
+		s.commits[c] = struct{}{}
+	}
+}
@@ -0,0 +1,38 @@
+package usecases
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDedupeCommits(t *testing.T) {
+	tests := []struct {
+		name    string
+		commits []string
+		want    []string
+	}{
+		{name: "empty"},
+		{name: "no repeats", commits: []string{"c0", "c1", "c2"}, want: []string{"c0", "c1", "c2"}},
+		{name: "repeats keep the nearest", commits: []string{"c0", "c1", "c0", "c2", "c1"}, want: []string{"c0", "c1", "c2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, dedupeCommits(tt.commits))
+		})
+	}
+}
+
+func TestSearchedCommits(t *testing.T) {
+	var searched searchedCommits
+
+	assert.Equal(t, []string{"c0", "c1"}, searched.unsearched([]string{"c0", "c1", "c0"}))
+	searched.add([]string{"c0", "c1"})
+	assert.Equal(t, []string{"c2"}, searched.unsearched([]string{"c1", "c2", "c0"}))
+	assert.Empty(t, searched.unsearched([]string{"c1"}))
+
+	var none *searchedCommits
+	none.add([]string{"c0"})
+	assert.Equal(t, []string{"c0"}, none.unsearched([]string{"c0", "c0"}), "a nil set only drops repeats")
+}
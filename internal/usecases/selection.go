@@ -0,0 +1,98 @@
+package usecases
+
+import (
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// selectMatch applies policy to choose one slip from matches.
+// commits is the ancestry list (nearest first) used to measure commit distance;
+// branch is the current branch used by SelectionBranchPreferred.
+// Matches the policy ranks equally are ordered by domain.Slip.Precedes, so the
+// result does not depend on store order. matches must be non-empty.
+func selectMatch(
+	matches []domain.SlipMatch,
+	commits []string,
+	policy domain.SelectionPolicy,
+	branch string,
+) domain.SlipMatch {
+	distance := commitDistances(commits)
+
+	switch policy {
+	case domain.SelectionNewestCreated:
+		best := matches[0]
+		for _, m := range matches[1:] {
+			if m.Slip.CreatedAt.After(best.Slip.CreatedAt) ||
+				(m.Slip.CreatedAt.Equal(best.Slip.CreatedAt) && closer(m, best, distance)) {
+				best = m
+			}
+		}
+		return best
+	case domain.SelectionBranchPreferred:
+		if branch != "" {
+			var onBranch []domain.SlipMatch
+			for _, m := range matches {
+				if m.Slip.Branch == branch {
+					onBranch = append(onBranch, m)
+				}
+			}
+			if len(onBranch) > 0 {
+				return nearest(onBranch, distance)
+			}
+		}
+		return nearest(matches, distance)
+	case domain.SelectionNearestCommit:
+		return nearest(matches, distance)
+	default:
+		return nearest(matches, distance)
+	}
+}
+
+// commitDistances maps each commit SHA to its index in the ancestry (0 = HEAD).
+func commitDistances(commits []string) map[string]int {
+	distance := make(map[string]int, len(commits))
+	for i, c := range commits {
+		if _, seen := distance[c]; !seen {
+			distance[c] = i
+		}
+	}
+	return distance
+}
+
+// nearest returns the match whose commit is closest to HEAD.
+// Matches at the same distance are ordered by domain.Slip.Precedes.
+func nearest(matches []domain.SlipMatch, distance map[string]int) domain.SlipMatch {
+	best := matches[0]
+	for _, m := range matches[1:] {
+		if closer(m, best, distance) {
+			best = m
+		}
+	}
+	return best
+}
+
+// closer reports whether a ranks before b by distance from HEAD, breaking
+// ties at the same distance with domain.Slip.Precedes.
+func closer(a, b domain.SlipMatch, distance map[string]int) bool {
+	if nearer(a, b, distance) {
+		return true
+	}
+	if nearer(b, a, distance) {
+		return false
+	}
+	return a.Slip.Precedes(b.Slip)
+}
+
+// nearer reports whether a's matched commit is strictly closer to HEAD than b's.
+// Commits missing from the ancestry sort after every known commit.
+func nearer(a, b domain.SlipMatch, distance map[string]int) bool {
+	da, okA := distance[a.MatchedCommit]
+	db, okB := distance[b.MatchedCommit]
+	switch {
+	case okA && okB:
+		return da < db
+	case okA:
+		return true
+	default:
+		return false
+	}
+}
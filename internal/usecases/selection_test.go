@@ -0,0 +1,138 @@
+package usecases
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+func TestSelectMatch(t *testing.T) {
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	commits := []string{"c0", "c1", "c2", "c3"}
+
+	match := func(id, commit, branch string, created time.Time) domain.SlipMatch {
+		return domain.SlipMatch{
+			Slip:          &domain.Slip{CorrelationID: id, Branch: branch, CreatedAt: created},
+			MatchedCommit: commit,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		matches []domain.SlipMatch
+		policy  domain.SelectionPolicy
+		branch  string
+		wantID  string
+	}{
+		{
+			name:    "nearest-commit ignores store order",
+			matches: []domain.SlipMatch{match("far", "c3", "main", base), match("near", "c1", "main", base)},
+			policy:  domain.SelectionNearestCommit,
+			wantID:  "near",
+		},
+		{
+			name:    "nearest-commit places unknown commits last",
+			matches: []domain.SlipMatch{match("unknown", "zz", "main", base), match("known", "c2", "main", base)},
+			policy:  domain.SelectionNearestCommit,
+			wantID:  "known",
+		},
+		{
+			name: "nearest-commit tie prefers newest created",
+			matches: []domain.SlipMatch{
+				match("old", "c1", "main", base),
+				match("new", "c1", "main", base.Add(time.Minute)),
+			},
+			policy: domain.SelectionNearestCommit,
+			wantID: "new",
+		},
+		{
+			name:    "nearest-commit tie then prefers smaller correlation ID",
+			matches: []domain.SlipMatch{match("slip-b", "c1", "main", base), match("slip-a", "c1", "main", base)},
+			policy:  domain.SelectionNearestCommit,
+			wantID:  "slip-a",
+		},
+		{
+			name:    "unknown commits tie on correlation ID",
+			matches: []domain.SlipMatch{match("slip-b", "yy", "main", base), match("slip-a", "zz", "main", base)},
+			policy:  domain.SelectionNearestCommit,
+			wantID:  "slip-a",
+		},
+		{
+			name: "newest-created",
+			matches: []domain.SlipMatch{
+				match("old", "c0", "main", base),
+				match("new", "c3", "main", base.Add(time.Minute)),
+			},
+			policy: domain.SelectionNewestCreated,
+			wantID: "new",
+		},
+		{
+			name: "newest-created tie falls back to nearest",
+			matches: []domain.SlipMatch{
+				match("far", "c3", "main", base),
+				match("near", "c1", "main", base),
+			},
+			policy: domain.SelectionNewestCreated,
+			wantID: "near",
+		},
+		{
+			name: "newest-created full tie prefers smaller correlation ID",
+			matches: []domain.SlipMatch{
+				match("slip-b", "c2", "main", base),
+				match("slip-a", "c2", "main", base),
+			},
+			policy: domain.SelectionNewestCreated,
+			wantID: "slip-a",
+		},
+		{
+			name: "branch-preferred tie on branch prefers newest created",
+			matches: []domain.SlipMatch{
+				match("feat-old", "c2", "feature", base),
+				match("feat-new", "c2", "feature", base.Add(time.Minute)),
+			},
+			policy: domain.SelectionBranchPreferred,
+			branch: "feature",
+			wantID: "feat-new",
+		},
+		{
+			name: "branch-preferred picks nearest on branch",
+			matches: []domain.SlipMatch{
+				match("main-near", "c0", "main", base),
+				match("feat-far", "c3", "feature", base),
+				match("feat-mid", "c2", "feature", base),
+			},
+			policy: domain.SelectionBranchPreferred,
+			branch: "feature",
+			wantID: "feat-mid",
+		},
+		{
+			name:    "branch-preferred falls back to nearest overall",
+			matches: []domain.SlipMatch{match("far", "c3", "main", base), match("near", "c1", "release", base)},
+			policy:  domain.SelectionBranchPreferred,
+			branch:  "feature",
+			wantID:  "near",
+		},
+		{
+			name:    "branch-preferred with detached HEAD uses nearest",
+			matches: []domain.SlipMatch{match("far", "c3", "", base), match("near", "c0", "main", base)},
+			policy:  domain.SelectionBranchPreferred,
+			wantID:  "near",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := selectMatch(tt.matches, commits, tt.policy, tt.branch)
+			assert.Equal(t, tt.wantID, got.Slip.CorrelationID)
+
+			// The result must not depend on the order the store returned matches in
+			reversed := slices.Clone(tt.matches)
+			slices.Reverse(reversed)
+			assert.Equal(t, tt.wantID, selectMatch(reversed, commits, tt.policy, tt.branch).Slip.CorrelationID)
+		})
+	}
+}
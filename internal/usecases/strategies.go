@@ -0,0 +1,324 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// miss is the result of a strategy that found nothing without walking a head.
+var miss = StrategyResult{Distance: -1}
+
+// hintStrategy resolves an explicit correlation ID hint, for emergency or manual
+// overrides. The hinted slip is validated against the store; a hint that fails
+// validation is an error rather than a miss, so a mistyped override never
+// silently resolves something else.
+type hintStrategy struct{}
+
+// Name implements Strategy.
+func (hintStrategy) Name() string { return domain.ResolvedByHint }
+
+// Resolve implements Strategy.
+func (hintStrategy) Resolve(ctx context.Context, req *StrategyRequest) (StrategyResult, error) {
+	hint := req.Input.Hint
+	if hint == "" {
+		return miss, nil
+	}
+
+	slip, err := req.Finder.FindByID(ctx, hint)
+	if err != nil {
+		if errors.Is(err, domain.ErrSlipNotFound) {
+			return miss, fmt.Errorf("%w: slip %s not found", domain.ErrInvalidHint, hint)
+		}
+		return miss, domain.WithCode(domain.CodeStore, fmt.Errorf("failed to load hinted slip: %w", err))
+	}
+	if !strings.EqualFold(slip.Repository, req.GitCtx.Repository) {
+		return miss, fmt.Errorf("%w: slip %s belongs to %s, not %s",
+			domain.ErrInvalidHint, hint, slip.Repository, req.GitCtx.Repository)
+	}
+	if _, denied := req.criteria.denied[hint]; denied {
+		return miss, fmt.Errorf("%w: slip %s is on the deny-list", domain.ErrInvalidHint, hint)
+	}
+
+	req.Logger.Warn(ctx, "resolution overridden by hint", map[string]interface{}{
+		"repository":     req.GitCtx.Repository,
+		"correlation_id": hint,
+		"matched_commit": slip.CommitSHA,
+	})
+	match := &domain.SlipMatch{Slip: slip, MatchedCommit: slip.CommitSHA}
+	return StrategyResult{Match: match, Candidates: 1, Distance: -1}, nil
+}
+
+// headAncestryStrategy walks HEAD's first-parent ancestry (filtered to the
+// component's paths, if any) with progressive deepening. When the repository
+// is a domain.AncestryStreamer and the criteria allow it, the ancestry is
+// queried as it is walked instead. It is replaced by the pr-head and pr-base
+// strategies in PR-aware mode.
+type headAncestryStrategy struct{}
+
+// Name implements Strategy.
+func (headAncestryStrategy) Name() string { return domain.ResolvedByAncestry }
+
+// Resolve implements Strategy.
+func (headAncestryStrategy) Resolve(ctx context.Context, req *StrategyRequest) (StrategyResult, error) {
+	if req.Input.PullRequest != nil {
+		return miss, nil
+	}
+
+	paths := req.Input.ComponentPaths
+	if streamer, ok := req.Git.(domain.AncestryStreamer); ok && len(paths) == 0 && req.criteria.streamable() {
+		match, candidates, commits, err := req.resolver.streamAncestry(
+			ctx, req.GitCtx, streamer, req.Depth, req.Input.MaxDepth, req.criteria)
+		if !errors.Is(err, domain.ErrStreamUnsupported) {
+			if err != nil {
+				return miss, err
+			}
+			return headResult(match, candidates, commits), nil
+		}
+	}
+
+	commits, err := walkAncestry(ctx, req.Git, req.Depth, paths)
+	if err != nil {
+		return miss, domain.WithCode(domain.CodeGit, fmt.Errorf("failed to get commit ancestry: %w", err))
+	}
+
+	req.Logger.Debug(ctx, "retrieved commit ancestry", map[string]interface{}{
+		"repository":    req.GitCtx.Repository,
+		"commits_count": len(commits),
+		"head_sha":      commits[0],
+	})
+
+	walk := func(d int) ([]string, error) { return walkAncestry(ctx, req.Git, d, paths) }
+	match, candidates, commits, err := req.Search(ctx, commits, walk)
+	if err != nil {
+		return miss, domain.WithCode(domain.CodeStore, fmt.Errorf("failed to find slip by commits: %w", err))
+	}
+	return headResult(match, candidates, commits), nil
+}
+
+// prHeadStrategy walks the pull request head's first-parent ancestry with
+// progressive deepening. A head missing from the local clone (common with
+// shallow checkouts) is logged as a warning and treated as a miss.
+type prHeadStrategy struct{}
+
+// Name implements Strategy.
+func (prHeadStrategy) Name() string { return domain.ResolvedByPRHead }
+
+// Resolve implements Strategy.
+func (prHeadStrategy) Resolve(ctx context.Context, req *StrategyRequest) (StrategyResult, error) {
+	pr := req.Input.PullRequest
+	if pr == nil {
+		return miss, nil
+	}
+
+	req.Logger.Info(ctx, "resolving against pull request", map[string]interface{}{
+		"repository": req.GitCtx.Repository,
+		"pr_head":    pr.HeadSHA,
+		"pr_base":    pr.BaseRef,
+		"base_sha":   pr.BaseSHA,
+	})
+
+	commits, err := req.Git.GetAncestryFrom(ctx, pr.HeadSHA, req.Depth)
+	if err != nil {
+		req.Logger.Warn(ctx, "pull request head ancestry unavailable", map[string]interface{}{
+			"repository": req.GitCtx.Repository,
+			"pr_head":    pr.HeadSHA,
+			"error":      err.Error(),
+		})
+		return miss, nil
+	}
+
+	walk := func(d int) ([]string, error) { return req.Git.GetAncestryFrom(ctx, pr.HeadSHA, d) }
+	match, candidates, commits, err := req.Search(ctx, commits, walk)
+	if err != nil {
+		return miss, domain.WithCode(domain.CodeStore,
+			fmt.Errorf("failed to find slip by pull request head commits: %w", err))
+	}
+	return headResult(match, candidates, commits), nil
+}
+
+// prBaseStrategy searches the ancestry of the pull request base: BaseSHA, else
+// BaseRef, else HEAD, whose first parent is the base branch in a merge-commit
+// build. A base missing from the local clone is logged and treated as a miss.
+type prBaseStrategy struct{}
+
+// Name implements Strategy.
+func (prBaseStrategy) Name() string { return domain.ResolvedByPRBase }
+
+// Resolve implements Strategy.
+func (prBaseStrategy) Resolve(ctx context.Context, req *StrategyRequest) (StrategyResult, error) {
+	pr := req.Input.PullRequest
+	if pr == nil {
+		return miss, nil
+	}
+
+	base := pr.BaseSHA
+	if base == "" {
+		base = pr.BaseRef
+	}
+
+	var commits []string
+	var err error
+	if base == "" {
+		commits, err = req.Git.GetCommitAncestry(ctx, req.Depth)
+	} else {
+		commits, err = req.Git.GetAncestryFrom(ctx, base, req.Depth)
+	}
+	if err != nil {
+		req.Logger.Warn(ctx, "pull request base ancestry unavailable", map[string]interface{}{
+			"repository": req.GitCtx.Repository,
+			"pr_base":    base,
+			"error":      err.Error(),
+		})
+		return miss, nil
+	}
+
+	req.Logger.Info(ctx, "trying pull request base ancestry", map[string]interface{}{
+		"repository":    req.GitCtx.Repository,
+		"pr_base":       base,
+		"commits_count": len(commits),
+	})
+
+	match, candidates, err := req.Match(ctx, commits)
+	if err != nil {
+		return miss, domain.WithCode(domain.CodeStore,
+			fmt.Errorf("failed to find slip by pull request base commits: %w", err))
+	}
+	return StrategyResult{Match: match, Candidates: candidates, Distance: -1}, nil
+}
+
+// mergeBaseStrategy searches the ancestry of the merge base between HEAD and the
+// default branch, when enabled. A missing branch or unrelated history is a miss,
+// not an error, so the chain can continue.
+type mergeBaseStrategy struct{}
+
+// Name implements Strategy.
+func (mergeBaseStrategy) Name() string { return domain.ResolvedByMergeBase }
+
+// Resolve implements Strategy.
+func (mergeBaseStrategy) Resolve(ctx context.Context, req *StrategyRequest) (StrategyResult, error) {
+	if !req.Input.FallbackMergeBase {
+		return miss, nil
+	}
+
+	defaultBranch := defaultBranchOf(req)
+
+	commits, err := req.Git.GetMergeBaseAncestry(ctx, defaultBranch, req.Depth)
+	if err != nil {
+		req.Logger.Warn(ctx, "merge-base fallback skipped", map[string]interface{}{
+			"repository":     req.GitCtx.Repository,
+			"default_branch": defaultBranch,
+			"error":          err.Error(),
+		})
+		return miss, nil
+	}
+
+	req.Logger.Info(ctx, "trying merge-base fallback", map[string]interface{}{
+		"repository":     req.GitCtx.Repository,
+		"default_branch": defaultBranch,
+		"merge_base":     commits[0],
+		"commits_count":  len(commits),
+	})
+
+	match, candidates, err := req.Match(ctx, commits)
+	if err != nil {
+		return miss, domain.WithCode(domain.CodeStore, fmt.Errorf("failed to find slip by merge-base commits: %w", err))
+	}
+	return StrategyResult{Match: match, Candidates: candidates, Distance: -1}, nil
+}
+
+// branchLatestStrategy returns the newest slip recorded for the current branch,
+// when enabled. A detached HEAD has no branch, so the default branch's newest
+// slip is returned instead. A slip that is older than the age limit or denied
+// misses.
+type branchLatestStrategy struct{}
+
+// Name implements Strategy.
+func (branchLatestStrategy) Name() string { return domain.ResolvedByBranchLatest }
+
+// Resolve implements Strategy.
+func (branchLatestStrategy) Resolve(ctx context.Context, req *StrategyRequest) (StrategyResult, error) {
+	if !req.Input.FallbackBranchLatest {
+		return miss, nil
+	}
+
+	gitCtx := req.GitCtx
+	branch := gitCtx.Branch
+	if branch == "" {
+		branch = defaultBranchOf(req)
+		req.Logger.Warn(ctx, "HEAD is detached; branch-latest fallback uses the default branch", map[string]interface{}{
+			"repository":     gitCtx.Repository,
+			"default_branch": branch,
+		})
+	}
+
+	req.Logger.Info(ctx, "trying branch-latest fallback", map[string]interface{}{
+		"repository": gitCtx.Repository,
+		"branch":     branch,
+	})
+
+	slip, err := req.Finder.FindLatestByBranch(ctx, gitCtx.Repository, branch)
+	if err != nil {
+		if errors.Is(err, domain.ErrSlipNotFound) {
+			return miss, nil
+		}
+		return miss, domain.WithCode(domain.CodeStore, fmt.Errorf("failed to find latest slip for branch: %w", err))
+	}
+	if notBefore := req.criteria.notBefore; slip.CreatedAt.Before(notBefore) {
+		req.Logger.Warn(ctx, "branch-latest slip is older than the age limit", map[string]interface{}{
+			"repository":     gitCtx.Repository,
+			"branch":         branch,
+			"correlation_id": slip.CorrelationID,
+			"created_at":     slip.CreatedAt,
+			"not_before":     notBefore,
+		})
+		return miss, nil
+	}
+	if _, denied := req.criteria.denied[slip.CorrelationID]; denied {
+		req.Logger.Warn(ctx, "branch-latest slip is on the deny-list", map[string]interface{}{
+			"repository":     gitCtx.Repository,
+			"branch":         branch,
+			"correlation_id": slip.CorrelationID,
+		})
+		return miss, nil
+	}
+
+	match := &domain.SlipMatch{Slip: slip, MatchedCommit: slip.CommitSHA}
+	return StrategyResult{Match: match, Candidates: 1, Distance: -1}, nil
+}
+
+// defaultBranchOf returns the default branch the fallbacks use: the one the
+// caller asked for, else the repository's, else DefaultBranchName.
+func defaultBranchOf(req *StrategyRequest) string {
+	switch {
+	case req.Input.DefaultBranch != "":
+		return req.Input.DefaultBranch
+	case req.GitCtx.DefaultBranch != "":
+		return req.GitCtx.DefaultBranch
+	default:
+		return domain.DefaultBranchName
+	}
+}
+
+// headResult reports a search from a head: commits walked and, on a match,
+// the matched commit's distance from that head.
+func headResult(match *domain.SlipMatch, candidates int, commits []string) StrategyResult {
+	result := StrategyResult{Match: match, Candidates: candidates, Walked: len(commits), Distance: -1}
+	if match != nil {
+		result.Distance = slices.Index(commits, match.MatchedCommit)
+	}
+	return result
+}
+
+// walkAncestry returns HEAD's first-parent ancestry, restricted to commits
+// touching paths when any are given.
+func walkAncestry(ctx context.Context, git domain.LocalGitRepository, depth int, paths []string) ([]string, error) {
+	if len(paths) > 0 {
+		return git.GetPathAncestry(ctx, paths, depth)
+	}
+	return git.GetCommitAncestry(ctx, depth)
+}
@@ -0,0 +1,80 @@
+package usecases
+
+import (
+	"context"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// Strategy is one step of the resolution pipeline. SlipResolver runs its
+// strategies in order and returns the first match; a strategy that does not
+// apply to the request (e.g. a fallback that was not enabled) simply misses.
+type Strategy interface {
+	// Name identifies the strategy in the registry and is reported as
+	// ResolveOutput.ResolvedBy when the strategy produces the result.
+	Name() string
+
+	// Resolve looks for a slip. A miss is a result with a nil Match;
+	// an error aborts resolution.
+	Resolve(ctx context.Context, req *StrategyRequest) (StrategyResult, error)
+}
+
+// StrategyResult is the outcome of one strategy.
+type StrategyResult struct {
+	// Match is the chosen slip, or nil on a miss.
+	Match *domain.SlipMatch
+
+	// Candidates is the number of matching slips Match was chosen from.
+	Candidates int
+
+	// Walked is the number of commits walked from the searched head, reported
+	// as ResolveOutput.Depth. Strategies that do not walk from a head leave it 0.
+	// It is meaningful on a miss too.
+	Walked int
+
+	// Distance is the number of commits between the searched head and the
+	// matched commit, or -1 when the match is not in that head's ancestry.
+	Distance int
+}
+
+// StrategyRequest carries one resolution request to each strategy, along with
+// the adapters and helpers that apply the request's selection rules.
+type StrategyRequest struct {
+	// Input is the caller's request.
+	Input domain.ResolveInput
+
+	// GitCtx is the local repository's HEAD, branch, and repository name.
+	GitCtx *domain.GitContext
+
+	// Depth is Input.Depth with the default applied.
+	Depth int
+
+	// Git and Finder are the resolver's adapters.
+	Git    domain.LocalGitRepository
+	Finder domain.SlipFinder
+
+	// Logger is the resolver's logger.
+	Logger Logger
+
+	resolver *SlipResolver
+	criteria matchCriteria
+}
+
+// Match queries the store for commits and applies the request's selection
+// policy, age limit, component preference, and deny-list.
+// Returns the chosen match (nil if none) and the number of candidates considered.
+func (q *StrategyRequest) Match(ctx context.Context, commits []string) (*domain.SlipMatch, int, error) {
+	return q.resolver.findMatch(ctx, q.GitCtx, commits, q.criteria)
+}
+
+// Search is Match with progressive deepening: on a miss, walk is called with
+// growing depths up to Input.MaxDepth and only the newly reached commits are
+// queried. commits must be the result of walk(Depth).
+// Returns the match (nil on a miss), the candidate count, and every commit walked.
+func (q *StrategyRequest) Search(
+	ctx context.Context,
+	commits []string,
+	walk func(depth int) ([]string, error),
+) (*domain.SlipMatch, int, []string, error) {
+	return q.resolver.searchAncestry(ctx, q.GitCtx, commits, q.Depth, q.Input.MaxDepth, walk, q.criteria)
+}
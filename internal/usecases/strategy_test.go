@@ -0,0 +1,82 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// tagStrategy is a custom strategy that searches commits listed under a tag,
+// using the request's Match helper so the selection rules still apply.
+type tagStrategy struct {
+	commits []string
+}
+
+func (tagStrategy) Name() string { return "release-tag" }
+
+func (s tagStrategy) Resolve(ctx context.Context, req *StrategyRequest) (StrategyResult, error) {
+	match, candidates, err := req.Match(ctx, s.commits)
+	if err != nil {
+		return StrategyResult{}, err
+	}
+	return StrategyResult{Match: match, Candidates: candidates, Distance: -1}, nil
+}
+
+func TestSlipResolver_CustomStrategy(t *testing.T) {
+	newResolver := func(finder *mockSlipFinder) *SlipResolver {
+		registry := DefaultStrategyRegistry()
+		require.NoError(t, registry.RegisterBefore(domain.ResolvedByMergeBase, tagStrategy{commits: []string{"t1", "t2"}}))
+		mockGit := &mockLocalGitRepository{
+			gitContext: &domain.GitContext{HeadSHA: "c0", Branch: "main", Repository: "org/repo"},
+			commits:    []string{"c0", "c1"},
+		}
+		return NewSlipResolverWithRegistry(mockGit, finder, &mockLogger{}, registry)
+	}
+
+	t.Run("runs after the ancestry misses", func(t *testing.T) {
+		finder := &mockSlipFinder{
+			slipsByCommit: map[string]*domain.Slip{"t2": {CorrelationID: "tagged"}},
+			latestSlip:    &domain.Slip{CorrelationID: "latest"},
+		}
+
+		output, err := newResolver(finder).Resolve(context.Background(), domain.ResolveInput{
+			FallbackBranchLatest: true,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "tagged", output.CorrelationID)
+		assert.Equal(t, "release-tag", output.ResolvedBy)
+		assert.Equal(t, -1, output.Distance)
+		assert.Equal(t, 2, output.Depth, "depth still reports the HEAD walk")
+		assert.Zero(t, finder.latestCalls, "later strategies are skipped")
+	})
+
+	t.Run("ancestry still wins", func(t *testing.T) {
+		finder := &mockSlipFinder{slipsByCommit: map[string]*domain.Slip{
+			"c1": {CorrelationID: "head"},
+			"t1": {CorrelationID: "tagged"},
+		}}
+
+		output, err := newResolver(finder).Resolve(context.Background(), domain.ResolveInput{})
+
+		require.NoError(t, err)
+		assert.Equal(t, "head", output.CorrelationID)
+		assert.Equal(t, domain.ResolvedByAncestry, output.ResolvedBy)
+		assert.Equal(t, 1, output.Distance)
+	})
+
+	t.Run("deny-list applies to custom strategies", func(t *testing.T) {
+		finder := &mockSlipFinder{findAllMatches: []domain.SlipMatch{
+			{Slip: &domain.Slip{CorrelationID: "tagged"}, MatchedCommit: "t1"},
+		}}
+
+		_, err := newResolver(finder).Resolve(context.Background(), domain.ResolveInput{DenyList: []string{"tagged"}})
+
+		require.Error(t, err)
+		assert.ErrorIs(t, err, domain.ErrNoAncestorSlip)
+	})
+}
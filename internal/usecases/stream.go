@@ -0,0 +1,116 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// streamable reports whether, under c, the nearest chunk of the ancestry
+// holding a match decides the result, so chunks can be queried as the walk
+// reaches them. Age limits and the deny-list drop slips one at a time, but the
+// other policies and the component and branch preferences weigh every
+// candidate in the ancestry together.
+func (c matchCriteria) streamable() bool {
+	return c.policy == domain.SelectionNearestCommit && c.component == "" && c.branch == ""
+}
+
+// streamAncestry searches HEAD's ancestry while streamer walks it, to maxDepth
+// if that is deeper than depth. Each chunkSize commits reached are
+// queried at once, up to chunkWorkers chunks in flight, instead of after the
+// whole walk, and the walk stops once the nearest chunk with a match is known.
+// A deep search thus answers as soon as its match is reached, and deepening
+// never walks the same commits twice. A commit the walk repeats is dropped.
+// criteria must be streamable.
+//
+// Returns the match (nil on a miss), the candidate count, and the commits
+// walked. Returns domain.ErrStreamUnsupported if streamer cannot stream.
+func (r *SlipResolver) streamAncestry(
+	ctx context.Context,
+	gitCtx *domain.GitContext,
+	streamer domain.AncestryStreamer,
+	depth, maxDepth int,
+	criteria matchCriteria,
+) (*domain.SlipMatch, int, []string, error) {
+	walkCtx, stopWalk := context.WithCancel(ctx)
+	defer stopWalk()
+	size := r.chunkSize()
+
+	var (
+		mu         sync.Mutex
+		chunks     [][]string
+		matches    = make(map[int]*domain.SlipMatch)
+		candidates = make(map[int]int)
+		ready      = make(chan int)
+		walked     = make(chan error, 1)
+	)
+	go func() {
+		defer close(ready)
+		var chunk []string
+		seen := make(map[string]struct{})
+		send := func() bool {
+			mu.Lock()
+			chunks = append(chunks, chunk)
+			i := len(chunks) - 1
+			mu.Unlock()
+			chunk = nil
+			select {
+			case ready <- i:
+				return true
+			case <-walkCtx.Done():
+				return false
+			}
+		}
+		err := streamer.StreamCommitAncestry(walkCtx, max(depth, maxDepth), func(sha string) bool {
+			if _, repeat := seen[sha]; repeat {
+				return true
+			}
+			seen[sha] = struct{}{}
+			chunk = append(chunk, sha)
+			return len(chunk) < size || send()
+		})
+		if err == nil && len(chunk) > 0 {
+			send()
+		}
+		walked <- err
+	}()
+
+	nearest, err := queryChunkStream(ctx, ready, r.chunks.counted(func(ctx context.Context, i int) (bool, error) {
+		mu.Lock()
+		commits := chunks[i]
+		mu.Unlock()
+		match, n, err := r.findMatch(ctx, gitCtx, commits, criteria)
+		mu.Lock()
+		matches[i], candidates[i] = match, n
+		mu.Unlock()
+		return match != nil, err
+	}))
+	stopWalk()
+	walkErr := <-walked
+
+	if errors.Is(walkErr, domain.ErrStreamUnsupported) {
+		return nil, 0, nil, walkErr
+	}
+	commits := slices.Concat(chunks...)
+	r.chunks.settle(nearest, len(chunks))
+	r.logger.Debug(ctx, "streamed commit ancestry search", map[string]interface{}{
+		"repository":    gitCtx.Repository,
+		"commits_count": len(commits),
+		"chunks":        len(chunks),
+	})
+	if err != nil {
+		return nil, 0, nil, domain.WithCode(domain.CodeStore, fmt.Errorf("failed to find slip by commits: %w", err))
+	}
+	if nearest >= 0 {
+		return matches[nearest], candidates[nearest], commits, nil
+	}
+	// Without a settled chunk the walk ran to its end, so its error is its own
+	if walkErr != nil {
+		return nil, 0, nil, domain.WithCode(domain.CodeGit, fmt.Errorf("failed to get commit ancestry: %w", walkErr))
+	}
+	return nil, 0, commits, nil
+}
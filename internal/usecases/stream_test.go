@@ -0,0 +1,124 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// streamingGitRepo is a mockLocalGitRepository that streams its history,
+// counting the commits yielded.
+type streamingGitRepo struct {
+	*mockLocalGitRepository
+
+	streamErr error
+	yielded   atomic.Int32
+}
+
+func (s *streamingGitRepo) StreamCommitAncestry(ctx context.Context, depth int, yield func(sha string) bool) error {
+	for _, sha := range s.history[:min(depth, len(s.history))] {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		s.yielded.Add(1)
+		if !yield(sha) {
+			return nil
+		}
+	}
+	return s.streamErr
+}
+
+func streamingHistory(n int) []string {
+	history := make([]string, n)
+	for i := range history {
+		history[i] = fmt.Sprintf("c%04d", i)
+	}
+	return history
+}
+
+func TestSlipResolver_Resolve_Streaming(t *testing.T) {
+	history := streamingHistory(5000)
+	slip := &domain.Slip{CorrelationID: "deep-slip", Repository: "org/repo"}
+	tests := []struct {
+		name         string
+		matchAt      string
+		depth        int
+		maxDepth     int
+		wantFound    bool
+		wantDistance int
+	}{
+		{name: "match within depth", matchAt: "c0010", depth: 25, wantFound: true, wantDistance: 10},
+		{name: "match found deepening", matchAt: "c0250", depth: 25, maxDepth: 5000, wantFound: true, wantDistance: 250},
+		{name: "miss beyond depth", matchAt: "c0030", depth: 25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &streamingGitRepo{mockLocalGitRepository: &mockLocalGitRepository{
+				gitContext: &domain.GitContext{HeadSHA: history[0], Repository: "org/repo"},
+				history:    history,
+			}}
+			finder := &mockSlipFinder{slipsByCommit: map[string]*domain.Slip{tt.matchAt: slip}}
+
+			output, err := NewSlipResolver(repo, finder, &mockLogger{}).Resolve(context.Background(), domain.ResolveInput{
+				Depth:    tt.depth,
+				MaxDepth: tt.maxDepth,
+			})
+
+			assert.Empty(t, repo.depths, "the ancestry is streamed, not walked up front")
+			for _, call := range finder.findByCommitsCalls {
+				assert.LessOrEqual(t, len(call.commits), ancestryChunkSize)
+			}
+			if !tt.wantFound {
+				assert.ErrorIs(t, err, domain.ErrNoAncestorSlip)
+				assert.ErrorContains(t, err, fmt.Sprintf("searched %d commits", tt.depth))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "deep-slip", output.CorrelationID)
+			assert.Equal(t, tt.wantDistance, output.Distance)
+			assert.Less(t, int(repo.yielded.Load()), len(history), "the walk stops once the match is known")
+		})
+	}
+}
+
+func TestSlipResolver_Resolve_StreamingFallsBack(t *testing.T) {
+	history := streamingHistory(50)
+	repo := &streamingGitRepo{mockLocalGitRepository: &mockLocalGitRepository{
+		gitContext: &domain.GitContext{HeadSHA: history[0], Repository: "org/repo", Branch: "main"},
+		history:    history,
+	}}
+	finder := &mockSlipFinder{slipsByCommit: map[string]*domain.Slip{"c0005": {CorrelationID: "slip-1"}}}
+
+	output, err := NewSlipResolver(repo, finder, &mockLogger{}).Resolve(context.Background(), domain.ResolveInput{
+		SelectionPolicy: domain.SelectionNewestCreated,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "slip-1", output.CorrelationID)
+	assert.Equal(t, []int{domain.DefaultAncestryDepth}, repo.depths, "policies weighing every candidate walk first")
+	assert.Zero(t, repo.yielded.Load())
+}
+
+func TestSlipResolver_Resolve_StreamingWalkError(t *testing.T) {
+	history := streamingHistory(10)
+	repo := &streamingGitRepo{
+		mockLocalGitRepository: &mockLocalGitRepository{
+			gitContext: &domain.GitContext{HeadSHA: history[0], Repository: "org/repo"},
+			history:    history,
+		},
+		streamErr: errors.New("corrupt object"),
+	}
+
+	_, err := NewSlipResolver(repo, &mockSlipFinder{}, &mockLogger{}).Resolve(context.Background(), domain.ResolveInput{})
+
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "corrupt object")
+	assert.Equal(t, domain.CodeGit, domain.CodeOf(err))
+}
@@ -0,0 +1,378 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// stageClock accumulates the time spent in each resolution stage. It is safe
+// for concurrent use, since chunk queries run in parallel; their durations are
+// summed, so a stage's total can exceed its wall-clock time.
+type stageClock struct {
+	mu      sync.Mutex
+	timings []domain.StageTiming
+}
+
+// Timings returns the accumulated durations, in the order stages first ran.
+func (c *stageClock) Timings() []domain.StageTiming {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.timings)
+}
+
+// observe adds the time elapsed since start to stage. Use it deferred:
+// defer c.observe(domain.StageStoreQuery, time.Now()).
+func (c *stageClock) observe(stage string, start time.Time) {
+	elapsed := time.Since(start)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := range c.timings {
+		if c.timings[i].Stage == stage {
+			c.timings[i].Duration += elapsed
+			return
+		}
+	}
+	c.timings = append(c.timings, domain.StageTiming{Stage: stage, Duration: elapsed})
+}
+
+// slowWatch warns about individual calls that take longer than their
+// operation's threshold. A nil slowWatch never warns.
+type slowWatch struct {
+	logger     Logger
+	thresholds domain.SlowThresholds
+
+	// depth is the requested ancestry depth, reported with slow store queries.
+	depth int
+}
+
+// gitWalk warns if a history walk to depth, started at start, was slow.
+func (w *slowWatch) gitWalk(ctx context.Context, start time.Time, depth int) {
+	if w == nil {
+		return
+	}
+	w.check(ctx, "slow git walk", start, w.thresholds.GitWalk, map[string]interface{}{
+		"depth": depth,
+	})
+}
+
+// storeQuery warns if a store lookup of chunkSize commits, started at start,
+// was slow. Lookups that do not take commits pass a chunkSize of 0.
+func (w *slowWatch) storeQuery(ctx context.Context, start time.Time, repository string, chunkSize int) {
+	if w == nil {
+		return
+	}
+	w.check(ctx, "slow store query", start, w.thresholds.StoreQuery, map[string]interface{}{
+		"repository": repository,
+		"chunk_size": chunkSize,
+		"depth":      w.depth,
+	})
+}
+
+// check logs msg with fields if more than threshold has passed since start.
+func (w *slowWatch) check(
+	ctx context.Context,
+	msg string,
+	start time.Time,
+	threshold time.Duration,
+	fields map[string]interface{},
+) {
+	elapsed := time.Since(start)
+	if threshold <= 0 || elapsed <= threshold {
+		return
+	}
+	fields["duration_ms"] = elapsed.Milliseconds()
+	fields["threshold_ms"] = threshold.Milliseconds()
+	w.logger.Warn(ctx, msg, fields)
+}
+
+// stageBudget bounds the total time spent in one stage's calls, as set by
+// domain.StageTimeouts. It is safe for concurrent use: the time any call is
+// running counts once, so parallel chunk queries share the budget by wall
+// clock. A nil stageBudget imposes no limit.
+type stageBudget struct {
+	stage string
+	limit time.Duration
+
+	mu     sync.Mutex
+	active int
+	since  time.Time
+	spent  time.Duration
+}
+
+// newStageBudget returns a budget of limit for stage, or nil if limit is not
+// positive.
+func newStageBudget(stage string, limit time.Duration) *stageBudget {
+	if limit <= 0 {
+		return nil
+	}
+	return &stageBudget{stage: stage, limit: limit}
+}
+
+// start begins a call in the stage. It returns the context to make the call
+// with, which expires when the budget runs out, and the function that ends the
+// call: it is passed the call's error and returns it, attributed to the stage
+// if it is a deadline failure caused by the budget running out. Returns domain.ErrStageTimeout if the budget is
+// already used up.
+func (b *stageBudget) start(ctx context.Context) (context.Context, func(error) error, error) {
+	if b == nil {
+		return ctx, func(err error) error { return err }, nil
+	}
+
+	b.mu.Lock()
+	now := time.Now()
+	remaining := b.limit - b.spent
+	if b.active > 0 {
+		remaining -= now.Sub(b.since)
+	}
+	if remaining <= 0 {
+		b.mu.Unlock()
+		return nil, nil, fmt.Errorf("%w: the %s stage used up its %s timeout", domain.ErrStageTimeout, b.stage, b.limit)
+	}
+	if b.active == 0 {
+		b.since = now
+	}
+	b.active++
+	b.mu.Unlock()
+
+	stageCtx, cancel := context.WithTimeout(ctx, remaining)
+	finish := func(err error) error {
+		expired := errors.Is(stageCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil
+		cancel()
+
+		b.mu.Lock()
+		b.active--
+		if b.active == 0 {
+			b.spent += time.Since(b.since)
+		}
+		b.mu.Unlock()
+
+		if expired && timedOut(err) {
+			return fmt.Errorf("%w: the %s stage exceeded its %s timeout: %w", domain.ErrStageTimeout, b.stage, b.limit, err)
+		}
+		return err
+	}
+	return stageCtx, finish, nil
+}
+
+// timedOut reports whether err is a deadline failure, either of a context or
+// of a connection given the context's deadline. Other errors of calls that
+// outlast the budget are returned as they are.
+func timedOut(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded)
+}
+
+// timedRepository records the time spent in the wrapped repository's calls
+// and bounds it by the git stage budget.
+type timedRepository struct {
+	domain.LocalGitRepository
+
+	clock  *stageClock
+	slow   *slowWatch
+	budget *stageBudget
+}
+
+// GetGitContext times the git context read.
+func (t *timedRepository) GetGitContext(ctx context.Context) (*domain.GitContext, error) {
+	stageCtx, finish, err := t.budget.start(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer t.clock.observe(domain.StageGitContext, time.Now())
+	gitCtx, err := t.LocalGitRepository.GetGitContext(stageCtx)
+	return gitCtx, finish(err)
+}
+
+// GetCommitAncestry times the HEAD ancestry walk.
+func (t *timedRepository) GetCommitAncestry(ctx context.Context, depth int) ([]string, error) {
+	stageCtx, finish, err := t.budget.start(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer t.slow.gitWalk(ctx, time.Now(), depth)
+	defer t.clock.observe(domain.StageAncestryWalk, time.Now())
+	commits, err := t.LocalGitRepository.GetCommitAncestry(stageCtx, depth)
+	return commits, finish(err)
+}
+
+// StreamCommitAncestry times the streamed HEAD ancestry walk if the wrapped
+// repository is a domain.AncestryStreamer. Returns domain.ErrStreamUnsupported
+// otherwise. The time yield takes is counted as walking.
+func (t *timedRepository) StreamCommitAncestry(ctx context.Context, depth int, yield func(sha string) bool) error {
+	streamer, ok := t.LocalGitRepository.(domain.AncestryStreamer)
+	if !ok {
+		return domain.ErrStreamUnsupported
+	}
+	stageCtx, finish, err := t.budget.start(ctx)
+	if err != nil {
+		return err
+	}
+	defer t.slow.gitWalk(ctx, time.Now(), depth)
+	defer t.clock.observe(domain.StageAncestryWalk, time.Now())
+	return finish(streamer.StreamCommitAncestry(stageCtx, depth, yield))
+}
+
+// GetMergeBaseAncestry times the merge-base ancestry walk.
+func (t *timedRepository) GetMergeBaseAncestry(ctx context.Context, branch string, depth int) ([]string, error) {
+	stageCtx, finish, err := t.budget.start(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer t.slow.gitWalk(ctx, time.Now(), depth)
+	defer t.clock.observe(domain.StageAncestryWalk, time.Now())
+	commits, err := t.LocalGitRepository.GetMergeBaseAncestry(stageCtx, branch, depth)
+	return commits, finish(err)
+}
+
+// GetPathAncestry times the path-filtered ancestry walk.
+func (t *timedRepository) GetPathAncestry(ctx context.Context, paths []string, depth int) ([]string, error) {
+	stageCtx, finish, err := t.budget.start(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer t.slow.gitWalk(ctx, time.Now(), depth)
+	defer t.clock.observe(domain.StageAncestryWalk, time.Now())
+	commits, err := t.LocalGitRepository.GetPathAncestry(stageCtx, paths, depth)
+	return commits, finish(err)
+}
+
+// GetPathAncestries times the multi-component ancestry walk.
+func (t *timedRepository) GetPathAncestries(
+	ctx context.Context,
+	paths map[string][]string,
+	depth int,
+) (map[string][]string, error) {
+	stageCtx, finish, err := t.budget.start(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer t.slow.gitWalk(ctx, time.Now(), depth)
+	defer t.clock.observe(domain.StageAncestryWalk, time.Now())
+	ancestries, err := t.LocalGitRepository.GetPathAncestries(stageCtx, paths, depth)
+	return ancestries, finish(err)
+}
+
+// GetAncestryFrom times the ancestry walk from rev.
+func (t *timedRepository) GetAncestryFrom(ctx context.Context, rev string, depth int) ([]string, error) {
+	stageCtx, finish, err := t.budget.start(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer t.slow.gitWalk(ctx, time.Now(), depth)
+	defer t.clock.observe(domain.StageAncestryWalk, time.Now())
+	commits, err := t.LocalGitRepository.GetAncestryFrom(stageCtx, rev, depth)
+	return commits, finish(err)
+}
+
+// timedFinder records the time spent in the wrapped finder's lookups and
+// bounds it by the store stage budget.
+type timedFinder struct {
+	domain.SlipFinder
+
+	clock  *stageClock
+	slow   *slowWatch
+	budget *stageBudget
+}
+
+// FindByCommits times the lookup.
+func (t *timedFinder) FindByCommits(
+	ctx context.Context,
+	repository string,
+	commits []string,
+) (*domain.Slip, string, error) {
+	stageCtx, finish, err := t.budget.start(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	defer t.slow.storeQuery(ctx, time.Now(), repository, len(commits))
+	defer t.clock.observe(domain.StageStoreQuery, time.Now())
+	slip, commit, err := t.SlipFinder.FindByCommits(stageCtx, repository, commits)
+	return slip, commit, finish(err)
+}
+
+// FindAllByCommits times the lookup.
+func (t *timedFinder) FindAllByCommits(
+	ctx context.Context,
+	repository string,
+	commits []string,
+) ([]domain.SlipMatch, error) {
+	stageCtx, finish, err := t.budget.start(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer t.slow.storeQuery(ctx, time.Now(), repository, len(commits))
+	defer t.clock.observe(domain.StageStoreQuery, time.Now())
+	matches, err := t.SlipFinder.FindAllByCommits(stageCtx, repository, commits)
+	return matches, finish(err)
+}
+
+// FindNearestByCommits times the lookup if the wrapped finder is a
+// domain.NearestSlipsFinder. Returns domain.ErrNearestUnsupported otherwise.
+func (t *timedFinder) FindNearestByCommits(
+	ctx context.Context,
+	repository string,
+	commits []string,
+) ([]domain.SlipMatch, error) {
+	nearest, ok := t.SlipFinder.(domain.NearestSlipsFinder)
+	if !ok {
+		return nil, domain.ErrNearestUnsupported
+	}
+	stageCtx, finish, err := t.budget.start(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer t.slow.storeQuery(ctx, time.Now(), repository, len(commits))
+	defer t.clock.observe(domain.StageStoreQuery, time.Now())
+	matches, err := nearest.FindNearestByCommits(stageCtx, repository, commits)
+	return matches, finish(err)
+}
+
+// FindLatestByBranch times the lookup.
+func (t *timedFinder) FindLatestByBranch(ctx context.Context, repository, branch string) (*domain.Slip, error) {
+	stageCtx, finish, err := t.budget.start(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer t.slow.storeQuery(ctx, time.Now(), repository, 0)
+	defer t.clock.observe(domain.StageStoreQuery, time.Now())
+	slip, err := t.SlipFinder.FindLatestByBranch(stageCtx, repository, branch)
+	return slip, finish(err)
+}
+
+// FindByID times the lookup.
+func (t *timedFinder) FindByID(ctx context.Context, correlationID string) (*domain.Slip, error) {
+	stageCtx, finish, err := t.budget.start(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer t.slow.storeQuery(ctx, time.Now(), "", 0)
+	defer t.clock.observe(domain.StageStoreQuery, time.Now())
+	slip, err := t.SlipFinder.FindByID(stageCtx, correlationID)
+	return slip, finish(err)
+}
+
+// AppendHistory delegates to the wrapped finder if it is a domain.SlipAnnotator.
+// Returns domain.ErrAnnotationUnsupported otherwise. It is not timed.
+func (t *timedFinder) AppendHistory(ctx context.Context, correlationID string, entry domain.HistoryEntry) error {
+	annotator, ok := t.SlipFinder.(domain.SlipAnnotator)
+	if !ok {
+		return domain.ErrAnnotationUnsupported
+	}
+	return annotator.AppendHistory(ctx, correlationID, entry)
+}
+
+// ChunkSize delegates to the wrapped finder if it is a domain.ChunkSizer.
+// Returns 0, the resolver's default, otherwise.
+func (t *timedFinder) ChunkSize() int {
+	if sizer, ok := t.SlipFinder.(domain.ChunkSizer); ok {
+		return sizer.ChunkSize()
+	}
+	return 0
+}
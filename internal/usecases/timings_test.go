@@ -0,0 +1,262 @@
+package usecases
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+func TestStageClock(t *testing.T) {
+	clock := &stageClock{}
+	start := time.Now().Add(-time.Second)
+
+	clock.observe(domain.StageGitContext, start)
+	clock.observe(domain.StageStoreQuery, start)
+	clock.observe(domain.StageGitContext, start)
+
+	timings := clock.Timings()
+	require.Len(t, timings, 2)
+	assert.Equal(t, domain.StageGitContext, timings[0].Stage)
+	assert.GreaterOrEqual(t, timings[0].Duration, 2*time.Second)
+	assert.Equal(t, domain.StageStoreQuery, timings[1].Stage)
+	assert.GreaterOrEqual(t, timings[1].Duration, time.Second)
+}
+
+func TestSlipResolver_Resolve_Timings(t *testing.T) {
+	mockGit := &mockLocalGitRepository{
+		gitContext: &domain.GitContext{HeadSHA: "abc123", Branch: "main", Repository: "org/repo"},
+		commits:    []string{"abc123", "def456"},
+	}
+	mockFinder := &mockSlipFinder{
+		findByCommitsSlip:   &domain.Slip{CorrelationID: "slip-1"},
+		findByCommitsCommit: "def456",
+	}
+	resolver := NewSlipResolver(mockGit, mockFinder, &mockLogger{})
+
+	output, err := resolver.Resolve(context.Background(), domain.ResolveInput{Depth: 10})
+
+	require.NoError(t, err)
+	stages := make([]string, 0, len(output.Timings))
+	for _, timing := range output.Timings {
+		stages = append(stages, timing.Stage)
+	}
+	assert.Equal(t, []string{domain.StageGitContext, domain.StageAncestryWalk, domain.StageStoreQuery}, stages)
+}
+
+func TestTimedFinder_AppendHistoryUnsupported(t *testing.T) {
+	finder := &timedFinder{SlipFinder: &mockSlipFinder{}, clock: &stageClock{}}
+
+	err := finder.AppendHistory(context.Background(), "slip-1", domain.HistoryEntry{})
+
+	assert.ErrorIs(t, err, domain.ErrAnnotationUnsupported)
+}
+
+func TestTimedFinder_FindNearestByCommitsUnsupported(t *testing.T) {
+	finder := &timedFinder{SlipFinder: &mockSlipFinder{}, clock: &stageClock{}}
+
+	_, err := finder.FindNearestByCommits(context.Background(), "org/repo", []string{"c1"})
+
+	assert.ErrorIs(t, err, domain.ErrNearestUnsupported)
+}
+
+// warnLogger records the warnings logged through it.
+type warnLogger struct {
+	mockLogger
+
+	mu       sync.Mutex
+	messages []string
+	fields   []map[string]interface{}
+}
+
+func (l *warnLogger) Warn(_ context.Context, msg string, fields map[string]interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.messages = append(l.messages, msg)
+	l.fields = append(l.fields, fields)
+}
+
+func TestSlowWatch(t *testing.T) {
+	started := time.Now().Add(-2 * time.Second)
+
+	tests := []struct {
+		name       string
+		thresholds domain.SlowThresholds
+		wantWarn   bool
+	}{
+		{
+			name:       "slower than threshold",
+			thresholds: domain.SlowThresholds{GitWalk: time.Second},
+			wantWarn:   true,
+		},
+		{name: "within threshold", thresholds: domain.SlowThresholds{GitWalk: time.Minute}},
+		{name: "disabled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := &warnLogger{}
+			watch := &slowWatch{logger: log, thresholds: tt.thresholds, depth: 25}
+
+			watch.gitWalk(context.Background(), started, 100)
+
+			if !tt.wantWarn {
+				assert.Empty(t, log.messages)
+				return
+			}
+			require.Equal(t, []string{"slow git walk"}, log.messages)
+			assert.Equal(t, 100, log.fields[0]["depth"])
+			assert.Equal(t, int64(1000), log.fields[0]["threshold_ms"])
+			assert.GreaterOrEqual(t, log.fields[0]["duration_ms"], int64(2000))
+		})
+	}
+}
+
+func TestSlowWatch_Nil(t *testing.T) {
+	var watch *slowWatch
+
+	assert.NotPanics(t, func() {
+		watch.gitWalk(context.Background(), time.Now(), 25)
+		watch.storeQuery(context.Background(), time.Now(), "org/repo", 100)
+	})
+}
+
+func TestSlipResolver_Resolve_SlowWarnings(t *testing.T) {
+	mockGit := &mockLocalGitRepository{
+		gitContext: &domain.GitContext{HeadSHA: "abc123", Branch: "main", Repository: "org/repo"},
+		commits:    []string{"abc123", "def456"},
+	}
+	mockFinder := &mockSlipFinder{
+		findByCommitsSlip:   &domain.Slip{CorrelationID: "slip-1"},
+		findByCommitsCommit: "def456",
+	}
+	log := &warnLogger{}
+	resolver := NewSlipResolver(mockGit, mockFinder, log)
+
+	// Any call takes longer than a nanosecond
+	_, err := resolver.Resolve(context.Background(), domain.ResolveInput{
+		Depth:          10,
+		SlowThresholds: domain.SlowThresholds{GitWalk: time.Nanosecond, StoreQuery: time.Nanosecond},
+	})
+
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(log.messages), 2)
+	assert.Equal(t, "slow git walk", log.messages[0])
+	assert.Equal(t, "slow store query", log.messages[1])
+	assert.Equal(t, 10, log.fields[0]["depth"])
+	assert.Equal(t, "org/repo", log.fields[1]["repository"])
+	assert.Equal(t, 2, log.fields[1]["chunk_size"])
+	assert.Equal(t, 10, log.fields[1]["depth"])
+}
+
+func TestTimedRepository_StreamCommitAncestryUnsupported(t *testing.T) {
+	repo := &timedRepository{LocalGitRepository: &mockLocalGitRepository{}, clock: &stageClock{}}
+
+	err := repo.StreamCommitAncestry(context.Background(), 10, func(string) bool { return true })
+
+	assert.ErrorIs(t, err, domain.ErrStreamUnsupported)
+}
+
+func TestStageBudget(t *testing.T) {
+	t.Run("attributes an expired call to its stage", func(t *testing.T) {
+		budget := newStageBudget("store", 10*time.Millisecond)
+
+		ctx, finish, err := budget.start(context.Background())
+		require.NoError(t, err)
+		<-ctx.Done()
+		err = finish(ctx.Err())
+
+		require.ErrorIs(t, err, domain.ErrStageTimeout)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Contains(t, err.Error(), "store stage exceeded its 10ms timeout")
+		assert.Equal(t, domain.CodeStageTimeout, domain.CodeOf(err))
+
+		_, _, err = budget.start(context.Background())
+		require.ErrorIs(t, err, domain.ErrStageTimeout)
+		assert.Contains(t, err.Error(), "store stage used up its 10ms timeout")
+	})
+
+	t.Run("leaves a cancelled caller's error alone", func(t *testing.T) {
+		budget := newStageBudget("git", time.Minute)
+		parent, cancel := context.WithCancel(context.Background())
+
+		ctx, finish, err := budget.start(parent)
+		require.NoError(t, err)
+		cancel()
+		err = finish(ctx.Err())
+
+		require.ErrorIs(t, err, context.Canceled)
+		assert.NotErrorIs(t, err, domain.ErrStageTimeout)
+	})
+
+	t.Run("leaves other errors of an expired call alone", func(t *testing.T) {
+		budget := newStageBudget("git", time.Millisecond)
+
+		ctx, finish, err := budget.start(context.Background())
+		require.NoError(t, err)
+		<-ctx.Done()
+		err = finish(domain.ErrNoRemoteOrigin)
+
+		assert.Equal(t, domain.ErrNoRemoteOrigin, err)
+	})
+
+	t.Run("counts concurrent calls once", func(t *testing.T) {
+		budget := newStageBudget("store", time.Minute)
+
+		_, finishFirst, err := budget.start(context.Background())
+		require.NoError(t, err)
+		_, finishSecond, err := budget.start(context.Background())
+		require.NoError(t, err)
+		time.Sleep(10 * time.Millisecond)
+		require.NoError(t, finishFirst(nil))
+		require.NoError(t, finishSecond(nil))
+
+		assert.Less(t, budget.spent, time.Second)
+		assert.GreaterOrEqual(t, budget.spent, 10*time.Millisecond)
+		assert.Zero(t, budget.active)
+	})
+
+	t.Run("no limit", func(t *testing.T) {
+		budget := newStageBudget("git", 0)
+		require.Nil(t, budget)
+
+		ctx, finish, err := budget.start(context.Background())
+		require.NoError(t, err)
+		_, hasDeadline := ctx.Deadline()
+		assert.False(t, hasDeadline)
+		assert.Equal(t, assert.AnError, finish(assert.AnError))
+	})
+}
+
+// stallingFinder is a slip finder whose commit lookups block until their
+// context is done.
+type stallingFinder struct {
+	mockSlipFinder
+}
+
+func (f *stallingFinder) FindByCommits(ctx context.Context, _ string, _ []string) (*domain.Slip, string, error) {
+	<-ctx.Done()
+	return nil, "", ctx.Err()
+}
+
+func TestSlipResolver_Resolve_StageTimeout(t *testing.T) {
+	mockGit := &mockLocalGitRepository{
+		gitContext: &domain.GitContext{HeadSHA: "abc123", Branch: "main", Repository: "org/repo"},
+		commits:    []string{"abc123", "def456"},
+	}
+	resolver := NewSlipResolver(mockGit, &stallingFinder{}, &mockLogger{})
+
+	_, err := resolver.Resolve(context.Background(), domain.ResolveInput{
+		Depth:         10,
+		StageTimeouts: domain.StageTimeouts{Git: time.Minute, Store: 20 * time.Millisecond},
+	})
+
+	require.ErrorIs(t, err, domain.ErrStageTimeout)
+	assert.Equal(t, domain.CodeStageTimeout, domain.CodeOf(err))
+	assert.Contains(t, err.Error(), "store stage exceeded its 20ms timeout")
+}
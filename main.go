@@ -4,57 +4,192 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	ch "github.com/MyCarrier-DevOps/goLibMyCarrier/clickhouse"
 	"github.com/MyCarrier-DevOps/goLibMyCarrier/logger"
 	"github.com/MyCarrier-DevOps/goLibMyCarrier/slippy"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 
 	"github.com/MyCarrier-DevOps/slippy-find/cmd"
+	"github.com/MyCarrier-DevOps/slippy-find/internal/adapters/argocd"
+	"github.com/MyCarrier-DevOps/slippy-find/internal/adapters/azuredevops"
+	"github.com/MyCarrier-DevOps/slippy-find/internal/adapters/buildkite"
+	"github.com/MyCarrier-DevOps/slippy-find/internal/adapters/bundle"
 	"github.com/MyCarrier-DevOps/slippy-find/internal/adapters/git"
+	"github.com/MyCarrier-DevOps/slippy-find/internal/adapters/github"
 	logadapter "github.com/MyCarrier-DevOps/slippy-find/internal/adapters/logger"
+	"github.com/MyCarrier-DevOps/slippy-find/internal/adapters/metrics"
+	"github.com/MyCarrier-DevOps/slippy-find/internal/adapters/notify"
 	"github.com/MyCarrier-DevOps/slippy-find/internal/adapters/output"
+	"github.com/MyCarrier-DevOps/slippy-find/internal/adapters/queue"
+	"github.com/MyCarrier-DevOps/slippy-find/internal/adapters/reporting"
+	"github.com/MyCarrier-DevOps/slippy-find/internal/adapters/server"
 	"github.com/MyCarrier-DevOps/slippy-find/internal/adapters/store"
-	"github.com/MyCarrier-DevOps/slippy-find/internal/domain"
 	"github.com/MyCarrier-DevOps/slippy-find/internal/infrastructure/config"
+	"github.com/MyCarrier-DevOps/slippy-find/internal/infrastructure/telemetry"
 	"github.com/MyCarrier-DevOps/slippy-find/internal/usecases"
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/extension"
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/gitcontext"
 )
 
 func main() {
-	// Create a single shared logger instance for the application
-	zapLog := logger.NewZapLoggerFromConfig()
+	// Load .env before anything reads the environment (logger included)
+	envFile, envKeys, envErr := config.LoadDotEnv()
+
+	// Export logs over OTLP too when a logs endpoint is configured
+	logProvider, shutdownLogs, logExportErr := telemetry.SetupLogging(context.Background(), cmd.Version)
+	var logCores []zapcore.Core
+	if logProvider != nil {
+		logCores = append(logCores, logadapter.NewOTelCore(logProvider, cmd.Version))
+	}
+
+	// Create a single shared logger instance for the application; every
+	// logger built for it shares one level, which --verbose can lower
+	logLevel := logadapter.NewLevel()
+	zapLog, closeLog := newZapLogger(logLevel, logCores...)
 	adapter := logadapter.NewZapAdapter(zapLog)
 
+	if logExportErr != nil {
+		adapter.Warn(context.Background(), "failed to set up log export", map[string]interface{}{
+			"error": logExportErr.Error(),
+		})
+		shutdownLogs = func(context.Context) error { return nil }
+	}
+
+	switch {
+	case errors.Is(envErr, config.ErrDotEnvUnavailable):
+		// Reported as the failure of every command loading configuration (see configLoader)
+	case envErr != nil:
+		adapter.Warn(context.Background(), "failed to load .env file", map[string]interface{}{
+			"path":  envFile,
+			"error": envErr.Error(),
+		})
+	case envFile != "":
+		adapter.Debug(context.Background(), "loaded .env file", map[string]interface{}{
+			"path": envFile,
+			"keys": envKeys,
+		})
+	}
+
+	// Join the CI job's trace, exporting spans when an OTLP endpoint is configured
+	ctx := telemetry.ContextFromEnv(context.Background())
+	shutdownTracing, err := telemetry.SetupTracing(ctx, cmd.Version)
+	if err != nil {
+		adapter.Warn(ctx, "failed to set up tracing", map[string]interface{}{
+			"error": err.Error(),
+		})
+		shutdownTracing = func(context.Context) error { return nil }
+	}
+
+	// Metrics are recorded by instrumented adapters and exported on request
+	recorder := metrics.New()
+	statsd := connectStatsD(ctx, recorder, adapter)
+
+	// Unexpected failures are reported when Sentry or an error webhook is configured
+	errorReporter := setupErrorReporting(ctx, adapter)
+
+	// Misses and store failures are announced when a Slack or Teams webhook is configured
+	failureNotifier := setupNotify(ctx, adapter)
+
+	// Resolutions are streamed to NATS or Kafka when an events queue is configured
+	resolutionPublisher := setupEvents(ctx, adapter)
+
 	// Wire up production dependencies
 	deps := &cmd.Dependencies{
 		LoggerFactory: func() cmd.Logger {
+			// Rebuild now that --log-format and --log-file have updated the environment
+			next, closeNext := newZapLogger(logLevel, logCores...)
+			adapter.SetLogger(next)
+			_ = closeLog()
+			zapLog, closeLog = next, closeNext
 			return adapter
 		},
 
-		ConfigLoader: func() (*cmd.AppConfig, error) {
-			cfg, err := config.Load()
+		DebugLogging: func() {
+			logLevel.SetLevel(zapcore.DebugLevel)
+		},
+
+		ConfigLoader: configLoader(envErr),
+
+		VaultChecker: func(ctx context.Context) (bool, error) {
+			return config.CheckVault(ctx, nil)
+		},
+
+		ComponentLoader: config.LoadComponents,
+
+		PullRequestLoader: func() (*domain.PullRequest, error) {
+			event, err := config.LoadPullRequestEvent()
 			if err != nil {
 				return nil, err
 			}
-			return &cmd.AppConfig{
-				ClickHouseConfig: cfg.ClickHouse,
-				PipelineConfig:   cfg.PipelineConfig,
-				Database:         cfg.Database,
-				LogLevel:         cfg.LogLevel,
-				LogAppName:       cfg.LogAppName,
+			return &domain.PullRequest{
+				HeadSHA: event.HeadSHA,
+				BaseRef: event.BaseRef,
+				BaseSHA: event.BaseSHA,
 			}, nil
 		},
 
-		GitRepoFactory: func(path string, _ cmd.Logger) (domain.LocalGitRepository, error) {
-			return git.NewGoGitRepository(path, adapter)
+		HintLoader: config.LoadHint,
+
+		AnnotationLoader: func() domain.Annotation {
+			identity := config.DetectBuildIdentity()
+			return domain.Annotation{Host: identity.Host, Pipeline: identity.Pipeline}
 		},
 
-		SlipFinderFactory: func(cfg *cmd.AppConfig, _ cmd.Logger) (domain.SlipFinder, error) {
+		// Adapters log through the session's logger, so --debug-bundle captures their entries
+		GitRepoFactory: func(path string, log cmd.Logger) (domain.LocalGitRepository, error) {
+			gitCfg, err := config.LoadGitConfig()
+			if err != nil {
+				return nil, domain.WithCode(domain.CodeConfiguration, err)
+			}
+			opts := []gitcontext.Option{gitcontext.WithMaxCommits(gitCfg.MaxCommits)}
+			if gitCfg.AncestryCache {
+				opts = append(opts, gitcontext.WithAncestryCache())
+			}
+			repo, err := git.NewGoGitRepository(path, log, opts...)
+			if err != nil {
+				return nil, err
+			}
+			return recorder.InstrumentGit(repo), nil
+		},
+
+		RepoRootFinder: func(path string) (string, error) {
+			gitCfg, err := config.LoadGitConfig()
+			if err != nil {
+				return "", domain.WithCode(domain.CodeConfiguration, err)
+			}
+			return gitcontext.FindRoot(path, gitCfg.CeilingDirectories...)
+		},
+
+		SlipFinderFactory: func(cfg *cmd.AppConfig, log cmd.Logger) (domain.SlipFinder, error) {
+			switch {
+			case cfg.FinderPlugin != "":
+				return recorder.InstrumentFinder(store.NewExecFinder(cfg.FinderPlugin)), nil
+			case cfg.Store == config.StoreMock:
+				warnMockStore(log)
+				return recorder.InstrumentFinder(store.NewMockFinder()), nil
+			}
+
 			chConfig, ok := cfg.ClickHouseConfig.(*ch.ClickhouseConfig)
 			if !ok {
 				return nil, newConfigTypeError("*ch.ClickhouseConfig")
 			}
 
+			queryCfg, err := config.LoadQueryConfig()
+			if err != nil {
+				return nil, domain.WithCode(domain.CodeConfiguration, err)
+			}
+
 			pipelineCfg, ok := cfg.PipelineConfig.(*slippy.PipelineConfig)
 			if !ok {
 				return nil, newConfigTypeError("*slippy.PipelineConfig")
@@ -69,27 +204,559 @@ func main() {
 			if err != nil {
 				return nil, err
 			}
-			return store.NewClickHouseAdapter(slippyStore), nil
+			finder := store.NewClickHouseAdapterWithSession(slippyStore, slippyStore.Session(), cfg.Database,
+				store.WithChunkSize(queryCfg.ChunkSize),
+				store.WithMaxQueryCommits(queryCfg.MaxQueryCommits),
+				store.WithCommitList(store.CommitList(queryCfg.CommitList)),
+			)
+			return recorder.InstrumentFinder(finder), nil
+		},
+
+		AuditWriterFactory: func(cfg *cmd.AppConfig, _ cmd.Logger) (domain.AuditWriter, error) {
+			if err := requireClickHouse("the audit log", cfg); err != nil {
+				return nil, err
+			}
+			chConfig, ok := cfg.ClickHouseConfig.(*ch.ClickhouseConfig)
+			if !ok {
+				return nil, newConfigTypeError("*ch.ClickhouseConfig")
+			}
+			session, err := ch.NewClickhouseSession(chConfig, context.Background())
+			if err != nil {
+				return nil, err
+			}
+			return store.NewClickHouseAuditWriter(session, cfg.Database), nil
 		},
 
 		ResolverFactory: func(
 			gitRepo domain.LocalGitRepository,
 			finder domain.SlipFinder,
-			_ cmd.Logger,
+			log cmd.Logger,
 		) domain.Resolver {
-			return usecases.NewSlipResolver(gitRepo, finder, adapter)
+			// The built-in strategies, plus any compiled-in extensions registered
+			strategies := extension.Strategies()
+			return recorder.InstrumentResolver(
+				usecases.NewSlipResolverWithRegistry(gitRepo, finder, log, strategies))
+		},
+
+		ServerFactory: newServer(recorder),
+		WorkerFactory: newWorker,
+		SecretsLoader: config.LoadSecretsDir,
+		TenantLoader:  loadTenants,
+		DaemonStarter: server.StartDaemon,
+
+		SocketResolverFactory: func(path string) cmd.RemoteResolver {
+			return server.NewSocketClient(path)
+		},
+
+		// Commit lists from serve-mode clients are instrumented like local repositories
+		AncestryRepoFactory: func(repository, branch string, commits []string) domain.LocalGitRepository {
+			return recorder.InstrumentGit(git.NewKnownAncestry(repository, branch, commits))
+		},
+
+		MetricsExporter: recorder,
+
+		ErrorReporter: errorReporter,
+
+		FailureNotifier: failureNotifier,
+
+		ResolutionPublisher: resolutionPublisher,
+
+		DebugBundleWriter: bundle.NewWriter(logadapter.NewRedactor(logadapter.SecretsFromEnv()...).Redact),
+
+		DotenvWriter: output.WriteDotenv,
+
+		BuildkiteMetaData: buildkite.NewAgent("").SetMetaData,
+
+		ArgoCDAnnotator: annotateArgoCDApp,
+
+		OctopusVariableWriter: output.WriteOctopusVariables,
+
+		BackstageWriter: output.WriteBackstageFragment,
+
+		SpinnakerArtifactWriter: output.WriteSpinnakerArtifact,
+
+		AzureDevOpsTagger: tagAzureDevOpsBuild,
+
+		GitHubDeployer: createGitHubDeployment,
+
+		ResultPoster: func(ctx context.Context, url string, body []byte) error {
+			return notify.NewResultWebhook(config.LoadNotifySecret()).Post(ctx, url, body)
 		},
 
 		OutputWriterFactory: func() domain.OutputWriter {
 			return output.NewWriter()
 		},
 
+		OutputFormats: extension.OutputFormats(),
+
 		Stdout: os.Stdout,
 		Stderr: os.Stderr,
 	}
 
-	cmd.SetDefaultDependencies(deps)
-	cmd.Execute()
+	// Serve and worker switch store connections when the store settings change
+	deps.StoreReloader = func(
+		ctx context.Context,
+		cfg *cmd.AppConfig,
+		finder domain.SlipFinder,
+		log cmd.Logger,
+	) domain.SlipFinder {
+		return reloadStore(ctx, cfg, finder, log, deps.SlipFinderFactory)
+	}
+
+	// Resolution is tried through a local daemon first when SLIPPY_DAEMON names one
+	daemon, err := config.LoadDaemonAddress()
+	switch {
+	case err != nil:
+		adapter.Warn(ctx, "ignoring daemon configuration", map[string]interface{}{
+			"error": err.Error(),
+		})
+	case daemon.Socket != "":
+		deps.DaemonResolverFactory = func() cmd.RemoteResolver { return server.NewSocketClient(daemon.Socket) }
+	case daemon.URL != "":
+		deps.DaemonResolverFactory = func() cmd.RemoteResolver { return server.NewHTTPClient(daemon.URL) }
+	}
+
+	app := cmd.NewApp(deps)
+
+	// A write to a closed stdout pipe fails with EPIPE instead of killing the
+	// process, so commands can stop cleanly and the cleanup below still runs.
+	signal.Ignore(syscall.SIGPIPE)
+
+	// SIGTERM (pod termination) and Ctrl-C cancel the run, so serve and worker
+	// can drain; a second signal exits at once.
+	runCtx, stopSignals := signal.NotifyContext(ctx, syscall.SIGTERM, os.Interrupt)
+	context.AfterFunc(runCtx, stopSignals)
+	runErr := app.Run(runCtx, os.Args[1:])
+	stopSignals()
+
+	// Flush spans before exiting; a slow collector must not hold up the build
+	flushCtx, cancel := context.WithTimeout(context.Background(), tracingFlushTimeout)
+	if err := shutdownTracing(flushCtx); err != nil {
+		adapter.Warn(ctx, "failed to flush traces", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	cancel()
+	if statsd != nil {
+		_ = statsd.Close()
+	}
+	if errorReporter != nil && !errorReporter.Flush(tracingFlushTimeout) {
+		adapter.Warn(ctx, "timed out sending error reports", nil)
+	}
+
+	// Logs are flushed last, so the warnings above are exported too
+	flushCtx, cancel = context.WithTimeout(context.Background(), tracingFlushTimeout)
+	if err := shutdownLogs(flushCtx); err != nil {
+		adapter.Warn(ctx, "failed to flush logs", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	cancel()
+	_ = closeLog()
+
+	if runErr != nil {
+		os.Exit(cmd.ExitCode(runErr))
+	}
+}
+
+// newServer returns a factory for the serve-mode HTTP server, which serves
+// recorder's metrics on GET /metrics. The factory loads the bearer tokens from
+// opts.TokenFile, the webhook secret from opts.WebhookSecretFile, and the admin
+// tokens from opts.AdminTokenFile when given.
+func newServer(
+	recorder *metrics.Metrics,
+) func(opts cmd.ServeOptions, resolve cmd.ResolveRequestFunc, log cmd.Logger) (cmd.Server, error) {
+	return func(opts cmd.ServeOptions, resolve cmd.ResolveRequestFunc, log cmd.Logger) (cmd.Server, error) {
+		return buildServer(opts, resolve, log, recorder.Handler())
+	}
+}
+
+// buildServer builds the serve-mode HTTP server for newServer, serving
+// metricsHandler on GET /metrics.
+func buildServer(
+	opts cmd.ServeOptions,
+	resolve cmd.ResolveRequestFunc,
+	log cmd.Logger,
+	metricsHandler http.Handler,
+) (cmd.Server, error) {
+	var tokens map[string]string
+	if opts.TokenFile != "" {
+		var err error
+		tokens, err = config.LoadServeTokens(opts.TokenFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var webhookSecret []byte
+	if opts.WebhookSecretFile != "" {
+		var err error
+		webhookSecret, err = config.LoadWebhookSecret(opts.WebhookSecretFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var admin *server.Admin
+	if opts.AdminTokenFile != "" && opts.Admin != nil {
+		var err error
+		admin, err = newServerAdmin(opts.AdminTokenFile, opts.Admin)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return server.New(server.Config{
+		Addr:            opts.Addr,
+		SocketPath:      opts.SocketPath,
+		TLSCertFile:     opts.TLSCertFile,
+		TLSKeyFile:      opts.TLSKeyFile,
+		ClientCAFile:    opts.ClientCAFile,
+		Tokens:          tokens,
+		RateLimit:       opts.RateLimit,
+		RateBurst:       opts.RateBurst,
+		WebhookSecret:   webhookSecret,
+		Ping:            opts.Ping,
+		ShutdownTimeout: opts.ShutdownTimeout,
+		ProbeAddr:       opts.ProbeAddr,
+		Metrics:         metricsHandler,
+		Admin:           admin,
+	}, server.ResolveFunc(resolve), log)
+}
+
+// newServerAdmin adapts hooks to the server's admin endpoints, accepting the
+// operator tokens listed in tokenFile.
+func newServerAdmin(tokenFile string, hooks *cmd.AdminHooks) (*server.Admin, error) {
+	tokens, err := config.LoadServeTokens(tokenFile)
+	if err != nil {
+		return nil, err
+	}
+	return &server.Admin{
+		Tokens: tokens,
+		CacheStats: func() map[string]server.CacheStats {
+			stats := make(map[string]server.CacheStats)
+			for name, s := range hooks.CacheStats() {
+				stats[name] = server.CacheStats(s)
+			}
+			return stats
+		},
+		FlushCache: hooks.FlushCache,
+		Settings: func() []server.Setting {
+			var settings []server.Setting
+			for _, s := range hooks.Settings() {
+				settings = append(settings, server.Setting{Key: s.Key, Value: s.Value, Source: s.Source, Detail: s.Detail})
+			}
+			return settings
+		},
+	}, nil
+}
+
+// configLoader returns loadAppConfig, or a loader failing with envErr when the
+// file named by SLIPPY_ENV_FILE could not be loaded: the variables it was
+// meant to set are missing, so no command may run on the configuration left.
+func configLoader(envErr error) func() (*cmd.AppConfig, error) {
+	if errors.Is(envErr, config.ErrDotEnvUnavailable) {
+		return func() (*cmd.AppConfig, error) { return nil, envErr }
+	}
+	return loadAppConfig
+}
+
+// loadAppConfig loads the configuration from the environment and Vault.
+func loadAppConfig() (*cmd.AppConfig, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	fingerprint, err := config.Fingerprint(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &cmd.AppConfig{
+		ClickHouseConfig:    cfg.ClickHouse,
+		PipelineConfig:      cfg.PipelineConfig,
+		Database:            cfg.Database,
+		LogLevel:            cfg.LogLevel,
+		LogAppName:          cfg.LogAppName,
+		LogFormat:           cfg.LogFormat,
+		LogFile:             cfg.LogFile,
+		Audit:               cfg.Audit,
+		DenyList:            cfg.DenyList,
+		CorrelationIDFormat: cfg.CorrelationIDFormat,
+		FinderPlugin:        cfg.FinderPlugin,
+		Store:               cfg.Store,
+		GitTimeout:          cfg.GitTimeout,
+		StoreTimeout:        cfg.StoreTimeout,
+		Settings:            toConfigSettings(cfg.Settings),
+		StoreFingerprint:    fingerprint,
+	}, nil
+}
+
+// warnMockStore warns that the mock store is in use, so a dry-run setting
+// left in a real pipeline's environment does not go unnoticed.
+func warnMockStore(log cmd.Logger) {
+	log.Warn(context.Background(), "using the mock slip store: correlation IDs are fake", map[string]interface{}{
+		"store": config.StoreMock,
+	})
+}
+
+// reloadStore re-reads configuration every SLIPPY_CONFIG_RELOAD_INTERVAL
+// until ctx is done, switching the returned finder to a new one from
+// openFinder when the store settings differ from cfg's. If the new store
+// cannot be opened, the current one is kept until the settings change again.
+func reloadStore(
+	ctx context.Context,
+	cfg *cmd.AppConfig,
+	finder domain.SlipFinder,
+	log cmd.Logger,
+	openFinder func(cfg *cmd.AppConfig, log cmd.Logger) (domain.SlipFinder, error),
+) domain.SlipFinder {
+	swappable := store.NewSwappableFinder(finder)
+	watcher := config.NewWatcher(
+		loadAppConfig,
+		func(cfg *cmd.AppConfig) (string, error) { return cfg.StoreFingerprint, nil },
+		config.ReloadIntervalFromEnv(),
+		func(next *cmd.AppConfig) {
+			nextFinder, err := openFinder(next, log)
+			if err != nil {
+				log.Error(ctx, "failed to open the reconfigured slip store; keeping the current one", err, nil)
+				return
+			}
+			if err := swappable.Swap(nextFinder); err != nil {
+				log.Warn(ctx, "failed to close the previous slip store", map[string]interface{}{
+					"error": err.Error(),
+				})
+			}
+			log.Info(ctx, "slip store settings changed; switched to a new connection", nil)
+		},
+		func(err error) {
+			log.Warn(ctx, "failed to reload configuration", map[string]interface{}{
+				"error": err.Error(),
+			})
+		},
+	)
+	if err := watcher.Prime(cfg); err != nil {
+		log.Warn(ctx, "configuration reload disabled", map[string]interface{}{"error": err.Error()})
+		return finder
+	}
+	go watcher.Run(ctx)
+	return swappable
+}
+
+// loadTenants reads a serve-mode tenants file, deriving each tenant's
+// configuration from the default store's.
+func loadTenants(path string, base *cmd.AppConfig) (map[string]*cmd.AppConfig, error) {
+	if err := requireClickHouse("serving tenants", base); err != nil {
+		return nil, err
+	}
+	chConfig, ok := base.ClickHouseConfig.(*ch.ClickhouseConfig)
+	if !ok {
+		return nil, newConfigTypeError("*ch.ClickhouseConfig")
+	}
+	stores, err := config.LoadTenants(path, chConfig, base.Database)
+	if err != nil {
+		return nil, err
+	}
+	pipeline, _ := base.PipelineConfig.(*slippy.PipelineConfig)
+	configs := make(map[string]*cmd.AppConfig, len(stores))
+	for name, tenantStore := range stores {
+		cfg := *base
+		cfg.ClickHouseConfig, cfg.Database = tenantStore.ClickHouse, tenantStore.Database
+		// Tenants pointing at the same store share a fingerprint, and so a finder.
+		cfg.StoreFingerprint, err = config.Fingerprint(&config.Config{
+			ClickHouse:     tenantStore.ClickHouse,
+			PipelineConfig: pipeline,
+			Database:       tenantStore.Database,
+			FinderPlugin:   base.FinderPlugin,
+			Store:          base.Store,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("tenant %s: %w", name, err)
+		}
+		configs[name] = &cfg
+	}
+	return configs, nil
+}
+
+// annotateArgoCDApp merges annotations into the Argo CD Application app, using
+// the server and token configured in the environment.
+func annotateArgoCDApp(ctx context.Context, app string, annotations map[string]string) error {
+	cfg, err := config.LoadArgoCD()
+	if err != nil {
+		return err
+	}
+	return argocd.NewClient(cfg.BaseURL, cfg.Token).Annotate(ctx, app, annotations)
+}
+
+// tagAzureDevOpsBuild tags the running Azure Pipelines build, using the build
+// and token configured in the environment.
+func tagAzureDevOpsBuild(ctx context.Context, tag string) error {
+	cfg, err := config.LoadAzureDevOps()
+	if err != nil {
+		return err
+	}
+	return azuredevops.NewClient(cfg.CollectionURL, cfg.Project, cfg.Token).AddBuildTag(ctx, cfg.BuildID, tag)
+}
+
+// createGitHubDeployment creates a GitHub Deployment carrying payload, using the
+// API and token configured in the environment.
+func createGitHubDeployment(
+	ctx context.Context,
+	repository, ref, environment string,
+	payload map[string]string,
+) error {
+	cfg, err := config.LoadGitHub()
+	if err != nil {
+		return err
+	}
+	_, err = github.NewClient(cfg.BaseURL, cfg.Token).CreateDeployment(ctx, github.Deployment{
+		Repository:  repository,
+		Ref:         ref,
+		Environment: environment,
+		Payload:     payload,
+	})
+	return err
+}
+
+// newWorker connects to the queue named by opts and builds the worker consuming it.
+func newWorker(opts cmd.WorkerOptions, resolve cmd.ResolveRequestFunc, log cmd.Logger) (cmd.Worker, error) {
+	broker, err := queue.Open(opts.QueueURL, opts.Subject, opts.Group)
+	if err != nil {
+		return nil, err
+	}
+	return queue.NewWorker(broker, queue.ResolveFunc(resolve), log, opts.Concurrency), nil
+}
+
+// tracingFlushTimeout bounds how long exiting waits for buffered spans, log
+// records, and queued error reports to be sent.
+const tracingFlushTimeout = 5 * time.Second
+
+// newZapLogger builds the application logger at level from the LOG_*
+// environment, also writing to extra, falling back to goLibMyCarrier's default
+// logger if that configuration is invalid (e.g. an unknown LOG_FORMAT); the
+// fallback ignores later changes to level. The returned function closes its
+// log file.
+func newZapLogger(level zap.AtomicLevel, extra ...zapcore.Core) (*logger.ZapLogger, logadapter.CloseFunc) {
+	zapLog, closeLog, err := logadapter.NewZapLogger(level, extra...)
+	if err != nil {
+		zapLog, closeLog = logger.NewZapLoggerFromConfig(), func() error { return nil }
+		zapLog.Warn(context.Background(), "invalid logging configuration, using defaults", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	return zapLog, closeLog
+}
+
+// connectStatsD mirrors recorder's metrics to the StatsD agent configured in
+// the environment. Returns nil when StatsD is disabled or cannot be set up;
+// misconfiguration is logged rather than failing the run.
+func connectStatsD(ctx context.Context, recorder *metrics.Metrics, log cmd.Logger) *metrics.StatsD {
+	cfg, err := config.LoadStatsD()
+	if err != nil {
+		log.Warn(ctx, "ignoring StatsD configuration", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil
+	}
+	if !cfg.Enabled() {
+		return nil
+	}
+
+	client, err := metrics.NewStatsD(cfg.Addr, cfg.Prefix, cfg.DogStatsD)
+	if err != nil {
+		log.Warn(ctx, "failed to connect to StatsD", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil
+	}
+	recorder.SendToStatsD(client)
+	return client
+}
+
+// setupErrorReporting builds the reporters configured in the environment.
+// Returns nil when none is configured or none can be set up; misconfiguration
+// is logged rather than failing the run.
+func setupErrorReporting(ctx context.Context, log cmd.Logger) reporting.Reporter {
+	cfg, err := config.LoadErrorReporting()
+	if err != nil {
+		log.Warn(ctx, "ignoring error reporting configuration", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil
+	}
+	if !cfg.Enabled() {
+		return nil
+	}
+
+	// reportFailure tags each report with the backend of the store opened
+	var reporters reporting.Multi
+	if cfg.SentryDSN != "" {
+		sentry, err := reporting.NewSentry(cfg.SentryDSN, cfg.SentryEnvironment, cmd.Version, nil)
+		if err != nil {
+			log.Warn(ctx, "failed to set up Sentry", map[string]interface{}{
+				"error": err.Error(),
+			})
+		} else {
+			reporters = append(reporters, sentry)
+		}
+	}
+	if cfg.WebhookURL != "" {
+		reporters = append(reporters, reporting.NewWebhook(cfg.WebhookURL, cmd.Version, nil))
+	}
+	if len(reporters) == 0 {
+		return nil
+	}
+	return reporters
+}
+
+// setupNotify builds the failure notifiers configured in the environment.
+// Returns nil when none is configured; misconfiguration is logged rather than
+// failing the run.
+func setupNotify(ctx context.Context, log cmd.Logger) domain.FailureNotifier {
+	cfg, err := config.LoadNotify()
+	if err != nil {
+		log.Warn(ctx, "ignoring notification configuration", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil
+	}
+	if !cfg.Enabled() {
+		return nil
+	}
+
+	var notifiers notify.Multi
+	if cfg.SlackWebhookURL != "" {
+		notifiers = append(notifiers, notify.NewSlack(cfg.SlackWebhookURL, cfg.SlackChannel))
+	}
+	if cfg.TeamsWebhookURL != "" {
+		notifiers = append(notifiers, notify.NewTeams(cfg.TeamsWebhookURL))
+	}
+	return notifiers
+}
+
+// setupEvents builds the publisher of resolution events configured in the
+// environment. Returns nil when none is configured; misconfiguration is logged
+// rather than failing the run.
+func setupEvents(ctx context.Context, log cmd.Logger) domain.ResolutionPublisher {
+	cfg, err := config.LoadEvents()
+	if err != nil {
+		log.Warn(ctx, "ignoring event publishing configuration", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil
+	}
+	if !cfg.Enabled() {
+		return nil
+	}
+	return queue.NewEventPublisher(cfg.URL, cfg.Topic)
+}
+
+// requireClickHouse rejects a feature that keeps its data in ClickHouse when
+// a finder plugin or the mock store replaces it.
+func requireClickHouse(feature string, cfg *cmd.AppConfig) error {
+	switch {
+	case cfg.FinderPlugin != "":
+		return fmt.Errorf("%s requires ClickHouse, which is not used with %s", feature, config.EnvFinderPlugin)
+	case cfg.Store == config.StoreMock:
+		return fmt.Errorf("%s requires ClickHouse, which is not used with %s=%s", feature, config.EnvStore, config.StoreMock)
+	}
+	return nil
 }
 
 func newConfigTypeError(expected string) error {
@@ -104,3 +771,18 @@ type configTypeError struct {
 func (e *configTypeError) Error() string {
 	return "invalid configuration type: expected " + e.expected
 }
+
+// toConfigSettings converts config provenance into the command's representation.
+func toConfigSettings(settings []config.Setting) []cmd.ConfigSetting {
+	out := make([]cmd.ConfigSetting, 0, len(settings))
+	for _, s := range settings {
+		out = append(out, cmd.ConfigSetting{
+			Key:    s.Key,
+			Value:  s.Value,
+			Source: s.Source,
+			Detail: s.Detail,
+			Secret: s.Secret,
+		})
+	}
+	return out
+}
@@ -1,9 +1,25 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	ch "github.com/MyCarrier-DevOps/goLibMyCarrier/clickhouse"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/cmd"
+	"github.com/MyCarrier-DevOps/slippy-find/internal/adapters/metrics"
+	"github.com/MyCarrier-DevOps/slippy-find/internal/adapters/server"
+	"github.com/MyCarrier-DevOps/slippy-find/internal/adapters/store"
+	"github.com/MyCarrier-DevOps/slippy-find/internal/infrastructure/config"
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
 )
 
 func TestNewConfigTypeError(t *testing.T) {
@@ -49,3 +65,368 @@ func TestConfigTypeError_ImplementsError(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Contains(t, err.Error(), "test")
 }
+
+func TestToConfigSettings(t *testing.T) {
+	settings := toConfigSettings([]config.Setting{
+		{Key: "clickhouse.password", Value: "secret", Source: config.SourceEnv, Detail: "CLICKHOUSE_PASSWORD", Secret: true},
+	})
+
+	assert.Equal(t, []cmd.ConfigSetting{
+		{Key: "clickhouse.password", Value: "secret", Source: "env", Detail: "CLICKHOUSE_PASSWORD", Secret: true},
+	}, settings)
+}
+
+func TestLoadTenants(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tenants.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("tenants:\n  payments:\n    database: payments_ci\n"), 0o600))
+	base := &cmd.AppConfig{
+		ClickHouseConfig: &ch.ClickhouseConfig{ChHostname: "ch.internal", ChDatabase: "ci"},
+		Database:         "ci",
+		DenyList:         []string{"slip-bad"},
+		StoreFingerprint: "base",
+	}
+
+	configs, err := loadTenants(path, base)
+
+	require.NoError(t, err)
+	require.Contains(t, configs, "payments")
+	payments := configs["payments"]
+	assert.Equal(t, "payments_ci", payments.Database)
+	assert.Equal(t, &ch.ClickhouseConfig{ChHostname: "ch.internal", ChDatabase: "payments_ci"}, payments.ClickHouseConfig)
+	assert.Equal(t, []string{"slip-bad"}, payments.DenyList)
+	assert.NotEmpty(t, payments.StoreFingerprint)
+	assert.NotEqual(t, base.StoreFingerprint, payments.StoreFingerprint, "each tenant store has its own fingerprint")
+	assert.Equal(t, "ci", base.Database, "the default configuration is not modified")
+}
+
+func TestLoadTenants_WrongConfigType(t *testing.T) {
+	_, err := loadTenants("tenants.yaml", &cmd.AppConfig{})
+
+	assert.ErrorContains(t, err, "*ch.ClickhouseConfig")
+}
+
+func TestLoadTenants_FinderPlugin(t *testing.T) {
+	_, err := loadTenants("tenants.yaml", &cmd.AppConfig{FinderPlugin: "/usr/local/bin/slippy-finder"})
+
+	assert.ErrorContains(t, err, "serving tenants requires ClickHouse")
+}
+
+func TestLoadTenants_MockStore(t *testing.T) {
+	_, err := loadTenants("tenants.yaml", &cmd.AppConfig{Store: config.StoreMock})
+
+	assert.ErrorContains(t, err, "serving tenants requires ClickHouse, which is not used with SLIPPY_STORE=mock")
+}
+
+// closingFinder is a SlipFinder recording whether it was closed.
+type closingFinder struct {
+	store.MockFinder
+	closed chan struct{}
+}
+
+func (f *closingFinder) Close() error {
+	close(f.closed)
+	return nil
+}
+
+func TestReloadStore(t *testing.T) {
+	t.Setenv("CLICKHOUSE_HOSTNAME", "")
+	t.Setenv(config.EnvPipelineConfig, "")
+	t.Setenv(config.EnvVaultPipelineConfigPath, "")
+	t.Setenv(config.EnvFinderPlugin, "")
+	t.Setenv(config.EnvStore, config.StoreMock)
+	t.Setenv(config.EnvConfigReloadInterval, "10ms")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	current := &closingFinder{closed: make(chan struct{})}
+	var opened []*cmd.AppConfig
+
+	finder := reloadStore(ctx, &cmd.AppConfig{Store: config.StoreClickHouse, StoreFingerprint: "clickhouse"}, current,
+		&warnLogger{}, func(cfg *cmd.AppConfig, _ cmd.Logger) (domain.SlipFinder, error) {
+			opened = append(opened, cfg)
+			return store.NewMockFinder(), nil
+		})
+
+	select {
+	case <-current.closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the previous finder was not closed")
+	}
+	cancel()
+	require.Len(t, opened, 1)
+	assert.Equal(t, config.StoreMock, opened[0].Store)
+	_, _, err := finder.FindByCommits(context.Background(), "org/repo", []string{"c1"})
+	assert.NoError(t, err)
+}
+
+func TestNewServerAdmin(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "admin-tokens")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("oncall admin-s3cret\n"), 0o600))
+
+	admin, err := newServerAdmin(tokenFile, &cmd.AdminHooks{
+		CacheStats: func() map[string]cmd.CacheStats {
+			return map[string]cmd.CacheStats{cmd.DefaultStoreName: {Enabled: true, Entries: 3}}
+		},
+		FlushCache: func() int { return 3 },
+		Settings: func() []cmd.ConfigSetting {
+			return []cmd.ConfigSetting{{Key: "clickhouse.password", Value: "********", Source: "env", Secret: true}}
+		},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"admin-s3cret": "oncall"}, admin.Tokens)
+	assert.Equal(t, map[string]server.CacheStats{"default": {Enabled: true, Entries: 3}}, admin.CacheStats())
+	assert.Equal(t, 3, admin.FlushCache())
+	assert.Equal(t, []server.Setting{{Key: "clickhouse.password", Value: "********", Source: "env"}}, admin.Settings())
+}
+
+func TestNewServerAdmin_MissingTokens(t *testing.T) {
+	_, err := newServerAdmin(filepath.Join(t.TempDir(), "missing"), &cmd.AdminHooks{})
+
+	assert.Error(t, err)
+}
+
+// warnLogger is a cmd.Logger that records warning messages.
+type warnLogger struct {
+	warnings []string
+}
+
+func (l *warnLogger) Info(_ context.Context, _ string, _ map[string]interface{})  {}
+func (l *warnLogger) Debug(_ context.Context, _ string, _ map[string]interface{}) {}
+func (l *warnLogger) Warn(_ context.Context, msg string, _ map[string]interface{}) {
+	l.warnings = append(l.warnings, msg)
+}
+func (l *warnLogger) Error(_ context.Context, _ string, _ error, _ map[string]interface{}) {}
+
+func TestConfigLoader_EnvFileUnavailable(t *testing.T) {
+	envErr := fmt.Errorf("%w: open local.env: no such file or directory", config.ErrDotEnvUnavailable)
+
+	cfg, err := configLoader(envErr)()
+
+	assert.Nil(t, cfg)
+	assert.ErrorIs(t, err, config.ErrDotEnvUnavailable)
+}
+
+func TestWarnMockStore(t *testing.T) {
+	log := &warnLogger{}
+
+	warnMockStore(log)
+
+	assert.Equal(t, []string{"using the mock slip store: correlation IDs are fake"}, log.warnings)
+}
+
+func TestConnectStatsD(t *testing.T) {
+	tests := []struct {
+		name        string
+		host        string
+		port        string
+		wantClient  bool
+		wantWarning string
+	}{
+		{name: "disabled"},
+		{name: "enabled", host: "127.0.0.1", port: "8125", wantClient: true},
+		{name: "invalid port", host: "127.0.0.1", port: "none", wantWarning: "ignoring StatsD configuration"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(config.EnvStatsDHost, tt.host)
+			t.Setenv(config.EnvStatsDPort, tt.port)
+			log := &warnLogger{}
+
+			client := connectStatsD(context.Background(), metrics.New(), log)
+
+			if tt.wantClient {
+				assert.NotNil(t, client)
+				assert.NoError(t, client.Close())
+			} else {
+				assert.Nil(t, client)
+			}
+			if tt.wantWarning != "" {
+				assert.Equal(t, []string{tt.wantWarning}, log.warnings)
+			} else {
+				assert.Empty(t, log.warnings)
+			}
+		})
+	}
+}
+
+func TestSetupErrorReporting(t *testing.T) {
+	tests := []struct {
+		name         string
+		dsn          string
+		webhook      string
+		wantReporter bool
+		wantWarning  string
+	}{
+		{name: "disabled"},
+		{name: "sentry", dsn: "https://key@sentry.example.com/1", wantReporter: true},
+		{name: "webhook", webhook: "https://hooks.example.com/slippy", wantReporter: true},
+		{name: "invalid webhook", webhook: "hooks", wantWarning: "ignoring error reporting configuration"},
+		{name: "invalid dsn", dsn: "not a dsn", wantWarning: "failed to set up Sentry"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(config.EnvSentryDSN, tt.dsn)
+			t.Setenv(config.EnvErrorWebhookURL, tt.webhook)
+			log := &warnLogger{}
+
+			reporter := setupErrorReporting(context.Background(), log)
+
+			assert.Equal(t, tt.wantReporter, reporter != nil)
+			if tt.wantWarning != "" {
+				assert.Equal(t, []string{tt.wantWarning}, log.warnings)
+			} else {
+				assert.Empty(t, log.warnings)
+			}
+		})
+	}
+}
+
+func TestSetupNotify(t *testing.T) {
+	tests := []struct {
+		name         string
+		webhook      string
+		channel      string
+		teams        string
+		wantNotifier bool
+		wantWarning  string
+	}{
+		{name: "disabled"},
+		{name: "slack", webhook: "https://hooks.slack.com/services/T0/B0/x", wantNotifier: true},
+		{name: "teams", teams: "https://example.webhook.office.com/webhookb2/x", wantNotifier: true},
+		{name: "invalid webhook", webhook: "hooks", wantWarning: "ignoring notification configuration"},
+		{name: "channel without webhook", channel: "#alerts", wantWarning: "ignoring notification configuration"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(config.EnvSlackWebhookURL, tt.webhook)
+			t.Setenv(config.EnvSlackChannel, tt.channel)
+			t.Setenv(config.EnvTeamsWebhookURL, tt.teams)
+			log := &warnLogger{}
+
+			notifier := setupNotify(context.Background(), log)
+
+			assert.Equal(t, tt.wantNotifier, notifier != nil)
+			if tt.wantWarning != "" {
+				assert.Equal(t, []string{tt.wantWarning}, log.warnings)
+			} else {
+				assert.Empty(t, log.warnings)
+			}
+		})
+	}
+}
+
+func TestSetupEvents(t *testing.T) {
+	tests := []struct {
+		name          string
+		url           string
+		topic         string
+		wantPublisher bool
+		wantWarning   string
+	}{
+		{name: "disabled"},
+		{name: "nats", url: "nats://nats:4222", wantPublisher: true},
+		{name: "kafka", url: "kafka://kafka-0:9092", topic: "ci.slips", wantPublisher: true},
+		{name: "invalid url", url: "amqp://rabbit:5672", wantWarning: "ignoring event publishing configuration"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(config.EnvEventsURL, tt.url)
+			t.Setenv(config.EnvEventsTopic, tt.topic)
+			log := &warnLogger{}
+
+			publisher := setupEvents(context.Background(), log)
+
+			assert.Equal(t, tt.wantPublisher, publisher != nil)
+			if tt.wantWarning != "" {
+				assert.Equal(t, []string{tt.wantWarning}, log.warnings)
+			} else {
+				assert.Empty(t, log.warnings)
+			}
+		})
+	}
+}
+
+func TestAnnotateArgoCDApp(t *testing.T) {
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	t.Setenv(config.EnvArgoCDServer, srv.URL)
+	t.Setenv(config.EnvArgoCDAuthToken, "token")
+
+	err := annotateArgoCDApp(context.Background(), "payments-api", map[string]string{"k": "v"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "/api/v1/applications/payments-api", path)
+}
+
+func TestAnnotateArgoCDApp_NotConfigured(t *testing.T) {
+	t.Setenv(config.EnvArgoCDServer, "")
+	t.Setenv(config.EnvArgoCDAuthToken, "")
+
+	err := annotateArgoCDApp(context.Background(), "payments-api", nil)
+
+	assert.ErrorIs(t, err, config.ErrArgoCDInvalid)
+}
+
+func TestTagAzureDevOpsBuild(t *testing.T) {
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	t.Setenv(config.EnvAzureDevOpsCollectionURI, srv.URL+"/myorg/")
+	t.Setenv(config.EnvAzureDevOpsProject, "proj-1")
+	t.Setenv(config.EnvAzureDevOpsBuildID, "42")
+	t.Setenv(config.EnvAzureDevOpsPAT, "token")
+
+	err := tagAzureDevOpsBuild(context.Background(), "slip-1")
+
+	require.NoError(t, err)
+	assert.Equal(t, "/myorg/proj-1/_apis/build/builds/42/tags/slip-1", path)
+}
+
+func TestTagAzureDevOpsBuild_NotConfigured(t *testing.T) {
+	t.Setenv(config.EnvAzureDevOpsCollectionURI, "")
+
+	err := tagAzureDevOpsBuild(context.Background(), "slip-1")
+
+	assert.ErrorIs(t, err, config.ErrAzureDevOpsInvalid)
+}
+
+func TestCreateGitHubDeployment(t *testing.T) {
+	var posted bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(`[]`))
+			return
+		}
+		posted = r.URL.Path == "/repos/org/repo/deployments"
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":1}`))
+	}))
+	defer srv.Close()
+	t.Setenv(config.EnvGitHubToken, "token")
+	t.Setenv(config.EnvGitHubAPIURL, srv.URL)
+
+	err := createGitHubDeployment(context.Background(), "org/repo", "c1", "production",
+		map[string]string{"correlation_id": "slip-1"})
+
+	require.NoError(t, err)
+	assert.True(t, posted)
+}
+
+func TestCreateGitHubDeployment_NotConfigured(t *testing.T) {
+	t.Setenv(config.EnvGitHubToken, "")
+
+	err := createGitHubDeployment(context.Background(), "org/repo", "c1", "production", nil)
+
+	assert.ErrorIs(t, err, config.ErrGitHubInvalid)
+}
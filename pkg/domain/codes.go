@@ -0,0 +1,158 @@
+package domain
+
+import "errors"
+
+// ErrorCode is a stable identifier for a class of failure. Codes never change
+// meaning once released, so dashboards can aggregate failures across runs and
+// versions; new failure classes get new codes.
+type ErrorCode string
+
+// Error codes reported by slippy-find.
+const (
+	// CodeUnknown covers failures not yet classified.
+	CodeUnknown ErrorCode = "SLIPPY_E000"
+
+	// CodeNotARepository: the path is not a git repository.
+	CodeNotARepository ErrorCode = "SLIPPY_E001"
+
+	// CodeNoRemoteOrigin: the repository has no usable 'origin' remote.
+	CodeNoRemoteOrigin ErrorCode = "SLIPPY_E002"
+
+	// CodeNoSlip: no slip matched the searched history.
+	CodeNoSlip ErrorCode = "SLIPPY_E003"
+
+	// CodeInvalidRemoteURL: the repository name could not be parsed from 'origin'.
+	CodeInvalidRemoteURL ErrorCode = "SLIPPY_E004"
+
+	// CodeEmptyAncestry: the history walk found no commits (e.g. none touch a component).
+	CodeEmptyAncestry ErrorCode = "SLIPPY_E005"
+
+	// CodeBranchNotFound: a branch is missing locally and on origin.
+	CodeBranchNotFound ErrorCode = "SLIPPY_E006"
+
+	// CodeCommitNotFound: a commit is missing from the local repository (e.g. a shallow clone).
+	CodeCommitNotFound ErrorCode = "SLIPPY_E007"
+
+	// CodeNoMergeBase: HEAD shares no history with the default branch.
+	CodeNoMergeBase ErrorCode = "SLIPPY_E008"
+
+	// CodeStepRequirementUnmet: a slip was resolved but failed a --require-step check.
+	CodeStepRequirementUnmet ErrorCode = "SLIPPY_E009"
+
+	// CodeInvalidHint: a hinted correlation ID failed validation.
+	CodeInvalidHint ErrorCode = "SLIPPY_E010"
+
+	// CodeInvalidInput: a flag or argument is malformed.
+	CodeInvalidInput ErrorCode = "SLIPPY_E011"
+
+	// CodeConfiguration: configuration could not be loaded or is incomplete.
+	CodeConfiguration ErrorCode = "SLIPPY_E012"
+
+	// CodeGit: reading the repository failed for another reason.
+	CodeGit ErrorCode = "SLIPPY_E013"
+
+	// CodeStore: connecting to or querying the slip store failed.
+	CodeStore ErrorCode = "SLIPPY_E014"
+
+	// CodeOutput: the result could not be written.
+	CodeOutput ErrorCode = "SLIPPY_E015"
+
+	// CodeAmbiguousCommit: an abbreviated commit SHA matches several commits.
+	CodeAmbiguousCommit ErrorCode = "SLIPPY_E016"
+
+	// CodeStageTimeout: the git walk or store queries ran out of their timeout.
+	CodeStageTimeout ErrorCode = "SLIPPY_E017"
+
+	// CodeMalformedCorrelationID: the store returned a correlation ID of the wrong format.
+	CodeMalformedCorrelationID ErrorCode = "SLIPPY_E018"
+)
+
+// Unexpected reports whether c indicates a systemic failure (configuration,
+// store, git, or output trouble, a stage timeout, a malformed correlation ID,
+// or an unclassified error) rather than a condition of the repository or the
+// caller's input.
+func (c ErrorCode) Unexpected() bool {
+	switch c {
+	case CodeUnknown, CodeConfiguration, CodeGit, CodeStore, CodeOutput, CodeStageTimeout,
+		CodeMalformedCorrelationID:
+		return true
+	default:
+		return false
+	}
+}
+
+// sentinelCodes classifies the domain errors. They take precedence over codes
+// attached with WithCode, since a sentinel is the more specific diagnosis.
+var sentinelCodes = []struct {
+	err  error
+	code ErrorCode
+}{
+	{ErrRepositoryNotFound, CodeNotARepository},
+	{ErrNoRemoteOrigin, CodeNoRemoteOrigin},
+	{ErrNoAncestorSlip, CodeNoSlip},
+	{ErrSlipNotFound, CodeNoSlip},
+	{ErrInvalidRemoteURL, CodeInvalidRemoteURL},
+	{ErrInvalidRepository, CodeInvalidInput},
+	{ErrEmptyAncestry, CodeEmptyAncestry},
+	{ErrBranchNotFound, CodeBranchNotFound},
+	{ErrCommitNotFound, CodeCommitNotFound},
+	{ErrAmbiguousCommit, CodeAmbiguousCommit},
+	{ErrStageTimeout, CodeStageTimeout},
+	{ErrObjectFormatMismatch, CodeInvalidInput},
+	{ErrInvalidCommitList, CodeInvalidInput},
+	{ErrUnsupportedObjectFormat, CodeGit},
+	{ErrNoMergeBase, CodeNoMergeBase},
+	{ErrStepRequirementUnmet, CodeStepRequirementUnmet},
+	{ErrInvalidHint, CodeInvalidHint},
+	{ErrMalformedCorrelationID, CodeMalformedCorrelationID},
+	{ErrInvalidSelectionPolicy, CodeInvalidInput},
+	{ErrInvalidStepRequirement, CodeInvalidInput},
+	{ErrHistoryUnavailable, CodeInvalidInput},
+	{ErrAncestryTooLarge, CodeInvalidInput},
+}
+
+// CodedError attaches an ErrorCode to an error that wraps no domain sentinel.
+type CodedError struct {
+	Code ErrorCode
+	Err  error
+}
+
+// WithCode attaches code to err. Returns nil if err is nil.
+func WithCode(code ErrorCode, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &CodedError{Code: code, Err: err}
+}
+
+// Error returns the wrapped error's message, unchanged.
+func (e *CodedError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *CodedError) Unwrap() error {
+	return e.Err
+}
+
+// CodeOf classifies err: by the domain sentinel it wraps, else by the
+// innermost code attached with WithCode (the one nearest the failure), else
+// CodeUnknown. Returns "" for nil.
+func CodeOf(err error) ErrorCode {
+	if err == nil {
+		return ""
+	}
+	for _, s := range sentinelCodes {
+		if errors.Is(err, s.err) {
+			return s.code
+		}
+	}
+	code := CodeUnknown
+	for {
+		var coded *CodedError
+		if !errors.As(err, &coded) {
+			return code
+		}
+		code, err = coded.Code, coded.Err
+	}
+}
@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxCommitList is the most commits a caller may send in one request.
+const MaxCommitList = 10000
+
+// CommitListError describes why a caller-supplied commit list was rejected,
+// so API callers can point at the offending entry. It wraps
+// ErrInvalidCommitList.
+type CommitListError struct {
+	// Index is the position of the rejected commit in the list, or -1 if the
+	// list as a whole was rejected.
+	Index int
+
+	// Commit is the rejected value; empty if Index is -1.
+	Commit string
+
+	// Reason says what is wrong with the commit or the list.
+	Reason string
+}
+
+// Error describes the rejected commit or list.
+func (e *CommitListError) Error() string {
+	if e.Index < 0 {
+		return fmt.Sprintf("%s: %s", ErrInvalidCommitList, e.Reason)
+	}
+	return fmt.Sprintf("%s: commits[%d] %q %s", ErrInvalidCommitList, e.Index, e.Commit, e.Reason)
+}
+
+// Unwrap returns ErrInvalidCommitList.
+func (e *CommitListError) Unwrap() error {
+	return ErrInvalidCommitList
+}
+
+// ParseCommitList validates and normalizes a caller-supplied commit list
+// before it is searched. Commits are trimmed and lowercased, and repeats are
+// dropped, keeping each commit's first position so HEAD stays first.
+//
+// Returns a *CommitListError if the list is empty or longer than
+// MaxCommitList, or a commit is not a full 40- or 64-digit hexadecimal SHA
+// (stores match commits exactly, so an abbreviation never finds a slip), and
+// ErrObjectFormatMismatch if it mixes SHA-1 and SHA-256 commit SHAs.
+func ParseCommitList(commits []string) ([]string, error) {
+	switch {
+	case len(commits) == 0:
+		return nil, &CommitListError{Index: -1, Reason: "is empty"}
+	case len(commits) > MaxCommitList:
+		return nil, &CommitListError{
+			Index:  -1,
+			Reason: fmt.Sprintf("has %d commits, more than the limit of %d", len(commits), MaxCommitList),
+		}
+	}
+
+	parsed := make([]string, 0, len(commits))
+	seen := make(map[string]struct{}, len(commits))
+	for i, commit := range commits {
+		sha := strings.ToLower(strings.TrimSpace(commit))
+		if reason := checkCommit(sha); reason != "" {
+			return nil, &CommitListError{Index: i, Commit: commit, Reason: reason}
+		}
+		if _, ok := seen[sha]; ok {
+			continue
+		}
+		seen[sha] = struct{}{}
+		parsed = append(parsed, sha)
+	}
+	if err := CheckObjectFormat("", parsed...); err != nil {
+		return nil, err
+	}
+	return parsed, nil
+}
+
+// checkCommit returns why the lowercase sha is not a full commit SHA, or "".
+func checkCommit(sha string) string {
+	for _, c := range sha {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return fmt.Sprintf("contains %q, expected hexadecimal digits", c)
+		}
+	}
+	if ObjectFormatOf(sha) == "" {
+		return fmt.Sprintf("is %d characters long, expected a full SHA of %d or %d",
+			len(sha), ObjectFormatSHA1.HexLength(), ObjectFormatSHA256.HexLength())
+	}
+	return ""
+}
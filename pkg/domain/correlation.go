@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Correlation ID formats understood by ParseCorrelationIDFormat besides a
+// regular expression.
+const (
+	// CorrelationIDFormatUUID accepts hyphenated UUIDs, the IDs slippy
+	// creates slips with. It is the default.
+	CorrelationIDFormatUUID = "uuid"
+
+	// CorrelationIDFormatAny accepts any non-empty correlation ID.
+	CorrelationIDFormatAny = "any"
+)
+
+// uuidPattern matches a hyphenated UUID of any version, in either case.
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// maxReportedCorrelationID is the most bytes of a malformed correlation ID
+// quoted in an error, since a store returning garbage may return a lot of it.
+const maxReportedCorrelationID = 80
+
+// ParseCorrelationIDFormat returns the pattern resolved correlation IDs must
+// match for format: CorrelationIDFormatUUID, CorrelationIDFormatAny (nil), or
+// a regular expression, which must match the whole ID. An empty format means
+// CorrelationIDFormatUUID.
+func ParseCorrelationIDFormat(format string) (*regexp.Regexp, error) {
+	switch strings.TrimSpace(format) {
+	case "", CorrelationIDFormatUUID:
+		return uuidPattern, nil
+	case CorrelationIDFormatAny:
+		return nil, nil
+	}
+	pattern, err := regexp.Compile(`^(?:` + format + `)$`)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not %s, %s, or a regular expression: %w",
+			format, CorrelationIDFormatUUID, CorrelationIDFormatAny, err)
+	}
+	return pattern, nil
+}
+
+// CheckCorrelationID returns ErrMalformedCorrelationID if id is empty, or
+// pattern is not nil and does not match id.
+func CheckCorrelationID(pattern *regexp.Regexp, id string) error {
+	switch {
+	case id == "":
+		return fmt.Errorf("%w: the correlation ID is empty", ErrMalformedCorrelationID)
+	case pattern == nil || pattern.MatchString(id):
+		return nil
+	case pattern == uuidPattern:
+		return fmt.Errorf("%w: %.*q is not a UUID", ErrMalformedCorrelationID, maxReportedCorrelationID, id)
+	default:
+		return fmt.Errorf("%w: %.*q does not match %s",
+			ErrMalformedCorrelationID, maxReportedCorrelationID, id, pattern)
+	}
+}
@@ -0,0 +1,603 @@
+// Package domain defines the core business entities and interfaces for slippy-find.
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// GitContext contains all derived git information needed for slip resolution.
+// This struct is populated by LocalGitRepository.GetGitContext() from the local repository.
+type GitContext struct {
+	// HeadSHA is the full commit SHA of HEAD: 40 characters, or 64 in a
+	// repository of the sha256 object format.
+	HeadSHA string
+
+	// ObjectFormat is the repository's object format, ObjectFormatSHA1 unless
+	// it was initialized with --object-format=sha256.
+	ObjectFormat ObjectFormat
+
+	// Branch is the current branch name (empty string if HEAD is detached).
+	Branch string
+
+	// Repository is the repository name in owner/repo format.
+	// Derived from the 'origin' remote URL.
+	Repository string
+
+	// IsDetached indicates if HEAD is detached (not on a branch).
+	// When true, Branch will be empty.
+	IsDetached bool
+
+	// DefaultBranch is the 'origin' remote's default branch, as origin/HEAD
+	// names it, else main or master if one exists. Empty if none is found.
+	DefaultBranch string
+}
+
+// ResolveInput contains the parameters for slip resolution.
+// The repository path is provided separately when creating the LocalGitRepository.
+type ResolveInput struct {
+	// Depth is the maximum number of commits to walk in the ancestry.
+	// A higher value increases the chance of finding a matching slip
+	// but also increases database query size.
+	Depth int
+
+	// MaxDepth enables progressive deepening. When no slip is found within Depth,
+	// the walk is retried at DepthGrowthFactor times the previous depth until a
+	// slip is found, history runs out, or MaxDepth is reached.
+	// Zero (or any value not above Depth) disables deepening.
+	MaxDepth int
+
+	// SelectionPolicy decides which slip wins when several commits in the
+	// ancestry window match different slips. Empty means DefaultSelectionPolicy.
+	SelectionPolicy SelectionPolicy
+
+	// MaxAge rejects slips created longer ago than this duration, so stale slips
+	// from abandoned ancestor commits are never returned. Zero means no limit.
+	MaxAge time.Duration
+
+	// Since rejects slips created before this time. Zero means no limit.
+	// When both MaxAge and Since are set, the later cutoff applies.
+	Since time.Time
+
+	// FallbackMergeBase enables searching the ancestry of the merge base between
+	// HEAD and DefaultBranch when the HEAD ancestry has no slip.
+	FallbackMergeBase bool
+
+	// FallbackBranchLatest enables returning the newest slip recorded for the
+	// current branch when every earlier strategy misses.
+	FallbackBranchLatest bool
+
+	// DefaultBranch is the branch used by the merge-base fallback. Empty
+	// means GitContext.DefaultBranch, or DefaultBranchName if that is unknown.
+	DefaultBranch string
+
+	// Component restricts resolution to one monorepo component. The HEAD
+	// ancestry only includes commits touching ComponentPaths, and slips whose
+	// aggregate steps include the component are preferred. Empty disables it.
+	Component string
+
+	// ComponentPaths are the repository-relative path prefixes owned by Component.
+	ComponentPaths []string
+
+	// RequiredSteps are step states the resolved slip must satisfy. If any is
+	// unmet, resolution fails with ErrStepRequirementUnmet.
+	RequiredSteps []StepRequirement
+
+	// BranchAffinity prefers slips recorded for the current branch among the
+	// slips matching a search, falling back to the others only if none are.
+	// This keeps a commit shared by several branches (e.g. a release branch cut
+	// or cherry-picked from main) resolving to its own branch's slip. It has no
+	// effect on a detached HEAD.
+	BranchAffinity bool
+
+	// DenyList holds correlation IDs that must never be returned (e.g. slips
+	// rolled back after a security incident). Matching slips are skipped and
+	// the search continues as if their commits had no slip.
+	DenyList []string
+
+	// PullRequest enables PR-aware resolution. When set, the PR head's ancestry
+	// is searched instead of HEAD's (a PR build usually checks out a synthetic
+	// merge commit no slip was created for), then the base branch ancestry.
+	// ComponentPaths do not filter these walks. Nil disables it.
+	PullRequest *PullRequest
+
+	// Hint is an explicit correlation ID that short-circuits resolution, for
+	// emergency or manual overrides. The slip must exist in the store, belong to
+	// the repository, and not be deny-listed, or resolution fails with
+	// ErrInvalidHint. Age limits do not apply. Empty disables it.
+	Hint string
+
+	// CorrelationIDFormat is the pattern every returned correlation ID must
+	// match (see ParseCorrelationIDFormat). A slip whose ID is empty or does
+	// not match fails resolution with ErrMalformedCorrelationID instead of
+	// being returned. Nil accepts any non-empty ID.
+	CorrelationIDFormat *regexp.Regexp
+
+	// Annotation, when set, is recorded in the resolved slip's state history as
+	// an audit trail of which builds consumed the slip. The store must
+	// implement SlipAnnotator. Nil disables it.
+	Annotation *Annotation
+
+	// SlowThresholds sets how long a single git walk or store query may take
+	// before it is logged as a warning.
+	SlowThresholds SlowThresholds
+
+	// StageTimeouts bounds the time spent reading git and querying the store,
+	// each independently of the other.
+	StageTimeouts StageTimeouts
+}
+
+// SlowThresholds are the durations above which a single operation is logged
+// as slow, to notice degradation before pipelines time out. Zero disables the
+// warning for that operation.
+type SlowThresholds struct {
+	// GitWalk applies to each commit history walk.
+	GitWalk time.Duration
+
+	// StoreQuery applies to each slip store lookup, including each chunk of a
+	// chunked ancestry query.
+	StoreQuery time.Duration
+}
+
+// StageTimeouts are the most time a resolution may spend in each stage, in
+// total across its calls, so a slow store cannot use up the time the git walk
+// needs or the other way around. A stage that runs out fails the resolution
+// with ErrStageTimeout naming it. Zero means no limit for that stage.
+type StageTimeouts struct {
+	// Git applies to reading the git context and walking history.
+	Git time.Duration
+
+	// Store applies to slip store lookups. Concurrent chunk queries count
+	// their wall-clock time once.
+	Store time.Duration
+}
+
+// ResolveRequest is a resolution request received by serve mode. It names
+// either a repository checkout on the server's host (Path) or, for callers
+// without one, the repository and its HEAD ancestry (Repository and Commits).
+type ResolveRequest struct {
+	// Path is the repository checkout to resolve for.
+	Path string
+
+	// Repository is the repository name in owner/repo format. Used with Commits.
+	Repository string
+
+	// Branch is the branch Commits were taken from, if known. Used with Commits.
+	Branch string
+
+	// Commits is the HEAD ancestry as full commit SHAs, newest (HEAD) first.
+	// Used with Repository.
+	Commits []string
+
+	// Depth is the maximum number of commits to search. Zero means DefaultAncestryDepth.
+	Depth int
+
+	// SelectionPolicy decides among several matching slips. Empty means DefaultSelectionPolicy.
+	SelectionPolicy SelectionPolicy
+
+	// Tenant names the slip store to search, on a server hosting several.
+	// Empty means the server's default store.
+	Tenant string
+
+	// Verified marks Commits as coming from a source the server trusts, such
+	// as a signed push webhook, so its result may answer every request for
+	// the same head. It is never set from a client's request.
+	Verified bool
+}
+
+// PullRequest identifies the commits of a pull request build.
+type PullRequest struct {
+	// HeadSHA is the tip of the pull request branch. Required.
+	HeadSHA string
+
+	// BaseRef is the branch the pull request targets (e.g., "main").
+	BaseRef string
+
+	// BaseSHA is the base branch commit the build merged with. When set it is
+	// preferred over BaseRef. If both are empty, HEAD's ancestry stands in for
+	// the base, since a merge commit's first parent is the base branch.
+	BaseSHA string
+}
+
+// Annotation identifies the build that resolved a slip, for its audit trail.
+type Annotation struct {
+	// Host is the machine that ran the resolution.
+	Host string
+
+	// Pipeline identifies the CI pipeline run that consumed the slip.
+	Pipeline string
+}
+
+// HistoryEntry is an entry appended to a slip's state history.
+type HistoryEntry struct {
+	// Step is the step the entry is recorded against.
+	Step string
+
+	// Actor is the system that recorded the entry.
+	Actor string
+
+	// Message describes the event.
+	Message string
+
+	// Timestamp is when the event occurred.
+	Timestamp time.Time
+}
+
+// RecordKind selects how a resolved slip is recorded in the repository.
+type RecordKind string
+
+// Supported record kinds.
+const (
+	// RecordNote adds a git note under refs/notes/slippy to the matched commit.
+	RecordNote RecordKind = "note"
+
+	// RecordTag points a lightweight tag, slippy/<correlation_id>, at the matched commit.
+	RecordTag RecordKind = "tag"
+)
+
+// SlipRecord is a resolved slip to record against the commit it matched.
+type SlipRecord struct {
+	// Commit is the full SHA of the matched commit.
+	Commit string
+
+	// CorrelationID is the resolved slip.
+	CorrelationID string
+
+	// Kind selects a note or a tag.
+	Kind RecordKind
+
+	// Push sends the note or tag to the origin remote once recorded.
+	Push bool
+}
+
+// ResolutionFailure describes a failed resolution that may point to a
+// systemic problem, such as a pipeline that stopped creating slips or an
+// unreachable store, for alerting the team that runs them.
+type ResolutionFailure struct {
+	// Repository is the repository resolved for (owner/repo), if known.
+	Repository string
+
+	// Branch is the branch at resolution time (may be empty if detached).
+	Branch string
+
+	// HeadSHA is the commit HEAD pointed to, if known.
+	HeadSHA string
+
+	// Component is the monorepo component resolved for, if any.
+	Component string
+
+	// Code classifies the failure.
+	Code ErrorCode
+
+	// Error is the failure's message.
+	Error string
+}
+
+// AuditEvent records one successful resolution, so the builds that consumed a
+// slip can be listed after the fact.
+type AuditEvent struct {
+	// Timestamp is when the resolution completed.
+	Timestamp time.Time
+
+	// Repository is the repository resolved for (owner/repo).
+	Repository string
+
+	// Branch is the branch at resolution time (may be empty if detached).
+	Branch string
+
+	// HeadSHA is the commit HEAD pointed to.
+	HeadSHA string
+
+	// MatchedCommit is the commit that matched the slip.
+	MatchedCommit string
+
+	// CorrelationID is the resolved slip.
+	CorrelationID string
+
+	// ResolvedBy identifies the strategy that produced the result.
+	ResolvedBy string
+
+	// Component is the monorepo component resolved for, if any.
+	Component string
+
+	// Caller identifies the build that ran the resolution.
+	Caller Annotation
+
+	// Duration is how long the resolution took.
+	Duration time.Duration
+}
+
+// ResolveOutput contains the result of a successful slip resolution.
+type ResolveOutput struct {
+	// CorrelationID is the unique identifier of the resolved slip.
+	// This is the primary output value written to stdout.
+	CorrelationID string
+
+	// MatchedCommit is the commit SHA that matched a slip in the database.
+	// This may differ from the HEAD SHA if the slip was found in ancestry.
+	MatchedCommit string
+
+	// Repository is the repository name in owner/repo format.
+	// Included for logging and verification purposes.
+	Repository string
+
+	// Branch is the branch name at resolution time (may be empty if detached).
+	Branch string
+
+	// DefaultBranch is the repository's default branch (see
+	// GitContext.DefaultBranch); empty if it is unknown.
+	DefaultBranch string
+
+	// Depth is the number of HEAD ancestry commits walked before the slip was resolved.
+	// It exceeds the requested depth only when progressive deepening was needed.
+	Depth int
+
+	// Distance is the number of commits between HEAD and MatchedCommit (0 = HEAD).
+	// With a component filter, only commits touching the component are counted.
+	// In PR-aware mode it is measured from the PR head instead. It is -1 when the
+	// PR base or a fallback resolved the slip, since the matched commit is not in
+	// the searched head's first-parent ancestry.
+	Distance int
+
+	// Confidence classifies Distance so consuming pipelines can decide whether
+	// to proceed automatically or require approval.
+	Confidence Confidence
+
+	// ResolvedBy identifies the strategy that produced the result:
+	// ResolvedByHint, ResolvedByAncestry, ResolvedByPRHead, ResolvedByPRBase,
+	// ResolvedByMergeBase, or ResolvedByBranchLatest.
+	ResolvedBy string
+
+	// SelectionPolicy is the policy that chose this slip among the candidates.
+	SelectionPolicy SelectionPolicy
+
+	// Candidates is the number of matching slips the policy chose from.
+	Candidates int
+
+	// Component is the component the resolution was restricted to, if any.
+	Component string
+
+	// Timings records how long each stage of the run took, in the order the
+	// stages first ran.
+	Timings []StageTiming
+
+	// Chunks counts the store queries the ancestry was split into, when it
+	// was queried in chunks.
+	Chunks ChunkStats
+
+	// Mock reports that the slip came from the mock store and is not real.
+	Mock bool
+
+	// Environment fingerprints the setup of the resolution, when the caller
+	// collected it.
+	Environment *Environment
+}
+
+// ChunkStats counts the chunked store queries of a resolution. Chunks are
+// queried newest first, and none is started once a nearer one has matched.
+type ChunkStats struct {
+	// Queried is the number of chunk queries started, including any cancelled
+	// once a nearer chunk matched.
+	Queried int
+
+	// Needed is the number of chunks up to and including the nearest one with
+	// a match, or every chunk queried on a miss.
+	Needed int
+}
+
+// Stage names reported in StageTiming.
+const (
+	// StageConfigLoad is loading configuration (environment, .env, Vault).
+	StageConfigLoad = "config_load"
+
+	// StageGitOpen is opening the local repository.
+	StageGitOpen = "git_open"
+
+	// StageStoreOpen is connecting to the slip store.
+	StageStoreOpen = "store_open"
+
+	// StageGitContext is reading HEAD, the branch, and the repository name.
+	StageGitContext = "git_context"
+
+	// StageAncestryWalk covers every commit history walk.
+	StageAncestryWalk = "ancestry_walk"
+
+	// StageStoreQuery covers every slip store lookup.
+	StageStoreQuery = "store_query"
+)
+
+// StageTiming is the time spent in one stage of a run. Stages that run more
+// than once (e.g. a store query per deepening step) report the total.
+type StageTiming struct {
+	// Stage is one of the Stage* names.
+	Stage string
+
+	// Duration is the time spent in the stage.
+	Duration time.Duration
+}
+
+// Candidate is one slip that matched a commit in the HEAD ancestry.
+type Candidate struct {
+	// CorrelationID is the unique identifier of the slip.
+	CorrelationID string
+
+	// MatchedCommit is the ancestry commit SHA that matched the slip.
+	MatchedCommit string
+
+	// Distance is the number of commits between HEAD and MatchedCommit (0 = HEAD).
+	// With a component filter, only commits touching the component are counted.
+	Distance int
+
+	// CreatedAt is when the slip was created.
+	CreatedAt time.Time
+
+	// Branch is the branch the slip was created for.
+	Branch string
+}
+
+// ResolveAllOutput contains every candidate slip found in the HEAD ancestry.
+type ResolveAllOutput struct {
+	// Repository is the repository name in owner/repo format.
+	Repository string
+
+	// Branch is the branch name at resolution time (may be empty if detached).
+	Branch string
+
+	// HeadSHA is the commit the ancestry walk started from.
+	HeadSHA string
+
+	// Candidates are ordered by Distance, then newest CreatedAt first.
+	Candidates []Candidate
+}
+
+// DefaultAncestryDepth is the default number of commits to walk when searching for slips.
+const DefaultAncestryDepth = 25
+
+// SchemaVersion is the version of the JSON result and error documents written
+// by the CLI's --format json and returned by serve mode. The minor version
+// increases when fields are added and the major version when fields are
+// removed or change meaning.
+const SchemaVersion = "1.4"
+
+// DefaultSlowGitWalk is the default threshold above which a git walk is logged as slow.
+const DefaultSlowGitWalk = time.Second
+
+// DefaultSlowStoreQuery is the default threshold above which a store query is logged as slow.
+const DefaultSlowStoreQuery = 2 * time.Second
+
+// DepthGrowthFactor is the multiplier applied to the ancestry depth on each deepening step
+// (e.g. 25 -> 100 -> 400).
+const DepthGrowthFactor = 4
+
+// DefaultBranchName is the default branch assumed when the repository's own
+// is unknown.
+const DefaultBranchName = "main"
+
+// Resolution strategies reported in ResolveOutput.ResolvedBy.
+const (
+	// ResolvedByHint means an explicit correlation ID hint was validated and used.
+	ResolvedByHint = "hint"
+
+	// ResolvedByAncestry means a slip matched a commit in HEAD's first-parent ancestry.
+	ResolvedByAncestry = "ancestry"
+
+	// ResolvedByPRHead means a slip matched the first-parent ancestry of the pull request head.
+	ResolvedByPRHead = "pr-head"
+
+	// ResolvedByPRBase means a slip matched the first-parent ancestry of the pull request base.
+	ResolvedByPRBase = "pr-base"
+
+	// ResolvedByMergeBase means a slip matched the ancestry of HEAD's merge base with the default branch.
+	ResolvedByMergeBase = "merge-base"
+
+	// ResolvedByBranchLatest means the newest slip recorded for the current branch was used.
+	ResolvedByBranchLatest = "branch-latest"
+)
+
+// Confidence classifies how closely a resolved slip is tied to HEAD.
+type Confidence string
+
+// Supported confidence classifications.
+const (
+	// ConfidenceExactHead means the slip was created for HEAD itself.
+	ConfidenceExactHead Confidence = "exact-head"
+
+	// ConfidenceNearAncestor means the slip matched an ancestor within NearAncestorMaxDistance commits.
+	ConfidenceNearAncestor Confidence = "near-ancestor"
+
+	// ConfidenceDeepAncestor means the slip matched a more distant ancestor or was found by a fallback.
+	ConfidenceDeepAncestor Confidence = "deep-ancestor"
+)
+
+// NearAncestorMaxDistance is the largest distance from HEAD classified as ConfidenceNearAncestor.
+const NearAncestorMaxDistance = 5
+
+// ClassifyDistance maps a distance from HEAD to a Confidence.
+// A negative distance (unknown, e.g. a fallback match) is ConfidenceDeepAncestor.
+func ClassifyDistance(distance int) Confidence {
+	switch {
+	case distance == 0:
+		return ConfidenceExactHead
+	case distance > 0 && distance <= NearAncestorMaxDistance:
+		return ConfidenceNearAncestor
+	default:
+		return ConfidenceDeepAncestor
+	}
+}
+
+// StepRequirement asserts that a pipeline step of the resolved slip has a given status.
+type StepRequirement struct {
+	// Step is the pipeline step name (e.g. "push_parsed").
+	Step string
+
+	// Status is the required step status (e.g. "completed").
+	Status string
+}
+
+// String formats the requirement as "step=status".
+func (r StepRequirement) String() string {
+	return r.Step + "=" + r.Status
+}
+
+// StepStatuses lists the step statuses a requirement may name.
+var StepStatuses = []string{
+	"pending", "held", "running", "completed", "failed", "error", "aborted", "timeout", "skipped",
+}
+
+// ParseStepRequirement parses "step=status", validating the status against StepStatuses.
+func ParseStepRequirement(s string) (StepRequirement, error) {
+	step, status, ok := strings.Cut(s, "=")
+	step, status = strings.TrimSpace(step), strings.TrimSpace(status)
+	if !ok || step == "" || status == "" {
+		return StepRequirement{}, fmt.Errorf("%w: %q: expected step=status", ErrInvalidStepRequirement, s)
+	}
+	if !slices.Contains(StepStatuses, status) {
+		return StepRequirement{}, fmt.Errorf(
+			"%w: %q: unknown status %q (expected one of %s)",
+			ErrInvalidStepRequirement, s, status, strings.Join(StepStatuses, ", "),
+		)
+	}
+	return StepRequirement{Step: step, Status: status}, nil
+}
+
+// SelectionPolicy names a strategy for choosing among multiple matching slips.
+type SelectionPolicy string
+
+// Supported selection policies.
+const (
+	// SelectionNearestCommit picks the slip whose matched commit is closest to HEAD.
+	SelectionNearestCommit SelectionPolicy = "nearest-commit"
+
+	// SelectionNewestCreated picks the most recently created slip.
+	SelectionNewestCreated SelectionPolicy = "newest-created"
+
+	// SelectionBranchPreferred picks the nearest slip created for the current branch,
+	// falling back to the nearest slip overall if none match the branch.
+	SelectionBranchPreferred SelectionPolicy = "branch-preferred"
+)
+
+// DefaultSelectionPolicy is used when ResolveInput.SelectionPolicy is empty.
+const DefaultSelectionPolicy = SelectionNearestCommit
+
+// SelectionPolicies lists every supported policy in display order.
+var SelectionPolicies = []SelectionPolicy{
+	SelectionNearestCommit,
+	SelectionNewestCreated,
+	SelectionBranchPreferred,
+}
+
+// ParseSelectionPolicy validates a policy name. An empty string yields DefaultSelectionPolicy.
+func ParseSelectionPolicy(name string) (SelectionPolicy, error) {
+	if name == "" {
+		return DefaultSelectionPolicy, nil
+	}
+	for _, p := range SelectionPolicies {
+		if string(p) == name {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q", ErrInvalidSelectionPolicy, name)
+}
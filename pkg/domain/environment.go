@@ -0,0 +1,66 @@
+package domain
+
+// Environment fingerprints the setup a resolution ran in: how the repository
+// was read and cloned and which slip store was searched. It is reported with
+// --verbose and --format json so a user report can be diagnosed without
+// asking for it.
+type Environment struct {
+	// GitBackend reads the repository: GitBackendGoGit, GitBackendCommitList,
+	// or empty if unknown.
+	GitBackend string
+
+	// Shallow reports a shallow clone, whose history stops at the commits it
+	// was cut at.
+	Shallow bool
+
+	// PartialClone reports a partial (e.g. blobless) clone, whose missing
+	// objects git fetches on demand but GitBackendGoGit cannot.
+	PartialClone bool
+
+	// AncestryCache is the state of the ancestry cache for HEAD before the
+	// resolution walked it: one of the AncestryCache constants, or empty if
+	// unknown.
+	AncestryCache string
+
+	// Store is the slip store searched: StoreClickHouse, StoreExec,
+	// StoreMock, or empty if unknown.
+	Store string
+}
+
+// Git backends reported in Environment.GitBackend.
+const (
+	// GitBackendGoGit reads a local repository with go-git.
+	GitBackendGoGit = "go-git"
+
+	// GitBackendCommitList searches a commit list supplied by a client.
+	GitBackendCommitList = "commit-list"
+)
+
+// Ancestry cache states reported in Environment.AncestryCache.
+const (
+	// AncestryCacheDisabled means the ancestry cache is not enabled.
+	AncestryCacheDisabled = "disabled"
+
+	// AncestryCacheCold means there is no usable cache, so history is walked
+	// in full and cached.
+	AncestryCacheCold = "cold"
+
+	// AncestryCacheWarm means the cache was written for HEAD and is used as is.
+	AncestryCacheWarm = "warm"
+
+	// AncestryCacheStale means the cache was written for another HEAD, so
+	// the commits since it are walked and the cache is updated.
+	AncestryCacheStale = "stale"
+)
+
+// Slip stores reported in Environment.Store.
+const (
+	// StoreClickHouse is the ClickHouse slip store.
+	StoreClickHouse = "clickhouse"
+
+	// StoreExec is a finder plugin run as a child process.
+	StoreExec = "exec"
+
+	// StoreMock is the mock store of SLIPPY_STORE=mock.
+	StoreMock = "mock"
+)
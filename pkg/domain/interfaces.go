@@ -0,0 +1,352 @@
+// Package domain defines the core business entities and interfaces for slippy-find.
+// This package contains no external dependencies and represents the innermost layer
+// of the CLEAN architecture.
+package domain
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"time"
+)
+
+// Domain errors for git operations and slip resolution.
+var (
+	// ErrRepositoryNotFound indicates the specified path is not a valid Git repository.
+	ErrRepositoryNotFound = errors.New("git repository not found at specified path")
+
+	// ErrNoRemoteOrigin indicates no 'origin' remote is configured in the repository.
+	ErrNoRemoteOrigin = errors.New("no 'origin' remote configured; cannot determine repository name")
+
+	// ErrInvalidRemoteURL indicates the remote URL could not be parsed to extract owner/repo.
+	ErrInvalidRemoteURL = errors.New("could not parse repository name from remote URL")
+
+	// ErrInvalidRepository indicates a repository identifier is not a valid
+	// owner/repo name.
+	ErrInvalidRepository = errors.New("invalid repository identifier")
+
+	// ErrNoAncestorSlip indicates no slip was found in the commit ancestry.
+	ErrNoAncestorSlip = errors.New("no slip found in commit ancestry")
+
+	// ErrEmptyAncestry indicates the commit ancestry walk returned no commits.
+	ErrEmptyAncestry = errors.New("commit ancestry is empty")
+
+	// ErrSlipNotFound indicates a direct slip lookup found nothing.
+	ErrSlipNotFound = errors.New("slip not found")
+
+	// ErrBranchNotFound indicates a branch could not be found locally or on origin.
+	ErrBranchNotFound = errors.New("branch not found")
+
+	// ErrCommitNotFound indicates a commit SHA is not present in the local repository.
+	ErrCommitNotFound = errors.New("commit not found")
+
+	// ErrAmbiguousCommit indicates an abbreviated commit SHA matches more than
+	// one commit in the local repository.
+	ErrAmbiguousCommit = errors.New("ambiguous abbreviated commit SHA")
+
+	// ErrObjectFormatMismatch indicates a commit SHA has the length of another
+	// object format than the repository's, or a list mixes SHA-1 and SHA-256.
+	ErrObjectFormatMismatch = errors.New("commit SHA does not match the object format")
+
+	// ErrUnsupportedObjectFormat indicates the repository uses an object format
+	// this build cannot read.
+	ErrUnsupportedObjectFormat = errors.New("unsupported repository object format")
+
+	// ErrInvalidCommitList indicates a caller-supplied commit list is empty,
+	// too long, or holds a value that is not a commit SHA; see CommitListError.
+	ErrInvalidCommitList = errors.New("invalid commit list")
+
+	// ErrStageTimeout indicates a resolution stage ran out of its
+	// StageTimeouts budget.
+	ErrStageTimeout = errors.New("stage timed out")
+
+	// ErrNoMergeBase indicates HEAD and another branch share no common ancestor.
+	ErrNoMergeBase = errors.New("no merge base found")
+
+	// ErrAncestryTooLarge indicates a history walk would hold more commits in
+	// memory than its limit allows.
+	ErrAncestryTooLarge = errors.New("commit ancestry exceeds the commit limit")
+
+	// ErrInvalidSelectionPolicy indicates an unknown selection policy name.
+	ErrInvalidSelectionPolicy = errors.New("invalid selection policy")
+
+	// ErrInvalidStepRequirement indicates a malformed step=status requirement.
+	ErrInvalidStepRequirement = errors.New("invalid step requirement")
+
+	// ErrStepRequirementUnmet indicates the resolved slip's steps do not satisfy a requirement.
+	ErrStepRequirementUnmet = errors.New("step requirement not met")
+
+	// ErrInvalidHint indicates a hinted correlation ID failed validation against the store.
+	ErrInvalidHint = errors.New("invalid slip hint")
+
+	// ErrMalformedCorrelationID indicates the store returned a slip whose
+	// correlation ID does not have the expected format.
+	ErrMalformedCorrelationID = errors.New("malformed correlation ID")
+
+	// ErrAnnotationUnsupported indicates the slip store cannot record annotations.
+	ErrAnnotationUnsupported = errors.New("slip store does not support annotations")
+
+	// ErrHistoryUnavailable indicates an operation needs repository history that
+	// is not available, as when only a list of commits is known.
+	ErrHistoryUnavailable = errors.New("repository history not available")
+
+	// ErrPingUnsupported indicates the slip store cannot check its connection.
+	ErrPingUnsupported = errors.New("slip store does not support health checks")
+
+	// ErrNearestUnsupported indicates the slip store cannot look up the slips
+	// of the nearest matching commit in one query.
+	ErrNearestUnsupported = errors.New("slip store does not support nearest-commit lookups")
+
+	// ErrRecordUnsupported indicates the repository cannot record resolved slips.
+	ErrRecordUnsupported = errors.New("repository does not support recording slips")
+
+	// ErrStreamUnsupported indicates the repository cannot stream its ancestry.
+	ErrStreamUnsupported = errors.New("repository does not support streaming ancestry")
+
+	// ErrResolveCommitUnsupported indicates the repository cannot expand
+	// revisions to full commit SHAs.
+	ErrResolveCommitUnsupported = errors.New("repository does not support resolving commits")
+)
+
+// LocalGitRepository provides git context and commit ancestry from a local repository.
+// This interface replaces the GitHub API-based GitHubAPI interface from goLibMyCarrier/slippy.
+// The repository path is the ONLY external input - all other context is derived from Git.
+type LocalGitRepository interface {
+	// GetGitContext extracts all necessary context from the repository.
+	// This includes HEAD SHA, branch name, and repository name derived from origin remote.
+	// Returns ErrNoRemoteOrigin if no origin remote is configured.
+	// Logs a warning if HEAD is detached but continues with empty branch name.
+	GetGitContext(ctx context.Context) (*GitContext, error)
+
+	// GetCommitAncestry walks the commit graph from HEAD, returning commit SHAs.
+	// Returns commits in order from newest (HEAD) to oldest, up to depth commits.
+	// The depth parameter limits how far back in history to walk.
+	GetCommitAncestry(ctx context.Context, depth int) ([]string, error)
+
+	// GetMergeBaseAncestry walks the first-parent chain starting at the merge base
+	// of HEAD and the given branch, returning up to depth commit SHAs (newest first).
+	// Returns ErrBranchNotFound if the branch does not exist and ErrNoMergeBase
+	// if HEAD and the branch share no history.
+	GetMergeBaseAncestry(ctx context.Context, branch string, depth int) ([]string, error)
+
+	// GetPathAncestry walks the first-parent chain from HEAD like GetCommitAncestry,
+	// but returns only commits whose changes touch one of the given path prefixes,
+	// up to depth such commits (newest first). A prefix of "." matches every path.
+	// Returns ErrEmptyAncestry if no commit in the history touches the paths.
+	GetPathAncestry(ctx context.Context, paths []string, depth int) ([]string, error)
+
+	// GetPathAncestries is GetPathAncestry for several named path sets, walking
+	// the history a single time. Each key maps to up to depth commit SHAs touching
+	// its paths (newest first); keys no commit touches are absent.
+	GetPathAncestries(ctx context.Context, paths map[string][]string, depth int) (map[string][]string, error)
+
+	// GetAncestryFrom walks the first-parent chain from rev, a full or
+	// abbreviated commit SHA or a branch name, returning up to depth commit
+	// SHAs (newest first). Returns ErrCommitNotFound if the SHA is not present
+	// locally, ErrAmbiguousCommit if an abbreviated SHA matches several
+	// commits, and ErrBranchNotFound if the branch does not exist.
+	GetAncestryFrom(ctx context.Context, rev string, depth int) ([]string, error)
+
+	// Close releases any resources held by the repository.
+	Close() error
+}
+
+// OutputWriter writes resolved slip data to an output destination.
+type OutputWriter interface {
+	// WriteCorrelationID writes the correlation ID to the output.
+	WriteCorrelationID(correlationID string) error
+}
+
+// ResultWriter writes a resolved slip in an output format added by an
+// extension, selected with --format.
+type ResultWriter interface {
+	// WriteResult writes result to w.
+	WriteResult(w io.Writer, result *ResolveOutput) error
+}
+
+// SlipFinder queries the slip store to find slips by commit ancestry.
+type SlipFinder interface {
+	// FindByCommits searches for a slip matching any of the given commits.
+	// Returns the slip, the matched commit SHA, and any error.
+	// Returns (nil, "", nil) if no matching slip is found.
+	FindByCommits(ctx context.Context, repository string, commits []string) (*Slip, string, error)
+
+	// FindAllByCommits returns every slip matching any of the given commits.
+	// Results are ordered by commit priority (the slip for the earliest commit
+	// in the list first). Returns an empty slice if nothing matches.
+	FindAllByCommits(ctx context.Context, repository string, commits []string) ([]SlipMatch, error)
+
+	// FindLatestByBranch returns the most recently created slip for the repository and branch.
+	// Returns ErrSlipNotFound if the branch has no slips.
+	FindLatestByBranch(ctx context.Context, repository, branch string) (*Slip, error)
+
+	// FindByID returns the slip with the given correlation ID.
+	// Returns ErrSlipNotFound if no such slip exists.
+	FindByID(ctx context.Context, correlationID string) (*Slip, error)
+
+	// Close releases any resources held by the finder.
+	Close() error
+}
+
+// SlipAnnotator records audit entries in a slip's state history.
+// A SlipFinder that can write to the store implements it as well.
+type SlipAnnotator interface {
+	// AppendHistory appends entry to the state history of the slip with correlationID.
+	AppendHistory(ctx context.Context, correlationID string, entry HistoryEntry) error
+}
+
+// NearestSlipsFinder looks up every slip recorded for the nearest matching
+// commit at once, so a tie between them is seen without a second query.
+// A SlipFinder backed by a store that can do so implements it as well.
+type NearestSlipsFinder interface {
+	// FindNearestByCommits returns every slip recorded for the first of
+	// commits that has any. Returns an empty slice if nothing matches.
+	FindNearestByCommits(ctx context.Context, repository string, commits []string) ([]SlipMatch, error)
+}
+
+// StorePinger checks the connection to the slip store.
+// A SlipFinder backed by a network store implements it as well.
+type StorePinger interface {
+	// Ping returns an error if the store cannot be reached.
+	Ping(ctx context.Context) error
+}
+
+// ChunkSizer chooses how many commits go in each chunked store query, when
+// a deep ancestry is split into several. A SlipFinder tuned for its backend
+// implements it as well.
+type ChunkSizer interface {
+	// ChunkSize returns the commits per chunk, or 0 for the resolver's default.
+	ChunkSize() int
+}
+
+// EnvironmentDescriber contributes to the environment fingerprint. It is an
+// optional capability of LocalGitRepository and SlipFinder.
+type EnvironmentDescriber interface {
+	// DescribeEnvironment sets the fields of env that describe the receiver.
+	DescribeEnvironment(env *Environment)
+}
+
+// CommitResolver expands a revision to the full SHA of its commit, so an
+// abbreviated SHA pasted by a person can be used where a full one is needed.
+// It is an optional capability of a LocalGitRepository.
+type CommitResolver interface {
+	// ResolveCommit returns the full SHA of rev, a full or abbreviated commit
+	// SHA or a branch name, with the errors of GetAncestryFrom.
+	ResolveCommit(ctx context.Context, rev string) (string, error)
+}
+
+// AuditWriter records resolutions in an audit log kept outside the slips.
+type AuditWriter interface {
+	// WriteAudit records event.
+	WriteAudit(ctx context.Context, event AuditEvent) error
+
+	// Close releases any resources held by the writer.
+	Close() error
+}
+
+// AncestryStreamer walks HEAD's ancestry incrementally, so the store can be
+// queried for the newest commits while older ones are still being read. It is
+// an optional capability of LocalGitRepository.
+type AncestryStreamer interface {
+	// StreamCommitAncestry calls yield with each commit GetCommitAncestry would
+	// return for depth, in the same order, stopping early when yield returns false.
+	StreamCommitAncestry(ctx context.Context, depth int, yield func(sha string) bool) error
+}
+
+// SlipRecorder records resolved slips in the repository itself. It is an
+// optional capability of LocalGitRepository.
+type SlipRecorder interface {
+	// RecordSlip stores record.CorrelationID against record.Commit.
+	RecordSlip(ctx context.Context, record SlipRecord) error
+}
+
+// FailureNotifier alerts a team channel about resolution failures.
+type FailureNotifier interface {
+	// Notify sends one alert for failure.
+	Notify(ctx context.Context, failure ResolutionFailure) error
+}
+
+// ResolutionPublisher streams successful resolutions to downstream consumers,
+// such as analytics and deployment trackers.
+type ResolutionPublisher interface {
+	// PublishResolutions publishes one event per resolution.
+	PublishResolutions(ctx context.Context, events []AuditEvent) error
+}
+
+// Slip represents a routing slip found in the store.
+// This is a domain representation - the actual slip structure comes from goLibMyCarrier.
+type Slip struct {
+	// CorrelationID is the unique identifier for the slip.
+	CorrelationID string
+
+	// Repository is the repository the slip belongs to (owner/repo).
+	Repository string
+
+	// Branch is the branch the slip was created for.
+	Branch string
+
+	// CommitSHA is the commit the slip was created for.
+	CommitSHA string
+
+	// CreatedAt is when the slip was created.
+	CreatedAt time.Time
+
+	// Components lists the monorepo components that appear in the slip's
+	// aggregate steps, sorted by name.
+	Components []string
+
+	// Steps maps pipeline step names to their current status (e.g. "completed").
+	Steps map[string]string
+
+	// Mock marks a slip invented by the mock store rather than recorded by a
+	// pipeline.
+	Mock bool
+}
+
+// HasComponent reports whether component appears in the slip's aggregate steps.
+func (s *Slip) HasComponent(component string) bool {
+	return slices.Contains(s.Components, component)
+}
+
+// Precedes reports whether s wins a tie against other, such as two slips
+// recorded for the same commit: the newer CreatedAt wins, then the
+// lexicographically smaller CorrelationID. The order is total, so the outcome
+// never depends on the order the store returned the slips in.
+func (s *Slip) Precedes(other *Slip) bool {
+	if !s.CreatedAt.Equal(other.CreatedAt) {
+		return s.CreatedAt.After(other.CreatedAt)
+	}
+	return s.CorrelationID < other.CorrelationID
+}
+
+// SlipMatch pairs a slip with the commit from the searched list that matched it.
+type SlipMatch struct {
+	// Slip is the matching routing slip.
+	Slip *Slip
+
+	// MatchedCommit is the commit SHA that matched the slip.
+	MatchedCommit string
+}
+
+// Resolver resolves routing slips from git context.
+type Resolver interface {
+	// Resolve finds a routing slip for the current git state.
+	Resolve(ctx context.Context, input ResolveInput) (*ResolveOutput, error)
+
+	// ResolveAll returns every slip matching the HEAD ancestry instead of choosing one,
+	// so callers can apply their own selection logic. Depth, MaxAge, Since, and the
+	// component fields of input apply; selection policy, deepening, and fallbacks do not.
+	// Returns ErrNoAncestorSlip if nothing matches.
+	ResolveAll(ctx context.Context, input ResolveInput) (*ResolveAllOutput, error)
+
+	// ResolveComponents runs Resolve for each monorepo component in components
+	// (name -> path prefixes), sharing one path-filtered history walk and the
+	// same store. input.Component and input.ComponentPaths are ignored. Fails if
+	// any component cannot be resolved, wrapping that component's error.
+	ResolveComponents(
+		ctx context.Context,
+		input ResolveInput,
+		components map[string][]string,
+	) (map[string]*ResolveOutput, error)
+}
@@ -0,0 +1,72 @@
+package domain
+
+import "fmt"
+
+// ObjectFormat is the hash algorithm a Git repository names its objects
+// with, as in its extensions.objectformat setting.
+type ObjectFormat string
+
+// Object formats a repository can use.
+const (
+	// ObjectFormatSHA1 is the default format: 40-character commit SHAs.
+	ObjectFormatSHA1 ObjectFormat = "sha1"
+
+	// ObjectFormatSHA256 is the format of repositories initialized with
+	// --object-format=sha256: 64-character commit SHAs.
+	ObjectFormatSHA256 ObjectFormat = "sha256"
+)
+
+// HexLength returns the length of a full commit SHA in format f, or 0 for an
+// unknown format.
+func (f ObjectFormat) HexLength() int {
+	switch f {
+	case ObjectFormatSHA1:
+		return 40
+	case ObjectFormatSHA256:
+		return 64
+	default:
+		return 0
+	}
+}
+
+// ObjectFormatOf returns the format sha is a full commit SHA of, or "" if
+// sha is not 40 or 64 hexadecimal digits.
+func ObjectFormatOf(sha string) ObjectFormat {
+	var format ObjectFormat
+	switch len(sha) {
+	case ObjectFormatSHA1.HexLength():
+		format = ObjectFormatSHA1
+	case ObjectFormatSHA256.HexLength():
+		format = ObjectFormatSHA256
+	default:
+		return ""
+	}
+	for _, c := range sha {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') && (c < 'A' || c > 'F') {
+			return ""
+		}
+	}
+	return format
+}
+
+// CheckObjectFormat returns ErrObjectFormatMismatch if a full commit SHA
+// among shas is not of format. An empty format is taken from the first full
+// SHA, so the check rejects a list mixing formats. Values that are not full
+// SHAs are left to other validation.
+func CheckObjectFormat(format ObjectFormat, shas ...string) error {
+	for _, sha := range shas {
+		got := ObjectFormatOf(sha)
+		if got == "" {
+			continue
+		}
+		if format == "" {
+			format = got
+			continue
+		}
+		if got != format {
+			return fmt.Errorf("%w: %s is a %s SHA, expected %s (%d characters)",
+				ErrObjectFormatMismatch, sha, got, format, format.HexLength())
+		}
+	}
+	return nil
+}
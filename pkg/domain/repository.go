@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseRepository validates and normalizes a repository identifier to
+// owner/repo. Surrounding whitespace and a .git suffix are removed, and a
+// leading host such as "GitHub.com/" is dropped. The owner and repo keep
+// their case, since the store compares names case-insensitively.
+//
+// Returns ErrInvalidRepository if an owner or repo component is empty, the
+// identifier has more components, or a component contains characters other
+// than letters, digits, '.', '-', and '_'.
+func ParseRepository(s string) (string, error) {
+	repository := strings.TrimSpace(s)
+	if len(repository) >= len(".git") && strings.EqualFold(repository[len(repository)-len(".git"):], ".git") {
+		repository = repository[:len(repository)-len(".git")]
+	}
+	parts := strings.Split(repository, "/")
+	if len(parts) == 3 && isHost(parts[0]) {
+		parts = parts[1:]
+	}
+
+	switch {
+	case repository == "":
+		return "", fmt.Errorf("%w: empty repository identifier", ErrInvalidRepository)
+	case len(parts) == 1:
+		return "", fmt.Errorf("%w: %q is not in owner/repo format", ErrInvalidRepository, s)
+	case len(parts) > 2:
+		return "", fmt.Errorf("%w: %q has %d path components, expected owner/repo", ErrInvalidRepository, s, len(parts))
+	case parts[0] == "":
+		return "", fmt.Errorf("%w: %q has an empty owner", ErrInvalidRepository, s)
+	case parts[1] == "":
+		return "", fmt.Errorf("%w: %q has an empty repository name", ErrInvalidRepository, s)
+	}
+	for i, name := range []string{"owner", "repository name"} {
+		if err := checkRepositoryComponent(parts[i]); err != nil {
+			return "", fmt.Errorf("%w: %q has an invalid %s: %w", ErrInvalidRepository, s, name, err)
+		}
+	}
+	return parts[0] + "/" + parts[1], nil
+}
+
+// isHost reports whether component looks like a host name such as
+// github.com: it contains a dot, but does not start or end with one.
+func isHost(component string) bool {
+	return strings.Contains(component, ".") && !strings.HasPrefix(component, ".") &&
+		!strings.HasSuffix(component, ".")
+}
+
+// checkRepositoryComponent checks one component of an owner/repo identifier.
+func checkRepositoryComponent(component string) error {
+	if component == "." || component == ".." {
+		return fmt.Errorf("%q is not a name", component)
+	}
+	for _, c := range component {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '.', c == '-', c == '_':
+		default:
+			return fmt.Errorf("character %q is not allowed", c)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+// Package extension lets downstream builds compile custom resolution
+// strategies and output formats into slippy-find without forking it.
+//
+// An extension is a package that registers itself from an init function:
+//
+//	func init() {
+//		extension.RegisterStrategyBefore(domain.ResolvedByMergeBase, releaseTagStrategy{})
+//		extension.RegisterOutputFormat("teamcity", teamCityWriter{})
+//	}
+//
+// A downstream build enables it with a build-tag gated file in the main
+// package, so the default build stays unchanged:
+//
+//	//go:build acme
+//
+//	package main
+//
+//	import _ "example.com/acme/slippy-extensions"
+//
+// and builds with go build -tags acme. Registrations only apply to resolvers
+// created after them, so they belong in init functions. An invalid
+// registration panics, so a broken extension fails at startup.
+package extension
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/MyCarrier-DevOps/slippy-find/internal/usecases"
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// Strategy is one step of the resolution pipeline. See usecases.Strategy.
+type Strategy = usecases.Strategy
+
+// StrategyRequest is the request a Strategy resolves, with helpers that query
+// the store under the request's selection rules.
+type StrategyRequest = usecases.StrategyRequest
+
+// StrategyResult is the outcome of a Strategy.
+type StrategyResult = usecases.StrategyResult
+
+// StrategyRegistry is an ordered list of strategies.
+type StrategyRegistry = usecases.StrategyRegistry
+
+// builtinFormats are the --format values slippy-find itself provides.
+var builtinFormats = []string{"text", "json"}
+
+var (
+	mu         sync.Mutex
+	strategies = usecases.DefaultStrategyRegistry()
+	formats    = map[string]domain.ResultWriter{}
+)
+
+// RegisterStrategy adds s after every registered strategy, so it runs only
+// when the built-ins miss. It panics if the name of s is taken.
+func RegisterStrategy(s Strategy) {
+	mu.Lock()
+	defer mu.Unlock()
+	if err := strategies.Register(s); err != nil {
+		panic(fmt.Sprintf("extension: %v", err))
+	}
+}
+
+// RegisterStrategyBefore inserts s immediately before the strategy named
+// before, such as domain.ResolvedByMergeBase. It panics if before is not
+// registered or the name of s is taken.
+func RegisterStrategyBefore(before string, s Strategy) {
+	mu.Lock()
+	defer mu.Unlock()
+	if err := strategies.RegisterBefore(before, s); err != nil {
+		panic(fmt.Sprintf("extension: %v", err))
+	}
+}
+
+// RegisterOutputFormat adds name as a --format value written by w. It panics
+// if name is empty, built in, or already registered.
+func RegisterOutputFormat(name string, w domain.ResultWriter) {
+	mu.Lock()
+	defer mu.Unlock()
+	switch {
+	case name == "":
+		panic("extension: output format name is empty")
+	case slices.Contains(builtinFormats, name):
+		panic(fmt.Sprintf("extension: output format %q is built in", name))
+	case formats[name] != nil:
+		panic(fmt.Sprintf("extension: output format %q already registered", name))
+	}
+	formats[name] = w
+}
+
+// Strategies returns a new registry holding the built-in and registered
+// strategies in resolution order. Changes to it do not affect later calls.
+func Strategies() *StrategyRegistry {
+	mu.Lock()
+	defer mu.Unlock()
+	registry := usecases.NewStrategyRegistry()
+	for _, s := range strategies.Strategies() {
+		// Names are unique in the source registry, so this cannot fail
+		_ = registry.Register(s)
+	}
+	return registry
+}
+
+// OutputFormats returns the registered output formats by name.
+func OutputFormats() map[string]domain.ResultWriter {
+	mu.Lock()
+	defer mu.Unlock()
+	return maps.Clone(formats)
+}
@@ -0,0 +1,98 @@
+package extension
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/MyCarrier-DevOps/slippy-find/internal/usecases"
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// namedStrategy is a Strategy that always misses.
+type namedStrategy string
+
+func (s namedStrategy) Name() string { return string(s) }
+
+func (s namedStrategy) Resolve(context.Context, *StrategyRequest) (StrategyResult, error) {
+	return StrategyResult{}, nil
+}
+
+// idWriter writes the correlation ID.
+type idWriter struct{}
+
+func (idWriter) WriteResult(w io.Writer, result *domain.ResolveOutput) error {
+	_, err := io.WriteString(w, result.CorrelationID)
+	return err
+}
+
+// resetRegistrations restores the built-in registrations after the test.
+func resetRegistrations(t *testing.T) {
+	t.Cleanup(func() {
+		strategies = usecases.DefaultStrategyRegistry()
+		formats = map[string]domain.ResultWriter{}
+	})
+}
+
+func TestRegisterStrategy(t *testing.T) {
+	resetRegistrations(t)
+
+	RegisterStrategy(namedStrategy("last-resort"))
+	RegisterStrategyBefore(domain.ResolvedByMergeBase, namedStrategy("release-tag"))
+
+	assert.Equal(t, []string{
+		domain.ResolvedByHint, domain.ResolvedByAncestry, domain.ResolvedByPRHead, domain.ResolvedByPRBase,
+		"release-tag", domain.ResolvedByMergeBase, domain.ResolvedByBranchLatest, "last-resort",
+	}, Strategies().Names())
+}
+
+func TestStrategies_IsACopy(t *testing.T) {
+	resetRegistrations(t)
+
+	registry := Strategies()
+	assert.NoError(t, registry.Remove(domain.ResolvedByHint))
+
+	assert.Contains(t, Strategies().Names(), domain.ResolvedByHint)
+}
+
+func TestRegisterStrategy_Panics(t *testing.T) {
+	resetRegistrations(t)
+
+	assert.PanicsWithValue(t, "extension: strategy already registered: ancestry", func() {
+		RegisterStrategy(namedStrategy(domain.ResolvedByAncestry))
+	})
+	assert.PanicsWithValue(t, "extension: strategy not registered: missing", func() {
+		RegisterStrategyBefore("missing", namedStrategy("release-tag"))
+	})
+}
+
+func TestRegisterOutputFormat(t *testing.T) {
+	resetRegistrations(t)
+
+	RegisterOutputFormat("id", idWriter{})
+
+	assert.Equal(t, map[string]domain.ResultWriter{"id": idWriter{}}, OutputFormats())
+}
+
+func TestRegisterOutputFormat_Panics(t *testing.T) {
+	resetRegistrations(t)
+	RegisterOutputFormat("id", idWriter{})
+
+	tests := []struct {
+		name      string
+		format    string
+		wantPanic string
+	}{
+		{name: "empty", format: "", wantPanic: "extension: output format name is empty"},
+		{name: "built in", format: "json", wantPanic: `extension: output format "json" is built in`},
+		{name: "duplicate", format: "id", wantPanic: `extension: output format "id" already registered`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.PanicsWithValue(t, tt.wantPanic, func() { RegisterOutputFormat(tt.format, idWriter{}) })
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package gitcontext
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// MinAbbrevLength is the shortest abbreviated commit SHA accepted, as in git.
+const MinAbbrevLength = 4
+
+// ErrAmbiguousCommit indicates an abbreviated commit SHA matches more than
+// one commit.
+var ErrAmbiguousCommit = domain.ErrAmbiguousCommit
+
+// maxAmbiguousListed caps the candidates named in an ErrAmbiguousCommit error.
+const maxAmbiguousListed = 5
+
+// prefixStorer is implemented by object storages that can list the objects
+// whose hash starts with a prefix, such as the filesystem storage, which
+// reads it from the pack indexes instead of decoding every object.
+type prefixStorer interface {
+	HashesWithPrefix(prefix []byte) ([]plumbing.Hash, error)
+}
+
+// ResolveCommit returns the full SHA of the commit rev names: a full or
+// abbreviated commit SHA, or a branch name looked up as in MergeBaseAncestry.
+// An abbreviated SHA that also names a branch means the commit, as in git.
+// Returns ErrCommitNotFound if no commit matches, ErrAmbiguousCommit if
+// several do, and ErrBranchNotFound for an unknown branch.
+func (r *Repository) ResolveCommit(rev string) (string, error) {
+	commit, err := r.resolveRevision(rev)
+	if err != nil {
+		return "", err
+	}
+	return commit.Hash.String(), nil
+}
+
+// isAbbreviatedSHA reports whether rev could be an abbreviated commit SHA:
+// MinAbbrevLength or more hexadecimal digits, fewer than a full SHA.
+func isAbbreviatedSHA(rev string) bool {
+	if len(rev) < MinAbbrevLength || len(rev) >= len(plumbing.ZeroHash)*2 {
+		return false
+	}
+	for _, c := range rev {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') && (c < 'A' || c > 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// expandAbbreviated returns the commit whose SHA starts with prefix. Returns
+// ErrCommitNotFound if none does and ErrAmbiguousCommit if several do.
+func (r *Repository) expandAbbreviated(prefix string) (*object.Commit, error) {
+	prefix = strings.ToLower(prefix)
+	hashes, err := r.hashesWithPrefix(prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up commit %s: %w", prefix, err)
+	}
+
+	var commits []*object.Commit
+	for _, h := range hashes {
+		commit, err := r.repo.CommitObject(h)
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			continue // a tree, blob, or tag sharing the prefix
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get commit object for %s: %w", h, err)
+		}
+		commits = append(commits, commit)
+	}
+
+	switch len(commits) {
+	case 0:
+		return nil, fmt.Errorf("%w: %s", ErrCommitNotFound, prefix)
+	case 1:
+		return commits[0], nil
+	}
+	listed := make([]string, 0, maxAmbiguousListed)
+	for _, commit := range commits[:min(len(commits), maxAmbiguousListed)] {
+		listed = append(listed, commit.Hash.String())
+	}
+	more := ""
+	if len(commits) > maxAmbiguousListed {
+		more = fmt.Sprintf(" and %d more", len(commits)-maxAmbiguousListed)
+	}
+	return nil, fmt.Errorf("%w: %s matches %d commits (%s%s); use more digits",
+		ErrAmbiguousCommit, prefix, len(commits), strings.Join(listed, ", "), more)
+}
+
+// hashesWithPrefix returns the hashes of the objects whose SHA starts with
+// prefix, lower-case hexadecimal digits.
+func (r *Repository) hashesWithPrefix(prefix string) ([]plumbing.Hash, error) {
+	storer, ok := r.repo.Storer.(prefixStorer)
+	if !ok {
+		return r.commitsWithPrefix(prefix)
+	}
+	// The storage matches whole bytes; an odd digit is checked afterwards
+	even, err := hex.DecodeString(prefix[:len(prefix)&^1])
+	if err != nil {
+		return nil, err
+	}
+	candidates, err := storer.HashesWithPrefix(even)
+	if err != nil {
+		return nil, err
+	}
+	hashes := candidates[:0]
+	for _, h := range candidates {
+		if strings.HasPrefix(h.String(), prefix) {
+			hashes = append(hashes, h)
+		}
+	}
+	return hashes, nil
+}
+
+// commitsWithPrefix is hashesWithPrefix for storages that cannot search by
+// prefix, scanning every commit.
+func (r *Repository) commitsWithPrefix(prefix string) ([]plumbing.Hash, error) {
+	iter, err := r.repo.CommitObjects()
+	if err != nil {
+		return nil, err
+	}
+	var hashes []plumbing.Hash
+	err = iter.ForEach(func(commit *object.Commit) error {
+		if strings.HasPrefix(commit.Hash.String(), prefix) {
+			hashes = append(hashes, commit.Hash)
+		}
+		return nil
+	})
+	return hashes, err
+}
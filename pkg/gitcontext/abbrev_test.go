@@ -0,0 +1,108 @@
+package gitcontext
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_ResolveCommit(t *testing.T) {
+	dir, commits := mergedRepo(t)
+	for _, packed := range []bool{false, true} {
+		t.Run(fmt.Sprintf("packed=%t", packed), func(t *testing.T) {
+			if packed {
+				runGit(t, dir, "gc", "--quiet")
+			}
+			repo, err := Open(dir)
+			require.NoError(t, err)
+			side := commits["side"]
+
+			tests := []struct {
+				name    string
+				rev     string
+				want    string
+				wantErr error
+			}{
+				{name: "full", rev: side, want: side},
+				{name: "abbreviated", rev: side[:7], want: side},
+				{name: "upper case", rev: strings.ToUpper(side[:8]), want: side},
+				{name: "branch", rev: "side", want: side},
+				{name: "too short for a SHA", rev: side[:3], wantErr: ErrBranchNotFound},
+				{name: "unknown prefix", rev: "0000000", wantErr: ErrCommitNotFound},
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					got, err := repo.ResolveCommit(tt.rev)
+
+					if tt.wantErr != nil {
+						assert.ErrorIs(t, err, tt.wantErr)
+						return
+					}
+					require.NoError(t, err)
+					assert.Equal(t, tt.want, got)
+				})
+			}
+		})
+	}
+}
+
+func TestRepository_ResolveCommit_Ambiguous(t *testing.T) {
+	dir, _ := mergedRepo(t)
+	prefix := ambiguousPrefix(t, dir)
+	repo, err := Open(dir)
+	require.NoError(t, err)
+
+	_, err = repo.ResolveCommit(prefix)
+
+	require.ErrorIs(t, err, ErrAmbiguousCommit)
+	assert.Contains(t, err.Error(), prefix+" matches ")
+	_, err = repo.AncestryFrom(context.Background(), prefix, 0)
+	assert.ErrorIs(t, err, ErrAmbiguousCommit)
+}
+
+func TestRepository_ResolveCommit_HexBranch(t *testing.T) {
+	dir, commits := mergedRepo(t)
+	runGit(t, dir, "branch", "cafe", commits["side"])
+	repo, err := Open(dir)
+	require.NoError(t, err)
+
+	got, err := repo.ResolveCommit("cafe")
+
+	require.NoError(t, err)
+	assert.Equal(t, commits["side"], got, "a hex branch name matching no commit is a branch")
+}
+
+// ambiguousPrefix adds commits to the repository at dir until two commits
+// share a MinAbbrevLength-digit SHA prefix, and returns that prefix.
+func ambiguousPrefix(t *testing.T, dir string) string {
+	t.Helper()
+	var stream strings.Builder
+	for i := 1; i <= 2000; i++ {
+		fmt.Fprintf(&stream, "commit refs/heads/many\nmark :%d\ncommitter T <t@example.com> %d +0000\ndata %d\n%d\n",
+			i, 1700000000+i, len(fmt.Sprint(i))+1, i)
+		if i > 1 {
+			fmt.Fprintf(&stream, "from :%d\n", i-1)
+		}
+	}
+	cmd := exec.Command("git", "fast-import", "--quiet")
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(stream.String())
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git fast-import: %s", out)
+
+	seen := map[string]bool{}
+	for _, sha := range strings.Fields(runGit(t, dir, "rev-list", "--all")) {
+		prefix := sha[:MinAbbrevLength]
+		if seen[prefix] {
+			return prefix
+		}
+		seen[prefix] = true
+	}
+	t.Fatal("no two commits share a prefix")
+	return ""
+}
@@ -0,0 +1,96 @@
+package gitcontext
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/storage/memory"
+
+	"github.com/MyCarrier-DevOps/slippy-find/internal/benchrepo"
+)
+
+// benchSizes are the history lengths, in first-parent commits, benchmarked.
+var benchSizes = []int{1_000, 10_000, 100_000}
+
+// benchRepos caches generated repositories across benchmarks, keyed by
+// shape and size.
+var benchRepos sync.Map
+
+// benchRepo returns an in-memory repository of n commits laid out as shape,
+// generating it on first use. The largest sizes are skipped with -short.
+func benchRepo(b *testing.B, shape benchrepo.Shape, n int) *Repository {
+	b.Helper()
+	if n > 10_000 && testing.Short() {
+		b.Skip("large repository skipped in short mode")
+	}
+	key := fmt.Sprintf("%s/%d", shape, n)
+	cached, ok := benchRepos.Load(key)
+	if !ok {
+		st := memory.NewStorage()
+		if _, err := benchrepo.Generate(st, shape, n); err != nil {
+			b.Fatal(err)
+		}
+		repo, err := git.Open(st, nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+		cached, _ = benchRepos.LoadOrStore(key, &Repository{repo: repo, remote: DefaultRemote})
+	}
+	return cached.(*Repository)
+}
+
+// benchWalk runs walk to the full depth of every shape and size, reporting
+// the commits it reached.
+func benchWalk(b *testing.B, opts []Option, walk func(ctx context.Context, repo *Repository, depth int) (int, error)) {
+	for _, shape := range benchrepo.Shapes {
+		for _, n := range benchSizes {
+			b.Run(fmt.Sprintf("%s/%d", shape, n), func(b *testing.B) {
+				repo := benchRepo(b, shape, n).With(opts...)
+				ctx := context.Background()
+				b.ReportAllocs()
+				for b.Loop() {
+					walked, err := walk(ctx, repo, n)
+					if err != nil {
+						b.Fatal(err)
+					}
+					b.ReportMetric(float64(walked), "commits/op")
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkRepository_Ancestry(b *testing.B) {
+	benchWalk(b, nil, func(ctx context.Context, repo *Repository, depth int) (int, error) {
+		commits, err := repo.Ancestry(ctx, depth)
+		return len(commits), err
+	})
+}
+
+func BenchmarkRepository_AncestryAllParents(b *testing.B) {
+	benchWalk(b, []Option{WithAllParents()}, func(ctx context.Context, repo *Repository, depth int) (int, error) {
+		commits, err := repo.Ancestry(ctx, depth)
+		return len(commits), err
+	})
+}
+
+func BenchmarkRepository_AncestryPaths(b *testing.B) {
+	benchWalk(b, []Option{WithPaths("side.txt")}, func(ctx context.Context, repo *Repository, depth int) (int, error) {
+		commits, err := repo.Ancestry(ctx, depth)
+		return len(commits), err
+	})
+}
+
+func BenchmarkRepository_WalkAncestry(b *testing.B) {
+	benchWalk(b, nil, func(ctx context.Context, repo *Repository, depth int) (int, error) {
+		walked := 0
+		err := repo.WalkAncestry(ctx, depth, func(string) bool {
+			walked++
+			return true
+		})
+		return walked, err
+	})
+}
@@ -0,0 +1,131 @@
+package gitcontext
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// AncestryCacheFile is where WithAncestryCache keeps the first-parent
+// ancestry of the last HEAD walked, relative to the Git directory.
+const AncestryCacheFile = "slippy/ancestry"
+
+// ancestryCacheHeader starts every ancestry cache file, naming its format.
+const ancestryCacheHeader = "slippy-find ancestry v1"
+
+// The second line of an ancestry cache file: whether its commits run to a
+// root commit, or stop short of one.
+const (
+	ancestryComplete = "complete"
+	ancestryPartial  = "partial"
+)
+
+// ancestryCache is the first-parent ancestry of a HEAD as last walked.
+type ancestryCache struct {
+	// commits are newest (the HEAD walked) first; never empty.
+	commits []plumbing.Hash
+
+	// complete reports whether the last commit is a root commit.
+	complete bool
+}
+
+// tip returns the HEAD the cache was walked from, or the zero hash for a nil
+// cache.
+func (c *ancestryCache) tip() plumbing.Hash {
+	if c == nil {
+		return plumbing.ZeroHash
+	}
+	return c.commits[0]
+}
+
+// ancestryCacheFS returns the Git directory to keep the ancestry cache of a
+// walk from start in, or nil if WithAncestryCache is not set, start is not
+// HEAD, or the repository is not stored in a filesystem.
+func (r *Repository) ancestryCacheFS(start plumbing.Hash) billy.Filesystem {
+	if !r.ancestryCache {
+		return nil
+	}
+	st, ok := r.repo.Storer.(interface{ Filesystem() billy.Filesystem })
+	if !ok {
+		return nil
+	}
+	head, err := r.repo.Head()
+	if err != nil || head.Hash() != start {
+		return nil
+	}
+	return st.Filesystem()
+}
+
+// readAncestryCache reads the ancestry cache from fs. Returns nil if there is
+// none or it cannot be read, so the walk reads history instead.
+func readAncestryCache(fs billy.Filesystem) *ancestryCache {
+	f, err := fs.Open(AncestryCacheFile)
+	if err != nil {
+		return nil
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() || scanner.Text() != ancestryCacheHeader || !scanner.Scan() {
+		return nil
+	}
+	c := &ancestryCache{}
+	switch scanner.Text() {
+	case ancestryComplete:
+		c.complete = true
+	case ancestryPartial:
+	default:
+		return nil
+	}
+	for scanner.Scan() {
+		if !plumbing.IsHash(scanner.Text()) {
+			return nil
+		}
+		c.commits = append(c.commits, plumbing.NewHash(scanner.Text()))
+	}
+	if scanner.Err() != nil || len(c.commits) == 0 {
+		return nil
+	}
+	return c
+}
+
+// writeAncestryCache replaces the ancestry cache in fs with c. The file is
+// written aside and renamed into place, so concurrent readers see either
+// cache whole.
+func writeAncestryCache(fs billy.Filesystem, c *ancestryCache) (err error) {
+	dir := path.Dir(AncestryCacheFile)
+	if err := fs.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	f, err := fs.TempFile(dir, "ancestry-")
+	if err != nil {
+		return fmt.Errorf("failed to create ancestry cache: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = fs.Remove(f.Name())
+		}
+	}()
+
+	state := ancestryPartial
+	if c.complete {
+		state = ancestryComplete
+	}
+	w := bufio.NewWriter(f)
+	_, _ = fmt.Fprintf(w, "%s\n%s\n", ancestryCacheHeader, state)
+	for _, commit := range c.commits {
+		_, _ = w.WriteString(commit.String())
+		_ = w.WriteByte('\n')
+	}
+	if err := errors.Join(w.Flush(), f.Close()); err != nil {
+		return fmt.Errorf("failed to write ancestry cache: %w", err)
+	}
+	if err := fs.Rename(f.Name(), AncestryCacheFile); err != nil {
+		return fmt.Errorf("failed to write ancestry cache: %w", err)
+	}
+	return nil
+}
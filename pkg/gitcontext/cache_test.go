@@ -0,0 +1,192 @@
+package gitcontext
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runGit runs git in dir and returns its trimmed output.
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git %v: %s", args, out)
+	return strings.TrimSpace(string(out))
+}
+
+// readCacheFile returns the lines of the ancestry cache of the repository at dir.
+func readCacheFile(t *testing.T, dir string) []string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, ".git", AncestryCacheFile))
+	require.NoError(t, err)
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+// writeCacheFile replaces the ancestry cache of the repository at dir.
+func writeCacheFile(t *testing.T, dir string, lines ...string) {
+	t.Helper()
+	file := filepath.Join(dir, ".git", AncestryCacheFile)
+	require.NoError(t, os.MkdirAll(filepath.Dir(file), 0o755))
+	require.NoError(t, os.WriteFile(file, []byte(strings.Join(lines, "\n")+"\n"), 0o644))
+}
+
+func TestWithAncestryCache_WritesAndReuses(t *testing.T) {
+	dir, commits := mergedRepo(t)
+	repo, err := Open(dir, WithAncestryCache())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	got, err := repo.Ancestry(ctx, 0)
+
+	require.NoError(t, err)
+	want := []string{commits["merge"], commits["feature"], commits["base"]}
+	assert.Equal(t, want, got)
+	assert.Equal(t, append([]string{ancestryCacheHeader, ancestryComplete}, want...), readCacheFile(t, dir))
+
+	// A cache for the same HEAD is served without reading history
+	fake := strings.Repeat("a", 40)
+	writeCacheFile(t, dir, ancestryCacheHeader, ancestryComplete, commits["merge"], fake)
+	got, err = repo.Ancestry(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{commits["merge"], fake}, got)
+}
+
+func TestWithAncestryCache_HeadAdvances(t *testing.T) {
+	dir, commits := mergedRepo(t)
+	repo, err := Open(dir, WithAncestryCache())
+	require.NoError(t, err)
+	ctx := context.Background()
+	_, err = repo.Ancestry(ctx, 0)
+	require.NoError(t, err)
+
+	// Only the commits since the cached HEAD are read; the rest come from the cache
+	fake := strings.Repeat("b", 40)
+	writeCacheFile(t, dir, ancestryCacheHeader, ancestryComplete, commits["merge"], fake)
+	runGit(t, dir, "commit", "--allow-empty", "-m", "next")
+	next := runGit(t, dir, "rev-parse", "HEAD")
+	repo, err = Open(dir, WithAncestryCache())
+	require.NoError(t, err)
+	got, err := repo.Ancestry(ctx, 0)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{next, commits["merge"], fake}, got)
+	assert.Equal(t, []string{ancestryCacheHeader, ancestryComplete, next, commits["merge"], fake}, readCacheFile(t, dir))
+}
+
+func TestWithAncestryCache_ExtendsPartial(t *testing.T) {
+	dir, commits := mergedRepo(t)
+	repo, err := Open(dir, WithAncestryCache())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	got, err := repo.Ancestry(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{commits["merge"]}, got)
+	assert.Equal(t, []string{ancestryCacheHeader, ancestryPartial, commits["merge"]}, readCacheFile(t, dir))
+
+	got, err = repo.Ancestry(ctx, 0)
+
+	require.NoError(t, err)
+	want := []string{commits["merge"], commits["feature"], commits["base"]}
+	assert.Equal(t, want, got)
+	assert.Equal(t, append([]string{ancestryCacheHeader, ancestryComplete}, want...), readCacheFile(t, dir))
+}
+
+func TestWithAncestryCache_StreamStopsEarly(t *testing.T) {
+	dir, commits := mergedRepo(t)
+	repo, err := Open(dir, WithAncestryCache())
+	require.NoError(t, err)
+	_, err = repo.Ancestry(context.Background(), 0)
+	require.NoError(t, err)
+
+	var got []string
+	err = repo.WalkAncestry(context.Background(), 0, func(sha string) bool {
+		got = append(got, sha)
+		return false
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{commits["merge"]}, got)
+	assert.Len(t, readCacheFile(t, dir), 5, "stopping early keeps the cache whole")
+}
+
+func TestWithAncestryCache_Replaced(t *testing.T) {
+	dir, commits := mergedRepo(t)
+	repo, err := Open(dir, WithAncestryCache())
+	require.NoError(t, err)
+	ctx := context.Background()
+	_, err = repo.Ancestry(ctx, 0)
+	require.NoError(t, err)
+
+	runGit(t, dir, "checkout", "side")
+	repo, err = Open(dir, WithAncestryCache())
+	require.NoError(t, err)
+	got, err := repo.Ancestry(ctx, 0)
+
+	require.NoError(t, err)
+	want := []string{commits["side"], commits["base"]}
+	assert.Equal(t, want, got, "a HEAD not descending from the cached one walks history")
+	assert.Equal(t, append([]string{ancestryCacheHeader, ancestryComplete}, want...), readCacheFile(t, dir))
+}
+
+func TestWithAncestryCache_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+	}{
+		{name: "unknown format", lines: []string{"slippy-find ancestry v0", ancestryComplete, strings.Repeat("a", 40)}},
+		{name: "unknown state", lines: []string{ancestryCacheHeader, "done", strings.Repeat("a", 40)}},
+		{name: "no commits", lines: []string{ancestryCacheHeader, ancestryComplete}},
+		{name: "malformed commit", lines: []string{ancestryCacheHeader, ancestryComplete, "HEAD"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, commits := mergedRepo(t)
+			writeCacheFile(t, dir, tt.lines...)
+			repo, err := Open(dir, WithAncestryCache())
+			require.NoError(t, err)
+
+			got, err := repo.Ancestry(context.Background(), 0)
+
+			require.NoError(t, err)
+			assert.Equal(t, []string{commits["merge"], commits["feature"], commits["base"]}, got)
+			assert.Equal(t, ancestryCacheHeader, readCacheFile(t, dir)[0], "an invalid cache is rewritten")
+		})
+	}
+}
+
+func TestWithAncestryCache_NotUsed(t *testing.T) {
+	dir, commits := mergedRepo(t)
+	repo, err := Open(dir, WithAncestryCache())
+	require.NoError(t, err)
+	ctx := context.Background()
+	cacheFile := filepath.Join(dir, ".git", AncestryCacheFile)
+
+	_, err = repo.AncestryFrom(ctx, commits["feature"], 0)
+	require.NoError(t, err)
+	_, err = repo.With(WithPaths("main.txt")).Ancestry(ctx, 0)
+	require.NoError(t, err)
+	_, err = repo.With(WithAllParents()).Ancestry(ctx, 0)
+	require.NoError(t, err)
+	uncached, err := Open(dir)
+	require.NoError(t, err)
+	_, err = uncached.Ancestry(ctx, 0)
+	require.NoError(t, err)
+
+	assert.NoFileExists(t, cacheFile, "only first-parent walks from HEAD without paths are cached")
+
+	fsRepo, err := OpenFS(osfs.New(filepath.Join(dir, ".git")), WithAncestryCache())
+	require.NoError(t, err)
+	_, err = fsRepo.Ancestry(ctx, 0)
+	require.NoError(t, err)
+	assert.FileExists(t, cacheFile, "OpenFS repositories are cached in their filesystem")
+}
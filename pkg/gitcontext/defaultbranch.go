@@ -0,0 +1,36 @@
+package gitcontext
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// defaultBranchGuesses are the branches DefaultBranch tries, in order, when
+// the remote's HEAD is unknown.
+var defaultBranchGuesses = []string{"main", "master"}
+
+// DefaultBranch returns the remote's default branch: the branch its HEAD
+// (refs/remotes/origin/HEAD for origin, set by git clone and git remote
+// set-head) points at. Checkouts that fetch single branches, as CI systems
+// make, lack that ref; then the first of main and master that exists, as a
+// remote-tracking or a local branch, is assumed. Returns ErrBranchNotFound if
+// neither does.
+func (r *Repository) DefaultBranch() (string, error) {
+	head, err := r.repo.Reference(plumbing.NewRemoteHEADReferenceName(r.remote), false)
+	if err == nil && head.Type() == plumbing.SymbolicReference {
+		prefix := "refs/remotes/" + r.remote + "/"
+		if branch, ok := strings.CutPrefix(head.Target().String(), prefix); ok && branch != "" {
+			return branch, nil
+		}
+	}
+
+	for _, branch := range defaultBranchGuesses {
+		if _, err := r.resolveBranch(branch); err == nil {
+			return branch, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s/HEAD is not set and there is no %s branch",
+		ErrBranchNotFound, r.remote, strings.Join(defaultBranchGuesses, " or "))
+}
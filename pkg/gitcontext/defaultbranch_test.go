@@ -0,0 +1,74 @@
+package gitcontext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_DefaultBranch(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(t *testing.T, dir string, commits map[string]string)
+		opts    []Option
+		want    string
+		wantErr error
+	}{
+		{
+			name: "origin HEAD",
+			setup: func(t *testing.T, dir string, commits map[string]string) {
+				runGit(t, dir, "update-ref", "refs/remotes/origin/develop", commits["side"])
+				runGit(t, dir, "symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/develop")
+			},
+			want: "develop",
+		},
+		{
+			name: "other remote's HEAD",
+			setup: func(t *testing.T, dir string, commits map[string]string) {
+				runGit(t, dir, "update-ref", "refs/remotes/upstream/trunk", commits["side"])
+				runGit(t, dir, "symbolic-ref", "refs/remotes/upstream/HEAD", "refs/remotes/upstream/trunk")
+			},
+			opts: []Option{WithRemote("upstream")},
+			want: "trunk",
+		},
+		{
+			name: "main without origin HEAD",
+			want: "main",
+		},
+		{
+			name: "remote-tracking master",
+			setup: func(t *testing.T, dir string, commits map[string]string) {
+				runGit(t, dir, "branch", "-m", "main", "work")
+				runGit(t, dir, "update-ref", "refs/remotes/origin/master", commits["base"])
+			},
+			want: "master",
+		},
+		{
+			name: "neither main nor master",
+			setup: func(t *testing.T, dir string, _ map[string]string) {
+				runGit(t, dir, "branch", "-m", "main", "work")
+			},
+			wantErr: ErrBranchNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, commits := mergedRepo(t)
+			if tt.setup != nil {
+				tt.setup(t, dir, commits)
+			}
+			repo, err := Open(dir, tt.opts...)
+			require.NoError(t, err)
+
+			got, err := repo.DefaultBranch()
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package gitcontext
+
+import (
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// Shallow reports whether the repository is a shallow clone, whose history
+// stops at the commits it was cut at.
+func (r *Repository) Shallow() bool {
+	commits, err := r.repo.Storer.Shallow()
+	return err == nil && len(commits) > 0
+}
+
+// PartialClone reports whether the repository is a partial clone, as made by
+// git clone --filter: objects left out are fetched by git on demand, but
+// cannot be read by a Repository, so walks needing them fail.
+func (r *Repository) PartialClone() bool {
+	cfg, err := r.repo.Config()
+	if err != nil {
+		return false
+	}
+	if cfg.Raw.Section("extensions").Options.Get("partialclone") != "" {
+		return true
+	}
+	return cfg.Raw.Section("remote").Subsection(r.remote).Options.Get("promisor") == "true"
+}
+
+// AncestryCacheState returns the state of the WithAncestryCache cache for
+// HEAD, one of the domain.AncestryCache constants. A repository not stored in
+// a filesystem has no cache and reports domain.AncestryCacheDisabled.
+func (r *Repository) AncestryCacheState() string {
+	head, err := r.repo.Head()
+	if err != nil {
+		return domain.AncestryCacheCold
+	}
+	fs := r.ancestryCacheFS(head.Hash())
+	if fs == nil {
+		return domain.AncestryCacheDisabled
+	}
+	switch cache := readAncestryCache(fs); {
+	case cache == nil:
+		return domain.AncestryCacheCold
+	case cache.tip() == head.Hash():
+		return domain.AncestryCacheWarm
+	default:
+		return domain.AncestryCacheStale
+	}
+}
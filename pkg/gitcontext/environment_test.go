@@ -0,0 +1,79 @@
+package gitcontext
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+func TestRepository_Shallow(t *testing.T) {
+	dir, _ := mergedRepo(t)
+	clone := filepath.Join(t.TempDir(), "clone")
+	runGit(t, dir, "clone", "--quiet", "--depth", "1", "file://"+dir, clone)
+
+	full, err := Open(dir)
+	require.NoError(t, err)
+	shallow, err := Open(clone)
+	require.NoError(t, err)
+
+	assert.False(t, full.Shallow())
+	assert.True(t, shallow.Shallow())
+}
+
+func TestRepository_PartialClone(t *testing.T) {
+	tests := []struct {
+		name   string
+		config [][]string
+		opts   []Option
+		want   bool
+	}{
+		{name: "full clone"},
+		{name: "partial clone extension", config: [][]string{{"extensions.partialClone", "origin"}}, want: true},
+		{name: "promisor remote", config: [][]string{{"remote.origin.promisor", "true"}}, want: true},
+		{
+			name:   "promisor on another remote",
+			config: [][]string{{"remote.origin.promisor", "true"}},
+			opts:   []Option{WithRemote("upstream")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, _ := mergedRepo(t)
+			for _, kv := range tt.config {
+				runGit(t, dir, "config", kv[0], kv[1])
+			}
+			repo, err := Open(dir, tt.opts...)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, repo.PartialClone())
+		})
+	}
+}
+
+func TestRepository_AncestryCacheState(t *testing.T) {
+	dir, _ := mergedRepo(t)
+	ctx := context.Background()
+
+	plain, err := Open(dir)
+	require.NoError(t, err)
+	assert.Equal(t, domain.AncestryCacheDisabled, plain.AncestryCacheState())
+
+	repo, err := Open(dir, WithAncestryCache())
+	require.NoError(t, err)
+	assert.Equal(t, domain.AncestryCacheCold, repo.AncestryCacheState())
+
+	_, err = repo.Ancestry(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, domain.AncestryCacheWarm, repo.AncestryCacheState())
+
+	runGit(t, dir, "commit", "--allow-empty", "-m", "next")
+	assert.Equal(t, domain.AncestryCacheStale, repo.AncestryCacheState())
+
+	writeCacheFile(t, dir, "not a cache")
+	assert.Equal(t, domain.AncestryCacheCold, repo.AncestryCacheState())
+}
@@ -0,0 +1,893 @@
+// Package gitcontext reads what a CI tool needs to know about a local Git
+// checkout: the HEAD commit, the branch, the owner/repo name of a remote, and
+// the commit ancestry. It is the git layer of slippy-find, published so other
+// tools get the same answers without re-implementing remote URL parsing or
+// history walks.
+//
+// The exported API of this package follows the module's semantic version:
+// it only changes incompatibly in a new major version.
+//
+//	repo, err := gitcontext.Open(".", gitcontext.WithRemote("upstream"))
+//	if err != nil {
+//		return err
+//	}
+//	gc, err := repo.Context(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Println(gc.Repository, gc.Branch, gc.HeadSHA)
+//
+//	// The last 10 commits that changed services/payments, following merges
+//	commits, err := repo.With(gitcontext.WithPaths("services/payments"), gitcontext.WithAllParents()).
+//		Ancestry(ctx, 10)
+package gitcontext
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// DefaultRemote is the remote the repository name is read from and branches
+// are looked up on, unless WithRemote names another.
+const DefaultRemote = "origin"
+
+// DefaultDepth is the number of commits a walk returns when asked for zero or fewer.
+const DefaultDepth = domain.DefaultAncestryDepth
+
+// Errors returned by Repository, for use with errors.Is. They are the
+// slippy-find domain errors, so the CLI reports them with its error codes.
+var (
+	// ErrRepositoryNotFound indicates the path is not a Git repository.
+	ErrRepositoryNotFound = domain.ErrRepositoryNotFound
+
+	// ErrNoRemote indicates the remote is missing or has no URL.
+	ErrNoRemote = domain.ErrNoRemoteOrigin
+
+	// ErrInvalidRemoteURL indicates no owner/repo could be parsed from the remote URL.
+	ErrInvalidRemoteURL = domain.ErrInvalidRemoteURL
+
+	// ErrEmptyAncestry indicates a walk found no commits, such as when none
+	// touches the paths.
+	ErrEmptyAncestry = domain.ErrEmptyAncestry
+
+	// ErrBranchNotFound indicates a branch exists neither on the remote nor locally.
+	ErrBranchNotFound = domain.ErrBranchNotFound
+
+	// ErrCommitNotFound indicates a commit SHA is not present locally, as in a
+	// shallow checkout.
+	ErrCommitNotFound = domain.ErrCommitNotFound
+
+	// ErrNoMergeBase indicates HEAD and a branch share no history.
+	ErrNoMergeBase = domain.ErrNoMergeBase
+
+	// ErrTooManyCommits indicates a walk would return more commits than the
+	// WithMaxCommits limit.
+	ErrTooManyCommits = domain.ErrAncestryTooLarge
+)
+
+// Context is the git context of a checkout.
+type Context = domain.GitContext
+
+// Repository reads the context and history of a local Git repository. A
+// Repository, and the copies With makes of it, must not be used from several
+// goroutines at once.
+type Repository struct {
+	repo       *git.Repository
+	path       string
+	format     ObjectFormat
+	remote     string
+	allParents bool
+	paths      []string
+
+	// ancestryCache enables the ancestry cache of WithAncestryCache.
+	ancestryCache bool
+
+	// maxCommits is the WithMaxCommits limit; zero means none.
+	maxCommits int
+}
+
+// Option configures a Repository.
+type Option func(*Repository)
+
+// WithRemote reads the repository name from, and looks branches up on, the
+// named remote instead of DefaultRemote.
+func WithRemote(name string) Option {
+	return func(r *Repository) {
+		r.remote = name
+	}
+}
+
+// WithAllParents makes walks follow every parent of merge commits, newest
+// commit first, as git log does. By default walks follow only first parents,
+// so commits merged in from other branches are not part of a branch's
+// ancestry.
+func WithAllParents() Option {
+	return func(r *Repository) {
+		r.allParents = true
+	}
+}
+
+// WithPaths makes walks return only commits that add, modify, or remove a
+// file under one of paths, compared with their first parent. Paths are
+// slash-separated and relative to the repository root; "." matches every
+// file. A walk continues past other commits until it has enough or history
+// runs out.
+func WithPaths(paths ...string) Option {
+	return func(r *Repository) {
+		r.paths = slices.Clone(paths)
+	}
+}
+
+// WithAncestryCache makes first-parent walks from HEAD without paths keep
+// the ancestry they read in the Git directory, at AncestryCacheFile, and
+// reuse it: a later walk from the same HEAD reads no commits, and one from a
+// descendant of it reads only the commits since. The cache holds the
+// ancestry of one HEAD, the last walked. It is best effort: a repository it
+// cannot be written to is walked as without it. Repositories not stored in a
+// filesystem, such as in memory, are never cached.
+func WithAncestryCache() Option {
+	return func(r *Repository) {
+		r.ancestryCache = true
+	}
+}
+
+// WithMaxCommits limits a walk to n commits: one asked for more that history
+// can supply fails with ErrTooManyCommits instead of holding them all, so a
+// mistaken depth cannot exhaust memory. n of zero or less means no limit,
+// the default.
+func WithMaxCommits(n int) Option {
+	return func(r *Repository) {
+		r.maxCommits = max(n, 0)
+	}
+}
+
+// Open opens the repository at path, a working directory or a bare
+// repository. Returns ErrRepositoryNotFound if path is not a Git repository
+// and ErrUnsupportedObjectFormat if its object format is not BuildObjectFormat.
+// The repository keeps its packfiles open between reads; Close releases them.
+func Open(path string, opts ...Option) (*Repository, error) {
+	repo, err := openPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrRepositoryNotFound, path)
+	}
+	format, err := objectFormatOf(repo)
+	if err != nil {
+		if closer, ok := repo.Storer.(io.Closer); ok {
+			_ = closer.Close()
+		}
+		return nil, err
+	}
+	r := &Repository{repo: repo, path: path, format: format, remote: DefaultRemote}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r, nil
+}
+
+// OpenFS opens the repository whose Git directory (.git, or a bare
+// repository) is the root of fs. It serves environments without an OS
+// filesystem, such as WebAssembly, where fs is typically an in-memory
+// memfs.Filesystem the caller filled. Path returns "" for such a repository.
+// Returns ErrRepositoryNotFound if fs holds no Git repository and
+// ErrUnsupportedObjectFormat if its object format is not BuildObjectFormat.
+func OpenFS(fs billy.Filesystem, opts ...Option) (*Repository, error) {
+	repo, err := git.Open(filesystem.NewStorage(fs, cache.NewObjectLRUDefault()), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRepositoryNotFound, err)
+	}
+	format, err := objectFormatOf(repo)
+	if err != nil {
+		return nil, err
+	}
+	r := &Repository{repo: repo, format: format, remote: DefaultRemote}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r, nil
+}
+
+// openPath opens the repository at path for reading. A .git directory or a
+// bare repository is opened with storage that keeps packfile descriptors open
+// and trusts nothing else writes to it while open, so a walk does not reopen
+// the pack and re-read its index for every commit. A .git file, as in linked
+// worktrees and submodules, or a Git directory sharing another's objects
+// through commondir, is left to plainOpen, which resolves both, as is a
+// path the fast open fails on, such as one starting with ~.
+func openPath(path string) (*git.Repository, error) {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	worktree := osfs.New(dir)
+	dot := worktree
+	if fi, err := worktree.Stat(git.GitDirName); err == nil && fi.IsDir() {
+		dot = osfs.New(filepath.Join(dir, git.GitDirName))
+	} else if err == nil || !errors.Is(err, fs.ErrNotExist) {
+		return plainOpen(path)
+	} else {
+		worktree = nil
+	}
+	if _, err := dot.Stat("commondir"); !errors.Is(err, fs.ErrNotExist) {
+		return plainOpen(path)
+	}
+
+	st := filesystem.NewStorageWithOptions(dot, cache.NewObjectLRUDefault(), filesystem.Options{
+		ExclusiveAccess: true,
+		KeepDescriptors: true,
+	})
+	repo, err := git.Open(st, worktree)
+	if err != nil {
+		_ = st.Close()
+		return plainOpen(path)
+	}
+	return repo, nil
+}
+
+// plainOpen opens the repository at path with git.PlainOpen, following a
+// commondir file to the objects a linked worktree shares.
+func plainOpen(path string) (*git.Repository, error) {
+	return git.PlainOpenWithOptions(path, &git.PlainOpenOptions{EnableDotGitCommonDir: true})
+}
+
+// Close releases the files the repository keeps open, for it and every copy
+// made by With. Reads after Close reopen them.
+func (r *Repository) Close() error {
+	if closer, ok := r.repo.Storer.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// With returns a copy of the repository with opts applied on top of its
+// own. Both share the opened repository.
+func (r *Repository) With(opts ...Option) *Repository {
+	c := *r
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return &c
+}
+
+// Path returns the path the repository was opened at, empty for OpenFS.
+func (r *Repository) Path() string {
+	return r.path
+}
+
+// Context returns the HEAD commit, the branch (empty when HEAD is
+// detached), the owner/repo name from the remote's first URL, and the
+// DefaultBranch (empty when it cannot be found).
+// Returns ErrNoRemote if the remote is missing and ErrInvalidRemoteURL if
+// its URL names no repository.
+func (r *Repository) Context(_ context.Context) (*Context, error) {
+	head, err := r.repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	gitCtx := &Context{
+		HeadSHA:      head.Hash().String(),
+		ObjectFormat: r.format,
+		IsDetached:   !head.Name().IsBranch(),
+	}
+	if head.Name().IsBranch() {
+		gitCtx.Branch = head.Name().Short()
+	}
+
+	remote, err := r.repo.Remote(r.remote)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to get %s remote: %w", ErrNoRemote, r.remote, err)
+	}
+	urls := remote.Config().URLs
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("%w: %s remote has no URLs configured", ErrNoRemote, r.remote)
+	}
+	gitCtx.Repository, err = ParseRemoteURL(urls[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to parse URL: %w", ErrInvalidRemoteURL, err)
+	}
+	gitCtx.DefaultBranch, _ = r.DefaultBranch()
+	return gitCtx, nil
+}
+
+// Ancestry walks history from HEAD, returning up to depth commit SHAs,
+// newest (HEAD) first.
+func (r *Repository) Ancestry(ctx context.Context, depth int) ([]string, error) {
+	head, err := r.headCommit()
+	if err != nil {
+		return nil, err
+	}
+	return r.walk(ctx, head, depth)
+}
+
+// WalkAncestry walks history from HEAD like Ancestry, but calls yield with
+// each commit SHA as it is reached instead of collecting them, so a caller can
+// act on the newest commits while older ones are still being read. The walk
+// stops early when yield returns false. Returns ErrEmptyAncestry if no commit
+// was reached.
+func (r *Repository) WalkAncestry(ctx context.Context, depth int, yield func(sha string) bool) error {
+	head, err := r.headCommit()
+	if err != nil {
+		return err
+	}
+	return r.walkEach(ctx, head, depth, func(h plumbing.Hash) bool { return yield(h.String()) })
+}
+
+// AncestryFrom walks history from rev, returning up to depth commit SHAs.
+// rev is a full or abbreviated commit SHA or a branch name, as accepted by
+// ResolveCommit. Returns ErrCommitNotFound if a SHA is not present locally,
+// ErrAmbiguousCommit if an abbreviated SHA matches several commits, and
+// ErrBranchNotFound for an unknown branch.
+func (r *Repository) AncestryFrom(ctx context.Context, rev string, depth int) ([]string, error) {
+	start, err := r.resolveRevision(rev)
+	if err != nil {
+		return nil, err
+	}
+	return r.walk(ctx, start, depth)
+}
+
+// MergeBaseAncestry finds the merge base of HEAD and branch, then walks
+// history from it, returning up to depth commit SHAs. The branch is looked up
+// as the remote's tracking branch first, then as a local branch. Returns
+// ErrBranchNotFound if neither exists and ErrNoMergeBase if the histories are
+// unrelated.
+func (r *Repository) MergeBaseAncestry(ctx context.Context, branch string, depth int) ([]string, error) {
+	head, err := r.headCommit()
+	if err != nil {
+		return nil, err
+	}
+	branchRef, err := r.resolveBranch(branch)
+	if err != nil {
+		return nil, err
+	}
+	branchCommit, err := r.repo.CommitObject(branchRef.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit object for %s: %w", branchRef.Name(), err)
+	}
+
+	bases, err := head.MergeBase(branchCommit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute merge base with %s: %w", branchRef.Name(), err)
+	}
+	if len(bases) == 0 {
+		return nil, fmt.Errorf("%w: HEAD and %s", ErrNoMergeBase, branchRef.Name())
+	}
+	return r.walk(ctx, bases[0], depth)
+}
+
+// PathAncestries is Ancestry filtered by several path sets at once, keyed
+// by name, in place of any WithPaths option. It walks history a single time,
+// diffing each commit once, until every key has depth commits or history
+// runs out. Keys whose paths no commit touches are absent from the result.
+func (r *Repository) PathAncestries(ctx context.Context, paths map[string][]string, depth int) (map[string][]string, error) {
+	if depth <= 0 {
+		depth = DefaultDepth
+	}
+	head, err := r.headCommit()
+	if err != nil {
+		return nil, err
+	}
+
+	ancestries := make(map[string][]string, len(paths))
+	pending := len(paths)
+	next := r.commits(head)
+	for pending > 0 {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		c, err := next()
+		if err != nil {
+			return nil, err
+		}
+		if c == nil {
+			break
+		}
+		changes, err := firstParentChanges(c)
+		if err != nil {
+			return nil, fmt.Errorf("failed to diff commit %s: %w", c.Hash, err)
+		}
+		for name, prefixes := range paths {
+			if len(ancestries[name]) < depth && changesTouch(changes, prefixes) {
+				ancestries[name] = append(ancestries[name], c.Hash.String())
+				if len(ancestries[name]) == depth {
+					pending--
+				}
+			}
+		}
+	}
+	return ancestries, nil
+}
+
+// headCommit returns the commit HEAD points at.
+func (r *Repository) headCommit() (*object.Commit, error) {
+	head, err := r.repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	commit, err := r.repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit object for HEAD: %w", err)
+	}
+	return commit, nil
+}
+
+// resolveBranch looks up a branch as a remote-tracking ref, then as a local branch.
+func (r *Repository) resolveBranch(branch string) (*plumbing.Reference, error) {
+	candidates := []plumbing.ReferenceName{
+		plumbing.NewRemoteReferenceName(r.remote, branch),
+		plumbing.NewBranchReferenceName(branch),
+	}
+	for _, name := range candidates {
+		ref, err := r.repo.Reference(name, true)
+		if err == nil {
+			return ref, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrBranchNotFound, branch)
+}
+
+// resolveRevision returns the commit named by a full or abbreviated SHA or a
+// branch name.
+func (r *Repository) resolveRevision(rev string) (*object.Commit, error) {
+	if err := r.checkRevisionFormat(rev); err != nil {
+		return nil, err
+	}
+	if plumbing.IsHash(rev) {
+		commit, err := r.repo.CommitObject(plumbing.NewHash(rev))
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrCommitNotFound, rev)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get commit object for %s: %w", rev, err)
+		}
+		return commit, nil
+	}
+	if isAbbreviatedSHA(rev) {
+		commit, err := r.expandAbbreviated(rev)
+		if !errors.Is(err, ErrCommitNotFound) {
+			return commit, err
+		}
+		// No commit has the prefix, so it may be a branch name such as "cafe"
+		ref, branchErr := r.resolveBranch(rev)
+		if branchErr != nil {
+			return nil, err
+		}
+		return r.commitOf(ref)
+	}
+
+	ref, err := r.resolveBranch(rev)
+	if err != nil {
+		return nil, err
+	}
+	return r.commitOf(ref)
+}
+
+// commitOf returns the commit ref points to.
+func (r *Repository) commitOf(ref *plumbing.Reference) (*object.Commit, error) {
+	commit, err := r.repo.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit object for %s: %w", ref.Name(), err)
+	}
+	return commit, nil
+}
+
+// walk returns up to depth commits of the history from start that match the
+// path filter, newest first. Returns ErrEmptyAncestry if none does.
+func (r *Repository) walk(ctx context.Context, start *object.Commit, depth int) ([]string, error) {
+	commits := make([]plumbing.Hash, 0, min(max(depth, DefaultDepth), walkPrealloc))
+	err := r.walkEach(ctx, start, depth, func(h plumbing.Hash) bool {
+		commits = append(commits, h)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return shaStrings(commits), nil
+}
+
+// walkPrealloc caps the commits walk allocates room for up front, so a walk
+// deeper than history does not reserve memory for commits it never finds.
+const walkPrealloc = 4096
+
+// shaStrings returns the hex SHAs of hashes. They share one backing string
+// rather than taking an allocation per commit.
+func shaStrings(hashes []plumbing.Hash) []string {
+	size := hex.EncodedLen(len(plumbing.Hash{}))
+	buf := make([]byte, size*len(hashes))
+	for i, h := range hashes {
+		hex.Encode(buf[i*size:], h[:])
+	}
+	all := string(buf)
+	shas := make([]string, len(hashes))
+	for i := range shas {
+		shas[i] = all[i*size : (i+1)*size]
+	}
+	return shas
+}
+
+// walkEach calls yield with up to depth commits of the history from start
+// that match the path filter, newest first, until yield returns false.
+// Returns ErrEmptyAncestry if none does, and ErrTooManyCommits if depth is
+// over the WithMaxCommits limit and history holds more commits than it.
+func (r *Repository) walkEach(
+	ctx context.Context,
+	start *object.Commit,
+	depth int,
+	yield func(plumbing.Hash) bool,
+) error {
+	if depth <= 0 {
+		depth = DefaultDepth
+	}
+	if r.maxCommits <= 0 || depth <= r.maxCommits {
+		return r.walkCommits(ctx, start, depth, yield)
+	}
+
+	yielded, exceeded := 0, false
+	err := r.walkCommits(ctx, start, depth, func(h plumbing.Hash) bool {
+		if yielded == r.maxCommits {
+			exceeded = true
+			return false
+		}
+		yielded++
+		return yield(h)
+	})
+	if exceeded {
+		return fmt.Errorf("%w: a walk of %d commits reached the limit of %d", ErrTooManyCommits, depth, r.maxCommits)
+	}
+	return err
+}
+
+// walkCommits is walkEach without the commit limit.
+func (r *Repository) walkCommits(
+	ctx context.Context,
+	start *object.Commit,
+	depth int,
+	yield func(plumbing.Hash) bool,
+) error {
+	if !r.allParents && len(r.paths) == 0 {
+		return r.walkFirstParents(ctx, start.Hash, depth, yield)
+	}
+
+	walked := 0
+	next := r.commits(start)
+	for walked < depth {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		c, err := next()
+		if err != nil {
+			return err
+		}
+		if c == nil {
+			break
+		}
+		if len(r.paths) > 0 {
+			changes, err := firstParentChanges(c)
+			if err != nil {
+				return fmt.Errorf("failed to diff commit %s: %w", c.Hash, err)
+			}
+			if !changesTouch(changes, r.paths) {
+				continue
+			}
+		}
+		walked++
+		if !yield(c.Hash) {
+			break
+		}
+	}
+
+	if walked == 0 {
+		return ErrEmptyAncestry
+	}
+	return nil
+}
+
+// walkFirstParents calls yield with up to depth commits of the first-parent
+// history from start, newest first, until yield returns false. Without a path
+// filter a walk needs only each commit's parent, so it reads commit headers
+// instead of decoding whole commits with their signatures and messages.
+//
+// With WithAncestryCache, a walk from HEAD reads history only down to the
+// HEAD of the cached ancestry, takes the rest from the cache, and walks past
+// its end only if that is too shallow, then records what it learned.
+func (r *Repository) walkFirstParents(
+	ctx context.Context,
+	start plumbing.Hash,
+	depth int,
+	yieldHash func(plumbing.Hash) bool,
+) error {
+	fs := r.ancestryCacheFS(start)
+	if fs == nil {
+		_, err := r.walkHistory(ctx, start, depth, plumbing.ZeroHash, yieldHash)
+		return err
+	}
+
+	cache := readAncestryCache(fs)
+	var fresh []plumbing.Hash
+	end, err := r.walkHistory(ctx, start, depth, cache.tip(), func(h plumbing.Hash) bool {
+		fresh = append(fresh, h)
+		return yieldHash(h)
+	})
+	if err != nil {
+		return err
+	}
+	if end != historyJoined {
+		// HEAD does not descend from the cached HEAD within depth commits
+		_ = writeAncestryCache(fs, &ancestryCache{commits: fresh, complete: end == historyRoot})
+		return nil
+	}
+
+	remaining := depth - len(fresh)
+	served := 0
+	for _, h := range cache.commits[:min(remaining, len(cache.commits))] {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		served++
+		if !yieldHash(h) {
+			break
+		}
+	}
+	extended, complete := []plumbing.Hash(nil), cache.complete
+	if served == len(cache.commits) && served < remaining && !cache.complete {
+		if extended, complete, err = r.extendAncestry(ctx, cache, remaining-served, yieldHash); err != nil {
+			return err
+		}
+	}
+	if len(fresh) > 0 || len(extended) > 0 || complete != cache.complete {
+		_ = writeAncestryCache(fs, &ancestryCache{
+			commits:  slices.Concat(fresh, cache.commits, extended),
+			complete: complete,
+		})
+	}
+	return nil
+}
+
+// extendAncestry walks up to depth commits of the history past the end of
+// cache, calling yield with each. Returns the commits walked, and whether
+// history ended at a root commit.
+func (r *Repository) extendAncestry(
+	ctx context.Context,
+	cache *ancestryCache,
+	depth int,
+	yield func(plumbing.Hash) bool,
+) ([]plumbing.Hash, bool, error) {
+	last := cache.commits[len(cache.commits)-1]
+	obj, err := r.repo.Storer.EncodedObject(plumbing.CommitObject, last)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to walk history: %w", err)
+	}
+	parent, err := firstParent(obj, newHeaderReader())
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read commit %s: %w", last, err)
+	}
+	if parent.IsZero() {
+		return nil, true, nil
+	}
+
+	var extended []plumbing.Hash
+	end, err := r.walkHistory(ctx, parent, depth, plumbing.ZeroHash, func(h plumbing.Hash) bool {
+		extended = append(extended, h)
+		return yield(h)
+	})
+	return extended, end == historyRoot, err
+}
+
+// historyEnd is why walkHistory stopped.
+type historyEnd int
+
+const (
+	// historyDepth means depth commits were yielded.
+	historyDepth historyEnd = iota
+
+	// historyStopped means yield returned false.
+	historyStopped
+
+	// historyRoot means the last commit yielded is a root commit.
+	historyRoot
+
+	// historyMissing means the next commit is missing, as in a shallow clone.
+	historyMissing
+
+	// historyJoined means the walk reached the commit it was to stop at,
+	// without yielding it.
+	historyJoined
+)
+
+// walkHistory calls yield with up to depth commits of the first-parent
+// history from start, newest first, until yield returns false or the walk
+// reaches stop. Returns why it stopped.
+func (r *Repository) walkHistory(
+	ctx context.Context,
+	start plumbing.Hash,
+	depth int,
+	stop plumbing.Hash,
+	yield func(plumbing.Hash) bool,
+) (historyEnd, error) {
+	current := start
+	header := newHeaderReader()
+	for walked := 0; ; walked++ {
+		if current == stop {
+			return historyJoined, nil
+		}
+		if walked == depth {
+			return historyDepth, nil
+		}
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		default:
+		}
+
+		obj, err := r.repo.Storer.EncodedObject(plumbing.CommitObject, current)
+		// History ends at a parent a shallow clone lacks
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			return historyMissing, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to walk history: %w", err)
+		}
+		if !yield(current) {
+			return historyStopped, nil
+		}
+		parent, err := firstParent(obj, header)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read commit %s: %w", current, err)
+		}
+		if parent.IsZero() {
+			return historyRoot, nil
+		}
+		current = parent
+	}
+}
+
+// newHeaderReader returns a reader for firstParent, sized for the tree and
+// parent lines of a commit rather than bufio's default 4 KiB, which a walk
+// would otherwise allocate for each commit.
+func newHeaderReader() *bufio.Reader {
+	return bufio.NewReaderSize(nil, 128)
+}
+
+// firstParent returns the first parent of the commit obj, or the zero hash
+// at a root commit, reading its header through header.
+func firstParent(obj plumbing.EncodedObject, header *bufio.Reader) (plumbing.Hash, error) {
+	reader, err := obj.Reader()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	defer func() { _ = reader.Close() }()
+	header.Reset(reader)
+	return parseFirstParent(header)
+}
+
+// parseFirstParent reads a commit object's header lines up to the first
+// parent line, and returns its hash, or the zero hash if the commit has no
+// parent. Parent lines follow the tree line and precede all others; a line
+// longer than r's buffer is read in part, enough to tell it is neither.
+func parseFirstParent(r *bufio.Reader) (plumbing.Hash, error) {
+	for {
+		line, err := r.ReadSlice('\n')
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+			return plumbing.ZeroHash, err
+		}
+		if sha, ok := bytes.CutPrefix(line, []byte("parent ")); ok {
+			sha = bytes.TrimSpace(sha)
+			var parent plumbing.Hash
+			if len(sha) != hex.EncodedLen(len(parent)) {
+				return plumbing.ZeroHash, fmt.Errorf("malformed parent %q", sha)
+			}
+			if _, err := hex.Decode(parent[:], sha); err != nil {
+				return plumbing.ZeroHash, fmt.Errorf("malformed parent %q", sha)
+			}
+			return parent, nil
+		}
+		if !bytes.HasPrefix(line, []byte("tree ")) || err != nil {
+			return plumbing.ZeroHash, nil
+		}
+	}
+}
+
+// commits returns a function yielding the history from start, newest first,
+// and nil once it runs out.
+func (r *Repository) commits(start *object.Commit) func() (*object.Commit, error) {
+	if r.allParents {
+		iter := object.NewCommitIterCTime(start, nil, nil)
+		return func() (*object.Commit, error) {
+			c, err := iter.Next()
+			if errors.Is(err, io.EOF) {
+				return nil, nil
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to walk history: %w", err)
+			}
+			return c, nil
+		}
+	}
+
+	// For merge commits, parent 0 is the branch you were on when you ran
+	// git merge, and parent 1+ are the branches merged in
+	current := start
+	return func() (*object.Commit, error) {
+		c := current
+		if c == nil {
+			return nil, nil
+		}
+		current = nil
+		// History ends at a root commit, or at a parent a shallow clone lacks
+		if c.NumParents() > 0 {
+			if parent, err := c.Parent(0); err == nil {
+				current = parent
+			}
+		}
+		return c, nil
+	}
+}
+
+// firstParentChanges diffs c against its first parent, or against an empty
+// tree at a root commit.
+func firstParentChanges(c *object.Commit) (object.Changes, error) {
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	var parentTree *object.Tree
+	if c.NumParents() > 0 {
+		parent, err := c.Parent(0)
+		if err != nil {
+			return nil, err
+		}
+		parentTree, err = parent.Tree()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return object.DiffTree(parentTree, tree)
+}
+
+// changesTouch reports whether any change adds, modifies, or removes a file under paths.
+func changesTouch(changes object.Changes, paths []string) bool {
+	for _, change := range changes {
+		if matchesPathPrefix(change.From.Name, paths) || matchesPathPrefix(change.To.Name, paths) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesPathPrefix reports whether file lies under any of the slash-separated prefixes.
+// The prefix "." matches every file.
+func matchesPathPrefix(file string, prefixes []string) bool {
+	if file == "" {
+		return false
+	}
+	for _, prefix := range prefixes {
+		if prefix == "." || file == prefix || strings.HasPrefix(file, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
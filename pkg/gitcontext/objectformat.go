@@ -0,0 +1,80 @@
+package gitcontext
+
+import (
+	"fmt"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/hash"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// ObjectFormat is the hash algorithm a repository names its objects with.
+type ObjectFormat = domain.ObjectFormat
+
+// Object formats, as in a repository's extensions.objectformat setting.
+const (
+	ObjectFormatSHA1   = domain.ObjectFormatSHA1
+	ObjectFormatSHA256 = domain.ObjectFormatSHA256
+)
+
+var (
+	// ErrObjectFormatMismatch indicates a commit SHA has the length of the
+	// other object format than the repository's.
+	ErrObjectFormatMismatch = domain.ErrObjectFormatMismatch
+
+	// ErrUnsupportedObjectFormat indicates the repository uses an object
+	// format this build cannot read; see BuildObjectFormat.
+	ErrUnsupportedObjectFormat = domain.ErrUnsupportedObjectFormat
+)
+
+// BuildObjectFormat is the object format this build reads. go-git reads one
+// format per build: sha1 by default, sha256 when built with -tags sha256.
+var BuildObjectFormat = buildObjectFormat()
+
+func buildObjectFormat() ObjectFormat {
+	if hash.HexSize == ObjectFormatSHA256.HexLength() {
+		return ObjectFormatSHA256
+	}
+	return ObjectFormatSHA1
+}
+
+// objectFormatOf returns the object format repo is configured with. Returns
+// ErrUnsupportedObjectFormat if it is unknown or not BuildObjectFormat, since
+// reading objects of another format fails with misleading "object not found"
+// errors.
+func objectFormatOf(repo *git.Repository) (ObjectFormat, error) {
+	cfg, err := repo.Config()
+	if err != nil {
+		return "", fmt.Errorf("failed to read repository config: %w", err)
+	}
+	// go-git does not decode the extension, so it is read from the raw config
+	format := ObjectFormat(cfg.Raw.Section("extensions").Option("objectformat"))
+	if format == "" {
+		format = ObjectFormatSHA1
+	}
+	switch {
+	case format.HexLength() == 0:
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedObjectFormat, format)
+	case format != BuildObjectFormat:
+		tag := " (build with -tags sha256 to read it)"
+		if format == ObjectFormatSHA1 {
+			tag = " (build without -tags sha256 to read it)"
+		}
+		return "", fmt.Errorf("%w: the repository uses the %s object format, but this build reads %s repositories%s",
+			ErrUnsupportedObjectFormat, format, BuildObjectFormat, tag)
+	}
+	return format, nil
+}
+
+// ObjectFormat returns the repository's object format, which sets the length
+// of its commit SHAs.
+func (r *Repository) ObjectFormat() ObjectFormat {
+	return r.format
+}
+
+// checkRevisionFormat returns ErrObjectFormatMismatch if rev is a full commit
+// SHA of another object format than the repository's.
+func (r *Repository) checkRevisionFormat(rev string) error {
+	return domain.CheckObjectFormat(r.format, rev)
+}
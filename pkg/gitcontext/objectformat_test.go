@@ -0,0 +1,66 @@
+package gitcontext
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// otherObjectFormat returns the object format this build does not read.
+func otherObjectFormat() ObjectFormat {
+	if BuildObjectFormat == ObjectFormatSHA1 {
+		return ObjectFormatSHA256
+	}
+	return ObjectFormatSHA1
+}
+
+func TestOpen_ObjectFormat(t *testing.T) {
+	t.Run("build format", func(t *testing.T) {
+		dir := t.TempDir()
+		runGit(t, dir, "init", "--object-format="+string(BuildObjectFormat))
+
+		repo, err := Open(dir)
+
+		require.NoError(t, err)
+		assert.Equal(t, BuildObjectFormat, repo.ObjectFormat())
+	})
+
+	t.Run("other format", func(t *testing.T) {
+		dir := t.TempDir()
+		runGit(t, dir, "init", "--object-format="+string(otherObjectFormat()))
+
+		_, err := Open(dir)
+
+		require.ErrorIs(t, err, ErrUnsupportedObjectFormat)
+		assert.Contains(t, err.Error(), "uses the "+string(otherObjectFormat())+" object format")
+		assert.Contains(t, err.Error(), "-tags sha256")
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		dir := t.TempDir()
+		runGit(t, dir, "init")
+		runGit(t, dir, "config", "extensions.objectformat", "md5")
+
+		_, err := Open(dir)
+
+		require.ErrorIs(t, err, ErrUnsupportedObjectFormat)
+		assert.Contains(t, err.Error(), `"md5"`)
+	})
+}
+
+func TestRepository_ResolveCommit_ObjectFormatMismatch(t *testing.T) {
+	dir, _ := mergedRepo(t)
+	repo, err := Open(dir)
+	require.NoError(t, err)
+	sha := strings.Repeat("a", otherObjectFormat().HexLength())
+
+	_, err = repo.ResolveCommit(sha)
+
+	require.ErrorIs(t, err, ErrObjectFormatMismatch)
+	assert.Contains(t, err.Error(), "expected "+string(BuildObjectFormat))
+	_, err = repo.AncestryFrom(context.Background(), sha, 0)
+	assert.ErrorIs(t, err, ErrObjectFormatMismatch)
+}
@@ -0,0 +1,84 @@
+package gitcontext
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// FindRoot returns the root of the repository containing path, as git -C
+// path does: path is made absolute and its symlinks resolved, then it and its
+// parent directories are searched for a working directory (one holding .git,
+// a directory or, in linked worktrees and submodules, a file) or a bare
+// repository. A path naming a file is searched from its directory.
+//
+// The search does not enter ceilings, absolute directories as in git's
+// GIT_CEILING_DIRECTORIES: path itself is searched even if it is one, but no
+// parent at or above a ceiling is. Relative ceilings are ignored, as in git.
+// Returns ErrRepositoryNotFound if no directory searched is a repository.
+func FindRoot(path string, ceilings ...string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %w", ErrRepositoryNotFound, path, err)
+	}
+	dir, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %w", ErrRepositoryNotFound, path, err)
+	}
+	if fi, err := os.Stat(dir); err == nil && !fi.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+
+	stop := ceilingSet(ceilings)
+	for {
+		if isRepositoryRoot(dir) {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("%w: %s or any of its parent directories", ErrRepositoryNotFound, path)
+		}
+		if stop[parent] {
+			return "", fmt.Errorf("%w: %s or any of its parent directories below the ceiling %s",
+				ErrRepositoryNotFound, path, parent)
+		}
+		dir = parent
+	}
+}
+
+// ceilingSet returns the absolute ceilings, cleaned and with their symlinks
+// resolved where they exist, so they compare equal to the searched parents.
+func ceilingSet(ceilings []string) map[string]bool {
+	set := make(map[string]bool, len(ceilings))
+	for _, ceiling := range ceilings {
+		if !filepath.IsAbs(ceiling) {
+			continue
+		}
+		ceiling = filepath.Clean(ceiling)
+		if resolved, err := filepath.EvalSymlinks(ceiling); err == nil {
+			ceiling = resolved
+		}
+		set[ceiling] = true
+	}
+	return set
+}
+
+// isRepositoryRoot reports whether dir is a working directory or a bare
+// repository. Like git, a bare repository is recognized by its HEAD file and
+// objects and refs directories.
+func isRepositoryRoot(dir string) bool {
+	if _, err := os.Stat(filepath.Join(dir, git.GitDirName)); err == nil {
+		return true
+	}
+	if fi, err := os.Stat(filepath.Join(dir, "HEAD")); err != nil || fi.IsDir() {
+		return false
+	}
+	for _, sub := range []string{"objects", "refs"} {
+		if fi, err := os.Stat(filepath.Join(dir, sub)); err != nil || !fi.IsDir() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,113 @@
+package gitcontext
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindRoot(t *testing.T) {
+	dir, commits := mergedRepo(t)
+	dir, err := filepath.EvalSymlinks(dir)
+	require.NoError(t, err)
+	services := filepath.Join(dir, "services", "foo")
+	require.NoError(t, os.MkdirAll(services, 0o755))
+	link := filepath.Join(t.TempDir(), "link")
+	require.NoError(t, os.Symlink(services, link))
+	bare := filepath.Join(t.TempDir(), "bare.git")
+	runGit(t, dir, "clone", "--quiet", "--bare", dir, bare)
+	bare, err = filepath.EvalSymlinks(bare)
+	require.NoError(t, err)
+	worktree := filepath.Join(t.TempDir(), "worktree")
+	runGit(t, dir, "worktree", "add", "--detach", worktree, commits["feature"])
+	worktree, err = filepath.EvalSymlinks(worktree)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "root", path: dir, want: dir},
+		{name: "subdirectory", path: services, want: dir},
+		{name: "file", path: filepath.Join(dir, "main.txt"), want: dir},
+		{name: "symlink into the worktree", path: link, want: dir},
+		{name: "unclean path", path: services + "/../../services/.", want: dir},
+		{name: "bare repository", path: filepath.Join(bare, "refs", "heads"), want: bare},
+		{name: "linked worktree", path: worktree, want: worktree},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FindRoot(tt.path)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("relative", func(t *testing.T) {
+		t.Chdir(services)
+
+		got, err := FindRoot(".")
+
+		require.NoError(t, err)
+		assert.Equal(t, dir, got)
+	})
+}
+
+func TestFindRoot_Ceilings(t *testing.T) {
+	dir, _ := mergedRepo(t)
+	dir, err := filepath.EvalSymlinks(dir)
+	require.NoError(t, err)
+	services := filepath.Join(dir, "services")
+	foo := filepath.Join(services, "foo")
+	require.NoError(t, os.MkdirAll(foo, 0o755))
+
+	tests := []struct {
+		name     string
+		path     string
+		ceilings []string
+		want     string
+		wantErr  string
+	}{
+		{name: "ceiling above the root", path: foo, ceilings: []string{filepath.Dir(dir)}, want: dir},
+		{name: "ceiling is the root", path: foo, ceilings: []string{dir}, wantErr: "below the ceiling " + dir},
+		{name: "ceiling between", path: foo, ceilings: []string{"", services}, wantErr: "below the ceiling"},
+		{name: "path is the ceiling", path: dir, ceilings: []string{dir}, want: dir},
+		{name: "relative ceiling ignored", path: foo, ceilings: []string{"services"}, want: dir},
+		{name: "unclean ceiling", path: foo, ceilings: []string{dir + "/services/"}, wantErr: "below the ceiling"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FindRoot(tt.path, tt.ceilings...)
+
+			if tt.wantErr != "" {
+				require.ErrorIs(t, err, ErrRepositoryNotFound)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFindRoot_NotARepository(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+	}{
+		{name: "no repository", path: t.TempDir()},
+		{name: "missing path", path: filepath.Join(t.TempDir(), "missing")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := FindRoot(tt.path)
+
+			assert.ErrorIs(t, err, ErrRepositoryNotFound)
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package slippyfind
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/MyCarrier-DevOps/slippy-find/internal/adapters/git"
+)
+
+// DefaultConcurrency is the number of requests ResolveMany resolves at once
+// unless WithConcurrency sets another.
+const DefaultConcurrency = 8
+
+// Response is the outcome of one request of ResolveMany: its Result, or the
+// Err it failed with, as Resolve would report them.
+type Response struct {
+	// Request is the request resolved.
+	Request Request
+
+	// Result is the resolved slip, nil if Err is set.
+	Result *Result
+
+	// Err is why the request failed, nil if it succeeded.
+	Err error
+}
+
+// WithConcurrency returns a copy of r that resolves n ResolveMany requests
+// at once. Values below 1 mean 1.
+func (r *Resolver) WithConcurrency(n int) *Resolver {
+	copied := *r
+	copied.concurrency = max(n, 1)
+	return &copied
+}
+
+// WithRequestTimeout returns a copy of r that gives up on a ResolveMany
+// request after d, failing it without affecting the others. Zero or less
+// means no limit.
+func (r *Resolver) WithRequestTimeout(d time.Duration) *Resolver {
+	copied := *r
+	copied.requestTimeout = max(d, 0)
+	return &copied
+}
+
+// ResolveMany resolves every request against the Resolver's Store, sharing
+// its connection, and returns one Response per request in the same order.
+// A failing request does not stop the others; once ctx is done, requests
+// not yet started fail with its error.
+//
+// Requests are resolved with the defaults of Options, overridden by their
+// Depth and SelectionPolicy.
+func (r *Resolver) ResolveMany(ctx context.Context, reqs []Request) []Response {
+	responses := make([]Response, len(reqs))
+	r.ResolveEach(ctx, reqs, func(i int, response Response) {
+		responses[i] = response
+	})
+	return responses
+}
+
+// ResolveEach resolves reqs as ResolveMany does, but hands each Response to
+// fn with the index of its request as soon as it is resolved, so results can
+// be written while later requests are still resolving. fn is called once per
+// request, in completion order, and never concurrently; ResolveEach returns
+// once every call has returned.
+func (r *Resolver) ResolveEach(ctx context.Context, reqs []Request, fn func(i int, response Response)) {
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	deliver := func(i int, response Response) {
+		mu.Lock()
+		defer mu.Unlock()
+		fn(i, response)
+	}
+	slots := make(chan struct{}, max(r.concurrency, 1))
+	for i, req := range reqs {
+		if err := ctx.Err(); err != nil {
+			deliver(i, Response{Request: req, Err: err})
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			deliver(i, Response{Request: req, Err: ctx.Err()})
+			continue
+		case slots <- struct{}{}:
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			result, err := r.resolveTimed(ctx, req)
+			deliver(i, Response{Request: req, Result: result, Err: err})
+		}()
+	}
+	wg.Wait()
+}
+
+// resolveTimed resolves one ResolveMany request within the request timeout.
+func (r *Resolver) resolveTimed(ctx context.Context, req Request) (*Result, error) {
+	if r.requestTimeout <= 0 {
+		return r.resolveRequest(ctx, req)
+	}
+	reqCtx, cancel := context.WithTimeout(ctx, r.requestTimeout)
+	defer cancel()
+	result, err := r.resolveRequest(reqCtx, req)
+	if err != nil && ctx.Err() == nil && errors.Is(reqCtx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("request timed out after %s: %w", r.requestTimeout, err)
+	}
+	return result, err
+}
+
+// resolveRequest resolves one ResolveMany request.
+func (r *Resolver) resolveRequest(ctx context.Context, req Request) (*Result, error) {
+	req, err := req.normalized()
+	if err != nil {
+		return nil, err
+	}
+	input, err := Options{Depth: req.Depth, SelectionPolicy: req.SelectionPolicy}.resolveInput()
+	if err != nil {
+		return nil, err
+	}
+	if req.Path == "" {
+		return r.resolveWith(ctx, git.NewKnownAncestry(req.Repository, req.Branch, req.Commits), input)
+	}
+	repo, err := git.NewGoGitRepository(req.Path, r.log)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = repo.Close() }()
+	return r.resolveWith(ctx, repo, input)
+}
@@ -0,0 +1,182 @@
+package slippyfind
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// countingFinder is a fakeFinder whose searches block until release is
+// closed, recording how many ran at once.
+type countingFinder struct {
+	fakeFinder
+	mu      sync.Mutex
+	running int
+	peak    int
+	release chan struct{}
+}
+
+func (f *countingFinder) enter() {
+	f.mu.Lock()
+	f.running++
+	f.peak = max(f.peak, f.running)
+	f.mu.Unlock()
+	<-f.release
+	f.mu.Lock()
+	f.running--
+	f.mu.Unlock()
+}
+
+func (f *countingFinder) inFlight() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.running
+}
+
+func (f *countingFinder) FindByCommits(ctx context.Context, repository string, commits []string) (*domain.Slip, string, error) {
+	f.enter()
+	return f.fakeFinder.FindByCommits(ctx, repository, commits)
+}
+
+func (f *countingFinder) FindAllByCommits(ctx context.Context, repository string, commits []string) ([]domain.SlipMatch, error) {
+	f.enter()
+	return f.fakeFinder.FindAllByCommits(ctx, repository, commits)
+}
+
+func TestResolver_ResolveMany(t *testing.T) {
+	dir, commits := testRepo(t)
+	finder := &fakeFinder{slips: map[string]*domain.Slip{
+		commits[1]: {CorrelationID: "slip-head", Repository: "org/repo", CommitSHA: commits[1]},
+		"c222222222222222222222222222222222222222": {CorrelationID: "slip-c2", Repository: "org/other", CommitSHA: "c222222222222222222222222222222222222222"},
+	}}
+	reqs := []Request{
+		{Path: dir},
+		{Repository: "org/other", Branch: "main", Commits: []string{"c111111111111111111111111111111111111111", "c222222222222222222222222222222222222222"}},
+		{Repository: "org/other", Commits: []string{"c999999999999999999999999999999999999999"}},
+		{Repository: "org/other"},
+		{Repository: "org/other", Commits: []string{"c222222222222222222222222222222222222222"}, SelectionPolicy: "oldest"},
+	}
+
+	responses := NewResolver(&Store{finder: finder}, nil).ResolveMany(context.Background(), reqs)
+
+	require.Len(t, responses, len(reqs))
+	for i, response := range responses {
+		assert.Equal(t, reqs[i], response.Request)
+	}
+	require.NoError(t, responses[0].Err)
+	assert.Equal(t, "slip-head", responses[0].Result.CorrelationID)
+	require.NoError(t, responses[1].Err)
+	assert.Equal(t, "slip-c2", responses[1].Result.CorrelationID)
+	assert.Equal(t, "c222222222222222222222222222222222222222", responses[1].Result.MatchedCommit)
+	assert.Equal(t, "main", responses[1].Result.Branch)
+	assert.ErrorIs(t, responses[2].Err, ErrNoSlip)
+	assert.ErrorContains(t, responses[3].Err, "no commits")
+	assert.ErrorIs(t, responses[4].Err, ErrInvalidSelectionPolicy)
+	assert.False(t, finder.closed, "the shared store stays open")
+}
+
+func TestResolver_ResolveMany_Concurrency(t *testing.T) {
+	finder := &countingFinder{release: make(chan struct{})}
+	reqs := make([]Request, 5)
+	for i := range reqs {
+		reqs[i] = Request{Repository: "org/repo", Commits: []string{"c111111111111111111111111111111111111111"}}
+	}
+	resolver := NewResolver(&Store{finder: finder}, nil).WithConcurrency(2)
+
+	done := make(chan []Response)
+	go func() { done <- resolver.ResolveMany(context.Background(), reqs) }()
+	require.Eventually(t, func() bool { return finder.inFlight() == 2 }, 5*time.Second, time.Millisecond)
+	close(finder.release)
+	responses := <-done
+
+	assert.Equal(t, 2, finder.peak)
+	for _, response := range responses {
+		assert.ErrorIs(t, response.Err, ErrNoSlip)
+	}
+}
+
+func TestResolver_ResolveMany_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	responses := NewResolver(&Store{finder: &fakeFinder{}}, nil).ResolveMany(ctx, []Request{
+		{Repository: "org/repo", Commits: []string{"c111111111111111111111111111111111111111"}},
+	})
+
+	require.Len(t, responses, 1)
+	assert.ErrorIs(t, responses[0].Err, context.Canceled)
+}
+
+// stallingFinder is a fakeFinder whose searches for stall block until their
+// context is done.
+type stallingFinder struct {
+	fakeFinder
+	stall string
+}
+
+func (f *stallingFinder) FindByCommits(ctx context.Context, repository string, commits []string) (*domain.Slip, string, error) {
+	if repository == f.stall {
+		<-ctx.Done()
+		return nil, "", ctx.Err()
+	}
+	return f.fakeFinder.FindByCommits(ctx, repository, commits)
+}
+
+func (f *stallingFinder) FindAllByCommits(ctx context.Context, repository string, commits []string) ([]domain.SlipMatch, error) {
+	if repository == f.stall {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return f.fakeFinder.FindAllByCommits(ctx, repository, commits)
+}
+
+func TestResolver_ResolveMany_RequestTimeout(t *testing.T) {
+	finder := &stallingFinder{stall: "org/slow", fakeFinder: fakeFinder{slips: map[string]*domain.Slip{
+		"c111111111111111111111111111111111111111": {CorrelationID: "slip-1", Repository: "org/fast", CommitSHA: "c111111111111111111111111111111111111111"},
+	}}}
+	resolver := NewResolver(&Store{finder: finder}, nil).WithRequestTimeout(10 * time.Millisecond)
+
+	responses := resolver.ResolveMany(context.Background(), []Request{
+		{Repository: "org/slow", Commits: []string{"c111111111111111111111111111111111111111"}},
+		{Repository: "org/fast", Commits: []string{"c111111111111111111111111111111111111111"}},
+	})
+
+	require.Len(t, responses, 2)
+	assert.ErrorIs(t, responses[0].Err, context.DeadlineExceeded)
+	assert.ErrorContains(t, responses[0].Err, "request timed out after 10ms")
+	require.NoError(t, responses[1].Err, "a timed-out request does not affect the others")
+	assert.Equal(t, "slip-1", responses[1].Result.CorrelationID)
+}
+
+func TestResolver_ResolveEach(t *testing.T) {
+	finder := &stallingFinder{stall: "org/slow"}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reqs := []Request{
+		{Repository: "org/slow", Commits: []string{"c111111111111111111111111111111111111111"}},
+		{Repository: "org/fast", Commits: []string{"c111111111111111111111111111111111111111"}},
+	}
+
+	var (
+		order   []int
+		calling bool
+	)
+	NewResolver(&Store{finder: finder}, nil).ResolveEach(ctx, reqs, func(i int, response Response) {
+		assert.False(t, calling, "fn is never called concurrently")
+		calling = true
+		defer func() { calling = false }()
+		order = append(order, i)
+		if i == 1 {
+			assert.ErrorIs(t, response.Err, ErrNoSlip)
+			cancel()
+		}
+	})
+
+	assert.Equal(t, []int{1, 0}, order, "responses are delivered as they complete")
+}
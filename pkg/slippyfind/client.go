@@ -0,0 +1,112 @@
+package slippyfind
+
+import (
+	"context"
+	"errors"
+
+	"github.com/MyCarrier-DevOps/slippy-find/internal/adapters/server"
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// Request is a HEAD to resolve, by a Client or with Resolver.ResolveMany:
+// either the checkout at Path, or the ancestry given by Repository and
+// Commits.
+type Request struct {
+	// Path is the repository checkout to resolve for. A Client's server only
+	// accepts paths under its --repo-root.
+	Path string
+
+	// Repository is the repository in owner/repo format. Used with Commits.
+	Repository string
+
+	// Branch is the branch Commits were taken from, if known.
+	Branch string
+
+	// Commits is the HEAD ancestry as full commit SHAs, newest (HEAD) first.
+	// Used with Repository.
+	Commits []string
+
+	// Depth is the number of Commits searched. Zero means DefaultDepth.
+	Depth int
+
+	// SelectionPolicy chooses among several matching slips, as in Options.
+	SelectionPolicy string
+
+	// Tenant names the server's slip store to search. Empty means its
+	// default. A Resolver searches its own Store and ignores it.
+	Tenant string
+}
+
+// normalized checks that req names one HEAD, a well-formed repository and
+// commit list, and a valid depth. Returns req with its repository and commits
+// normalized as by domain.ParseRepository and domain.ParseCommitList.
+func (req Request) normalized() (Request, error) {
+	switch {
+	case req.Path != "" && (req.Repository != "" || len(req.Commits) > 0):
+		return req, errors.New("request path cannot be combined with repository or commits")
+	case req.Path == "" && req.Repository == "":
+		return req, errors.New("request has no path or repository")
+	case req.Path == "" && len(req.Commits) == 0:
+		return req, errors.New("request has no commits")
+	case req.Depth < 0:
+		return req, errors.New("request depth must not be negative")
+	}
+	if req.Path != "" {
+		return req, nil
+	}
+	var err error
+	if req.Repository, err = domain.ParseRepository(req.Repository); err != nil {
+		return req, err
+	}
+	req.Commits, err = domain.ParseCommitList(req.Commits)
+	return req, err
+}
+
+// Client resolves slips through the HTTP API of a slippy-find server (see
+// the README's Serve Mode section), for callers that have a commit list but
+// neither a checkout nor store credentials. It is the way to resolve from
+// WebAssembly, where requests are sent with the browser's fetch API.
+type Client struct {
+	client *server.Client
+}
+
+// NewClient creates a client for the server at baseURL, such as
+// "https://slippy-find.example.com", authenticating with the bearer token
+// if it is not empty.
+func NewClient(baseURL, token string) *Client {
+	return &Client{client: server.NewHTTPClientWithToken(baseURL, token)}
+}
+
+// Resolve asks the server to resolve req. A failure the server reports
+// carries its error code; see ErrorCode.
+func (c *Client) Resolve(ctx context.Context, req Request) (*Result, error) {
+	req, err := req.normalized()
+	if err != nil {
+		return nil, err
+	}
+	policy, err := domain.ParseSelectionPolicy(req.SelectionPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := c.client.Resolve(ctx, domain.ResolveRequest{
+		Path:            req.Path,
+		Repository:      req.Repository,
+		Branch:          req.Branch,
+		Commits:         req.Commits,
+		Depth:           req.Depth,
+		SelectionPolicy: policy,
+		Tenant:          req.Tenant,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newResult(output), nil
+}
+
+// ErrorCode returns the error code of err, such as "SLIPPY_E003" when no slip
+// matched, as listed in the README's Error Codes section. Errors without one,
+// such as a server that cannot be reached, are "SLIPPY_E000"; nil is "".
+func ErrorCode(err error) string {
+	return string(domain.CodeOf(err))
+}
@@ -0,0 +1,115 @@
+package slippyfind
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Resolve(t *testing.T) {
+	var body map[string]any
+	var auth string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"correlation_id":"slip-1","matched_commit":"c222222222222222222222222222222222222222","repository":"org/repo",` +
+			`"resolved_by":"ancestry","selection_policy":"newest-created","candidates":1,"depth":2,` +
+			`"distance":1,"confidence":"near-ancestor"}`))
+	}))
+	t.Cleanup(ts.Close)
+
+	result, err := NewClient(ts.URL, "s3cret").Resolve(context.Background(), Request{
+		Repository:      "org/repo",
+		Branch:          "main",
+		Commits:         []string{"c111111111111111111111111111111111111111", "c222222222222222222222222222222222222222"},
+		SelectionPolicy: "newest-created",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, &Result{
+		CorrelationID:   "slip-1",
+		MatchedCommit:   "c222222222222222222222222222222222222222",
+		Repository:      "org/repo",
+		Depth:           2,
+		Distance:        1,
+		Confidence:      "near-ancestor",
+		ResolvedBy:      "ancestry",
+		SelectionPolicy: "newest-created",
+		Candidates:      1,
+	}, result)
+	assert.Equal(t, "Bearer s3cret", auth)
+	assert.Equal(t, "org/repo", body["repository"])
+	assert.Equal(t, []any{"c111111111111111111111111111111111111111", "c222222222222222222222222222222222222222"}, body["commits"])
+}
+
+func TestClient_Resolve_Errors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":"no slip found in commit ancestry","code":"SLIPPY_E003"}`))
+	}))
+	t.Cleanup(ts.Close)
+	client := NewClient(ts.URL, "")
+	valid := Request{Repository: "org/repo", Commits: []string{"c111111111111111111111111111111111111111"}}
+
+	_, err := client.Resolve(context.Background(), valid)
+	assert.Equal(t, "SLIPPY_E003", ErrorCode(err))
+
+	tests := []struct {
+		name    string
+		mutate  func(*Request)
+		wantErr error
+		wantMsg string
+	}{
+		{name: "no repository", mutate: func(r *Request) { r.Repository = "" }, wantMsg: "no path or repository"},
+		{name: "path and commits", mutate: func(r *Request) { r.Path = "/builds/repo" }, wantMsg: "cannot be combined"},
+		{name: "no commits", mutate: func(r *Request) { r.Commits = nil }, wantMsg: "no commits"},
+		{name: "negative depth", mutate: func(r *Request) { r.Depth = -1 }, wantMsg: "must not be negative"},
+		{name: "unknown policy", mutate: func(r *Request) { r.SelectionPolicy = "oldest" }, wantErr: ErrInvalidSelectionPolicy},
+		{
+			name:    "empty repository name",
+			mutate:  func(r *Request) { r.Repository = "org/" },
+			wantErr: ErrInvalidRepository,
+			wantMsg: "empty repository name",
+		},
+		{
+			name:    "malformed commit",
+			mutate:  func(r *Request) { r.Commits = []string{"c111111111111111111111111111111111111111", "main"} },
+			wantErr: ErrInvalidCommitList,
+			wantMsg: `commits[1] "main" contains 'm', expected hexadecimal digits`,
+		},
+		{
+			name:    "abbreviated commit",
+			mutate:  func(r *Request) { r.Commits = []string{"abc123"} },
+			wantErr: ErrInvalidCommitList,
+			wantMsg: `commits[0] "abc123" is 6 characters long, expected a full SHA of 40 or 64`,
+		},
+		{
+			name:    "mixed object formats",
+			mutate:  func(r *Request) { r.Commits = []string{strings.Repeat("a", 40), strings.Repeat("b", 64)} },
+			wantErr: ErrObjectFormatMismatch,
+			wantMsg: "expected sha1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid
+			tt.mutate(&req)
+
+			_, err := client.Resolve(context.Background(), req)
+
+			require.Error(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+			assert.ErrorContains(t, err, tt.wantMsg)
+		})
+	}
+	assert.Empty(t, ErrorCode(nil))
+}
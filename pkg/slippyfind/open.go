@@ -0,0 +1,48 @@
+//go:build !js
+
+package slippyfind
+
+import (
+	"context"
+
+	"github.com/MyCarrier-DevOps/goLibMyCarrier/slippy"
+
+	"github.com/MyCarrier-DevOps/slippy-find/internal/adapters/store"
+	"github.com/MyCarrier-DevOps/slippy-find/internal/infrastructure/config"
+)
+
+// OpenStore connects to the slip store configured in the environment and
+// Vault, exactly as the CLI does (see the README's Configuration section),
+// including a finder plugin or the mock store. It is not available in
+// WebAssembly builds, which resolve through a Client instead.
+func OpenStore(ctx context.Context) (*Store, error) {
+	cfg, err := config.LoadWithVaultClient(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case cfg.FinderPlugin != "":
+		return &Store{finder: store.NewExecFinder(cfg.FinderPlugin)}, nil
+	case cfg.Store == config.StoreMock:
+		return &Store{finder: store.NewMockFinder()}, nil
+	}
+	queryCfg, err := config.LoadQueryConfig()
+	if err != nil {
+		return nil, err
+	}
+	clickHouseStore, err := slippy.NewClickHouseStoreFromConfig(cfg.ClickHouse, slippy.ClickHouseStoreOptions{
+		PipelineConfig: cfg.PipelineConfig,
+		Database:       cfg.Database,
+		SkipMigrations: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Store{
+		finder: store.NewClickHouseAdapterWithSession(clickHouseStore, clickHouseStore.Session(), cfg.Database,
+			store.WithChunkSize(queryCfg.ChunkSize),
+			store.WithMaxQueryCommits(queryCfg.MaxQueryCommits),
+			store.WithCommitList(store.CommitList(queryCfg.CommitList)),
+		),
+	}, nil
+}
@@ -0,0 +1,329 @@
+// Package slippyfind resolves routing slips in-process, for Go services that
+// would otherwise run the slippy-find CLI and parse its stdout.
+//
+// The exported API of this package follows the module's semantic version:
+// it only changes incompatibly in a new major version. Everything under
+// internal/ may change in any release.
+//
+//	store, err := slippyfind.OpenStore(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	defer store.Close()
+//
+//	result, err := slippyfind.NewResolver(store, nil).Resolve(ctx, "/builds/payments-api", slippyfind.Options{})
+//	if errors.Is(err, slippyfind.ErrNoSlip) {
+//		// no slip in the ancestry
+//	}
+package slippyfind
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/MyCarrier-DevOps/slippy-find/internal/adapters/git"
+	"github.com/MyCarrier-DevOps/slippy-find/internal/usecases"
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/extension"
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/gitcontext"
+)
+
+// Errors returned by Resolve, for use with errors.Is.
+var (
+	// ErrNoSlip indicates no slip matched the searched commits.
+	ErrNoSlip = domain.ErrNoAncestorSlip
+
+	// ErrRepositoryNotFound indicates the path is not a Git repository.
+	ErrRepositoryNotFound = domain.ErrRepositoryNotFound
+
+	// ErrNoRemoteOrigin indicates the repository has no origin remote to name it.
+	ErrNoRemoteOrigin = domain.ErrNoRemoteOrigin
+
+	// ErrInvalidSelectionPolicy indicates Options.SelectionPolicy is unknown.
+	ErrInvalidSelectionPolicy = domain.ErrInvalidSelectionPolicy
+
+	// ErrInvalidStepRequirement indicates a malformed Options.RequiredSteps entry.
+	ErrInvalidStepRequirement = domain.ErrInvalidStepRequirement
+
+	// ErrStepRequirementUnmet indicates the resolved slip does not satisfy Options.RequiredSteps.
+	ErrStepRequirementUnmet = domain.ErrStepRequirementUnmet
+
+	// ErrInvalidHint indicates Options.Hint names no slip of the repository, or a denied one.
+	ErrInvalidHint = domain.ErrInvalidHint
+
+	// ErrInvalidRepository indicates Request.Repository is not a valid
+	// owner/repo name.
+	ErrInvalidRepository = domain.ErrInvalidRepository
+
+	// ErrObjectFormatMismatch indicates Request.Commits mixes SHA-1 and SHA-256
+	// commit SHAs.
+	ErrObjectFormatMismatch = domain.ErrObjectFormatMismatch
+
+	// ErrInvalidCommitList indicates Request.Commits is empty, too long, or
+	// holds a value that is not a full commit SHA. The error is a
+	// *CommitListError.
+	ErrInvalidCommitList = domain.ErrInvalidCommitList
+
+	// ErrStageTimeout indicates reading git or querying the store took longer
+	// than Options.GitTimeout or Options.StoreTimeout.
+	ErrStageTimeout = domain.ErrStageTimeout
+)
+
+// CommitListError describes a rejected Request.Commits: the position and
+// value of the rejected commit, or an Index of -1 for the whole list.
+type CommitListError = domain.CommitListError
+
+// MaxCommitList is the most commits a Request may carry.
+const MaxCommitList = domain.MaxCommitList
+
+// DefaultDepth is the number of commits searched when Options.Depth is zero.
+const DefaultDepth = domain.DefaultAncestryDepth
+
+// Options control a resolution. The zero value searches DefaultDepth commits of
+// HEAD's ancestry with the nearest-commit selection policy. Unlike the CLI, it
+// does not prefer slips of the current branch; set BranchAffinity for that.
+type Options struct {
+	// Depth is the number of ancestry commits to search. Zero means DefaultDepth.
+	Depth int
+
+	// MaxDepth enables progressive deepening up to this many commits when no
+	// slip is found within Depth. Zero disables it.
+	MaxDepth int
+
+	// SelectionPolicy chooses among several matching slips: "nearest-commit",
+	// "newest-created", or "branch-preferred". Empty means "nearest-commit".
+	SelectionPolicy string
+
+	// MaxAge rejects slips created longer ago than this. Zero means no limit.
+	MaxAge time.Duration
+
+	// Since rejects slips created before this time. Zero means no limit.
+	Since time.Time
+
+	// FallbackMergeBase searches the ancestry of HEAD's merge base with
+	// DefaultBranch when HEAD's ancestry has no slip.
+	FallbackMergeBase bool
+
+	// FallbackBranchLatest returns the newest slip of the current branch when
+	// every other search misses.
+	FallbackBranchLatest bool
+
+	// DefaultBranch is the branch used by the fallbacks. Empty means the
+	// repository's default branch (see GitContext.DefaultBranch), else "main".
+	DefaultBranch string
+
+	// Component restricts resolution to one monorepo component owning
+	// ComponentPaths. Empty disables it.
+	Component string
+
+	// ComponentPaths are the repository-relative path prefixes owned by Component.
+	ComponentPaths []string
+
+	// RequiredSteps are "step=status" requirements the resolved slip must meet.
+	RequiredSteps []string
+
+	// BranchAffinity prefers slips recorded for the current branch. The CLI
+	// enables it by default.
+	BranchAffinity bool
+
+	// DenyList holds correlation IDs that are never returned.
+	DenyList []string
+
+	// Hint is a correlation ID to validate and return instead of searching.
+	Hint string
+
+	// GitTimeout is the most time the resolution may spend reading git, in
+	// total. Zero means no limit.
+	GitTimeout time.Duration
+
+	// StoreTimeout is the most time the resolution may spend querying the
+	// slip store, in total. Zero means no limit.
+	StoreTimeout time.Duration
+}
+
+// Result is a resolved slip.
+type Result struct {
+	// CorrelationID identifies the resolved slip.
+	CorrelationID string
+
+	// MatchedCommit is the commit the slip was found for.
+	MatchedCommit string
+
+	// Repository is the repository in owner/repo format.
+	Repository string
+
+	// Branch is the checked-out branch, empty if HEAD is detached.
+	Branch string
+
+	// Depth is the number of ancestry commits walked. It exceeds Options.Depth
+	// only when progressive deepening was needed.
+	Depth int
+
+	// Distance is the number of commits between HEAD and MatchedCommit, or -1
+	// when a fallback found the slip.
+	Distance int
+
+	// Confidence classifies Distance: "exact-head", "near-ancestor", or "deep-ancestor".
+	Confidence string
+
+	// ResolvedBy names the search that found the slip, e.g. "ancestry" or "merge-base".
+	ResolvedBy string
+
+	// SelectionPolicy is the policy that chose the slip.
+	SelectionPolicy string
+
+	// Candidates is the number of matching slips the selection policy chose from.
+	Candidates int
+
+	// Component is the component resolved for, if any.
+	Component string
+
+	// Mock reports that the slip came from the mock store and is not real.
+	Mock bool
+}
+
+// Logger receives the resolver's log entries.
+type Logger interface {
+	Info(ctx context.Context, msg string, fields map[string]interface{})
+	Debug(ctx context.Context, msg string, fields map[string]interface{})
+	Warn(ctx context.Context, msg string, fields map[string]interface{})
+	Error(ctx context.Context, msg string, err error, fields map[string]interface{})
+}
+
+// discardLogger drops every entry.
+type discardLogger struct{}
+
+func (discardLogger) Info(context.Context, string, map[string]interface{})         {}
+func (discardLogger) Debug(context.Context, string, map[string]interface{})        {}
+func (discardLogger) Warn(context.Context, string, map[string]interface{})         {}
+func (discardLogger) Error(context.Context, string, error, map[string]interface{}) {}
+
+// Resolver resolves slips for local repositories from a Store. It is safe
+// for concurrent use.
+type Resolver struct {
+	store *Store
+	log   Logger
+
+	// concurrency is the number of ResolveMany requests resolved at once.
+	concurrency int
+
+	// requestTimeout bounds each ResolveMany request; zero means no limit.
+	requestTimeout time.Duration
+}
+
+// NewResolver creates a Resolver searching store. A nil log discards log entries.
+func NewResolver(store *Store, log Logger) *Resolver {
+	if log == nil {
+		log = discardLogger{}
+	}
+	return &Resolver{store: store, log: log, concurrency: DefaultConcurrency}
+}
+
+// Resolve finds the slip for the HEAD of the repository at repoPath, exactly
+// as the CLI would with the equivalent flags.
+func (r *Resolver) Resolve(ctx context.Context, repoPath string, opts Options) (*Result, error) {
+	input, err := opts.resolveInput()
+	if err != nil {
+		return nil, err
+	}
+	repo, err := git.NewGoGitRepository(repoPath, r.log)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = repo.Close() }()
+
+	return r.resolveWith(ctx, repo, input)
+}
+
+// ResolveRepository is Resolve for a repository already opened with
+// gitcontext, such as one from gitcontext.OpenFS in WebAssembly, where there
+// is no OS filesystem to open a path from. The repository's options, such as
+// WithPaths, apply to its walks.
+func (r *Resolver) ResolveRepository(ctx context.Context, repo *gitcontext.Repository, opts Options) (*Result, error) {
+	input, err := opts.resolveInput()
+	if err != nil {
+		return nil, err
+	}
+	return r.resolveWith(ctx, git.NewGoGitRepositoryFrom(repo, r.log), input)
+}
+
+// resolveWith resolves the slip of repo against the store, with the built-in
+// strategies and any registered through package extension, as the CLI does.
+func (r *Resolver) resolveWith(
+	ctx context.Context,
+	repo domain.LocalGitRepository,
+	input domain.ResolveInput,
+) (*Result, error) {
+	resolver := usecases.NewSlipResolverWithRegistry(repo, r.store.finder, r.log, extension.Strategies())
+	output, err := resolver.Resolve(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return newResult(output), nil
+}
+
+// newResult converts a resolver output to a Result.
+func newResult(output *domain.ResolveOutput) *Result {
+	return &Result{
+		CorrelationID:   output.CorrelationID,
+		MatchedCommit:   output.MatchedCommit,
+		Repository:      output.Repository,
+		Branch:          output.Branch,
+		Depth:           output.Depth,
+		Distance:        output.Distance,
+		Confidence:      string(output.Confidence),
+		ResolvedBy:      output.ResolvedBy,
+		SelectionPolicy: string(output.SelectionPolicy),
+		Candidates:      output.Candidates,
+		Component:       output.Component,
+		Mock:            output.Mock,
+	}
+}
+
+// resolveInput validates opts and converts them to the resolver's input.
+func (opts Options) resolveInput() (domain.ResolveInput, error) {
+	policy, err := domain.ParseSelectionPolicy(opts.SelectionPolicy)
+	if err != nil {
+		return domain.ResolveInput{}, err
+	}
+	requirements := make([]domain.StepRequirement, 0, len(opts.RequiredSteps))
+	for _, s := range opts.RequiredSteps {
+		requirement, err := domain.ParseStepRequirement(s)
+		if err != nil {
+			return domain.ResolveInput{}, err
+		}
+		requirements = append(requirements, requirement)
+	}
+	depth := opts.Depth
+	if depth < 0 {
+		return domain.ResolveInput{}, fmt.Errorf("invalid depth %d: must not be negative", depth)
+	}
+	if depth == 0 {
+		depth = DefaultDepth
+	}
+	if opts.GitTimeout < 0 || opts.StoreTimeout < 0 {
+		return domain.ResolveInput{}, fmt.Errorf("invalid stage timeout: GitTimeout and StoreTimeout must not be negative")
+	}
+	return domain.ResolveInput{
+		Depth:                depth,
+		MaxDepth:             opts.MaxDepth,
+		SelectionPolicy:      policy,
+		MaxAge:               opts.MaxAge,
+		Since:                opts.Since,
+		FallbackMergeBase:    opts.FallbackMergeBase,
+		FallbackBranchLatest: opts.FallbackBranchLatest,
+		DefaultBranch:        opts.DefaultBranch,
+		Component:            opts.Component,
+		ComponentPaths:       opts.ComponentPaths,
+		RequiredSteps:        requirements,
+		BranchAffinity:       opts.BranchAffinity,
+		DenyList:             opts.DenyList,
+		Hint:                 opts.Hint,
+		SlowThresholds: domain.SlowThresholds{
+			GitWalk:    domain.DefaultSlowGitWalk,
+			StoreQuery: domain.DefaultSlowStoreQuery,
+		},
+		StageTimeouts: domain.StageTimeouts{Git: opts.GitTimeout, Store: opts.StoreTimeout},
+	}, nil
+}
@@ -0,0 +1,221 @@
+package slippyfind
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/internal/adapters/store"
+	"github.com/MyCarrier-DevOps/slippy-find/internal/infrastructure/config"
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/extension"
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/gitcontext"
+)
+
+// fakeFinder holds slips by commit SHA.
+type fakeFinder struct {
+	slips  map[string]*domain.Slip
+	closed bool
+}
+
+func (f *fakeFinder) FindByCommits(_ context.Context, _ string, commits []string) (*domain.Slip, string, error) {
+	for _, c := range commits {
+		if slip, ok := f.slips[c]; ok {
+			return slip, c, nil
+		}
+	}
+	return nil, "", nil
+}
+
+func (f *fakeFinder) FindAllByCommits(_ context.Context, _ string, commits []string) ([]domain.SlipMatch, error) {
+	var matches []domain.SlipMatch
+	for _, c := range commits {
+		if slip, ok := f.slips[c]; ok {
+			matches = append(matches, domain.SlipMatch{Slip: slip, MatchedCommit: c})
+		}
+	}
+	return matches, nil
+}
+
+func (f *fakeFinder) FindLatestByBranch(context.Context, string, string) (*domain.Slip, error) {
+	return nil, domain.ErrSlipNotFound
+}
+
+func (f *fakeFinder) FindByID(context.Context, string) (*domain.Slip, error) {
+	return nil, domain.ErrSlipNotFound
+}
+
+func (f *fakeFinder) Close() error {
+	f.closed = true
+	return nil
+}
+
+// testRepo creates a repository of org/repo with two commits on main and
+// returns its path and the commit SHAs, oldest first.
+func testRepo(t *testing.T) (string, []string) {
+	t.Helper()
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git %v: %s", args, out)
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-b", "main")
+	git("config", "user.email", "test@example.com")
+	git("config", "user.name", "Test User")
+	git("remote", "add", "origin", "https://github.com/org/repo.git")
+	var commits []string
+	for _, content := range []string{"one", "two"} {
+		require.NoError(t, os.WriteFile(dir+"/file.txt", []byte(content), 0o644))
+		git("add", ".")
+		git("commit", "-m", content)
+		commits = append(commits, git("rev-parse", "HEAD"))
+	}
+	return dir, commits
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	dir, commits := testRepo(t)
+	finder := &fakeFinder{slips: map[string]*domain.Slip{
+		commits[0]: {CorrelationID: "slip-1", Repository: "org/repo", CommitSHA: commits[0]},
+	}}
+	resolver := NewResolver(&Store{finder: finder}, nil)
+
+	result, err := resolver.Resolve(context.Background(), dir, Options{})
+
+	require.NoError(t, err)
+	assert.Equal(t, &Result{
+		CorrelationID:   "slip-1",
+		MatchedCommit:   commits[0],
+		Repository:      "org/repo",
+		Branch:          "main",
+		Depth:           2,
+		Distance:        1,
+		Confidence:      "near-ancestor",
+		ResolvedBy:      "ancestry",
+		SelectionPolicy: "nearest-commit",
+		Candidates:      1,
+	}, result)
+}
+
+func TestResolver_ResolveRepository(t *testing.T) {
+	dir, commits := testRepo(t)
+	repo, err := gitcontext.OpenFS(osfs.New(filepath.Join(dir, ".git")))
+	require.NoError(t, err)
+	finder := &fakeFinder{slips: map[string]*domain.Slip{
+		commits[1]: {CorrelationID: "slip-2", Repository: "org/repo", CommitSHA: commits[1]},
+	}}
+
+	result, err := NewResolver(NewFinderStore(finder), nil).ResolveRepository(context.Background(), repo, Options{})
+
+	require.NoError(t, err)
+	assert.Equal(t, "slip-2", result.CorrelationID)
+	assert.Equal(t, "exact-head", result.Confidence)
+}
+
+func TestResolver_Resolve_Errors(t *testing.T) {
+	dir, _ := testRepo(t)
+	tests := []struct {
+		name    string
+		path    string
+		opts    Options
+		wantErr error
+	}{
+		{name: "no slip", path: dir, wantErr: ErrNoSlip},
+		{name: "not a repository", path: t.TempDir(), wantErr: ErrRepositoryNotFound},
+		{name: "unknown policy", path: dir, opts: Options{SelectionPolicy: "oldest"}, wantErr: ErrInvalidSelectionPolicy},
+		{
+			name:    "malformed requirement",
+			path:    dir,
+			opts:    Options{RequiredSteps: []string{"build"}},
+			wantErr: ErrInvalidStepRequirement,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := NewResolver(&Store{finder: &fakeFinder{}}, nil)
+
+			_, err := resolver.Resolve(context.Background(), tt.path, tt.opts)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+// extensionRepository is the only repository extensionStrategy resolves, so
+// it leaves the other tests' misses alone.
+const extensionRepository = "org/extension"
+
+// extensionStrategy is a compiled-in strategy that resolves every commit of
+// extensionRepository to slip-ext.
+type extensionStrategy struct{}
+
+func (extensionStrategy) Name() string { return "test-extension" }
+
+func (extensionStrategy) Resolve(_ context.Context, req *extension.StrategyRequest) (extension.StrategyResult, error) {
+	if req.GitCtx.Repository != extensionRepository {
+		return extension.StrategyResult{}, nil
+	}
+	return extension.StrategyResult{
+		Match:      &domain.SlipMatch{Slip: &domain.Slip{CorrelationID: "slip-ext"}, MatchedCommit: req.GitCtx.HeadSHA},
+		Candidates: 1,
+		Distance:   -1,
+	}, nil
+}
+
+// Registered as a downstream build's extension would be, before any resolver
+// is created.
+func init() {
+	extension.RegisterStrategy(extensionStrategy{})
+}
+
+func TestResolver_ExtensionStrategy(t *testing.T) {
+	head := strings.Repeat("e", 40)
+	resolver := NewResolver(NewFinderStore(&fakeFinder{}), nil)
+
+	responses := resolver.ResolveMany(context.Background(), []Request{
+		{Repository: extensionRepository, Commits: []string{head}},
+		{Repository: "org/repo", Commits: []string{head}},
+	})
+
+	require.NoError(t, responses[0].Err)
+	assert.Equal(t, "slip-ext", responses[0].Result.CorrelationID)
+	assert.Equal(t, "test-extension", responses[0].Result.ResolvedBy)
+	assert.ErrorIs(t, responses[1].Err, ErrNoSlip, "the built-ins still miss elsewhere")
+}
+
+func TestStore_Close(t *testing.T) {
+	finder := &fakeFinder{}
+
+	require.NoError(t, (&Store{finder: finder}).Close())
+
+	assert.True(t, finder.closed)
+}
+
+func TestOpenStore_Mock(t *testing.T) {
+	dir, commits := testRepo(t)
+	t.Setenv(config.EnvStore, config.StoreMock)
+	t.Setenv(config.EnvFinderPlugin, "")
+
+	s, err := OpenStore(context.Background())
+	require.NoError(t, err)
+	defer s.Close()
+	result, err := NewResolver(s, nil).Resolve(context.Background(), dir, Options{})
+
+	require.NoError(t, err)
+	assert.Equal(t, store.MockCorrelationID("org/repo", commits[1]), result.CorrelationID)
+	assert.Equal(t, commits[1], result.MatchedCommit)
+	assert.Equal(t, "exact-head", result.Confidence)
+	assert.True(t, result.Mock)
+}
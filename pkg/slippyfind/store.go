@@ -0,0 +1,34 @@
+package slippyfind
+
+import (
+	"github.com/MyCarrier-DevOps/goLibMyCarrier/slippy"
+
+	"github.com/MyCarrier-DevOps/slippy-find/internal/adapters/store"
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// Store is the slip store a Resolver searches. One Store may be shared by any
+// number of Resolvers and resolutions.
+type Store struct {
+	finder domain.SlipFinder
+}
+
+// NewStore wraps a goLibMyCarrier ClickHouse store the caller has already
+// opened, such as one it writes slips through. database is the store's
+// ClickHouse database. Closing the Store closes clickHouseStore.
+func NewStore(clickHouseStore *slippy.ClickHouseStore, database string) *Store {
+	return &Store{
+		finder: store.NewClickHouseAdapterWithSession(clickHouseStore, clickHouseStore.Session(), database),
+	}
+}
+
+// NewFinderStore wraps a domain.SlipFinder, such as a custom store or a
+// slippyfindtest.MemoryFinder. Closing the Store closes finder.
+func NewFinderStore(finder domain.SlipFinder) *Store {
+	return &Store{finder: finder}
+}
+
+// Close closes the connection to the store.
+func (s *Store) Close() error {
+	return s.finder.Close()
+}
@@ -0,0 +1,115 @@
+package slippyfindtest
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// MemoryFinder is an in-memory domain.SlipFinder. It is the reference
+// implementation of the contract TestSlipFinder checks, and a stand-in store
+// for tests of code that searches slips.
+type MemoryFinder struct {
+	slips []*domain.Slip
+}
+
+// NewMemoryFinder creates a finder over slips. Repositories are matched
+// case-insensitively, as the ClickHouse store matches them.
+func NewMemoryFinder(slips ...*domain.Slip) *MemoryFinder {
+	return &MemoryFinder{slips: slips}
+}
+
+// FindByCommits returns the first match of FindAllByCommits.
+// Returns (nil, "", nil) if no commit has a slip.
+func (f *MemoryFinder) FindByCommits(ctx context.Context, repository string, commits []string) (*domain.Slip, string, error) {
+	matches, err := f.FindAllByCommits(ctx, repository, commits)
+	if err != nil || len(matches) == 0 {
+		return nil, "", err
+	}
+	return matches[0].Slip, matches[0].MatchedCommit, nil
+}
+
+// FindAllByCommits returns every slip of the repository created for one of
+// commits, ordered by commit priority (the earliest commit in the list
+// first), then by Slip.Precedes. A commit listed twice keeps its first
+// position, and each slip is returned once.
+func (f *MemoryFinder) FindAllByCommits(
+	_ context.Context,
+	repository string,
+	commits []string,
+) ([]domain.SlipMatch, error) {
+	priority := make(map[string]int, len(commits))
+	for i, c := range commits {
+		if _, ok := priority[c]; !ok {
+			priority[c] = i
+		}
+	}
+	matches := []domain.SlipMatch{}
+	for _, s := range f.slips {
+		if _, ok := priority[s.CommitSHA]; ok && strings.EqualFold(s.Repository, repository) {
+			matches = append(matches, domain.SlipMatch{Slip: cloneSlip(s), MatchedCommit: s.CommitSHA})
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b domain.SlipMatch) int {
+		if d := priority[a.MatchedCommit] - priority[b.MatchedCommit]; d != 0 {
+			return d
+		}
+		return compareSlips(a.Slip, b.Slip)
+	})
+	return matches, nil
+}
+
+// FindLatestByBranch returns the newest slip of the repository's branch.
+// Returns domain.ErrSlipNotFound if the branch has none.
+func (f *MemoryFinder) FindLatestByBranch(_ context.Context, repository, branch string) (*domain.Slip, error) {
+	var latest *domain.Slip
+	for _, s := range f.slips {
+		if s.Branch == branch && strings.EqualFold(s.Repository, repository) &&
+			(latest == nil || s.Precedes(latest)) {
+			latest = s
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("%w: %s@%s", domain.ErrSlipNotFound, repository, branch)
+	}
+	return cloneSlip(latest), nil
+}
+
+// FindByID returns the slip with correlationID.
+// Returns domain.ErrSlipNotFound if there is none.
+func (f *MemoryFinder) FindByID(_ context.Context, correlationID string) (*domain.Slip, error) {
+	for _, s := range f.slips {
+		if s.CorrelationID == correlationID {
+			return cloneSlip(s), nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", domain.ErrSlipNotFound, correlationID)
+}
+
+// Close does nothing.
+func (f *MemoryFinder) Close() error {
+	return nil
+}
+
+// compareSlips orders slips by Slip.Precedes.
+func compareSlips(a, b *domain.Slip) int {
+	switch {
+	case a.Precedes(b):
+		return -1
+	case b.Precedes(a):
+		return 1
+	}
+	return 0
+}
+
+// cloneSlip copies s so callers cannot change the finder's slips.
+func cloneSlip(s *domain.Slip) *domain.Slip {
+	c := *s
+	c.Components = slices.Clone(s.Components)
+	c.Steps = maps.Clone(s.Steps)
+	return &c
+}
@@ -0,0 +1,31 @@
+package slippyfindtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+func TestMemoryFinder(t *testing.T) {
+	TestSlipFinder(t, func(_ *testing.T, slips []*domain.Slip) domain.SlipFinder {
+		return NewMemoryFinder(slips...)
+	})
+}
+
+func TestMemoryFinder_ReturnsCopies(t *testing.T) {
+	finder := NewMemoryFinder(Slips()...)
+
+	slip, err := finder.FindByID(context.Background(), "head")
+	require.NoError(t, err)
+	slip.Components[0] = "changed"
+	slip.Steps["build"] = "failed"
+
+	again, err := finder.FindByID(context.Background(), "head")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"api", "web"}, again.Components)
+	assert.Equal(t, "completed", again.Steps["build"])
+}
@@ -0,0 +1,225 @@
+// Package slippyfindtest checks that a domain.SlipFinder behaves like the
+// ClickHouse store slippy-find searches by default, so a backend written for
+// another store, or served through a finder plugin, resolves the same slips.
+//
+// A backend's tests run the suite with a function that seeds a fresh store:
+//
+//	func TestPostgresFinder(t *testing.T) {
+//		slippyfindtest.TestSlipFinder(t, func(t *testing.T, slips []*domain.Slip) domain.SlipFinder {
+//			db := newTestDatabase(t)
+//			insertSlips(t, db, slips)
+//			return postgres.NewFinder(db)
+//		})
+//	}
+package slippyfindtest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/MyCarrier-DevOps/slippy-find/pkg/domain"
+)
+
+// NewFinder returns the finder under test, searching a store that holds
+// exactly slips. The suite calls it once per subtest and closes the finder
+// when the subtest ends.
+type NewFinder func(t *testing.T, slips []*domain.Slip) domain.SlipFinder
+
+// Repository is the repository most slips of the suite belong to.
+const Repository = "acme/payments"
+
+// OtherRepository holds a slip for one of Repository's commits, which
+// searches of Repository must never return.
+const OtherRepository = "acme/orders"
+
+// created is the CreatedAt of the oldest slip of the suite.
+var created = time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+
+// Slips returns the slips the suite seeds each finder with:
+//
+//	c1  head      main     +3h  components api, web
+//	c2  c2-new    feature  +2h
+//	c2  c2-tie    release  +2h  (ties c2-new, loses on correlation ID)
+//	c2  c2-old    main     +1h
+//	c4  c4        main     +0h
+//	c1  other-c1  main     +5h  (OtherRepository)
+//
+// Commit c3 has no slip.
+func Slips() []*domain.Slip {
+	return []*domain.Slip{
+		{
+			CorrelationID: "head", Repository: Repository, Branch: "main", CommitSHA: "c1",
+			CreatedAt: created.Add(3 * time.Hour), Components: []string{"api", "web"},
+			Steps: map[string]string{"build": "completed", "deploy": "pending"},
+		},
+		{CorrelationID: "c2-old", Repository: Repository, Branch: "main", CommitSHA: "c2", CreatedAt: created.Add(time.Hour)},
+		{CorrelationID: "c2-new", Repository: Repository, Branch: "feature", CommitSHA: "c2", CreatedAt: created.Add(2 * time.Hour)},
+		{CorrelationID: "c2-tie", Repository: Repository, Branch: "release", CommitSHA: "c2", CreatedAt: created.Add(2 * time.Hour)},
+		{CorrelationID: "c4", Repository: Repository, Branch: "main", CommitSHA: "c4", CreatedAt: created},
+		{CorrelationID: "other-c1", Repository: OtherRepository, Branch: "main", CommitSHA: "c1", CreatedAt: created.Add(5 * time.Hour)},
+	}
+}
+
+// TestSlipFinder runs the conformance suite against the finders newFinder
+// returns.
+func TestSlipFinder(t *testing.T, newFinder NewFinder) {
+	t.Helper()
+	open := func(t *testing.T) domain.SlipFinder {
+		t.Helper()
+		finder := newFinder(t, Slips())
+		require.NotNil(t, finder)
+		t.Cleanup(func() {
+			assert.NoError(t, finder.Close(), "Close")
+		})
+		return finder
+	}
+	ctx := context.Background()
+
+	t.Run("FindAllByCommits", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			repository string
+			commits    []string
+			want       []string // correlation ID@matched commit, in order
+		}{
+			{
+				name:       "orders by commit priority then newest",
+				repository: Repository,
+				commits:    []string{"c1", "c2", "c3", "c4"},
+				want:       []string{"head@c1", "c2-new@c2", "c2-tie@c2", "c2-old@c2", "c4@c4"},
+			},
+			{
+				name:       "follows the order of the list",
+				repository: Repository,
+				commits:    []string{"c4", "c1"},
+				want:       []string{"c4@c4", "head@c1"},
+			},
+			{
+				name:       "duplicate commits keep their first position",
+				repository: Repository,
+				commits:    []string{"c2", "c1", "c2", "c1"},
+				want:       []string{"c2-new@c2", "c2-tie@c2", "c2-old@c2", "head@c1"},
+			},
+			{name: "empty commit list", repository: Repository, commits: []string{}},
+			{name: "nil commit list", repository: Repository},
+			{name: "commits without slips", repository: Repository, commits: []string{"c3", "unknown"}},
+			{
+				name:       "repository is case-insensitive",
+				repository: "ACME/Payments",
+				commits:    []string{"c4"},
+				want:       []string{"c4@c4"},
+			},
+			{
+				name:       "other repository",
+				repository: OtherRepository,
+				commits:    []string{"c1", "c2"},
+				want:       []string{"other-c1@c1"},
+			},
+			{name: "unknown repository", repository: "acme/unknown", commits: []string{"c1"}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				finder := open(t)
+
+				matches, err := finder.FindAllByCommits(ctx, tt.repository, tt.commits)
+
+				require.NoError(t, err)
+				got := make([]string, 0, len(matches))
+				for _, m := range matches {
+					require.NotNil(t, m.Slip)
+					assert.Equal(t, m.Slip.CommitSHA, m.MatchedCommit, "MatchedCommit of %s", m.Slip.CorrelationID)
+					got = append(got, m.Slip.CorrelationID+"@"+m.MatchedCommit)
+				}
+				assert.Equal(t, append([]string{}, tt.want...), got)
+
+				slip, matched, err := finder.FindByCommits(ctx, tt.repository, tt.commits)
+				require.NoError(t, err, "FindByCommits")
+				if len(tt.want) == 0 {
+					assert.Nil(t, slip, "FindByCommits")
+					assert.Empty(t, matched, "FindByCommits")
+					return
+				}
+				require.NotNil(t, slip, "FindByCommits")
+				assert.Equal(t, tt.want[0], slip.CorrelationID+"@"+matched, "FindByCommits returns the first match")
+			})
+		}
+	})
+
+	t.Run("FindByID", func(t *testing.T) {
+		finder := open(t)
+
+		for _, want := range Slips() {
+			got, err := finder.FindByID(ctx, want.CorrelationID)
+			require.NoError(t, err, want.CorrelationID)
+			assertSlip(t, want, got)
+		}
+	})
+
+	t.Run("FindByID not found", func(t *testing.T) {
+		finder := open(t)
+
+		_, err := finder.FindByID(ctx, "unknown")
+
+		assert.ErrorIs(t, err, domain.ErrSlipNotFound)
+	})
+
+	t.Run("FindLatestByBranch", func(t *testing.T) {
+		tests := []struct {
+			repository string
+			branch     string
+			want       string
+		}{
+			{repository: Repository, branch: "main", want: "head"},
+			{repository: Repository, branch: "feature", want: "c2-new"},
+			{repository: "ACME/PAYMENTS", branch: "main", want: "head"},
+			{repository: OtherRepository, branch: "main", want: "other-c1"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.repository+"@"+tt.branch, func(t *testing.T) {
+				finder := open(t)
+
+				slip, err := finder.FindLatestByBranch(ctx, tt.repository, tt.branch)
+
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, slip.CorrelationID)
+			})
+		}
+	})
+
+	t.Run("FindLatestByBranch not found", func(t *testing.T) {
+		for _, tt := range []struct{ repository, branch string }{
+			{Repository, "unknown"},
+			{Repository, "Main"},
+			{"acme/unknown", "main"},
+		} {
+			finder := open(t)
+
+			_, err := finder.FindLatestByBranch(ctx, tt.repository, tt.branch)
+
+			assert.ErrorIs(t, err, domain.ErrSlipNotFound, "%s@%s", tt.repository, tt.branch)
+		}
+	})
+}
+
+// assertSlip asserts got carries every field of want. Empty and nil
+// components or steps are equivalent.
+func assertSlip(t *testing.T, want, got *domain.Slip) {
+	t.Helper()
+	require.NotNil(t, got, want.CorrelationID)
+	assert.Equal(t, want.CorrelationID, got.CorrelationID)
+	assert.Equal(t, want.Repository, got.Repository, want.CorrelationID)
+	assert.Equal(t, want.Branch, got.Branch, want.CorrelationID)
+	assert.Equal(t, want.CommitSHA, got.CommitSHA, want.CorrelationID)
+	assert.True(t, want.CreatedAt.Equal(got.CreatedAt), "%s: CreatedAt %v, want %v",
+		want.CorrelationID, got.CreatedAt, want.CreatedAt)
+	if len(want.Components) > 0 || len(got.Components) > 0 {
+		assert.Equal(t, want.Components, got.Components, want.CorrelationID)
+	}
+	if len(want.Steps) > 0 || len(got.Steps) > 0 {
+		assert.Equal(t, want.Steps, got.Steps, want.CorrelationID)
+	}
+}